@@ -0,0 +1,24 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+
+	"backend/internal/models"
+)
+
+//	go test -run '^$' -bench . -benchmem ./internal/ai/
+
+func BenchmarkAnswerPrompt(b *testing.B) {
+	note := "Title: Attention\nContent: " + strings.Repeat("the transformer relies entirely on attention ", 400) + "\n\n"
+	contextText := strings.Repeat(note, 5)
+	history := []models.ConversationMessage{
+		{Role: models.ConversationRoleUser, Content: "What did the paper say about attention?"},
+		{Role: models.ConversationRoleAssistant, Content: strings.Repeat("attention draws global dependencies ", 40)},
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		answerPrompt("And about recurrent models?", contextText, history, nil)
+	}
+}
@@ -3,67 +3,160 @@ package ai
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/google/generative-ai-go/genai"
 	"google.golang.org/api/option"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
+	"backend/internal/config"
+	"backend/internal/models"
 	"backend/internal/utils"
 )
 
-// AIClient wraps the Gemini generative AI client with helper methods
+// AIClient wraps the configured AI provider with helper methods
 type AIClient struct {
-	client *genai.Client
+	generator       generator
+	gemini          *genai.Client // Set when Gemini generates or embeds
+	embeddingModel  string
+	embeddingServer *embeddingServer // Set when an OpenAI-compatible server embeds instead of Gemini
+	resilience      *resilience      // Rate limits, retries and circuit-breaks every API call
+	cfg             *config.Config
+	sampler         func(models.AISample) // Given every generation call once SetSampler is called
 }
 
-// NewAIClient creates a new AI client with the provided API key
-func NewAIClient(ctx context.Context, apiKey string) (*AIClient, error) {
-	client, err := genai.NewClient(ctx, option.WithAPIKey(apiKey))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
+// generator produces text replies from the configured provider's generation model
+type generator interface {
+	generate(ctx context.Context, req generateRequest) (string, error)
+	// generateStream passes each text chunk of the reply to onChunk and returns the whole reply
+	generateStream(ctx context.Context, req generateRequest, onChunk func(string) error) (string, error)
+}
+
+// generateRequest is a single-turn prompt, optionally with an image the prompt refers to
+type generateRequest struct {
+	operation string // The AIClient method making the call, for AI samples
+	prompt    string
+	answer    bool          // Answers a question, so the answer model (ANSWER_MODEL) replies
+	jsonReply bool          // Ask the model for a JSON object rather than free text
+	schema    *genai.Schema // Constrain the JSON reply to this structure; implies jsonReply
+	image     []byte
+	imageType string
+}
+
+// generationModel returns the name of the model that replies to a request
+func generationModel(cfg *config.Config, req generateRequest) string {
+	if req.answer {
+		return cfg.AnswerModel
 	}
+	return cfg.GenerationModel
+}
 
-	return &AIClient{client: client}, nil
+// NewAIClient creates a new AI client for the configured AI_PROVIDER
+func NewAIClient(ctx context.Context, cfg *config.Config) (*AIClient, error) {
+	c := &AIClient{
+		embeddingModel:  cfg.EmbeddingModel,
+		embeddingServer: newEmbeddingServer(cfg),
+		resilience:      newResilience(cfg),
+		cfg:             cfg,
+	}
+
+	switch cfg.AIProvider {
+	case config.AI_PROVIDER_GEMINI, "":
+		client, err := genai.NewClient(ctx, option.WithAPIKey(cfg.GeminiAPIKey))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Gemini client: %w", err)
+		}
+		c.gemini = client
+		c.generator = &geminiGenerator{
+			client:         client,
+			cfg:            cfg,
+			safetySettings: buildSafetySettings(cfg.SafetyThreshold),
+		}
+	case config.AI_PROVIDER_OPENAI:
+		c.generator = newOpenAIGenerator(cfg)
+	default:
+		return nil, fmt.Errorf("unknown AI_PROVIDER %q, expected %s or %s", cfg.AIProvider, config.AI_PROVIDER_GEMINI, config.AI_PROVIDER_OPENAI)
+	}
+
+	return c, nil
 }
 
 // Close closes the underlying client connection
 func (c *AIClient) Close() error {
-	return c.client.Close()
+	if c.gemini == nil {
+		return nil
+	}
+	return c.gemini.Close()
 }
 
-// GenerativeModel returns a generative model by name
-func (c *AIClient) GenerativeModel(name string) *genai.GenerativeModel {
-	return c.client.GenerativeModel(name)
+// SetSampler passes every generation call, with its raw prompt and reply, to sample, which decides
+// whether to keep it. It must be set before the client is used.
+func (c *AIClient) SetSampler(sample func(models.AISample)) {
+	c.sampler = sample
 }
 
-// EmbeddingModel returns an embedding model by name
-func (c *AIClient) EmbeddingModel(name string) *genai.EmbeddingModel {
-	return c.client.EmbeddingModel(name)
+// generate sends a prompt to the generation model and returns its trimmed reply
+func (c *AIClient) generate(req generateRequest) (string, error) {
+	ctx := context.Background()
+	start := time.Now()
+	var reply string
+	err := c.resilience.do(ctx, func() (err error) {
+		reply, err = c.generator.generate(ctx, req)
+		return err
+	}, nil)
+	c.sample(req, reply, err, start)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(reply), nil
 }
 
-// ExtractTextResponse extracts plain text from a Gemini response
-// Consolidates the duplicate pattern used throughout the codebase
-func ExtractTextResponse(result *genai.GenerateContentResponse) (string, error) {
-	if result == nil || len(result.Candidates) == 0 || result.Candidates[0].Content == nil {
-		return "", fmt.Errorf("no response generated")
+// sample passes a finished generation call to the sampler, if one is set
+func (c *AIClient) sample(req generateRequest, reply string, err error, start time.Time) {
+	if c.sampler == nil {
+		return
 	}
-
-	responseText := strings.TrimSpace(string(result.Candidates[0].Content.Parts[0].(genai.Text)))
-	return responseText, nil
+	sample := models.AISample{
+		Operation:  req.operation,
+		Model:      generationModel(c.cfg, req),
+		Prompt:     req.prompt,
+		Response:   strings.TrimSpace(reply),
+		ImageType:  req.imageType,
+		DurationMs: time.Since(start).Milliseconds(),
+		CreatedAt:  time.Now(),
+	}
+	if err != nil {
+		sample.Error = err.Error()
+	}
+	c.sampler(sample)
 }
 
-// ExtractJSONResponse extracts and parses JSON from a Gemini response
-// Automatically cleans markdown code blocks before parsing
-func ExtractJSONResponse(result *genai.GenerateContentResponse, target interface{}) error {
-	if result == nil || len(result.Candidates) == 0 || result.Candidates[0].Content == nil {
-		return fmt.Errorf("no response generated")
+// ErrRateLimited is returned when the API rejects a call over its rate limit or quota
+var ErrRateLimited = errors.New("rate limited")
+
+// IsRateLimited reports whether a call failed because the API's rate limit or quota was exhausted,
+// so it is worth retrying after a pause
+func IsRateLimited(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrRateLimited) || status.Code(err) == codes.ResourceExhausted {
+		return true
 	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "resource_exhausted") || strings.Contains(msg, "resourceexhausted") || strings.Contains(msg, "error 429")
+}
 
-	responseText := string(result.Candidates[0].Content.Parts[0].(genai.Text))
-	responseText = utils.CleanMarkdownCodeBlocks(responseText)
+// parseJSONReply parses a JSON reply into target
+// Automatically cleans markdown code blocks before parsing
+func parseJSONReply(reply string, target interface{}) error {
+	reply = utils.CleanMarkdownCodeBlocks(reply)
 
-	if err := json.Unmarshal([]byte(responseText), target); err != nil {
+	if err := json.Unmarshal([]byte(reply), target); err != nil {
 		return fmt.Errorf("failed to parse JSON response: %w", err)
 	}
 
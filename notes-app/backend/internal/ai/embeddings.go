@@ -3,19 +3,46 @@ package ai
 import (
 	"context"
 	"fmt"
+	"log"
 
 	"github.com/google/generative-ai-go/genai"
-
-	"backend/internal/config"
 )
 
-// GenerateEmbedding generates a vector embedding for the given text using the configured embedding model
+// DetectEmbeddingDimension finds the length of the embedding vectors by embedding a short probe text
+// configured is EMBEDDING_DIM (0 when unset), which the detected dimension must match. When the
+// model can't be reached the configured dimension is returned, or 0 when there is none.
+func DetectEmbeddingDimension(client Client, configured int) (int, error) {
+	embedding, err := client.GenerateEmbedding("embedding dimension probe")
+	if err != nil {
+		log.Printf("Failed to detect the embedding dimension: %v", err)
+		return configured, nil
+	}
+	if configured != 0 && len(embedding) != configured {
+		return 0, fmt.Errorf("EMBEDDING_DIM is %d but the embedding model returns %d-dimensional embeddings", configured, len(embedding))
+	}
+	return len(embedding), nil
+}
+
+// GenerateEmbedding generates a vector embedding for the given text using the configured embedding model,
+// on the embedding server when one is configured
 func (c *AIClient) GenerateEmbedding(text string) ([]float32, error) {
+	if c.embeddingServer != nil {
+		embeddings, err := c.embedOnServer([]string{text})
+		if err != nil {
+			return nil, err
+		}
+		return embeddings[0], nil
+	}
+
 	ctx := context.Background()
 
-	model := c.EmbeddingModel(config.EMBEDDING_MODEL)
+	model := c.gemini.EmbeddingModel(c.embeddingModel)
 
-	result, err := model.EmbedContent(ctx, genai.Text(text))
+	var result *genai.EmbedContentResponse
+	err := c.resilience.do(ctx, func() (err error) {
+		result, err = model.EmbedContent(ctx, genai.Text(text))
+		return err
+	}, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate embedding: %w", err)
 	}
@@ -26,3 +53,53 @@ func (c *AIClient) GenerateEmbedding(text string) ([]float32, error) {
 
 	return result.Embedding.Values, nil
 }
+
+// GenerateEmbeddings generates the embeddings of several texts in one request, in the order given
+// Callers keep batches within EMBEDDING_BATCH_SIZE texts, the most Gemini accepts per request.
+func (c *AIClient) GenerateEmbeddings(texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	if c.embeddingServer != nil {
+		return c.embedOnServer(texts)
+	}
+
+	ctx := context.Background()
+
+	model := c.gemini.EmbeddingModel(c.embeddingModel)
+	batch := model.NewBatch()
+	for _, text := range texts {
+		batch.AddContent(genai.Text(text))
+	}
+
+	var result *genai.BatchEmbedContentsResponse
+	err := c.resilience.do(ctx, func() (err error) {
+		result, err = model.BatchEmbedContents(ctx, batch)
+		return err
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate embeddings: %w", err)
+	}
+	if result == nil || len(result.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings in the batch response", len(texts))
+	}
+
+	embeddings := make([][]float32, len(texts))
+	for i, embedding := range result.Embeddings {
+		if embedding == nil || len(embedding.Values) == 0 {
+			return nil, fmt.Errorf("no embedding returned for text %d of the batch", i)
+		}
+		embeddings[i] = embedding.Values
+	}
+	return embeddings, nil
+}
+
+// embedOnServer embeds texts on the embedding server through the rate limiter and retries
+func (c *AIClient) embedOnServer(texts []string) ([][]float32, error) {
+	var embeddings [][]float32
+	err := c.resilience.do(context.Background(), func() (err error) {
+		embeddings, err = c.embeddingServer.embed(texts)
+		return err
+	}, nil)
+	return embeddings, err
+}
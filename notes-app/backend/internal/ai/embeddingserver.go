@@ -0,0 +1,95 @@
+package ai
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"backend/internal/config"
+)
+
+// embeddingServer calls an OpenAI-compatible embeddings API, such as OpenAI, Ollama or
+// text-embeddings-inference, in place of Gemini
+type embeddingServer struct {
+	baseURL string
+	apiKey  string
+	model   string
+	client  *http.Client
+}
+
+// newEmbeddingServer creates a client for the configured embedding server, or nil when Gemini embeds
+// With the openai provider and no EMBEDDING_URL, the provider's API embeds.
+func newEmbeddingServer(cfg *config.Config) *embeddingServer {
+	baseURL, apiKey := cfg.EmbeddingURL, cfg.EmbeddingAPIKey
+	if baseURL == "" && cfg.AIProvider == config.AI_PROVIDER_OPENAI {
+		baseURL, apiKey = cfg.OpenAIURL, cfg.OpenAIAPIKey
+	}
+	if baseURL == "" {
+		return nil
+	}
+	return &embeddingServer{
+		baseURL: baseURL,
+		apiKey:  apiKey,
+		model:   cfg.EmbeddingModel,
+		client:  &http.Client{Timeout: config.EMBEDDING_SERVER_TIMEOUT_SECONDS * time.Second},
+	}
+}
+
+// embed returns the embeddings of texts from the server's /embeddings endpoint, in the order given
+func (s *embeddingServer) embed(texts []string) ([][]float32, error) {
+	body, err := json.Marshal(map[string]interface{}{"model": s.model, "input": texts})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.baseURL+"/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate embedding: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, fmt.Errorf("failed to generate embedding: %w", ErrRateLimited)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("failed to generate embedding: embedding server returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	var result struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to parse embedding response: %w", err)
+	}
+
+	// Each embedding carries the position of its input, which need not match its own
+	embeddings := make([][]float32, len(texts))
+	for _, item := range result.Data {
+		if item.Index >= 0 && item.Index < len(embeddings) {
+			embeddings[item.Index] = item.Embedding
+		}
+	}
+	for _, embedding := range embeddings {
+		if len(embedding) == 0 {
+			return nil, fmt.Errorf("no embedding returned")
+		}
+	}
+	return embeddings, nil
+}
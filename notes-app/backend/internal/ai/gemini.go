@@ -0,0 +1,129 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/iterator"
+
+	"backend/internal/config"
+)
+
+// geminiGenerator generates replies with the Gemini API
+type geminiGenerator struct {
+	client         *genai.Client
+	cfg            *config.Config
+	safetySettings []*genai.SafetySetting
+}
+
+// model returns the model for a request with the configured safety settings and generation parameters applied
+func (g *geminiGenerator) model(req generateRequest) *genai.GenerativeModel {
+	model := g.client.GenerativeModel(generationModel(g.cfg, req))
+	model.SafetySettings = g.safetySettings
+	if g.cfg.GenerationTemperature >= 0 {
+		model.SetTemperature(float32(g.cfg.GenerationTemperature))
+	}
+	if g.cfg.GenerationMaxOutputTokens > 0 {
+		model.SetMaxOutputTokens(int32(g.cfg.GenerationMaxOutputTokens))
+	}
+	return model
+}
+
+func (g *geminiGenerator) generate(ctx context.Context, req generateRequest) (string, error) {
+	model := g.model(req)
+	if req.jsonReply || req.schema != nil {
+		model.ResponseMIMEType = "application/json"
+		model.ResponseSchema = req.schema
+	}
+
+	var parts []genai.Part
+	if req.image != nil {
+		parts = append(parts, genai.Blob{MIMEType: req.imageType, Data: req.image})
+	}
+	parts = append(parts, genai.Text(req.prompt))
+
+	result, err := model.GenerateContent(ctx, parts...)
+	if err != nil {
+		return "", err
+	}
+	return firstTextPart(result)
+}
+
+func (g *geminiGenerator) generateStream(ctx context.Context, req generateRequest, onChunk func(string) error) (string, error) {
+	iter := g.model(req).GenerateContentStream(ctx, genai.Text(req.prompt))
+
+	var reply strings.Builder
+	for {
+		resp, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return reply.String(), fmt.Errorf("failed to stream answer: %w", err)
+		}
+
+		chunk, err := streamChunkText(resp)
+		if err != nil {
+			return reply.String(), err
+		}
+		if chunk == "" {
+			continue
+		}
+
+		reply.WriteString(chunk)
+		if err := onChunk(chunk); err != nil {
+			return reply.String(), err
+		}
+	}
+
+	return reply.String(), nil
+}
+
+// firstTextPart returns the text of the first candidate's first part
+// Empty candidates that were stopped by a content policy are reported as ErrContentBlocked
+func firstTextPart(result *genai.GenerateContentResponse) (string, error) {
+	if result == nil || len(result.Candidates) == 0 {
+		return "", fmt.Errorf("no response generated")
+	}
+
+	candidate := result.Candidates[0]
+	if candidate.Content == nil || len(candidate.Content.Parts) == 0 {
+		if isBlockedFinishReason(candidate.FinishReason) {
+			return "", fmt.Errorf("%w: finish reason %s", ErrContentBlocked, candidate.FinishReason)
+		}
+		return "", fmt.Errorf("no response generated")
+	}
+
+	text, ok := candidate.Content.Parts[0].(genai.Text)
+	if !ok {
+		return "", fmt.Errorf("unexpected response part type %T", candidate.Content.Parts[0])
+	}
+
+	return string(text), nil
+}
+
+// streamChunkText concatenates the text parts of a streamed response chunk
+// Chunks without text (e.g. the final one carrying only a finish reason) return ""
+func streamChunkText(resp *genai.GenerateContentResponse) (string, error) {
+	if resp == nil || len(resp.Candidates) == 0 {
+		return "", nil
+	}
+
+	candidate := resp.Candidates[0]
+	if isBlockedFinishReason(candidate.FinishReason) {
+		return "", fmt.Errorf("%w: finish reason %s", ErrContentBlocked, candidate.FinishReason)
+	}
+	if candidate.Content == nil {
+		return "", nil
+	}
+
+	var text strings.Builder
+	for _, part := range candidate.Content.Parts {
+		if t, ok := part.(genai.Text); ok {
+			text.WriteString(string(t))
+		}
+	}
+	return text.String(), nil
+}
@@ -2,67 +2,125 @@ package ai
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
+	"slices"
 	"strings"
-
-	"github.com/google/generative-ai-go/genai"
+	"time"
 
 	"backend/internal/config"
 	"backend/internal/models"
+	"backend/internal/utils"
 )
 
+// PromptVersions number the wording of the prompts whose results are kept in a note's AI history
+// Bump an operation's version whenever its prompt changes, so results can be told apart by prompt.
+var PromptVersions = map[string]int{
+	models.AIOperationAnalysis:       1,
+	models.AIOperationClassification: 1,
+	models.AIOperationTitle:          1,
+	models.AIOperationSummary:        1,
+	models.AIOperationSummaryPatch:   1,
+	models.AIOperationTranslation:    1,
+}
+
 // ClassifyNote classifies a note into one of the predefined categories
-func (c *AIClient) ClassifyNote(title, content string) (string, error) {
+// Returns the category and the model's confidence (0-1) in that choice
+func (c *AIClient) ClassifyNote(title, content string, examples []models.ClassificationExample) (string, float64, error) {
 	prompt := fmt.Sprintf(`
 Classify this note into exactly ONE of these categories: %s
-
+%s
 Note Title: %s
 Note Content: %s
 
 Rules:
-1. Return ONLY the category name, nothing else
-2. Choose the MOST relevant category
-3. If uncertain, use "other"
-4. Be consistent with similar content
+1. Choose the MOST relevant category
+2. If uncertain, use "other"
+3. Be consistent with similar content
+4. "confidence" is a number between 0 and 1 describing how certain you are
 
-Category:`, strings.Join(config.CATEGORIES, ", "), title, content)
+Return this JSON structure:
+{"category": "category-name", "confidence": 0.9}`, strings.Join(config.Categories(), ", "), formatClassificationExamples(examples), title, content)
 
-	ctx := context.Background()
-	model := c.GenerativeModel(config.GENERATION_MODEL)
-	result, err := model.GenerateContent(ctx, genai.Text(prompt))
+	reply, err := c.generate(generateRequest{operation: "ClassifyNote", prompt: prompt, schema: classificationSchema()})
 	if err != nil {
-		return "", fmt.Errorf("failed to generate classification: %w", err)
+		return "", 0, fmt.Errorf("failed to generate classification: %w", err)
 	}
 
-	categoryText, err := ExtractTextResponse(result)
-	if err != nil {
-		return "", fmt.Errorf("failed to extract classification: %w", err)
+	var classification struct {
+		Category   string  `json:"category"`
+		Confidence float64 `json:"confidence"`
+	}
+	if err := parseJSONReply(reply, &classification); err != nil {
+		return "", 0, fmt.Errorf("failed to extract classification: %w", err)
+	}
+
+	category := strings.ToLower(strings.TrimSpace(classification.Category))
+
+	// Validate category is in our list, otherwise return "other" with no confidence
+	if !config.IsValidCategory(category) {
+		return "other", 0, nil
 	}
 
-	category := strings.ToLower(categoryText)
+	return category, clampConfidence(classification.Confidence), nil
+}
 
-	// Validate category is in our list
-	if config.IsValidCategory(category) {
-		return category, nil
+// formatClassificationExamples renders confirmed classifications as few-shot examples for prompts
+func formatClassificationExamples(examples []models.ClassificationExample) string {
+	if len(examples) == 0 {
+		return ""
 	}
 
-	// If not found, return "other"
-	return "other", nil
+	var b strings.Builder
+	b.WriteString("\nExamples of notes whose category was confirmed by the user:\n")
+	for _, example := range examples {
+		b.WriteString(fmt.Sprintf("- Title: %s | Excerpt: %s => %s\n", example.Title, example.Excerpt, example.Category))
+	}
+	return b.String()
+}
+
+// clampConfidence keeps a model-reported confidence within 0-1
+func clampConfidence(confidence float64) float64 {
+	if confidence < 0 {
+		return 0
+	}
+	if confidence > 1 {
+		return 1
+	}
+	return confidence
+}
+
+// cleanSuggestedTags lowercases and hyphenates model-suggested tags, dropping blanks and duplicates
+func cleanSuggestedTags(tags []string) []string {
+	seen := make(map[string]bool)
+	cleaned := []string{}
+	for _, tag := range tags {
+		tag = strings.Join(strings.Fields(strings.ToLower(strings.Trim(tag, "#\"' "))), "-")
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		cleaned = append(cleaned, tag)
+		if len(cleaned) == config.MAX_SUGGESTED_TAGS {
+			break
+		}
+	}
+	return cleaned
 }
 
 // AnalyzeNote performs title generation, classification, and summary in a single API call
-func (c *AIClient) AnalyzeNote(content string, includeSummary bool) (*models.NoteAnalysis, error) {
-	// Get first 2000 characters for analysis to avoid token limits while keeping enough context
+func (c *AIClient) AnalyzeNote(content string, includeSummary bool, examples []models.ClassificationExample) (*models.NoteAnalysis, error) {
+	// Get the first ANALYSIS_CONTENT_CHARS characters for analysis to avoid token limits while keeping enough context
 	excerpt := content
-	if len(content) > 2000 {
-		excerpt = content[:2000] + "..."
+	if len(content) > config.ANALYSIS_CONTENT_CHARS {
+		excerpt = content[:config.ANALYSIS_CONTENT_CHARS] + "..."
 	}
 
 	summaryInstruction := ""
 	summaryField := `"summary": ""`
 	if includeSummary {
-		summaryInstruction = `4. "summary": A concise summary (2-4 sentences) capturing the key points and main takeaways`
+		summaryInstruction = `6. "summary": A concise summary (2-4 sentences) capturing the key points and main takeaways`
 		summaryField = `"summary": "your summary here"`
 	}
 
@@ -71,33 +129,39 @@ func (c *AIClient) AnalyzeNote(content string, includeSummary bool) (*models.Not
 1. "title": A concise, descriptive title (2-10 words, no quotes or special formatting)
 2. "category": Exactly ONE category from this list: %s
 3. Choose the MOST relevant category. If uncertain, use "other"
+4. "confidence": A number between 0 and 1 describing how certain you are about the category
+5. "tags": 1-%d short lowercase topical tags (e.g. "kubernetes", "sourdough", "stoicism"); use hyphens instead of spaces
+%s
 %s
-
-IMPORTANT: Return ONLY valid JSON, no markdown formatting, no code blocks, just the raw JSON object.
-
 Content to analyze:
 %s
 
 Return this exact JSON structure:
-{"title": "your title here", "category": "category-name", %s}`,
-		strings.Join(config.CATEGORIES, ", "),
+{"title": "your title here", "category": "category-name", "confidence": 0.9, "tags": ["tag-one", "tag-two"], %s}`,
+		strings.Join(config.Categories(), ", "),
+		config.MAX_SUGGESTED_TAGS,
 		summaryInstruction,
+		formatClassificationExamples(examples),
 		excerpt,
 		summaryField)
 
-	ctx := context.Background()
-	model := c.GenerativeModel(config.GENERATION_MODEL)
-	result, err := model.GenerateContent(ctx, genai.Text(prompt))
+	reply, err := c.generate(generateRequest{operation: "AnalyzeNote", prompt: prompt, schema: analysisSchema(includeSummary)})
 	if err != nil {
 		return nil, fmt.Errorf("failed to analyze note: %w", err)
 	}
 
 	var analysis models.NoteAnalysis
-	if err := ExtractJSONResponse(result, &analysis); err != nil {
+	if err := parseJSONReply(reply, &analysis); err != nil {
 		log.Printf("Failed to extract analysis JSON: %v", err)
 		return nil, fmt.Errorf("failed to parse analysis response: %w", err)
 	}
 
+	cleanAnalysis(&analysis)
+	return &analysis, nil
+}
+
+// cleanAnalysis validates the title, category and tags the model returned
+func cleanAnalysis(analysis *models.NoteAnalysis) {
 	// Validate and clean up title
 	analysis.Title = strings.Trim(analysis.Title, "\"'")
 	if len(analysis.Title) > 100 {
@@ -109,47 +173,291 @@ Return this exact JSON structure:
 
 	// Validate category
 	analysis.Category = strings.ToLower(strings.TrimSpace(analysis.Category))
+	analysis.Confidence = clampConfidence(analysis.Confidence)
 	if !config.IsValidCategory(analysis.Category) {
 		analysis.Category = "other"
+		analysis.Confidence = 0
 	}
 
-	return &analysis, nil
+	analysis.Tags = cleanSuggestedTags(analysis.Tags)
 }
 
-// GenerateAnswer generates an answer to a question based on provided context
-func (c *AIClient) GenerateAnswer(question, contextText string) (string, error) {
-	prompt := fmt.Sprintf(`You are an AI assistant helping someone understand their personal notes. Based on the provided context from their notes, answer their question in a helpful and conversational way.
+// AnalyzeNotes generates titles, categories and tags for several short notes in a single API call
+// The result has one entry per note in the same order; entries the model left out or mangled are
+// nil, so the caller can analyze those notes on their own. No summaries are generated.
+func (c *AIClient) AnalyzeNotes(contents []string, examples []models.ClassificationExample) ([]*models.NoteAnalysis, error) {
+	type batchItem struct {
+		Index   int    `json:"index"`
+		Content string `json:"content"`
+	}
+	items := make([]batchItem, len(contents))
+	for i, content := range contents {
+		if len(content) > config.ANALYSIS_BATCH_MAX_CHARS {
+			content = content[:config.ANALYSIS_BATCH_MAX_CHARS] + "..."
+		}
+		items[i] = batchItem{Index: i, Content: content}
+	}
+	input, err := json.Marshal(items)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode notes: %w", err)
+	}
 
-Context from their notes:
+	prompt := fmt.Sprintf(`Analyze each note in the JSON array below independently and return a JSON array with one object per note:
+
+1. "index": The index of the note, copied from the input
+2. "title": A concise, descriptive title (2-10 words, no quotes or special formatting)
+3. "category": Exactly ONE category from this list: %s
+4. Choose the MOST relevant category. If uncertain, use "other"
+5. "confidence": A number between 0 and 1 describing how certain you are about the category
+6. "tags": 1-%d short lowercase topical tags (e.g. "kubernetes", "sourdough", "stoicism"); use hyphens instead of spaces
+%s
+IMPORTANT: Return ONLY a valid JSON array, no markdown formatting, no code blocks. Never merge notes or skip one.
+
+Notes to analyze:
 %s
 
+Return this exact JSON structure:
+[{"index": 0, "title": "your title here", "category": "category-name", "confidence": 0.9, "tags": ["tag-one", "tag-two"]}]`,
+		strings.Join(config.Categories(), ", "),
+		config.MAX_SUGGESTED_TAGS,
+		formatClassificationExamples(examples),
+		input)
+
+	reply, err := c.generate(generateRequest{operation: "AnalyzeNotes", prompt: prompt})
+	if err != nil {
+		return nil, fmt.Errorf("failed to analyze notes: %w", err)
+	}
+
+	var response []struct {
+		Index *int `json:"index"`
+		models.NoteAnalysis
+	}
+	if err := parseJSONReply(reply, &response); err != nil {
+		log.Printf("Failed to extract batch analysis JSON: %v", err)
+		return nil, fmt.Errorf("failed to parse batch analysis response: %w", err)
+	}
+
+	analyses := make([]*models.NoteAnalysis, len(contents))
+	for _, entry := range response {
+		if entry.Index == nil || *entry.Index < 0 || *entry.Index >= len(contents) || analyses[*entry.Index] != nil {
+			continue
+		}
+		analysis := entry.NoteAnalysis
+		analysis.Summary = ""
+		cleanAnalysis(&analysis)
+		analyses[*entry.Index] = &analysis
+	}
+	return analyses, nil
+}
+
+// DefaultAnswerInstructions introduces the Q&A assistant unless a persona replaces it
+const DefaultAnswerInstructions = "You are an AI assistant helping someone understand their personal notes. Based on the provided context from their notes, answer their question in a helpful and conversational way."
+
+// answerRules are the built-in Q&A instructions; a persona's rules are appended after them
+var answerRules = []string{
+	"Answer based ONLY on the information provided in the context",
+	"Be conversational and helpful",
+	"If the context doesn't contain enough information, say so politely",
+	`Reference specific notes when relevant (e.g., "According to your note about...")`,
+	"Keep the answer concise but complete",
+	`If the question refers back to the conversation (e.g., "what about the second one?"), resolve it using the earlier turns`,
+}
+
+// DefaultAnswerSchema is the structure of JSON answers when the caller doesn't give one
+const DefaultAnswerSchema = `{"answer": "string", "keyPoints": ["string"], "sourceTitles": ["string"]}`
+
+// answerFormatRules lay out answers in the markdown formats; text and json answers add no rule
+var answerFormatRules = map[string]string{
+	models.AnswerFormatBullets: "Format the answer as a markdown bulleted list with one point per bullet and no introduction",
+	models.AnswerFormatTable:   "Format the answer as a markdown table with a header row, choosing columns that fit the question",
+}
+
+// multiHopCitationRule asks answers built from several retrieval hops to show how the notes connect
+const multiHopCitationRule = "The context was gathered in several steps, each headed by the search that found it. Cite the title of every note your answer relies on, in the order the reasoning follows them"
+
+// WithAnswerFormat returns the persona with the rule for laying out answers in format added,
+// leaving the given persona unchanged. A nil persona stands for the default one.
+func WithAnswerFormat(persona *models.AssistantPersona, format string) *models.AssistantPersona {
+	rule, ok := answerFormatRules[format]
+	if !ok {
+		return persona
+	}
+	return withRule(persona, rule)
+}
+
+// WithMultiHopCitations returns the persona with the rule for citing notes found across retrieval hops added,
+// leaving the given persona unchanged. A nil persona stands for the default one.
+func WithMultiHopCitations(persona *models.AssistantPersona) *models.AssistantPersona {
+	return withRule(persona, multiHopCitationRule)
+}
+
+// withRule returns a copy of persona (or of the default persona when nil) with rule appended
+func withRule(persona *models.AssistantPersona, rule string) *models.AssistantPersona {
+	extended := models.AssistantPersona{}
+	if persona != nil {
+		extended = *persona
+	}
+	extended.Rules = append(slices.Clip(extended.Rules), rule)
+	return &extended
+}
+
+// answerPrompt builds the Q&A prompt from the question, retrieved note context and any prior conversation turns
+// A nil persona uses the default introduction and only the built-in rules.
+func answerPrompt(question, contextText string, history []models.ConversationMessage, persona *models.AssistantPersona) string {
+	intro := DefaultAnswerInstructions
+	rules := answerRules
+	if persona != nil {
+		if persona.Instructions != "" {
+			intro = persona.Instructions
+		}
+		rules = append(slices.Clip(rules), persona.Rules...)
+	}
+
+	var instructions strings.Builder
+	for i, rule := range rules {
+		fmt.Fprintf(&instructions, "%d. %s\n", i+1, rule)
+	}
+
+	var conversation strings.Builder
+	if len(history) > 0 {
+		conversation.WriteString("\nConversation so far:\n")
+		for _, msg := range history {
+			speaker := "User"
+			if msg.Role == models.ConversationRoleAssistant {
+				speaker = "Assistant"
+			}
+			conversation.WriteString(fmt.Sprintf("%s: %s\n", speaker, msg.Content))
+		}
+	}
+
+	return fmt.Sprintf(`%s
+
+Context from their notes:
+%s
+%s
 Question: %s
 
 Instructions:
-1. Answer based ONLY on the information provided in the context
-2. Be conversational and helpful
-3. If the context doesn't contain enough information, say so politely
-4. Reference specific notes when relevant (e.g., "According to your note about...")
-5. Keep the answer concise but complete
-
-Answer:`, contextText, question)
+%s
+Answer:`, intro, contextText, conversation.String(), question, instructions.String())
+}
 
-	ctx := context.Background()
-	model := c.GenerativeModel(config.GENERATION_MODEL)
-	result, err := model.GenerateContent(ctx, genai.Text(prompt))
+// GenerateAnswer generates an answer to a question based on provided context
+// history holds prior conversation turns (oldest first) and may be nil for one-off questions;
+// persona customizes the assistant and may be nil for the default
+func (c *AIClient) GenerateAnswer(question, contextText string, history []models.ConversationMessage, persona *models.AssistantPersona) (string, error) {
+	reply, err := c.generate(generateRequest{operation: "GenerateAnswer", prompt: answerPrompt(question, contextText, history, persona), answer: true})
 	if err != nil {
 		return "", fmt.Errorf("failed to generate answer: %w", err)
 	}
 
-	return ExtractTextResponse(result)
+	return reply, nil
+}
+
+// GenerateStructuredAnswer generates an answer like GenerateAnswer as JSON following schema, an example
+// of the structure (DefaultAnswerSchema when empty). Returns the "answer" field, or the raw JSON when
+// the schema has none, along with the parsed JSON.
+func (c *AIClient) GenerateStructuredAnswer(question, contextText string, persona *models.AssistantPersona, schema string) (string, map[string]interface{}, error) {
+	if schema == "" {
+		schema = DefaultAnswerSchema
+	}
+
+	structured := models.AssistantPersona{}
+	if persona != nil {
+		structured = *persona
+	}
+	structured.Rules = append(slices.Clip(structured.Rules),
+		fmt.Sprintf("Respond with valid JSON matching this exact structure: %s", schema),
+		"Return ONLY valid JSON, no markdown formatting, no code blocks")
+
+	reply, err := c.generate(generateRequest{operation: "GenerateStructuredAnswer", prompt: answerPrompt(question, contextText, nil, &structured), answer: true, jsonReply: true})
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate structured answer: %w", err)
+	}
+
+	var data map[string]interface{}
+	if err := parseJSONReply(reply, &data); err != nil {
+		return "", nil, fmt.Errorf("failed to generate structured answer: %w", err)
+	}
+
+	if answer, ok := data["answer"].(string); ok {
+		return answer, data, nil
+	}
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate structured answer: %w", err)
+	}
+	return string(raw), data, nil
+}
+
+// GenerateAnswerStream generates an answer like GenerateAnswer but passes each text chunk
+// to onChunk as the model produces it. Returns the full answer once the stream completes.
+// An error from onChunk (e.g. the client disconnected) stops the stream.
+func (c *AIClient) GenerateAnswerStream(ctx context.Context, question, contextText string, persona *models.AssistantPersona, onChunk func(string) error) (string, error) {
+	// A failed stream is only retried before any of the answer has been passed on
+	req := generateRequest{operation: "GenerateAnswerStream", prompt: answerPrompt(question, contextText, nil, persona), answer: true}
+	start := time.Now()
+	var answer string
+	err := c.resilience.do(ctx, func() (err error) {
+		answer, err = c.generator.generateStream(ctx, req, onChunk)
+		return err
+	}, func() bool { return answer == "" })
+	c.sample(req, answer, err, start)
+	if err != nil {
+		return answer, err
+	}
+	return strings.TrimSpace(answer), nil
+}
+
+// ProposeFollowUpQueries suggests searches for what the context is still missing to answer a question
+// that chains facts across notes, e.g. finding the author named in one note and then what they wrote
+// elsewhere. previous lists the searches already run so they aren't repeated. Returns no queries when
+// the context already answers the question.
+func (c *AIClient) ProposeFollowUpQueries(question, contextText string, previous []string) ([]string, error) {
+	prompt := fmt.Sprintf(`You are gathering notes to answer a question. Some notes have been found so far. Decide whether they are enough to answer the question; if not, propose up to 2 short search queries for the missing facts, using names, titles or terms mentioned in the notes found so far.
+
+Question: %s
+
+Searches already run:
+%s
+Notes found so far:
+%s
+IMPORTANT: Return ONLY valid JSON, no markdown formatting, no code blocks, just the raw JSON object.
+Return an empty list when the notes already answer the question or nothing more could be searched for.
+
+Return this exact JSON structure:
+{"queries": ["search query"]}`,
+		question,
+		"- "+strings.Join(previous, "\n- ")+"\n",
+		contextText)
+
+	reply, err := c.generate(generateRequest{operation: "ProposeFollowUpQueries", prompt: prompt})
+	if err != nil {
+		return nil, fmt.Errorf("failed to propose follow-up queries: %w", err)
+	}
+
+	var proposal struct {
+		Queries []string `json:"queries"`
+	}
+	if err := parseJSONReply(reply, &proposal); err != nil {
+		return nil, fmt.Errorf("failed to parse follow-up queries: %w", err)
+	}
+
+	var queries []string
+	for _, query := range proposal.Queries {
+		if query = strings.TrimSpace(query); query != "" && !slices.Contains(previous, query) {
+			queries = append(queries, query)
+		}
+	}
+	return queries, nil
 }
 
 // GenerateTitle generates a concise, descriptive title for note content
 func (c *AIClient) GenerateTitle(content string) (string, error) {
-	// Get first 500 characters for title generation to avoid token limits
+	// Get first characters for title generation to avoid token limits
 	excerpt := content
-	if len(content) > 500 {
-		excerpt = content[:500] + "..."
+	if len(content) > config.TITLE_CONTENT_CHARS {
+		excerpt = content[:config.TITLE_CONTENT_CHARS] + "..."
 	}
 
 	prompt := fmt.Sprintf(`Generate a concise, descriptive title for this note content. The title should:
@@ -165,20 +473,13 @@ Content:
 
 Title:`, excerpt)
 
-	ctx := context.Background()
-	model := c.GenerativeModel(config.GENERATION_MODEL)
-	result, err := model.GenerateContent(ctx, genai.Text(prompt))
+	reply, err := c.generate(generateRequest{operation: "GenerateTitle", prompt: prompt})
 	if err != nil {
 		return "", fmt.Errorf("failed to generate title: %w", err)
 	}
 
-	title, err := ExtractTextResponse(result)
-	if err != nil {
-		return "", fmt.Errorf("failed to extract title: %w", err)
-	}
-
 	// Clean up the title (remove quotes, ensure reasonable length)
-	title = strings.Trim(title, "\"'")
+	title := strings.Trim(reply, "\"'")
 	if len(title) > 100 {
 		title = title[:100]
 	}
@@ -227,14 +528,81 @@ Content to summarize:
 Summary:`, content)
 	}
 
-	ctx := context.Background()
-	model := c.GenerativeModel(config.GENERATION_MODEL)
-	result, err := model.GenerateContent(ctx, genai.Text(prompt))
+	reply, err := c.generate(generateRequest{operation: "GenerateSummaryWithPrompt", prompt: prompt})
 	if err != nil {
 		return "", fmt.Errorf("failed to generate summary: %w", err)
 	}
 
-	return ExtractTextResponse(result)
+	return reply, nil
+}
+
+// PatchSummary updates a summary for a small edit of the content it summarizes, given as a diff of
+// "- " removed and "+ " added sentences, changing only what the edit affects so the summary stays stable
+func (c *AIClient) PatchSummary(summary, diff string) (string, error) {
+	prompt := fmt.Sprintf(`The content of a note was edited slightly, e.g. to correct a transcript. Below is the summary of the
+note before the edit and the edit itself: lines starting with "-" were removed from the content, lines
+starting with "+" were added in their place.
+
+Update the summary for the edit with as few changes as possible:
+1. Correct anything in the summary that the edit changes (names, numbers, facts, wording that was corrected)
+2. Add a point only if the edit adds something a summary of the note would mention
+3. Leave everything the edit doesn't affect exactly as it is, including its format, style and length
+4. If the edit doesn't affect the summary, return it unchanged
+
+Return only the updated summary, no explanation.
+
+Summary:
+%s
+
+Edit:
+%s
+Updated summary:`, summary, diff)
+
+	reply, err := c.generate(generateRequest{operation: "PatchSummary", prompt: prompt})
+	if err != nil {
+		return "", fmt.Errorf("failed to patch summary: %w", err)
+	}
+	if strings.TrimSpace(reply) == "" {
+		return "", fmt.Errorf("failed to patch summary: empty reply")
+	}
+	return reply, nil
+}
+
+// TranslateNote translates a note's content and summary into the language with the given code,
+// e.g. "fr" or "pt-br"; an empty summary is left empty
+func (c *AIClient) TranslateNote(content, summary, language string) (*models.NoteTranslation, error) {
+	prompt := fmt.Sprintf(`Translate the note below into the language with the code %q.
+
+Rules:
+1. Translate the content and the summary completely and faithfully, without summarizing or adding anything
+2. Keep the formatting: paragraphs, line breaks, lists and markdown
+3. Leave names, URLs, code and quotes in their original form
+4. If the summary is empty, return an empty summary
+
+IMPORTANT: Return ONLY valid JSON, no markdown formatting, no code blocks, just the raw JSON object.
+
+Content:
+%s
+
+Summary:
+%s
+
+Return this exact JSON structure:
+{"content": "translated content", "summary": "translated summary"}`, language, content, summary)
+
+	reply, err := c.generate(generateRequest{operation: "TranslateNote", prompt: prompt, jsonReply: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to translate note: %w", err)
+	}
+
+	var translation models.NoteTranslation
+	if err := parseJSONReply(reply, &translation); err != nil {
+		return nil, fmt.Errorf("failed to parse translation response: %w", err)
+	}
+	if strings.TrimSpace(translation.Content) == "" {
+		return nil, fmt.Errorf("failed to translate note: empty content")
+	}
+	return &translation, nil
 }
 
 // GenerateStructuredSummary generates a summary with structured data based on a schema
@@ -248,34 +616,31 @@ func (c *AIClient) GenerateStructuredSummary(content, promptText, promptSchema s
 		return summary, nil, nil
 	}
 
-	// Build prompt that requests JSON output matching the schema
+	// Build prompt that requests JSON output matching the schema; the reply is also constrained to
+	// the structure of the example, when it can be expressed as a response schema
 	prompt := fmt.Sprintf(`%s
 
-You MUST respond with valid JSON matching this exact structure:
+You MUST respond with JSON matching this exact structure:
 %s
 
 IMPORTANT:
-- Return ONLY valid JSON, no markdown formatting, no code blocks
 - The "summary" field must always be included as a string
 - Follow the schema structure exactly
 
 Content to analyze:
 %s`, promptText, promptSchema, content)
 
-	ctx := context.Background()
-	model := c.GenerativeModel(config.GENERATION_MODEL)
-	result, err := model.GenerateContent(ctx, genai.Text(prompt))
+	reply, err := c.generate(generateRequest{operation: "GenerateStructuredSummary", prompt: prompt, jsonReply: true, schema: exampleSchema(promptSchema)})
 	if err != nil {
 		return "", nil, fmt.Errorf("failed to generate structured summary: %w", err)
 	}
 
 	// Parse the JSON response
 	var structuredData map[string]interface{}
-	if err := ExtractJSONResponse(result, &structuredData); err != nil {
+	if err := parseJSONReply(reply, &structuredData); err != nil {
 		log.Printf("Failed to parse structured summary JSON: %v", err)
 		// Fall back to treating the response as plain text summary
-		responseText, _ := ExtractTextResponse(result)
-		return responseText, nil, nil
+		return reply, nil, nil
 	}
 
 	// Extract summary field
@@ -289,6 +654,302 @@ Content to analyze:
 	return summary, structuredData, nil
 }
 
+// ExtractMetadata finds the author, source, publication date and URL of pasted content
+func (c *AIClient) ExtractMetadata(content string) (*models.ExtractedMetadata, error) {
+	excerpt := content
+	if len(content) > 4000 {
+		excerpt = content[:4000] + "..."
+	}
+
+	prompt := fmt.Sprintf(`This note was pasted without any information about where it came from. Read it and return a JSON object with:
+
+- "author": the person or channel who wrote or said it
+- "source": the publication, website, podcast or show it comes from
+- "publishedDate": the date it was published as YYYY-MM-DD
+- "url": the address of the original, only if it appears in the note
+
+Use "" for anything the note doesn't clearly state; do not guess.
+
+IMPORTANT: Return ONLY valid JSON, no markdown formatting, no code blocks, just the raw JSON object.
+
+Note:
+%s
+
+Return this exact JSON structure:
+{"author": "", "source": "", "publishedDate": "", "url": ""}`, excerpt)
+
+	reply, err := c.generate(generateRequest{operation: "ExtractMetadata", prompt: prompt})
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract metadata: %w", err)
+	}
+
+	var extracted models.ExtractedMetadata
+	if err := parseJSONReply(reply, &extracted); err != nil {
+		return nil, fmt.Errorf("failed to parse metadata response: %w", err)
+	}
+	return &extracted, nil
+}
+
+// ExtractEntities finds the people, organizations, places and tools a note mentions
+// Entities of other types, or without a name, are dropped.
+func (c *AIClient) ExtractEntities(content string) ([]models.NoteEntity, error) {
+	excerpt := content
+	if len(content) > 4000 {
+		excerpt = content[:4000] + "..."
+	}
+
+	prompt := fmt.Sprintf(`Read this note and list the named entities it mentions. Return a JSON object with:
+
+- "entities": a list of the people, organizations, places and tools named in the note. Tools are software, frameworks, programming languages, products and instruments. "name" is the entity's full name as written and "type" is one of "person", "organization", "place" or "tool". List each entity once.
+
+IMPORTANT: Return ONLY valid JSON, no markdown formatting, no code blocks, just the raw JSON object.
+
+Note:
+%s
+
+Return this exact JSON structure:
+{"entities": [{"name": "Ada Lovelace", "type": "person"}]}`, excerpt)
+
+	reply, err := c.generate(generateRequest{operation: "ExtractEntities", prompt: prompt})
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract entities: %w", err)
+	}
+
+	var response struct {
+		Entities []models.NoteEntity `json:"entities"`
+	}
+	if err := parseJSONReply(reply, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse entities response: %w", err)
+	}
+
+	var entities []models.NoteEntity
+	for _, entity := range response.Entities {
+		entity.Name = strings.TrimSpace(entity.Name)
+		entity.Type = strings.ToLower(strings.TrimSpace(entity.Type))
+		if entity.Name != "" && slices.Contains(models.EntityTypes, entity.Type) {
+			entities = append(entities, entity)
+		}
+	}
+	return entities, nil
+}
+
+// ExtractGoals finds goals set in a note and progress reported on the active goals
+// New goals are only extracted when allowNew is set; updates refer to activeGoals by 1-based position.
+// Relative target dates ("by June") are resolved against today's date.
+func (c *AIClient) ExtractGoals(content string, activeGoals []string, allowNew bool) (*models.GoalExtraction, error) {
+	excerpt := content
+	if len(content) > 4000 {
+		excerpt = content[:4000] + "..."
+	}
+
+	goalsInstruction := `"goals": always an empty list`
+	if allowNew {
+		goalsInstruction = `"goals": Goals this note sets. "title" is a short statement of the goal; "targetDate" is the date it should be reached by as YYYY-MM-DD, or "" if none is given`
+	}
+
+	var active strings.Builder
+	for i, goal := range activeGoals {
+		fmt.Fprintf(&active, "%d. %s\n", i+1, goal)
+	}
+	if active.Len() == 0 {
+		active.WriteString("(none)\n")
+	}
+
+	prompt := fmt.Sprintf(`Today is %s. Read this note and return a JSON object with two fields:
+
+1. %s
+2. "updates": Progress the note reports on the active goals below. "goal" is the goal's number, "status" is one of "active" (still in progress), "achieved" or "abandoned", and "summary" is one sentence describing the progress. Only include goals the note clearly talks about.
+
+Active goals:
+%s
+IMPORTANT: Return ONLY valid JSON, no markdown formatting, no code blocks, just the raw JSON object.
+
+Note:
+%s
+
+Return this exact JSON structure:
+{"goals": [{"title": "goal", "targetDate": "2025-12-31"}], "updates": [{"goal": 1, "status": "active", "summary": "progress"}]}`,
+		time.Now().Format("2006-01-02"),
+		goalsInstruction,
+		active.String(),
+		excerpt)
+
+	reply, err := c.generate(generateRequest{operation: "ExtractGoals", prompt: prompt})
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract goals: %w", err)
+	}
+
+	var extraction models.GoalExtraction
+	if err := parseJSONReply(reply, &extraction); err != nil {
+		return nil, fmt.Errorf("failed to parse goals response: %w", err)
+	}
+	if !allowNew {
+		extraction.Goals = nil
+	}
+	return &extraction, nil
+}
+
+// CompareNotes judges whether a newer note supersedes or contradicts an older note on the same subject
+func (c *AIClient) CompareNotes(older, newer string) (*models.NoteComparison, error) {
+	excerpt := func(content string) string {
+		if len(content) > 3000 {
+			return content[:3000] + "..."
+		}
+		return content
+	}
+
+	prompt := fmt.Sprintf(`Compare an older note with a newer note and decide how the newer one relates to it.
+
+Answer with one relation:
+- "supersedes": the newer note replaces what the older one describes, e.g. a new API version, a changed procedure or a deprecated approach
+- "contradicts": the newer note states something that conflicts with the older one
+- "consistent": the notes cover the same subject and both still hold
+- "unrelated": the notes are about different things
+
+IMPORTANT: Return ONLY valid JSON, no markdown formatting, no code blocks, just the raw JSON object.
+
+Older note:
+%s
+
+Newer note:
+%s
+
+Return this exact JSON structure:
+{"relation": "supersedes", "reason": "one sentence explaining what changed"}`,
+		excerpt(older),
+		excerpt(newer))
+
+	reply, err := c.generate(generateRequest{operation: "CompareNotes", prompt: prompt})
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare notes: %w", err)
+	}
+
+	var comparison models.NoteComparison
+	if err := parseJSONReply(reply, &comparison); err != nil {
+		return nil, fmt.Errorf("failed to parse comparison response: %w", err)
+	}
+	return &comparison, nil
+}
+
+// InferChannelPrompt proposes a prompt and JSON schema for summarizing notes like the samples,
+// which come from one channel. The schema is returned as a compact JSON object with a summary field.
+func (c *AIClient) InferChannelPrompt(samples []string) (string, string, error) {
+	var notes strings.Builder
+	for i, sample := range samples {
+		fmt.Fprintf(&notes, "--- Note %d ---\n%s\n\n", i+1, utils.TruncateWords(sample, config.SCHEMA_SAMPLE_NOTE_CHARS))
+	}
+
+	prompt := fmt.Sprintf(`These notes all come from the same channel (a YouTube channel, podcast, newsletter, author or similar). Design the instructions and JSON output structure an AI should use to summarize any note from this channel.
+
+Rules:
+1. "promptText": 1-3 sentences of instructions naming what matters in this kind of content, e.g. guests and takeaways for interviews, steps for tutorials, tickers and figures for market news
+2. "promptSchema": a JSON object showing the output structure, with a short description or example as each value
+3. The schema must include a "summary" string field; add 2-5 other fields that every note of the channel can fill
+4. Prefer lists of short strings; avoid deeply nested objects
+
+IMPORTANT: Return ONLY valid JSON, no markdown formatting, no code blocks, just the raw JSON object.
+
+Notes:
+%s
+Return this exact JSON structure:
+{"promptText": "instructions", "promptSchema": {"summary": "2-3 sentence summary", "field": ["description"]}}`, notes.String())
+
+	reply, err := c.generate(generateRequest{operation: "InferChannelPrompt", prompt: prompt, jsonReply: true})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to infer channel prompt: %w", err)
+	}
+
+	var suggestion struct {
+		PromptText   string                 `json:"promptText"`
+		PromptSchema map[string]interface{} `json:"promptSchema"`
+	}
+	if err := parseJSONReply(reply, &suggestion); err != nil {
+		return "", "", fmt.Errorf("failed to parse channel prompt response: %w", err)
+	}
+	if len(suggestion.PromptSchema) == 0 {
+		return "", "", fmt.Errorf("failed to infer channel prompt: no schema returned")
+	}
+	if _, ok := suggestion.PromptSchema["summary"]; !ok {
+		suggestion.PromptSchema["summary"] = "2-3 sentence summary"
+	}
+
+	schema, err := json.Marshal(suggestion.PromptSchema)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to infer channel prompt: %w", err)
+	}
+	return strings.TrimSpace(suggestion.PromptText), string(schema), nil
+}
+
+// NameTopics names the topic of each group of notes, given as the titles of notes in the group
+// Returns a name per group, in order; a group the reply leaves out gets an empty name.
+func (c *AIClient) NameTopics(groups [][]string) ([]string, error) {
+	var b strings.Builder
+	for i, titles := range groups {
+		fmt.Fprintf(&b, "--- Group %d ---\n- %s\n\n", i+1, strings.Join(titles, "\n- "))
+	}
+
+	prompt := fmt.Sprintf(`These are groups of notes clustered by similarity, each listed by the titles of its most typical notes. Name the topic each group shares.
+
+Rules:
+1. 1-4 words per name, in title case, e.g. "Kubernetes Deployments" or "Sourdough Baking"
+2. Name what the notes have in common, not any single note
+3. Give every group a different name
+4. Return exactly %d names, in the order of the groups
+
+Groups:
+%s
+Return this JSON structure:
+{"topics": ["Topic Name"]}`, len(groups), b.String())
+
+	reply, err := c.generate(generateRequest{operation: "NameTopics", prompt: prompt, schema: topicsSchema()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to name topics: %w", err)
+	}
+
+	var naming struct {
+		Topics []string `json:"topics"`
+	}
+	if err := parseJSONReply(reply, &naming); err != nil {
+		return nil, fmt.Errorf("failed to parse topic names: %w", err)
+	}
+
+	names := make([]string, len(groups))
+	for i := range names {
+		if i < len(naming.Topics) {
+			names[i] = strings.TrimSpace(naming.Topics[i])
+		}
+	}
+	return names, nil
+}
+
+// ExtractImage reads the text off an image, such as a whiteboard photo or a screenshot, and describes it
+func (c *AIClient) ExtractImage(data []byte, mimeType string) (*models.ImageExtraction, error) {
+	prompt := `Extract the content of this image so it can be saved as a searchable note.
+
+Rules:
+1. "text": transcribe all text visible in the image as written, keeping line breaks and list structure; use "" if there is none
+2. For handwriting or whiteboards, transcribe your best reading and mark illegible words as [illegible]
+3. "description": 1-3 sentences describing what the image shows (e.g. a whiteboard diagram of a service architecture, a screenshot of an error dialog)
+
+IMPORTANT: Return ONLY valid JSON, no markdown formatting, no code blocks, just the raw JSON object.
+
+Return this exact JSON structure:
+{"text": "transcribed text", "description": "what the image shows"}`
+
+	reply, err := c.generate(generateRequest{operation: "ExtractImage", prompt: prompt, image: data, imageType: mimeType})
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract image content: %w", err)
+	}
+
+	var extraction models.ImageExtraction
+	if err := parseJSONReply(reply, &extraction); err != nil {
+		return nil, fmt.Errorf("failed to parse image extraction response: %w", err)
+	}
+	extraction.Text = strings.TrimSpace(extraction.Text)
+	extraction.Description = strings.TrimSpace(extraction.Description)
+	return &extraction, nil
+}
+
 // AskAboutContent asks the AI a question about specific content
 func (c *AIClient) AskAboutContent(prompt, content string) (string, error) {
 	fullPrompt := fmt.Sprintf(`%s
@@ -296,12 +957,10 @@ func (c *AIClient) AskAboutContent(prompt, content string) (string, error) {
 Content to analyze:
 %s`, prompt, content)
 
-	ctx := context.Background()
-	model := c.GenerativeModel(config.GENERATION_MODEL)
-	result, err := model.GenerateContent(ctx, genai.Text(fullPrompt))
+	reply, err := c.generate(generateRequest{operation: "AskAboutContent", prompt: fullPrompt, answer: true})
 	if err != nil {
 		return "", fmt.Errorf("failed to generate AI response: %w", err)
 	}
 
-	return ExtractTextResponse(result)
+	return reply, nil
 }
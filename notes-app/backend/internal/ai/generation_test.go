@@ -0,0 +1,121 @@
+package ai
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"backend/internal/config"
+)
+
+// stubGenerator replies to every request with a fixed reply, keeping the prompts it was sent
+type stubGenerator struct {
+	reply   string
+	prompts []string
+}
+
+func (g *stubGenerator) generate(ctx context.Context, req generateRequest) (string, error) {
+	g.prompts = append(g.prompts, req.prompt)
+	return g.reply, nil
+}
+
+func (g *stubGenerator) generateStream(ctx context.Context, req generateRequest, onChunk func(string) error) (string, error) {
+	reply, err := g.generate(ctx, req)
+	if err == nil {
+		err = onChunk(reply)
+	}
+	return reply, err
+}
+
+// stubClient returns an AI client whose generation model always replies with reply
+func stubClient(reply string) (*AIClient, *stubGenerator) {
+	generator := &stubGenerator{reply: reply}
+	return &AIClient{generator: generator, resilience: &resilience{}, cfg: &config.Config{}}, generator
+}
+
+func TestAnalyzeNotes(t *testing.T) {
+	contents := []string{"Knead the dough", "Simmer the stock", "Check the tides", "Water the plants"}
+
+	t.Run("matches entries to notes by index", func(t *testing.T) {
+		client, generator := stubClient("```json\n" + `[
+			{"index": 2, "title": "Tides", "category": "travel-plans", "confidence": 0.8, "tags": ["Beach Walks"]},
+			{"index": 0, "title": "\"Bread\"", "category": " Recipes ", "confidence": 1.5, "tags": [], "summary": "Not asked for"},
+			{"index": 1, "title": "Soup", "category": "not-a-category", "confidence": 0.9, "tags": []},
+			{"index": 3, "title": "", "category": "hobbies", "confidence": 0.7, "tags": []}
+		]` + "\n```")
+
+		analyses, err := client.AnalyzeNotes(contents, nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if len(analyses) != len(contents) {
+			t.Fatalf("Expected %d analyses, got %d", len(contents), len(analyses))
+		}
+		for i, want := range contents {
+			if !strings.Contains(generator.prompts[0], want) {
+				t.Errorf("Expected note %d in the prompt", i)
+			}
+		}
+
+		if a := analyses[0]; a.Title != "Bread" || a.Category != "recipes" || a.Confidence != 1 || a.Summary != "" {
+			t.Errorf("Expected the first entry cleaned, got %+v", a)
+		}
+		if a := analyses[1]; a.Category != "other" || a.Confidence != 0 {
+			t.Errorf("Expected an unknown category to fall back to other, got %+v", a)
+		}
+		if a := analyses[2]; a.Title != "Tides" || a.Category != "travel-plans" || len(a.Tags) != 1 || a.Tags[0] != "beach-walks" {
+			t.Errorf("Expected the out of order entry at its index, got %+v", a)
+		}
+		if a := analyses[3]; a.Title != "Untitled Note" {
+			t.Errorf("Expected an empty title replaced, got %q", a.Title)
+		}
+	})
+
+	t.Run("leaves notes with a missing, duplicate or out of range entry nil", func(t *testing.T) {
+		client, _ := stubClient(`[
+			{"index": 0, "title": "First", "category": "recipes", "confidence": 0.9, "tags": []},
+			{"index": 0, "title": "Duplicate", "category": "journal", "confidence": 0.9, "tags": []},
+			{"title": "No index", "category": "journal", "confidence": 0.9, "tags": []},
+			{"index": 4, "title": "Out of range", "category": "journal", "confidence": 0.9, "tags": []},
+			{"index": -1, "title": "Negative", "category": "journal", "confidence": 0.9, "tags": []},
+			{"index": 3, "title": "Last", "category": "hobbies", "confidence": 0.9, "tags": []}
+		]`)
+
+		analyses, err := client.AnalyzeNotes(contents, nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if analyses[0] == nil || analyses[0].Title != "First" {
+			t.Errorf("Expected the first entry for a duplicated index kept, got %+v", analyses[0])
+		}
+		if analyses[1] != nil || analyses[2] != nil {
+			t.Errorf("Expected the notes without a valid entry nil, got %+v and %+v", analyses[1], analyses[2])
+		}
+		if analyses[3] == nil || analyses[3].Title != "Last" {
+			t.Errorf("Expected the last note analyzed, got %+v", analyses[3])
+		}
+	})
+
+	t.Run("fails on a reply that isn't a JSON array", func(t *testing.T) {
+		client, _ := stubClient(`{"index": 0, "title": "Not an array"}`)
+		if _, err := client.AnalyzeNotes(contents, nil); err == nil {
+			t.Error("Expected an error for a malformed reply")
+		}
+	})
+
+	t.Run("shortens long notes in the prompt", func(t *testing.T) {
+		client, generator := stubClient(`[]`)
+		long := strings.Repeat("a", config.ANALYSIS_BATCH_MAX_CHARS+500)
+
+		analyses, err := client.AnalyzeNotes([]string{long}, nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if analyses[0] != nil {
+			t.Errorf("Expected no analysis from an empty reply, got %+v", analyses[0])
+		}
+		if strings.Contains(generator.prompts[0], long) || !strings.Contains(generator.prompts[0], long[:config.ANALYSIS_BATCH_MAX_CHARS]+"...") {
+			t.Error("Expected the note cut to ANALYSIS_BATCH_MAX_CHARS in the prompt")
+		}
+	})
+}
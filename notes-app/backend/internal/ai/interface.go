@@ -1,6 +1,10 @@
 package ai
 
-import "backend/internal/models"
+import (
+	"context"
+
+	"backend/internal/models"
+)
 
 // Client defines the interface for AI operations
 // This allows for mocking in tests
@@ -9,17 +13,31 @@ type Client interface {
 	Close() error
 
 	// Generation methods
-	AnalyzeNote(content string, includeSummary bool) (*models.NoteAnalysis, error)
-	ClassifyNote(title, content string) (string, error)
+	AnalyzeNote(content string, includeSummary bool, examples []models.ClassificationExample) (*models.NoteAnalysis, error)
+	AnalyzeNotes(contents []string, examples []models.ClassificationExample) ([]*models.NoteAnalysis, error)
+	ClassifyNote(title, content string, examples []models.ClassificationExample) (string, float64, error)
 	GenerateTitle(content string) (string, error)
 	GenerateSummary(content string) (string, error)
 	GenerateSummaryWithPrompt(content string, customPrompt string) (string, error)
 	GenerateStructuredSummary(content, promptText, promptSchema string) (string, map[string]interface{}, error)
-	GenerateAnswer(question, contextText string) (string, error)
+	PatchSummary(summary, diff string) (string, error)
+	TranslateNote(content, summary, language string) (*models.NoteTranslation, error)
+	GenerateAnswer(question, contextText string, history []models.ConversationMessage, persona *models.AssistantPersona) (string, error)
+	GenerateAnswerStream(ctx context.Context, question, contextText string, persona *models.AssistantPersona, onChunk func(string) error) (string, error)
+	GenerateStructuredAnswer(question, contextText string, persona *models.AssistantPersona, schema string) (string, map[string]interface{}, error)
+	ProposeFollowUpQueries(question, contextText string, previous []string) ([]string, error)
 	AskAboutContent(prompt, content string) (string, error)
+	ExtractMetadata(content string) (*models.ExtractedMetadata, error)
+	ExtractEntities(content string) ([]models.NoteEntity, error)
+	ExtractGoals(content string, activeGoals []string, allowNew bool) (*models.GoalExtraction, error)
+	CompareNotes(older, newer string) (*models.NoteComparison, error)
+	InferChannelPrompt(samples []string) (promptText, promptSchema string, err error)
+	NameTopics(groups [][]string) ([]string, error)
+	ExtractImage(data []byte, mimeType string) (*models.ImageExtraction, error)
 
 	// Embedding methods
 	GenerateEmbedding(text string) ([]float32, error)
+	GenerateEmbeddings(texts []string) ([][]float32, error)
 }
 
 // Ensure AIClient implements Client interface
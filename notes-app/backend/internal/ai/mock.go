@@ -1,7 +1,11 @@
 package ai
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"regexp"
+	"slices"
 	"strings"
 
 	"backend/internal/models"
@@ -10,14 +14,28 @@ import (
 // MockAIClient provides mock AI responses for testing
 type MockAIClient struct {
 	// Optional callbacks for custom behavior
-	AnalyzeNoteFunc             func(content string, includeSummary bool) (*models.NoteAnalysis, error)
-	ClassifyNoteFunc            func(title, content string) (string, error)
+	AnalyzeNoteFunc             func(content string, includeSummary bool, examples []models.ClassificationExample) (*models.NoteAnalysis, error)
+	AnalyzeNotesFunc            func(contents []string, examples []models.ClassificationExample) ([]*models.NoteAnalysis, error)
+	ClassifyNoteFunc            func(title, content string, examples []models.ClassificationExample) (string, float64, error)
 	GenerateTitleFunc           func(content string) (string, error)
 	GenerateSummaryFunc         func(content string) (string, error)
 	GenerateSummaryWithPromptFunc func(content, customPrompt string) (string, error)
 	GenerateStructuredSummaryFunc func(content, promptText, promptSchema string) (string, map[string]interface{}, error)
-	GenerateAnswerFunc          func(question, contextText string) (string, error)
+	PatchSummaryFunc            func(summary, diff string) (string, error)
+	TranslateNoteFunc           func(content, summary, language string) (*models.NoteTranslation, error)
+	GenerateAnswerFunc          func(question, contextText string, history []models.ConversationMessage, persona *models.AssistantPersona) (string, error)
+	GenerateAnswerStreamFunc    func(ctx context.Context, question, contextText string, persona *models.AssistantPersona, onChunk func(string) error) (string, error)
+	GenerateStructuredAnswerFunc func(question, contextText string, persona *models.AssistantPersona, schema string) (string, map[string]interface{}, error)
+	ProposeFollowUpQueriesFunc  func(question, contextText string, previous []string) ([]string, error)
 	GenerateEmbeddingFunc       func(text string) ([]float32, error)
+	GenerateEmbeddingsFunc      func(texts []string) ([][]float32, error)
+	ExtractMetadataFunc         func(content string) (*models.ExtractedMetadata, error)
+	ExtractEntitiesFunc         func(content string) ([]models.NoteEntity, error)
+	ExtractGoalsFunc            func(content string, activeGoals []string, allowNew bool) (*models.GoalExtraction, error)
+	CompareNotesFunc            func(older, newer string) (*models.NoteComparison, error)
+	InferChannelPromptFunc      func(samples []string) (string, string, error)
+	NameTopicsFunc              func(groups [][]string) ([]string, error)
+	ExtractImageFunc            func(data []byte, mimeType string) (*models.ImageExtraction, error)
 }
 
 // NewMockAIClient creates a new mock AI client with default behavior
@@ -34,17 +52,18 @@ func (m *MockAIClient) Close() error {
 }
 
 // AnalyzeNote returns a mock analysis
-func (m *MockAIClient) AnalyzeNote(content string, includeSummary bool) (*models.NoteAnalysis, error) {
+func (m *MockAIClient) AnalyzeNote(content string, includeSummary bool, examples []models.ClassificationExample) (*models.NoteAnalysis, error) {
 	if m.AnalyzeNoteFunc != nil {
-		return m.AnalyzeNoteFunc(content, includeSummary)
+		return m.AnalyzeNoteFunc(content, includeSummary, examples)
 	}
 
 	// Generate a simple title from first few words
 	title := generateMockTitle(content)
 
 	analysis := &models.NoteAnalysis{
-		Title:    title,
-		Category: "other",
+		Title:      title,
+		Category:   "other",
+		Confidence: 0.9,
 	}
 
 	if includeSummary {
@@ -54,12 +73,29 @@ func (m *MockAIClient) AnalyzeNote(content string, includeSummary bool) (*models
 	return analysis, nil
 }
 
+// AnalyzeNotes returns a mock analysis of each note, without summaries
+func (m *MockAIClient) AnalyzeNotes(contents []string, examples []models.ClassificationExample) ([]*models.NoteAnalysis, error) {
+	if m.AnalyzeNotesFunc != nil {
+		return m.AnalyzeNotesFunc(contents, examples)
+	}
+
+	analyses := make([]*models.NoteAnalysis, len(contents))
+	for i, content := range contents {
+		analysis, err := m.AnalyzeNote(content, false, examples)
+		if err != nil {
+			return nil, err
+		}
+		analyses[i] = analysis
+	}
+	return analyses, nil
+}
+
 // ClassifyNote returns a mock classification
-func (m *MockAIClient) ClassifyNote(title, content string) (string, error) {
+func (m *MockAIClient) ClassifyNote(title, content string, examples []models.ClassificationExample) (string, float64, error) {
 	if m.ClassifyNoteFunc != nil {
-		return m.ClassifyNoteFunc(title, content)
+		return m.ClassifyNoteFunc(title, content, examples)
 	}
-	return "other", nil
+	return "other", 0.9, nil
 }
 
 // GenerateTitle returns a mock title
@@ -100,12 +136,118 @@ func (m *MockAIClient) GenerateStructuredSummary(content, promptText, promptSche
 	return summary, structuredData, nil
 }
 
-// GenerateAnswer returns a mock answer
-func (m *MockAIClient) GenerateAnswer(question, contextText string) (string, error) {
+// PatchSummary replaces the first removed sentence of each hunk found in the summary with the hunk's
+// added sentences, and drops its other removed sentences
+func (m *MockAIClient) PatchSummary(summary, diff string) (string, error) {
+	if m.PatchSummaryFunc != nil {
+		return m.PatchSummaryFunc(summary, diff)
+	}
+
+	for _, hunk := range strings.Split(diff, "\n\n") {
+		var removed, added []string
+		for _, line := range strings.Split(hunk, "\n") {
+			if strings.HasPrefix(line, "- ") {
+				removed = append(removed, line[2:])
+			} else if strings.HasPrefix(line, "+ ") {
+				added = append(added, line[2:])
+			}
+		}
+		for i, sentence := range removed {
+			replacement := ""
+			if i == 0 {
+				replacement = strings.Join(added, " ")
+			}
+			summary = strings.Replace(summary, sentence, replacement, 1)
+		}
+	}
+	return strings.Join(strings.Fields(summary), " "), nil
+}
+
+// TranslateNote prefixes the content and summary with the language code in brackets, e.g. "[fr] "
+func (m *MockAIClient) TranslateNote(content, summary, language string) (*models.NoteTranslation, error) {
+	if m.TranslateNoteFunc != nil {
+		return m.TranslateNoteFunc(content, summary, language)
+	}
+
+	translation := &models.NoteTranslation{Content: "[" + language + "] " + content}
+	if summary != "" {
+		translation.Summary = "[" + language + "] " + summary
+	}
+	return translation, nil
+}
+
+// GenerateAnswer returns a mock answer, prefixed with the persona's instructions when one is set
+func (m *MockAIClient) GenerateAnswer(question, contextText string, history []models.ConversationMessage, persona *models.AssistantPersona) (string, error) {
 	if m.GenerateAnswerFunc != nil {
-		return m.GenerateAnswerFunc(question, contextText)
+		return m.GenerateAnswerFunc(question, contextText, history, persona)
 	}
-	return fmt.Sprintf("Based on your notes, here is information related to: %s", question), nil
+	answer := fmt.Sprintf("Based on your notes, here is information related to: %s", question)
+	if persona != nil && persona.Instructions != "" {
+		answer = fmt.Sprintf("[%s] %s", persona.Instructions, answer)
+	}
+	return answer, nil
+}
+
+// GenerateStructuredAnswer returns the mock answer with every other top-level schema field left empty
+func (m *MockAIClient) GenerateStructuredAnswer(question, contextText string, persona *models.AssistantPersona, schema string) (string, map[string]interface{}, error) {
+	if m.GenerateStructuredAnswerFunc != nil {
+		return m.GenerateStructuredAnswerFunc(question, contextText, persona, schema)
+	}
+
+	answer, err := m.GenerateAnswer(question, contextText, nil, persona)
+	if err != nil {
+		return "", nil, err
+	}
+
+	data := map[string]interface{}{}
+	if schema != "" {
+		if err := json.Unmarshal([]byte(schema), &data); err != nil {
+			return "", nil, err
+		}
+		for key := range data {
+			data[key] = nil
+		}
+	}
+	data["answer"] = answer
+	return answer, data, nil
+}
+
+// ProposeFollowUpQueries returns the text after each "See also:" line in the context that hasn't been searched yet
+func (m *MockAIClient) ProposeFollowUpQueries(question, contextText string, previous []string) ([]string, error) {
+	if m.ProposeFollowUpQueriesFunc != nil {
+		return m.ProposeFollowUpQueriesFunc(question, contextText, previous)
+	}
+
+	var queries []string
+	for _, line := range strings.Split(contextText, "\n") {
+		query, ok := strings.CutPrefix(strings.TrimSpace(line), "See also:")
+		if query = strings.TrimSpace(query); ok && query != "" && !slices.Contains(previous, query) && !slices.Contains(queries, query) {
+			queries = append(queries, query)
+		}
+	}
+	return queries, nil
+}
+
+// GenerateAnswerStream streams the mock answer word by word
+func (m *MockAIClient) GenerateAnswerStream(ctx context.Context, question, contextText string, persona *models.AssistantPersona, onChunk func(string) error) (string, error) {
+	if m.GenerateAnswerStreamFunc != nil {
+		return m.GenerateAnswerStreamFunc(ctx, question, contextText, persona, onChunk)
+	}
+
+	answer, err := m.GenerateAnswer(question, contextText, nil, persona)
+	if err != nil {
+		return "", err
+	}
+
+	for i, word := range strings.Fields(answer) {
+		if i > 0 {
+			word = " " + word
+		}
+		if err := onChunk(word); err != nil {
+			return answer, err
+		}
+	}
+	return answer, nil
 }
 
 // GenerateEmbedding returns a mock embedding vector
@@ -123,11 +265,178 @@ func (m *MockAIClient) GenerateEmbedding(text string) ([]float32, error) {
 	return embedding, nil
 }
 
+// GenerateEmbeddings returns the mock embedding of each text
+func (m *MockAIClient) GenerateEmbeddings(texts []string) ([][]float32, error) {
+	if m.GenerateEmbeddingsFunc != nil {
+		return m.GenerateEmbeddingsFunc(texts)
+	}
+
+	var embeddings [][]float32
+	for _, text := range texts {
+		embedding, err := m.GenerateEmbedding(text)
+		if err != nil {
+			return nil, err
+		}
+		embeddings = append(embeddings, embedding)
+	}
+	return embeddings, nil
+}
+
 // AskAboutContent returns a mock response about content
 func (m *MockAIClient) AskAboutContent(prompt, content string) (string, error) {
 	return fmt.Sprintf("Response to: %s (based on content of length %d)", prompt, len(content)), nil
 }
 
+// ExtractMetadata reads "By"/"Author:" and "Source:" lines, the first YYYY-MM-DD date and the first URL
+func (m *MockAIClient) ExtractMetadata(content string) (*models.ExtractedMetadata, error) {
+	if m.ExtractMetadataFunc != nil {
+		return m.ExtractMetadataFunc(content)
+	}
+
+	extracted := &models.ExtractedMetadata{
+		PublishedDate: mockDatePattern.FindString(content),
+		URL:           mockURLPattern.FindString(content),
+	}
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		lower := strings.ToLower(line)
+		switch {
+		case strings.HasPrefix(lower, "by "):
+			extracted.Author = strings.TrimSpace(line[len("by "):])
+		case strings.HasPrefix(lower, "author:"):
+			extracted.Author = strings.TrimSpace(line[len("author:"):])
+		case strings.HasPrefix(lower, "source:"):
+			extracted.Source = strings.TrimSpace(line[len("source:"):])
+		}
+	}
+	return extracted, nil
+}
+
+// ExtractEntities returns an entity for each "Person:", "Organization:", "Place:" and "Tool:" line
+func (m *MockAIClient) ExtractEntities(content string) ([]models.NoteEntity, error) {
+	if m.ExtractEntitiesFunc != nil {
+		return m.ExtractEntitiesFunc(content)
+	}
+
+	var entities []models.NoteEntity
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		for _, entityType := range models.EntityTypes {
+			if strings.HasPrefix(strings.ToLower(line), entityType+":") {
+				entities = append(entities, models.NoteEntity{Name: strings.TrimSpace(line[len(entityType)+1:]), Type: entityType})
+			}
+		}
+	}
+	return entities, nil
+}
+
+// ExtractGoals returns a goal for each "Goal:" line (with any YYYY-MM-DD on it as the target date)
+// and an update for each active goal whose title appears in the content
+func (m *MockAIClient) ExtractGoals(content string, activeGoals []string, allowNew bool) (*models.GoalExtraction, error) {
+	if m.ExtractGoalsFunc != nil {
+		return m.ExtractGoalsFunc(content, activeGoals, allowNew)
+	}
+
+	extraction := &models.GoalExtraction{}
+	if allowNew {
+		for _, line := range strings.Split(content, "\n") {
+			line = strings.TrimSpace(line)
+			if !strings.HasPrefix(strings.ToLower(line), "goal:") {
+				continue
+			}
+			title := strings.TrimSpace(line[len("goal:"):])
+			goal := models.ExtractedGoal{Title: title}
+			if date := mockDatePattern.FindString(title); date != "" {
+				goal.TargetDate = date
+				goal.Title = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(strings.Replace(title, date, "", 1)), "by"))
+			}
+			extraction.Goals = append(extraction.Goals, goal)
+		}
+	}
+
+	lower := strings.ToLower(content)
+	for i, goal := range activeGoals {
+		if !strings.Contains(lower, strings.ToLower(goal)) {
+			continue
+		}
+		status := models.GoalStatusActive
+		if strings.Contains(lower, "achieved") || strings.Contains(lower, "completed") {
+			status = models.GoalStatusAchieved
+		} else if strings.Contains(lower, "gave up") || strings.Contains(lower, "abandoned") {
+			status = models.GoalStatusAbandoned
+		}
+		extraction.Updates = append(extraction.Updates, models.ExtractedGoalUpdate{
+			Goal:    i + 1,
+			Status:  status,
+			Summary: generateMockSummary(content),
+		})
+	}
+	return extraction, nil
+}
+
+// CompareNotes returns "supersedes" when the newer note mentions a deprecation or replacement,
+// "contradicts" when it says something no longer holds, and "consistent" otherwise
+func (m *MockAIClient) CompareNotes(older, newer string) (*models.NoteComparison, error) {
+	if m.CompareNotesFunc != nil {
+		return m.CompareNotesFunc(older, newer)
+	}
+
+	lower := strings.ToLower(newer)
+	switch {
+	case strings.Contains(lower, "deprecated") || strings.Contains(lower, "replaced"):
+		return &models.NoteComparison{Relation: models.NoteRelationSupersedes, Reason: "Mock: newer note replaces the older one"}, nil
+	case strings.Contains(lower, "no longer"):
+		return &models.NoteComparison{Relation: models.NoteRelationContradicts, Reason: "Mock: newer note contradicts the older one"}, nil
+	}
+	return &models.NoteComparison{Relation: models.NoteRelationConsistent, Reason: "Mock: notes agree"}, nil
+}
+
+// InferChannelPrompt returns a fixed prompt and a schema with a summary and topics
+func (m *MockAIClient) InferChannelPrompt(samples []string) (string, string, error) {
+	if m.InferChannelPromptFunc != nil {
+		return m.InferChannelPromptFunc(samples)
+	}
+
+	return fmt.Sprintf("Mock: summarize notes like these %d samples", len(samples)),
+		`{"summary":"2-3 sentence summary","topics":["topic"]}`, nil
+}
+
+// NameTopics names each group after the first word of its first title
+func (m *MockAIClient) NameTopics(groups [][]string) ([]string, error) {
+	if m.NameTopicsFunc != nil {
+		return m.NameTopicsFunc(groups)
+	}
+
+	names := make([]string, len(groups))
+	for i, titles := range groups {
+		names[i] = fmt.Sprintf("Mock Topic %d", i+1)
+		if len(titles) > 0 {
+			if words := strings.Fields(titles[0]); len(words) > 0 {
+				names[i] = "Mock " + words[0]
+			}
+		}
+	}
+	return names, nil
+}
+
+// ExtractImage returns a fixed transcription and a description naming the image type
+func (m *MockAIClient) ExtractImage(data []byte, mimeType string) (*models.ImageExtraction, error) {
+	if m.ExtractImageFunc != nil {
+		return m.ExtractImageFunc(data, mimeType)
+	}
+
+	return &models.ImageExtraction{
+		Text:        "Mock text read from the image",
+		Description: fmt.Sprintf("Mock description of a %s image", strings.TrimPrefix(mimeType, "image/")),
+	}, nil
+}
+
+// mockDatePattern finds YYYY-MM-DD dates in mock goal lines
+var mockDatePattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}`)
+
+// mockURLPattern finds URLs in mock metadata extraction
+var mockURLPattern = regexp.MustCompile(`https?://[^\s)]+`)
+
 // Helper functions for generating mock content
 
 func generateMockTitle(content string) string {
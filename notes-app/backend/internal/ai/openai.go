@@ -0,0 +1,193 @@
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"backend/internal/config"
+)
+
+// openAIGenerator generates replies with an OpenAI-compatible chat completions API, such as
+// OpenAI itself, vLLM or Ollama
+type openAIGenerator struct {
+	baseURL string
+	apiKey  string
+	cfg     *config.Config
+	client  *http.Client
+}
+
+// newOpenAIGenerator creates a generator for the configured OpenAI-compatible API
+func newOpenAIGenerator(cfg *config.Config) *openAIGenerator {
+	return &openAIGenerator{
+		baseURL: cfg.OpenAIURL,
+		apiKey:  cfg.OpenAIAPIKey,
+		cfg:     cfg,
+		client:  &http.Client{Timeout: config.OPENAI_TIMEOUT_SECONDS * time.Second},
+	}
+}
+
+// openAIChoice is a choice of a chat completion, or of one chunk of a streamed completion
+type openAIChoice struct {
+	Message struct {
+		Content string `json:"content"`
+	} `json:"message"`
+	Delta struct {
+		Content string `json:"content"`
+	} `json:"delta"`
+	FinishReason string `json:"finish_reason"`
+}
+
+func (g *openAIGenerator) generate(ctx context.Context, req generateRequest) (string, error) {
+	var content interface{} = req.prompt
+	if req.image != nil {
+		content = []map[string]interface{}{
+			{"type": "text", "text": req.prompt},
+			{"type": "image_url", "image_url": map[string]string{
+				"url": "data:" + req.imageType + ";base64," + base64.StdEncoding.EncodeToString(req.image),
+			}},
+		}
+	}
+	body := g.requestBody(req, content)
+	if req.schema != nil {
+		body["response_format"] = map[string]interface{}{
+			"type":        "json_schema",
+			"json_schema": map[string]interface{}{"name": "reply", "schema": jsonSchema(req.schema)},
+		}
+	} else if req.jsonReply {
+		body["response_format"] = map[string]string{"type": "json_object"}
+	}
+
+	resp, err := g.post(ctx, body)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Choices []openAIChoice `json:"choices"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to parse chat completion: %w", err)
+	}
+	if len(result.Choices) == 0 {
+		return "", fmt.Errorf("no response generated")
+	}
+
+	choice := result.Choices[0]
+	if choice.FinishReason == "content_filter" {
+		return "", fmt.Errorf("%w: finish reason %s", ErrContentBlocked, choice.FinishReason)
+	}
+	if choice.Message.Content == "" {
+		return "", fmt.Errorf("no response generated")
+	}
+	return choice.Message.Content, nil
+}
+
+func (g *openAIGenerator) generateStream(ctx context.Context, req generateRequest, onChunk func(string) error) (string, error) {
+	body := g.requestBody(req, req.prompt)
+	body["stream"] = true
+	resp, err := g.post(ctx, body)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	// The reply arrives as server-sent events, one "data: {chunk}" line each, ending with "data: [DONE]"
+	var reply strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk struct {
+			Choices []openAIChoice `json:"choices"`
+		}
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return reply.String(), fmt.Errorf("failed to stream answer: %w", err)
+		}
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+		if chunk.Choices[0].FinishReason == "content_filter" {
+			return reply.String(), fmt.Errorf("%w: finish reason %s", ErrContentBlocked, chunk.Choices[0].FinishReason)
+		}
+
+		text := chunk.Choices[0].Delta.Content
+		if text == "" {
+			continue
+		}
+		reply.WriteString(text)
+		if err := onChunk(text); err != nil {
+			return reply.String(), err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return reply.String(), fmt.Errorf("failed to stream answer: %w", err)
+	}
+
+	return reply.String(), nil
+}
+
+// requestBody returns a chat completions request for the given user message content, with the
+// configured model and generation parameters
+func (g *openAIGenerator) requestBody(req generateRequest, content interface{}) map[string]interface{} {
+	body := map[string]interface{}{
+		"model":    generationModel(g.cfg, req),
+		"messages": []map[string]interface{}{{"role": "user", "content": content}},
+	}
+	if g.cfg.GenerationTemperature >= 0 {
+		body["temperature"] = g.cfg.GenerationTemperature
+	}
+	if g.cfg.GenerationMaxOutputTokens > 0 {
+		body["max_tokens"] = g.cfg.GenerationMaxOutputTokens
+	}
+	return body
+}
+
+// post sends a request to the /chat/completions endpoint, returning the response when it succeeded
+func (g *openAIGenerator) post(ctx context.Context, body map[string]interface{}) (*http.Response, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.baseURL+"/chat/completions", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if g.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+g.apiKey)
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		resp.Body.Close()
+		return nil, ErrRateLimited
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("chat completions API returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	return resp, nil
+}
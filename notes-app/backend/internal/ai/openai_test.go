@@ -0,0 +1,242 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"backend/internal/config"
+)
+
+// openAITestServer serves handler as the /chat/completions endpoint, recording each request body
+func openAITestServer(t *testing.T, handler http.HandlerFunc) (*openAIGenerator, *[]map[string]interface{}) {
+	t.Helper()
+	var requests []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/chat/completions" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer test-key" {
+			t.Errorf("Expected the API key as bearer token, got %q", auth)
+		}
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Failed to decode request: %v", err)
+		}
+		requests = append(requests, body)
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := &config.Config{
+		OpenAIURL:                 server.URL,
+		OpenAIAPIKey:              "test-key",
+		GenerationModel:           "gen-model",
+		AnswerModel:               "answer-model",
+		GenerationTemperature:     0.2,
+		GenerationMaxOutputTokens: 256,
+	}
+	return newOpenAIGenerator(cfg), &requests
+}
+
+// completion writes a chat completion with a single choice
+func completion(w http.ResponseWriter, content, finishReason string) {
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{"choices":[{"message":{"role":"assistant","content":%q},"finish_reason":%q}]}`, content, finishReason)
+}
+
+func TestOpenAIGenerate(t *testing.T) {
+	t.Run("returns the completion", func(t *testing.T) {
+		g, requests := openAITestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			completion(w, "A reply", "stop")
+		})
+
+		reply, err := g.generate(context.Background(), generateRequest{prompt: "Hello"})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if reply != "A reply" {
+			t.Errorf("Expected 'A reply', got %q", reply)
+		}
+
+		body := (*requests)[0]
+		if body["model"] != "gen-model" {
+			t.Errorf("Expected the generation model, got %v", body["model"])
+		}
+		if body["temperature"] != 0.2 || body["max_tokens"] != float64(256) {
+			t.Errorf("Expected the generation parameters, got temperature %v and max_tokens %v", body["temperature"], body["max_tokens"])
+		}
+		messages := body["messages"].([]interface{})
+		if message := messages[0].(map[string]interface{}); message["role"] != "user" || message["content"] != "Hello" {
+			t.Errorf("Expected the prompt as user message, got %v", message)
+		}
+		if _, ok := body["response_format"]; ok {
+			t.Error("Expected no response format for a free text reply")
+		}
+	})
+
+	t.Run("answers with the answer model", func(t *testing.T) {
+		g, requests := openAITestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			completion(w, "An answer", "stop")
+		})
+
+		if _, err := g.generate(context.Background(), generateRequest{prompt: "Why?", answer: true}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if model := (*requests)[0]["model"]; model != "answer-model" {
+			t.Errorf("Expected the answer model, got %v", model)
+		}
+	})
+
+	t.Run("constrains the reply to the schema", func(t *testing.T) {
+		g, requests := openAITestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			completion(w, `{"topics":["go"]}`, "stop")
+		})
+
+		if _, err := g.generate(context.Background(), generateRequest{prompt: "Name topics", schema: topicsSchema()}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		format := (*requests)[0]["response_format"].(map[string]interface{})
+		if format["type"] != "json_schema" {
+			t.Fatalf("Expected a json_schema response format, got %v", format["type"])
+		}
+		schema := format["json_schema"].(map[string]interface{})["schema"].(map[string]interface{})
+		if schema["type"] != "object" {
+			t.Errorf("Expected an object schema, got %v", schema["type"])
+		}
+		topics := schema["properties"].(map[string]interface{})["topics"].(map[string]interface{})
+		if topics["type"] != "array" || topics["items"].(map[string]interface{})["type"] != "string" {
+			t.Errorf("Expected topics as an array of strings, got %v", topics)
+		}
+	})
+
+	t.Run("asks for a JSON object", func(t *testing.T) {
+		g, requests := openAITestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			completion(w, `{}`, "stop")
+		})
+
+		if _, err := g.generate(context.Background(), generateRequest{prompt: "JSON please", jsonReply: true}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		format := (*requests)[0]["response_format"].(map[string]interface{})
+		if format["type"] != "json_object" {
+			t.Errorf("Expected a json_object response format, got %v", format["type"])
+		}
+	})
+
+	t.Run("rate limit", func(t *testing.T) {
+		g, _ := openAITestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+		})
+
+		_, err := g.generate(context.Background(), generateRequest{prompt: "Hello"})
+		if !errors.Is(err, ErrRateLimited) {
+			t.Errorf("Expected ErrRateLimited, got %v", err)
+		}
+	})
+
+	t.Run("server error", func(t *testing.T) {
+		g, _ := openAITestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "overloaded", http.StatusServiceUnavailable)
+		})
+
+		_, err := g.generate(context.Background(), generateRequest{prompt: "Hello"})
+		if err == nil || !strings.Contains(err.Error(), "status 503") || !isRetryable(err) {
+			t.Errorf("Expected a retryable status 503 error, got %v", err)
+		}
+	})
+
+	t.Run("content filter", func(t *testing.T) {
+		g, _ := openAITestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			completion(w, "", "content_filter")
+		})
+
+		_, err := g.generate(context.Background(), generateRequest{prompt: "Hello"})
+		if !errors.Is(err, ErrContentBlocked) {
+			t.Errorf("Expected ErrContentBlocked, got %v", err)
+		}
+	})
+}
+
+func TestOpenAIGenerateStream(t *testing.T) {
+	t.Run("streams until done", func(t *testing.T) {
+		g, requests := openAITestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"role\":\"assistant\"}}]}\n\n")
+			fmt.Fprint(w, ": keep-alive\n\n")
+			fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"Hello\"}}]}\n\n")
+			fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\", world\"}}]}\n\n")
+			fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{},\"finish_reason\":\"stop\"}]}\n\n")
+			fmt.Fprint(w, "data: [DONE]\n\n")
+			fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\" after done\"}}]}\n\n")
+		})
+
+		var chunks []string
+		reply, err := g.generateStream(context.Background(), generateRequest{prompt: "Hi", answer: true}, func(chunk string) error {
+			chunks = append(chunks, chunk)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if reply != "Hello, world" {
+			t.Errorf("Expected 'Hello, world', got %q", reply)
+		}
+		if len(chunks) != 2 || chunks[0] != "Hello" || chunks[1] != ", world" {
+			t.Errorf("Expected the two content chunks, got %q", chunks)
+		}
+		if body := (*requests)[0]; body["stream"] != true || body["model"] != "answer-model" {
+			t.Errorf("Expected a streamed request to the answer model, got stream %v and model %v", body["stream"], body["model"])
+		}
+	})
+
+	t.Run("content filter", func(t *testing.T) {
+		g, _ := openAITestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"Part\"}}]}\n\n")
+			fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{},\"finish_reason\":\"content_filter\"}]}\n\n")
+		})
+
+		reply, err := g.generateStream(context.Background(), generateRequest{prompt: "Hi"}, func(string) error { return nil })
+		if !errors.Is(err, ErrContentBlocked) {
+			t.Errorf("Expected ErrContentBlocked, got %v", err)
+		}
+		if reply != "Part" {
+			t.Errorf("Expected the reply so far, got %q", reply)
+		}
+	})
+
+	t.Run("stops when the consumer fails", func(t *testing.T) {
+		g, _ := openAITestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"One\"}}]}\n\n")
+			fmt.Fprint(w, "data: {\"choices\":[{\"delta\":{\"content\":\"Two\"}}]}\n\n")
+			fmt.Fprint(w, "data: [DONE]\n\n")
+		})
+
+		consumerErr := errors.New("client went away")
+		calls := 0
+		_, err := g.generateStream(context.Background(), generateRequest{prompt: "Hi"}, func(string) error {
+			calls++
+			return consumerErr
+		})
+		if !errors.Is(err, consumerErr) || calls != 1 {
+			t.Errorf("Expected the stream to stop at the consumer error, got %d chunks and %v", calls, err)
+		}
+	})
+
+	t.Run("rate limit", func(t *testing.T) {
+		g, _ := openAITestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTooManyRequests)
+		})
+
+		_, err := g.generateStream(context.Background(), generateRequest{prompt: "Hi"}, func(string) error { return nil })
+		if !errors.Is(err, ErrRateLimited) {
+			t.Errorf("Expected ErrRateLimited, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,189 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"backend/internal/config"
+)
+
+// ErrCircuitOpen is returned without calling the API while the circuit breaker is open after
+// repeated failures. It wraps ErrRateLimited, so callers that back off on rate limits do here too.
+var ErrCircuitOpen = fmt.Errorf("%w: AI API circuit open after repeated failures", ErrRateLimited)
+
+// resilience wraps every AI API call: calls are spaced to respect the request quota, rate-limited
+// and transient failures are retried with exponential backoff, and after repeated failures the
+// circuit opens and calls fail fast until a cooldown has passed.
+// It is shared by all callers of an AIClient, so bulk operations and live requests draw on one quota.
+type resilience struct {
+	interval   time.Duration // Minimum gap between calls; 0 doesn't throttle
+	maxRetries int
+
+	mu       sync.Mutex
+	next     time.Time // When the next call may start
+	failures int       // Consecutive calls that failed after their retries
+	openedAt time.Time // When the circuit opened; zero while closed
+	probing  bool      // A half-open probe call is in flight; only that call clears it
+}
+
+// newResilience creates the call wrapper for the configured quota and retries
+func newResilience(cfg *config.Config) *resilience {
+	r := &resilience{maxRetries: max(cfg.AIMaxRetries, 0)}
+	if cfg.AIRequestsPerMinute > 0 {
+		r.interval = time.Minute / time.Duration(cfg.AIRequestsPerMinute)
+	}
+	return r
+}
+
+// do runs call through the circuit breaker, rate limiter and retries
+// retry reports whether a failed attempt may be retried; it lets streaming calls stop retrying
+// once part of the reply has been delivered.
+func (r *resilience) do(ctx context.Context, call func() error, retry func() bool) error {
+	probe, err := r.allow()
+	if err != nil {
+		return err
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err = r.wait(ctx); err != nil {
+			break
+		}
+		err = call()
+		if err == nil || !isRetryable(err) || attempt >= r.maxRetries || (retry != nil && !retry()) {
+			break
+		}
+
+		backoff := retryBackoff(attempt)
+		log.Printf("AI API call failed (attempt %d of %d), retrying in %s: %v", attempt+1, r.maxRetries+1, backoff, err)
+		if err = sleepContext(ctx, backoff); err != nil {
+			break
+		}
+	}
+
+	r.record(ctx, err, probe)
+	return err
+}
+
+// allow fails fast while the circuit is open; once the cooldown has passed it lets a single
+// probe call through, reporting it as the probe, whose outcome closes or reopens the circuit
+func (r *resilience) allow() (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.openedAt.IsZero() {
+		return false, nil
+	}
+	if r.probing || time.Since(r.openedAt) < config.AI_BREAKER_COOLDOWN_SECONDS*time.Second {
+		return false, ErrCircuitOpen
+	}
+	r.probing = true
+	return true, nil
+}
+
+// record counts a call's outcome towards the circuit breaker; probe is what allow reported for it,
+// so a call that started before the circuit opened can't end the probe of another
+// Only failures the API is to blame for count: a call the API answered, even with an error such
+// as blocked content, shows it is up, and a cancelled call shows nothing.
+func (r *resilience) record(ctx context.Context, err error, probe bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if probe {
+		r.probing = false
+	}
+
+	if ctx.Err() != nil {
+		return
+	}
+	if err == nil || !isRetryable(err) {
+		if !r.openedAt.IsZero() {
+			log.Printf("AI API recovered, closing the circuit")
+		}
+		r.failures = 0
+		r.openedAt = time.Time{}
+		return
+	}
+
+	r.failures++
+	if r.failures >= config.AI_BREAKER_FAILURES {
+		if r.openedAt.IsZero() {
+			log.Printf("AI API failed %d calls in a row, opening the circuit for %ds: %v", r.failures, config.AI_BREAKER_COOLDOWN_SECONDS, err)
+		}
+		r.openedAt = time.Now()
+	}
+}
+
+// wait blocks until the call may start under the request quota
+func (r *resilience) wait(ctx context.Context) error {
+	if r.interval == 0 {
+		return ctx.Err()
+	}
+
+	r.mu.Lock()
+	now := time.Now()
+	start := r.next
+	if start.Before(now) {
+		start = now
+	}
+	r.next = start.Add(r.interval)
+	r.mu.Unlock()
+
+	return sleepContext(ctx, time.Until(start))
+}
+
+// retryBackoff returns the pause before retrying a call that failed its attempt-th try:
+// AI_RETRY_BACKOFF_MS doubling each retry, up to AI_MAX_RETRY_BACKOFF_SECONDS, with jitter so
+// callers that failed together don't retry together
+func retryBackoff(attempt int) time.Duration {
+	backoff := min(time.Duration(config.AI_RETRY_BACKOFF_MS)*time.Millisecond<<min(attempt, 16), config.AI_MAX_RETRY_BACKOFF_SECONDS*time.Second)
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
+
+// isRetryable reports whether a call failed for a reason that may pass: a rate limit, an
+// overloaded or unavailable API, or a network error
+func isRetryable(err error) bool {
+	if err == nil || errors.Is(err, ErrCircuitOpen) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if IsRateLimited(err) {
+		return true
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.Internal, codes.DeadlineExceeded:
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	for _, transient := range []string{"status 500", "status 502", "status 503", "status 504", "error 500", "error 502", "error 503", "error 504"} {
+		if strings.Contains(msg, transient) {
+			return true
+		}
+	}
+	return false
+}
+
+// sleepContext waits for d, returning early with the context's error once it is cancelled
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
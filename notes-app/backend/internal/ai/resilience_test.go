@@ -0,0 +1,177 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"backend/internal/config"
+)
+
+func TestResilienceThrottle(t *testing.T) {
+	r := &resilience{interval: 50 * time.Millisecond}
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := r.do(context.Background(), func() error { return nil }, nil); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	// The first call starts right away, each further one an interval after the previous
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("Expected 4 calls to take at least 150ms, took %s", elapsed)
+	}
+}
+
+func TestResilienceRetry(t *testing.T) {
+	for name, failure := range map[string]error{
+		"rate limited":    fmt.Errorf("%w: status 429", ErrRateLimited),
+		"quota exhausted": status.Error(codes.ResourceExhausted, "quota exceeded"),
+		"unavailable":     status.Error(codes.Unavailable, "overloaded"),
+		"server error":    errors.New("embedding API returned status 503"),
+	} {
+		t.Run(name+" is retried", func(t *testing.T) {
+			r := &resilience{maxRetries: 1}
+			calls := 0
+			err := r.do(context.Background(), func() error {
+				calls++
+				if calls == 1 {
+					return failure
+				}
+				return nil
+			}, nil)
+			if err != nil || calls != 2 {
+				t.Errorf("Expected a successful retry, got %d calls and %v", calls, err)
+			}
+		})
+	}
+
+	for name, failure := range map[string]error{
+		"invalid argument": status.Error(codes.InvalidArgument, "bad request"),
+		"blocked content":  ErrContentBlocked,
+		"other error":      errors.New("failed to parse response"),
+	} {
+		t.Run(name+" is not retried", func(t *testing.T) {
+			r := &resilience{maxRetries: 3}
+			calls := 0
+			err := r.do(context.Background(), func() error {
+				calls++
+				return failure
+			}, nil)
+			if !errors.Is(err, failure) || calls != 1 {
+				t.Errorf("Expected a single failed call, got %d calls and %v", calls, err)
+			}
+		})
+	}
+
+	t.Run("retries stop when the retry check says so", func(t *testing.T) {
+		r := &resilience{maxRetries: 3}
+		calls := 0
+		err := r.do(context.Background(), func() error {
+			calls++
+			return ErrRateLimited
+		}, func() bool { return false })
+		if !errors.Is(err, ErrRateLimited) || calls != 1 {
+			t.Errorf("Expected a single failed call, got %d calls and %v", calls, err)
+		}
+	})
+}
+
+func TestResilienceCircuitBreaker(t *testing.T) {
+	failing := func() error { return ErrRateLimited }
+
+	t.Run("opens after consecutive failures", func(t *testing.T) {
+		r := &resilience{}
+		for i := 0; i < config.AI_BREAKER_FAILURES; i++ {
+			if err := r.do(context.Background(), failing, nil); errors.Is(err, ErrCircuitOpen) {
+				t.Fatalf("Circuit opened after %d failures, expected %d", i, config.AI_BREAKER_FAILURES)
+			}
+		}
+
+		called := false
+		err := r.do(context.Background(), func() error { called = true; return nil }, nil)
+		if !errors.Is(err, ErrCircuitOpen) || called {
+			t.Errorf("Expected the call to fail fast, got %v (called %v)", err, called)
+		}
+		if !IsRateLimited(err) {
+			t.Error("Expected ErrCircuitOpen to count as rate limited")
+		}
+	})
+
+	t.Run("a success resets the failure count", func(t *testing.T) {
+		r := &resilience{}
+		for i := 0; i < config.AI_BREAKER_FAILURES-1; i++ {
+			r.do(context.Background(), failing, nil)
+		}
+		r.do(context.Background(), func() error { return nil }, nil)
+		r.do(context.Background(), failing, nil)
+		if err := r.do(context.Background(), func() error { return nil }, nil); err != nil {
+			t.Errorf("Expected the circuit closed, got %v", err)
+		}
+	})
+
+	t.Run("lets a single probe through after the cooldown", func(t *testing.T) {
+		r := &resilience{failures: config.AI_BREAKER_FAILURES, openedAt: time.Now().Add(-config.AI_BREAKER_COOLDOWN_SECONDS*time.Second - time.Second)}
+
+		release := make(chan struct{})
+		started := make(chan struct{})
+		var probes atomic.Int32
+		probeDone := make(chan error)
+		go func() {
+			probeDone <- r.do(context.Background(), func() error {
+				probes.Add(1)
+				close(started)
+				<-release
+				return nil
+			}, nil)
+		}()
+		<-started
+
+		if err := r.do(context.Background(), func() error { probes.Add(1); return nil }, nil); !errors.Is(err, ErrCircuitOpen) {
+			t.Errorf("Expected calls during the probe to fail fast, got %v", err)
+		}
+		close(release)
+		if err := <-probeDone; err != nil {
+			t.Fatalf("Unexpected probe error: %v", err)
+		}
+		if n := probes.Load(); n != 1 {
+			t.Errorf("Expected a single probe, got %d calls", n)
+		}
+		if err := r.do(context.Background(), func() error { return nil }, nil); err != nil {
+			t.Errorf("Expected the successful probe to close the circuit, got %v", err)
+		}
+	})
+
+	t.Run("a failed probe reopens the circuit", func(t *testing.T) {
+		r := &resilience{failures: config.AI_BREAKER_FAILURES, openedAt: time.Now().Add(-config.AI_BREAKER_COOLDOWN_SECONDS*time.Second - time.Second)}
+		if err := r.do(context.Background(), failing, nil); !errors.Is(err, ErrRateLimited) || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("Expected the probe to run and fail, got %v", err)
+		}
+		if err := r.do(context.Background(), func() error { return nil }, nil); !errors.Is(err, ErrCircuitOpen) {
+			t.Errorf("Expected the circuit open again, got %v", err)
+		}
+	})
+
+	t.Run("a call started before the circuit opened doesn't end the probe", func(t *testing.T) {
+		r := &resilience{failures: config.AI_BREAKER_FAILURES, openedAt: time.Now().Add(-config.AI_BREAKER_COOLDOWN_SECONDS*time.Second - time.Second)}
+		probe, err := r.allow()
+		if err != nil || !probe {
+			t.Fatalf("Expected the probe let through, got %v", err)
+		}
+
+		// The earlier call finishes cancelled while the probe is in flight
+		cancelled, cancel := context.WithCancel(context.Background())
+		cancel()
+		r.record(cancelled, context.Canceled, false)
+
+		if _, err := r.allow(); !errors.Is(err, ErrCircuitOpen) {
+			t.Errorf("Expected a second probe to be refused, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,72 @@
+package ai
+
+import (
+	"errors"
+	"log"
+
+	"github.com/google/generative-ai-go/genai"
+)
+
+// ErrContentBlocked is returned when Gemini refuses to generate a response
+// because the prompt or the candidate tripped a safety or content policy filter
+var ErrContentBlocked = errors.New("content blocked by safety filters")
+
+// safetyCategories lists the harm categories supported by Gemini models
+var safetyCategories = []genai.HarmCategory{
+	genai.HarmCategoryHarassment,
+	genai.HarmCategoryHateSpeech,
+	genai.HarmCategorySexuallyExplicit,
+	genai.HarmCategoryDangerousContent,
+}
+
+// parseSafetyThreshold maps a config value to a Gemini block threshold
+func parseSafetyThreshold(value string) (genai.HarmBlockThreshold, bool) {
+	switch value {
+	case "none", "block_none":
+		return genai.HarmBlockNone, true
+	case "only_high", "block_only_high":
+		return genai.HarmBlockOnlyHigh, true
+	case "medium_and_above", "block_medium_and_above":
+		return genai.HarmBlockMediumAndAbove, true
+	case "low_and_above", "block_low_and_above":
+		return genai.HarmBlockLowAndAbove, true
+	}
+	return genai.HarmBlockUnspecified, false
+}
+
+// buildSafetySettings creates safety settings applying the threshold to every harm category
+// Returns nil (Gemini defaults) when the threshold is empty or unknown
+func buildSafetySettings(threshold string) []*genai.SafetySetting {
+	if threshold == "" {
+		return nil
+	}
+
+	blockThreshold, ok := parseSafetyThreshold(threshold)
+	if !ok {
+		log.Printf("Unknown GEMINI_SAFETY_THRESHOLD %q, using Gemini defaults", threshold)
+		return nil
+	}
+
+	settings := make([]*genai.SafetySetting, 0, len(safetyCategories))
+	for _, category := range safetyCategories {
+		settings = append(settings, &genai.SafetySetting{
+			Category:  category,
+			Threshold: blockThreshold,
+		})
+	}
+	return settings
+}
+
+// IsContentBlocked reports whether an error was caused by a safety block
+func IsContentBlocked(err error) bool {
+	if err == nil {
+		return false
+	}
+	var blockedErr *genai.BlockedError
+	return errors.As(err, &blockedErr) || errors.Is(err, ErrContentBlocked)
+}
+
+// isBlockedFinishReason reports whether a candidate stopped because of a content policy
+func isBlockedFinishReason(reason genai.FinishReason) bool {
+	return reason == genai.FinishReasonSafety || reason == genai.FinishReasonRecitation
+}
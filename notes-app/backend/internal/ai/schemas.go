@@ -0,0 +1,154 @@
+package ai
+
+import (
+	"encoding/json"
+	"slices"
+
+	"github.com/google/generative-ai-go/genai"
+
+	"backend/internal/config"
+)
+
+// Response schemas constrain a JSON reply to a structure, so the model can't wrap it in markdown,
+// cut it short of a field or pick a category that doesn't exist. Gemini takes them as the response
+// schema; OpenAI-compatible APIs as a json_schema response format (see jsonSchema).
+
+// analysisSchema is the reply of AnalyzeNote; the summary is only required when one is asked for
+func analysisSchema(includeSummary bool) *genai.Schema {
+	required := []string{"title", "category", "confidence", "tags"}
+	if includeSummary {
+		required = append(required, "summary")
+	}
+	return &genai.Schema{
+		Type: genai.TypeObject,
+		Properties: map[string]*genai.Schema{
+			"title":      {Type: genai.TypeString},
+			"category":   categorySchema(),
+			"confidence": {Type: genai.TypeNumber},
+			"tags":       {Type: genai.TypeArray, Items: &genai.Schema{Type: genai.TypeString}},
+			"summary":    {Type: genai.TypeString},
+		},
+		Required: required,
+	}
+}
+
+// classificationSchema is the reply of ClassifyNote
+func classificationSchema() *genai.Schema {
+	return &genai.Schema{
+		Type: genai.TypeObject,
+		Properties: map[string]*genai.Schema{
+			"category":   categorySchema(),
+			"confidence": {Type: genai.TypeNumber},
+		},
+		Required: []string{"category", "confidence"},
+	}
+}
+
+// topicsSchema is the reply of NameTopics
+func topicsSchema() *genai.Schema {
+	return &genai.Schema{
+		Type: genai.TypeObject,
+		Properties: map[string]*genai.Schema{
+			"topics": {Type: genai.TypeArray, Items: &genai.Schema{Type: genai.TypeString}},
+		},
+		Required: []string{"topics"},
+	}
+}
+
+// categorySchema only allows the predefined categories
+func categorySchema() *genai.Schema {
+	return &genai.Schema{Type: genai.TypeString, Format: "enum", Enum: config.Categories()}
+}
+
+// exampleSchema infers the schema of a structured summary from a channel's promptSchema, which is an
+// example of the JSON wanted (e.g. {"summary": "...", "topics": ["topic"]}): every field of the
+// example is required, with the type of its example value, and "summary" is always a string.
+// Returns nil when the example isn't a JSON object or has an empty object or array of objects
+// somewhere (a schema can't leave an object's fields open); the reply is then only asked to be JSON.
+func exampleSchema(example string) *genai.Schema {
+	var value interface{}
+	if err := json.Unmarshal([]byte(example), &value); err != nil {
+		return nil
+	}
+	schema := valueSchema(value)
+	if schema == nil || schema.Type != genai.TypeObject {
+		return nil
+	}
+
+	schema.Properties["summary"] = &genai.Schema{Type: genai.TypeString}
+	if !slices.Contains(schema.Required, "summary") {
+		schema.Required = append(schema.Required, "summary")
+	}
+	return schema
+}
+
+// valueSchema returns the schema of an example JSON value, or nil for an empty object
+// An empty array is taken to hold strings.
+func valueSchema(value interface{}) *genai.Schema {
+	switch v := value.(type) {
+	case string:
+		return &genai.Schema{Type: genai.TypeString}
+	case float64:
+		return &genai.Schema{Type: genai.TypeNumber}
+	case bool:
+		return &genai.Schema{Type: genai.TypeBoolean}
+	case []interface{}:
+		items := &genai.Schema{Type: genai.TypeString}
+		if len(v) > 0 {
+			if items = valueSchema(v[0]); items == nil {
+				return nil
+			}
+		}
+		return &genai.Schema{Type: genai.TypeArray, Items: items}
+	case map[string]interface{}:
+		if len(v) == 0 {
+			return nil
+		}
+		schema := &genai.Schema{Type: genai.TypeObject, Properties: make(map[string]*genai.Schema, len(v))}
+		for field, fieldValue := range v {
+			fieldSchema := valueSchema(fieldValue)
+			if fieldSchema == nil {
+				return nil
+			}
+			schema.Properties[field] = fieldSchema
+			schema.Required = append(schema.Required, field)
+		}
+		slices.Sort(schema.Required)
+		return schema
+	default: // null
+		return &genai.Schema{Type: genai.TypeString, Nullable: true}
+	}
+}
+
+// jsonSchemaTypes maps response schema types to JSON Schema types
+var jsonSchemaTypes = map[genai.Type]string{
+	genai.TypeString:  "string",
+	genai.TypeNumber:  "number",
+	genai.TypeInteger: "integer",
+	genai.TypeBoolean: "boolean",
+	genai.TypeArray:   "array",
+	genai.TypeObject:  "object",
+}
+
+// jsonSchema converts a response schema to JSON Schema, as OpenAI's json_schema response format takes it
+func jsonSchema(schema *genai.Schema) map[string]interface{} {
+	converted := map[string]interface{}{"type": jsonSchemaTypes[schema.Type]}
+	if schema.Nullable {
+		converted["type"] = []string{jsonSchemaTypes[schema.Type], "null"}
+	}
+	if len(schema.Enum) > 0 {
+		converted["enum"] = schema.Enum
+	}
+	if schema.Items != nil {
+		converted["items"] = jsonSchema(schema.Items)
+	}
+	if schema.Type == genai.TypeObject {
+		properties := make(map[string]interface{}, len(schema.Properties))
+		for field, fieldSchema := range schema.Properties {
+			properties[field] = jsonSchema(fieldSchema)
+		}
+		converted["properties"] = properties
+		converted["required"] = schema.Required
+	}
+	return converted
+}
@@ -0,0 +1,78 @@
+package ai
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/google/generative-ai-go/genai"
+
+	"backend/internal/config"
+)
+
+func TestCategorySchema(t *testing.T) {
+	defer config.SetCategories(config.Categories())
+
+	// A category created at runtime reaches the schemas built after it
+	config.SetCategories(append(config.Categories(), "sourdough"))
+
+	for name, schema := range map[string]*genai.Schema{
+		"analysis":       analysisSchema(false),
+		"classification": classificationSchema(),
+	} {
+		category := schema.Properties["category"]
+		if !slices.Contains(category.Enum, "sourdough") {
+			t.Errorf("Expected the new category in the %s schema enum, got %v", name, category.Enum)
+		}
+
+		converted := jsonSchema(schema)["properties"].(map[string]interface{})["category"].(map[string]interface{})
+		if converted["type"] != "string" || !slices.Contains(converted["enum"].([]string), "sourdough") {
+			t.Errorf("Expected the new category in the %s JSON Schema enum, got %v", name, converted)
+		}
+	}
+
+	config.SetCategories([]string{"other", "recipes"})
+	if enum := classificationSchema().Properties["category"].Enum; !slices.Equal(enum, []string{"other", "recipes"}) {
+		t.Errorf("Expected a deleted category gone from the enum, got %v", enum)
+	}
+}
+
+func TestAnalysisSchema(t *testing.T) {
+	if required := analysisSchema(false).Required; slices.Contains(required, "summary") {
+		t.Errorf("Expected no summary required without one asked for, got %v", required)
+	}
+	if required := analysisSchema(true).Required; !slices.Contains(required, "summary") {
+		t.Errorf("Expected the summary required when asked for, got %v", required)
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	schema := exampleSchema(`{"topics": ["topic"], "rating": 4, "source": null}`)
+	if schema == nil {
+		t.Fatal("Expected a schema for the example")
+	}
+
+	converted := jsonSchema(schema)
+	if converted["type"] != "object" {
+		t.Fatalf("Expected an object, got %v", converted["type"])
+	}
+	required := slices.Clone(converted["required"].([]string))
+	slices.Sort(required)
+	if !slices.Equal(required, []string{"rating", "source", "summary", "topics"}) {
+		t.Errorf("Expected every field required, got %v", required)
+	}
+
+	properties := converted["properties"].(map[string]interface{})
+	topics := properties["topics"].(map[string]interface{})
+	if topics["type"] != "array" || topics["items"].(map[string]interface{})["type"] != "string" {
+		t.Errorf("Expected topics as an array of strings, got %v", topics)
+	}
+	if rating := properties["rating"].(map[string]interface{}); rating["type"] != "number" {
+		t.Errorf("Expected rating as a number, got %v", rating)
+	}
+	if source := properties["source"].(map[string]interface{}); !slices.Equal(source["type"].([]string), []string{"string", "null"}) {
+		t.Errorf("Expected source as a nullable string, got %v", source)
+	}
+	if summary := properties["summary"].(map[string]interface{}); summary["type"] != "string" {
+		t.Errorf("Expected summary added as a string, got %v", summary)
+	}
+}
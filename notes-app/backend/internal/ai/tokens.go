@@ -0,0 +1,19 @@
+package ai
+
+import (
+	"unicode/utf8"
+
+	"backend/internal/config"
+)
+
+// EstimateTokens approximates how many tokens text takes up in a prompt or reply
+// It counts CHARS_PER_TOKEN characters per token, close for English text with the Gemini and
+// OpenAI tokenizers alike; it is meant for budgets and cost estimates, not billing.
+func EstimateTokens(text string) int {
+	return TokensForChars(utf8.RuneCountInString(text))
+}
+
+// TokensForChars approximates how many tokens a text of the given length in characters takes up
+func TokensForChars(chars int) int {
+	return (chars + config.CHARS_PER_TOKEN - 1) / config.CHARS_PER_TOKEN
+}
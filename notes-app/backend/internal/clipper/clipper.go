@@ -0,0 +1,258 @@
+// Package clipper downloads web pages and extracts their readable article text and metadata.
+package clipper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/utils"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/net/html/charset"
+)
+
+// Article is the readable part of a web page with the metadata found around it
+type Article struct {
+	URL         string // Canonical URL when the page declares one, else the URL after redirects
+	Title       string
+	Author      string
+	SiteName    string
+	PublishedAt time.Time // Zero when the page doesn't say
+	Content     string    // Main text as Markdown-style paragraphs
+}
+
+// Client downloads pages to clip
+type Client struct {
+	client *http.Client
+}
+
+// NewClient creates a new Client that only connects to addresses check allows; pass
+// utils.PublicAddress, since the pages it fetches are chosen by the user
+func NewClient(check utils.AddressCheck) *Client {
+	return &Client{client: utils.PublicHTTPClient(config.CLIP_TIMEOUT_SECONDS*time.Second, check)}
+}
+
+// Clip downloads a page and extracts its article
+// Pages that aren't HTML or have too little readable text fail with an "invalid page" error.
+func (c *Client) Clip(ctx context.Context, pageURL string) (*Article, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	// Some sites refuse requests without a browser-like user agent
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; NotesClipper/1.0)")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%s returned status %d", req.URL.Host, resp.StatusCode)
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType != "" && mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, fmt.Errorf("invalid page: %s is not an HTML page", mediaType)
+	}
+
+	body, err := charset.NewReader(io.LimitReader(resp.Body, config.MAX_CLIP_PAGE_BYTES), contentType)
+	if err != nil {
+		return nil, fmt.Errorf("invalid page: %w", err)
+	}
+	doc, err := html.Parse(body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid page: %w", err)
+	}
+
+	article := Parse(doc, resp.Request.URL)
+	if len(article.Content) < config.MIN_CLIP_CONTENT_CHARS {
+		return nil, fmt.Errorf("invalid page: no readable article text found")
+	}
+	return article, nil
+}
+
+// Parse extracts the article from a parsed page that was served from pageURL
+func Parse(doc *html.Node, pageURL *url.URL) *Article {
+	meta := readMetadata(doc)
+
+	article := &Article{
+		URL:      pageURL.String(),
+		Title:    firstNonEmpty(meta.tags["og:title"], meta.tags["twitter:title"], meta.ld.Headline, meta.title),
+		SiteName: firstNonEmpty(meta.tags["og:site_name"], meta.ld.Publisher.Name),
+	}
+	if article.SiteName == "" {
+		article.SiteName = strings.TrimPrefix(pageURL.Hostname(), "www.")
+	}
+	article.Title = trimSiteSuffix(article.Title, article.SiteName)
+
+	for _, candidate := range []string{meta.tags["author"], meta.tags["article:author"], meta.ld.authorName(), meta.byline} {
+		// article:author is often a profile URL rather than a name
+		if candidate = collapseSpace(candidate); candidate != "" && !strings.Contains(candidate, "://") {
+			article.Author = candidate
+			break
+		}
+	}
+
+	for _, candidate := range []string{meta.tags["article:published_time"], meta.ld.DatePublished, meta.tags["date"],
+		meta.tags["pubdate"], meta.tags["publish-date"], meta.tags["dc.date"], meta.timeElement} {
+		if published := parseDate(candidate); !published.IsZero() {
+			article.PublishedAt = published
+			break
+		}
+	}
+
+	for _, candidate := range []string{meta.canonical, meta.tags["og:url"]} {
+		if resolved, err := pageURL.Parse(strings.TrimSpace(candidate)); candidate != "" && err == nil &&
+			(resolved.Scheme == "http" || resolved.Scheme == "https") {
+			article.URL = resolved.String()
+			break
+		}
+	}
+
+	article.Content = extractContent(doc, article.Title)
+	return article
+}
+
+// metadata is what the page says about itself outside the article text
+type metadata struct {
+	title       string
+	tags        map[string]string // <meta> content by lowercased property or name; first one wins
+	canonical   string
+	byline      string // Text of a rel="author" link
+	timeElement string // datetime of the first <time> element
+	ld          linkedData
+}
+
+// linkedData holds the schema.org Article fields read from JSON-LD
+type linkedData struct {
+	Headline      string          `json:"headline"`
+	DatePublished string          `json:"datePublished"`
+	Author        json.RawMessage `json:"author"`
+	Publisher     struct {
+		Name string `json:"name"`
+	} `json:"publisher"`
+}
+
+// authorName reads the author, which may be a name, a Person or a list of either
+func (ld *linkedData) authorName() string {
+	var name string
+	if json.Unmarshal(ld.Author, &name) == nil {
+		return name
+	}
+	var person struct {
+		Name string `json:"name"`
+	}
+	if json.Unmarshal(ld.Author, &person) == nil && person.Name != "" {
+		return person.Name
+	}
+	var people []json.RawMessage
+	if json.Unmarshal(ld.Author, &people) == nil && len(people) > 0 {
+		first := linkedData{Author: people[0]}
+		return first.authorName()
+	}
+	return ""
+}
+
+// readMetadata collects the title, meta tags, canonical link and JSON-LD of a page
+func readMetadata(doc *html.Node) *metadata {
+	meta := &metadata{tags: map[string]string{}}
+	walk(doc, func(n *html.Node) bool {
+		switch n.DataAtom {
+		case atom.Title:
+			if meta.title == "" {
+				meta.title = collapseSpace(textContent(n))
+			}
+		case atom.Meta:
+			key := strings.ToLower(firstNonEmpty(attr(n, "property"), attr(n, "name"), attr(n, "itemprop")))
+			if _, seen := meta.tags[key]; key != "" && !seen {
+				meta.tags[key] = strings.TrimSpace(attr(n, "content"))
+			}
+		case atom.Link:
+			if strings.EqualFold(attr(n, "rel"), "canonical") && meta.canonical == "" {
+				meta.canonical = attr(n, "href")
+			}
+		case atom.A:
+			if strings.EqualFold(attr(n, "rel"), "author") && meta.byline == "" {
+				meta.byline = collapseSpace(textContent(n))
+			}
+		case atom.Time:
+			if meta.timeElement == "" {
+				meta.timeElement = attr(n, "datetime")
+			}
+		case atom.Script:
+			if strings.EqualFold(attr(n, "type"), "application/ld+json") && meta.ld.Headline == "" {
+				meta.ld = readLinkedData(textContent(n))
+			}
+		}
+		return true
+	})
+	return meta
+}
+
+// readLinkedData finds the first schema.org object with a headline in a JSON-LD block,
+// which may be a single object, a list or an @graph
+func readLinkedData(raw string) linkedData {
+	var objects []json.RawMessage
+	if json.Unmarshal([]byte(raw), &objects) != nil {
+		var graph struct {
+			Graph []json.RawMessage `json:"@graph"`
+		}
+		if json.Unmarshal([]byte(raw), &graph) == nil && len(graph.Graph) > 0 {
+			objects = graph.Graph
+		} else {
+			objects = []json.RawMessage{json.RawMessage(raw)}
+		}
+	}
+	for _, object := range objects {
+		var ld linkedData
+		if json.Unmarshal(object, &ld) == nil && ld.Headline != "" {
+			return ld
+		}
+	}
+	return linkedData{}
+}
+
+// dateLayouts are the date formats found in article metadata
+var dateLayouts = []string{time.RFC3339, "2006-01-02T15:04:05Z0700", "2006-01-02T15:04:05", "2006-01-02T15:04Z07:00", "2006-01-02 15:04:05", "2006-01-02", time.RFC1123Z, time.RFC1123}
+
+// parseDate parses a metadata date, returning the zero time when it can't be read
+func parseDate(value string) time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range dateLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed
+		}
+	}
+	return time.Time{}
+}
+
+// trimSiteSuffix drops a " | Site Name" or " - Site Name" suffix from a <title>
+func trimSiteSuffix(title, siteName string) string {
+	for _, separator := range []string{" | ", " - ", " — ", " · "} {
+		if i := strings.LastIndex(title, separator); i > 0 && strings.EqualFold(strings.TrimSpace(title[i+len(separator):]), siteName) {
+			return strings.TrimSpace(title[:i])
+		}
+	}
+	return title
+}
+
+// firstNonEmpty returns the first of values that isn't blank
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
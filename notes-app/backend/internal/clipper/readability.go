@@ -0,0 +1,360 @@
+package clipper
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Content extraction follows the approach of Mozilla's Readability: boilerplate is pruned, every
+// paragraph scores its parent and grandparent by length, and the best scoring container (less the
+// share of its text that is links) is taken as the article, along with strong sibling containers.
+
+var (
+	unlikelyPattern = regexp.MustCompile(`(?i)comment|sidebar|footer|footnote|masthead|menu|\bnav|share|social|sponsor|promo|related|advert|\bads?\b|cookie|banner|subscribe|newsletter|popup|modal|breadcrumb|disqus`)
+	likelyPattern   = regexp.MustCompile(`(?i)article|body|content|entry|main|post|story|text`)
+)
+
+// prunedTags never hold article text
+var prunedTags = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Iframe: true, atom.Svg: true,
+	atom.Form: true, atom.Nav: true, atom.Footer: true, atom.Aside: true, atom.Button: true,
+	atom.Select: true, atom.Textarea: true, atom.Input: true, atom.Template: true, atom.Object: true,
+	atom.Embed: true, atom.Canvas: true,
+}
+
+// blockTags start a new paragraph in the extracted text
+var blockTags = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Section: true, atom.Article: true, atom.Main: true, atom.Header: true,
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+	atom.Ul: true, atom.Ol: true, atom.Li: true, atom.Blockquote: true, atom.Pre: true, atom.Table: true,
+	atom.Tr: true, atom.Figure: true, atom.Figcaption: true, atom.Dl: true, atom.Dt: true, atom.Dd: true,
+	atom.Hr: true, atom.Br: true,
+}
+
+// extractContent returns the article text of a page, dropping a leading heading that repeats the title
+func extractContent(doc *html.Node, title string) string {
+	prune(doc)
+
+	var nodes []*html.Node
+	if top, scores := topCandidate(doc); top != nil {
+		nodes = withSiblings(top, scores)
+	} else if fallback := findFirst(doc, atom.Article, atom.Main, atom.Body); fallback != nil {
+		nodes = []*html.Node{fallback}
+	}
+
+	r := &renderer{}
+	for _, n := range nodes {
+		r.render(n)
+	}
+	r.flush()
+
+	paragraphs := r.paragraphs
+	if len(paragraphs) > 0 && title != "" && strings.EqualFold(strings.TrimLeft(paragraphs[0], "# "), title) {
+		paragraphs = paragraphs[1:]
+	}
+
+	var b strings.Builder
+	for i, paragraph := range paragraphs {
+		switch {
+		case i == 0:
+		case strings.HasPrefix(paragraph, "- ") && strings.HasPrefix(paragraphs[i-1], "- "):
+			b.WriteString("\n") // Items of a list stay together
+		default:
+			b.WriteString("\n\n")
+		}
+		b.WriteString(paragraph)
+	}
+	return b.String()
+}
+
+// prune removes elements that are boilerplate by tag, visibility or class and id
+func prune(n *html.Node) {
+	for child := n.FirstChild; child != nil; {
+		next := child.NextSibling
+		if child.Type == html.CommentNode || (child.Type == html.ElementNode && unlikely(child)) {
+			n.RemoveChild(child)
+		} else {
+			prune(child)
+		}
+		child = next
+	}
+}
+
+// unlikely reports whether an element is boilerplate rather than article content
+func unlikely(n *html.Node) bool {
+	if prunedTags[n.DataAtom] || hasAttr(n, "hidden") || attr(n, "aria-hidden") == "true" {
+		return true
+	}
+	switch n.DataAtom {
+	case atom.Html, atom.Body, atom.Article, atom.Main:
+		return false
+	}
+	names := attr(n, "class") + " " + attr(n, "id")
+	return unlikelyPattern.MatchString(names) && !likelyPattern.MatchString(names)
+}
+
+// topCandidate scores containers by the paragraphs they hold and returns the best one,
+// along with the final score of every container
+func topCandidate(doc *html.Node) (*html.Node, map[*html.Node]float64) {
+	scores := map[*html.Node]float64{}
+	add := func(n *html.Node, score float64) {
+		if n == nil || n.Type != html.ElementNode {
+			return
+		}
+		if _, ok := scores[n]; !ok {
+			scores[n] = initialScore(n)
+		}
+		scores[n] += score
+	}
+
+	walk(doc, func(n *html.Node) bool {
+		if !isParagraph(n) {
+			return true
+		}
+		text := collapseSpace(textContent(n))
+		if len(text) < 25 {
+			return false
+		}
+		score := 1 + float64(strings.Count(text, ",")) + min(float64(len(text))/100, 3)
+		add(n.Parent, score)
+		if n.Parent != nil {
+			add(n.Parent.Parent, score/2)
+		}
+		return false
+	})
+
+	var top *html.Node
+	var topScore float64
+	for n, score := range scores {
+		score *= 1 - linkDensity(n)
+		scores[n] = score
+		if top == nil || score > topScore {
+			top, topScore = n, score
+		}
+	}
+	return top, scores
+}
+
+// withSiblings returns the top candidate together with the siblings that look like article content,
+// as articles are often split across several containers
+func withSiblings(top *html.Node, scores map[*html.Node]float64) []*html.Node {
+	if top.Parent == nil {
+		return []*html.Node{top}
+	}
+	threshold := max(10, scores[top]*0.2)
+
+	var nodes []*html.Node
+	for sibling := top.Parent.FirstChild; sibling != nil; sibling = sibling.NextSibling {
+		if sibling == top {
+			nodes = append(nodes, sibling)
+			continue
+		}
+		if sibling.Type != html.ElementNode {
+			continue
+		}
+		if score, ok := scores[sibling]; ok && score >= threshold {
+			nodes = append(nodes, sibling)
+			continue
+		}
+		// A stray paragraph next to the article is kept when it is long and mostly not links
+		if sibling.DataAtom == atom.P && len(collapseSpace(textContent(sibling))) > 80 && linkDensity(sibling) < 0.25 {
+			nodes = append(nodes, sibling)
+		}
+	}
+	return nodes
+}
+
+// initialScore weighs a container by its tag and its class and id names
+func initialScore(n *html.Node) float64 {
+	var score float64
+	switch n.DataAtom {
+	case atom.Article:
+		score = 10
+	case atom.Div, atom.Main:
+		score = 5
+	case atom.Pre, atom.Td, atom.Blockquote:
+		score = 3
+	case atom.Address, atom.Ol, atom.Ul, atom.Dl, atom.Dd, atom.Dt, atom.Li, atom.Form:
+		score = -3
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6, atom.Th:
+		score = -5
+	}
+	names := attr(n, "class") + " " + attr(n, "id")
+	if likelyPattern.MatchString(names) {
+		score += 25
+	}
+	if unlikelyPattern.MatchString(names) {
+		score -= 25
+	}
+	return score
+}
+
+// isParagraph reports whether an element is a run of text: a <p>, a <pre>, or a <div> used as one
+func isParagraph(n *html.Node) bool {
+	switch n.DataAtom {
+	case atom.P, atom.Pre:
+		return true
+	case atom.Div:
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type == html.ElementNode && blockTags[child.DataAtom] && child.DataAtom != atom.Br {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// linkDensity is the share of an element's text that is inside links
+func linkDensity(n *html.Node) float64 {
+	total := len(collapseSpace(textContent(n)))
+	if total == 0 {
+		return 0
+	}
+	linked := 0
+	walk(n, func(child *html.Node) bool {
+		if child.DataAtom == atom.A {
+			linked += len(collapseSpace(textContent(child)))
+			return false
+		}
+		return true
+	})
+	return float64(linked) / float64(total)
+}
+
+// renderer turns the article elements into Markdown-style paragraphs
+type renderer struct {
+	paragraphs []string
+	current    strings.Builder
+	prefixes   []string // Line prefix of the innermost heading, list item or quote
+}
+
+// render writes an element and its descendants
+func (r *renderer) render(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		r.current.WriteString(n.Data)
+		return
+	case html.ElementNode, html.DocumentNode:
+	default:
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.Img, atom.Picture, atom.Video, atom.Audio:
+		return
+	case atom.Pre:
+		r.flush()
+		if code := strings.Trim(textContent(n), "\n"); strings.TrimSpace(code) != "" {
+			r.paragraphs = append(r.paragraphs, "```\n"+code+"\n```")
+		}
+		return
+	}
+	if !blockTags[n.DataAtom] {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			r.render(child)
+		}
+		return
+	}
+
+	r.flush()
+	prefix := ""
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		prefix = strings.Repeat("#", int(n.Data[1]-'0')) + " "
+	case atom.Li:
+		prefix = "- "
+	case atom.Blockquote:
+		prefix = "> "
+	}
+	if prefix != "" {
+		r.prefixes = append(r.prefixes, prefix)
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		r.render(child)
+	}
+	r.flush()
+	if prefix != "" {
+		r.prefixes = r.prefixes[:len(r.prefixes)-1]
+	}
+}
+
+// flush ends the current paragraph
+func (r *renderer) flush() {
+	text := collapseSpace(r.current.String())
+	r.current.Reset()
+	if text == "" {
+		return
+	}
+	if len(r.prefixes) > 0 {
+		text = r.prefixes[len(r.prefixes)-1] + text
+	}
+	r.paragraphs = append(r.paragraphs, text)
+}
+
+// walk visits n and its descendants in document order; visit returns false to skip the children
+func walk(n *html.Node, visit func(*html.Node) bool) {
+	if n.Type == html.ElementNode && !visit(n) {
+		return
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		walk(child, visit)
+	}
+}
+
+// findFirst returns the first element with one of the tags, trying the tags in order
+func findFirst(doc *html.Node, tags ...atom.Atom) *html.Node {
+	for _, tag := range tags {
+		var found *html.Node
+		walk(doc, func(n *html.Node) bool {
+			if found == nil && n.DataAtom == tag {
+				found = n
+			}
+			return found == nil
+		})
+		if found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// textContent concatenates the text inside a node
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		b.WriteString(textContent(child))
+	}
+	return b.String()
+}
+
+// attr returns the value of an element's attribute, or "" when it has none
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// hasAttr reports whether an element has an attribute, with or without a value
+func hasAttr(n *html.Node, key string) bool {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// collapseSpace trims text and joins its words with single spaces
+func collapseSpace(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -1,7 +1,12 @@
 package config
 
-// CATEGORIES defines all available note categories
-var CATEGORIES = []string{
+import (
+	"slices"
+	"sync"
+)
+
+// DEFAULT_CATEGORIES are the note categories the categories collection is seeded with on first run
+var DEFAULT_CATEGORIES = []string{
 	// Personal & Life
 	"journal", "reflections", "goals", "ideas", "thoughts", "dreams", "personal-growth",
 
@@ -31,14 +36,49 @@ var CATEGORIES = []string{
 
 	// Other
 	"other", "miscellaneous", "random-thoughts",
+
+	// Saved Q&A answers
+	ANSWER_CATEGORY,
+}
+
+// ANSWER_CATEGORY is the category of notes saved from Q&A answers
+const ANSWER_CATEGORY = "qa-answers"
+
+// GOAL_CATEGORY is the category whose notes set new goals
+const GOAL_CATEGORY = "goals"
+
+// GOAL_UPDATE_CATEGORIES are the categories whose notes are checked for progress on active goals
+var GOAL_UPDATE_CATEGORIES = []string{GOAL_CATEGORY, "journal", "reflections", "personal-growth", "tasks"}
+
+// FALLBACK_CATEGORY is the category of notes the AI can't place elsewhere; it can't be deleted
+const FALLBACK_CATEGORY = "other"
+
+// The note categories, cached from the categories collection; the defaults until it is loaded
+var (
+	categoriesMu sync.RWMutex
+	categories   = DEFAULT_CATEGORIES
+)
+
+// Categories returns the note categories, as last loaded from the categories collection
+func Categories() []string {
+	categoriesMu.RLock()
+	defer categoriesMu.RUnlock()
+	return slices.Clone(categories)
 }
 
-// IsValidCategory checks if a category exists in the CATEGORIES list
+// SetCategories replaces the cached note categories; CategoryService calls it whenever it loads them
+func SetCategories(names []string) {
+	categoriesMu.Lock()
+	defer categoriesMu.Unlock()
+	categories = slices.Clone(names)
+}
+
+// IsValidCategory checks if a category is one of the note categories
 func IsValidCategory(category string) bool {
-	for _, validCat := range CATEGORIES {
-		if category == validCat {
-			return true
-		}
-	}
-	return false
+	categoriesMu.RLock()
+	defer categoriesMu.RUnlock()
+	return slices.Contains(categories, category)
 }
+
+// STALE_CATEGORIES are fast-moving categories whose old notes are checked for newer notes that supersede them
+var STALE_CATEGORIES = []string{"apis", "coding-notes", "technical-docs", "programming"}
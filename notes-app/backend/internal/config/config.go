@@ -1,21 +1,251 @@
 package config
 
 import (
+	"log"
 	"os"
+	"strconv"
 	"strings"
 )
 
 // Database and Vector Store Constants
 const (
-	COLLECTION_NAME     = "notes_embeddings"
-	CHUNK_SIZE          = 1000
-	MAX_WORDS           = 10000
-	EMBEDDING_DIM       = 768
-	MIN_RELEVANCE_SCORE = 0.3 // Filter out results below 30% relevance
+	COLLECTION_NAME   = "notes_embeddings"
+	MAX_WORDS         = 10000
+	EMBEDDING_DIM     = 768 // Dimension of the default Gemini embedding model
+	RRF_K             = 60  // Reciprocal rank fusion constant for hybrid search
+	MAX_CHUNK_MATCHES = 3   // Matching passages returned per note in search results
+	SNIPPET_LENGTH    = 300 // Maximum characters in a matched passage snippet
+
+	DEFAULT_CHUNK_SIZE    = 1000 // Words per embedded chunk; CHUNK_SIZE overrides it
+	DEFAULT_CHUNK_OVERLAP = 100  // Words of the previous chunk repeated at the start of the next; CHUNK_OVERLAP overrides it
+
+	SIMILAR_TEXT_CHUNK_WORDS = 200 // Words per embedded chunk when finding notes similar to pasted text
+	SIMILAR_TEXT_MAX_CHUNKS  = 8   // Upper bound on chunks embedded per similar-to-text request
+
+	DEFAULT_RELATED_NOTES_LIMIT = 5
+	MAX_RELATED_NOTES_LIMIT     = 50
+	RELATED_NOTE_MAX_VECTORS    = 64 // Stored embeddings of a note averaged when finding notes related to it
+
+	TITLE_MATCH_MIN_SCORE = 0.9 // Title embedding similarity at which a title counts as matching the query
+	MAX_TITLE_MATCHES     = 3   // Title matches ranked ahead of the other search results
+
+	SPELL_MIN_WORD_LENGTH        = 4  // Shorter query words are never corrected
+	SPELL_VOCABULARY_TTL_MINUTES = 10 // How long the corpus vocabulary is cached before rebuilding
+
+	FALLBACK_SUMMARY_SENTENCES = 3 // Sentences kept by the extractive summarizer
+
+	SUMMARY_PATCH_MAX_CHANGE = 0.2 // Share of words an edit may touch for the note's AI summary to be patched from the diff
+
+	MAX_STRUCTURED_DATA_BYTES = 64 * 1024 // JSON size of a note's structured data; generated data is cut down to it, given data rejected
+	MAX_STRUCTURED_DATA_DEPTH = 6         // Levels of objects and lists nested in structured data, the top-level object being 1
+
+	DEFAULT_REVIEW_CONFIDENCE = 0.6 // Classifications below this confidence go to the review queue
+	FEW_SHOT_EXAMPLES         = 5   // Confirmed classifications included in classification prompts
+	MAX_SUGGESTED_TAGS        = 5   // Tags kept from AI tag suggestions during note analysis
+
+	ANALYSIS_BATCH_SIZE      = 10   // Short notes analyzed per AI call during bulk imports
+	ANALYSIS_BATCH_MAX_CHARS = 2000 // Notes longer than this are analyzed on their own
+
+	MAX_BULK_UPDATE_IDS    = 1000 // Upper bound on notes changed by a single bulk update
+	MAX_BULK_DELETE_IDS    = 1000 // Upper bound on notes removed by a single bulk delete
+	BULK_DELETE_BATCH_SIZE = 100  // Notes removed per MongoDB/Qdrant delete call during a bulk delete
+
+	EMBEDDINGS_PAGE_SIZE  = 256     // Points read or written per Qdrant call during embedding export/import
+	MAX_IMPORT_ERRORS     = 20      // Rejected lines described in an import response; the rest are only counted
+	MAX_IMPORT_LINE_BYTES = 1 << 20 // Longest accepted JSONL line (a 768-dim vector is ~15KB)
+
+	MAX_BACKUP_BYTES = 256 << 20 // Largest backup accepted by POST /import/json, which is decoded in memory
+
+	MAX_NOTION_IMPORT_BYTES = 50 << 20 // Largest accepted Notion export upload
+	MAX_NOTION_FILE_BYTES   = 5 << 20  // Larger pages and databases inside an export are skipped
+	MAX_NOTION_PAGES        = 500      // Notes created per Notion import; each one is analyzed by the AI
+
+	MAX_VAULT_SYNC_FILES = 100 // Files per Obsidian vault push or pull
+
+	MAX_ON_THIS_DAY_NOTES = 200 // Notes resurfaced by GET /onthisday, newest first
+
+	MAX_CONVERSATION_HISTORY = 10   // Prior conversation messages passed into follow-up answers
+	MAX_PINNED_CONTEXT_CHARS = 8000 // Budget for always-in-context notes in a Q&A prompt (~2000 tokens)
+	MAX_PERSONA_CHARS        = 4000 // Longest assistant persona, instructions and rules combined
+	MAX_PERSONA_RULES        = 20   // Answering rules an assistant persona may add
+	MAX_ANSWER_TITLE_CHARS   = 100  // Question characters kept in the title of a saved Q&A answer
+
+	ANSWER_SOURCES                  = 5  // Notes used as Q&A context
+	ANSWER_CANDIDATE_CHUNKS         = 30 // Chunks retrieved per question, so a note or channel with many matching chunks can't crowd out the rest
+	DEFAULT_MAX_SOURCES_PER_CHANNEL = 2  // Q&A context notes taken from any one channel
+
+	MULTI_HOP_MAX_HOPS      = 3  // Retrieval rounds for a multi-hop question, the first search included
+	MULTI_HOP_QUERIES       = 2  // Follow-up searches run per round
+	MULTI_HOP_QUERY_SOURCES = 3  // New notes each follow-up search may add
+	MULTI_HOP_MAX_SOURCES   = 10 // Notes used as context for a multi-hop answer
+
+	WEBHOOK_BATCH_SIZE      = 50 // Events loaded per webhook per delivery pass
+	WEBHOOK_RETRY_SECONDS   = 30 // Delay before retrying webhooks whose endpoint failed
+	WEBHOOK_TIMEOUT_SECONDS = 10 // Per-request timeout for webhook deliveries
+
+	EVENT_STREAM_BUFFER            = 100 // Events queued per GET /events client before it misses new ones
+	EVENT_STREAM_KEEPALIVE_SECONDS = 15  // Comment lines sent to idle GET /events clients so proxies keep the connection open
+
+	JOB_POLL_SECONDS      = 5    // How often idle workers look for jobs due for retry or queued by other instances
+	JOB_LEASE_SECONDS     = 600  // How long a claimed job is reserved before another worker may take it over
+	JOB_RENEW_SECONDS     = 120  // How often a running job's lease is extended by another JOB_LEASE_SECONDS
+	JOB_RETRY_SECONDS     = 30   // Delay before the first retry of a failed embedding job, doubling per attempt
+	JOB_MAX_RETRY_SECONDS = 3600 // Longest delay between retries of a job
+	JOB_MAX_ATTEMPTS      = 5    // Jobs failing this often move to the dead-letter queue instead of being retried
+	MAX_LISTED_JOBS       = 100  // Jobs returned by GET /jobs and GET /admin/dead-letter
+
+	CHUNK_RETRIES        = 3   // Attempts per embedding call or Qdrant write before the job fails
+	CHUNK_RETRY_MS       = 500 // Pause after a chunk's first failed attempt, doubling per attempt
+	EMBEDDING_BATCH_SIZE = 100 // Chunks embedded per batch request; Gemini accepts at most 100
+
+	SEARCH_INDEX_NAME            = "notes" // Meilisearch index / Typesense collection mirroring the notes
+	SEARCH_INDEX_BATCH_SIZE      = 100     // Notes sent per upsert call when syncing or reindexing
+	SEARCH_INDEX_RETRY_SECONDS   = 30      // How often the search index sync retries after a failure, and picks up events it wasn't woken for
+	SEARCH_INDEX_TIMEOUT_SECONDS = 10      // Per-request timeout for search index calls
+
+	GRAPHQL_MAX_DEPTH          = 8   // Deepest nesting of object fields accepted in a GraphQL query
+	GRAPHQL_MAX_LIMIT          = 100 // Upper bound on list arguments such as notes(limit:)
+	GRAPHQL_RELATED_CANDIDATES = 500 // Notes sharing a tag considered per batch when ranking related notes
+
+	MAX_TOPIC_CLUSTERS      = 30   // Upper bound on the k of GET /topics; by default k grows with the square root of the notes
+	TOPIC_MAX_NOTES         = 2000 // Embedded notes clustered at most, keeping k-means to a few seconds
+	TOPIC_KMEANS_ITERATIONS = 30   // Assignment rounds before k-means stops, if it hasn't settled sooner
+	TOPIC_LABEL_TITLES      = 8    // Titles of a cluster's most typical notes sent to name its topic
+	TOPIC_CLUSTER_TAGS      = 3    // Most common tags listed per cluster
+
+	DEFAULT_ENTITIES_LIMIT = 100
+	MAX_ENTITIES_LIMIT     = 1000
+
+	DEFAULT_GRAPH_NOTES  = 200
+	MAX_GRAPH_NOTES      = 2000
+	GRAPH_SIMILAR_NOTES  = 5   // Most similar notes linked to each note in the knowledge graph
+	GRAPH_MIN_SIMILARITY = 0.6 // Similarity below which notes aren't linked
+	GRAPH_QUEUE_SIZE     = 100 // Processed notes waiting for their graph edges before new ones are dropped
+
+	GOAL_QUEUE_SIZE       = 100 // Notes waiting for goal extraction before new ones are dropped
+	GOAL_PROGRESS_UPDATES = 10  // Most recent updates a goal's progress summary is generated from
+
+	DEFAULT_YOUTUBE_SYNC_INTERVAL_HOURS = 6        // How often channels with a channel URL are checked for new uploads
+	YOUTUBE_SYNC_MAX_VIDEOS             = 10       // Latest uploads checked per channel on each sync
+	YOUTUBE_TIMEOUT_SECONDS             = 15       // Per-request timeout for YouTube calls
+	MAX_YOUTUBE_RESPONSE_BYTES          = 10 << 20 // Watch pages run to ~1MB; anything far larger is not a YouTube page
+
+	DEFAULT_YOUTUBE_API_URL   = "https://www.googleapis.com/youtube/v3"
+	DEFAULT_YOUTUBE_WATCH_URL = "https://www.youtube.com/watch" // Watch pages list a video's caption tracks
+
+	DEFAULT_PODCAST_SYNC_INTERVAL_HOURS = 6        // How often podcast feeds are checked for new episodes
+	PODCAST_SYNC_MAX_EPISODES           = 5        // Latest episodes checked per feed on each sync
+	PODCAST_TIMEOUT_SECONDS             = 60       // Per-request timeout for feed, transcript and audio downloads
+	MAX_PODCAST_FEED_BYTES              = 20 << 20 // Long-running shows publish multi-megabyte feeds
+	MAX_PODCAST_TRANSCRIPT_BYTES        = 5 << 20
+	MAX_PODCAST_AUDIO_BYTES             = 25 << 20 // Upload limit of the OpenAI transcription API
+	TRANSCRIPTION_TIMEOUT_SECONDS       = 600      // Transcribing an hour of audio takes minutes
+
+	DEFAULT_TRANSCRIPTION_URL   = "https://api.openai.com/v1"
+	DEFAULT_TRANSCRIPTION_MODEL = "whisper-1"
+
+	EMBEDDING_SERVER_TIMEOUT_SECONDS = 30 // Per-request timeout of an OpenAI-compatible embedding server
+
+	AI_PROVIDER_GEMINI      = "gemini"
+	AI_PROVIDER_OPENAI      = "openai" // Any OpenAI-compatible chat completions API, e.g. OpenAI, vLLM or Ollama
+	DEFAULT_OPENAI_URL      = "https://api.openai.com/v1"
+	OPENAI_GENERATION_MODEL = "gpt-4o-mini"            // Default GENERATION_MODEL when AI_PROVIDER is openai
+	OPENAI_EMBEDDING_MODEL  = "text-embedding-3-small" // Default EMBEDDING_MODEL when AI_PROVIDER is openai
+	OPENAI_TIMEOUT_SECONDS  = 120                      // Per-request timeout of chat completions, which may stream a long answer
+
+	DEFAULT_AI_REQUESTS_PER_MINUTE = 600 // Calls to the AI API (generation and embedding) spaced to stay within this quota
+	DEFAULT_AI_MAX_RETRIES         = 3   // Retries of a rate-limited or transiently failing AI call
+	AI_RETRY_BACKOFF_MS            = 500 // Pause before the first retry, doubling with each further one
+	AI_MAX_RETRY_BACKOFF_SECONDS   = 30
+	AI_BREAKER_FAILURES            = 5  // Consecutive failed AI calls (after retries) that open the circuit
+	AI_BREAKER_COOLDOWN_SECONDS    = 30 // Calls fail fast while the circuit is open, then one probe call is let through
+
+	CHARS_PER_TOKEN = 4 // Rough characters per token of English text, for token and cost estimates
+
+	CLIP_TIMEOUT_SECONDS   = 15      // Per-request timeout when fetching a page to clip
+	MAX_CLIP_PAGE_BYTES    = 5 << 20 // Larger pages are cut off before extraction
+	MIN_CLIP_CONTENT_CHARS = 100     // Pages with less readable text than this are rejected
+
+	MAX_PDF_UPLOAD_BYTES  = 50 << 20  // Largest PDF accepted by POST /notes/upload
+	MAX_PDF_STREAM_BYTES  = 64 << 20  // Decompressed size limit of a single PDF stream
+	MAX_PDF_DECODED_BYTES = 256 << 20 // Decompressed size limit of a whole PDF, against compression bombs
+	PDF_NOTE_MAX_WORDS    = 8000      // Longer PDFs are split into several notes at page boundaries
+
+	MAX_IMAGE_UPLOAD_BYTES = 15 << 20 // Largest image accepted by POST /notes/upload; Gemini takes inline data up to 20MB a request
+
+	DEFAULT_STALE_AFTER_DAYS   = 365 // Notes in fast-moving categories older than this are checked for newer replacements
+	MAX_STALE_NOTES_CHECKED    = 50  // Old notes compared per outdated report, oldest first
+	STALE_SUCCESSOR_CANDIDATES = 3   // Newer notes on the same subject compared against each old note
+
+	DEFAULT_RECONCILE_INTERVAL_HOURS = 24   // How often Mongo chunks are reconciled against Qdrant points
+	DEFAULT_RECONCILE_DRIFT_ALERT    = 0.01 // Drift ratio above which a reconciliation raises an alert
+	MAX_RECONCILE_DRIFT_NOTES        = 100  // Notes with drift listed in a reconciliation report
+
+	WARMUP_RETRY_SECONDS      = 5  // Wait between vector search warm-up attempts at startup
+	WARMUP_TIMEOUT_SECONDS    = 30 // Limit on each warm-up attempt, which may wait for Qdrant to load the collection
+	READINESS_TIMEOUT_SECONDS = 2  // Limit on each Qdrant check made for GET /ready
+
+	CATEGORY_REFRESH_SECONDS = 60 // How often the cached categories are reloaded, picking up changes made through other instances
+	MAX_CATEGORY_NAME_LENGTH = 50
+
+	// Default minimum vector similarity per operation; MIN_SEARCH_SCORE and MIN_ANSWER_SCORE override them
+	DEFAULT_MIN_SEARCH_SCORE = 0.3 // Search results below 30% relevance are dropped
+	DEFAULT_MIN_ANSWER_SCORE = 0.4 // Higher bar for notes used as Q&A context
+
+	// Default search ranking boosts, added to a 1.0 score multiplier
+	DEFAULT_BOOST_PINNED           = 0.2 // Pinned notes
+	DEFAULT_BOOST_SUMMARY          = 0.1 // Notes with a summary (curated or processed content)
+	DEFAULT_BOOST_RECENCY          = 0.1 // Full boost for brand-new notes, halving every half-life
+	DEFAULT_RECENCY_HALF_LIFE_DAYS = 180
+	DEFAULT_BOOST_POPULARITY       = 0.0 // Frequently opened notes; off unless configured
+	POPULARITY_HALF_VIEWS          = 10  // Views at which a note gets half the popularity boost
+
+	DEFAULT_MIGRATION_PARALLELISM = 4   // Notes processed at once by POST /migrate/classify and /migrate/titles
+	MAX_MIGRATION_PARALLELISM     = 32  // Upper bound on the parallelism a request may ask for
+	MIGRATION_MAX_RETRIES         = 5   // Attempts per note while the AI API keeps rate-limiting
+	MIGRATION_BACKOFF_SECONDS     = 5   // Pause for every worker after a rate limit, doubling per retry
+	MIGRATION_MAX_BACKOFF_SECONDS = 120 // Longest pause after repeated rate limits
+	MIGRATION_CHECKPOINT_EVERY    = 25  // Notes finished between saves of a run's progress
+	MIGRATION_RUNS_LIMIT          = 20  // Most recent runs listed by GET /migrate/runs
+
+	DEFAULT_RESUMMARIZE_PER_MINUTE = 30  // Notes re-summarized per minute by POST /admin/resummarize
+	MAX_RESUMMARIZE_PER_MINUTE     = 600 // Upper bound on the rate a request may ask for
+
+	// Prompt instructions added to each note and reply lengths, for the estimates of GET /admin/estimates
+	// and the AI history; reply lengths are capped by GENERATION_MAX_OUTPUT_TOKENS
+	ANALYSIS_PROMPT_TOKENS = 250  // Fields, categories and rules, besides few-shot examples
+	ANALYSIS_CONTENT_CHARS = 2000 // Note content a note is analyzed from
+	CLASSIFY_PROMPT_TOKENS = 120
+	CLASSIFY_OUTPUT_TOKENS = 15
+	TITLE_PROMPT_TOKENS    = 60
+	TITLE_OUTPUT_TOKENS    = 15
+	TITLE_CONTENT_CHARS    = 500 // Note content a title is generated from
+	SUMMARY_PROMPT_TOKENS  = 100 // Besides the channel prompt and schema
+	SUMMARY_OUTPUT_TOKENS  = 400
+
+	SUMMARY_PATCH_PROMPT_TOKENS = 200 // Besides the old summary and the diff
+	TRANSLATION_PROMPT_TOKENS   = 120 // Besides the content and summary
+
+	AI_HISTORY_LIMIT        = 100  // Most recent operations listed by GET /notes/:id/ai-history
+	AI_HISTORY_OUTPUT_CHARS = 4000 // Longer replies are cut off in the AI history
+
+	AI_SAMPLES_MAX_BYTES     = 64 << 20 // Size of the capped ai_samples collection; the oldest samples make way for new ones
+	AI_SAMPLE_MAX_CHARS      = 20000    // Longer prompts and replies are cut off in a sample
+	DEFAULT_AI_SAMPLES_LIMIT = 20
+	MAX_AI_SAMPLES_LIMIT     = 100
+
+	DEFAULT_ACCESS_REPORT_LIMIT = 20
+	MAX_ACCESS_REPORT_LIMIT     = 200
+
+	DEFAULT_PROMPT_PREVIEW_SAMPLES = 3  // Recent channel notes a prompt preview runs against
+	MAX_PROMPT_PREVIEW_SAMPLES     = 10 // Each sample is one LLM call, made while the request waits
+	DEFAULT_SCHEMA_SAMPLE_NOTES    = 5  // Recent channel notes shown to the LLM when inferring a prompt schema
+	MAX_SCHEMA_SAMPLE_NOTES        = 10
+	SCHEMA_SAMPLE_NOTE_CHARS       = 3000 // Each sample note is cut to this length in the inference prompt
 
 	// Gemini AI Model Configuration
-	EMBEDDING_MODEL  = "text-embedding-004"    // For generating embeddings
-	GENERATION_MODEL = "gemini-2.5-flash-lite" // For text generation and classification
+	DEFAULT_EMBEDDING_MODEL  = "text-embedding-004"    // For generating embeddings, unless EMBEDDING_MODEL is set
+	DEFAULT_GENERATION_MODEL = "gemini-2.5-flash-lite" // For text generation and classification, unless GENERATION_MODEL is set
 )
 
 // Config holds the application configuration
@@ -23,6 +253,127 @@ type Config struct {
 	MongoURI     string
 	QdrantURL    string
 	GeminiAPIKey string
+
+	// AIProvider selects the generation backend: "gemini" (the default) or "openai" for an
+	// OpenAI-compatible API at OpenAIURL. With "openai" embeddings also come from OpenAIURL
+	// unless EmbeddingURL is set.
+	AIProvider   string
+	OpenAIURL    string
+	OpenAIAPIKey string
+
+	// GenerationModel classifies, titles, summarizes and extracts; AnswerModel answers questions
+	// (/ask, /ask/stream, conversations) and is GenerationModel unless set, e.g. to a larger model
+	GenerationModel string
+	AnswerModel     string
+	// GenerationTemperature is the sampling temperature of every generation call; negative keeps the model's default
+	GenerationTemperature float64
+	// GenerationMaxOutputTokens caps the length of each reply; 0 keeps the model's default
+	GenerationMaxOutputTokens int
+
+	// AIRequestsPerMinute is the quota all AI API calls share; 0 doesn't throttle them
+	AIRequestsPerMinute int
+	// AIMaxRetries is how often a rate-limited or transiently failing AI call is retried
+	AIMaxRetries int
+
+	// SafetyThreshold is the Gemini harm block threshold applied to every harm
+	// category: "none", "only_high", "medium_and_above" or "low_and_above".
+	// Empty keeps Gemini's default thresholds.
+	SafetyThreshold string
+
+	// PrivacyMode keeps note content out of LLM summarization; summaries are
+	// produced locally by the extractive summarizer instead
+	PrivacyMode bool
+
+	// InferMetadata has the AI pull author, source, date and URL out of notes created without metadata
+	InferMetadata bool
+
+	// ReviewConfidenceThreshold is the classification confidence below which
+	// notes are flagged for manual review
+	ReviewConfidenceThreshold float64
+
+	// Search ranking boosts applied after vector scoring; requests may override them
+	BoostPinned         float64
+	BoostSummary        float64
+	BoostRecency        float64
+	RecencyHalfLifeDays float64
+	BoostPopularity     float64
+
+	// MinSearchScore and MinAnswerScore are the minimum vector similarity for a note to be a
+	// search result or Q&A context; requests may override them
+	MinSearchScore float32
+	MinAnswerScore float32
+
+	// ChunkSize is the most words in an embedded chunk; ChunkOverlap is how many words of whole
+	// sentences from the end of one chunk are repeated at the start of the next
+	ChunkSize    int
+	ChunkOverlap int
+
+	// MaxSourcesPerChannel caps the Q&A context notes from one channel so answers draw on several
+	// sources; 0 removes the cap
+	MaxSourcesPerChannel int
+
+	// ReconcileIntervalHours schedules the Mongo/Qdrant reconciliation job; 0 disables it
+	ReconcileIntervalHours float64
+	// ReconcileDriftAlert is the fraction of drifted chunks that raises an alert
+	ReconcileDriftAlert float64
+
+	// YouTubeAPIKey enables automatic sync of channels whose settings have a channel URL
+	YouTubeAPIKey string
+	// YouTubeAPIURL and YouTubeWatchURL point at YouTube; override them to route through a proxy
+	YouTubeAPIURL   string
+	YouTubeWatchURL string
+	// YouTubeSyncIntervalHours schedules channel sync; 0 leaves it to POST /sync/youtube
+	YouTubeSyncIntervalHours float64
+
+	// PodcastSyncIntervalHours schedules podcast feed sync; 0 leaves it to POST /podcasts/sync
+	PodcastSyncIntervalHours float64
+	// TranscriptionURL is an OpenAI-compatible transcription API for episodes published without a
+	// transcript; empty skips them. It defaults to OpenAI when only TranscriptionAPIKey is set.
+	TranscriptionURL    string
+	TranscriptionAPIKey string
+	TranscriptionModel  string
+
+	// EmbeddingURL is a self-hosted OpenAI-compatible embeddings API (e.g. Ollama's http://ollama:11434/v1)
+	// used instead of the AI provider's; empty uses the provider's. EmbeddingModel names the model for either.
+	EmbeddingURL    string
+	EmbeddingAPIKey string
+	EmbeddingModel  string
+	// EmbeddingDim is the length of the embedding vectors; 0 detects it from the model at startup
+	EmbeddingDim int
+
+	// MigrationParallelism is how many notes the bulk AI migrations process at once; requests may override it
+	MigrationParallelism int
+	// ResummarizePerMinute is how many notes a re-summarization starts per minute; requests may override it
+	ResummarizePerMinute int
+	// AIInputPricePerMillion and AIOutputPricePerMillion are the generation model's prices in USD per
+	// million prompt and reply tokens, and AIEmbeddingPricePerMillion the embedding model's, used for
+	// cost estimates; 0 leaves the cost out
+	AIInputPricePerMillion     float64
+	AIOutputPricePerMillion    float64
+	AIEmbeddingPricePerMillion float64
+	// AISampleRate is the fraction of generation calls, from 0 to 1, whose prompt and reply are kept in
+	// ai_samples, secrets redacted, to debug bad generations; 0 keeps none
+	AISampleRate float64
+
+	// SearchIndexProvider mirrors notes into "meilisearch" or "typesense"; empty disables the mirror
+	SearchIndexProvider string
+	SearchIndexURL      string
+	SearchIndexAPIKey   string
+	// SearchIndexKeywordRouting sends keyword-mode searches to the mirror instead of MongoDB
+	SearchIndexKeywordRouting bool
+
+	// AdminToken is the bearer token the admin-only endpoints require; empty leaves them unregistered
+	AdminToken string
+	// EnablePprof serves the Go profiler under /debug/pprof, behind AdminToken
+	EnablePprof bool
+	// AdminAllowedIPs are the CIDR ranges or addresses the admin, migration, jobs and profiler routes,
+	// and the bulk import, delete, rename and merge routes, accept requests from; empty accepts any
+	// address. It applies on top of AdminToken: the /admin, /jobs and profiler routes also require the
+	// token, while the /migrate and bulk routes are only guarded by this allowlist.
+	AdminAllowedIPs []string
+	// TrustedProxies are the CIDR ranges or addresses of reverse proxies whose X-Forwarded-For header
+	// gives the client address; empty trusts none, so the connecting address is the client's
+	TrustedProxies []string
 }
 
 // LoadConfig loads configuration from environment variables
@@ -45,9 +396,168 @@ func LoadConfig() *Config {
 
 	geminiAPIKey := os.Getenv("GEMINI_API_KEY")
 
+	safetyThreshold := strings.ToLower(strings.TrimSpace(os.Getenv("GEMINI_SAFETY_THRESHOLD")))
+
+	privacyMode := os.Getenv("PRIVACY_MODE") == "true"
+
+	reviewThreshold := DEFAULT_REVIEW_CONFIDENCE
+	if value := os.Getenv("CLASSIFICATION_REVIEW_THRESHOLD"); value != "" {
+		if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+			reviewThreshold = parsed
+		} else {
+			log.Printf("Invalid CLASSIFICATION_REVIEW_THRESHOLD %q, using default %.2f", value, reviewThreshold)
+		}
+	}
+
+	chunkSize := envInt("CHUNK_SIZE", DEFAULT_CHUNK_SIZE)
+	if chunkSize < 1 {
+		log.Printf("Invalid CHUNK_SIZE %d, using default %d", chunkSize, DEFAULT_CHUNK_SIZE)
+		chunkSize = DEFAULT_CHUNK_SIZE
+	}
+	chunkOverlap := envInt("CHUNK_OVERLAP", DEFAULT_CHUNK_OVERLAP)
+	if chunkOverlap < 0 || chunkOverlap >= chunkSize {
+		fallback := min(DEFAULT_CHUNK_OVERLAP, chunkSize/2)
+		log.Printf("Invalid CHUNK_OVERLAP %d: must be less than CHUNK_SIZE, using %d", chunkOverlap, fallback)
+		chunkOverlap = fallback
+	}
+
+	aiProvider := strings.ToLower(strings.TrimSpace(os.Getenv("AI_PROVIDER")))
+	if aiProvider == "" {
+		aiProvider = AI_PROVIDER_GEMINI
+	}
+	generationModel, embeddingModel := DEFAULT_GENERATION_MODEL, DEFAULT_EMBEDDING_MODEL
+	if aiProvider == AI_PROVIDER_OPENAI {
+		generationModel, embeddingModel = OPENAI_GENERATION_MODEL, OPENAI_EMBEDDING_MODEL
+	}
+	generationModel = envString("GENERATION_MODEL", generationModel)
+	maxOutputTokens := envInt("GENERATION_MAX_OUTPUT_TOKENS", 0)
+	if maxOutputTokens < 0 {
+		log.Printf("Invalid GENERATION_MAX_OUTPUT_TOKENS %d, using the model's default", maxOutputTokens)
+		maxOutputTokens = 0
+	}
+
+	transcriptionAPIKey := os.Getenv("TRANSCRIPTION_API_KEY")
+	transcriptionURL := os.Getenv("TRANSCRIPTION_URL")
+	if transcriptionURL == "" && transcriptionAPIKey != "" {
+		transcriptionURL = DEFAULT_TRANSCRIPTION_URL
+	}
+
 	return &Config{
-		MongoURI:     mongoURI,
-		QdrantURL:    qdrantURL,
-		GeminiAPIKey: geminiAPIKey,
+		MongoURI:        mongoURI,
+		QdrantURL:       qdrantURL,
+		GeminiAPIKey:    geminiAPIKey,
+		SafetyThreshold: safetyThreshold,
+		PrivacyMode:     privacyMode,
+		InferMetadata:   os.Getenv("INFER_METADATA") != "false",
+
+		AIProvider:   aiProvider,
+		OpenAIURL:    strings.TrimSuffix(envString("OPENAI_URL", DEFAULT_OPENAI_URL), "/"),
+		OpenAIAPIKey: os.Getenv("OPENAI_API_KEY"),
+
+		GenerationModel:           generationModel,
+		AnswerModel:               envString("ANSWER_MODEL", generationModel),
+		GenerationTemperature:     envFloat("GENERATION_TEMPERATURE", -1),
+		GenerationMaxOutputTokens: maxOutputTokens,
+
+		AIRequestsPerMinute: envInt("AI_REQUESTS_PER_MINUTE", DEFAULT_AI_REQUESTS_PER_MINUTE),
+		AIMaxRetries:        envInt("AI_MAX_RETRIES", DEFAULT_AI_MAX_RETRIES),
+
+		ReviewConfidenceThreshold: reviewThreshold,
+
+		BoostPinned:         envFloat("SEARCH_BOOST_PINNED", DEFAULT_BOOST_PINNED),
+		BoostSummary:        envFloat("SEARCH_BOOST_SUMMARY", DEFAULT_BOOST_SUMMARY),
+		BoostRecency:        envFloat("SEARCH_BOOST_RECENCY", DEFAULT_BOOST_RECENCY),
+		RecencyHalfLifeDays: envFloat("SEARCH_RECENCY_HALF_LIFE_DAYS", DEFAULT_RECENCY_HALF_LIFE_DAYS),
+		BoostPopularity:     envFloat("SEARCH_BOOST_POPULARITY", DEFAULT_BOOST_POPULARITY),
+
+		MinSearchScore: float32(envFloat("MIN_SEARCH_SCORE", DEFAULT_MIN_SEARCH_SCORE)),
+		MinAnswerScore: float32(envFloat("MIN_ANSWER_SCORE", DEFAULT_MIN_ANSWER_SCORE)),
+
+		ChunkSize:    chunkSize,
+		ChunkOverlap: chunkOverlap,
+
+		MaxSourcesPerChannel: envInt("MAX_SOURCES_PER_CHANNEL", DEFAULT_MAX_SOURCES_PER_CHANNEL),
+
+		ReconcileIntervalHours: envFloat("RECONCILE_INTERVAL_HOURS", DEFAULT_RECONCILE_INTERVAL_HOURS),
+		ReconcileDriftAlert:    envFloat("RECONCILE_DRIFT_ALERT", DEFAULT_RECONCILE_DRIFT_ALERT),
+
+		YouTubeAPIKey:            os.Getenv("YOUTUBE_API_KEY"),
+		YouTubeAPIURL:            envString("YOUTUBE_API_URL", DEFAULT_YOUTUBE_API_URL),
+		YouTubeWatchURL:          envString("YOUTUBE_WATCH_URL", DEFAULT_YOUTUBE_WATCH_URL),
+		YouTubeSyncIntervalHours: envFloat("YOUTUBE_SYNC_INTERVAL_HOURS", DEFAULT_YOUTUBE_SYNC_INTERVAL_HOURS),
+
+		PodcastSyncIntervalHours: envFloat("PODCAST_SYNC_INTERVAL_HOURS", DEFAULT_PODCAST_SYNC_INTERVAL_HOURS),
+		TranscriptionURL:         transcriptionURL,
+		TranscriptionAPIKey:      transcriptionAPIKey,
+		TranscriptionModel:       envString("TRANSCRIPTION_MODEL", DEFAULT_TRANSCRIPTION_MODEL),
+
+		EmbeddingURL:    strings.TrimSuffix(os.Getenv("EMBEDDING_URL"), "/"),
+		EmbeddingAPIKey: os.Getenv("EMBEDDING_API_KEY"),
+		EmbeddingModel:  envString("EMBEDDING_MODEL", embeddingModel),
+		EmbeddingDim:    envInt("EMBEDDING_DIM", 0),
+
+		MigrationParallelism:       envInt("MIGRATION_PARALLELISM", DEFAULT_MIGRATION_PARALLELISM),
+		ResummarizePerMinute:       envInt("RESUMMARIZE_PER_MINUTE", DEFAULT_RESUMMARIZE_PER_MINUTE),
+		AIInputPricePerMillion:     envFloat("AI_INPUT_PRICE_PER_MILLION", 0),
+		AIOutputPricePerMillion:    envFloat("AI_OUTPUT_PRICE_PER_MILLION", 0),
+		AIEmbeddingPricePerMillion: envFloat("AI_EMBEDDING_PRICE_PER_MILLION", 0),
+		AISampleRate:               min(max(envFloat("AI_SAMPLE_RATE", 0), 0), 1),
+
+		SearchIndexProvider:       strings.ToLower(strings.TrimSpace(os.Getenv("SEARCH_INDEX_PROVIDER"))),
+		SearchIndexURL:            os.Getenv("SEARCH_INDEX_URL"),
+		SearchIndexAPIKey:         os.Getenv("SEARCH_INDEX_API_KEY"),
+		SearchIndexKeywordRouting: os.Getenv("SEARCH_INDEX_KEYWORD_ROUTING") == "true",
+
+		AdminToken:      os.Getenv("ADMIN_TOKEN"),
+		EnablePprof:     os.Getenv("ENABLE_PPROF") == "true",
+		AdminAllowedIPs: envList("ADMIN_ALLOWED_IPS"),
+		TrustedProxies:  envList("TRUSTED_PROXIES"),
+	}
+}
+
+// envString reads a string environment variable, falling back to the default when unset
+func envString(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// envList reads a comma-separated environment variable, dropping blank entries
+func envList(name string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(name), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// envInt reads an integer environment variable, falling back to the default when unset or invalid
+func envInt(name string, fallback int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Invalid %s %q, using default %d", name, value, fallback)
+		return fallback
+	}
+	return parsed
+}
+
+// envFloat reads a float environment variable, falling back to the default when unset or invalid
+func envFloat(name string, fallback float64) float64 {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		log.Printf("Invalid %s %q, using default %.2f", name, value, fallback)
+		return fallback
 	}
+	return parsed
 }
@@ -0,0 +1,83 @@
+package config
+
+import "testing"
+
+func TestLoadConfigGeneration(t *testing.T) {
+	// Blank the variables read here so the environment running the tests can't leak in
+	unsetGeneration := func(t *testing.T) {
+		for _, name := range []string{"AI_PROVIDER", "GENERATION_MODEL", "ANSWER_MODEL", "GENERATION_TEMPERATURE", "GENERATION_MAX_OUTPUT_TOKENS"} {
+			t.Setenv(name, "")
+		}
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		unsetGeneration(t)
+		cfg := LoadConfig()
+		if cfg.GenerationModel != DEFAULT_GENERATION_MODEL {
+			t.Errorf("Expected GenerationModel %q, got %q", DEFAULT_GENERATION_MODEL, cfg.GenerationModel)
+		}
+		if cfg.AnswerModel != cfg.GenerationModel {
+			t.Errorf("Expected AnswerModel to default to the generation model, got %q", cfg.AnswerModel)
+		}
+		if cfg.GenerationTemperature >= 0 {
+			t.Errorf("Expected a negative temperature to keep the model's default, got %v", cfg.GenerationTemperature)
+		}
+		if cfg.GenerationMaxOutputTokens != 0 {
+			t.Errorf("Expected no output token cap, got %d", cfg.GenerationMaxOutputTokens)
+		}
+	})
+
+	t.Run("OpenAI defaults", func(t *testing.T) {
+		unsetGeneration(t)
+		t.Setenv("AI_PROVIDER", "OpenAI")
+		cfg := LoadConfig()
+		if cfg.AIProvider != AI_PROVIDER_OPENAI || cfg.GenerationModel != OPENAI_GENERATION_MODEL || cfg.AnswerModel != OPENAI_GENERATION_MODEL {
+			t.Errorf("Expected the OpenAI models, got provider %q, models %q and %q", cfg.AIProvider, cfg.GenerationModel, cfg.AnswerModel)
+		}
+	})
+
+	t.Run("overrides", func(t *testing.T) {
+		unsetGeneration(t)
+		t.Setenv("GENERATION_MODEL", "small-model")
+		t.Setenv("ANSWER_MODEL", "large-model")
+		t.Setenv("GENERATION_TEMPERATURE", "0.3")
+		t.Setenv("GENERATION_MAX_OUTPUT_TOKENS", "512")
+		cfg := LoadConfig()
+		if cfg.GenerationModel != "small-model" || cfg.AnswerModel != "large-model" {
+			t.Errorf("Expected the configured models, got %q and %q", cfg.GenerationModel, cfg.AnswerModel)
+		}
+		if cfg.GenerationTemperature != 0.3 || cfg.GenerationMaxOutputTokens != 512 {
+			t.Errorf("Expected temperature 0.3 and 512 tokens, got %v and %d", cfg.GenerationTemperature, cfg.GenerationMaxOutputTokens)
+		}
+	})
+
+	t.Run("the answer model follows GENERATION_MODEL unless set", func(t *testing.T) {
+		unsetGeneration(t)
+		t.Setenv("GENERATION_MODEL", "small-model")
+		if cfg := LoadConfig(); cfg.AnswerModel != "small-model" {
+			t.Errorf("Expected AnswerModel small-model, got %q", cfg.AnswerModel)
+		}
+	})
+
+	t.Run("invalid values fall back to the defaults", func(t *testing.T) {
+		for _, tc := range []struct {
+			name, temperature, tokens string
+		}{
+			{"non-numeric", "warm", "many"},
+			{"negative", "-0.5", "-100"},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				unsetGeneration(t)
+				t.Setenv("GENERATION_TEMPERATURE", tc.temperature)
+				t.Setenv("GENERATION_MAX_OUTPUT_TOKENS", tc.tokens)
+				cfg := LoadConfig()
+				if cfg.GenerationTemperature >= 0 {
+					t.Errorf("Expected the model's default temperature, got %v", cfg.GenerationTemperature)
+				}
+				if cfg.GenerationMaxOutputTokens != 0 {
+					t.Errorf("Expected no output token cap, got %d", cfg.GenerationMaxOutputTokens)
+				}
+			})
+		}
+	})
+}
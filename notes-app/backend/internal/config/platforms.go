@@ -0,0 +1,53 @@
+package config
+
+import "strings"
+
+// Platform describes a content source notes can come from
+type Platform struct {
+	Name           string   `json:"name"` // Canonical name stored in metadata.platform
+	DisplayName    string   `json:"displayName"`
+	Icon           string   `json:"icon"`
+	HasTranscripts bool     `json:"hasTranscripts"` // Long-form transcripts that get summarized on ingest
+	Aliases        []string `json:"aliases"`
+}
+
+// PLATFORMS defines all supported note platforms
+var PLATFORMS = []Platform{
+	{Name: "youtube", DisplayName: "YouTube", Icon: "📺", HasTranscripts: true, Aliases: []string{"yt", "you tube", "youtube.com"}},
+	{Name: "twitter", DisplayName: "Twitter / X", Icon: "🐦", Aliases: []string{"x", "x.com", "twitter.com", "tweet"}},
+	{Name: "linkedin", DisplayName: "LinkedIn", Icon: "💼", Aliases: []string{"linked in", "linkedin.com"}},
+	{Name: "podcast", DisplayName: "Podcast", Icon: "🎙️", HasTranscripts: true, Aliases: []string{"podcasts"}},
+	{Name: "reddit", DisplayName: "Reddit", Icon: "👽", Aliases: []string{"reddit.com"}},
+	{Name: "web", DisplayName: "Web", Icon: "🌐", Aliases: []string{"website", "article", "blog"}},
+}
+
+// LookupPlatform finds a platform by canonical name or alias, ignoring case and surrounding whitespace
+func LookupPlatform(platform string) (*Platform, bool) {
+	key := strings.ToLower(strings.TrimSpace(platform))
+	for i := range PLATFORMS {
+		if PLATFORMS[i].Name == key {
+			return &PLATFORMS[i], true
+		}
+		for _, alias := range PLATFORMS[i].Aliases {
+			if alias == key {
+				return &PLATFORMS[i], true
+			}
+		}
+	}
+	return nil, false
+}
+
+// IsValidPlatform checks if a platform name or alias is in the registry
+func IsValidPlatform(platform string) bool {
+	_, ok := LookupPlatform(platform)
+	return ok
+}
+
+// NormalizePlatform returns the canonical form of a platform name used to namespace channels
+// Unknown platforms are lowercased and trimmed so lookups stay consistent
+func NormalizePlatform(platform string) string {
+	if p, ok := LookupPlatform(platform); ok {
+		return p.Name
+	}
+	return strings.ToLower(strings.TrimSpace(platform))
+}
@@ -0,0 +1,502 @@
+// Package graphql is a small GraphQL query executor
+// It supports the executable query language (operations, variables, aliases, fragments and
+// @include/@skip) over a code-defined schema. Mutations and introspection beyond __typename are
+// not supported; writes go through the REST API.
+//
+// Fields resolve in batches: a resolver receives every parent object at its level of the query
+// at once, so a nested field costs one lookup per level rather than one per parent.
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"backend/internal/config"
+)
+
+// Resolver resolves a field for every parent at one level of the query
+// It returns one value per parent, in order. List fields return []interface{} values and object
+// fields return values passed as parents to the object's own field resolvers; nil means null.
+type Resolver func(ctx context.Context, parents []interface{}, args map[string]interface{}) ([]interface{}, error)
+
+// Object is a GraphQL object type
+type Object struct {
+	Name   string
+	Fields map[string]*Field
+}
+
+// Field is a field of an object type
+type Field struct {
+	Type    *Object           // Object type of the field; nil for scalars
+	List    bool              // Whether the field is a list of Type (or of scalars)
+	Args    map[string]string // Argument types by name, e.g. "ID!", "Int", "[String]"
+	Resolve Resolver
+}
+
+// Schema is an executable schema
+type Schema struct {
+	Query *Object
+
+	// Prepare, when set, is called once per request, e.g. to attach per-request loaders
+	Prepare func(ctx context.Context) context.Context
+}
+
+// Error is a GraphQL error as returned in the response
+type Error struct {
+	Message string        `json:"message"`
+	Path    []interface{} `json:"path,omitempty"`
+}
+
+// Response is a GraphQL response
+type Response struct {
+	Data   *OrderedMap `json:"data,omitempty"`
+	Errors []Error     `json:"errors,omitempty"`
+}
+
+// OrderedMap is a JSON object that keeps the order of the selected fields
+type OrderedMap struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func newOrderedMap() *OrderedMap {
+	return &OrderedMap{values: make(map[string]interface{})}
+}
+
+func (m *OrderedMap) set(key string, value interface{}) {
+	if _, exists := m.values[key]; !exists {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+// Get returns the value of a key
+func (m *OrderedMap) Get(key string) interface{} {
+	return m.values[key]
+}
+
+// MarshalJSON writes the fields in selection order
+func (m *OrderedMap) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		encodedKey, _ := json.Marshal(key)
+		b.Write(encodedKey)
+		b.WriteByte(':')
+		encoded, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(encoded)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// RequestError is an error in the request itself, reported without executing anything
+type RequestError struct {
+	Message string
+}
+
+func (e *RequestError) Error() string {
+	return e.Message
+}
+
+// execution holds the state of one request
+type execution struct {
+	doc       *document
+	variables map[string]interface{}
+	errors    []Error
+}
+
+// Execute parses, validates and runs a query
+// Problems with the request (syntax, unknown fields, missing variables) are returned as a
+// *RequestError; resolver failures null the affected field and are listed in the response.
+func (s *Schema) Execute(ctx context.Context, query, operationName string, variables map[string]interface{}) (*Response, error) {
+	doc, err := parse(query)
+	if err != nil {
+		return nil, &RequestError{Message: "syntax error: " + err.Error()}
+	}
+
+	op, err := selectOperation(doc, operationName)
+	if err != nil {
+		return nil, &RequestError{Message: err.Error()}
+	}
+	if op.kind != "query" {
+		return nil, &RequestError{Message: op.kind + " operations are not supported; use the REST API"}
+	}
+
+	if err := validateSelections(doc, s.Query, op.selections, 1, map[string]bool{}); err != nil {
+		return nil, &RequestError{Message: err.Error()}
+	}
+
+	ex := &execution{doc: doc}
+	if ex.variables, err = coerceVariables(op.variables, variables); err != nil {
+		return nil, &RequestError{Message: err.Error()}
+	}
+
+	if s.Prepare != nil {
+		ctx = s.Prepare(ctx)
+	}
+
+	results := ex.executeFields(ctx, s.Query, []interface{}{nil}, op.selections, nil)
+	return &Response{Data: results[0], Errors: ex.errors}, nil
+}
+
+// selectOperation picks the operation to run
+func selectOperation(doc *document, name string) (*operation, error) {
+	if name == "" {
+		if len(doc.operations) > 1 {
+			return nil, fmt.Errorf("operationName is required when the document has several operations")
+		}
+		return doc.operations[0], nil
+	}
+	for _, op := range doc.operations {
+		if op.name == name {
+			return op, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown operation %q", name)
+}
+
+// validateSelections checks that every selected field exists with known arguments and the right
+// kind of sub-selection, and that the query isn't nested beyond config.GRAPHQL_MAX_DEPTH
+func validateSelections(doc *document, obj *Object, selections []*selection, depth int, visiting map[string]bool) error {
+	if depth > config.GRAPHQL_MAX_DEPTH {
+		return fmt.Errorf("query is nested deeper than %d levels", config.GRAPHQL_MAX_DEPTH)
+	}
+
+	for _, sel := range selections {
+		switch {
+		case sel.fragmentSpread != "":
+			frag, ok := doc.fragments[sel.fragmentSpread]
+			if !ok {
+				return fmt.Errorf("unknown fragment %q", sel.fragmentSpread)
+			}
+			if visiting[frag.name] {
+				return fmt.Errorf("fragment %q spreads itself", frag.name)
+			}
+			if frag.typeCondition != obj.Name {
+				return fmt.Errorf("fragment %q on %s cannot be spread in %s", frag.name, frag.typeCondition, obj.Name)
+			}
+			visiting[frag.name] = true
+			err := validateSelections(doc, obj, frag.selections, depth, visiting)
+			delete(visiting, frag.name)
+			if err != nil {
+				return err
+			}
+
+		case sel.inline != nil:
+			if sel.inline.typeCondition != "" && sel.inline.typeCondition != obj.Name {
+				return fmt.Errorf("inline fragment on %s cannot be used in %s", sel.inline.typeCondition, obj.Name)
+			}
+			if err := validateSelections(doc, obj, sel.inline.selections, depth, visiting); err != nil {
+				return err
+			}
+
+		default:
+			f := sel.field
+			if f.name == "__typename" {
+				if len(f.selections) > 0 {
+					return fmt.Errorf("field __typename cannot have a selection")
+				}
+				continue
+			}
+
+			def, ok := obj.Fields[f.name]
+			if !ok {
+				return fmt.Errorf("unknown field %q on %s", f.name, obj.Name)
+			}
+			for name := range f.args {
+				if _, ok := def.Args[name]; !ok {
+					return fmt.Errorf("unknown argument %q on %s.%s", name, obj.Name, f.name)
+				}
+			}
+			for name, typ := range def.Args {
+				if _, given := f.args[name]; !given && strings.HasSuffix(typ, "!") {
+					return fmt.Errorf("missing required argument %q on %s.%s", name, obj.Name, f.name)
+				}
+			}
+
+			if def.Type == nil && len(f.selections) > 0 {
+				return fmt.Errorf("field %s.%s is a scalar and cannot have a selection", obj.Name, f.name)
+			}
+			if def.Type != nil {
+				if len(f.selections) == 0 {
+					return fmt.Errorf("field %s.%s of type %s needs a selection", obj.Name, f.name, def.Type.Name)
+				}
+				if err := validateSelections(doc, def.Type, f.selections, depth+1, visiting); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, d := range sel.directives {
+			if d.name != "include" && d.name != "skip" {
+				return fmt.Errorf("unknown directive @%s", d.name)
+			}
+			if _, ok := d.args["if"]; !ok {
+				return fmt.Errorf("directive @%s needs an if argument", d.name)
+			}
+		}
+	}
+	return nil
+}
+
+// executeFields resolves a selection set for every parent at once, returning one map per parent
+func (ex *execution) executeFields(ctx context.Context, obj *Object, parents []interface{}, selections []*selection, path []interface{}) []*OrderedMap {
+	results := make([]*OrderedMap, len(parents))
+	for i := range results {
+		results[i] = newOrderedMap()
+	}
+
+	for _, f := range ex.collectFields(selections) {
+		key := f.responseKey()
+		fieldPath := append(append([]interface{}{}, path...), key)
+
+		if f.name == "__typename" {
+			for _, result := range results {
+				result.set(key, obj.Name)
+			}
+			continue
+		}
+
+		def := obj.Fields[f.name]
+		values, err := ex.resolve(ctx, def, parents, f)
+		if err != nil {
+			ex.errors = append(ex.errors, Error{Message: err.Error(), Path: fieldPath})
+			for _, result := range results {
+				result.set(key, nil)
+			}
+			continue
+		}
+
+		if def.Type == nil {
+			for i, result := range results {
+				result.set(key, values[i])
+			}
+			continue
+		}
+
+		// Flatten the children of every parent into one batch for the next level
+		var children []interface{}
+		for _, value := range values {
+			if def.List {
+				if items, ok := value.([]interface{}); ok {
+					children = append(children, items...)
+				}
+			} else if value != nil {
+				children = append(children, value)
+			}
+		}
+		childResults := ex.executeFields(ctx, def.Type, children, f.selections, fieldPath)
+
+		next := 0
+		for i, value := range values {
+			switch {
+			case value == nil:
+				results[i].set(key, nil)
+			case def.List:
+				items, _ := value.([]interface{})
+				list := make([]interface{}, len(items))
+				for j := range items {
+					list[j] = childResults[next]
+					next++
+				}
+				results[i].set(key, list)
+			default:
+				results[i].set(key, childResults[next])
+				next++
+			}
+		}
+	}
+	return results
+}
+
+// resolve runs a field resolver with its coerced arguments
+func (ex *execution) resolve(ctx context.Context, def *Field, parents []interface{}, f *field) ([]interface{}, error) {
+	if len(parents) == 0 {
+		return nil, nil
+	}
+
+	args := make(map[string]interface{}, len(def.Args))
+	for name, typ := range def.Args {
+		raw, given := f.args[name]
+		if !given {
+			continue
+		}
+		value, err := coerce(typ, ex.substitute(raw))
+		if err != nil {
+			return nil, fmt.Errorf("invalid argument %q: %w", name, err)
+		}
+		if value != nil {
+			args[name] = value
+		}
+	}
+
+	values, err := def.Resolve(ctx, parents, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != len(parents) {
+		return nil, fmt.Errorf("internal error: resolver returned %d values for %d parents", len(values), len(parents))
+	}
+	return values, nil
+}
+
+// collectFields flattens fragments and applies @include/@skip, merging fields that share a
+// response key so their sub-selections resolve together
+func (ex *execution) collectFields(selections []*selection) []*field {
+	var fields []*field
+	byKey := make(map[string]*field)
+
+	var collect func([]*selection)
+	collect = func(selections []*selection) {
+		for _, sel := range selections {
+			if !ex.included(sel.directives) {
+				continue
+			}
+			switch {
+			case sel.fragmentSpread != "":
+				collect(ex.doc.fragments[sel.fragmentSpread].selections)
+			case sel.inline != nil:
+				collect(sel.inline.selections)
+			default:
+				key := sel.field.responseKey()
+				if existing, ok := byKey[key]; ok {
+					merged := *existing
+					merged.selections = append(append([]*selection{}, existing.selections...), sel.field.selections...)
+					*existing = merged
+					continue
+				}
+				copied := *sel.field
+				byKey[key] = &copied
+				fields = append(fields, &copied)
+			}
+		}
+	}
+	collect(selections)
+	return fields
+}
+
+// included evaluates @include(if:) and @skip(if:)
+func (ex *execution) included(directives []*directive) bool {
+	for _, d := range directives {
+		value, _ := ex.substitute(d.args["if"]).(bool)
+		if (d.name == "include" && !value) || (d.name == "skip" && value) {
+			return false
+		}
+	}
+	return true
+}
+
+// substitute replaces variable references in a value with the variable values
+func (ex *execution) substitute(value interface{}) interface{} {
+	switch v := value.(type) {
+	case variableRef:
+		return ex.variables[string(v)]
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			list[i] = ex.substitute(item)
+		}
+		return list
+	case map[string]interface{}:
+		object := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			object[key] = ex.substitute(item)
+		}
+		return object
+	}
+	return value
+}
+
+// coerceVariables applies defaults and checks required variables are given
+func coerceVariables(defs []*variableDef, given map[string]interface{}) (map[string]interface{}, error) {
+	variables := make(map[string]interface{}, len(defs))
+	for _, def := range defs {
+		value, ok := given[def.name]
+		if !ok {
+			value = def.defaultValue
+		}
+		if value == nil && strings.HasSuffix(def.typ, "!") {
+			return nil, fmt.Errorf("variable $%s of type %s is required", def.name, def.typ)
+		}
+		coerced, err := coerce(def.typ, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid variable $%s: %w", def.name, err)
+		}
+		variables[def.name] = coerced
+	}
+	return variables, nil
+}
+
+// coerce converts a value to a type: String and ID give string, Int gives int, Float gives
+// float64, Boolean gives bool and lists give []interface{}. Other (enum or input) types are
+// passed through. A single value given for a list becomes a one-item list.
+func coerce(typ string, value interface{}) (interface{}, error) {
+	nonNull := strings.HasSuffix(typ, "!")
+	typ = strings.TrimSuffix(typ, "!")
+	if value == nil {
+		if nonNull {
+			return nil, fmt.Errorf("expected non-null %s", typ)
+		}
+		return nil, nil
+	}
+
+	if strings.HasPrefix(typ, "[") {
+		itemType := typ[1 : len(typ)-1]
+		items, ok := value.([]interface{})
+		if !ok {
+			items = []interface{}{value}
+		}
+		list := make([]interface{}, len(items))
+		for i, item := range items {
+			coerced, err := coerce(itemType, item)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = coerced
+		}
+		return list, nil
+	}
+
+	switch typ {
+	case "String", "ID":
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+	case "Int":
+		switch n := value.(type) {
+		case int:
+			return n, nil
+		case float64: // JSON variables decode as float64
+			if n == float64(int(n)) {
+				return int(n), nil
+			}
+		}
+	case "Float":
+		switch n := value.(type) {
+		case int:
+			return float64(n), nil
+		case float64:
+			return n, nil
+		}
+	case "Boolean":
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+	default:
+		if e, ok := value.(enumValue); ok {
+			return string(e), nil
+		}
+		return value, nil
+	}
+	return nil, fmt.Errorf("expected %s, got %v", typ, value)
+}
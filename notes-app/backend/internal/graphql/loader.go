@@ -0,0 +1,57 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+)
+
+// Loader batches and caches keyed lookups for the lifetime of one request
+// Resolvers call LoadMany with every key at their level; only keys not seen earlier in the
+// request are fetched, in a single call, so repeated and nested lookups don't multiply queries.
+type Loader[K comparable, V any] struct {
+	fetch func(ctx context.Context, keys []K) (map[K]V, error)
+
+	mu    sync.Mutex
+	cache map[K]V
+}
+
+// NewLoader creates a Loader that fetches missing keys with fetch
+// fetch may leave keys out of its result; they are cached as the zero value
+func NewLoader[K comparable, V any](fetch func(ctx context.Context, keys []K) (map[K]V, error)) *Loader[K, V] {
+	return &Loader[K, V]{
+		fetch: fetch,
+		cache: make(map[K]V),
+	}
+}
+
+// LoadMany returns the values for keys, fetching uncached ones in one batch
+func (l *Loader[K, V]) LoadMany(ctx context.Context, keys []K) (map[K]V, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var missing []K
+	seen := make(map[K]bool)
+	for _, key := range keys {
+		if _, cached := l.cache[key]; cached || seen[key] {
+			continue
+		}
+		seen[key] = true
+		missing = append(missing, key)
+	}
+
+	if len(missing) > 0 {
+		fetched, err := l.fetch(ctx, missing)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range missing {
+			l.cache[key] = fetched[key]
+		}
+	}
+
+	values := make(map[K]V, len(keys))
+	for _, key := range keys {
+		values[key] = l.cache[key]
+	}
+	return values, nil
+}
@@ -0,0 +1,660 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// document is a parsed GraphQL request document
+type document struct {
+	operations []*operation
+	fragments  map[string]*fragment
+}
+
+// operation is a query (or an unsupported mutation/subscription) with its variables
+type operation struct {
+	kind       string
+	name       string
+	variables  []*variableDef
+	selections []*selection
+}
+
+// variableDef declares an operation variable
+type variableDef struct {
+	name         string
+	typ          string
+	defaultValue interface{}
+}
+
+// fragment is a named fragment definition
+type fragment struct {
+	name          string
+	typeCondition string
+	selections    []*selection
+}
+
+// selection is a field, a fragment spread or an inline fragment
+type selection struct {
+	field          *field
+	fragmentSpread string
+	inline         *fragment // Named "" with an optional type condition
+	directives     []*directive
+}
+
+// field selects a field, optionally aliased, with arguments and a sub-selection
+type field struct {
+	alias      string
+	name       string
+	args       map[string]interface{}
+	selections []*selection
+}
+
+// responseKey is the key the field is returned under
+func (f *field) responseKey() string {
+	if f.alias != "" {
+		return f.alias
+	}
+	return f.name
+}
+
+// directive is an @include or @skip annotation
+type directive struct {
+	name string
+	args map[string]interface{}
+}
+
+// variableRef is a $variable used as a value; it's replaced at execution time
+type variableRef string
+
+// enumValue is a bare enum literal
+type enumValue string
+
+// Token kinds
+const (
+	tokenEOF = iota
+	tokenPunct
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind  int
+	value string
+	pos   int
+}
+
+// parser is a recursive-descent parser for the executable subset of GraphQL
+type parser struct {
+	src string
+	pos int
+	tok token
+}
+
+// parse parses a GraphQL request document
+func parse(src string) (*document, error) {
+	p := &parser{src: src}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	doc := &document{fragments: make(map[string]*fragment)}
+	for p.tok.kind != tokenEOF {
+		switch {
+		case p.is(tokenPunct, "{"):
+			selections, err := p.parseSelectionSet()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, &operation{kind: "query", selections: selections})
+		case p.is(tokenName, "query"), p.is(tokenName, "mutation"), p.is(tokenName, "subscription"):
+			op, err := p.parseOperation()
+			if err != nil {
+				return nil, err
+			}
+			doc.operations = append(doc.operations, op)
+		case p.is(tokenName, "fragment"):
+			frag, err := p.parseFragment()
+			if err != nil {
+				return nil, err
+			}
+			if _, exists := doc.fragments[frag.name]; exists {
+				return nil, fmt.Errorf("fragment %q is defined more than once", frag.name)
+			}
+			doc.fragments[frag.name] = frag
+		default:
+			return nil, p.unexpected()
+		}
+	}
+
+	if len(doc.operations) == 0 {
+		return nil, fmt.Errorf("document contains no operations")
+	}
+	return doc, nil
+}
+
+func (p *parser) parseOperation() (*operation, error) {
+	op := &operation{kind: p.tok.value}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind == tokenName {
+		op.name = p.tok.value
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.is(tokenPunct, "(") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		for !p.is(tokenPunct, ")") {
+			def, err := p.parseVariableDef()
+			if err != nil {
+				return nil, err
+			}
+			op.variables = append(op.variables, def)
+		}
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := p.parseDirectives(); err != nil {
+		return nil, err
+	}
+	selections, err := p.parseSelectionSet()
+	if err != nil {
+		return nil, err
+	}
+	op.selections = selections
+	return op, nil
+}
+
+func (p *parser) parseVariableDef() (*variableDef, error) {
+	if err := p.expect(tokenPunct, "$"); err != nil {
+		return nil, err
+	}
+	name, err := p.parseName()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(tokenPunct, ":"); err != nil {
+		return nil, err
+	}
+	typ, err := p.parseType()
+	if err != nil {
+		return nil, err
+	}
+
+	def := &variableDef{name: name, typ: typ}
+	if p.is(tokenPunct, "=") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		if def.defaultValue, err = p.parseValue(true); err != nil {
+			return nil, err
+		}
+	}
+	return def, nil
+}
+
+// parseType returns a type reference in its source form, e.g. "[String!]!"
+func (p *parser) parseType() (string, error) {
+	var typ string
+	if p.is(tokenPunct, "[") {
+		if err := p.next(); err != nil {
+			return "", err
+		}
+		inner, err := p.parseType()
+		if err != nil {
+			return "", err
+		}
+		if err := p.expect(tokenPunct, "]"); err != nil {
+			return "", err
+		}
+		typ = "[" + inner + "]"
+	} else {
+		name, err := p.parseName()
+		if err != nil {
+			return "", err
+		}
+		typ = name
+	}
+
+	if p.is(tokenPunct, "!") {
+		if err := p.next(); err != nil {
+			return "", err
+		}
+		typ += "!"
+	}
+	return typ, nil
+}
+
+func (p *parser) parseFragment() (*fragment, error) {
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	name, err := p.parseName()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(tokenName, "on"); err != nil {
+		return nil, err
+	}
+	typeCondition, err := p.parseName()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.parseDirectives(); err != nil {
+		return nil, err
+	}
+	selections, err := p.parseSelectionSet()
+	if err != nil {
+		return nil, err
+	}
+	return &fragment{name: name, typeCondition: typeCondition, selections: selections}, nil
+}
+
+func (p *parser) parseSelectionSet() ([]*selection, error) {
+	if err := p.expect(tokenPunct, "{"); err != nil {
+		return nil, err
+	}
+
+	var selections []*selection
+	for !p.is(tokenPunct, "}") {
+		sel, err := p.parseSelection()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, sel)
+	}
+	if len(selections) == 0 {
+		return nil, fmt.Errorf("empty selection set at %s", p.location(p.tok.pos))
+	}
+	return selections, p.next()
+}
+
+func (p *parser) parseSelection() (*selection, error) {
+	if p.is(tokenPunct, "...") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+
+		// A fragment spread names a fragment; "on" or a directive/brace starts an inline fragment
+		if p.tok.kind == tokenName && p.tok.value != "on" {
+			name := p.tok.value
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+			directives, err := p.parseDirectives()
+			if err != nil {
+				return nil, err
+			}
+			return &selection{fragmentSpread: name, directives: directives}, nil
+		}
+
+		inline := &fragment{}
+		if p.is(tokenName, "on") {
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+			typeCondition, err := p.parseName()
+			if err != nil {
+				return nil, err
+			}
+			inline.typeCondition = typeCondition
+		}
+		directives, err := p.parseDirectives()
+		if err != nil {
+			return nil, err
+		}
+		if inline.selections, err = p.parseSelectionSet(); err != nil {
+			return nil, err
+		}
+		return &selection{inline: inline, directives: directives}, nil
+	}
+
+	f := &field{}
+	name, err := p.parseName()
+	if err != nil {
+		return nil, err
+	}
+	if p.is(tokenPunct, ":") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		f.alias = name
+		if name, err = p.parseName(); err != nil {
+			return nil, err
+		}
+	}
+	f.name = name
+
+	if f.args, err = p.parseArguments(); err != nil {
+		return nil, err
+	}
+	directives, err := p.parseDirectives()
+	if err != nil {
+		return nil, err
+	}
+	if p.is(tokenPunct, "{") {
+		if f.selections, err = p.parseSelectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return &selection{field: f, directives: directives}, nil
+}
+
+func (p *parser) parseArguments() (map[string]interface{}, error) {
+	args := map[string]interface{}{}
+	if !p.is(tokenPunct, "(") {
+		return args, nil
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+
+	for !p.is(tokenPunct, ")") {
+		name, err := p.parseName()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenPunct, ":"); err != nil {
+			return nil, err
+		}
+		if _, exists := args[name]; exists {
+			return nil, fmt.Errorf("argument %q is given more than once", name)
+		}
+		if args[name], err = p.parseValue(false); err != nil {
+			return nil, err
+		}
+	}
+	return args, p.next()
+}
+
+func (p *parser) parseDirectives() ([]*directive, error) {
+	var directives []*directive
+	for p.is(tokenPunct, "@") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		name, err := p.parseName()
+		if err != nil {
+			return nil, err
+		}
+		args, err := p.parseArguments()
+		if err != nil {
+			return nil, err
+		}
+		directives = append(directives, &directive{name: name, args: args})
+	}
+	return directives, nil
+}
+
+// parseValue parses a literal or variable; constant contexts (defaults) reject variables
+func (p *parser) parseValue(constant bool) (interface{}, error) {
+	tok := p.tok
+	switch tok.kind {
+	case tokenInt:
+		value, err := strconv.ParseInt(tok.value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer %s at %s", tok.value, p.location(tok.pos))
+		}
+		return int(value), p.next()
+	case tokenFloat:
+		value, err := strconv.ParseFloat(tok.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid float %s at %s", tok.value, p.location(tok.pos))
+		}
+		return value, p.next()
+	case tokenString:
+		return tok.value, p.next()
+	case tokenName:
+		var value interface{}
+		switch tok.value {
+		case "true":
+			value = true
+		case "false":
+			value = false
+		case "null":
+			value = nil
+		default:
+			value = enumValue(tok.value)
+		}
+		return value, p.next()
+	}
+
+	switch {
+	case p.is(tokenPunct, "$") && !constant:
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		name, err := p.parseName()
+		if err != nil {
+			return nil, err
+		}
+		return variableRef(name), nil
+	case p.is(tokenPunct, "["):
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		list := []interface{}{}
+		for !p.is(tokenPunct, "]") {
+			item, err := p.parseValue(constant)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+		}
+		return list, p.next()
+	case p.is(tokenPunct, "{"):
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		object := map[string]interface{}{}
+		for !p.is(tokenPunct, "}") {
+			name, err := p.parseName()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(tokenPunct, ":"); err != nil {
+				return nil, err
+			}
+			if object[name], err = p.parseValue(constant); err != nil {
+				return nil, err
+			}
+		}
+		return object, p.next()
+	}
+	return nil, p.unexpected()
+}
+
+func (p *parser) parseName() (string, error) {
+	if p.tok.kind != tokenName {
+		return "", p.unexpected()
+	}
+	name := p.tok.value
+	return name, p.next()
+}
+
+// is reports whether the current token has the given kind and value
+func (p *parser) is(kind int, value string) bool {
+	return p.tok.kind == kind && p.tok.value == value
+}
+
+// expect consumes the current token if it matches, failing otherwise
+func (p *parser) expect(kind int, value string) error {
+	if !p.is(kind, value) {
+		return fmt.Errorf("expected %q, found %s", value, p.describe())
+	}
+	return p.next()
+}
+
+func (p *parser) unexpected() error {
+	return fmt.Errorf("unexpected %s", p.describe())
+}
+
+// describe names the current token and its location for error messages
+func (p *parser) describe() string {
+	if p.tok.kind == tokenEOF {
+		return "end of document"
+	}
+	return fmt.Sprintf("%q at %s", p.tok.value, p.location(p.tok.pos))
+}
+
+// location formats a byte offset as line:column
+func (p *parser) location(pos int) string {
+	line := 1 + strings.Count(p.src[:pos], "\n")
+	column := pos - strings.LastIndex(p.src[:pos], "\n")
+	return fmt.Sprintf("%d:%d", line, column)
+}
+
+// next advances to the next token, skipping whitespace, commas and comments
+func (p *parser) next() error {
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',' {
+			p.pos++
+			continue
+		}
+		if c == '#' {
+			for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+				p.pos++
+			}
+			continue
+		}
+		break
+	}
+
+	start := p.pos
+	if p.pos >= len(p.src) {
+		p.tok = token{kind: tokenEOF, pos: start}
+		return nil
+	}
+
+	c := p.src[p.pos]
+	switch {
+	case strings.HasPrefix(p.src[p.pos:], "..."):
+		p.pos += 3
+		p.tok = token{kind: tokenPunct, value: "...", pos: start}
+	case strings.IndexByte("!$()[]{}:=@|&", c) >= 0:
+		p.pos++
+		p.tok = token{kind: tokenPunct, value: string(c), pos: start}
+	case c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+		for p.pos < len(p.src) && isNameChar(p.src[p.pos]) {
+			p.pos++
+		}
+		p.tok = token{kind: tokenName, value: p.src[start:p.pos], pos: start}
+	case c == '-' || (c >= '0' && c <= '9'):
+		return p.lexNumber(start)
+	case c == '"':
+		return p.lexString(start)
+	default:
+		r, _ := utf8.DecodeRuneInString(p.src[p.pos:])
+		return fmt.Errorf("unexpected character %q at %s", r, p.location(start))
+	}
+	return nil
+}
+
+func (p *parser) lexNumber(start int) error {
+	kind := tokenInt
+	if p.src[p.pos] == '-' {
+		p.pos++
+	}
+	digits := func() {
+		for p.pos < len(p.src) && p.src[p.pos] >= '0' && p.src[p.pos] <= '9' {
+			p.pos++
+		}
+	}
+	digits()
+	if p.pos < len(p.src) && p.src[p.pos] == '.' {
+		kind = tokenFloat
+		p.pos++
+		digits()
+	}
+	if p.pos < len(p.src) && (p.src[p.pos] == 'e' || p.src[p.pos] == 'E') {
+		kind = tokenFloat
+		p.pos++
+		if p.pos < len(p.src) && (p.src[p.pos] == '+' || p.src[p.pos] == '-') {
+			p.pos++
+		}
+		digits()
+	}
+	p.tok = token{kind: kind, value: p.src[start:p.pos], pos: start}
+	return nil
+}
+
+// lexString reads a quoted string, or a """block string""" with its indentation kept as is
+func (p *parser) lexString(start int) error {
+	if strings.HasPrefix(p.src[p.pos:], `"""`) {
+		end := strings.Index(p.src[p.pos+3:], `"""`)
+		if end < 0 {
+			return fmt.Errorf("unterminated block string at %s", p.location(start))
+		}
+		value := p.src[p.pos+3 : p.pos+3+end]
+		p.pos += end + 6
+		p.tok = token{kind: tokenString, value: strings.TrimSpace(value), pos: start}
+		return nil
+	}
+
+	var b strings.Builder
+	p.pos++
+	for {
+		if p.pos >= len(p.src) || p.src[p.pos] == '\n' {
+			return fmt.Errorf("unterminated string at %s", p.location(start))
+		}
+		c := p.src[p.pos]
+		if c == '"' {
+			p.pos++
+			break
+		}
+		if c != '\\' {
+			b.WriteByte(c)
+			p.pos++
+			continue
+		}
+
+		if p.pos+1 >= len(p.src) {
+			return fmt.Errorf("unterminated string at %s", p.location(start))
+		}
+		escape := p.src[p.pos+1]
+		p.pos += 2
+		switch escape {
+		case '"', '\\', '/':
+			b.WriteByte(escape)
+		case 'b':
+			b.WriteByte('\b')
+		case 'f':
+			b.WriteByte('\f')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case 'u':
+			if p.pos+4 > len(p.src) {
+				return fmt.Errorf("invalid unicode escape at %s", p.location(p.pos))
+			}
+			code, err := strconv.ParseUint(p.src[p.pos:p.pos+4], 16, 32)
+			if err != nil {
+				return fmt.Errorf("invalid unicode escape at %s", p.location(p.pos))
+			}
+			b.WriteRune(rune(code))
+			p.pos += 4
+		default:
+			return fmt.Errorf("invalid escape \\%c at %s", escape, p.location(p.pos-2))
+		}
+	}
+	p.tok = token{kind: tokenString, value: b.String(), pos: start}
+	return nil
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,400 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/services"
+	"backend/internal/utils"
+)
+
+// SchemaSDL documents the schema built by NewSchema; it's served at GET /graphql/schema
+const SchemaSDL = `scalar JSON
+
+type Query {
+  note(id: ID!): Note
+  notes(channel: String, tags: [String], pinned: Boolean, includeArchived: Boolean, limit: Int, offset: Int): [Note]
+  search(query: String!, mode: String, limit: Int): [SearchResult]
+  channels: [ChannelSettings]
+}
+
+type Note {
+  id: ID
+  title: String
+  content: String
+  summary: String
+  category: String
+  tags: [String]
+  pinned: Boolean
+  archived: Boolean
+  needsReview: Boolean
+  created: String
+  author: String
+  platform: String
+  url: String
+  metadata: JSON
+  related(limit: Int): [Note]
+  channel: ChannelSettings
+}
+
+type ChannelSettings {
+  id: ID
+  channelName: String
+  platform: String
+  channelUrl: String
+  aliases: [String]
+  promptText: String
+  searchWeight: Float
+  updatedAt: String
+  notes(limit: Int): [Note]
+}
+
+type SearchResult {
+  score: Float
+  note: Note
+}
+`
+
+// Default list sizes when no limit argument is given
+const (
+	defaultNotesLimit   = 20
+	defaultRelatedLimit = 5
+)
+
+// loaders are the per-request batch loaders, attached to the context by Schema.Prepare
+type loaders struct {
+	channels *Loader[string, []models.ChannelSettings] // Settings by channel lookup key
+}
+
+type loadersKey struct{}
+
+// resolvers holds the services the notes schema resolves against
+type resolvers struct {
+	notesService        *services.NotesService
+	searchService       *services.SearchService
+	channelSettingsRepo *repository.ChannelSettingsRepository
+}
+
+// NewSchema builds the notes schema (see SchemaSDL) over the existing services
+// searchService may be nil when vector search isn't available; the search field then fails
+func NewSchema(
+	notesService *services.NotesService,
+	searchService *services.SearchService,
+	channelSettingsRepo *repository.ChannelSettingsRepository,
+) *Schema {
+	r := &resolvers{
+		notesService:        notesService,
+		searchService:       searchService,
+		channelSettingsRepo: channelSettingsRepo,
+	}
+
+	note := &Object{Name: "Note"}
+	channel := &Object{Name: "ChannelSettings"}
+	searchResult := &Object{Name: "SearchResult"}
+
+	note.Fields = map[string]*Field{
+		"id":          {Resolve: prop(func(n *models.Note) interface{} { return n.ID.Hex() })},
+		"title":       {Resolve: prop(func(n *models.Note) interface{} { return n.Title })},
+		"content":     {Resolve: prop(func(n *models.Note) interface{} { return n.Content })},
+		"summary":     {Resolve: prop(func(n *models.Note) interface{} { return n.Summary })},
+		"category":    {Resolve: prop(func(n *models.Note) interface{} { return n.Category })},
+		"tags":        {List: true, Resolve: prop(func(n *models.Note) interface{} { return nonNilStrings(n.Tags) })},
+		"pinned":      {Resolve: prop(func(n *models.Note) interface{} { return n.Pinned })},
+		"archived":    {Resolve: prop(func(n *models.Note) interface{} { return n.Archived })},
+		"needsReview": {Resolve: prop(func(n *models.Note) interface{} { return n.NeedsReview })},
+		"created":     {Resolve: prop(func(n *models.Note) interface{} { return formatTime(n.Created) })},
+		"author":      {Resolve: prop(func(n *models.Note) interface{} { return metadataString(n, "author") })},
+		"platform":    {Resolve: prop(func(n *models.Note) interface{} { return metadataString(n, "platform") })},
+		"url":         {Resolve: prop(func(n *models.Note) interface{} { return metadataString(n, "url") })},
+		"metadata":    {Resolve: prop(func(n *models.Note) interface{} { return n.Metadata })},
+		"related":     {Type: note, List: true, Args: map[string]string{"limit": "Int"}, Resolve: r.noteRelated},
+		"channel":     {Type: channel, Resolve: r.noteChannel},
+	}
+
+	channel.Fields = map[string]*Field{
+		"id":           {Resolve: prop(func(c *models.ChannelSettings) interface{} { return c.ID.Hex() })},
+		"channelName":  {Resolve: prop(func(c *models.ChannelSettings) interface{} { return c.ChannelName })},
+		"platform":     {Resolve: prop(func(c *models.ChannelSettings) interface{} { return c.Platform })},
+		"channelUrl":   {Resolve: prop(func(c *models.ChannelSettings) interface{} { return c.ChannelUrl })},
+		"aliases":      {List: true, Resolve: prop(func(c *models.ChannelSettings) interface{} { return nonNilStrings(c.Aliases) })},
+		"promptText":   {Resolve: prop(func(c *models.ChannelSettings) interface{} { return c.PromptText })},
+		"searchWeight": {Resolve: prop(func(c *models.ChannelSettings) interface{} { return c.SearchWeight })},
+		"updatedAt":    {Resolve: prop(func(c *models.ChannelSettings) interface{} { return formatTime(c.UpdatedAt) })},
+		"notes":        {Type: note, List: true, Args: map[string]string{"limit": "Int"}, Resolve: r.channelNotes},
+	}
+
+	searchResult.Fields = map[string]*Field{
+		"score": {Resolve: prop(func(s *models.SearchResult) interface{} { return s.Score })},
+		"note":  {Type: note, Resolve: prop(func(s *models.SearchResult) interface{} { return &s.Note })},
+	}
+
+	query := &Object{Name: "Query", Fields: map[string]*Field{
+		"note": {Type: note, Args: map[string]string{"id": "ID!"}, Resolve: r.queryNote},
+		"notes": {Type: note, List: true, Args: map[string]string{
+			"channel":         "String",
+			"tags":            "[String]",
+			"pinned":          "Boolean",
+			"includeArchived": "Boolean",
+			"limit":           "Int",
+			"offset":          "Int",
+		}, Resolve: r.queryNotes},
+		"search":   {Type: searchResult, List: true, Args: map[string]string{"query": "String!", "mode": "String", "limit": "Int"}, Resolve: r.querySearch},
+		"channels": {Type: channel, List: true, Resolve: r.queryChannels},
+	}}
+
+	return &Schema{Query: query, Prepare: r.prepare}
+}
+
+// prepare attaches fresh loaders to a request context
+func (r *resolvers) prepare(ctx context.Context) context.Context {
+	return context.WithValue(ctx, loadersKey{}, &loaders{
+		channels: NewLoader(r.fetchChannels),
+	})
+}
+
+// fetchChannels loads channel settings for lookup keys, listing each under its name and alias keys
+func (r *resolvers) fetchChannels(ctx context.Context, keys []string) (map[string][]models.ChannelSettings, error) {
+	settings, err := r.channelSettingsRepo.FindByChannelKeys(ctx, keys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load channel settings: %w", err)
+	}
+
+	byKey := make(map[string][]models.ChannelSettings)
+	for _, s := range settings {
+		byKey[s.ChannelKey] = append(byKey[s.ChannelKey], s)
+		for _, alias := range s.AliasKeys {
+			if alias != s.ChannelKey {
+				byKey[alias] = append(byKey[alias], s)
+			}
+		}
+	}
+	return byKey, nil
+}
+
+func (r *resolvers) queryNote(ctx context.Context, parents []interface{}, args map[string]interface{}) ([]interface{}, error) {
+	note, err := r.notesService.GetNoteByID(ctx, args["id"].(string))
+	if err != nil {
+		if err.Error() == "note not found" {
+			return []interface{}{nil}, nil
+		}
+		return nil, err
+	}
+	return []interface{}{note}, nil
+}
+
+func (r *resolvers) queryNotes(ctx context.Context, parents []interface{}, args map[string]interface{}) ([]interface{}, error) {
+	limit, err := limitArg(args, defaultNotesLimit)
+	if err != nil {
+		return nil, err
+	}
+	offset, _ := args["offset"].(int)
+	if offset < 0 {
+		return nil, fmt.Errorf("invalid offset, must not be negative")
+	}
+
+	channel, _ := args["channel"].(string)
+	includeArchived, _ := args["includeArchived"].(bool)
+	pinned, _ := args["pinned"].(bool)
+	var tags []string
+	if list, ok := args["tags"].([]interface{}); ok {
+		for _, tag := range list {
+			if s, ok := tag.(string); ok {
+				tags = append(tags, s)
+			}
+		}
+	}
+
+	notes, err := r.notesService.GetNotesPage(ctx, channel, tags, "", includeArchived, pinned, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{notePointers(notes)}, nil
+}
+
+func (r *resolvers) querySearch(ctx context.Context, parents []interface{}, args map[string]interface{}) ([]interface{}, error) {
+	if r.searchService == nil {
+		return nil, fmt.Errorf("search is unavailable")
+	}
+	limit, err := limitArg(args, 10)
+	if err != nil {
+		return nil, err
+	}
+	query := args["query"].(string)
+	mode, _ := args["mode"].(string)
+
+	var results []models.SearchResult
+	switch mode {
+	case "", models.SearchModeSemantic:
+		results, err = r.searchService.SemanticSearch(ctx, query, limit, models.SearchFilters{}, nil)
+	case models.SearchModeHybrid:
+		results, err = r.searchService.HybridSearch(ctx, query, limit, models.SearchFilters{}, nil)
+	case models.SearchModeKeyword:
+		results, err = r.searchService.KeywordSearch(ctx, query, limit, models.SearchFilters{}, nil)
+	default:
+		return nil, fmt.Errorf("invalid search mode, must be semantic, hybrid or keyword")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]interface{}, len(results))
+	for i := range results {
+		list[i] = &results[i]
+	}
+	return []interface{}{list}, nil
+}
+
+func (r *resolvers) queryChannels(ctx context.Context, parents []interface{}, args map[string]interface{}) ([]interface{}, error) {
+	settings, err := r.channelSettingsRepo.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load channel settings: %w", err)
+	}
+
+	list := make([]interface{}, len(settings))
+	for i := range settings {
+		list[i] = &settings[i]
+	}
+	return []interface{}{list}, nil
+}
+
+// noteRelated resolves related notes for a whole level of notes in one query
+func (r *resolvers) noteRelated(ctx context.Context, parents []interface{}, args map[string]interface{}) ([]interface{}, error) {
+	limit, err := limitArg(args, defaultRelatedLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	notes := make([]*models.Note, len(parents))
+	for i, parent := range parents {
+		notes[i] = parent.(*models.Note)
+	}
+	related, err := r.notesService.GetRelatedNotes(ctx, notes, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load related notes: %w", err)
+	}
+
+	values := make([]interface{}, len(notes))
+	for i, note := range notes {
+		values[i] = notePointers(related[note.ID])
+	}
+	return values, nil
+}
+
+// noteChannel resolves each note's channel settings through the request's channel loader
+// Like ChannelSettingsRepository.FindByChannel, settings for the note's platform are preferred
+// over legacy settings saved without a platform
+func (r *resolvers) noteChannel(ctx context.Context, parents []interface{}, args map[string]interface{}) ([]interface{}, error) {
+	keys := make([]string, len(parents))
+	var lookup []string
+	for i, parent := range parents {
+		keys[i] = utils.NormalizeChannelKey(metadataString(parent.(*models.Note), "author"))
+		if keys[i] != "" {
+			lookup = append(lookup, keys[i])
+		}
+	}
+
+	candidates, err := ctx.Value(loadersKey{}).(*loaders).channels.LoadMany(ctx, lookup)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]interface{}, len(parents))
+	for i, parent := range parents {
+		if keys[i] == "" {
+			continue
+		}
+		platform := metadataString(parent.(*models.Note), "platform")
+
+		var match *models.ChannelSettings
+		for _, settings := range candidates[keys[i]] {
+			settings := settings
+			if platform == "" || settings.Platform == platform {
+				match = &settings
+				break
+			}
+			if settings.Platform == "" && match == nil {
+				match = &settings
+			}
+		}
+		if match != nil {
+			values[i] = match
+		}
+	}
+	return values, nil
+}
+
+// channelNotes resolves the notes of a whole level of channels in one query
+func (r *resolvers) channelNotes(ctx context.Context, parents []interface{}, args map[string]interface{}) ([]interface{}, error) {
+	limit, err := limitArg(args, defaultNotesLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(parents))
+	for i, parent := range parents {
+		names[i] = parent.(*models.ChannelSettings).ChannelName
+	}
+	byChannel, err := r.notesService.GetNotesByChannels(ctx, names, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load channel notes: %w", err)
+	}
+
+	values := make([]interface{}, len(parents))
+	for i, name := range names {
+		values[i] = notePointers(byChannel[name])
+	}
+	return values, nil
+}
+
+// prop resolves a scalar field by reading it from each parent
+func prop[T any](get func(T) interface{}) Resolver {
+	return func(ctx context.Context, parents []interface{}, args map[string]interface{}) ([]interface{}, error) {
+		values := make([]interface{}, len(parents))
+		for i, parent := range parents {
+			values[i] = get(parent.(T))
+		}
+		return values, nil
+	}
+}
+
+// limitArg reads the limit argument, between 1 and config.GRAPHQL_MAX_LIMIT
+func limitArg(args map[string]interface{}, fallback int) (int, error) {
+	limit, ok := args["limit"].(int)
+	if !ok {
+		return fallback, nil
+	}
+	if limit < 1 || limit > config.GRAPHQL_MAX_LIMIT {
+		return 0, fmt.Errorf("invalid limit, must be between 1 and %d", config.GRAPHQL_MAX_LIMIT)
+	}
+	return limit, nil
+}
+
+// notePointers converts notes to a list of parents for the Note type
+func notePointers(notes []models.Note) []interface{} {
+	list := make([]interface{}, len(notes))
+	for i := range notes {
+		list[i] = &notes[i]
+	}
+	return list
+}
+
+// metadataString reads a string metadata field, empty when missing
+func metadataString(note *models.Note, key string) string {
+	value, _ := note.Metadata[key].(string)
+	return value
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
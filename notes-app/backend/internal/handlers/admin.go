@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// adminPathPrefixes are the routes that can rewrite or wipe the whole corpus, or expose the process
+var adminPathPrefixes = []string{"/admin", "/migrate", "/jobs", "/debug/pprof"}
+
+// adminRoutes are the single routes outside those prefixes that replace, delete or move notes in
+// bulk, by method and route pattern
+var adminRoutes = []string{
+	"POST /import/json",
+	"POST /notes/bulk-delete",
+	"DELETE /channels/:channel/notes",
+	"POST /categories/rename",
+	"POST /categories/merge",
+}
+
+// RequireAdmin returns middleware rejecting requests that don't carry "Authorization: Bearer <admin token>"
+// Every request is rejected while the admin token is empty.
+func RequireAdmin(adminToken string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || adminToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "admin token required"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RestrictAdminNetwork returns middleware rejecting requests to the admin, migration and bulk
+// delete routes from client addresses outside the networks, before any admin token is checked. The
+// client address is only taken from X-Forwarded-For when the request comes through one of the
+// engine's trusted proxies.
+func RestrictAdminNetwork(networks []netip.Prefix) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !isAdminPath(c.Request.URL.Path) && !isAdminRoute(c.Request.Method, c.FullPath()) {
+			c.Next()
+			return
+		}
+		addr, err := netip.ParseAddr(c.ClientIP())
+		if err != nil || !inNetworks(addr.Unmap(), networks) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin access is not allowed from this address"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// ParseNetworks parses CIDR ranges such as "10.0.0.0/8", or single addresses, into network prefixes
+func ParseNetworks(entries []string) ([]netip.Prefix, error) {
+	networks := make([]netip.Prefix, 0, len(entries))
+	for _, entry := range entries {
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid network %q: %w", entry, err)
+			}
+			networks = append(networks, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %q: %w", entry, err)
+		}
+		networks = append(networks, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+	}
+	return networks, nil
+}
+
+// isAdminPath checks whether a request path is one of the admin path prefixes or below one
+func isAdminPath(path string) bool {
+	for _, prefix := range adminPathPrefixes {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// isAdminRoute checks whether a request method and matched route pattern are one of the admin routes
+func isAdminRoute(method, routePattern string) bool {
+	for _, route := range adminRoutes {
+		if route == method+" "+routePattern {
+			return true
+		}
+	}
+	return false
+}
+
+// inNetworks checks whether an address is in any of the networks
+func inNetworks(addr netip.Addr, networks []netip.Prefix) bool {
+	for _, network := range networks {
+		if network.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
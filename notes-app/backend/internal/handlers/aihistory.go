@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AIHistoryHandler handles HTTP requests for the AI history of notes
+type AIHistoryHandler struct {
+	aiHistoryService *services.AIHistoryService
+}
+
+// NewAIHistoryHandler creates a new AIHistoryHandler
+func NewAIHistoryHandler(aiHistoryService *services.AIHistoryService) *AIHistoryHandler {
+	return &AIHistoryHandler{
+		aiHistoryService: aiHistoryService,
+	}
+}
+
+// GetHistory handles GET /notes/:id/ai-history
+// Lists the AI operations performed on the note, newest first, with the model, prompt version and
+// estimated token usage of each
+func (h *AIHistoryHandler) GetHistory(c *gin.Context) {
+	ops, err := h.aiHistoryService.GetHistory(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err.Error() == "note not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get AI history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ops)
+}
+
+// RegisterRoutes registers the AI history routes on the given router
+func (h *AIHistoryHandler) RegisterRoutes(r *gin.Engine) {
+	r.GET("/notes/:id/ai-history", h.GetHistory)
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"backend/internal/config"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AISamplesHandler handles HTTP requests for the sampled raw prompts and replies of AI calls
+type AISamplesHandler struct {
+	aiSampleService *services.AISampleService
+	adminToken      string
+}
+
+// NewAISamplesHandler creates a new AISamplesHandler guarded by the given admin token
+func NewAISamplesHandler(aiSampleService *services.AISampleService, adminToken string) *AISamplesHandler {
+	return &AISamplesHandler{
+		aiSampleService: aiSampleService,
+		adminToken:      adminToken,
+	}
+}
+
+// GetSamples handles GET /admin/ai-samples
+// Lists the most recent sampled AI calls, newest first. Optional: ?operation= (the AI client method,
+// e.g. GenerateTitle) and ?limit=
+func (h *AISamplesHandler) GetSamples(c *gin.Context) {
+	limit := config.DEFAULT_AI_SAMPLES_LIMIT
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 || parsed > config.MAX_AI_SAMPLES_LIMIT {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid limit, must be between 1 and %d", config.MAX_AI_SAMPLES_LIMIT)})
+			return
+		}
+		limit = parsed
+	}
+
+	samples, err := h.aiSampleService.GetSamples(c.Request.Context(), c.Query("operation"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get AI samples"})
+		return
+	}
+
+	c.JSON(http.StatusOK, samples)
+}
+
+// GetSample handles GET /admin/ai-samples/:id
+// Returns a sampled AI call with its full prompt and reply
+func (h *AISamplesHandler) GetSample(c *gin.Context) {
+	sample, err := h.aiSampleService.GetSample(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err.Error() == "AI sample not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get AI sample"})
+		return
+	}
+
+	c.JSON(http.StatusOK, sample)
+}
+
+// RegisterRoutes registers the AI sample routes on the given router
+func (h *AISamplesHandler) RegisterRoutes(r *gin.Engine) {
+	admin := r.Group("/admin", RequireAdmin(h.adminToken))
+	admin.GET("/ai-samples", h.GetSamples)
+	admin.GET("/ai-samples/:id", h.GetSample)
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AutomationsHandler handles HTTP requests for automation rules
+type AutomationsHandler struct {
+	automationService *services.AutomationService
+}
+
+// NewAutomationsHandler creates a new AutomationsHandler
+func NewAutomationsHandler(automationService *services.AutomationService) *AutomationsHandler {
+	return &AutomationsHandler{
+		automationService: automationService,
+	}
+}
+
+// GetRules handles GET /automations
+func (h *AutomationsHandler) GetRules(c *gin.Context) {
+	rules, err := h.automationService.GetRules(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get automation rules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// GetRule handles GET /automations/:id
+func (h *AutomationsHandler) GetRule(c *gin.Context) {
+	rule, err := h.automationService.GetRuleByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondAutomationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// CreateRule handles POST /automations
+func (h *AutomationsHandler) CreateRule(c *gin.Context) {
+	var rule models.AutomationRule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	created, err := h.automationService.CreateRule(c.Request.Context(), &rule)
+	if err != nil {
+		respondAutomationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// UpdateRule handles PUT /automations/:id
+func (h *AutomationsHandler) UpdateRule(c *gin.Context) {
+	var rule models.AutomationRule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := h.automationService.UpdateRule(c.Request.Context(), c.Param("id"), &rule)
+	if err != nil {
+		respondAutomationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// DeleteRule handles DELETE /automations/:id
+func (h *AutomationsHandler) DeleteRule(c *gin.Context) {
+	if err := h.automationService.DeleteRule(c.Request.Context(), c.Param("id")); err != nil {
+		respondAutomationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Automation rule deleted"})
+}
+
+// respondAutomationError maps automation service errors to HTTP responses
+func respondAutomationError(c *gin.Context, err error) {
+	errMsg := err.Error()
+	switch {
+	case errMsg == "automation rule not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": "Automation rule not found"})
+	case strings.HasPrefix(errMsg, "invalid automation rule"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errMsg})
+	}
+}
+
+// RegisterRoutes registers the automation rules routes on the given router
+func (h *AutomationsHandler) RegisterRoutes(r *gin.Engine) {
+	r.GET("/automations", h.GetRules)
+	r.POST("/automations", h.CreateRule)
+	r.GET("/automations/:id", h.GetRule)
+	r.PUT("/automations/:id", h.UpdateRule)
+	r.DELETE("/automations/:id", h.DeleteRule)
+}
@@ -2,13 +2,13 @@ package handlers
 
 import (
 	"context"
-	"log"
 	"net/http"
+	"strings"
 
-	"backend/internal/ai"
 	"backend/internal/config"
 	"backend/internal/models"
 	"backend/internal/repository"
+	"backend/internal/services"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson"
@@ -17,20 +17,31 @@ import (
 
 // CategoriesHandler handles HTTP requests for category operations
 type CategoriesHandler struct {
-	notesRepo *repository.NotesRepository
-	aiClient  ai.Client
+	notesRepo       *repository.NotesRepository
+	categoryService *services.CategoryService
 }
 
 // NewCategoriesHandler creates a new CategoriesHandler
-func NewCategoriesHandler(notesRepo *repository.NotesRepository, aiClient ai.Client) *CategoriesHandler {
+func NewCategoriesHandler(notesRepo *repository.NotesRepository, categoryService *services.CategoryService) *CategoriesHandler {
 	return &CategoriesHandler{
-		notesRepo: notesRepo,
-		aiClient:  aiClient,
+		notesRepo:       notesRepo,
+		categoryService: categoryService,
 	}
 }
 
 // GetCategories handles GET /categories
+// Lists the categories with their note counts, most notes first, then the empty ones
 func (h *CategoriesHandler) GetCategories(c *gin.Context) {
+	categories, err := h.categoryService.GetCategories(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get categories"})
+		return
+	}
+	descriptions := make(map[string]string, len(categories))
+	for _, category := range categories {
+		descriptions[category.Name] = category.Description
+	}
+
 	pipeline := mongo.Pipeline{
 		{{Key: "$group", Value: bson.M{
 			"_id":   "$category",
@@ -59,8 +70,9 @@ func (h *CategoriesHandler) GetCategories(c *gin.Context) {
 		// Skip empty categories
 		if result.ID != "" {
 			results = append(results, models.CategoryCount{
-				Name:  result.ID,
-				Count: result.Count,
+				Name:        result.ID,
+				Description: descriptions[result.ID],
+				Count:       result.Count,
 			})
 		}
 	}
@@ -71,11 +83,12 @@ func (h *CategoriesHandler) GetCategories(c *gin.Context) {
 		existingCategories[result.Name] = true
 	}
 
-	for _, category := range config.CATEGORIES {
-		if !existingCategories[category] {
+	for _, category := range categories {
+		if !existingCategories[category.Name] {
 			results = append(results, models.CategoryCount{
-				Name:  category,
-				Count: 0,
+				Name:        category.Name,
+				Description: category.Description,
+				Count:       0,
 			})
 		}
 	}
@@ -143,62 +156,129 @@ func (h *CategoriesHandler) GetCategoryStats(c *gin.Context) {
 	response := gin.H{
 		"categories":       categoryStats,
 		"total_notes":      totalNotes,
-		"total_categories": len(config.CATEGORIES),
+		"total_categories": len(config.Categories()),
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
-// ClassifyExistingNotes handles POST /migrate/classify
-func (h *CategoriesHandler) ClassifyExistingNotes(c *gin.Context) {
-	// Find notes without category or with empty category
-	notes, err := h.notesRepo.FindAll(context.Background(), bson.M{
-		"$or": []bson.M{
-			{"category": bson.M{"$exists": false}},
-			{"category": ""},
-		},
-	})
+// CreateCategory handles POST /categories
+func (h *CategoriesHandler) CreateCategory(c *gin.Context) {
+	var category models.Category
+	if err := c.ShouldBindJSON(&category); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	created, err := h.categoryService.CreateCategory(c.Request.Context(), &category)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find notes"})
+		respondCategoryError(c, err)
 		return
 	}
 
-	classified := 0
-	errors := 0
+	c.JSON(http.StatusCreated, created)
+}
 
-	for _, note := range notes {
-		category, err := h.aiClient.ClassifyNote(note.Title, note.Content)
-		if err != nil {
-			log.Printf("Failed to classify note %s: %v", note.ID.Hex(), err)
-			category = "other"
-			errors++
-		}
+// UpdateCategory handles PUT /categories/:name
+// Only the description can change; POST /categories/rename renames a category
+func (h *CategoriesHandler) UpdateCategory(c *gin.Context) {
+	var req struct {
+		Description string `json:"description"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-		err = h.notesRepo.Update(
-			context.Background(),
-			note.ID,
-			bson.M{"$set": bson.M{"category": category}},
-		)
-		if err != nil {
-			log.Printf("Failed to update note %s with category: %v", note.ID.Hex(), err)
-			errors++
-		} else {
-			classified++
-		}
+	category, err := h.categoryService.UpdateCategory(c.Request.Context(), c.Param("name"), req.Description)
+	if err != nil {
+		respondCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, category)
+}
+
+// DeleteCategory handles DELETE /categories/:name
+// Only empty categories can be deleted
+func (h *CategoriesHandler) DeleteCategory(c *gin.Context) {
+	if err := h.categoryService.DeleteCategory(c.Request.Context(), c.Param("name")); err != nil {
+		respondCategoryError(c, err)
+		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message":    "Classification complete",
-		"classified": classified,
-		"errors":     errors,
-		"total":      len(notes),
-	})
+	c.JSON(http.StatusOK, gin.H{"message": "Category deleted"})
+}
+
+// RenameCategory handles POST /categories/rename
+// Body: {"from": "old-name", "to": "new-name", "dryRun": true}; moves the category's notes to the new
+// name, or with dryRun only reports how many would move
+func (h *CategoriesHandler) RenameCategory(c *gin.Context) {
+	var req struct {
+		From   string `json:"from" binding:"required"`
+		To     string `json:"to" binding:"required"`
+		DryRun bool   `json:"dryRun"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	change, err := h.categoryService.RenameCategory(c.Request.Context(), req.From, req.To, req.DryRun)
+	if err != nil {
+		respondCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, change)
+}
+
+// MergeCategories handles POST /categories/merge
+// Body: {"from": ["a", "b"], "to": "c", "dryRun": true}; moves the notes of the from categories into
+// the existing to category and removes them, or with dryRun only reports how many notes would move
+func (h *CategoriesHandler) MergeCategories(c *gin.Context) {
+	var req struct {
+		From   []string `json:"from" binding:"required"`
+		To     string   `json:"to" binding:"required"`
+		DryRun bool     `json:"dryRun"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	change, err := h.categoryService.MergeCategories(c.Request.Context(), req.From, req.To, req.DryRun)
+	if err != nil {
+		respondCategoryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, change)
+}
+
+// respondCategoryError maps category service errors to HTTP responses
+func respondCategoryError(c *gin.Context, err error) {
+	errMsg := err.Error()
+	switch {
+	case strings.HasPrefix(errMsg, "category not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": errMsg})
+	case errMsg == "category already exists" || strings.HasPrefix(errMsg, "category in use"):
+		c.JSON(http.StatusConflict, gin.H{"error": errMsg})
+	case strings.HasPrefix(errMsg, "invalid category"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errMsg})
+	}
 }
 
 // RegisterRoutes registers the category routes on the given router
 func (h *CategoriesHandler) RegisterRoutes(r *gin.Engine) {
 	r.GET("/categories", h.GetCategories)
+	r.POST("/categories", h.CreateCategory)
+	r.PUT("/categories/:name", h.UpdateCategory)
+	r.DELETE("/categories/:name", h.DeleteCategory)
+	r.POST("/categories/rename", h.RenameCategory)
+	r.POST("/categories/merge", h.MergeCategories)
 	r.GET("/notes/category/:category", h.GetNotesByCategory)
 	r.GET("/categories/stats", h.GetCategoryStats)
-	r.POST("/migrate/classify", h.ClassifyExistingNotes)
 }
@@ -5,14 +5,19 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
+	"regexp"
 	"time"
 
+	"backend/internal/config"
 	"backend/internal/models"
 	"backend/internal/repository"
+	"backend/internal/services"
+	"backend/internal/utils"
 	"backend/internal/vectordb"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
@@ -39,39 +44,92 @@ func NewChannelsHandler(
 	}
 }
 
+// channelKey identifies a channel within its platform namespace
+type channelKey struct {
+	Platform string
+	Name     string
+}
+
+// settingsKey returns the channel key for a settings record
+// Names are folded with utils.NormalizeChannelKey so differently written names of the same channel match
+func settingsKey(s models.ChannelSettings) channelKey {
+	return channelKey{Platform: config.NormalizePlatform(s.Platform), Name: utils.NormalizeChannelKey(s.ChannelName)}
+}
+
+// settingsKeys returns the channel keys for a settings record's name and aliases
+func settingsKeys(s models.ChannelSettings) []channelKey {
+	platform := config.NormalizePlatform(s.Platform)
+	keys := []channelKey{settingsKey(s)}
+	for _, alias := range utils.NormalizeChannelKeys(s.Aliases) {
+		keys = append(keys, channelKey{Platform: platform, Name: alias})
+	}
+	return keys
+}
+
+// statKey returns the channel key for aggregated note statistics
+func statKey(stat channelNoteStat) channelKey {
+	return channelKey{Platform: stat.ID.Platform, Name: utils.NormalizeChannelKey(stat.ID.Author)}
+}
+
 // GetChannelsWithNotes handles GET /channels
+// Channels are keyed by (platform, name), so same-named accounts on different platforms are listed separately.
+// Channels that only have settings (configured for sync but not yet synced) are included with hasNotes=false.
+// With ?configuredOnly=true only those channels are returned.
 func (h *ChannelsHandler) GetChannelsWithNotes(c *gin.Context) {
-	// Aggregate to get unique channels (authors) from notes with their platform
-	pipeline := mongo.Pipeline{
-		{{Key: "$match", Value: bson.M{"metadata.author": bson.M{"$exists": true, "$ne": ""}}}},
-		{{Key: "$group", Value: bson.M{
-			"_id":       "$metadata.author",
-			"platform":  bson.M{"$first": "$metadata.platform"},
-			"noteCount": bson.M{"$sum": 1},
-		}}},
-		{{Key: "$sort", Value: bson.M{"noteCount": -1}}},
-	}
+	configuredOnly := c.Query("configuredOnly") == "true"
 
-	cursor, err := h.notesRepo.Aggregate(context.Background(), pipeline)
+	stats, err := h.channelNoteStats(context.Background())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get channels"})
 		return
 	}
-	defer cursor.Close(context.Background())
 
-	var channels []bson.M
-	if err = cursor.All(context.Background(), &channels); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode channels"})
+	settings, err := h.channelSettingsRepo.FindAll(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get channel settings"})
 		return
 	}
 
+	configured := make(map[channelKey]bool, len(settings))
+	for _, s := range settings {
+		for _, key := range settingsKeys(s) {
+			configured[key] = true
+		}
+	}
+
 	// Transform to cleaner format
-	result := make([]gin.H, 0, len(channels))
-	for _, ch := range channels {
+	result := make([]gin.H, 0, len(stats)+len(settings))
+	withNotes := make(map[channelKey]bool, len(stats))
+	for _, stat := range stats {
+		key := statKey(stat)
+		withNotes[key] = true
+		if configuredOnly {
+			continue
+		}
+		result = append(result, gin.H{
+			"name":        stat.ID.Author,
+			"platform":    stat.ID.Platform,
+			"noteCount":   stat.NoteCount,
+			"hasNotes":    true,
+			"hasSettings": configured[key],
+		})
+	}
+
+	// Append channels that are configured but have no notes yet
+	for _, s := range settings {
+		hasNotes := false
+		for _, key := range settingsKeys(s) {
+			hasNotes = hasNotes || withNotes[key]
+		}
+		if hasNotes {
+			continue
+		}
 		result = append(result, gin.H{
-			"name":      ch["_id"],
-			"platform":  ch["platform"],
-			"noteCount": ch["noteCount"],
+			"name":        s.ChannelName,
+			"platform":    s.Platform,
+			"noteCount":   0,
+			"hasNotes":    false,
+			"hasSettings": true,
 		})
 	}
 
@@ -79,6 +137,7 @@ func (h *ChannelsHandler) GetChannelsWithNotes(c *gin.Context) {
 }
 
 // GetAllChannelSettings handles GET /channel-settings
+// Each record includes the channel's note count, latest note and platform consistency
 func (h *ChannelsHandler) GetAllChannelSettings(c *gin.Context) {
 	settings, err := h.channelSettingsRepo.FindAll(context.Background())
 	if err != nil {
@@ -86,14 +145,97 @@ func (h *ChannelsHandler) GetAllChannelSettings(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, settings)
+	stats, err := h.channelNoteStats(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get channel note stats"})
+		return
+	}
+
+	result := make([]models.ChannelSettingsWithStats, 0, len(settings))
+	for _, s := range settings {
+		key := settingsKey(s)
+		names := make(map[string]bool)
+		for _, k := range settingsKeys(s) {
+			names[k.Name] = true
+		}
+
+		entry := models.ChannelSettingsWithStats{
+			ChannelSettings:    s,
+			NotePlatforms:      []string{},
+			PlatformConsistent: true,
+		}
+
+		for _, stat := range stats {
+			if !names[statKey(stat).Name] {
+				continue
+			}
+			entry.NotePlatforms = append(entry.NotePlatforms, stat.ID.Platform)
+
+			// Settings without a platform cover the channel name on every platform
+			if key.Platform != "" && stat.ID.Platform != key.Platform {
+				entry.PlatformConsistent = false
+				continue
+			}
+			entry.NoteCount += stat.NoteCount
+			if entry.LastNoteAt == nil || stat.LastNoteAt.After(*entry.LastNoteAt) {
+				lastNoteAt := stat.LastNoteAt
+				entry.LastNoteAt = &lastNoteAt
+			}
+		}
+
+		result = append(result, entry)
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// channelNoteStat holds aggregated note statistics for a single (platform, channel) pair
+type channelNoteStat struct {
+	ID struct {
+		Author   string `bson:"author"`
+		Platform string `bson:"platform"`
+	} `bson:"_id"`
+	NoteCount  int       `bson:"noteCount"`
+	LastNoteAt time.Time `bson:"lastNoteAt"`
+}
+
+// channelNoteStats aggregates note count and latest note time per (platform, channel), largest first
+func (h *ChannelsHandler) channelNoteStats(ctx context.Context) ([]channelNoteStat, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"metadata.author": bson.M{"$exists": true, "$ne": ""}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{
+				"author": "$metadata.author",
+				"platform": bson.M{"$toLower": bson.M{"$trim": bson.M{
+					"input": bson.M{"$ifNull": bson.A{"$metadata.platform", ""}},
+				}}},
+			},
+			"noteCount":  bson.M{"$sum": 1},
+			"lastNoteAt": bson.M{"$max": "$created"},
+		}}},
+		{{Key: "$sort", Value: bson.M{"noteCount": -1}}},
+	}
+
+	cursor, err := h.notesRepo.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var stats []channelNoteStat
+	if err = cursor.All(ctx, &stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
 }
 
 // GetChannelSettings handles GET /channel-settings/:channel
+// An optional ?platform= selects the channel within a platform namespace
 func (h *ChannelsHandler) GetChannelSettings(c *gin.Context) {
 	channelName := c.Param("channel")
+	platform := config.NormalizePlatform(c.Query("platform"))
 
-	settings, err := h.channelSettingsRepo.FindByName(context.Background(), channelName)
+	settings, err := h.channelSettingsRepo.FindByChannel(context.Background(), platform, channelName)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get settings"})
 		return
@@ -103,6 +245,7 @@ func (h *ChannelsHandler) GetChannelSettings(c *gin.Context) {
 		// Return default settings if not found
 		c.JSON(http.StatusOK, models.ChannelSettings{
 			ChannelName:  channelName,
+			Platform:     platform,
 			ChannelUrl:   "",
 			PromptText:   "",
 			PromptSchema: "",
@@ -118,10 +261,13 @@ func (h *ChannelsHandler) UpdateChannelSettings(c *gin.Context) {
 	channelName := c.Param("channel")
 
 	var req struct {
-		Platform     string `json:"platform"`
-		ChannelUrl   string `json:"channelUrl"`
-		PromptText   string `json:"promptText"`
-		PromptSchema string `json:"promptSchema"`
+		Platform     string                `json:"platform"`
+		ChannelUrl   string                `json:"channelUrl"`
+		PromptText   string                `json:"promptText"`
+		PromptSchema string                `json:"promptSchema"`
+		Aliases      []string              `json:"aliases"`
+		SearchWeight float64               `json:"searchWeight"`
+		Chunking     *models.ChunkingHints `json:"chunking"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -129,6 +275,21 @@ func (h *ChannelsHandler) UpdateChannelSettings(c *gin.Context) {
 		return
 	}
 
+	if req.Platform != "" && !config.IsValidPlatform(req.Platform) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid platform"})
+		return
+	}
+
+	if req.SearchWeight < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "searchWeight must not be negative"})
+		return
+	}
+
+	if err := services.ValidateChunking(req.Chunking); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid chunking: " + err.Error()})
+		return
+	}
+
 	// Validate promptSchema is valid JSON if provided
 	if req.PromptSchema != "" {
 		var js json.RawMessage
@@ -140,10 +301,13 @@ func (h *ChannelsHandler) UpdateChannelSettings(c *gin.Context) {
 
 	settings := models.ChannelSettings{
 		ChannelName:  channelName,
-		Platform:     req.Platform,
+		Platform:     config.NormalizePlatform(req.Platform),
 		ChannelUrl:   req.ChannelUrl,
 		PromptText:   req.PromptText,
 		PromptSchema: req.PromptSchema,
+		Aliases:      req.Aliases,
+		SearchWeight: req.SearchWeight,
+		Chunking:     req.Chunking,
 		UpdatedAt:    time.Now(),
 	}
 
@@ -158,12 +322,14 @@ func (h *ChannelsHandler) UpdateChannelSettings(c *gin.Context) {
 }
 
 // DeleteChannelSettings handles DELETE /channel-settings/:channel
+// An optional ?platform= selects the channel within a platform namespace
 func (h *ChannelsHandler) DeleteChannelSettings(c *gin.Context) {
 	channelName := c.Param("channel")
+	platform := config.NormalizePlatform(c.Query("platform"))
 
-	log.Printf("Deleting channel settings for: %s", channelName)
+	log.Printf("Deleting channel settings for: %s (platform: %q)", channelName, platform)
 
-	deletedCount, err := h.channelSettingsRepo.Delete(context.Background(), channelName)
+	deletedCount, err := h.channelSettingsRepo.Delete(context.Background(), platform, channelName)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete settings"})
 		return
@@ -178,13 +344,19 @@ func (h *ChannelsHandler) DeleteChannelSettings(c *gin.Context) {
 }
 
 // DeleteChannelNotes handles DELETE /channels/:channel/notes
+// An optional ?platform= limits deletion to the channel on that platform
 func (h *ChannelsHandler) DeleteChannelNotes(c *gin.Context) {
 	channelName := c.Param("channel")
+	platform := config.NormalizePlatform(c.Query("platform"))
 
-	log.Printf("Deleting all notes for channel: %s", channelName)
+	log.Printf("Deleting all notes for channel: %s (platform: %q)", channelName, platform)
 
 	// Find all notes for this channel
-	notes, err := h.notesRepo.FindAll(context.Background(), bson.M{"metadata.author": channelName})
+	filter := bson.M{"metadata.author": channelName}
+	if platform != "" {
+		filter["metadata.platform"] = primitive.Regex{Pattern: "^" + regexp.QuoteMeta(platform) + "$", Options: "i"}
+	}
+	notes, err := h.notesRepo.FindAll(context.Background(), filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find notes"})
 		return
@@ -228,6 +400,96 @@ func (h *ChannelsHandler) DeleteChannelNotes(c *gin.Context) {
 	})
 }
 
+// MigrateChannelPlatforms handles POST /migrate/channel-platforms
+// Assigns a platform to settings saved before channels were namespaced, using the platform
+// of the channel's notes. Channels whose notes span several platforms are left for manual review.
+func (h *ChannelsHandler) MigrateChannelPlatforms(c *gin.Context) {
+	settings, err := h.channelSettingsRepo.FindAll(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get channel settings"})
+		return
+	}
+
+	stats, err := h.channelNoteStats(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get channel note stats"})
+		return
+	}
+
+	platformsByAuthor := make(map[string][]string)
+	for _, stat := range stats {
+		if key := statKey(stat); key.Platform != "" {
+			platformsByAuthor[key.Name] = append(platformsByAuthor[key.Name], key.Platform)
+		}
+	}
+
+	existing := make(map[channelKey]bool, len(settings))
+	for _, s := range settings {
+		existing[settingsKey(s)] = true
+	}
+
+	migrated := 0
+	ambiguous := []string{}
+	conflicts := []string{}
+	errors := 0
+
+	for _, s := range settings {
+		platform := config.NormalizePlatform(s.Platform)
+		if platform == "" {
+			platforms := platformsByAuthor[settingsKey(s).Name]
+			if len(platforms) != 1 {
+				if len(platforms) > 1 {
+					ambiguous = append(ambiguous, s.ChannelName)
+				}
+				continue
+			}
+			platform = platforms[0]
+		}
+
+		if platform == s.Platform {
+			continue
+		}
+
+		key := channelKey{Platform: platform, Name: settingsKey(s).Name}
+		if existing[key] {
+			conflicts = append(conflicts, s.ChannelName)
+			continue
+		}
+
+		if err := h.channelSettingsRepo.SetPlatform(context.Background(), s.ID, platform); err != nil {
+			log.Printf("Failed to set platform for channel settings %s: %v", s.ChannelName, err)
+			errors++
+			continue
+		}
+		existing[key] = true
+		migrated++
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Channel platform migration complete",
+		"migrated":  migrated,
+		"ambiguous": ambiguous,
+		"conflicts": conflicts,
+		"errors":    errors,
+		"total":     len(settings),
+	})
+}
+
+// MigrateChannelKeys handles POST /migrate/channel-keys
+// Derives case/diacritic-insensitive lookup keys for settings saved before keys existed
+func (h *ChannelsHandler) MigrateChannelKeys(c *gin.Context) {
+	updated, err := h.channelSettingsRepo.BackfillKeys(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to backfill channel keys"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Channel key migration complete",
+		"updated": updated,
+	})
+}
+
 // RegisterRoutes registers the channel routes on the given router
 func (h *ChannelsHandler) RegisterRoutes(r *gin.Engine) {
 	r.GET("/channels", h.GetChannelsWithNotes)
@@ -236,4 +498,6 @@ func (h *ChannelsHandler) RegisterRoutes(r *gin.Engine) {
 	r.PUT("/channel-settings/:channel", h.UpdateChannelSettings)
 	r.DELETE("/channel-settings/:channel", h.DeleteChannelSettings)
 	r.DELETE("/channels/:channel/notes", h.DeleteChannelNotes)
+	r.POST("/migrate/channel-platforms", h.MigrateChannelPlatforms)
+	r.POST("/migrate/channel-keys", h.MigrateChannelKeys)
 }
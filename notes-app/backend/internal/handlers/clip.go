@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClipHandler handles HTTP requests for the web clipper
+type ClipHandler struct {
+	clipService *services.ClipService
+}
+
+// NewClipHandler creates a new ClipHandler
+func NewClipHandler(clipService *services.ClipService) *ClipHandler {
+	return &ClipHandler{
+		clipService: clipService,
+	}
+}
+
+// Clip handles POST /clip
+// Fetches the page at the URL and saves its article text as a note, like POST /notes
+func (h *ClipHandler) Clip(c *gin.Context) {
+	var req models.ClipRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.clipService.Clip(c.Request.Context(), &req)
+	if err != nil {
+		errMsg := err.Error()
+		if strings.HasPrefix(errMsg, "invalid URL") || strings.HasPrefix(errMsg, "invalid page") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+			return
+		}
+		if strings.HasPrefix(errMsg, "failed to fetch page") {
+			c.JSON(http.StatusBadGateway, gin.H{"error": errMsg})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errMsg})
+		return
+	}
+
+	if result.Duplicate {
+		c.JSON(http.StatusConflict, gin.H{"error": "duplicate", "url": result.URL})
+		return
+	}
+
+	c.JSON(http.StatusCreated, result.Note)
+}
+
+// RegisterRoutes registers the web clipper routes on the given router
+func (h *ClipHandler) RegisterRoutes(r *gin.Engine) {
+	r.POST("/clip", h.Clip)
+}
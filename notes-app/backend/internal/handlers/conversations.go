@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ConversationsHandler handles HTTP requests for multi-turn Q&A conversations
+type ConversationsHandler struct {
+	conversationService *services.ConversationService
+}
+
+// NewConversationsHandler creates a new ConversationsHandler
+func NewConversationsHandler(conversationService *services.ConversationService) *ConversationsHandler {
+	return &ConversationsHandler{
+		conversationService: conversationService,
+	}
+}
+
+// CreateConversation handles POST /conversations
+func (h *ConversationsHandler) CreateConversation(c *gin.Context) {
+	var req models.CreateConversationRequest
+	// The body is optional; an untitled conversation is named after its first question
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	conversation, err := h.conversationService.CreateConversation(c.Request.Context(), req.Title)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create conversation"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, conversation)
+}
+
+// GetConversations handles GET /conversations
+func (h *ConversationsHandler) GetConversations(c *gin.Context) {
+	conversations, err := h.conversationService.GetConversations(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get conversations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, conversations)
+}
+
+// GetConversation handles GET /conversations/:id
+func (h *ConversationsHandler) GetConversation(c *gin.Context) {
+	conversation, err := h.conversationService.GetConversation(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if isConversationNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Conversation not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get conversation"})
+		return
+	}
+
+	c.JSON(http.StatusOK, conversation)
+}
+
+// AskInConversation handles POST /conversations/:id/messages
+// Answers the question using the conversation's prior turns and appends both turns to its history
+func (h *ConversationsHandler) AskInConversation(c *gin.Context) {
+	var req models.QuestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.conversationService.Ask(c.Request.Context(), c.Param("id"), req.Question, req.MinScore)
+	if err != nil {
+		if isConversationNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Conversation not found"})
+			return
+		}
+		if strings.HasPrefix(err.Error(), "invalid minScore") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteConversation handles DELETE /conversations/:id
+func (h *ConversationsHandler) DeleteConversation(c *gin.Context) {
+	err := h.conversationService.DeleteConversation(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if isConversationNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Conversation not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete conversation"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Conversation deleted successfully"})
+}
+
+// isConversationNotFound reports whether an error means the conversation does not exist
+func isConversationNotFound(err error) bool {
+	errMsg := err.Error()
+	return strings.Contains(errMsg, "invalid conversation ID") || errMsg == "conversation not found"
+}
+
+// RegisterRoutes registers the conversation routes on the given router
+func (h *ConversationsHandler) RegisterRoutes(r *gin.Engine) {
+	r.POST("/conversations", h.CreateConversation)
+	r.GET("/conversations", h.GetConversations)
+	r.GET("/conversations/:id", h.GetConversation)
+	r.POST("/conversations/:id/messages", h.AskInConversation)
+	r.DELETE("/conversations/:id", h.DeleteConversation)
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EmbeddingsHandler handles HTTP requests for embedding export and import
+type EmbeddingsHandler struct {
+	embeddingsService *services.EmbeddingsService
+	adminToken        string
+}
+
+// NewEmbeddingsHandler creates a new EmbeddingsHandler guarded by the given admin token
+func NewEmbeddingsHandler(embeddingsService *services.EmbeddingsService, adminToken string) *EmbeddingsHandler {
+	return &EmbeddingsHandler{
+		embeddingsService: embeddingsService,
+		adminToken:        adminToken,
+	}
+}
+
+// ExportEmbeddings handles GET /admin/embeddings/export
+// Streams every vector with its payload as JSONL, one models.EmbeddingRecord per line
+func (h *EmbeddingsHandler) ExportEmbeddings(c *gin.Context) {
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", `attachment; filename="embeddings.jsonl"`)
+	c.Status(http.StatusOK)
+
+	encoder := json.NewEncoder(c.Writer)
+	count, err := h.embeddingsService.Export(c.Request.Context(), func(record *models.EmbeddingRecord) error {
+		return encoder.Encode(record)
+	})
+	if err != nil {
+		// Headers are already sent, so a failure can only cut the stream short
+		log.Printf("Embedding export stopped after %d records: %v", count, err)
+		return
+	}
+	c.Writer.Flush()
+
+	log.Printf("Exported %d embeddings", count)
+}
+
+// ImportEmbeddings handles POST /admin/embeddings/import
+// Accepts a JSONL body in the export format and upserts the vectors
+func (h *EmbeddingsHandler) ImportEmbeddings(c *gin.Context) {
+	result, err := h.embeddingsService.Import(c.Request.Context(), c.Request.Body)
+	if err != nil {
+		errMsg := err.Error()
+		if strings.HasPrefix(errMsg, "invalid import") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import embeddings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// RegisterRoutes registers the embedding routes on the given router
+func (h *EmbeddingsHandler) RegisterRoutes(r *gin.Engine) {
+	admin := r.Group("/admin", RequireAdmin(h.adminToken))
+	admin.GET("/embeddings/export", h.ExportEmbeddings)
+	admin.POST("/embeddings/import", h.ImportEmbeddings)
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"backend/internal/config"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EntitiesHandler handles HTTP requests for the entities notes mention
+type EntitiesHandler struct {
+	entityService *services.EntityService
+}
+
+// NewEntitiesHandler creates a new EntitiesHandler
+func NewEntitiesHandler(entityService *services.EntityService) *EntitiesHandler {
+	return &EntitiesHandler{
+		entityService: entityService,
+	}
+}
+
+// GetEntities handles GET /entities
+// Lists the people, organizations, places and tools notes mention with the number of notes mentioning
+// each, most mentioned first. Optional: ?type= (person, organization, place or tool) and ?limit=
+func (h *EntitiesHandler) GetEntities(c *gin.Context) {
+	limit := config.DEFAULT_ENTITIES_LIMIT
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 || parsed > config.MAX_ENTITIES_LIMIT {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid limit, must be between 1 and %d", config.MAX_ENTITIES_LIMIT)})
+			return
+		}
+		limit = parsed
+	}
+
+	entities, err := h.entityService.GetEntities(c.Request.Context(), c.Query("type"), limit)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get entities"})
+		return
+	}
+
+	c.JSON(http.StatusOK, entities)
+}
+
+// GetEntityNotes handles GET /entities/:name/notes
+// Lists the notes mentioning the entity, newest first; the name matches case-insensitively
+// Optional: ?type= to tell apart entities sharing a name
+func (h *EntitiesHandler) GetEntityNotes(c *gin.Context) {
+	notes, err := h.entityService.GetEntityNotes(c.Request.Context(), c.Param("name"), c.Query("type"))
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get notes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, notes)
+}
+
+// RegisterRoutes registers the entity routes on the given router
+func (h *EntitiesHandler) RegisterRoutes(r *gin.Engine) {
+	r.GET("/entities", h.GetEntities)
+	r.GET("/entities/:name/notes", h.GetEntityNotes)
+}
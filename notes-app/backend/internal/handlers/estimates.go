@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"net/http"
+
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EstimatesHandler handles HTTP requests for estimating bulk AI operations before running them
+type EstimatesHandler struct {
+	migrationService *services.MigrationService
+	adminToken       string
+}
+
+// NewEstimatesHandler creates a new EstimatesHandler guarded by the given admin token
+func NewEstimatesHandler(migrationService *services.MigrationService, adminToken string) *EstimatesHandler {
+	return &EstimatesHandler{
+		migrationService: migrationService,
+		adminToken:       adminToken,
+	}
+}
+
+// GetEstimates handles GET /admin/estimates
+// Reports the notes, tokens, cost and time of the bulk AI operations (classify, titles, summaries,
+// reindex) without running them. Optional query parameters: operation to estimate just one, and
+// perMinute, resume=true and outdated=true as taken by the operations themselves.
+func (h *EstimatesHandler) GetEstimates(c *gin.Context) {
+	opts, ok := resummarizeOptions(c)
+	if !ok {
+		return
+	}
+	estimates, err := h.migrationService.EstimateOperations(c.Request.Context(), c.Query("operation"), opts)
+	if err != nil {
+		respondMigrationError(c, nil, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"estimates": estimates})
+}
+
+// RegisterRoutes registers the estimate routes on the given router
+func (h *EstimatesHandler) RegisterRoutes(r *gin.Engine) {
+	admin := r.Group("/admin", RequireAdmin(h.adminToken))
+	admin.GET("/estimates", h.GetEstimates)
+}
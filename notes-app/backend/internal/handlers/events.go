@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EventsHandler pushes note events to connected clients so they can update without polling
+type EventsHandler struct {
+	eventStream *services.EventStream
+}
+
+// NewEventsHandler creates a new EventsHandler
+func NewEventsHandler(eventStream *services.EventStream) *EventsHandler {
+	return &EventsHandler{
+		eventStream: eventStream,
+	}
+}
+
+// StreamEvents handles GET /events
+// Streams note events as Server-Sent Events named after their type (note.created, note.processed,
+// summary.generated, ...), each carrying the event as JSON. Optional ?types= takes a comma-separated
+// list of event types to receive.
+func (h *EventsHandler) StreamEvents(c *gin.Context) {
+	var types []string
+	if value := c.Query("types"); value != "" {
+		for _, eventType := range strings.Split(value, ",") {
+			if eventType = strings.TrimSpace(eventType); eventType != "" {
+				types = append(types, eventType)
+			}
+		}
+	}
+
+	events, stop, err := h.eventStream.Listen(types)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer stop()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // Disable nginx buffering so events arrive immediately
+
+	keepAlive := time.NewTicker(config.EVENT_STREAM_KEEPALIVE_SECONDS * time.Second)
+	defer keepAlive.Stop()
+
+	ctx := c.Request.Context()
+	c.Writer.WriteHeader(http.StatusOK)
+	c.Writer.Flush()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case event := <-events:
+			c.SSEvent(event.Type, event)
+			return true
+		case <-keepAlive.C:
+			_, err := io.WriteString(w, ": keep-alive\n\n")
+			return err == nil
+		case <-ctx.Done():
+			return false
+		}
+	})
+}
+
+// RegisterRoutes registers the event stream route on the given router
+func (h *EventsHandler) RegisterRoutes(r *gin.Engine) {
+	r.GET("/events", h.StreamEvents)
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportHandler handles HTTP requests for full note exports
+type ExportHandler struct {
+	exportService *services.ExportService
+}
+
+// NewExportHandler creates a new ExportHandler
+func NewExportHandler(exportService *services.ExportService) *ExportHandler {
+	return &ExportHandler{
+		exportService: exportService,
+	}
+}
+
+// ExportMarkdown handles GET /export/markdown
+// Streams a zip with one Markdown file per note
+func (h *ExportHandler) ExportMarkdown(c *gin.Context) {
+	filename := fmt.Sprintf("notes-%s.zip", time.Now().Format("2006-01-02"))
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Status(http.StatusOK)
+
+	count, err := h.exportService.WriteMarkdownZip(c.Request.Context(), c.Writer)
+	if err != nil {
+		// Headers are already sent, so a failure leaves a truncated archive
+		log.Printf("Markdown export stopped after %d notes: %v", count, err)
+		return
+	}
+
+	log.Printf("Exported %d notes as Markdown", count)
+}
+
+// ExportJSON handles GET /export/json
+// Streams a complete backup of notes and channel settings; ?includeChunks=true adds note chunks
+func (h *ExportHandler) ExportJSON(c *gin.Context) {
+	includeChunks := c.Query("includeChunks") == "true"
+
+	filename := fmt.Sprintf("notes-backup-%s.json", time.Now().Format("2006-01-02"))
+	c.Header("Content-Type", "application/json")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Status(http.StatusOK)
+
+	count, err := h.exportService.WriteJSONBackup(c.Request.Context(), c.Writer, includeChunks)
+	if err != nil {
+		// Headers are already sent, so a failure leaves truncated (invalid) JSON
+		log.Printf("JSON backup stopped after %d notes: %v", count, err)
+		return
+	}
+
+	log.Printf("Exported JSON backup of %d notes", count)
+}
+
+// ImportJSON handles POST /import/json
+// Restores a backup produced by GET /export/json and re-queues embedding for restored notes
+// without chunks in the backup
+func (h *ExportHandler) ImportJSON(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, config.MAX_BACKUP_BYTES)
+
+	result, err := h.exportService.RestoreJSONBackup(c.Request.Context(), c.Request.Body)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("backup is larger than %d bytes", tooLarge.Limit)})
+			return
+		}
+		errMsg := err.Error()
+		if strings.HasPrefix(errMsg, "invalid backup") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore backup"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// RegisterRoutes registers the export routes on the given router
+func (h *ExportHandler) RegisterRoutes(r *gin.Engine) {
+	r.GET("/export/markdown", h.ExportMarkdown)
+	r.GET("/export/json", h.ExportJSON)
+	r.POST("/import/json", h.ImportJSON)
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GoalsHandler handles HTTP requests for goal tracking
+type GoalsHandler struct {
+	goalsService *services.GoalsService
+}
+
+// NewGoalsHandler creates a new GoalsHandler
+func NewGoalsHandler(goalsService *services.GoalsService) *GoalsHandler {
+	return &GoalsHandler{
+		goalsService: goalsService,
+	}
+}
+
+// GetGoals handles GET /goals
+// Optional filter: ?status=active|achieved|abandoned
+func (h *GoalsHandler) GetGoals(c *gin.Context) {
+	result, err := h.goalsService.GetGoals(c.Request.Context(), c.Query("status"))
+	if err != nil {
+		respondGoalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetGoal handles GET /goals/:id
+func (h *GoalsHandler) GetGoal(c *gin.Context) {
+	goal, err := h.goalsService.GetGoal(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondGoalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, goal)
+}
+
+// UpdateGoal handles PATCH /goals/:id
+func (h *GoalsHandler) UpdateGoal(c *gin.Context) {
+	var req models.UpdateGoalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	goal, err := h.goalsService.UpdateGoal(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		respondGoalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, goal)
+}
+
+// DeleteGoal handles DELETE /goals/:id
+func (h *GoalsHandler) DeleteGoal(c *gin.Context) {
+	if err := h.goalsService.DeleteGoal(c.Request.Context(), c.Param("id")); err != nil {
+		respondGoalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Goal deleted successfully"})
+}
+
+// ExtractGoals handles POST /goals/extract
+// Runs goal extraction on an existing note, e.g. one saved before goal tracking was enabled
+func (h *GoalsHandler) ExtractGoals(c *gin.Context) {
+	var req models.ExtractGoalsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	goals, err := h.goalsService.ExtractFromNote(c.Request.Context(), req.NoteID)
+	if err != nil {
+		respondGoalError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"goals": goals})
+}
+
+// respondGoalError maps goals service errors to HTTP responses
+func respondGoalError(c *gin.Context, err error) {
+	errMsg := err.Error()
+	switch {
+	case errMsg == "goal not found" || strings.HasPrefix(errMsg, "invalid goal ID"):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Goal not found"})
+	case errMsg == "note not found" || strings.HasPrefix(errMsg, "invalid note ID"):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
+	case strings.HasPrefix(errMsg, "invalid status"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errMsg})
+	}
+}
+
+// RegisterRoutes registers the goals routes on the given router
+func (h *GoalsHandler) RegisterRoutes(r *gin.Engine) {
+	r.GET("/goals", h.GetGoals)
+	r.POST("/goals/extract", h.ExtractGoals)
+	r.GET("/goals/:id", h.GetGoal)
+	r.PATCH("/goals/:id", h.UpdateGoal)
+	r.DELETE("/goals/:id", h.DeleteGoal)
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"backend/internal/config"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GraphHandler handles HTTP requests for the knowledge graph of notes and entities
+type GraphHandler struct {
+	graphService *services.GraphService
+	adminToken   string
+}
+
+// NewGraphHandler creates a new GraphHandler; rebuilding the graph is guarded by the given admin token
+func NewGraphHandler(graphService *services.GraphService, adminToken string) *GraphHandler {
+	return &GraphHandler{
+		graphService: graphService,
+		adminToken:   adminToken,
+	}
+}
+
+// GetGraph handles GET /graph
+// Returns the most recent notes as nodes with the entities they mention, linked by mention and
+// similarity edges, ready for a force-directed layout. Optional: ?category= and ?limit= (notes)
+func (h *GraphHandler) GetGraph(c *gin.Context) {
+	limit := config.DEFAULT_GRAPH_NOTES
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 || parsed > config.MAX_GRAPH_NOTES {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid limit, must be between 1 and %d", config.MAX_GRAPH_NOTES)})
+			return
+		}
+		limit = parsed
+	}
+
+	graph, err := h.graphService.GetGraph(c.Request.Context(), c.Query("category"), limit)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get graph"})
+		return
+	}
+
+	c.JSON(http.StatusOK, graph)
+}
+
+// Rebuild handles POST /admin/graph/rebuild
+// Refreshes the graph edges of every note in the background; responds 202 with the number of notes
+func (h *GraphHandler) Rebuild(c *gin.Context) {
+	count, err := h.graphService.Rebuild(c.Request.Context())
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "graph rebuild already running") {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"notes": count})
+}
+
+// RegisterRoutes registers the graph routes on the given router
+func (h *GraphHandler) RegisterRoutes(r *gin.Engine) {
+	r.GET("/graph", h.GetGraph)
+	admin := r.Group("/admin", RequireAdmin(h.adminToken))
+	admin.POST("/graph/rebuild", h.Rebuild)
+}
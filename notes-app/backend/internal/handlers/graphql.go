@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"backend/internal/graphql"
+	"backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GraphQLHandler serves the GraphQL API alongside the REST routes
+type GraphQLHandler struct {
+	schema *graphql.Schema
+}
+
+// NewGraphQLHandler creates a new GraphQLHandler
+func NewGraphQLHandler(schema *graphql.Schema) *GraphQLHandler {
+	return &GraphQLHandler{
+		schema: schema,
+	}
+}
+
+// Query handles POST /graphql with a JSON body of query, operationName and variables
+func (h *GraphQLHandler) Query(c *gin.Context) {
+	var req models.GraphQLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, graphql.Response{Errors: []graphql.Error{{Message: err.Error()}}})
+		return
+	}
+
+	h.execute(c, &req)
+}
+
+// QueryGet handles GET /graphql?query=&operationName=&variables=
+// variables is a JSON object
+func (h *GraphQLHandler) QueryGet(c *gin.Context) {
+	req := models.GraphQLRequest{
+		Query:         c.Query("query"),
+		OperationName: c.Query("operationName"),
+	}
+	if req.Query == "" {
+		c.JSON(http.StatusBadRequest, graphql.Response{Errors: []graphql.Error{{Message: "Query parameter query is required"}}})
+		return
+	}
+	if variables := c.Query("variables"); variables != "" {
+		if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+			c.JSON(http.StatusBadRequest, graphql.Response{Errors: []graphql.Error{{Message: "Invalid variables, must be a JSON object"}}})
+			return
+		}
+	}
+
+	h.execute(c, &req)
+}
+
+// GetSchema handles GET /graphql/schema
+// Returns the schema in SDL form for client tooling
+func (h *GraphQLHandler) GetSchema(c *gin.Context) {
+	c.String(http.StatusOK, graphql.SchemaSDL)
+}
+
+// execute runs a query; request errors respond 400, while field errors come back with the data
+func (h *GraphQLHandler) execute(c *gin.Context, req *models.GraphQLRequest) {
+	resp, err := h.schema.Execute(c.Request.Context(), req.Query, req.OperationName, req.Variables)
+	if err != nil {
+		var reqErr *graphql.RequestError
+		if errors.As(err, &reqErr) {
+			c.JSON(http.StatusBadRequest, graphql.Response{Errors: []graphql.Error{{Message: reqErr.Message}}})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, graphql.Response{Errors: []graphql.Error{{Message: err.Error()}}})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// RegisterRoutes registers the GraphQL routes on the given router
+func (h *GraphQLHandler) RegisterRoutes(r *gin.Engine) {
+	r.POST("/graphql", h.Query)
+	r.GET("/graphql", h.QueryGet)
+	r.GET("/graphql/schema", h.GetSchema)
+}
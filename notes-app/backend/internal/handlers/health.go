@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HealthHandler handles the liveness and readiness probes
+type HealthHandler struct {
+	readinessService *services.ReadinessService
+}
+
+// NewHealthHandler creates a new HealthHandler
+func NewHealthHandler(readinessService *services.ReadinessService) *HealthHandler {
+	return &HealthHandler{
+		readinessService: readinessService,
+	}
+}
+
+// Health handles GET /health
+// Reports that the server is up, whether or not it can serve searches yet
+func (h *HealthHandler) Health(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Ready handles GET /ready
+// Responds 503 until vector search has warmed up, and while Qdrant's collection is unavailable
+func (h *HealthHandler) Ready(c *gin.Context) {
+	if err := h.readinessService.Check(c.Request.Context()); err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ready"})
+}
+
+// RegisterRoutes registers the health routes on the given router
+func (h *HealthHandler) RegisterRoutes(r *gin.Engine) {
+	r.GET("/health", h.Health)
+	r.GET("/ready", h.Ready)
+}
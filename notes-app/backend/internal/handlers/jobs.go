@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JobsHandler handles the admin HTTP requests for the embedding job queue, its dead-letter queue and
+// the indexing report
+type JobsHandler struct {
+	jobsService *services.JobsService
+	adminToken  string
+}
+
+// NewJobsHandler creates a new JobsHandler guarded by the given admin token
+func NewJobsHandler(jobsService *services.JobsService, adminToken string) *JobsHandler {
+	return &JobsHandler{
+		jobsService: jobsService,
+		adminToken:  adminToken,
+	}
+}
+
+// GetJobs handles GET /jobs
+// Optional ?status=pending|running|failed filter
+func (h *JobsHandler) GetJobs(c *gin.Context) {
+	jobs, err := h.jobsService.GetJobs(c.Request.Context(), c.Query("status"))
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get jobs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"jobs": jobs})
+}
+
+// RetryJob handles POST /jobs/:id/retry
+func (h *JobsHandler) RetryJob(c *gin.Context) {
+	job, err := h.jobsService.RetryJob(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		errMsg := err.Error()
+		switch {
+		case strings.Contains(errMsg, "invalid job ID") || errMsg == "job not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		case errMsg == "job is running":
+			c.JSON(http.StatusConflict, gin.H{"error": errMsg})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retry job"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// GetDeadLetter handles GET /admin/dead-letter
+// Lists the jobs that failed all their attempts with their last error, most recent failure first
+func (h *JobsHandler) GetDeadLetter(c *gin.Context) {
+	jobs, err := h.jobsService.GetDeadLetter(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get dead-letter jobs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"jobs": jobs})
+}
+
+// RequeueDeadLetter handles POST /admin/dead-letter/requeue
+// The body is optional: {"ids": [...]} requeues those jobs, no body requeues every dead-letter job
+func (h *JobsHandler) RequeueDeadLetter(c *gin.Context) {
+	var req models.RequeueDeadLetterRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	jobs, err := h.jobsService.RequeueDeadLetter(c.Request.Context(), &req)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid requeue") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to requeue jobs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"requeued": len(jobs), "jobs": jobs})
+}
+
+// GetIndexingReport handles GET /admin/indexing
+// Counts notes by processing status and lists the pending, processing and failed ones with how many
+// of their chunks are embedded
+func (h *JobsHandler) GetIndexingReport(c *gin.Context) {
+	report, err := h.jobsService.GetIndexingReport(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get indexing report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// RegisterRoutes registers the job routes on the given router
+func (h *JobsHandler) RegisterRoutes(r *gin.Engine) {
+	jobs := r.Group("/jobs", RequireAdmin(h.adminToken))
+	jobs.GET("", h.GetJobs)
+	jobs.POST("/:id/retry", h.RetryJob)
+
+	admin := r.Group("/admin", RequireAdmin(h.adminToken))
+	admin.GET("/dead-letter", h.GetDeadLetter)
+	admin.POST("/dead-letter/requeue", h.RequeueDeadLetter)
+	admin.GET("/indexing", h.GetIndexingReport)
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MigrationsHandler handles HTTP requests for the bulk AI migrations
+type MigrationsHandler struct {
+	migrationService *services.MigrationService
+}
+
+// NewMigrationsHandler creates a new MigrationsHandler
+func NewMigrationsHandler(migrationService *services.MigrationService) *MigrationsHandler {
+	return &MigrationsHandler{
+		migrationService: migrationService,
+	}
+}
+
+// ClassifyExistingNotes handles POST /migrate/classify
+// Optional query parameters: parallelism (notes processed at once) and resume=true to continue
+// the latest interrupted run
+func (h *MigrationsHandler) ClassifyExistingNotes(c *gin.Context) {
+	opts, ok := migrationOptions(c)
+	if !ok {
+		return
+	}
+	run, err := h.migrationService.ClassifyNotes(c.Request.Context(), opts)
+	if err != nil {
+		respondMigrationError(c, run, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Classification complete",
+		"classified":  run.Succeeded,
+		"ruleMatched": run.RuleMatched,
+		"needsReview": run.NeedsReview,
+		"errors":      run.Errors,
+		"total":       run.Total,
+		"run":         run,
+	})
+}
+
+// RegenerateAllTitles handles POST /migrate/titles
+// Takes the same query parameters as POST /migrate/classify, and outdated=true to regenerate only
+// titles an older model or prompt generated
+func (h *MigrationsHandler) RegenerateAllTitles(c *gin.Context) {
+	opts, ok := migrationOptions(c)
+	if !ok {
+		return
+	}
+	run, err := h.migrationService.RegenerateTitles(c.Request.Context(), opts)
+	if err != nil {
+		respondMigrationError(c, run, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Title regeneration complete",
+		"regenerated": run.Succeeded,
+		"errors":      run.Errors,
+		"total":       run.Total,
+		"run":         run,
+	})
+}
+
+// GetRuns handles GET /migrate/runs
+// Lists recent migration runs with their progress, newest first
+func (h *MigrationsHandler) GetRuns(c *gin.Context) {
+	runs, err := h.migrationService.GetRuns(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get migration runs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"runs": runs})
+}
+
+// migrationOptions parses the parallelism, resume and outdated query parameters, responding 400 when invalid
+func migrationOptions(c *gin.Context) (services.MigrationOptions, bool) {
+	var opts services.MigrationOptions
+	if value := c.Query("parallelism"); value != "" {
+		parallelism, err := strconv.Atoi(value)
+		if err != nil || parallelism < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid parallelism: must be a positive integer"})
+			return opts, false
+		}
+		opts.Parallelism = parallelism
+	}
+	opts.Resume = c.Query("resume") == "true"
+	opts.Outdated = c.Query("outdated") == "true"
+	return opts, true
+}
+
+// respondMigrationError maps migration errors to responses; interrupted runs report their progress
+func respondMigrationError(c *gin.Context, run *models.MigrationRun, err error) {
+	errMsg := err.Error()
+	switch {
+	case strings.HasPrefix(errMsg, "migration already running"):
+		c.JSON(http.StatusConflict, gin.H{"error": errMsg})
+	case strings.HasPrefix(errMsg, "migration interrupted"):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": errMsg, "run": run})
+	case strings.HasPrefix(errMsg, "invalid"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errMsg})
+	}
+}
+
+// RegisterRoutes registers the migration routes on the given router
+func (h *MigrationsHandler) RegisterRoutes(r *gin.Engine) {
+	r.POST("/migrate/classify", h.ClassifyExistingNotes)
+	r.POST("/migrate/titles", h.RegenerateAllTitles)
+	r.GET("/migrate/runs", h.GetRuns)
+}
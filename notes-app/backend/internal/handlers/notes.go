@@ -23,10 +23,15 @@ func NewNotesHandler(notesService *services.NotesService) *NotesHandler {
 }
 
 // GetNotes handles GET /notes
+// Optional filters: ?channel=, ?tags=a,b (notes carrying all listed tags), ?includeArchived=true and ?pinned=true
+// Pinned notes are listed first
 func (h *NotesHandler) GetNotes(c *gin.Context) {
 	channel := c.Query("channel")
+	tags := splitTags(c.Query("tags"))
+	includeArchived := c.Query("includeArchived") == "true"
+	pinnedOnly := c.Query("pinned") == "true"
 
-	notes, err := h.notesService.GetNotes(c.Request.Context(), channel)
+	notes, err := h.notesService.GetNotes(c.Request.Context(), channel, tags, c.Query("collection"), includeArchived, pinnedOnly)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -35,6 +40,22 @@ func (h *NotesHandler) GetNotes(c *gin.Context) {
 	c.JSON(http.StatusOK, notes)
 }
 
+// GetNote handles GET /notes/:id
+func (h *NotesHandler) GetNote(c *gin.Context) {
+	note, err := h.notesService.GetNoteByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		errMsg := err.Error()
+		if strings.Contains(errMsg, "invalid note ID") || errMsg == "note not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get note"})
+		return
+	}
+
+	c.JSON(http.StatusOK, note)
+}
+
 // CreateNote handles POST /notes
 func (h *NotesHandler) CreateNote(c *gin.Context) {
 	var req models.CreateNoteRequest
@@ -45,6 +66,10 @@ func (h *NotesHandler) CreateNote(c *gin.Context) {
 
 	result, err := h.notesService.CreateNote(c.Request.Context(), &req)
 	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid platform") || strings.HasPrefix(err.Error(), "invalid category") || strings.HasPrefix(err.Error(), "invalid structuredData") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -99,10 +124,169 @@ func (h *NotesHandler) DeleteNote(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Note deleted successfully"})
 }
 
+// ArchiveNote handles PATCH /notes/:id/archive
+func (h *NotesHandler) ArchiveNote(c *gin.Context) {
+	h.setArchived(c, true)
+}
+
+// UnarchiveNote handles PATCH /notes/:id/unarchive
+func (h *NotesHandler) UnarchiveNote(c *gin.Context) {
+	h.setArchived(c, false)
+}
+
+// PinNote handles POST /notes/:id/pin
+func (h *NotesHandler) PinNote(c *gin.Context) {
+	note, err := h.notesService.SetPinned(c.Request.Context(), c.Param("id"), true)
+	h.respondWithUpdatedNote(c, note, err)
+}
+
+// UnpinNote handles POST /notes/:id/unpin
+func (h *NotesHandler) UnpinNote(c *gin.Context) {
+	note, err := h.notesService.SetPinned(c.Request.Context(), c.Param("id"), false)
+	h.respondWithUpdatedNote(c, note, err)
+}
+
+// PinToContext handles POST /notes/:id/context-pin
+// The note is then passed as context to every /ask answer, within the pinned context budget
+func (h *NotesHandler) PinToContext(c *gin.Context) {
+	note, err := h.notesService.SetAlwaysInContext(c.Request.Context(), c.Param("id"), true)
+	h.respondWithUpdatedNote(c, note, err)
+}
+
+// UnpinFromContext handles POST /notes/:id/context-unpin
+func (h *NotesHandler) UnpinFromContext(c *gin.Context) {
+	note, err := h.notesService.SetAlwaysInContext(c.Request.Context(), c.Param("id"), false)
+	h.respondWithUpdatedNote(c, note, err)
+}
+
+// setArchived updates a note's archived flag and responds with the updated note
+func (h *NotesHandler) setArchived(c *gin.Context, archived bool) {
+	note, err := h.notesService.SetArchived(c.Request.Context(), c.Param("id"), archived)
+	h.respondWithUpdatedNote(c, note, err)
+}
+
+// respondWithUpdatedNote responds with a note after a flag update, mapping lookup errors to 404
+func (h *NotesHandler) respondWithUpdatedNote(c *gin.Context, note *models.Note, err error) {
+	if err != nil {
+		errMsg := err.Error()
+		if strings.Contains(errMsg, "invalid note ID") || errMsg == "note not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update note"})
+		return
+	}
+
+	c.JSON(http.StatusOK, note)
+}
+
+// BulkUpdateNotes handles POST /notes/bulk-update
+func (h *NotesHandler) BulkUpdateNotes(c *gin.Context) {
+	var req models.BulkUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.notesService.BulkUpdate(c.Request.Context(), &req)
+	if err != nil {
+		errMsg := err.Error()
+		if strings.HasPrefix(errMsg, "invalid bulk update") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// BulkDeleteNotes handles POST /notes/bulk-delete
+// Responds with the outcome for each requested ID
+func (h *NotesHandler) BulkDeleteNotes(c *gin.Context) {
+	var req models.BulkDeleteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.notesService.BulkDelete(c.Request.Context(), &req)
+	if err != nil {
+		errMsg := err.Error()
+		if strings.HasPrefix(errMsg, "invalid bulk delete") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete notes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetTags handles GET /tags
+func (h *NotesHandler) GetTags(c *gin.Context) {
+	tags, err := h.notesService.GetTags(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get tags"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+// RecordView handles POST /notes/:id/viewed
+// Clients call it when a note is opened; responds with the note and its updated view count
+func (h *NotesHandler) RecordView(c *gin.Context) {
+	note, err := h.notesService.RecordView(c.Request.Context(), c.Param("id"))
+	h.respondWithUpdatedNote(c, note, err)
+}
+
+// GetOnThisDay handles GET /onthisday
+// Optional: ?date=YYYY-MM-DD (today by default), ?scope=year|month and ?tz= (IANA timezone, UTC by default)
+func (h *NotesHandler) GetOnThisDay(c *gin.Context) {
+	result, err := h.notesService.GetOnThisDay(c.Request.Context(), c.Query("date"), c.Query("scope"), c.Query("tz"))
+	if err != nil {
+		errMsg := err.Error()
+		if strings.HasPrefix(errMsg, "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get notes on this day"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// splitTags parses a comma-separated tag list from a query parameter
+func splitTags(param string) []string {
+	var tags []string
+	for _, tag := range strings.Split(param, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
 // RegisterRoutes registers the note routes on the given router
 func (h *NotesHandler) RegisterRoutes(r *gin.Engine) {
 	r.GET("/notes", h.GetNotes)
+	r.GET("/notes/:id", h.GetNote)
 	r.POST("/notes", h.CreateNote)
+	r.POST("/notes/bulk-update", h.BulkUpdateNotes)
+	r.POST("/notes/bulk-delete", h.BulkDeleteNotes)
 	r.PUT("/notes/:id", h.UpdateNote)
 	r.DELETE("/notes/:id", h.DeleteNote)
+	r.PATCH("/notes/:id/archive", h.ArchiveNote)
+	r.PATCH("/notes/:id/unarchive", h.UnarchiveNote)
+	r.POST("/notes/:id/pin", h.PinNote)
+	r.POST("/notes/:id/unpin", h.UnpinNote)
+	r.POST("/notes/:id/context-pin", h.PinToContext)
+	r.POST("/notes/:id/context-unpin", h.UnpinFromContext)
+	r.POST("/notes/:id/viewed", h.RecordView)
+	r.GET("/tags", h.GetTags)
+	r.GET("/onthisday", h.GetOnThisDay)
 }
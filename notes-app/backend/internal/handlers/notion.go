@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"backend/internal/config"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NotionHandler handles HTTP requests for Notion imports
+type NotionHandler struct {
+	notionService *services.NotionImportService
+}
+
+// NewNotionHandler creates a new NotionHandler
+func NewNotionHandler(notionService *services.NotionImportService) *NotionHandler {
+	return &NotionHandler{
+		notionService: notionService,
+	}
+}
+
+// ImportNotion handles POST /import/notion
+// Takes a Notion "Markdown & CSV" export zip as the multipart "file" field
+func (h *NotionHandler) ImportNotion(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, config.MAX_NOTION_IMPORT_BYTES)
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required: upload the export zip as multipart field \"file\""})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read uploaded file"})
+		return
+	}
+	defer file.Close()
+
+	result, err := h.notionService.Import(c.Request.Context(), file, header.Size)
+	if err != nil {
+		errMsg := err.Error()
+		if strings.HasPrefix(errMsg, "invalid notion export") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import Notion export"})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// RegisterRoutes registers the Notion import routes on the given router
+func (h *NotionHandler) RegisterRoutes(r *gin.Engine) {
+	r.POST("/import/notion", h.ImportNotion)
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PersonaHandler handles HTTP requests for the assistant persona used by /ask and conversations
+type PersonaHandler struct {
+	personaService *services.PersonaService
+}
+
+// NewPersonaHandler creates a new PersonaHandler
+func NewPersonaHandler(personaService *services.PersonaService) *PersonaHandler {
+	return &PersonaHandler{
+		personaService: personaService,
+	}
+}
+
+// GetPersona handles GET /settings/assistant
+// Returns the default persona with custom false when none has been configured
+func (h *PersonaHandler) GetPersona(c *gin.Context) {
+	persona, err := h.personaService.GetPersona(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get assistant persona"})
+		return
+	}
+
+	c.JSON(http.StatusOK, persona)
+}
+
+// UpdatePersona handles PUT /settings/assistant
+// The instructions replace the assistant's default introduction and tone; rules are added to the
+// built-in answering instructions
+func (h *PersonaHandler) UpdatePersona(c *gin.Context) {
+	var req models.UpdatePersonaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	persona, err := h.personaService.UpdatePersona(c.Request.Context(), &req)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid persona") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update assistant persona"})
+		return
+	}
+
+	c.JSON(http.StatusOK, persona)
+}
+
+// ResetPersona handles DELETE /settings/assistant
+// Restores the default persona
+func (h *PersonaHandler) ResetPersona(c *gin.Context) {
+	persona, err := h.personaService.ResetPersona(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset assistant persona"})
+		return
+	}
+
+	c.JSON(http.StatusOK, persona)
+}
+
+// RegisterRoutes registers the assistant persona routes on the given router
+func (h *PersonaHandler) RegisterRoutes(r *gin.Engine) {
+	r.GET("/settings/assistant", h.GetPersona)
+	r.PUT("/settings/assistant", h.UpdatePersona)
+	r.DELETE("/settings/assistant", h.ResetPersona)
+}
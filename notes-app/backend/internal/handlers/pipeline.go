@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PipelineHandler handles HTTP requests for the note processing pipelines
+type PipelineHandler struct {
+	pipelineService *services.PipelineService
+}
+
+// NewPipelineHandler creates a new PipelineHandler
+func NewPipelineHandler(pipelineService *services.PipelineService) *PipelineHandler {
+	return &PipelineHandler{
+		pipelineService: pipelineService,
+	}
+}
+
+// GetPipelines handles GET /settings/pipelines
+// Returns the default pipeline with custom false when none has been configured
+func (h *PipelineHandler) GetPipelines(c *gin.Context) {
+	pipelines, err := h.pipelineService.GetPipelines(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get pipelines"})
+		return
+	}
+
+	c.JSON(http.StatusOK, pipelines)
+}
+
+// UpdatePipelines handles PUT /settings/pipelines
+// Each pipeline lists the steps to run in order: classify, summarize, extract_entities and embed.
+// Channel pipelines beat category pipelines, which beat the default.
+func (h *PipelineHandler) UpdatePipelines(c *gin.Context) {
+	var req models.UpdatePipelinesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	pipelines, err := h.pipelineService.UpdatePipelines(c.Request.Context(), &req)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update pipelines"})
+		return
+	}
+
+	c.JSON(http.StatusOK, pipelines)
+}
+
+// ResetPipelines handles DELETE /settings/pipelines
+// Restores the default pipeline for every note
+func (h *PipelineHandler) ResetPipelines(c *gin.Context) {
+	pipelines, err := h.pipelineService.ResetPipelines(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reset pipelines"})
+		return
+	}
+
+	c.JSON(http.StatusOK, pipelines)
+}
+
+// RegisterRoutes registers the processing pipeline routes on the given router
+func (h *PipelineHandler) RegisterRoutes(r *gin.Engine) {
+	r.GET("/settings/pipelines", h.GetPipelines)
+	r.PUT("/settings/pipelines", h.UpdatePipelines)
+	r.DELETE("/settings/pipelines", h.ResetPipelines)
+}
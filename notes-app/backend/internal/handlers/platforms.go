@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"backend/internal/config"
+	"backend/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// PlatformsHandler handles HTTP requests for the platform registry
+type PlatformsHandler struct {
+	notesRepo           *repository.NotesRepository
+	channelSettingsRepo *repository.ChannelSettingsRepository
+}
+
+// NewPlatformsHandler creates a new PlatformsHandler
+func NewPlatformsHandler(notesRepo *repository.NotesRepository, channelSettingsRepo *repository.ChannelSettingsRepository) *PlatformsHandler {
+	return &PlatformsHandler{
+		notesRepo:           notesRepo,
+		channelSettingsRepo: channelSettingsRepo,
+	}
+}
+
+// GetPlatforms handles GET /platforms
+func (h *PlatformsHandler) GetPlatforms(c *gin.Context) {
+	c.JSON(http.StatusOK, config.PLATFORMS)
+}
+
+// NormalizePlatforms handles POST /migrate/platforms
+// Rewrites platform values on existing notes and channel settings to their canonical names
+func (h *PlatformsHandler) NormalizePlatforms(c *gin.Context) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"metadata.platform": bson.M{"$exists": true, "$ne": ""}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$metadata.platform"}}},
+	}
+
+	cursor, err := h.notesRepo.Aggregate(context.Background(), pipeline)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find platforms"})
+		return
+	}
+	defer cursor.Close(context.Background())
+
+	var values []struct {
+		Platform string `bson:"_id"`
+	}
+	if err = cursor.All(context.Background(), &values); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decode platforms"})
+		return
+	}
+
+	notesUpdated := int64(0)
+	unknown := []string{}
+	errors := 0
+
+	for _, v := range values {
+		platform, ok := config.LookupPlatform(v.Platform)
+		if !ok {
+			unknown = append(unknown, v.Platform)
+			continue
+		}
+		if platform.Name == v.Platform {
+			continue
+		}
+
+		result, err := h.notesRepo.UpdateMany(
+			context.Background(),
+			bson.M{"metadata.platform": v.Platform},
+			bson.M{"$set": bson.M{"metadata.platform": platform.Name}},
+		)
+		if err != nil {
+			log.Printf("Failed to normalize platform %q on notes: %v", v.Platform, err)
+			errors++
+			continue
+		}
+		notesUpdated += result.ModifiedCount
+	}
+
+	settings, err := h.channelSettingsRepo.FindAll(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get channel settings"})
+		return
+	}
+
+	settingsUpdated := 0
+	for _, s := range settings {
+		canonical := config.NormalizePlatform(s.Platform)
+		if canonical == s.Platform {
+			continue
+		}
+		if err := h.channelSettingsRepo.SetPlatform(context.Background(), s.ID, canonical); err != nil {
+			log.Printf("Failed to normalize platform for channel settings %s: %v", s.ChannelName, err)
+			errors++
+			continue
+		}
+		settingsUpdated++
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "Platform normalization complete",
+		"notesUpdated":     notesUpdated,
+		"settingsUpdated":  settingsUpdated,
+		"unknownPlatforms": unknown,
+		"errors":           errors,
+	})
+}
+
+// RegisterRoutes registers the platform routes on the given router
+func (h *PlatformsHandler) RegisterRoutes(r *gin.Engine) {
+	r.GET("/platforms", h.GetPlatforms)
+	r.POST("/migrate/platforms", h.NormalizePlatforms)
+}
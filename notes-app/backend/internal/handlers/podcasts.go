@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PodcastsHandler handles HTTP requests for followed podcast feeds
+type PodcastsHandler struct {
+	podcastService *services.PodcastService
+}
+
+// NewPodcastsHandler creates a new PodcastsHandler
+func NewPodcastsHandler(podcastService *services.PodcastService) *PodcastsHandler {
+	return &PodcastsHandler{
+		podcastService: podcastService,
+	}
+}
+
+// AddFeed handles POST /podcasts
+func (h *PodcastsHandler) AddFeed(c *gin.Context) {
+	var req models.AddPodcastRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	feed, err := h.podcastService.AddFeed(c.Request.Context(), &req)
+	if err != nil {
+		errMsg := err.Error()
+		if strings.HasPrefix(errMsg, "invalid feed") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+			return
+		}
+		if errMsg == "feed already exists" {
+			c.JSON(http.StatusConflict, gin.H{"error": "This feed is already followed"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add feed"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, feed)
+}
+
+// GetFeeds handles GET /podcasts
+func (h *PodcastsHandler) GetFeeds(c *gin.Context) {
+	feeds, err := h.podcastService.GetFeeds(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get feeds"})
+		return
+	}
+
+	c.JSON(http.StatusOK, feeds)
+}
+
+// DeleteFeed handles DELETE /podcasts/:id
+func (h *PodcastsHandler) DeleteFeed(c *gin.Context) {
+	if err := h.podcastService.DeleteFeed(c.Request.Context(), c.Param("id")); err != nil {
+		if isFeedNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Feed not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete feed"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Feed deleted successfully"})
+}
+
+// SyncFeeds handles POST /podcasts/sync
+// Syncs every followed feed now, or only the one given by ?feed=<id>,
+// and responds with what each feed's sync created
+func (h *PodcastsHandler) SyncFeeds(c *gin.Context) {
+	report, err := h.podcastService.Sync(c.Request.Context(), c.Query("feed"))
+	if err != nil {
+		if isFeedNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Feed not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// isFeedNotFound reports whether a service error means the feed ID matches no feed
+func isFeedNotFound(err error) bool {
+	errMsg := err.Error()
+	return strings.Contains(errMsg, "invalid feed ID") || errMsg == "feed not found"
+}
+
+// RegisterRoutes registers the podcast feed routes on the given router
+func (h *PodcastsHandler) RegisterRoutes(r *gin.Engine) {
+	r.GET("/podcasts", h.GetFeeds)
+	r.POST("/podcasts", h.AddFeed)
+	r.DELETE("/podcasts/:id", h.DeleteFeed)
+	r.POST("/podcasts/sync", h.SyncFeeds)
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PresetsHandler handles HTTP requests for channel presets
+type PresetsHandler struct {
+	presetService *services.PresetService
+}
+
+// NewPresetsHandler creates a new PresetsHandler
+func NewPresetsHandler(presetService *services.PresetService) *PresetsHandler {
+	return &PresetsHandler{
+		presetService: presetService,
+	}
+}
+
+// ListPresets handles GET /channel-presets
+// Lists the built-in presets (podcast, tutorial-video, finance-news) and the imported ones
+func (h *PresetsHandler) ListPresets(c *gin.Context) {
+	presets, err := h.presetService.ListPresets(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get presets"})
+		return
+	}
+
+	c.JSON(http.StatusOK, presets)
+}
+
+// ExportPresets handles GET /channel-presets/export
+// Returns a preset library file; ?name=a,b exports only the named presets
+func (h *PresetsHandler) ExportPresets(c *gin.Context) {
+	var names []string
+	for _, name := range strings.Split(c.Query("name"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	library, err := h.presetService.ExportPresets(c.Request.Context(), names)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "preset not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export presets"})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="channel-presets.json"`)
+	c.JSON(http.StatusOK, library)
+}
+
+// ImportPresets handles POST /channel-presets/import
+// The body is a preset library file; presets replace those with the same name
+func (h *PresetsHandler) ImportPresets(c *gin.Context) {
+	var library models.ChannelPresetLibrary
+	if err := c.ShouldBindJSON(&library); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	imported, err := h.presetService.ImportPresets(c.Request.Context(), &library)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import presets"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"imported": imported})
+}
+
+// ApplyPreset handles POST /channel-settings/:channel/preset
+// Configures the channel's prompt, schema and chunking from a preset in one call
+func (h *PresetsHandler) ApplyPreset(c *gin.Context) {
+	var req models.ApplyChannelPresetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	settings, err := h.presetService.ApplyPreset(c.Request.Context(), c.Param("channel"), &req)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "preset not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.HasPrefix(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to apply preset"})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// RegisterRoutes registers the channel preset routes on the given router
+func (h *PresetsHandler) RegisterRoutes(r *gin.Engine) {
+	r.GET("/channel-presets", h.ListPresets)
+	r.GET("/channel-presets/export", h.ExportPresets)
+	r.POST("/channel-presets/import", h.ImportPresets)
+	r.POST("/channel-settings/:channel/preset", h.ApplyPreset)
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/http/pprof"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProfilingHandler serves the Go runtime profiler to admins
+type ProfilingHandler struct {
+	adminToken string
+}
+
+// NewProfilingHandler creates a new ProfilingHandler guarded by the given admin token
+func NewProfilingHandler(adminToken string) *ProfilingHandler {
+	return &ProfilingHandler{
+		adminToken: adminToken,
+	}
+}
+
+// RegisterRoutes registers the profiling routes on the given router
+// GET /debug/pprof lists the profiles; e.g. GET /debug/pprof/profile?seconds=30 captures a CPU profile
+func (h *ProfilingHandler) RegisterRoutes(r *gin.Engine) {
+	debug := r.Group("/debug/pprof", RequireAdmin(h.adminToken))
+	debug.GET("/", gin.WrapF(pprof.Index))
+	debug.GET("/cmdline", gin.WrapF(pprof.Cmdline))
+	debug.GET("/profile", gin.WrapF(pprof.Profile))
+	debug.GET("/symbol", gin.WrapF(pprof.Symbol))
+	debug.POST("/symbol", gin.WrapF(pprof.Symbol))
+	debug.GET("/trace", gin.WrapF(pprof.Trace))
+	for _, profile := range []string{"allocs", "block", "goroutine", "heap", "mutex", "threadcreate"} {
+		debug.GET("/"+profile, gin.WrapH(pprof.Handler(profile)))
+	}
+}
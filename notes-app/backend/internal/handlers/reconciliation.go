@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReconciliationHandler handles HTTP requests for Mongo/Qdrant reconciliation
+type ReconciliationHandler struct {
+	reconciliationService *services.ReconciliationService
+	adminToken            string
+}
+
+// NewReconciliationHandler creates a new ReconciliationHandler guarded by the given admin token
+func NewReconciliationHandler(reconciliationService *services.ReconciliationService, adminToken string) *ReconciliationHandler {
+	return &ReconciliationHandler{
+		reconciliationService: reconciliationService,
+		adminToken:            adminToken,
+	}
+}
+
+// GetReports handles GET /admin/reconciliation?limit=
+func (h *ReconciliationHandler) GetReports(c *gin.Context) {
+	limit := 10
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 || parsed > 100 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit, must be between 1 and 100"})
+			return
+		}
+		limit = parsed
+	}
+
+	reports, err := h.reconciliationService.GetReports(c.Request.Context(), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get reconciliation reports"})
+		return
+	}
+
+	c.JSON(http.StatusOK, reports)
+}
+
+// RunReconciliation handles POST /admin/reconciliation/run
+// Runs a reconciliation immediately and responds with its report
+func (h *ReconciliationHandler) RunReconciliation(c *gin.Context) {
+	report, err := h.reconciliationService.Run(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// RegisterRoutes registers the reconciliation routes on the given router
+func (h *ReconciliationHandler) RegisterRoutes(r *gin.Engine) {
+	admin := r.Group("/admin", RequireAdmin(h.adminToken))
+	admin.GET("/reconciliation", h.GetReports)
+	admin.POST("/reconciliation/run", h.RunReconciliation)
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReindexHandler handles HTTP requests for rebuilding the vector index
+type ReindexHandler struct {
+	reindexService *services.ReindexService
+	adminToken     string
+}
+
+// NewReindexHandler creates a new ReindexHandler guarded by the given admin token
+func NewReindexHandler(reindexService *services.ReindexService, adminToken string) *ReindexHandler {
+	return &ReindexHandler{
+		reindexService: reindexService,
+		adminToken:     adminToken,
+	}
+}
+
+// Reindex handles POST /admin/reindex
+// Wipes the vector index and queues every note to be re-chunked and re-embedded, e.g. after
+// switching EMBEDDING_MODEL. Responds 202 with the run's progress; GET /admin/reindex follows it.
+func (h *ReindexHandler) Reindex(c *gin.Context) {
+	progress, err := h.reindexService.Reindex(c.Request.Context())
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "reindex already running") {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, progress)
+}
+
+// GetProgress handles GET /admin/reindex
+// Reports the latest reindex run with the notes still waiting to be re-embedded
+func (h *ReindexHandler) GetProgress(c *gin.Context) {
+	progress, err := h.reindexService.GetProgress(c.Request.Context())
+	if err != nil {
+		if err.Error() == "reindex not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get reindex progress"})
+		return
+	}
+
+	c.JSON(http.StatusOK, progress)
+}
+
+// RegisterRoutes registers the reindex routes on the given router
+func (h *ReindexHandler) RegisterRoutes(r *gin.Engine) {
+	admin := r.Group("/admin", RequireAdmin(h.adminToken))
+	admin.POST("/reindex", h.Reindex)
+	admin.GET("/reindex", h.GetProgress)
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ResummarizeHandler handles HTTP requests for re-summarizing every note with the current prompts
+type ResummarizeHandler struct {
+	migrationService *services.MigrationService
+	adminToken       string
+}
+
+// NewResummarizeHandler creates a new ResummarizeHandler guarded by the given admin token
+func NewResummarizeHandler(migrationService *services.MigrationService, adminToken string) *ResummarizeHandler {
+	return &ResummarizeHandler{
+		migrationService: migrationService,
+		adminToken:       adminToken,
+	}
+}
+
+// Estimate handles GET /admin/resummarize/estimate
+// Reports the notes, tokens, cost and time a re-summarization would take, without starting one.
+// Takes the same query parameters as POST /admin/resummarize.
+func (h *ResummarizeHandler) Estimate(c *gin.Context) {
+	opts, ok := resummarizeOptions(c)
+	if !ok {
+		return
+	}
+	estimate, err := h.migrationService.EstimateResummarize(c.Request.Context(), opts)
+	if err != nil {
+		respondMigrationError(c, nil, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, estimate)
+}
+
+// Resummarize handles POST /admin/resummarize
+// Starts re-summarizing every note with its channel's current prompt in the background and
+// responds 202 with the estimate. Optional query parameters: perMinute (notes started per
+// minute), parallelism, resume=true to continue the latest interrupted run, and outdated=true to
+// re-summarize only notes whose summary an older model or prompt generated.
+func (h *ResummarizeHandler) Resummarize(c *gin.Context) {
+	opts, ok := resummarizeOptions(c)
+	if !ok {
+		return
+	}
+	estimate, err := h.migrationService.StartResummarize(c.Request.Context(), opts)
+	if err != nil {
+		respondMigrationError(c, nil, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":  "Re-summarization started",
+		"estimate": estimate,
+	})
+}
+
+// GetProgress handles GET /admin/resummarize
+// Reports the latest re-summarization run
+func (h *ResummarizeHandler) GetProgress(c *gin.Context) {
+	run, err := h.migrationService.GetLatestRun(c.Request.Context(), models.MigrationSummaries)
+	if err != nil {
+		if strings.HasSuffix(err.Error(), "run not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get re-summarization progress"})
+		return
+	}
+
+	c.JSON(http.StatusOK, run)
+}
+
+// resummarizeOptions parses the migration query parameters and perMinute, responding 400 when invalid
+func resummarizeOptions(c *gin.Context) (services.MigrationOptions, bool) {
+	opts, ok := migrationOptions(c)
+	if !ok {
+		return opts, false
+	}
+	if value := c.Query("perMinute"); value != "" {
+		perMinute, err := strconv.Atoi(value)
+		if err != nil || perMinute < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid perMinute: must be a positive integer"})
+			return opts, false
+		}
+		opts.PerMinute = perMinute
+	}
+	return opts, true
+}
+
+// RegisterRoutes registers the re-summarization routes on the given router
+func (h *ResummarizeHandler) RegisterRoutes(r *gin.Engine) {
+	admin := r.Group("/admin", RequireAdmin(h.adminToken))
+	admin.GET("/resummarize/estimate", h.Estimate)
+	admin.POST("/resummarize", h.Resummarize)
+	admin.GET("/resummarize", h.GetProgress)
+}
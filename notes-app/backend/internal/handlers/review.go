@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReviewHandler handles HTTP requests for the classification review queue
+type ReviewHandler struct {
+	reviewService *services.ReviewService
+}
+
+// NewReviewHandler creates a new ReviewHandler
+func NewReviewHandler(reviewService *services.ReviewService) *ReviewHandler {
+	return &ReviewHandler{
+		reviewService: reviewService,
+	}
+}
+
+// GetReviewQueue handles GET /review/classification
+func (h *ReviewHandler) GetReviewQueue(c *gin.Context) {
+	limit := 50
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		limit = parsed
+	}
+
+	notes, err := h.reviewService.GetReviewQueue(c.Request.Context(), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get review queue"})
+		return
+	}
+
+	c.JSON(http.StatusOK, notes)
+}
+
+// SubmitReview handles POST /review/classification
+// Confirms or corrects categories in bulk; reviewed notes leave the queue
+func (h *ReviewHandler) SubmitReview(c *gin.Context) {
+	var req models.ReviewClassificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if len(req.Decisions) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "At least one decision is required"})
+		return
+	}
+
+	result, err := h.reviewService.ApplyDecisions(c.Request.Context(), req.Decisions)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "errors": result.Errors})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// RegisterRoutes registers the review routes on the given router
+func (h *ReviewHandler) RegisterRoutes(r *gin.Engine) {
+	r.GET("/review/classification", h.GetReviewQueue)
+	r.POST("/review/classification", h.SubmitReview)
+}
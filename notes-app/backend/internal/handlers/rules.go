@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RulesHandler handles HTTP requests for heuristic category rules
+type RulesHandler struct {
+	rulesService *services.RulesService
+}
+
+// NewRulesHandler creates a new RulesHandler
+func NewRulesHandler(rulesService *services.RulesService) *RulesHandler {
+	return &RulesHandler{
+		rulesService: rulesService,
+	}
+}
+
+// GetRules handles GET /rules
+func (h *RulesHandler) GetRules(c *gin.Context) {
+	rules, err := h.rulesService.GetRules(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get rules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// GetRule handles GET /rules/:id
+func (h *RulesHandler) GetRule(c *gin.Context) {
+	rule, err := h.rulesService.GetRuleByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondRuleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// CreateRule handles POST /rules
+func (h *RulesHandler) CreateRule(c *gin.Context) {
+	var rule models.CategoryRule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	created, err := h.rulesService.CreateRule(c.Request.Context(), &rule)
+	if err != nil {
+		respondRuleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// UpdateRule handles PUT /rules/:id
+func (h *RulesHandler) UpdateRule(c *gin.Context) {
+	var rule models.CategoryRule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updated, err := h.rulesService.UpdateRule(c.Request.Context(), c.Param("id"), &rule)
+	if err != nil {
+		respondRuleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// DeleteRule handles DELETE /rules/:id
+func (h *RulesHandler) DeleteRule(c *gin.Context) {
+	if err := h.rulesService.DeleteRule(c.Request.Context(), c.Param("id")); err != nil {
+		respondRuleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Rule deleted"})
+}
+
+// TestRules handles POST /rules/test
+// Evaluates the enabled rules against sample input without creating a note
+func (h *RulesHandler) TestRules(c *gin.Context) {
+	var req models.TestRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	match, err := h.rulesService.Evaluate(c.Request.Context(), req.Title, req.Content, req.Metadata)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to evaluate rules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"matched": match != nil,
+		"match":   match,
+	})
+}
+
+// respondRuleError maps rules service errors to HTTP responses
+func respondRuleError(c *gin.Context, err error) {
+	errMsg := err.Error()
+	switch {
+	case errMsg == "rule not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": "Rule not found"})
+	case strings.HasPrefix(errMsg, "invalid rule"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errMsg})
+	}
+}
+
+// RegisterRoutes registers the rules routes on the given router
+func (h *RulesHandler) RegisterRoutes(r *gin.Engine) {
+	r.GET("/rules", h.GetRules)
+	r.POST("/rules", h.CreateRule)
+	r.POST("/rules/test", h.TestRules)
+	r.GET("/rules/:id", h.GetRule)
+	r.PUT("/rules/:id", h.UpdateRule)
+	r.DELETE("/rules/:id", h.DeleteRule)
+}
@@ -1,30 +1,54 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"backend/internal/ai"
+	"backend/internal/config"
 	"backend/internal/models"
 	"backend/internal/services"
+	"backend/internal/vectordb"
 
 	"github.com/gin-gonic/gin"
 )
 
 // SearchHandler handles HTTP requests for search and Q&A operations
 type SearchHandler struct {
-	searchService *services.SearchService
-	aiClient      ai.Client
+	searchService  *services.SearchService
+	suggestService *services.SuggestService
+	spellService   *services.SpellService
+	answerNotes    *services.AnswerNotesService
+	aiClient       ai.Client
 }
 
 // NewSearchHandler creates a new SearchHandler
-func NewSearchHandler(searchService *services.SearchService, aiClient ai.Client) *SearchHandler {
+func NewSearchHandler(
+	searchService *services.SearchService,
+	suggestService *services.SuggestService,
+	spellService *services.SpellService,
+	answerNotes *services.AnswerNotesService,
+	aiClient ai.Client,
+) *SearchHandler {
 	return &SearchHandler{
-		searchService: searchService,
-		aiClient:      aiClient,
+		searchService:  searchService,
+		suggestService: suggestService,
+		spellService:   spellService,
+		answerNotes:    answerNotes,
+		aiClient:       aiClient,
 	}
 }
 
 // SearchNotes handles POST /search
+// With autoCorrect set, misspelled words are corrected before searching and the
+// query actually used is returned in the X-Corrected-Query header.
+// With debug set, a SearchExplanation with the ranking breakdown is returned instead of the results array
+// Semantic and hybrid searches report the minimum similarity they applied in the X-Min-Score header.
+// While Qdrant is unavailable they fall back to keyword search and set X-Search-Degraded: true.
 func (h *SearchHandler) SearchNotes(c *gin.Context) {
 	var req models.SearchRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -32,16 +56,184 @@ func (h *SearchHandler) SearchNotes(c *gin.Context) {
 		return
 	}
 
-	results, err := h.searchService.SemanticSearch(c.Request.Context(), req.Query, req.Limit)
+	minScore, err := h.searchService.SearchThreshold(req.MinScore)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := services.ValidateChunkTypes(req.ChunkTypes); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Mode != models.SearchModeKeyword {
+		setMinScoreHeader(c, minScore)
+	}
+
+	if req.AutoCorrect {
+		suggestion, err := h.spellService.DidYouMean(c.Request.Context(), req.Query)
+		if err != nil {
+			log.Printf("Spelling correction failed, searching with original query: %v", err)
+		} else if suggestion.CorrectedQuery != "" {
+			req.Query = suggestion.CorrectedQuery
+			c.Header("X-Corrected-Query", suggestion.CorrectedQuery)
+		}
+	}
+
+	if req.Debug {
+		if req.Mode != "" && req.Mode != models.SearchModeSemantic && req.Mode != models.SearchModeHybrid {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid search mode, must be semantic or hybrid"})
+			return
+		}
+		explanation, err := h.searchService.ExplainSearch(c.Request.Context(), &req)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, explanation)
+		return
+	}
+
+	var results []models.SearchResult
+	switch req.Mode {
+	case "", models.SearchModeSemantic:
+		results, err = h.searchService.SemanticSearch(c.Request.Context(), req.Query, req.Limit, req.SearchFilters, req.Boosts)
+	case models.SearchModeHybrid:
+		results, err = h.searchService.HybridSearch(c.Request.Context(), req.Query, req.Limit, req.SearchFilters, req.Boosts)
+	case models.SearchModeKeyword:
+		results, err = h.searchService.KeywordSearch(c.Request.Context(), req.Query, req.Limit, req.SearchFilters, req.Boosts)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid search mode, must be semantic, hybrid or keyword"})
+		return
+	}
+	if errors.Is(err, vectordb.ErrUnavailable) {
+		log.Printf("Vector search unavailable, falling back to keyword search: %v", err)
+		results, err = h.searchService.KeywordSearch(c.Request.Context(), req.Query, req.Limit, req.SearchFilters, req.Boosts)
+		c.Header("X-Search-Degraded", "true")
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	if err := h.suggestService.RecordQuery(c.Request.Context(), req.Query); err != nil {
+		log.Printf("Failed to record search query: %v", err)
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+// DidYouMean handles GET /search/did-you-mean?q=
+func (h *SearchHandler) DidYouMean(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Query parameter q is required"})
+		return
+	}
+
+	suggestion, err := h.spellService.DidYouMean(c.Request.Context(), query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check spelling"})
+		return
+	}
+
+	c.JSON(http.StatusOK, suggestion)
+}
+
+// SuggestSearches handles GET /search/suggest?q=
+// Returns typeahead completions from recent queries, note titles, tags and categories
+func (h *SearchHandler) SuggestSearches(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Query parameter q is required"})
+		return
+	}
+
+	limit := 10
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 || parsed > 50 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit, must be between 1 and 50"})
+			return
+		}
+		limit = parsed
+	}
+
+	suggestions, err := h.suggestService.Suggest(c.Request.Context(), query, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get suggestions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, suggestions)
+}
+
+// SimilarToText handles POST /search/similar-to-text
+// The minimum similarity applied is reported in the X-Min-Score header
+func (h *SearchHandler) SimilarToText(c *gin.Context) {
+	var req models.SimilarTextRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	minScore, err := h.searchService.SearchThreshold(req.MinScore)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := services.ValidateChunkTypes(req.ChunkTypes); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	results, err := h.searchService.SimilarToText(c.Request.Context(), req.Text, req.Limit, req.SearchFilters)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	setMinScoreHeader(c, minScore)
+
+	c.JSON(http.StatusOK, results)
+}
+
+// RelatedNotes handles GET /notes/:id/related
+// Returns the notes most similar to the note, best first, for "see also" links
+func (h *SearchHandler) RelatedNotes(c *gin.Context) {
+	limit := config.DEFAULT_RELATED_NOTES_LIMIT
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 || parsed > config.MAX_RELATED_NOTES_LIMIT {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid limit, must be between 1 and %d", config.MAX_RELATED_NOTES_LIMIT)})
+			return
+		}
+		limit = parsed
+	}
+
+	results, err := h.searchService.RelatedNotes(c.Request.Context(), c.Param("id"), limit)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err.Error() == "note not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
 	c.JSON(http.StatusOK, results)
 }
 
 // AnswerQuestion handles POST /ask
+// With debug set, sources include their retrieval scores and unused candidates are listed under filtered
+// minScore overrides the configured minimum similarity of context notes; the response reports the one used
+// responseFormat lays the answer out as text (default), bullets or a table, or as json following schema,
+// returned under data for programmatic consumers. With saveAsNote set, an answer drawn from notes is
+// saved as a note too and its ID returned as noteId. With multiHop set, the model proposes follow-up
+// searches for facts the first notes point to (e.g. what the author of one note wrote elsewhere); the
+// searches made are listed under hops
 func (h *SearchHandler) AnswerQuestion(c *gin.Context) {
 	var req models.QuestionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -49,15 +241,82 @@ func (h *SearchHandler) AnswerQuestion(c *gin.Context) {
 		return
 	}
 
-	response, err := h.searchService.AnswerQuestion(c.Request.Context(), req.Question)
+	response, err := h.searchService.AnswerQuestion(c.Request.Context(), req.Question, req.MinScore, req.Debug, req.ResponseFormat, req.Schema, req.MultiHop)
 	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+	h.saveAnswer(c, &req, response)
 
 	c.JSON(http.StatusOK, response)
 }
 
+// saveAnswer saves a Q&A exchange as a note when the request asks for it
+// A failure is logged rather than losing the answer; the response then carries no noteId.
+func (h *SearchHandler) saveAnswer(c *gin.Context, req *models.QuestionRequest, response *models.QuestionResponse) {
+	if !req.SaveAsNote {
+		return
+	}
+	if _, err := h.answerNotes.SaveAnswer(c.Request.Context(), response); err != nil {
+		log.Printf("Failed to save answer to %q as a note: %v", req.Question, err)
+	}
+}
+
+// AnswerQuestionStream handles POST /ask/stream
+// Streams the answer as Server-Sent Events: "token" events carry answer text as it is generated,
+// a final "sources" event carries the full response, and "error" reports a failure mid-stream
+// responseFormat (except json), saveAsNote and multiHop work as for /ask
+func (h *SearchHandler) AnswerQuestionStream(c *gin.Context) {
+	var req models.QuestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, err := h.searchService.AnswerThreshold(req.MinScore); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := services.ValidateStreamFormat(req.ResponseFormat); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // Disable nginx buffering so tokens arrive immediately
+
+	ctx := c.Request.Context()
+	response, err := h.searchService.AnswerQuestionStream(ctx, req.Question, req.MinScore, req.ResponseFormat, req.MultiHop, func(chunk string) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		c.SSEvent("token", gin.H{"text": chunk})
+		c.Writer.Flush()
+		return nil
+	})
+	if err != nil {
+		if ctx.Err() == nil {
+			c.SSEvent("error", gin.H{"error": err.Error()})
+			c.Writer.Flush()
+		}
+		return
+	}
+	h.saveAnswer(c, &req, response)
+
+	c.SSEvent("sources", response)
+	c.Writer.Flush()
+}
+
+// setMinScoreHeader reports the minimum similarity a search applied, for responses that are a bare results array
+func setMinScoreHeader(c *gin.Context, minScore float32) {
+	c.Header("X-Min-Score", strconv.FormatFloat(float64(minScore), 'f', -1, 32))
+}
+
 // AskAIAboutNote handles POST /ai-question
 func (h *SearchHandler) AskAIAboutNote(c *gin.Context) {
 	var req models.AIQuestionRequest
@@ -80,6 +339,11 @@ func (h *SearchHandler) AskAIAboutNote(c *gin.Context) {
 // RegisterRoutes registers the search routes on the given router
 func (h *SearchHandler) RegisterRoutes(r *gin.Engine) {
 	r.POST("/search", h.SearchNotes)
+	r.GET("/search/suggest", h.SuggestSearches)
+	r.GET("/search/did-you-mean", h.DidYouMean)
+	r.POST("/search/similar-to-text", h.SimilarToText)
+	r.GET("/notes/:id/related", h.RelatedNotes)
 	r.POST("/ask", h.AnswerQuestion)
+	r.POST("/ask/stream", h.AnswerQuestionStream)
 	r.POST("/ai-question", h.AskAIAboutNote)
 }
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"net/http"
+
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SearchIndexHandler handles HTTP requests for the external search index mirror
+type SearchIndexHandler struct {
+	searchIndexService *services.SearchIndexService
+	adminToken         string
+}
+
+// NewSearchIndexHandler creates a new SearchIndexHandler guarded by the given admin token
+func NewSearchIndexHandler(searchIndexService *services.SearchIndexService, adminToken string) *SearchIndexHandler {
+	return &SearchIndexHandler{
+		searchIndexService: searchIndexService,
+		adminToken:         adminToken,
+	}
+}
+
+// Reindex handles POST /admin/search-index/reindex
+// Pushes every note into the search index, creating it if needed
+func (h *SearchIndexHandler) Reindex(c *gin.Context) {
+	count, err := h.searchIndexService.Reindex(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "indexed": count})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"indexed": count})
+}
+
+// RegisterRoutes registers the search index routes on the given router
+func (h *SearchIndexHandler) RegisterRoutes(r *gin.Engine) {
+	admin := r.Group("/admin", RequireAdmin(h.adminToken))
+	admin.POST("/search-index/reindex", h.Reindex)
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StalenessHandler handles HTTP requests for the outdated notes report
+type StalenessHandler struct {
+	stalenessService *services.StalenessService
+}
+
+// NewStalenessHandler creates a new StalenessHandler
+func NewStalenessHandler(stalenessService *services.StalenessService) *StalenessHandler {
+	return &StalenessHandler{
+		stalenessService: stalenessService,
+	}
+}
+
+// GetOutdated handles GET /analysis/outdated
+// Optional: ?category= to check one category instead of the fast-moving ones,
+// ?days= for the age after which notes are checked
+func (h *StalenessHandler) GetOutdated(c *gin.Context) {
+	days := 0
+	if daysStr := c.Query("days"); daysStr != "" {
+		parsed, err := strconv.Atoi(daysStr)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid days, must be a positive number"})
+			return
+		}
+		days = parsed
+	}
+
+	report, err := h.stalenessService.FindOutdated(c.Request.Context(), c.Query("category"), days)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid category") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build outdated notes report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// RegisterRoutes registers the staleness routes on the given router
+func (h *StalenessHandler) RegisterRoutes(r *gin.Engine) {
+	r.GET("/analysis/outdated", h.GetOutdated)
+}
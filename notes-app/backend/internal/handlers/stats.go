@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StatsHandler handles HTTP requests for note statistics
+type StatsHandler struct {
+	statsService *services.StatsService
+}
+
+// NewStatsHandler creates a new StatsHandler
+func NewStatsHandler(statsService *services.StatsService) *StatsHandler {
+	return &StatsHandler{
+		statsService: statsService,
+	}
+}
+
+// GetHeatmap handles GET /stats/heatmap
+// Optional filters: ?category=, ?channel= and ?tz= (IANA timezone, UTC by default)
+func (h *StatsHandler) GetHeatmap(c *gin.Context) {
+	heatmap, err := h.statsService.GetHeatmap(c.Request.Context(), c.Query("category"), c.Query("channel"), c.Query("tz"))
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid timezone") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build heatmap"})
+		return
+	}
+
+	c.JSON(http.StatusOK, heatmap)
+}
+
+// GetAccessReport handles GET /stats/access
+// Optional: ?order=most|least (most by default) and ?limit=
+func (h *StatsHandler) GetAccessReport(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	report, err := h.statsService.GetAccessReport(c.Request.Context(), c.Query("order"), limit)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid order") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build access report"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// RegisterRoutes registers the stats routes on the given router
+func (h *StatsHandler) RegisterRoutes(r *gin.Engine) {
+	r.GET("/stats/heatmap", h.GetHeatmap)
+	r.GET("/stats/access", h.GetAccessReport)
+}
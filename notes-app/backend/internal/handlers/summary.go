@@ -3,7 +3,9 @@ package handlers
 import (
 	"log"
 	"net/http"
+	"strings"
 
+	"backend/internal/ai"
 	"backend/internal/models"
 	"backend/internal/services"
 
@@ -36,6 +38,10 @@ func (h *SummaryHandler) SummarizeNote(c *gin.Context) {
 	})
 	if err != nil {
 		log.Printf("Error generating summary: %v", err)
+		if ai.IsContentBlocked(err) && result != nil {
+			respondContentBlocked(c, result)
+			return
+		}
 		if err.Error() == "invalid note ID: encoding/hex: invalid byte: U+0069 'i'" {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid note ID"})
 			return
@@ -69,6 +75,10 @@ func (h *SummaryHandler) SummarizeNoteById(c *gin.Context) {
 		req.PromptSchema,
 	)
 	if err != nil {
+		if ai.IsContentBlocked(err) && result != nil {
+			respondContentBlocked(c, result)
+			return
+		}
 		if err.Error() == "invalid note ID: encoding/hex: invalid byte: U+0069 'i'" {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid note ID"})
 			return
@@ -84,19 +94,69 @@ func (h *SummaryHandler) SummarizeNoteById(c *gin.Context) {
 	c.JSON(http.StatusOK, result)
 }
 
-// RegenerateAllTitles handles POST /migrate/titles
-func (h *SummaryHandler) RegenerateAllTitles(c *gin.Context) {
-	result, err := h.summaryService.RegenerateAllTitles(c.Request.Context())
+// PreviewChannelPrompt handles POST /channel-settings/:channel/preview
+// Runs the prompt in the body (or the saved one) on the channel's recent notes without saving anything
+func (h *SummaryHandler) PreviewChannelPrompt(c *gin.Context) {
+	var req models.ChannelPromptPreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	preview, err := h.summaryService.PreviewChannelPrompt(c.Request.Context(), c.Param("channel"), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		if strings.HasPrefix(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("Error previewing channel prompt: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to preview channel prompt"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message":     "Title regeneration complete",
-		"regenerated": result.Regenerated,
-		"errors":      result.Errors,
-		"total":       result.Total,
+	c.JSON(http.StatusOK, preview)
+}
+
+// InferChannelPrompt handles POST /channel-settings/:channel/infer-schema
+// Proposes a prompt and schema from the channel's recent notes, for review before saving
+func (h *SummaryHandler) InferChannelPrompt(c *gin.Context) {
+	var req models.InferChannelPromptRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	suggestion, err := h.summaryService.InferChannelPrompt(c.Request.Context(), c.Param("channel"), &req)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if strings.HasPrefix(err.Error(), "no notes found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		log.Printf("Error inferring channel prompt: %v", err)
+		if ai.IsContentBlocked(err) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "The channel's notes were blocked by content safety filters", "code": "content_blocked"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to infer channel prompt"})
+		return
+	}
+
+	c.JSON(http.StatusOK, suggestion)
+}
+
+// respondContentBlocked reports a safety-blocked summary with the extractive fallback that was saved instead
+func respondContentBlocked(c *gin.Context, result *models.SummarizeResponse) {
+	c.JSON(http.StatusUnprocessableEntity, gin.H{
+		"error":    "Summary was blocked by content safety filters",
+		"code":     "content_blocked",
+		"summary":  result.Summary,
+		"fallback": result.Fallback,
 	})
 }
 
@@ -104,5 +164,6 @@ func (h *SummaryHandler) RegenerateAllTitles(c *gin.Context) {
 func (h *SummaryHandler) RegisterRoutes(r *gin.Engine) {
 	r.POST("/summarize", h.SummarizeNote)
 	r.POST("/summarize/:id", h.SummarizeNoteById)
-	r.POST("/migrate/titles", h.RegenerateAllTitles)
+	r.POST("/channel-settings/:channel/preview", h.PreviewChannelPrompt)
+	r.POST("/channel-settings/:channel/infer-schema", h.InferChannelPrompt)
 }
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"backend/internal/config"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TopicsHandler handles HTTP requests for the topics notes cluster into
+type TopicsHandler struct {
+	topicService *services.TopicService
+}
+
+// NewTopicsHandler creates a new TopicsHandler
+func NewTopicsHandler(topicService *services.TopicService) *TopicsHandler {
+	return &TopicsHandler{
+		topicService: topicService,
+	}
+}
+
+// GetTopics handles GET /topics
+// Clusters the notes by their embeddings into k topics (chosen from the number of notes when not
+// given), largest first, each with an AI-generated label, its most common tags and its notes, most
+// typical first
+func (h *TopicsHandler) GetTopics(c *gin.Context) {
+	k := 0
+	if kStr := c.Query("k"); kStr != "" {
+		parsed, err := strconv.Atoi(kStr)
+		if err != nil || parsed <= 0 || parsed > config.MAX_TOPIC_CLUSTERS {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid k, must be between 1 and %d", config.MAX_TOPIC_CLUSTERS)})
+			return
+		}
+		k = parsed
+	}
+
+	topics, err := h.topicService.ClusterNotes(c.Request.Context(), k)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, topics)
+}
+
+// RegisterRoutes registers the topic routes on the given router
+func (h *TopicsHandler) RegisterRoutes(r *gin.Engine) {
+	r.GET("/topics", h.GetTopics)
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"backend/internal/ai"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TranslationHandler handles HTTP requests for note translations
+type TranslationHandler struct {
+	translationService *services.TranslationService
+}
+
+// NewTranslationHandler creates a new TranslationHandler
+func NewTranslationHandler(translationService *services.TranslationService) *TranslationHandler {
+	return &TranslationHandler{
+		translationService: translationService,
+	}
+}
+
+// TranslateNote handles POST /notes/:id/translate?lang=xx&save=true
+// Returns the note's content and summary translated into the language; save also keeps the
+// translation in the note's structuredData.translations
+func (h *TranslationHandler) TranslateNote(c *gin.Context) {
+	save := c.Query("save") == "true"
+	translation, err := h.translationService.TranslateNote(c.Request.Context(), c.Param("id"), c.Query("lang"), save)
+	if err != nil {
+		errMsg := err.Error()
+		switch {
+		case strings.HasPrefix(errMsg, "invalid"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+		case errMsg == "note not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
+		case strings.HasPrefix(errMsg, "translation too large"):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": errMsg})
+		case ai.IsContentBlocked(err):
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "The note was blocked by content safety filters", "code": "content_blocked"})
+		default:
+			log.Printf("Error translating note: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to translate note"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, translation)
+}
+
+// RegisterRoutes registers the translation routes on the given router
+func (h *TranslationHandler) RegisterRoutes(r *gin.Engine) {
+	r.POST("/notes/:id/translate", h.TranslateNote)
+}
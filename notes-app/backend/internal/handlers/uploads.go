@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"backend/internal/config"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UploadsHandler handles HTTP requests for document uploads
+type UploadsHandler struct {
+	uploadService *services.UploadService
+}
+
+// NewUploadsHandler creates a new UploadsHandler
+func NewUploadsHandler(uploadService *services.UploadService) *UploadsHandler {
+	return &UploadsHandler{
+		uploadService: uploadService,
+	}
+}
+
+// UploadNote handles POST /notes/upload
+// Takes a PDF or an image (PNG, JPEG, WebP or HEIC) as the multipart "file" field, with optional
+// repeated "tags" fields. Images become a note of the text and description the AI extracts from them.
+func (h *UploadsHandler) UploadNote(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, config.MAX_PDF_UPLOAD_BYTES)
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required: upload the PDF or image as multipart field \"file\""})
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read uploaded file"})
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read uploaded file"})
+		return
+	}
+
+	result, err := h.uploadService.Upload(c.Request.Context(), header.Filename, data, c.PostFormArray("tags"))
+	if err != nil {
+		errMsg := err.Error()
+		if strings.HasPrefix(errMsg, "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errMsg})
+		return
+	}
+
+	if result.Duplicate {
+		c.JSON(http.StatusConflict, gin.H{"error": "duplicate", "file_hash": result.FileHash})
+		return
+	}
+
+	c.JSON(http.StatusCreated, result.Upload)
+}
+
+// GetNoteFile handles GET /notes/:id/file
+// Downloads the original file a note was created from
+func (h *UploadsHandler) GetNoteFile(c *gin.Context) {
+	stream, contentType, err := h.uploadService.OpenNoteFile(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		errMsg := err.Error()
+		if strings.Contains(errMsg, "invalid note ID") || errMsg == "note not found" || strings.HasPrefix(errMsg, "file not found") {
+			c.JSON(http.StatusNotFound, gin.H{"error": errMsg})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": errMsg})
+		return
+	}
+	defer stream.Close()
+
+	file := stream.GetFile()
+	c.DataFromReader(http.StatusOK, file.Length, contentType, stream, map[string]string{
+		"Content-Disposition": fmt.Sprintf("attachment; filename=%q", file.Name),
+	})
+}
+
+// RegisterRoutes registers the upload routes on the given router
+func (h *UploadsHandler) RegisterRoutes(r *gin.Engine) {
+	r.POST("/notes/upload", h.UploadNote)
+	r.GET("/notes/:id/file", h.GetNoteFile)
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VaultSyncHandler handles HTTP requests for Obsidian vault sync
+type VaultSyncHandler struct {
+	vaultSyncService *services.VaultSyncService
+}
+
+// NewVaultSyncHandler creates a new VaultSyncHandler
+func NewVaultSyncHandler(vaultSyncService *services.VaultSyncService) *VaultSyncHandler {
+	return &VaultSyncHandler{
+		vaultSyncService: vaultSyncService,
+	}
+}
+
+// GetManifest handles GET /sync/obsidian/manifest
+// Lists the path and content hash of every synced note's vault file
+func (h *VaultSyncHandler) GetManifest(c *gin.Context) {
+	manifest, err := h.vaultSyncService.Manifest(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build vault manifest"})
+		return
+	}
+
+	c.JSON(http.StatusOK, manifest)
+}
+
+// Pull handles POST /sync/obsidian/pull
+func (h *VaultSyncHandler) Pull(c *gin.Context) {
+	var req models.VaultPullRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.vaultSyncService.Pull(c.Request.Context(), &req)
+	if err != nil {
+		respondVaultSyncError(c, err, "Failed to pull vault files")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Push handles POST /sync/obsidian/push
+// Responds with the outcome for each pushed file; conflicts are reported, not applied
+func (h *VaultSyncHandler) Push(c *gin.Context) {
+	var req models.VaultPushRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.vaultSyncService.Push(c.Request.Context(), &req)
+	if err != nil {
+		respondVaultSyncError(c, err, "Failed to push vault files")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// MigrateVaultPaths handles POST /migrate/vault-paths
+// Gives notes written in the app a vault path, so the manifest lists them before the next push
+func (h *VaultSyncHandler) MigrateVaultPaths(c *gin.Context) {
+	assigned, err := h.vaultSyncService.AssignPaths(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to assign vault paths"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Vault path migration complete",
+		"updated": assigned,
+	})
+}
+
+// respondVaultSyncError maps vault sync errors to HTTP responses
+func respondVaultSyncError(c *gin.Context, err error, fallback string) {
+	errMsg := err.Error()
+	if strings.HasPrefix(errMsg, "invalid sync request") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+}
+
+// RegisterRoutes registers the vault sync routes on the given router
+func (h *VaultSyncHandler) RegisterRoutes(r *gin.Engine) {
+	r.GET("/sync/obsidian/manifest", h.GetManifest)
+	r.POST("/sync/obsidian/pull", h.Pull)
+	r.POST("/sync/obsidian/push", h.Push)
+	r.POST("/migrate/vault-paths", h.MigrateVaultPaths)
+}
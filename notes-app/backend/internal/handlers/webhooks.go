@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"backend/internal/models"
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebhooksHandler handles HTTP requests for webhook subscriptions and the note event log
+type WebhooksHandler struct {
+	webhookService *services.WebhookService
+}
+
+// NewWebhooksHandler creates a new WebhooksHandler
+func NewWebhooksHandler(webhookService *services.WebhookService) *WebhooksHandler {
+	return &WebhooksHandler{
+		webhookService: webhookService,
+	}
+}
+
+// CreateWebhook handles POST /webhooks
+// The response is the only time the signing secret is returned
+func (h *WebhooksHandler) CreateWebhook(c *gin.Context) {
+	var req models.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	webhook, err := h.webhookService.CreateWebhook(c.Request.Context(), &req)
+	if err != nil {
+		errMsg := err.Error()
+		if strings.HasPrefix(errMsg, "invalid webhook") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, webhook)
+}
+
+// GetWebhooks handles GET /webhooks
+func (h *WebhooksHandler) GetWebhooks(c *gin.Context) {
+	webhooks, err := h.webhookService.GetWebhooks(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get webhooks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, webhooks)
+}
+
+// DeleteWebhook handles DELETE /webhooks/:id
+func (h *WebhooksHandler) DeleteWebhook(c *gin.Context) {
+	if err := h.webhookService.DeleteWebhook(c.Request.Context(), c.Param("id")); err != nil {
+		errMsg := err.Error()
+		if strings.Contains(errMsg, "invalid webhook ID") || errMsg == "webhook not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted successfully"})
+}
+
+// UpdateWebhookAuth handles PUT /webhooks/:id/auth
+// Replaces the webhook's outbound auth; credentials are stored but never returned
+func (h *WebhooksHandler) UpdateWebhookAuth(c *gin.Context) {
+	var auth models.WebhookAuth
+	if err := c.ShouldBindJSON(&auth); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	webhook, err := h.webhookService.UpdateAuth(c.Request.Context(), c.Param("id"), &auth)
+	if err != nil {
+		errMsg := err.Error()
+		if strings.Contains(errMsg, "invalid webhook ID") || errMsg == "webhook not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+			return
+		}
+		if strings.HasPrefix(errMsg, "invalid webhook") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update webhook auth"})
+		return
+	}
+
+	c.JSON(http.StatusOK, webhook)
+}
+
+// ReplayWebhook handles POST /webhooks/:id/replay
+// Redelivers every event after the given cursor, in order
+func (h *WebhooksHandler) ReplayWebhook(c *gin.Context) {
+	var req models.ReplayWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.webhookService.Replay(c.Request.Context(), c.Param("id"), req.Cursor); err != nil {
+		errMsg := err.Error()
+		if strings.HasPrefix(errMsg, "invalid cursor") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+			return
+		}
+		if strings.Contains(errMsg, "invalid webhook ID") || errMsg == "webhook not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to replay webhook"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Replay scheduled"})
+}
+
+// GetEvents handles GET /webhooks/events?after=&limit=
+// Returns logged note events after the cursor, oldest first; pass the last ID back as after to page
+func (h *WebhooksHandler) GetEvents(c *gin.Context) {
+	limit := 100
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 || parsed > 1000 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit, must be between 1 and 1000"})
+			return
+		}
+		limit = parsed
+	}
+
+	events, err := h.webhookService.GetEvents(c.Request.Context(), c.Query("after"), limit)
+	if err != nil {
+		errMsg := err.Error()
+		if strings.HasPrefix(errMsg, "invalid cursor") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get events"})
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
+// RegisterRoutes registers the webhook routes on the given router
+func (h *WebhooksHandler) RegisterRoutes(r *gin.Engine) {
+	r.POST("/webhooks", h.CreateWebhook)
+	r.GET("/webhooks", h.GetWebhooks)
+	r.GET("/webhooks/events", h.GetEvents)
+	r.DELETE("/webhooks/:id", h.DeleteWebhook)
+	r.PUT("/webhooks/:id/auth", h.UpdateWebhookAuth)
+	r.POST("/webhooks/:id/replay", h.ReplayWebhook)
+}
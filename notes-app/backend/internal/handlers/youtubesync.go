@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+
+	"backend/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// YouTubeSyncHandler handles HTTP requests for YouTube channel sync
+type YouTubeSyncHandler struct {
+	youtubeSyncService *services.YouTubeSyncService
+}
+
+// NewYouTubeSyncHandler creates a new YouTubeSyncHandler
+func NewYouTubeSyncHandler(youtubeSyncService *services.YouTubeSyncService) *YouTubeSyncHandler {
+	return &YouTubeSyncHandler{
+		youtubeSyncService: youtubeSyncService,
+	}
+}
+
+// SyncChannels handles POST /sync/youtube
+// Syncs every channel with a channel URL now, or only the one named by ?channel=,
+// and responds with what each channel's sync created
+func (h *YouTubeSyncHandler) SyncChannels(c *gin.Context) {
+	report, err := h.youtubeSyncService.Sync(c.Request.Context(), c.Query("channel"))
+	if err != nil {
+		if err.Error() == "channel not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No channel settings with a channel URL for this channel"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// RegisterRoutes registers the YouTube sync routes on the given router
+func (h *YouTubeSyncHandler) RegisterRoutes(r *gin.Engine) {
+	r.POST("/sync/youtube", h.SyncChannels)
+}
@@ -7,43 +7,335 @@ import (
 )
 
 type Note struct {
-	ID                primitive.ObjectID     `json:"id" bson:"_id,omitempty"`
-	Title             string                 `json:"title" bson:"title"`
-	Content           string                 `json:"content" bson:"content"`
-	Summary           string                 `json:"summary" bson:"summary"`
-	StructuredData    map[string]interface{} `json:"structuredData" bson:"structured_data"`
-	Category          string                 `json:"category" bson:"category"`
-	Created           time.Time              `json:"created" bson:"created"`
-	SourcePublishedAt *time.Time             `json:"sourcePublishedAt,omitempty" bson:"source_published_at,omitempty"`
-	LastSummarizedAt  *time.Time             `json:"lastSummarizedAt,omitempty" bson:"last_summarized_at,omitempty"`
-	Metadata          map[string]interface{} `json:"metadata" bson:"metadata"`
+	ID                 primitive.ObjectID     `json:"id" bson:"_id,omitempty"`
+	Title              string                 `json:"title" bson:"title"`
+	TitleWords         []string               `json:"-" bson:"title_words,omitempty"` // Lowercased title words backing title prefix suggestions
+	Content            string                 `json:"content" bson:"content"`
+	Summary            string                 `json:"summary" bson:"summary"`
+	StructuredData     map[string]interface{} `json:"structuredData" bson:"structured_data"`
+	Category           string                 `json:"category" bson:"category"`
+	CategoryConfidence float64                `json:"categoryConfidence,omitempty" bson:"category_confidence,omitempty"`
+	NeedsReview        bool                   `json:"needsReview,omitempty" bson:"needs_review,omitempty"` // Low-confidence classification awaiting confirmation
+	Tags               []string               `json:"tags,omitempty" bson:"tags,omitempty"`
+	Pinned             bool                   `json:"pinned,omitempty" bson:"pinned,omitempty"`
+	AlwaysInContext    bool                   `json:"alwaysInContext,omitempty" bson:"always_in_context,omitempty"`
+	Archived           bool                   `json:"archived,omitempty" bson:"archived,omitempty"` // Hidden from default lists and search
+	Created            time.Time              `json:"created" bson:"created"`
+	SourcePublishedAt  *time.Time             `json:"sourcePublishedAt,omitempty" bson:"source_published_at,omitempty"`
+	LastSummarizedAt   *time.Time             `json:"lastSummarizedAt,omitempty" bson:"last_summarized_at,omitempty"`
+	ViewCount          int                    `json:"viewCount,omitempty" bson:"view_count,omitempty"` // Times the note was opened
+	LastViewedAt       *time.Time             `json:"lastViewedAt,omitempty" bson:"last_viewed_at,omitempty"`
+	Metadata           map[string]interface{} `json:"metadata" bson:"metadata"`
+	Entities           []NoteEntity           `json:"entities,omitempty" bson:"entities,omitempty"`                  // Set by the extract_entities pipeline step
+	ProcessingStatus   string                 `json:"processingStatus,omitempty" bson:"processing_status,omitempty"` // Processing*; empty for notes embedded before statuses were tracked
+	ProcessingGen      int64                  `json:"-" bson:"processing_generation,omitempty"`                      // Bumped by each job re-embedding the whole note; older jobs are dropped
+	ChunkCount         int                    `json:"chunkCount" bson:"chunk_count"`                                 // Chunks the note is split into for search
+	EmbeddedChunkCount int                    `json:"embeddedChunkCount" bson:"embedded_chunk_count"`                // Chunks with a stored embedding
+
+	// Provenance of the generated fields; nil when a field was given, set by hand or by a rule,
+	// extractive, or saved before provenance was kept
+	TitleGeneration    *GenerationInfo `json:"titleGeneration,omitempty" bson:"title_generation,omitempty"`
+	CategoryGeneration *GenerationInfo `json:"categoryGeneration,omitempty" bson:"category_generation,omitempty"`
+	SummaryGeneration  *GenerationInfo `json:"summaryGeneration,omitempty" bson:"summary_generation,omitempty"`
+
+	// Set when generated structured data went over MAX_STRUCTURED_DATA_BYTES or MAX_STRUCTURED_DATA_DEPTH
+	// and was cut down to fit
+	StructuredDataTruncated bool `json:"structuredDataTruncated,omitempty" bson:"structured_data_truncated,omitempty"`
+
+	// Named collections the note was added to by automation rules
+	Collections []string `json:"collections,omitempty" bson:"collections,omitempty"`
+}
+
+// GenerationInfo is the provenance of a generated title, category or summary, so what an older
+// model or prompt produced can be found and regenerated after an upgrade
+type GenerationInfo struct {
+	Model         string    `json:"model" bson:"model"`
+	Operation     string    `json:"operation" bson:"operation"`          // AIOperation* of the call, e.g. analysis for all three at creation
+	PromptVersion int       `json:"promptVersion" bson:"prompt_version"` // The operation's version in ai.PromptVersions
+	GeneratedAt   time.Time `json:"generatedAt" bson:"generated_at"`
 }
 
+// Note processing statuses: how far a note's chunks are embedded for search
+const (
+	ProcessingPending = "pending"    // Waiting for an embedding job, possibly to retry a failed attempt
+	ProcessingRunning = "processing" // An embedding job is running
+	ProcessingIndexed = "indexed"    // Every chunk is embedded
+	ProcessingSkipped = "skipped"    // Not embedded because it looks like it holds secrets or its pipeline has no embed step
+	ProcessingFailed  = "failed"     // The embedding job failed all its attempts; see the dead-letter queue
+)
+
 type NoteChunk struct {
 	ID       primitive.ObjectID `json:"id" bson:"_id,omitempty"`
 	NoteID   primitive.ObjectID `json:"note_id" bson:"note_id"`
 	Content  string             `json:"content" bson:"content"`
-	ChunkIdx int                `json:"chunk_idx" bson:"chunk_idx"`
+	ChunkIdx int                `json:"chunk_idx" bson:"chunk_idx"`           // Position among the note's chunks of the same type
+	Page     int                `json:"page,omitempty" bson:"page,omitempty"` // Source page, for notes from paged documents
+	Type     string             `json:"type,omitempty" bson:"type,omitempty"` // ChunkType*; empty for content chunks stored before types existed
+	Pending  bool               `json:"pending,omitempty" bson:"pending,omitempty"`
+}
+
+// NoteEntity is a person, organization, place or tool a note mentions
+type NoteEntity struct {
+	Name string `json:"name" bson:"name"`
+	Type string `json:"type" bson:"type"` // EntityType*
+}
+
+// Entity types
+const (
+	EntityTypePerson       = "person"
+	EntityTypeOrganization = "organization"
+	EntityTypePlace        = "place"
+	EntityTypeTool         = "tool" // Software, frameworks, products and instruments
+)
+
+// EntityTypes lists every entity type
+var EntityTypes = []string{EntityTypePerson, EntityTypeOrganization, EntityTypePlace, EntityTypeTool}
+
+// Note processing pipeline steps
+// Classify and summarize run when the note is created; the others run in the worker, in the
+// order the pipeline lists them.
+const (
+	PipelineClassify        = "classify"         // Generate the title, category and tags
+	PipelineSummarize       = "summarize"        // Summarize transcripts and notes from channels with a custom prompt
+	PipelineExtractEntities = "extract_entities" // Find the people, organizations, places and tools mentioned
+	PipelineEmbed           = "embed"            // Chunk and embed the note for semantic search
+)
+
+// PipelineSteps lists every pipeline step
+var PipelineSteps = []string{PipelineClassify, PipelineSummarize, PipelineExtractEntities, PipelineEmbed}
+
+// DefaultPipeline is run for notes without a configured pipeline
+var DefaultPipeline = []string{PipelineClassify, PipelineSummarize, PipelineExtractEntities, PipelineEmbed}
+
+// PipelineSettings chooses which processing steps run for new notes
+// A channel's pipeline beats its category's, which beats the default.
+type PipelineSettings struct {
+	Default    []string            `json:"default" bson:"default"`                           // Steps for notes no other pipeline matches
+	Categories map[string][]string `json:"categories,omitempty" bson:"categories,omitempty"` // Steps by category
+	Channels   map[string][]string `json:"channels,omitempty" bson:"channels,omitempty"`     // Steps by channel key
+	Custom     bool                `json:"custom" bson:"-"`                                  // False when the default pipeline is in use
+	UpdatedAt  time.Time           `json:"updatedAt,omitempty" bson:"updated_at"`
+}
+
+// UpdatePipelinesRequest replaces the pipeline settings
+// Channels may be given by name; they are stored by channel key.
+type UpdatePipelinesRequest struct {
+	Default    []string            `json:"default"`
+	Categories map[string][]string `json:"categories,omitempty"`
+	Channels   map[string][]string `json:"channels,omitempty"`
 }
 
+// Chunk types: what part of a note an embedded chunk was taken from
+const (
+	ChunkTypeContent    = "content"
+	ChunkTypeTitle      = "title"
+	ChunkTypeSummary    = "summary"
+	ChunkTypeStructured = "structured" // Key fields of the note's structured data
+)
+
+// ChunkTypes lists every chunk type
+var ChunkTypes = []string{ChunkTypeContent, ChunkTypeTitle, ChunkTypeSummary, ChunkTypeStructured}
+
+// Search modes accepted by SearchRequest.Mode
+const (
+	SearchModeSemantic = "semantic"
+	SearchModeHybrid   = "hybrid"
+	SearchModeKeyword  = "keyword"
+)
+
 type SearchRequest struct {
-	Query string `json:"query" binding:"required"`
+	Query       string `json:"query" binding:"required"`
+	Limit       int    `json:"limit,omitempty"`
+	Mode        string `json:"mode,omitempty"`        // "semantic" (default), "hybrid" (keyword + vector) or "keyword"
+	AutoCorrect bool   `json:"autoCorrect,omitempty"` // Search with the spelling-corrected query when one is found
+	Debug       bool   `json:"debug,omitempty"`       // Return a SearchExplanation with the ranking breakdown
+	SearchFilters
+	Boosts *RankingBoosts `json:"boosts,omitempty"` // Overrides the configured ranking boosts for this request
+}
+
+// RankingBoosts adjusts search scores after vector scoring: the score is multiplied by
+// (1 + pinned + summary + recency + popularity) and then by the channel weight. Nil fields keep the configured value.
+type RankingBoosts struct {
+	Pinned              *float64           `json:"pinned,omitempty"`
+	Summary             *float64           `json:"summary,omitempty"`
+	Recency             *float64           `json:"recency,omitempty"`
+	RecencyHalfLifeDays *float64           `json:"recencyHalfLifeDays,omitempty"`
+	Popularity          *float64           `json:"popularity,omitempty"`     // Full boost approached as view counts grow
+	ChannelWeights      map[string]float64 `json:"channelWeights,omitempty"` // Channel name -> weight, merged over channel settings
+}
+
+// SearchFilters narrows search results to notes with the given tags and drops excluded notes
+type SearchFilters struct {
+	Tags              []string `json:"tags,omitempty"` // Only return notes carrying all of these tags
+	ExcludeCategories []string `json:"excludeCategories,omitempty"`
+	ExcludeChannels   []string `json:"excludeChannels,omitempty"` // Matched ignoring case, spacing and punctuation
+	ExcludeTerms      []string `json:"excludeTerms,omitempty"`    // Notes mentioning any of these words or phrases are dropped
+	IncludeArchived   bool     `json:"includeArchived,omitempty"` // Archived notes are left out unless set
+	MinScore          *float32 `json:"minScore,omitempty"`        // Overrides the configured minimum vector similarity, 0-1
+	ChunkTypes        []string `json:"chunkTypes,omitempty"`      // Only match passages of these ChunkTypes; all by default
+	ExcludeNoteIDs    []string `json:"-"`                         // Hex IDs of notes left out of vector search, such as the note related notes are found for
+
+	// Scopes, applied by Qdrant during vector search rather than to the results
+	Categories    []string   `json:"categories,omitempty"`    // Only notes in one of these categories
+	Authors       []string   `json:"authors,omitempty"`       // Only notes whose metadata author is one of these
+	Platforms     []string   `json:"platforms,omitempty"`     // Only notes from one of these platforms
+	CreatedAfter  *time.Time `json:"createdAfter,omitempty"`  // Only notes created at or after this time
+	CreatedBefore *time.Time `json:"createdBefore,omitempty"` // Only notes created before this time
+}
+
+// TopicClusters groups notes by the topics that emerge from clustering their embeddings
+type TopicClusters struct {
+	Clusters  []TopicCluster `json:"clusters"`            // Largest first
+	Notes     int            `json:"notes"`               // Notes clustered: the embedded, unarchived ones
+	Truncated bool           `json:"truncated,omitempty"` // More notes were embedded than TOPIC_MAX_NOTES; the rest were left out
+}
+
+// TopicCluster is a group of similar notes with an AI-generated topic name
+type TopicCluster struct {
+	Label string      `json:"label"`
+	Size  int         `json:"size"`
+	Tags  []string    `json:"tags,omitempty"` // Most common tags among its notes
+	Notes []TopicNote `json:"notes"`          // Most typical first
+}
+
+// TopicNote is a note's membership of a topic cluster
+type TopicNote struct {
+	ID         primitive.ObjectID `json:"id"`
+	Title      string             `json:"title"`
+	Category   string             `json:"category"`
+	Similarity float32            `json:"similarity"` // Cosine similarity to the cluster's center
+}
+
+// SimilarTextRequest finds notes related to an arbitrary piece of text
+type SimilarTextRequest struct {
+	Text  string `json:"text" binding:"required"`
 	Limit int    `json:"limit,omitempty"`
+	SearchFilters
+}
+
+// SpellCorrection replaces a query word missing from the corpus with a close, frequent one
+type SpellCorrection struct {
+	Original  string `json:"original"`
+	Corrected string `json:"corrected"`
+	Distance  int    `json:"distance"`
+}
+
+// DidYouMeanResponse is the spelling suggestion for a search query
+type DidYouMeanResponse struct {
+	Query          string            `json:"query"`
+	CorrectedQuery string            `json:"correctedQuery,omitempty"` // Empty when every word is known
+	Corrections    []SpellCorrection `json:"corrections"`
 }
 
 type SearchResult struct {
-	Note  Note    `json:"note"`
-	Score float32 `json:"score"`
+	Note       Note                `json:"note"`
+	Score      float32             `json:"score"`
+	Matches    []ChunkMatch        `json:"matches,omitempty"`    // Best matching passages within the note, highest score first
+	TitleMatch bool                `json:"titleMatch,omitempty"` // Title nearly equals the query; such results rank first
+	Explain    *RankingExplanation `json:"explain,omitempty"`    // Only set in debug mode
+}
+
+// RankingExplanation breaks down how a search result was scored, or why it was dropped
+type RankingExplanation struct {
+	VectorScore     *float32           `json:"vectorScore,omitempty"` // Best chunk similarity
+	TitleScore      *float32           `json:"titleScore,omitempty"`  // 1 for a word-for-word title match, else the title similarity
+	VectorRank      *int               `json:"vectorRank,omitempty"`  // 1-based position in the semantic list (hybrid)
+	KeywordRank     *int               `json:"keywordRank,omitempty"` // 1-based position in the text-search list (hybrid)
+	FusedScore      *float64           `json:"fusedScore,omitempty"`  // Normalized reciprocal rank fusion score (hybrid)
+	Boosts          map[string]float64 `json:"boosts,omitempty"`      // Contribution of each boost to the multiplier
+	BoostMultiplier float64            `json:"boostMultiplier"`       // 1 when no boosts applied
+	FinalScore      float32            `json:"finalScore"`
+	Threshold       float32            `json:"threshold"`                // Minimum vector similarity to be kept
+	FilteredReason  string             `json:"filteredReason,omitempty"` // Why the candidate was dropped
 }
 
+// SearchExplanation is the debug view of a search: ranked results and the candidates that were dropped
+type SearchExplanation struct {
+	Query    string         `json:"query"`
+	Mode     string         `json:"mode"`
+	MinScore float32        `json:"minScore"` // Effective minimum vector similarity
+	Results  []SearchResult `json:"results"`
+	Filtered []SearchResult `json:"filtered"`
+}
+
+// ChunkMatch is a passage of a note that matched a semantic search
+type ChunkMatch struct {
+	ChunkID  primitive.ObjectID `json:"chunkId"`
+	ChunkIdx int                `json:"chunkIdx"`
+	Page     int                `json:"page,omitempty"` // Source page to cite, for notes from paged documents
+	Type     string             `json:"type"`           // ChunkType of the passage, e.g. a match on the summary
+	Snippet  string             `json:"snippet"`
+	Score    float32            `json:"score"`
+}
+
+// Q&A answer formats
+const (
+	AnswerFormatText    = "text"
+	AnswerFormatBullets = "bullets"
+	AnswerFormatTable   = "table"
+	AnswerFormatJSON    = "json"
+)
+
 type QuestionRequest struct {
-	Question string `json:"question" binding:"required"`
+	Question       string                 `json:"question" binding:"required"`
+	Debug          bool                   `json:"debug,omitempty"`          // Include retrieval scoring for sources and dropped candidates
+	MinScore       *float32               `json:"minScore,omitempty"`       // Overrides the configured minimum similarity of context notes, 0-1
+	ResponseFormat string                 `json:"responseFormat,omitempty"` // text (default), bullets, table or json
+	Schema         map[string]interface{} `json:"schema,omitempty"`         // json format: structure of the answer, e.g. {"answer": "string", "steps": ["string"]}
+	SaveAsNote     bool                   `json:"saveAsNote,omitempty"`     // Also save the question, answer and sources as a note
+	MultiHop       bool                   `json:"multiHop,omitempty"`       // Run model-proposed follow-up searches for questions that chain facts across notes
 }
 
 type QuestionResponse struct {
-	Answer   string         `json:"answer"`
-	Sources  []SearchResult `json:"sources"`
-	Question string         `json:"question"`
+	Answer   string                 `json:"answer"`
+	Sources  []SearchResult         `json:"sources"`
+	Question string                 `json:"question"`
+	MinScore float32                `json:"minScore"`           // Effective minimum similarity of context notes
+	Filtered []SearchResult         `json:"filtered,omitempty"` // Debug mode: candidates dropped during retrieval
+	Data     map[string]interface{} `json:"data,omitempty"`     // json format: the answer following the requested schema
+	NoteID   string                 `json:"noteId,omitempty"`   // saveAsNote: the note the exchange was saved as
+	Hops     []RetrievalHop         `json:"hops,omitempty"`     // multiHop: the searches run and the notes each one added
+	Degraded bool                   `json:"degraded,omitempty"` // Qdrant was unavailable, so sources came from keyword search
+}
+
+// RetrievalHop is one search made while gathering context for a multi-hop question
+type RetrievalHop struct {
+	Hop     int      `json:"hop"` // 1 for the question itself, then each round of follow-up searches
+	Query   string   `json:"query"`
+	NoteIDs []string `json:"noteIds"` // Notes this search added to the context
+}
+
+// Conversation message roles
+const (
+	ConversationRoleUser      = "user"
+	ConversationRoleAssistant = "assistant"
+)
+
+// ConversationMessage is a single turn in a Q&A conversation
+type ConversationMessage struct {
+	Role      string    `json:"role" bson:"role"`
+	Content   string    `json:"content" bson:"content"`
+	Sources   []string  `json:"sources,omitempty" bson:"sources,omitempty"` // IDs of notes used to answer (assistant turns only)
+	CreatedAt time.Time `json:"createdAt" bson:"created_at"`
+}
+
+// Conversation stores the message history of a multi-turn Q&A session
+type Conversation struct {
+	ID        primitive.ObjectID    `json:"id" bson:"_id,omitempty"`
+	Title     string                `json:"title" bson:"title"`
+	Messages  []ConversationMessage `json:"messages" bson:"messages"`
+	CreatedAt time.Time             `json:"createdAt" bson:"created_at"`
+	UpdatedAt time.Time             `json:"updatedAt" bson:"updated_at"`
+}
+
+type CreateConversationRequest struct {
+	Title string `json:"title"`
+}
+
+// ConversationAnswerResponse is the answer to a question asked within a conversation
+type ConversationAnswerResponse struct {
+	ConversationID primitive.ObjectID `json:"conversationId"`
+	QuestionResponse
 }
 
 type AIQuestionRequest struct {
@@ -64,44 +356,1060 @@ type SummarizeRequest struct {
 type SummarizeResponse struct {
 	Summary        string                 `json:"summary"`
 	StructuredData map[string]interface{} `json:"structuredData,omitempty"`
+	Fallback       bool                   `json:"fallback,omitempty"` // True when the extractive summarizer was used instead of the LLM
+}
+
+// ChannelPromptPreviewRequest is a channel prompt to try on the channel's notes before saving it
+// An empty prompt and schema preview the channel's saved settings.
+type ChannelPromptPreviewRequest struct {
+	Platform     string `json:"platform"`
+	PromptText   string `json:"promptText"`
+	PromptSchema string `json:"promptSchema"`
+	Limit        int    `json:"limit"` // Recent notes to run the prompt on; 0 uses the default
+}
+
+// ChannelPromptPreview holds what a channel prompt generated for a sample of the channel's notes
+type ChannelPromptPreview struct {
+	Channel      string                `json:"channel"`
+	Platform     string                `json:"platform"`
+	PromptText   string                `json:"promptText"`
+	PromptSchema string                `json:"promptSchema"`
+	Samples      []ChannelPromptSample `json:"samples"` // Newest note first
 }
 
+// ChannelPromptSample is the output of a channel prompt for one note; nothing is saved
+type ChannelPromptSample struct {
+	NoteID         primitive.ObjectID     `json:"noteId"`
+	Title          string                 `json:"title"`
+	Summary        string                 `json:"summary,omitempty"`
+	StructuredData map[string]interface{} `json:"structuredData,omitempty"`
+	MissingFields  []string               `json:"missingFields,omitempty"` // Top-level schema fields the output left out
+	Error          string                 `json:"error,omitempty"`
+
+	StructuredDataTruncated bool `json:"structuredDataTruncated,omitempty"` // The output went over the size limits and would be cut down when saved
+}
+
+// InferChannelPromptRequest asks for a prompt and schema proposed from a channel's recent notes
+type InferChannelPromptRequest struct {
+	Platform string `json:"platform"`
+	Limit    int    `json:"limit"` // Recent notes to learn from; 0 uses the default
+}
+
+// ChannelPromptSuggestion is a prompt and schema the AI proposed for a channel, for review before saving
+type ChannelPromptSuggestion struct {
+	Channel       string               `json:"channel"`
+	Platform      string               `json:"platform"`
+	PromptText    string               `json:"promptText"`
+	PromptSchema  string               `json:"promptSchema"`  // JSON object, as saved in the channel settings
+	SampleNoteIDs []primitive.ObjectID `json:"sampleNoteIds"` // The notes the suggestion was made from
+}
+
+// ProcessingJob is a queued embedding job, stored in MongoDB so pending work survives restarts
 type ProcessingJob struct {
-	NoteID   primitive.ObjectID
-	Title    string
-	Content  string
-	Metadata map[string]interface{}
+	ID             primitive.ObjectID     `json:"id" bson:"_id,omitempty"`
+	NoteID         primitive.ObjectID     `json:"noteId" bson:"note_id"`
+	Title          string                 `json:"title" bson:"title"`
+	Content        string                 `json:"-" bson:"content"`
+	Category       string                 `json:"category" bson:"category"`
+	NoteCreated    time.Time              `json:"noteCreated,omitempty" bson:"note_created,omitempty"` // The note's created time, which imports and restores set explicitly
+	Metadata       map[string]interface{} `json:"-" bson:"metadata,omitempty"`
+	Summary        string                 `json:"-" bson:"summary,omitempty"`
+	StructuredData map[string]interface{} `json:"-" bson:"structured_data,omitempty"`
+	SummaryOnly    bool                   `json:"summaryOnly,omitempty" bson:"summary_only,omitempty"` // Re-embed only the summary and structured data, e.g. after the note was summarized again
+	Steps          []string               `json:"steps,omitempty" bson:"steps,omitempty"`              // Worker pipeline steps in order; empty to only embed
+	Generation     int64                  `json:"generation,omitempty" bson:"generation,omitempty"`    // The note's processing generation when queued
+	Automation     *AutomationPlan        `json:"automation,omitempty" bson:"automation,omitempty"`    // What the automation rules the new note matched do, run before Steps
+
+	Status      string    `json:"status" bson:"status"`            // JobPending or JobRunning; completed jobs are removed
+	Attempts    int       `json:"attempts" bson:"attempts"`        // Times the job was claimed
+	MaxAttempts int       `json:"maxAttempts" bson:"max_attempts"` // Attempts before the job moves to the dead-letter queue
+	LastError   string    `json:"lastError,omitempty" bson:"last_error,omitempty"`
+	AvailableAt time.Time `json:"availableAt" bson:"available_at"`         // Not claimed before this time, e.g. while waiting to retry
+	LeaseUntil  time.Time `json:"leaseUntil" bson:"lease_until,omitempty"` // A running job not finished by then was abandoned and is claimed again
+	CreatedAt   time.Time `json:"createdAt" bson:"created_at"`
+}
+
+// Processing job statuses
+const (
+	JobPending = "pending"
+	JobRunning = "running"
+)
+
+// DeadLetterJob is a job that failed all its attempts, kept with its last error until requeued
+type DeadLetterJob struct {
+	ProcessingJob `bson:",inline"`
+	FailedAt      time.Time `json:"failedAt" bson:"failed_at"`
+}
+
+// RequeueDeadLetterRequest selects dead-letter jobs to requeue; no IDs requeues all of them
+type RequeueDeadLetterRequest struct {
+	IDs []string `json:"ids,omitempty"`
+}
+
+// AssistantPersona customizes how the Q&A assistant answers questions and conversations
+type AssistantPersona struct {
+	Instructions string    `json:"instructions" bson:"instructions"`       // Who the assistant is and its tone; replaces the default introduction
+	Rules        []string  `json:"rules,omitempty" bson:"rules,omitempty"` // Answering rules added after the built-in ones
+	Custom       bool      `json:"custom" bson:"-"`                        // False when the default persona is in use
+	UpdatedAt    time.Time `json:"updatedAt,omitempty" bson:"updated_at"`
+}
+
+// UpdatePersonaRequest replaces the assistant persona
+type UpdatePersonaRequest struct {
+	Instructions string   `json:"instructions"`
+	Rules        []string `json:"rules,omitempty"`
+}
+
+// ClipRequest represents the request to save a web page as a note
+type ClipRequest struct {
+	URL  string   `json:"url" binding:"required"`
+	Tags []string `json:"tags,omitempty"`
+}
+
+// UploadResponse describes the notes created from an uploaded document
+// Long documents are split into several notes at page boundaries, in page order.
+type UploadResponse struct {
+	FileID   primitive.ObjectID `json:"fileId"`
+	FileName string             `json:"fileName"`
+	Pages    int                `json:"pages"` // 0 for images
+	Notes    []Note             `json:"notes"`
+}
+
+// ImageExtraction is what the AI reads off an uploaded image
+type ImageExtraction struct {
+	Text        string `json:"text"`        // Text visible in the image, transcribed as written
+	Description string `json:"description"` // What the image shows
 }
 
 // NoteAnalysis holds the combined AI analysis result
 type NoteAnalysis struct {
-	Title    string `json:"title"`
-	Category string `json:"category"`
-	Summary  string `json:"summary"`
+	Title      string   `json:"title"`
+	Category   string   `json:"category"`
+	Confidence float64  `json:"confidence"`
+	Tags       []string `json:"tags"`
+	Summary    string   `json:"summary"`
+}
+
+// ExtractedMetadata is the source information the AI found in a note's content
+// Fields are empty when the content doesn't mention them
+type ExtractedMetadata struct {
+	Author        string `json:"author"`
+	Source        string `json:"source"`        // Publication, site or show the content comes from
+	PublishedDate string `json:"publishedDate"` // YYYY-MM-DD
+	URL           string `json:"url"`
+}
+
+// Goal statuses
+const (
+	GoalStatusActive    = "active"
+	GoalStatusAchieved  = "achieved"
+	GoalStatusAbandoned = "abandoned"
+)
+
+// GoalStatuses lists every valid goal status
+var GoalStatuses = []string{GoalStatusActive, GoalStatusAchieved, GoalStatusAbandoned}
+
+// Goal is a goal extracted from a goals note, tracked through status updates found in later notes
+type Goal struct {
+	ID           primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Title        string             `json:"title" bson:"title"`
+	TargetDate   *time.Time         `json:"targetDate,omitempty" bson:"target_date,omitempty"`
+	Status       string             `json:"status" bson:"status"`
+	SourceNoteID primitive.ObjectID `json:"sourceNoteId" bson:"source_note_id"`
+	Updates      []GoalUpdate       `json:"updates" bson:"updates"`
+	Progress     string             `json:"progress,omitempty" bson:"progress,omitempty"` // Summary generated from the updates
+	Overdue      bool               `json:"overdue" bson:"-"`                             // Still active past its target date
+	CreatedAt    time.Time          `json:"createdAt" bson:"created_at"`
+	UpdatedAt    time.Time          `json:"updatedAt" bson:"updated_at"`
+}
+
+// GoalUpdate is progress on a goal reported in a later note
+type GoalUpdate struct {
+	NoteID    primitive.ObjectID `json:"noteId" bson:"note_id"`
+	Status    string             `json:"status" bson:"status"`
+	Summary   string             `json:"summary" bson:"summary"`
+	CreatedAt time.Time          `json:"createdAt" bson:"created_at"`
+}
+
+// ExtractedGoal is a new goal the AI found in a note
+type ExtractedGoal struct {
+	Title      string `json:"title"`
+	TargetDate string `json:"targetDate"` // YYYY-MM-DD, empty when the note gives none
+}
+
+// ExtractedGoalUpdate is progress on an active goal the AI found in a note
+type ExtractedGoalUpdate struct {
+	Goal    int    `json:"goal"` // 1-based position in the active goals given to the AI
+	Status  string `json:"status"`
+	Summary string `json:"summary"`
+}
+
+// GoalExtraction holds the goals and goal updates found in a note
+type GoalExtraction struct {
+	Goals   []ExtractedGoal       `json:"goals"`
+	Updates []ExtractedGoalUpdate `json:"updates"`
+}
+
+// GoalsResponse lists goals with the number of goals in each status
+type GoalsResponse struct {
+	Goals  []Goal         `json:"goals"`
+	Counts map[string]int `json:"counts"`
+}
+
+// UpdateGoalRequest changes a goal's status by hand, e.g. to correct the AI
+type UpdateGoalRequest struct {
+	Status string `json:"status" binding:"required"`
+}
+
+// ExtractGoalsRequest runs goal extraction on an existing note
+type ExtractGoalsRequest struct {
+	NoteID string `json:"noteId" binding:"required"`
+}
+
+// ClassificationExample is a user-confirmed classification used as a few-shot prompt example
+type ClassificationExample struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	NoteID    primitive.ObjectID `json:"noteId" bson:"note_id"`
+	Title     string             `json:"title" bson:"title"`
+	Excerpt   string             `json:"excerpt" bson:"excerpt"`
+	Category  string             `json:"category" bson:"category"`
+	CreatedAt time.Time          `json:"createdAt" bson:"created_at"`
+}
+
+// ReviewDecision confirms or corrects the category of a note in the review queue
+type ReviewDecision struct {
+	NoteID   string `json:"noteId" binding:"required"`
+	Category string `json:"category" binding:"required"`
+}
+
+type ReviewClassificationRequest struct {
+	Decisions []ReviewDecision `json:"decisions" binding:"required"`
 }
 
 // ChannelSettings holds per-channel configuration
 type ChannelSettings struct {
 	ID           primitive.ObjectID `json:"id" bson:"_id,omitempty"`
 	ChannelName  string             `json:"channelName" bson:"channel_name"`
+	ChannelKey   string             `json:"channelKey" bson:"channel_key,omitempty"` // Case/diacritic-insensitive lookup key; unset for names without letters or digits
+	Aliases      []string           `json:"aliases,omitempty" bson:"aliases"`        // Other names the channel appears under
+	AliasKeys    []string           `json:"-" bson:"alias_keys"`                     // Lookup keys for Aliases
 	Platform     string             `json:"platform" bson:"platform"`
-	ChannelUrl   string             `json:"channelUrl" bson:"channel_url"`     // YouTube channel URL for sync
-	PromptText   string             `json:"promptText" bson:"prompt_text"`     // Instructions for the AI
-	PromptSchema string             `json:"promptSchema" bson:"prompt_schema"` // Expected JSON output structure
+	ChannelUrl   string             `json:"channelUrl" bson:"channel_url"`                         // YouTube channel URL for sync
+	PromptText   string             `json:"promptText" bson:"prompt_text"`                         // Instructions for the AI
+	PromptSchema string             `json:"promptSchema" bson:"prompt_schema"`                     // Expected JSON output structure
+	SearchWeight float64            `json:"searchWeight,omitempty" bson:"search_weight,omitempty"` // Search score multiplier for the channel's notes; 0 means 1
+	Chunking     *ChunkingHints     `json:"chunking,omitempty" bson:"chunking"`                    // Overrides CHUNK_SIZE/CHUNK_OVERLAP for the channel's notes
 	UpdatedAt    time.Time          `json:"updatedAt" bson:"updated_at"`
+
+	// Outcome of the last YouTube sync of ChannelUrl; omitempty keeps settings saves from clearing it
+	LastSyncedAt  *time.Time `json:"lastSyncedAt,omitempty" bson:"last_synced_at,omitempty"`
+	LastSyncError string     `json:"lastSyncError,omitempty" bson:"last_sync_error,omitempty"`
+}
+
+// ChunkingHints override CHUNK_SIZE and CHUNK_OVERLAP for a channel's notes; zero keeps the default
+type ChunkingHints struct {
+	ChunkSize    int `json:"chunkSize,omitempty" bson:"chunk_size,omitempty"`
+	ChunkOverlap int `json:"chunkOverlap,omitempty" bson:"chunk_overlap,omitempty"`
+}
+
+// Channel preset library file format
+const (
+	ChannelPresetFormat  = "notes-channel-presets"
+	ChannelPresetVersion = 1
+)
+
+// ChannelPreset is a reusable channel configuration for a kind of channel, e.g. a podcast
+type ChannelPreset struct {
+	Name         string         `json:"name" bson:"_id"` // Lowercase identifier, e.g. "podcast"
+	Description  string         `json:"description,omitempty" bson:"description,omitempty"`
+	Platform     string         `json:"platform,omitempty" bson:"platform,omitempty"` // Used when applying the preset without a platform
+	PromptText   string         `json:"promptText,omitempty" bson:"prompt_text,omitempty"`
+	PromptSchema string         `json:"promptSchema,omitempty" bson:"prompt_schema,omitempty"`
+	Chunking     *ChunkingHints `json:"chunking,omitempty" bson:"chunking,omitempty"`
+	BuiltIn      bool           `json:"builtIn" bson:"-"` // Shipped with the app rather than imported
+}
+
+// ChannelPresetLibrary is the portable JSON file presets are imported from and exported to
+type ChannelPresetLibrary struct {
+	Format  string          `json:"format"`  // Always ChannelPresetFormat
+	Version int             `json:"version"` // ChannelPresetVersion when exported; imports accept it or older
+	Presets []ChannelPreset `json:"presets"`
+}
+
+// ApplyChannelPresetRequest configures a channel from a preset
+type ApplyChannelPresetRequest struct {
+	Preset   string `json:"preset" binding:"required"`
+	Platform string `json:"platform"` // Defaults to the preset's platform
+}
+
+// ChannelSettingsWithStats is a channel settings record joined with the channel's note statistics
+type ChannelSettingsWithStats struct {
+	ChannelSettings    `bson:",inline"`
+	NoteCount          int        `json:"noteCount"`
+	LastNoteAt         *time.Time `json:"lastNoteAt,omitempty"`
+	NotePlatforms      []string   `json:"notePlatforms"`
+	PlatformConsistent bool       `json:"platformConsistent"` // False when the channel's notes come from a different platform than configured
 }
 
 type CreateNoteRequest struct {
 	Content  string                 `json:"content" binding:"required"`
 	Title    string                 `json:"title,omitempty"`    // Optional, will be auto-generated if empty
+	Category string                 `json:"category,omitempty"` // Optional, skips rules and classification when set
+	Tags     []string               `json:"tags,omitempty"`     // Optional, merged with rule and AI-suggested tags
 	Metadata map[string]interface{} `json:"metadata"`           // Optional, for social media metadata
+
+	// StructuredData is optional, e.g. imported database properties; output from a channel's
+	// custom prompt replaces it
+	StructuredData map[string]interface{} `json:"structuredData,omitempty"`
 }
 
 type UpdateNoteRequest struct {
 	Content string `json:"content" binding:"required"`
+	Title   string `json:"title,omitempty"` // Optional, regenerated from the content if empty
+}
+
+// BulkUpdateRequest applies the same field changes to many notes at once
+// Nil fields are left untouched
+type BulkUpdateRequest struct {
+	IDs      []string  `json:"ids" binding:"required"`
+	Category *string   `json:"category,omitempty"`
+	Tags     *[]string `json:"tags,omitempty"` // Replaces the existing tags
+	Pinned   *bool     `json:"pinned,omitempty"`
+}
+
+type BulkUpdateResponse struct {
+	Matched  int64 `json:"matched"`
+	Modified int64 `json:"modified"`
+}
+
+// BulkDeleteRequest deletes many notes along with their chunks and embeddings
+type BulkDeleteRequest struct {
+	IDs []string `json:"ids" binding:"required"`
+}
+
+// Bulk delete result statuses
+const (
+	BulkDeleteStatusDeleted  = "deleted"
+	BulkDeleteStatusNotFound = "not_found"
+	BulkDeleteStatusInvalid  = "invalid_id"
+	BulkDeleteStatusFailed   = "failed"
+)
+
+// BulkDeleteResult is the outcome of deleting a single note in a bulk delete
+type BulkDeleteResult struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type BulkDeleteResponse struct {
+	Deleted int                `json:"deleted"`
+	Results []BulkDeleteResult `json:"results"` // In request order
+}
+
+// EmbeddingRecord is one exported embedding, written as a line of JSONL
+type EmbeddingRecord struct {
+	ID         uint64    `json:"id"` // Qdrant point ID; 0 on import derives it from chunkId
+	ChunkID    string    `json:"chunkId"`
+	NoteID     string    `json:"noteId"`
+	Category   string    `json:"category,omitempty"`
+	ChannelKey string    `json:"channelKey,omitempty"`
+	Hash       string    `json:"hash,omitempty"`      // Content hash of the embedded chunk
+	ChunkType  string    `json:"chunkType,omitempty"` // Empty for content chunks stored before types existed
+	Author     string    `json:"author,omitempty"`
+	Platform   string    `json:"platform,omitempty"`
+	CreatedAt  int64     `json:"createdAt,omitempty"` // Unix seconds the note was created; 0 for older points
+	Vector     []float32 `json:"vector"`
+}
+
+type EmbeddingImportResponse struct {
+	Imported int      `json:"imported"`
+	Skipped  int      `json:"skipped"`
+	Errors   []string `json:"errors,omitempty"` // First rejected lines with the reason
+}
+
+// ReconciliationReport compares the chunks stored in MongoDB with the vectors stored in Qdrant
+type ReconciliationReport struct {
+	ID               primitive.ObjectID   `json:"id" bson:"_id,omitempty"`
+	StartedAt        time.Time            `json:"startedAt" bson:"started_at"`
+	FinishedAt       time.Time            `json:"finishedAt" bson:"finished_at"`
+	Chunks           int                  `json:"chunks" bson:"chunks"`
+	Points           int                  `json:"points" bson:"points"`
+	MissingVectors   int                  `json:"missingVectors" bson:"missing_vectors"`     // Chunks with no point
+	OrphanVectors    int                  `json:"orphanVectors" bson:"orphan_vectors"`       // Points whose chunk no longer exists
+	DuplicateVectors int                  `json:"duplicateVectors" bson:"duplicate_vectors"` // Extra points for an already-seen chunk
+	HashMismatches   int                  `json:"hashMismatches" bson:"hash_mismatches"`     // Points embedded from different chunk text
+	Unverified       int                  `json:"unverified" bson:"unverified"`              // Points stored before content hashes existed
+	DriftRatio       float64              `json:"driftRatio" bson:"drift_ratio"`
+	AlertThreshold   float64              `json:"alertThreshold" bson:"alert_threshold"`
+	Alert            bool                 `json:"alert" bson:"alert"`
+	DriftedNoteIDs   []primitive.ObjectID `json:"driftedNoteIds" bson:"drifted_note_ids"` // Capped sample of affected notes
+	Error            string               `json:"error,omitempty" bson:"error,omitempty"`
+}
+
+// Bulk AI migrations tracked as MigrationRun.Kind
+const (
+	MigrationClassify  = "classify"  // POST /migrate/classify
+	MigrationTitles    = "titles"    // POST /migrate/titles
+	MigrationReindex   = "reindex"   // POST /admin/reindex
+	MigrationSummaries = "summaries" // POST /admin/resummarize
+)
+
+// Migration run statuses
+const (
+	MigrationRunning     = "running"
+	MigrationCompleted   = "completed"
+	MigrationInterrupted = "interrupted" // Cancelled or cut short by a restart; resumable
+)
+
+// MigrationRun records the progress of a bulk AI migration so an interrupted run can resume
+// Counts cover the notes up to the checkpoint, in note ID order.
+type MigrationRun struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Kind        string             `json:"kind" bson:"kind"`
+	Status      string             `json:"status" bson:"status"`
+	Parallelism int                `json:"parallelism" bson:"parallelism"`
+	PerMinute   int                `json:"perMinute,omitempty" bson:"per_minute,omitempty"` // Notes started per minute; 0 isn't throttled
+	Outdated    bool               `json:"outdated,omitempty" bson:"outdated,omitempty"`    // Only notes whose title or summary was outdated (see GenerationInfo)
+	Total       int                `json:"total" bson:"total"`                              // Notes to migrate when the run started
+	Processed   int                `json:"processed" bson:"processed"`
+	Succeeded   int                `json:"succeeded" bson:"succeeded"`
+	Errors      int                `json:"errors" bson:"errors"`
+	RuleMatched int                `json:"ruleMatched,omitempty" bson:"rule_matched,omitempty"` // Classify: notes categorized by a rule
+	NeedsReview int                `json:"needsReview,omitempty" bson:"needs_review,omitempty"` // Classify: notes flagged for review
+	RateLimited int                `json:"rateLimited" bson:"rate_limited"`                     // AI calls retried after a rate limit
+	Checkpoint  primitive.ObjectID `json:"checkpoint" bson:"checkpoint"`                        // Every note up to this ID is done
+	Resumes     int                `json:"resumes" bson:"resumes"`
+	StartedAt   time.Time          `json:"startedAt" bson:"started_at"`
+	UpdatedAt   time.Time          `json:"updatedAt" bson:"updated_at"`
+	FinishedAt  *time.Time         `json:"finishedAt,omitempty" bson:"finished_at,omitempty"`
+}
+
+// ReindexProgress reports how far a reindex has got re-embedding the notes it queued
+// The run counts notes whose processing finished: indexed and skipped ones succeeded, failed ones are errors.
+type ReindexProgress struct {
+	Run            *MigrationRun  `json:"run"`
+	EmbeddingModel string         `json:"embeddingModel"`
+	StatusCounts   map[string]int `json:"statusCounts"` // Notes by processing status
+	Remaining      int            `json:"remaining"`    // Notes still pending or processing
+}
+
+// AIOperationEstimate is what a bulk AI operation is expected to take, worked out before it runs
+// Tokens are estimated from the stored length of the notes it covers and the prompts it sends; the
+// cost is only reported when the model's AI_*_PRICE_PER_MILLION prices are set.
+type AIOperationEstimate struct {
+	Operation        string   `json:"operation"` // A MigrationRun kind
+	Notes            int      `json:"notes"`
+	Model            string   `json:"model"`
+	InputTokens      int      `json:"inputTokens"`
+	OutputTokens     int      `json:"outputTokens"`
+	EstimatedCost    *float64 `json:"estimatedCost,omitempty"` // USD
+	PerMinute        int      `json:"perMinute,omitempty"`     // Notes started per minute, for throttled operations
+	EstimatedMinutes float64  `json:"estimatedMinutes"`        // At PerMinute or the AI request quota, whichever is lower; 0 when neither throttles
+	Resume           bool     `json:"resume"`                  // Covers only the notes an unfinished run has left
+}
+
+// ContentLength sums the text of a channel's notes, for estimating what AI operations over them take
+type ContentLength struct {
+	Author       string `json:"author"`
+	Platform     string `json:"platform"`
+	Notes        int    `json:"notes"`
+	TitleChars   int    `json:"titleChars"`
+	ContentChars int    `json:"contentChars"`
+	SummaryChars int    `json:"summaryChars"`
+}
+
+// YouTubeSyncReport describes one sync of the channels that have a YouTube channel URL
+type YouTubeSyncReport struct {
+	StartedAt  time.Time            `json:"startedAt"`
+	FinishedAt time.Time            `json:"finishedAt"`
+	Channels   []YouTubeChannelSync `json:"channels"`
+}
+
+// YouTubeChannelSync is the outcome of syncing one channel's latest uploads
+type YouTubeChannelSync struct {
+	Channel      string   `json:"channel"`
+	Checked      int      `json:"checked"`      // Latest uploads looked at
+	Created      int      `json:"created"`      // New notes
+	Existing     int      `json:"existing"`     // Uploads already saved as notes
+	NoTranscript int      `json:"noTranscript"` // Uploads without captions yet; retried on the next sync
+	Failed       int      `json:"failed"`
+	Errors       []string `json:"errors,omitempty"` // Why uploads failed
+	Error        string   `json:"error,omitempty"`  // Why the channel couldn't be synced at all
+}
+
+// PodcastFeed is a podcast RSS feed whose new episodes are saved as notes
+type PodcastFeed struct {
+	ID            primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	URL           string             `json:"url" bson:"url"`
+	Title         string             `json:"title" bson:"title"` // Saved as the author of the feed's notes
+	LastCheckedAt *time.Time         `json:"lastCheckedAt,omitempty" bson:"last_checked_at,omitempty"`
+	LastError     string             `json:"lastError,omitempty" bson:"last_error,omitempty"`
+	CreatedAt     time.Time          `json:"createdAt" bson:"created_at"`
+}
+
+// AddPodcastRequest represents the request to follow a podcast feed
+type AddPodcastRequest struct {
+	URL   string `json:"url" binding:"required"`
+	Title string `json:"title,omitempty"` // Optional, defaults to the feed's own title
+}
+
+// PodcastSyncReport describes one sync of the followed podcast feeds
+type PodcastSyncReport struct {
+	StartedAt  time.Time         `json:"startedAt"`
+	FinishedAt time.Time         `json:"finishedAt"`
+	Feeds      []PodcastFeedSync `json:"feeds"`
+}
+
+// PodcastFeedSync is the outcome of syncing one feed's latest episodes
+type PodcastFeedSync struct {
+	Feed         string   `json:"feed"`
+	Checked      int      `json:"checked"`      // Latest episodes looked at
+	Created      int      `json:"created"`      // New notes
+	Existing     int      `json:"existing"`     // Episodes already saved as notes
+	NoTranscript int      `json:"noTranscript"` // Episodes without a transcript and no transcription API configured
+	Failed       int      `json:"failed"`
+	Errors       []string `json:"errors,omitempty"` // Why episodes failed
+	Error        string   `json:"error,omitempty"`  // Why the feed couldn't be synced at all
+}
+
+// Note event types delivered to webhooks
+const (
+	NoteEventCreated          = "note.created"
+	NoteEventUpdated          = "note.updated"
+	NoteEventDeleted          = "note.deleted"
+	NoteEventSummaryGenerated = "summary.generated"
+	NoteEventProcessed        = "note.processed" // Embedding finished: indexed, skipped or failed
+)
+
+// NoteEventTypes lists every event type a webhook can subscribe to
+var NoteEventTypes = []string{NoteEventCreated, NoteEventUpdated, NoteEventDeleted, NoteEventSummaryGenerated, NoteEventProcessed}
+
+// NoteEvent records a change to a note for external indexers
+// Event IDs increase over time and double as replay cursors
+type NoteEvent struct {
+	ID        primitive.ObjectID     `json:"id" bson:"_id,omitempty"`
+	Type      string                 `json:"type" bson:"type"`
+	NoteID    primitive.ObjectID     `json:"noteId" bson:"note_id"`
+	Delta     map[string]interface{} `json:"delta,omitempty" bson:"delta,omitempty"` // Changed fields with their new values
+	CreatedAt time.Time              `json:"createdAt" bson:"created_at"`
+}
+
+// Webhook is an external endpoint that receives signed note events in order
+type Webhook struct {
+	ID              primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	URL             string             `json:"url" bson:"url"`
+	Secret          string             `json:"secret,omitempty" bson:"secret,omitempty"`          // Only returned when the webhook is created; stored with its WebhookSecrets, or here for webhooks created before those were kept apart
+	EventTypes      []string           `json:"eventTypes,omitempty" bson:"event_types,omitempty"` // Empty receives every type
+	Cursor          primitive.ObjectID `json:"cursor" bson:"cursor"`                              // Last delivered event ID
+	LastDeliveredAt *time.Time         `json:"lastDeliveredAt,omitempty" bson:"last_delivered_at,omitempty"`
+	LastError       string             `json:"lastError,omitempty" bson:"last_error,omitempty"`
+	CreatedAt       time.Time          `json:"createdAt" bson:"created_at"`
+
+	AuthType string `json:"authType" bson:"auth_type,omitempty"` // WebhookAuth*; empty for webhooks saved before outbound auth was configurable, which sign only
+}
+
+// Webhook outbound auth types; every delivery is also signed with the webhook secret
+const (
+	WebhookAuthHMAC   = "hmac"   // Only the X-Webhook-Signature HMAC of the body
+	WebhookAuthBearer = "bearer" // An Authorization: Bearer token
+	WebhookAuthMTLS   = "mtls"   // A TLS client certificate, for https endpoints
+)
+
+// WebhookAuth configures how deliveries authenticate to a webhook's endpoint
+// Credentials are write-only: they are stored apart from the webhook and never returned.
+type WebhookAuth struct {
+	Type       string `json:"type"`                 // WebhookAuth*; defaults to hmac
+	Token      string `json:"token,omitempty"`      // Bearer token
+	ClientCert string `json:"clientCert,omitempty"` // PEM client certificate for mTLS
+	ClientKey  string `json:"clientKey,omitempty"`  // PEM private key of the client certificate
+	CACert     string `json:"caCert,omitempty"`     // PEM CA certificates to trust for the endpoint, e.g. a private CA; system roots when empty
+}
+
+// WebhookSecrets holds a webhook's signing secret and outbound credentials, in the webhook_secrets
+// collection so listing or exporting webhooks never loads them
+type WebhookSecrets struct {
+	WebhookID  primitive.ObjectID `json:"-" bson:"_id"`
+	Secret     string             `json:"-" bson:"secret"`
+	Token      string             `json:"-" bson:"token,omitempty"`
+	ClientCert string             `json:"-" bson:"client_cert,omitempty"`
+	ClientKey  string             `json:"-" bson:"client_key,omitempty"`
+	CACert     string             `json:"-" bson:"ca_cert,omitempty"`
+	UpdatedAt  time.Time          `json:"-" bson:"updated_at"`
+}
+
+type CreateWebhookRequest struct {
+	URL        string       `json:"url" binding:"required"`
+	Secret     string       `json:"secret,omitempty"` // Generated when empty
+	EventTypes []string     `json:"eventTypes,omitempty"`
+	Auth       *WebhookAuth `json:"auth,omitempty"` // Defaults to hmac
+}
+
+// ReplayWebhookRequest rewinds a webhook so events after the cursor are delivered again
+type ReplayWebhookRequest struct {
+	Cursor string `json:"cursor"` // Event ID; empty replays every retained event
+}
+
+// GraphQLRequest is a GraphQL query sent to POST /graphql
+type GraphQLRequest struct {
+	Query         string                 `json:"query" binding:"required"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// NotionImportResponse reports the outcome of a Notion export import
+type NotionImportResponse struct {
+	Imported   int      `json:"imported"`
+	Duplicates int      `json:"duplicates"` // Pages imported before, matched by their Notion URL
+	Failed     int      `json:"failed"`
+	Errors     []string `json:"errors,omitempty"` // The first MAX_IMPORT_ERRORS failures
+}
+
+// Outcomes of a pushed vault file, reported in VaultPushResult.Status
+const (
+	VaultStatusCreated   = "created"
+	VaultStatusUpdated   = "updated"
+	VaultStatusDeleted   = "deleted"
+	VaultStatusUnchanged = "unchanged"
+	VaultStatusConflict  = "conflict" // The note changed on the server since BaseHash; pull it and push again
+	VaultStatusFailed    = "failed"
+)
+
+// VaultFileInfo identifies a note's file in an Obsidian vault
+type VaultFileInfo struct {
+	Path   string `json:"path"`
+	Hash   string `json:"hash"` // Hex SHA-256 of the file content
+	NoteID string `json:"noteId"`
+}
+
+// VaultManifest lists every note as a vault file
+type VaultManifest struct {
+	Files []VaultFileInfo `json:"files"`
+}
+
+// VaultFile is a vault file with its content
+type VaultFile struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+	Hash    string `json:"hash"`
+}
+
+// VaultPullRequest asks for the content of vault files
+type VaultPullRequest struct {
+	Paths []string `json:"paths" binding:"required"`
+}
+
+// VaultPullResponse returns the requested files; paths with no note are listed as missing
+type VaultPullResponse struct {
+	Files   []VaultFile `json:"files"`
+	Missing []string    `json:"missing"`
+}
+
+// VaultPushFile is a file changed in the vault since the last sync
+type VaultPushFile struct {
+	Path     string `json:"path"`
+	Content  string `json:"content"`
+	BaseHash string `json:"baseHash,omitempty"` // Hash the vault last synced; empty for new files
+	Deleted  bool   `json:"deleted,omitempty"`
+}
+
+// VaultPushRequest pushes changed vault files
+type VaultPushRequest struct {
+	Files []VaultPushFile `json:"files" binding:"required"`
+}
+
+// VaultPushResult is the outcome for one pushed file
+type VaultPushResult struct {
+	Path   string `json:"path"`
+	Status string `json:"status"`
+	Hash   string `json:"hash,omitempty"` // The server's hash after the push
+	NoteID string `json:"noteId,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// VaultPushResponse reports the outcome of a push, in request order
+type VaultPushResponse struct {
+	Results []VaultPushResult `json:"results"`
+}
+
+// BackupVersion is the current JSON backup format version
+const BackupVersion = 1
+
+// Backup is a complete JSON dump of the notes database
+type Backup struct {
+	Version         int               `json:"version"`
+	ExportedAt      time.Time         `json:"exportedAt"`
+	Notes           []Note            `json:"notes"`
+	ChannelSettings []ChannelSettings `json:"channelSettings"`
+	Chunks          []NoteChunk       `json:"chunks,omitempty"` // Only with includeChunks; restored with their notes
+}
+
+// RestoreResponse reports the outcome of a backup restore
+type RestoreResponse struct {
+	NotesRestored           int `json:"notesRestored"`
+	NotesSkipped            int `json:"notesSkipped"` // Already present with the same ID
+	ChannelSettingsRestored int `json:"channelSettingsRestored"`
+	ChunksRestored          int `json:"chunksRestored"`      // Of restored notes; those notes aren't re-embedded
+	EmbeddingJobsQueued     int `json:"embeddingJobsQueued"` // For restored notes the backup has no chunks for
+}
+
+// AuditEntry records a change applied to notes outside the normal create/update flow
+type AuditEntry struct {
+	ID        primitive.ObjectID     `json:"id" bson:"_id,omitempty"`
+	Action    string                 `json:"action" bson:"action"`
+	NoteIDs   []primitive.ObjectID   `json:"noteIds" bson:"note_ids"`
+	Changes   map[string]interface{} `json:"changes" bson:"changes"`
+	Matched   int64                  `json:"matched" bson:"matched"`
+	Modified  int64                  `json:"modified" bson:"modified"`
+	CreatedAt time.Time              `json:"createdAt" bson:"created_at"`
+}
+
+// AI operations kept in a note's AI history
+const (
+	AIOperationAnalysis       = "analysis" // Title, category, tags and summary in one call, when a note is created
+	AIOperationClassification = "classification"
+	AIOperationTitle          = "title"
+	AIOperationSummary        = "summary"
+	AIOperationSummaryPatch   = "summary_patch" // A summary updated for a small content edit rather than regenerated
+	AIOperationTranslation    = "translation"   // Content and summary translated by POST /notes/:id/translate
+)
+
+// AIOperation records an AI operation performed on a note, so a result can be traced to the model
+// and prompt that produced it. Token counts are estimated from the length of the text sent and returned.
+type AIOperation struct {
+	ID            primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	NoteID        primitive.ObjectID `json:"noteId" bson:"note_id"`
+	Operation     string             `json:"operation" bson:"operation"`
+	Model         string             `json:"model" bson:"model"`
+	PromptVersion int                `json:"promptVersion" bson:"prompt_version"`               // Of the operation's built-in prompt
+	PromptHash    string             `json:"promptHash,omitempty" bson:"prompt_hash,omitempty"` // Identifies the channel's custom prompt and schema, when used
+	InputTokens   int                `json:"inputTokens" bson:"input_tokens"`
+	OutputTokens  int                `json:"outputTokens" bson:"output_tokens"`
+	Output        string             `json:"output,omitempty" bson:"output,omitempty"` // The reply, cut off after AI_HISTORY_OUTPUT_CHARS
+	Error         string             `json:"error,omitempty" bson:"error,omitempty"`
+	CreatedAt     time.Time          `json:"createdAt" bson:"created_at"`
+}
+
+// AISample is a generation call kept for debugging, sampled at AI_SAMPLE_RATE: the raw prompt and
+// reply, with secrets redacted and each cut off after AI_SAMPLE_MAX_CHARS
+type AISample struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Operation  string             `json:"operation" bson:"operation"` // The AI client method that made the call, e.g. GenerateTitle
+	Model      string             `json:"model" bson:"model"`
+	Prompt     string             `json:"prompt" bson:"prompt"`
+	Response   string             `json:"response,omitempty" bson:"response,omitempty"`
+	Error      string             `json:"error,omitempty" bson:"error,omitempty"`
+	ImageType  string             `json:"imageType,omitempty" bson:"image_type,omitempty"` // Of the image sent with the prompt, which isn't kept
+	DurationMs int64              `json:"durationMs" bson:"duration_ms"`                   // Including retries
+	CreatedAt  time.Time          `json:"createdAt" bson:"created_at"`
 }
 
 type CategoryCount struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Count       int    `json:"count"`
+}
+
+// CategoryChange reports a category rename or merge, or with DryRun what it would do
+type CategoryChange struct {
+	From        []string `json:"from"`
+	To          string   `json:"to"`
+	Notes       int64    `json:"notes"`       // Notes moved, or that would move with DryRun
+	Rules       int64    `json:"rules"`       // Category rules now assigning To
+	Automations int64    `json:"automations"` // Automation rules now triggered by To
+	Pipelines   int64    `json:"pipelines"`   // Per-category pipelines moved to To, or dropped when To has one
+	Examples    int64    `json:"examples"`    // Classification examples now confirming To
+	DryRun      bool     `json:"dryRun"`
+}
+
+// Category is a note category notes are classified into, kept in the categories collection
+type Category struct {
+	Name        string    `json:"name" bson:"_id"` // Lowercase words joined by hyphens, e.g. "meal-planning"
+	Description string    `json:"description,omitempty" bson:"description,omitempty"`
+	CreatedAt   time.Time `json:"createdAt" bson:"created_at"`
+}
+
+// TagCount is a tag and the number of notes carrying it
+type TagCount struct {
 	Name  string `json:"name"`
 	Count int    `json:"count"`
 }
+
+// EntityCount is an entity and the number of notes mentioning it
+type EntityCount struct {
+	Name          string    `json:"name"` // As most recently written
+	Type          string    `json:"type"`
+	Count         int       `json:"count"`
+	LastMentioned time.Time `json:"lastMentioned"` // Creation time of the newest note mentioning it
+}
+
+// Knowledge graph node and edge types
+const (
+	GraphNodeNote   = "note"
+	GraphNodeEntity = "entity"
+
+	GraphEdgeMention = "mention" // A note mentions an entity
+	GraphEdgeSimilar = "similar" // Two notes are semantically similar
+)
+
+// GraphEdge links two knowledge graph nodes
+// Edges are stored with the note they were derived from and replaced whenever it is reprocessed.
+type GraphEdge struct {
+	NoteID    primitive.ObjectID `json:"-" bson:"note_id"`
+	Source    string             `json:"source" bson:"source"` // Node ID of the note
+	Target    string             `json:"target" bson:"target"` // Node ID of the entity or similar note
+	Type      string             `json:"type" bson:"type"`     // GraphEdge*
+	Weight    float32            `json:"weight" bson:"weight"` // 1 for mentions, the similarity for similar notes
+	UpdatedAt time.Time          `json:"-" bson:"updated_at"`
+}
+
+// GraphNode is a note or an entity in the knowledge graph
+type GraphNode struct {
+	ID         string `json:"id"`   // "note:<id>" or "entity:<type>:<lowercased name>"
+	Type       string `json:"type"` // GraphNode*
+	Label      string `json:"label"`
+	Category   string `json:"category,omitempty"`   // Notes only
+	EntityType string `json:"entityType,omitempty"` // Entities only
+	Degree     int    `json:"degree"`               // Edges touching the node, e.g. to size it
+}
+
+// Graph is the knowledge graph of notes and the entities they mention, as nodes and edges for a
+// force-directed layout
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// DayCount is the number of notes created on a calendar day (YYYY-MM-DD)
+type DayCount struct {
+	Date  string `json:"date"`
+	Count int    `json:"count"`
+}
+
+// Heatmap is a GitHub-style grid of daily note creation counts
+// Weeks are columns of seven days starting on Sunday; days outside From..To are -1
+type Heatmap struct {
+	From     string  `json:"from"`
+	To       string  `json:"to"`
+	Timezone string  `json:"timezone"`
+	Total    int     `json:"total"`
+	Max      int     `json:"max"` // Highest daily count, for scaling colors
+	Weeks    [][]int `json:"weeks"`
+}
+
+// On this day scopes accepted by GET /onthisday
+const (
+	OnThisDayScopeYear  = "year"  // The same month and day in previous years
+	OnThisDayScopeMonth = "month" // The same day of the month in previous months
+)
+
+// OnThisDayYear holds the resurfaced notes from one year, newest first
+type OnThisDayYear struct {
+	Year     int    `json:"year"`
+	YearsAgo int    `json:"yearsAgo"`
+	Notes    []Note `json:"notes"`
+}
+
+// OnThisDay lists notes created or published on the same date in the past, newest year first
+type OnThisDay struct {
+	Date     string          `json:"date"`
+	Timezone string          `json:"timezone"`
+	Scope    string          `json:"scope"`
+	Years    []OnThisDayYear `json:"years"`
+}
+
+// Access report orders accepted by GET /stats/access
+const (
+	AccessOrderMost  = "most"
+	AccessOrderLeast = "least"
+)
+
+// NoteAccess is a note's view counters in an access report
+type NoteAccess struct {
+	ID           primitive.ObjectID `json:"id" bson:"_id"`
+	Title        string             `json:"title" bson:"title"`
+	Category     string             `json:"category" bson:"category"`
+	ViewCount    int                `json:"viewCount" bson:"view_count"`
+	LastViewedAt *time.Time         `json:"lastViewedAt,omitempty" bson:"last_viewed_at,omitempty"`
+	Created      time.Time          `json:"created" bson:"created"`
+}
+
+// NoteIndexing is a note's processing status and embedding coverage
+type NoteIndexing struct {
+	ID                 primitive.ObjectID `json:"id" bson:"_id"`
+	Title              string             `json:"title" bson:"title"`
+	ProcessingStatus   string             `json:"processingStatus" bson:"processing_status"`
+	ChunkCount         int                `json:"chunkCount" bson:"chunk_count"`
+	EmbeddedChunkCount int                `json:"embeddedChunkCount" bson:"embedded_chunk_count"`
+	Created            time.Time          `json:"created" bson:"created"`
+}
+
+// IndexingReport tells which notes are fully indexed for search
+type IndexingReport struct {
+	StatusCounts map[string]int `json:"statusCounts"` // Notes per processing status; "unknown" for notes embedded before statuses were tracked
+	Incomplete   []NoteIndexing `json:"incomplete"`   // Pending, processing and failed notes, oldest first
+}
+
+// AccessReport lists the most or least viewed unarchived notes
+// Least viewed lists never-opened notes first, then the ones opened longest ago
+type AccessReport struct {
+	Order string       `json:"order"`
+	Notes []NoteAccess `json:"notes"`
+}
+
+// Relations between an older note and a newer note on the same subject
+const (
+	NoteRelationSupersedes  = "supersedes"  // The newer note replaces what the older one describes
+	NoteRelationContradicts = "contradicts" // The newer note disagrees with the older one
+	NoteRelationConsistent  = "consistent"  // Both notes still hold
+	NoteRelationUnrelated   = "unrelated"
+)
+
+// NoteTranslation is a note's content and summary translated into another language
+// Saved translations are kept in the note's structuredData.translations, keyed by language.
+type NoteTranslation struct {
+	Language     string    `json:"language"`
+	Content      string    `json:"content"`
+	Summary      string    `json:"summary,omitempty"`
+	Model        string    `json:"model"`
+	TranslatedAt time.Time `json:"translatedAt"`
+	Saved        bool      `json:"saved"`
+}
+
+// NoteComparison is the AI's judgement of how a newer note relates to an older one
+type NoteComparison struct {
+	Relation string `json:"relation"`
+	Reason   string `json:"reason"`
+}
+
+// SuccessorNote is a newer note suggested as the replacement for an outdated one
+type SuccessorNote struct {
+	ID       primitive.ObjectID `json:"id"`
+	Title    string             `json:"title"`
+	Created  time.Time          `json:"created"`
+	Relation string             `json:"relation"` // NoteRelationSupersedes or NoteRelationContradicts
+	Reason   string             `json:"reason"`
+}
+
+// OutdatedCandidate is an old note that newer notes supersede or contradict
+type OutdatedCandidate struct {
+	ID         primitive.ObjectID `json:"id"`
+	Title      string             `json:"title"`
+	Category   string             `json:"category"`
+	Created    time.Time          `json:"created"`
+	AgeDays    int                `json:"ageDays"`
+	Successors []SuccessorNote    `json:"successors"`
+}
+
+// OutdatedReport lists old notes in fast-moving categories that have likely gone stale
+type OutdatedReport struct {
+	Categories     []string            `json:"categories"`
+	StaleAfterDays int                 `json:"staleAfterDays"`
+	Checked        int                 `json:"checked"`   // Old notes compared against newer ones, oldest first
+	Truncated      bool                `json:"truncated"` // More old notes exist than one report checks
+	Candidates     []OutdatedCandidate `json:"candidates"`
+}
+
+// Search suggestion types
+const (
+	SuggestionQuery    = "query"    // A previously run search
+	SuggestionTitle    = "title"    // A note title
+	SuggestionTag      = "tag"      // A tag on existing notes
+	SuggestionCategory = "category" // A configured category
+)
+
+// Suggestion is a typeahead completion for the search box
+type Suggestion struct {
+	Type   string              `json:"type"`
+	Text   string              `json:"text"`
+	NoteID *primitive.ObjectID `json:"noteId,omitempty"` // Set for title suggestions
+	Count  int                 `json:"count,omitempty"`  // Notes per tag or times a query was run
+	Score  float64             `json:"score"`
+}
+
+// SearchQuery records a search that was run, for recent-query suggestions
+type SearchQuery struct {
+	ID       primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Query    string             `json:"query" bson:"query"`
+	Key      string             `json:"key" bson:"key"` // Lowercased, whitespace-collapsed query used for prefix lookups
+	Count    int                `json:"count" bson:"count"`
+	LastUsed time.Time          `json:"lastUsed" bson:"last_used"`
+}
+
+// Category rule match types
+const (
+	RuleMatchRegex    = "regex"    // Pattern is a regular expression
+	RuleMatchKeyword  = "keyword"  // Pattern is a comma-separated list of keywords (case-insensitive)
+	RuleMatchMetadata = "metadata" // Pattern must equal the metadata value named by Field (case-insensitive)
+)
+
+// CategoryRule assigns a category and tags to predictable content without calling the LLM classifier
+type CategoryRule struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name      string             `json:"name" bson:"name"`
+	MatchType string             `json:"matchType" bson:"match_type"`
+	Field     string             `json:"field" bson:"field"` // "title", "content" or empty for both; metadata key for metadata rules
+	Pattern   string             `json:"pattern" bson:"pattern"`
+	Category  string             `json:"category" bson:"category"`
+	Tags      []string           `json:"tags" bson:"tags"`
+	Priority  int                `json:"priority" bson:"priority"` // Higher priority rules are evaluated first
+	Enabled   bool               `json:"enabled" bson:"enabled"`
+	UpdatedAt time.Time          `json:"updatedAt" bson:"updated_at"`
+}
+
+// RuleMatch is the outcome of evaluating category rules against a note
+type RuleMatch struct {
+	RuleID   primitive.ObjectID `json:"ruleId"`
+	RuleName string             `json:"ruleName"`
+	Category string             `json:"category"`
+	Tags     []string           `json:"tags"`
+}
+
+// Automation rule triggers: what a new note must have for the rule to run
+const (
+	AutomationTriggerTag      = "tag"      // The note carries the tag given as the rule's value
+	AutomationTriggerCategory = "category" // The note is in the category given as the rule's value
+	AutomationTriggerChannel  = "channel"  // The note's author is the channel given as the rule's value, matched by channel key
+)
+
+// Automation rule actions
+const (
+	AutomationActionRunStep         = "run_step"          // Run a worker pipeline step, e.g. extract_entities, even when the note's pipeline leaves it out
+	AutomationActionApplyPreset     = "apply_preset"      // Summarize the note with a channel preset's prompt and schema, e.g. a recipe schema
+	AutomationActionAddToCollection = "add_to_collection" // Add the note to a named collection
+)
+
+// AutomationRule runs actions on new notes carrying a tag, in a category or from a channel
+// The actions run in the worker pipeline along with the note's pipeline steps.
+type AutomationRule struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Name      string             `json:"name" bson:"name"`
+	Trigger   string             `json:"trigger" bson:"trigger"` // AutomationTrigger*
+	Value     string             `json:"value" bson:"value"`     // The tag, category or channel that triggers the rule
+	Actions   []AutomationAction `json:"actions" bson:"actions"`
+	Enabled   bool               `json:"enabled" bson:"enabled"`
+	UpdatedAt time.Time          `json:"updatedAt" bson:"updated_at"`
+}
+
+// AutomationAction is one thing an automation rule does to the notes it matches
+type AutomationAction struct {
+	Type  string `json:"type" bson:"type"`   // AutomationAction*
+	Value string `json:"value" bson:"value"` // The step, preset or collection name
+}
+
+// AutomationPlan combines the actions of every automation rule a new note matched, resolved when
+// the note is created and carried to the worker by its processing job
+type AutomationPlan struct {
+	Rules        []string `json:"rules" bson:"rules"`                                 // Names of the matched rules
+	Steps        []string `json:"steps,omitempty" bson:"steps,omitempty"`             // Worker steps added to the note's pipeline
+	Preset       string   `json:"preset,omitempty" bson:"preset,omitempty"`           // The preset the note is summarized with; the first matched rule's wins
+	PromptText   string   `json:"-" bson:"prompt_text,omitempty"`                     // The preset's prompt, as it was when the note was created
+	PromptSchema string   `json:"-" bson:"prompt_schema,omitempty"`                   // The preset's schema, as it was when the note was created
+	Collections  []string `json:"collections,omitempty" bson:"collections,omitempty"` // Collections the note is added to
+}
+
+type TestRuleRequest struct {
+	Title    string                 `json:"title"`
+	Content  string                 `json:"content"`
+	Metadata map[string]interface{} `json:"metadata"`
+}
@@ -0,0 +1,336 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io"
+	"strconv"
+)
+
+// parser reads PDF objects from file data, object streams, content streams and CMaps
+// Operators and other bare words come back as keywords.
+type parser struct {
+	data []byte
+	pos  int
+}
+
+// parseObject reads the next object, returning io.EOF at the end of the data
+func (p *parser) parseObject() (object, error) {
+	p.skipSpace()
+	if p.pos >= len(p.data) {
+		return nil, io.EOF
+	}
+
+	switch c := p.data[p.pos]; c {
+	case '/':
+		return p.readName(), nil
+	case '(':
+		return p.readLiteral(), nil
+	case '<':
+		if p.pos+1 < len(p.data) && p.data[p.pos+1] == '<' {
+			p.pos += 2
+			return p.readDict(), nil
+		}
+		return p.readHex(), nil
+	case '[':
+		p.pos++
+		return p.readArray(), nil
+	case ']', '>', ')', '{', '}':
+		p.pos++
+		return keyword(c), nil
+	}
+
+	token := p.readRegular()
+	if token == "" {
+		p.pos++
+		return keyword(p.data[p.pos-1]), nil
+	}
+	switch token {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	if !isNumber(token) {
+		return keyword(token), nil
+	}
+	if i, err := strconv.ParseInt(token, 10, 64); err == nil {
+		// "12 0 R" is a reference to object 12
+		save := p.pos
+		p.skipSpace()
+		if gen, err := strconv.Atoi(p.readRegular()); err == nil {
+			p.skipSpace()
+			if p.readRegular() == "R" {
+				return ref{num: int(i), gen: gen}, nil
+			}
+		}
+		p.pos = save
+		return i, nil
+	}
+	f, _ := strconv.ParseFloat(token, 64)
+	return f, nil
+}
+
+// tryObject reads the next object, treating errors as null
+func tryObject(p *parser) object {
+	obj, _ := p.parseObject()
+	return obj
+}
+
+// nextIs consumes the next token when it is the given keyword
+func (p *parser) nextIs(word string) bool {
+	save := p.pos
+	p.skipSpace()
+	if p.readRegular() == word {
+		return true
+	}
+	p.pos = save
+	return false
+}
+
+// readStream reads the data following a stream keyword
+// The declared length is used when it checks out, otherwise the data runs to "endstream".
+func (p *parser) readStream(d dict) *stream {
+	if p.pos < len(p.data) && p.data[p.pos] == '\r' {
+		p.pos++
+	}
+	if p.pos < len(p.data) && p.data[p.pos] == '\n' {
+		p.pos++
+	}
+	start := p.pos
+
+	if length, ok := d["Length"].(int64); ok && length >= 0 && start+int(length) <= len(p.data) {
+		end := start + int(length)
+		rest := bytes.TrimLeft(p.data[end:], " \t\r\n\f\x00")
+		if bytes.HasPrefix(rest, []byte("endstream")) {
+			p.pos = len(p.data) - len(rest) + len("endstream")
+			return &stream{dict: d, raw: p.data[start:end]}
+		}
+	}
+
+	end := bytes.Index(p.data[start:], []byte("endstream"))
+	if end < 0 {
+		p.pos = len(p.data)
+		return &stream{dict: d, raw: p.data[start:]}
+	}
+	p.pos = start + end + len("endstream")
+	raw := bytes.TrimSuffix(p.data[start:start+end], []byte("\n"))
+	return &stream{dict: d, raw: bytes.TrimSuffix(raw, []byte("\r"))}
+}
+
+// skipInlineImage skips the binary data of an inline image, which follows the ID operator
+// and ends at an EI operator
+func (p *parser) skipInlineImage() {
+	for i := p.pos; i+2 <= len(p.data); i++ {
+		if p.data[i] == 'E' && p.data[i+1] == 'I' && i > 0 && isSpace(p.data[i-1]) &&
+			(i+2 == len(p.data) || isSpace(p.data[i+2]) || isDelimiter(p.data[i+2])) {
+			p.pos = i + 2
+			return
+		}
+	}
+	p.pos = len(p.data)
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		switch {
+		case isSpace(c):
+			p.pos++
+		case c == '%':
+			for p.pos < len(p.data) && p.data[p.pos] != '\n' && p.data[p.pos] != '\r' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// readRegular reads a run of characters that are neither whitespace nor delimiters
+func (p *parser) readRegular() string {
+	start := p.pos
+	for p.pos < len(p.data) && !isSpace(p.data[p.pos]) && !isDelimiter(p.data[p.pos]) {
+		p.pos++
+	}
+	return string(p.data[start:p.pos])
+}
+
+// readName reads a name such as /Type, decoding #xx escapes
+func (p *parser) readName() name {
+	p.pos++
+	raw := p.readRegular()
+	var b []byte
+	for i := 0; i < len(raw); i++ {
+		if raw[i] == '#' && i+2 < len(raw) && isHexDigit(raw[i+1]) && isHexDigit(raw[i+2]) {
+			decoded, _ := hex.DecodeString(raw[i+1 : i+3])
+			b = append(b, decoded...)
+			i += 2
+			continue
+		}
+		b = append(b, raw[i])
+	}
+	return name(b)
+}
+
+// readLiteral reads a (string) with its escapes and balanced parentheses
+func (p *parser) readLiteral() string {
+	p.pos++
+	depth := 1
+	var b []byte
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		p.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return string(b)
+			}
+		case '\\':
+			if p.pos >= len(p.data) {
+				return string(b)
+			}
+			e := p.data[p.pos]
+			p.pos++
+			switch e {
+			case 'n':
+				b = append(b, '\n')
+			case 'r':
+				b = append(b, '\r')
+			case 't':
+				b = append(b, '\t')
+			case 'b':
+				b = append(b, '\b')
+			case 'f':
+				b = append(b, '\f')
+			case '\r':
+				// A backslash at the end of a line continues the string on the next one
+				if p.pos < len(p.data) && p.data[p.pos] == '\n' {
+					p.pos++
+				}
+			case '\n':
+			case '0', '1', '2', '3', '4', '5', '6', '7':
+				code := int(e - '0')
+				for n := 1; n < 3 && p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '7'; n++ {
+					code = code*8 + int(p.data[p.pos]-'0')
+					p.pos++
+				}
+				b = append(b, byte(code))
+			default:
+				b = append(b, e)
+			}
+			continue
+		}
+		b = append(b, c)
+	}
+	return string(b)
+}
+
+// readHex reads a <hex string>
+func (p *parser) readHex() string {
+	p.pos++
+	end := bytes.IndexByte(p.data[p.pos:], '>')
+	if end < 0 {
+		end = len(p.data) - p.pos
+	}
+	decoded := decodeHex(p.data[p.pos : p.pos+end])
+	p.pos = min(p.pos+end+1, len(p.data))
+	return string(decoded)
+}
+
+// readDict reads the entries of a << dictionary >>
+func (p *parser) readDict() dict {
+	d := dict{}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.data) {
+			return d
+		}
+		if p.data[p.pos] == '>' && p.pos+1 < len(p.data) && p.data[p.pos+1] == '>' {
+			p.pos += 2
+			return d
+		}
+		key, err := p.parseObject()
+		if err != nil {
+			return d
+		}
+		k, ok := key.(name)
+		if !ok {
+			continue
+		}
+		value, err := p.parseObject()
+		if err != nil {
+			return d
+		}
+		d[k] = value
+	}
+}
+
+// readArray reads the elements of an [array]
+func (p *parser) readArray() array {
+	var a array
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.data) {
+			return a
+		}
+		if p.data[p.pos] == ']' {
+			p.pos++
+			return a
+		}
+		obj, err := p.parseObject()
+		if err != nil {
+			return a
+		}
+		a = append(a, obj)
+	}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == 0
+}
+
+func isDelimiter(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+// isNumber reports whether a token is an integer or real number such as -12 or .5
+func isNumber(token string) bool {
+	digits := 0
+	for i := 0; i < len(token); i++ {
+		c := token[i]
+		switch {
+		case c >= '0' && c <= '9':
+			digits++
+		case (c == '+' || c == '-') && i == 0:
+		case c == '.':
+		default:
+			return false
+		}
+	}
+	return digits > 0
+}
+
+// number converts a numeric operand to float64
+func number(obj object) float64 {
+	switch v := obj.(type) {
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	}
+	return 0
+}
@@ -0,0 +1,332 @@
+// Package pdf extracts the text of PDF documents page by page.
+// Objects are found by scanning the file rather than trusting its xref table, so damaged and
+// incrementally updated files still open. Only text is read; images and layout are ignored.
+package pdf
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"encoding/ascii85"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"backend/internal/config"
+)
+
+// ErrEncrypted is returned for password-protected or otherwise encrypted documents
+var ErrEncrypted = errors.New("encrypted PDFs are not supported")
+
+// PDF objects are represented with these types, plus bool, int64, float64, string and nil
+type (
+	name    string
+	keyword string
+	dict    map[name]object
+	array   []object
+	ref     struct{ num, gen int }
+	stream  struct {
+		dict dict
+		raw  []byte
+	}
+	object interface{}
+)
+
+// document holds the objects of a PDF by object number
+type document struct {
+	objects  map[int]object
+	trailers []dict
+	decoded  int // Bytes decompressed so far, bounded by MAX_PDF_DECODED_BYTES
+}
+
+var objectHeader = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+
+// ExtractPages returns the text of each page of a PDF, in page order
+// Pages without extractable text, such as scans, come back empty.
+func ExtractPages(data []byte) ([]string, error) {
+	if !bytes.Contains(data[:min(len(data), 1024)], []byte("%PDF-")) {
+		return nil, fmt.Errorf("not a PDF file")
+	}
+	doc := load(data)
+	for _, trailer := range doc.trailers {
+		if _, ok := trailer["Encrypt"]; ok {
+			return nil, ErrEncrypted
+		}
+	}
+
+	pages := doc.pages()
+	if len(pages) == 0 {
+		return nil, fmt.Errorf("no pages found")
+	}
+	texts := make([]string, len(pages))
+	for i, page := range pages {
+		texts[i] = doc.pageText(page)
+	}
+	return texts, nil
+}
+
+// load reads every object in the file, including those packed into object streams
+func load(data []byte) *document {
+	doc := &document{objects: map[int]object{}}
+
+	end := 0
+	for _, match := range objectHeader.FindAllSubmatchIndex(data, -1) {
+		if match[0] < end {
+			continue // Inside the data of the previous stream
+		}
+		num, _ := strconv.Atoi(string(data[match[2]:match[3]]))
+		p := &parser{data: data, pos: match[1]}
+		obj, err := p.parseObject()
+		if err != nil {
+			continue
+		}
+		if d, ok := obj.(dict); ok && p.nextIs("stream") {
+			obj = p.readStream(d)
+			end = p.pos
+		}
+		// Later definitions win, as incremental updates append changed objects
+		doc.objects[num] = obj
+	}
+
+	for pos := 0; ; {
+		i := bytes.Index(data[pos:], []byte("trailer"))
+		if i < 0 {
+			break
+		}
+		pos += i + len("trailer")
+		p := &parser{data: data, pos: pos}
+		if d, ok := tryObject(p).(dict); ok {
+			doc.trailers = append(doc.trailers, d)
+		}
+	}
+
+	var objectStreams []int
+	for num, obj := range doc.objects {
+		if s, ok := obj.(*stream); ok {
+			switch s.dict["Type"] {
+			case name("ObjStm"):
+				objectStreams = append(objectStreams, num)
+			case name("XRef"):
+				// Cross-reference streams replace the trailer in compressed files
+				doc.trailers = append(doc.trailers, s.dict)
+			}
+		}
+	}
+	sort.Ints(objectStreams)
+	for _, num := range objectStreams {
+		doc.expandObjectStream(doc.objects[num].(*stream))
+	}
+	return doc
+}
+
+// expandObjectStream adds the objects packed into an object stream
+// Objects already defined directly in the file take precedence.
+func (d *document) expandObjectStream(s *stream) {
+	data, err := d.decode(s)
+	if err != nil {
+		return
+	}
+	count, _ := d.resolve(s.dict["N"]).(int64)
+	first, _ := d.resolve(s.dict["First"]).(int64)
+
+	header := &parser{data: data}
+	for i := int64(0); i < count; i++ {
+		num, ok1 := tryObject(header).(int64)
+		offset, ok2 := tryObject(header).(int64)
+		if !ok1 || !ok2 || first+offset >= int64(len(data)) {
+			return
+		}
+		if _, exists := d.objects[int(num)]; exists {
+			continue
+		}
+		p := &parser{data: data, pos: int(first + offset)}
+		if obj, err := p.parseObject(); err == nil {
+			d.objects[int(num)] = obj
+		}
+	}
+}
+
+// resolve follows indirect references to the object they point at
+func (d *document) resolve(obj object) object {
+	for i := 0; i < 32; i++ {
+		r, ok := obj.(ref)
+		if !ok {
+			return obj
+		}
+		obj = d.objects[r.num]
+	}
+	return nil
+}
+
+// dictAt resolves a dictionary entry that should itself be a dictionary
+func (d *document) dictAt(from dict, key name) dict {
+	if from == nil {
+		return nil
+	}
+	switch v := d.resolve(from[key]).(type) {
+	case dict:
+		return v
+	case *stream:
+		return v.dict
+	}
+	return nil
+}
+
+// pages returns the page dictionaries in order, each with its inherited resources filled in
+func (d *document) pages() []dict {
+	var root dict
+	for i := len(d.trailers) - 1; i >= 0 && root == nil; i-- {
+		root, _ = d.resolve(d.trailers[i]["Root"]).(dict)
+	}
+	if root == nil {
+		for _, num := range d.objectNumbers() {
+			if catalog, ok := d.objects[num].(dict); ok && catalog["Type"] == name("Catalog") {
+				root = catalog
+				break
+			}
+		}
+	}
+
+	var pages []dict
+	visited := map[int]bool{}
+	var walk func(node object, resources object, depth int)
+	walk = func(node object, resources object, depth int) {
+		if r, ok := node.(ref); ok {
+			if visited[r.num] {
+				return
+			}
+			visited[r.num] = true
+		}
+		n, ok := d.resolve(node).(dict)
+		if !ok || depth > 64 {
+			return
+		}
+		if res, ok := n["Resources"]; ok {
+			resources = res
+		}
+		kids, isTree := d.resolve(n["Kids"]).(array)
+		if !isTree {
+			page := dict{}
+			for k, v := range n {
+				page[k] = v
+			}
+			page["Resources"] = resources
+			pages = append(pages, page)
+			return
+		}
+		for _, kid := range kids {
+			walk(kid, resources, depth+1)
+		}
+	}
+	if root != nil {
+		walk(root["Pages"], nil, 0)
+	}
+
+	// A broken page tree falls back to every page object in object order
+	if len(pages) == 0 {
+		for _, num := range d.objectNumbers() {
+			if page, ok := d.objects[num].(dict); ok && page["Type"] == name("Page") {
+				pages = append(pages, page)
+			}
+		}
+	}
+	return pages
+}
+
+// objectNumbers lists the object numbers in ascending order
+func (d *document) objectNumbers() []int {
+	nums := make([]int, 0, len(d.objects))
+	for num := range d.objects {
+		nums = append(nums, num)
+	}
+	sort.Ints(nums)
+	return nums
+}
+
+// decode returns the decompressed data of a stream
+func (d *document) decode(s *stream) ([]byte, error) {
+	var filters []object
+	switch f := d.resolve(s.dict["Filter"]).(type) {
+	case name:
+		filters = []object{f}
+	case array:
+		filters = f
+	}
+
+	data := s.raw
+	for _, filter := range filters {
+		var err error
+		switch d.resolve(filter) {
+		case name("FlateDecode"), name("Fl"):
+			data, err = d.inflate(data)
+		case name("ASCIIHexDecode"), name("AHx"):
+			data = decodeHex(data)
+		case name("ASCII85Decode"), name("A85"):
+			data, err = decodeASCII85(data)
+		default:
+			return nil, fmt.Errorf("unsupported filter %v", filter)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// inflate decompresses Flate data, keeping what could be read from truncated streams
+func (d *document) inflate(data []byte) ([]byte, error) {
+	var r io.Reader
+	if zr, err := zlib.NewReader(bytes.NewReader(data)); err == nil {
+		r = zr
+	} else {
+		r = flate.NewReader(bytes.NewReader(data)) // Some writers omit the zlib header
+	}
+
+	limit := min(config.MAX_PDF_STREAM_BYTES, config.MAX_PDF_DECODED_BYTES-d.decoded)
+	if limit <= 0 {
+		return nil, fmt.Errorf("document decompresses to more than %d bytes", config.MAX_PDF_DECODED_BYTES)
+	}
+	out, err := io.ReadAll(io.LimitReader(r, int64(limit)))
+	d.decoded += len(out)
+	if err != nil && len(out) == 0 {
+		return nil, err
+	}
+	return out, nil
+}
+
+// decodeHex decodes ASCIIHexDecode data, which ends at '>' and may contain whitespace
+func decodeHex(data []byte) []byte {
+	digits := make([]byte, 0, len(data))
+	for _, c := range data {
+		if c == '>' {
+			break
+		}
+		if isHexDigit(c) {
+			digits = append(digits, c)
+		}
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+	out := make([]byte, len(digits)/2)
+	hex.Decode(out, digits)
+	return out
+}
+
+// decodeASCII85 decodes ASCII85Decode data, which ends at "~>"
+func decodeASCII85(data []byte) ([]byte, error) {
+	data = bytes.TrimPrefix(bytes.TrimSpace(data), []byte("<~"))
+	if i := bytes.Index(data, []byte("~>")); i >= 0 {
+		data = data[:i]
+	}
+	out := make([]byte, len(data))
+	n, _, err := ascii85.Decode(out, data, true)
+	if err != nil {
+		return nil, err
+	}
+	return out[:n], nil
+}
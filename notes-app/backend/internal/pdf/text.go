@@ -0,0 +1,437 @@
+package pdf
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// maxFormDepth bounds how deeply form XObjects may draw other forms
+const maxFormDepth = 8
+
+// pageText returns the text drawn by a page's content streams, one line per text line
+func (d *document) pageText(page dict) string {
+	var content []byte
+	switch c := d.resolve(page["Contents"]).(type) {
+	case *stream:
+		content, _ = d.decode(c)
+	case array:
+		for _, part := range c {
+			if s, ok := d.resolve(part).(*stream); ok {
+				if data, err := d.decode(s); err == nil {
+					content = append(append(content, data...), '\n')
+				}
+			}
+		}
+	}
+
+	resources, _ := d.resolve(page["Resources"]).(dict)
+	x := &extractor{doc: d, fonts: map[int]*font{}}
+	x.run(content, resources, 0)
+	return cleanText(x.out.String())
+}
+
+// extractor interprets content stream operators, writing the text they show
+// Text placed on a new baseline starts a new line; a horizontal jump within a line, or a
+// large gap in a TJ array, becomes a space.
+type extractor struct {
+	doc   *document
+	fonts map[int]*font // By object number
+	out   strings.Builder
+
+	font         *font
+	y            float64 // Baseline of the text line matrix
+	lastY        float64 // Baseline of the last text shown
+	shown        bool
+	newLine      bool
+	pendingSpace bool
+}
+
+// run interprets a content stream drawn with the given resources
+func (x *extractor) run(content []byte, resources dict, depth int) {
+	fonts := x.doc.dictAt(resources, "Font")
+	xobjects := x.doc.dictAt(resources, "XObject")
+
+	p := &parser{data: content}
+	var operands []object
+	for {
+		obj, err := p.parseObject()
+		if err != nil {
+			return
+		}
+		op, ok := obj.(keyword)
+		if !ok {
+			operands = append(operands, obj)
+			continue
+		}
+
+		switch op {
+		case "BT":
+			x.y = 0
+		case "Tf":
+			if len(operands) >= 2 {
+				if fontName, ok := operands[0].(name); ok && fonts != nil {
+					x.font = x.loadFont(fonts[fontName])
+				}
+			}
+		case "Td", "TD":
+			if len(operands) >= 2 {
+				x.y += number(operands[1])
+				if number(operands[1]) == 0 && number(operands[0]) > 0 {
+					x.pendingSpace = true
+				}
+			}
+		case "Tm":
+			if len(operands) >= 6 {
+				x.y = number(operands[5])
+				x.pendingSpace = true
+			}
+		case "T*":
+			x.newLine = true
+		case "Tj":
+			if len(operands) >= 1 {
+				x.show(operands[0])
+			}
+		case "'":
+			x.newLine = true
+			if len(operands) >= 1 {
+				x.show(operands[len(operands)-1])
+			}
+		case "\"":
+			x.newLine = true
+			if len(operands) >= 3 {
+				x.show(operands[2])
+			}
+		case "TJ":
+			if len(operands) >= 1 {
+				items, _ := operands[0].(array)
+				for _, item := range items {
+					switch item.(type) {
+					case int64, float64:
+						// Adjustments are in thousandths of an em; a wide negative one separates words
+						if number(item) < -200 {
+							x.pendingSpace = true
+						}
+					default:
+						x.show(item)
+					}
+				}
+			}
+		case "Do":
+			if len(operands) >= 1 && depth < maxFormDepth {
+				if xobjectName, ok := operands[0].(name); ok && xobjects != nil {
+					x.drawForm(xobjects[xobjectName], resources, depth)
+				}
+			}
+		case "ID":
+			p.skipInlineImage()
+		}
+		operands = operands[:0]
+	}
+}
+
+// drawForm runs the content of a form XObject; images are skipped
+func (x *extractor) drawForm(obj object, resources dict, depth int) {
+	form, ok := x.doc.resolve(obj).(*stream)
+	if !ok || form.dict["Subtype"] != name("Form") {
+		return
+	}
+	data, err := x.doc.decode(form)
+	if err != nil {
+		return
+	}
+	if own, ok := x.doc.resolve(form.dict["Resources"]).(dict); ok {
+		resources = own
+	}
+	saved := x.font
+	x.run(data, resources, depth+1)
+	x.font = saved
+}
+
+// show writes a shown string, starting a new line or adding a space first when needed
+func (x *extractor) show(obj object) {
+	s, ok := obj.(string)
+	if !ok {
+		return
+	}
+	f := x.font
+	if f == nil {
+		f = defaultFont
+	}
+	text := f.decode(s)
+	if text == "" {
+		return
+	}
+
+	if x.shown {
+		switch {
+		case x.newLine || math.Abs(x.y-x.lastY) > 1:
+			x.out.WriteByte('\n')
+		case x.pendingSpace && !strings.HasSuffix(x.out.String(), " ") && !strings.HasPrefix(text, " "):
+			x.out.WriteByte(' ')
+		}
+	}
+	x.out.WriteString(text)
+	x.shown = true
+	x.lastY = x.y
+	x.newLine = false
+	x.pendingSpace = false
+}
+
+// loadFont returns the decoder for a font, caching fonts shared by reference
+func (x *extractor) loadFont(obj object) *font {
+	r, shared := obj.(ref)
+	if f, ok := x.fonts[r.num]; shared && ok {
+		return f
+	}
+	f := x.doc.newFont(obj)
+	if shared {
+		x.fonts[r.num] = f
+	}
+	return f
+}
+
+// font maps the character codes of shown strings to Unicode text
+type font struct {
+	codeLen   int               // Bytes per character code
+	toUnicode map[uint32]string // From the font's ToUnicode CMap, when it has one
+	encoding  [256]rune         // Simple fonts: the code to rune table of the font's encoding
+	composite bool              // Type0 fonts can only be read through their ToUnicode CMap
+}
+
+var defaultFont = &font{codeLen: 1, encoding: encodingTable(charmap.Windows1252)}
+
+// newFont builds a decoder from a font dictionary
+func (d *document) newFont(obj object) *font {
+	fontDict, ok := d.resolve(obj).(dict)
+	if !ok {
+		return defaultFont
+	}
+	f := &font{codeLen: 1, encoding: defaultFont.encoding}
+	if fontDict["Subtype"] == name("Type0") {
+		f.codeLen = 2
+		f.composite = true
+	}
+
+	if cmap, ok := d.resolve(fontDict["ToUnicode"]).(*stream); ok {
+		if data, err := d.decode(cmap); err == nil {
+			var codeLen int
+			f.toUnicode, codeLen = parseCMap(data)
+			if codeLen > 0 {
+				f.codeLen = codeLen
+			}
+		}
+	}
+
+	switch enc := d.resolve(fontDict["Encoding"]).(type) {
+	case name:
+		f.encoding = baseEncoding(enc)
+	case dict:
+		if base, ok := d.resolve(enc["BaseEncoding"]).(name); ok {
+			f.encoding = baseEncoding(base)
+		}
+		differences, _ := d.resolve(enc["Differences"]).(array)
+		code := 0
+		for _, item := range differences {
+			switch v := d.resolve(item).(type) {
+			case int64:
+				code = int(v)
+			case name:
+				if code >= 0 && code < 256 {
+					if r := glyphRune(string(v)); r != 0 {
+						f.encoding[code] = r
+					}
+				}
+				code++
+			}
+		}
+	}
+	return f
+}
+
+// decode converts a shown string to text
+func (f *font) decode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i += f.codeLen {
+		end := min(i+f.codeLen, len(s))
+		var code uint32
+		for j := i; j < end; j++ {
+			code = code<<8 | uint32(s[j])
+		}
+		if text, ok := f.toUnicode[code]; ok {
+			b.WriteString(text)
+			continue
+		}
+		if f.composite || f.codeLen != 1 {
+			continue // CIDs say nothing about the character without a CMap
+		}
+		if r := f.encoding[s[i]]; r >= ' ' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// parseCMap reads the code to text mappings of a ToUnicode CMap, along with the code length in bytes
+func parseCMap(data []byte) (map[uint32]string, int) {
+	mappings := map[uint32]string{}
+	codeLen := 0
+	toCode := func(s string) uint32 {
+		var code uint32
+		for i := 0; i < len(s) && i < 4; i++ {
+			code = code<<8 | uint32(s[i])
+		}
+		return code
+	}
+
+	p := &parser{data: data}
+	var operands []object
+	for {
+		obj, err := p.parseObject()
+		if err != nil {
+			break
+		}
+		op, ok := obj.(keyword)
+		if !ok {
+			operands = append(operands, obj)
+			continue
+		}
+
+		switch op {
+		case "endcodespacerange":
+			if len(operands) > 0 {
+				if low, ok := operands[0].(string); ok && codeLen == 0 {
+					codeLen = len(low)
+				}
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, ok1 := operands[i].(string)
+				dst, ok2 := operands[i+1].(string)
+				if ok1 && ok2 {
+					mappings[toCode(src)] = utf16Text(dst)
+					if codeLen == 0 {
+						codeLen = len(src)
+					}
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				lowSrc, ok1 := operands[i].(string)
+				highSrc, ok2 := operands[i+1].(string)
+				if !ok1 || !ok2 {
+					continue
+				}
+				low, high := toCode(lowSrc), toCode(highSrc)
+				if high < low || high-low > 0xFFFF {
+					continue
+				}
+				switch dst := operands[i+2].(type) {
+				case string:
+					// Each code maps to the destination with its last character incremented
+					units := utf16Units(dst)
+					if len(units) == 0 {
+						continue
+					}
+					for code := low; code <= high; code++ {
+						next := append([]uint16(nil), units...)
+						next[len(next)-1] += uint16(code - low)
+						mappings[code] = string(utf16.Decode(next))
+					}
+				case array:
+					for j, item := range dst {
+						if s, ok := item.(string); ok && low+uint32(j) <= high {
+							mappings[low+uint32(j)] = utf16Text(s)
+						}
+					}
+				}
+				if codeLen == 0 {
+					codeLen = len(lowSrc)
+				}
+			}
+		}
+		operands = operands[:0]
+	}
+	return mappings, codeLen
+}
+
+// utf16Units splits UTF-16BE bytes into code units
+func utf16Units(s string) []uint16 {
+	units := make([]uint16, 0, len(s)/2)
+	for i := 0; i+1 < len(s); i += 2 {
+		units = append(units, uint16(s[i])<<8|uint16(s[i+1]))
+	}
+	return units
+}
+
+func utf16Text(s string) string {
+	return string(utf16.Decode(utf16Units(s)))
+}
+
+// baseEncoding returns the table of a named encoding; StandardEncoding is read as WinAnsi,
+// which agrees with it on letters, digits and punctuation
+func baseEncoding(enc name) [256]rune {
+	if enc == "MacRomanEncoding" {
+		return encodingTable(charmap.Macintosh)
+	}
+	return defaultFont.encoding
+}
+
+func encodingTable(cm *charmap.Charmap) [256]rune {
+	var table [256]rune
+	for i := range table {
+		table[i] = cm.DecodeByte(byte(i))
+	}
+	return table
+}
+
+// glyphNames maps the Adobe glyph names that fonts commonly use in their encoding differences
+var glyphNames = map[string]rune{
+	"space": ' ', "exclam": '!', "quotedbl": '"', "numbersign": '#', "dollar": '$', "percent": '%',
+	"ampersand": '&', "quotesingle": '\'', "parenleft": '(', "parenright": ')', "asterisk": '*',
+	"plus": '+', "comma": ',', "hyphen": '-', "period": '.', "slash": '/', "zero": '0', "one": '1',
+	"two": '2', "three": '3', "four": '4', "five": '5', "six": '6', "seven": '7', "eight": '8',
+	"nine": '9', "colon": ':', "semicolon": ';', "less": '<', "equal": '=', "greater": '>',
+	"question": '?', "at": '@', "bracketleft": '[', "backslash": '\\', "bracketright": ']',
+	"asciicircum": '^', "underscore": '_', "grave": '`', "braceleft": '{', "bar": '|',
+	"braceright": '}', "asciitilde": '~', "quoteleft": '‘', "quoteright": '’', "quotedblleft": '“',
+	"quotedblright": '”', "endash": '–', "emdash": '—', "bullet": '•', "ellipsis": '…',
+	"fi": 'ﬁ', "fl": 'ﬂ', "ff": 'ﬀ', "ffi": 'ﬃ', "ffl": 'ﬄ', "minus": '−', "degree": '°',
+	"copyright": '©', "registered": '®', "trademark": '™', "section": '§', "paragraph": '¶',
+	"dagger": '†', "daggerdbl": '‡', "Euro": '€', "sterling": '£', "yen": '¥', "cent": '¢',
+}
+
+// glyphRune returns the character for a glyph name, or 0 when the name is unknown
+func glyphRune(glyph string) rune {
+	if r, ok := glyphNames[glyph]; ok {
+		return r
+	}
+	if len(glyph) == 1 && (glyph[0] >= 'a' && glyph[0] <= 'z' || glyph[0] >= 'A' && glyph[0] <= 'Z') {
+		return rune(glyph[0])
+	}
+	// uniXXXX and uXXXX[XX] name characters by code point
+	hexCode := ""
+	if rest, ok := strings.CutPrefix(glyph, "uni"); ok && len(rest) >= 4 {
+		hexCode = rest[:4]
+	} else if rest, ok := strings.CutPrefix(glyph, "u"); ok && len(rest) >= 4 && len(rest) <= 6 {
+		hexCode = rest
+	}
+	if code, err := strconv.ParseUint(hexCode, 16, 32); err == nil {
+		return rune(code)
+	}
+	return 0
+}
+
+// cleanText trims each line, collapses runs of spaces and drops blank lines
+func cleanText(text string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,270 @@
+// Package podcast reads podcast RSS feeds and the transcripts episodes publish with them.
+package podcast
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/utils"
+)
+
+// ErrNoTranscript is returned for episodes that don't publish a transcript in a readable format
+var ErrNoTranscript = errors.New("no transcript published")
+
+// Feed is a parsed podcast feed
+type Feed struct {
+	Title    string
+	Episodes []Episode // Newest first
+}
+
+// Episode is an item of a podcast feed
+type Episode struct {
+	GUID        string
+	Title       string
+	Link        string
+	Description string
+	PublishedAt time.Time
+	Duration    string
+	AudioURL    string
+	AudioType   string
+	Transcripts []Transcript
+}
+
+// Transcript is a <podcast:transcript> link of an episode
+type Transcript struct {
+	URL  string
+	Type string
+}
+
+// URL identifies the episode in metadata.url: its audio file, or its page when it has none
+// Episode links often all point at the show's homepage, so they aren't preferred.
+func (e *Episode) URL() string {
+	if e.AudioURL != "" {
+		return e.AudioURL
+	}
+	return e.Link
+}
+
+// Client downloads feeds, transcripts and episode audio
+type Client struct {
+	client *http.Client
+}
+
+// NewClient creates a new Client that only connects to addresses check allows; pass
+// utils.PublicAddress, since feeds and the links in them are chosen by others
+func NewClient(check utils.AddressCheck) *Client {
+	return &Client{client: utils.PublicHTTPClient(config.PODCAST_TIMEOUT_SECONDS*time.Second, check)}
+}
+
+// rssFeed is the subset of RSS 2.0 with the iTunes and Podcasting 2.0 extensions read from feeds
+// Extension elements are matched by local name, so feeds declaring other prefixes still parse
+type rssFeed struct {
+	Channel struct {
+		Title string `xml:"title"`
+		Items []struct {
+			GUID        string `xml:"guid"`
+			Title       string `xml:"title"`
+			Link        string `xml:"link"`
+			Description string `xml:"description"`
+			PubDate     string `xml:"pubDate"`
+			Duration    string `xml:"duration"`
+			Enclosure   struct {
+				URL  string `xml:"url,attr"`
+				Type string `xml:"type,attr"`
+			} `xml:"enclosure"`
+			Transcripts []struct {
+				URL  string `xml:"url,attr"`
+				Type string `xml:"type,attr"`
+			} `xml:"transcript"`
+		} `xml:"item"`
+	} `xml:"channel"`
+}
+
+// FetchFeed downloads and parses a feed
+func (c *Client) FetchFeed(ctx context.Context, feedURL string) (*Feed, error) {
+	body, err := c.fetch(ctx, feedURL, config.MAX_PODCAST_FEED_BYTES)
+	if err != nil {
+		return nil, err
+	}
+
+	var rss rssFeed
+	if err := xml.Unmarshal(body, &rss); err != nil {
+		return nil, fmt.Errorf("invalid feed: %w", err)
+	}
+	if rss.Channel.Title == "" && len(rss.Channel.Items) == 0 {
+		return nil, fmt.Errorf("invalid feed: no RSS channel found")
+	}
+
+	feed := &Feed{Title: strings.TrimSpace(rss.Channel.Title), Episodes: []Episode{}}
+	for _, item := range rss.Channel.Items {
+		episode := Episode{
+			GUID:        strings.TrimSpace(item.GUID),
+			Title:       strings.TrimSpace(item.Title),
+			Link:        strings.TrimSpace(item.Link),
+			Description: strings.TrimSpace(item.Description),
+			PublishedAt: parsePubDate(item.PubDate),
+			Duration:    strings.TrimSpace(item.Duration),
+			AudioURL:    strings.TrimSpace(item.Enclosure.URL),
+			AudioType:   item.Enclosure.Type,
+		}
+		for _, transcript := range item.Transcripts {
+			if transcript.URL != "" {
+				episode.Transcripts = append(episode.Transcripts, Transcript{URL: transcript.URL, Type: transcript.Type})
+			}
+		}
+		if episode.URL() == "" {
+			continue
+		}
+		feed.Episodes = append(feed.Episodes, episode)
+	}
+	sort.SliceStable(feed.Episodes, func(i, j int) bool {
+		return feed.Episodes[i].PublishedAt.After(feed.Episodes[j].PublishedAt)
+	})
+	return feed, nil
+}
+
+// transcriptPreference orders transcript formats from least to most cleanup needed
+var transcriptPreference = []string{"text/plain", "application/json", "text/vtt", "application/x-subrip", "application/srt", "text/html"}
+
+// FetchTranscript downloads an episode's published transcript as plain text
+func (c *Client) FetchTranscript(ctx context.Context, episode *Episode) (string, error) {
+	var chosen *Transcript
+	for _, mimeType := range transcriptPreference {
+		for i := range episode.Transcripts {
+			if strings.EqualFold(episode.Transcripts[i].Type, mimeType) {
+				chosen = &episode.Transcripts[i]
+				break
+			}
+		}
+		if chosen != nil {
+			break
+		}
+	}
+	if chosen == nil {
+		return "", ErrNoTranscript
+	}
+
+	body, err := c.fetch(ctx, chosen.URL, config.MAX_PODCAST_TRANSCRIPT_BYTES)
+	if err != nil {
+		return "", fmt.Errorf("failed to download transcript: %w", err)
+	}
+	text, err := TranscriptText(body, chosen.Type)
+	if err != nil {
+		return "", err
+	}
+	if text == "" {
+		return "", ErrNoTranscript
+	}
+	return text, nil
+}
+
+// FetchAudio downloads an episode's audio file
+// Files above MAX_PODCAST_AUDIO_BYTES are refused, as the transcription API would reject them
+func (c *Client) FetchAudio(ctx context.Context, episode *Episode) ([]byte, error) {
+	if episode.AudioURL == "" {
+		return nil, fmt.Errorf("episode has no audio")
+	}
+	audio, err := c.fetch(ctx, episode.AudioURL, config.MAX_PODCAST_AUDIO_BYTES+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download audio: %w", err)
+	}
+	if len(audio) > config.MAX_PODCAST_AUDIO_BYTES {
+		return nil, fmt.Errorf("audio is larger than %d MB", config.MAX_PODCAST_AUDIO_BYTES>>20)
+	}
+	return audio, nil
+}
+
+var (
+	cueTimingPattern = regexp.MustCompile(`-->`)
+	cueNumberPattern = regexp.MustCompile(`^\d+$`)
+	markupPattern    = regexp.MustCompile(`<[^>]*>`)
+)
+
+// TranscriptText converts a transcript in one of the Podcasting 2.0 formats to plain text
+func TranscriptText(body []byte, mimeType string) (string, error) {
+	switch strings.ToLower(mimeType) {
+	case "application/json":
+		var transcript struct {
+			Segments []struct {
+				Body string `json:"body"`
+			} `json:"segments"`
+		}
+		if err := json.Unmarshal(body, &transcript); err != nil {
+			return "", fmt.Errorf("invalid transcript: %w", err)
+		}
+		parts := make([]string, 0, len(transcript.Segments))
+		for _, segment := range transcript.Segments {
+			parts = append(parts, segment.Body)
+		}
+		return collapseSpace(strings.Join(parts, " ")), nil
+
+	case "text/vtt", "application/x-subrip", "application/srt":
+		var lines []string
+		inNote := false
+		for _, line := range strings.Split(strings.ReplaceAll(string(body), "\r\n", "\n"), "\n") {
+			line = strings.TrimSpace(line)
+			switch {
+			case line == "":
+				inNote = false
+			case inNote, strings.HasPrefix(line, "WEBVTT"), cueNumberPattern.MatchString(line), cueTimingPattern.MatchString(line):
+			case strings.HasPrefix(line, "NOTE"):
+				inNote = true
+			default:
+				lines = append(lines, markupPattern.ReplaceAllString(line, ""))
+			}
+		}
+		return collapseSpace(html.UnescapeString(strings.Join(lines, " "))), nil
+
+	case "text/html":
+		return collapseSpace(html.UnescapeString(markupPattern.ReplaceAllString(string(body), " "))), nil
+	}
+	return collapseSpace(string(body)), nil
+}
+
+// collapseSpace trims text and joins its words with single spaces
+func collapseSpace(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// pubDateLayouts are the RFC 822 variants found in feed pubDates
+var pubDateLayouts = []string{time.RFC1123Z, time.RFC1123, "Mon, 2 Jan 2006 15:04:05 -0700", "Mon, 2 Jan 2006 15:04:05 MST", "2 Jan 2006 15:04:05 -0700", time.RFC3339}
+
+// parsePubDate parses a feed pubDate, returning the zero time when it can't be read
+func parsePubDate(value string) time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range pubDateLayouts {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed
+		}
+	}
+	return time.Time{}
+}
+
+// fetch GETs a URL, returning at most limit bytes of a 2xx response body
+func (c *Client) fetch(ctx context.Context, rawURL string, limit int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%s returned status %d", req.URL.Host, resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, limit))
+}
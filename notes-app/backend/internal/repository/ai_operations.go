@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AIOperationsRepository provides database operations for the AI history of notes
+type AIOperationsRepository struct {
+	collection *mongo.Collection
+}
+
+// NewAIOperationsRepository creates a new AIOperationsRepository
+func NewAIOperationsRepository(db *mongo.Database) *AIOperationsRepository {
+	return &AIOperationsRepository{
+		collection: db.Collection("ai_operations"),
+	}
+}
+
+// EnsureIndexes creates the index a note's history is listed by
+func (r *AIOperationsRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "note_id", Value: 1}, {Key: "created_at", Value: -1}},
+	})
+	return err
+}
+
+// Create inserts a new AI operation
+func (r *AIOperationsRepository) Create(ctx context.Context, op *models.AIOperation) (primitive.ObjectID, error) {
+	result, err := r.collection.InsertOne(ctx, op)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	return result.InsertedID.(primitive.ObjectID), nil
+}
+
+// FindByNote retrieves a note's most recent AI operations, newest first
+func (r *AIOperationsRepository) FindByNote(ctx context.Context, noteID primitive.ObjectID, limit int) ([]models.AIOperation, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).SetLimit(int64(limit))
+	cursor, err := r.collection.Find(ctx, bson.M{"note_id": noteID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	ops := []models.AIOperation{}
+	if err = cursor.All(ctx, &ops); err != nil {
+		return nil, err
+	}
+	return ops, nil
+}
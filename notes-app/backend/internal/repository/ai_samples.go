@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AISamplesRepository provides database operations for sampled AI generation calls
+type AISamplesRepository struct {
+	db         *mongo.Database
+	collection *mongo.Collection
+}
+
+// NewAISamplesRepository creates a new AISamplesRepository
+func NewAISamplesRepository(db *mongo.Database) *AISamplesRepository {
+	return &AISamplesRepository{
+		db:         db,
+		collection: db.Collection("ai_samples"),
+	}
+}
+
+// EnsureCollection creates the samples collection capped at maxBytes, so the oldest samples are
+// dropped as new ones arrive, and the index samples are listed by. A collection that already
+// exists keeps its size.
+func (r *AISamplesRepository) EnsureCollection(ctx context.Context, maxBytes int64) error {
+	opts := options.CreateCollection().SetCapped(true).SetSizeInBytes(maxBytes)
+	if err := r.db.CreateCollection(ctx, "ai_samples", opts); err != nil {
+		var cmdErr mongo.CommandError
+		if !errors.As(err, &cmdErr) || cmdErr.Name != "NamespaceExists" {
+			return err
+		}
+	}
+
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "operation", Value: 1}, {Key: "created_at", Value: -1}},
+	})
+	return err
+}
+
+// Create inserts a new sample
+func (r *AISamplesRepository) Create(ctx context.Context, sample *models.AISample) (primitive.ObjectID, error) {
+	result, err := r.collection.InsertOne(ctx, sample)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	return result.InsertedID.(primitive.ObjectID), nil
+}
+
+// FindRecent retrieves the most recent samples matching the filter, newest first
+func (r *AISamplesRepository) FindRecent(ctx context.Context, filter bson.M, limit int) ([]models.AISample, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).SetLimit(int64(limit))
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	samples := []models.AISample{}
+	if err = cursor.All(ctx, &samples); err != nil {
+		return nil, err
+	}
+	return samples, nil
+}
+
+// FindByID retrieves a single sample by its ID
+func (r *AISamplesRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.AISample, error) {
+	var sample models.AISample
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&sample)
+	if err != nil {
+		return nil, err
+	}
+	return &sample, nil
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// AuditRepository provides database operations for the audit log
+type AuditRepository struct {
+	collection *mongo.Collection
+}
+
+// NewAuditRepository creates a new AuditRepository
+func NewAuditRepository(db *mongo.Database) *AuditRepository {
+	return &AuditRepository{
+		collection: db.Collection("audit_log"),
+	}
+}
+
+// Create inserts a new audit entry
+func (r *AuditRepository) Create(ctx context.Context, entry *models.AuditEntry) (primitive.ObjectID, error) {
+	result, err := r.collection.InsertOne(ctx, entry)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	return result.InsertedID.(primitive.ObjectID), nil
+}
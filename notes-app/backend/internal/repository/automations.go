@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AutomationRulesRepository provides database operations for automation rules
+type AutomationRulesRepository struct {
+	collection *mongo.Collection
+}
+
+// NewAutomationRulesRepository creates a new AutomationRulesRepository
+func NewAutomationRulesRepository(db *mongo.Database) *AutomationRulesRepository {
+	return &AutomationRulesRepository{
+		collection: db.Collection("automation_rules"),
+	}
+}
+
+// FindAll retrieves rules matching the filter, oldest first
+func (r *AutomationRulesRepository) FindAll(ctx context.Context, filter bson.M) ([]models.AutomationRule, error) {
+	opts := options.Find().SetSort(bson.M{"_id": 1})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rules []models.AutomationRule
+	if err = cursor.All(ctx, &rules); err != nil {
+		return nil, err
+	}
+
+	if rules == nil {
+		rules = []models.AutomationRule{}
+	}
+
+	return rules, nil
+}
+
+// FindEnabled retrieves all enabled rules, oldest first
+func (r *AutomationRulesRepository) FindEnabled(ctx context.Context) ([]models.AutomationRule, error) {
+	return r.FindAll(ctx, bson.M{"enabled": true})
+}
+
+// FindByID retrieves a single rule by its ID
+func (r *AutomationRulesRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.AutomationRule, error) {
+	var rule models.AutomationRule
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&rule)
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// Create inserts a new rule and returns the inserted ID
+func (r *AutomationRulesRepository) Create(ctx context.Context, rule *models.AutomationRule) (primitive.ObjectID, error) {
+	result, err := r.collection.InsertOne(ctx, rule)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	return result.InsertedID.(primitive.ObjectID), nil
+}
+
+// Replace overwrites an existing rule
+// Returns the number of matched documents
+func (r *AutomationRulesRepository) Replace(ctx context.Context, id primitive.ObjectID, rule *models.AutomationRule) (int64, error) {
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": id}, rule)
+	if err != nil {
+		return 0, err
+	}
+	return result.MatchedCount, nil
+}
+
+// Delete removes a rule by its ID
+// Returns the number of deleted documents
+func (r *AutomationRulesRepository) Delete(ctx context.Context, id primitive.ObjectID) (int64, error) {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// CountCategoryTriggers counts the rules triggered by any of the categories
+func (r *AutomationRulesRepository) CountCategoryTriggers(ctx context.Context, categories []string) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{"trigger": models.AutomationTriggerCategory, "value": bson.M{"$in": categories}})
+}
+
+// SetCategoryTrigger makes the rules triggered by any of the from categories trigger on the to category
+// Returns the number of modified documents
+func (r *AutomationRulesRepository) SetCategoryTrigger(ctx context.Context, from []string, to string) (int64, error) {
+	result, err := r.collection.UpdateMany(ctx,
+		bson.M{"trigger": models.AutomationTriggerCategory, "value": bson.M{"$in": from}},
+		bson.M{"$set": bson.M{"value": to, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
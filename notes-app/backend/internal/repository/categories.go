@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CategoriesRepository provides database operations for note categories
+type CategoriesRepository struct {
+	collection *mongo.Collection
+}
+
+// NewCategoriesRepository creates a new CategoriesRepository
+func NewCategoriesRepository(db *mongo.Database) *CategoriesRepository {
+	return &CategoriesRepository{
+		collection: db.Collection("categories"),
+	}
+}
+
+// FindAll retrieves every category, by name
+func (r *CategoriesRepository) FindAll(ctx context.Context) ([]models.Category, error) {
+	opts := options.Find().SetSort(bson.M{"_id": 1})
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var categories []models.Category
+	if err = cursor.All(ctx, &categories); err != nil {
+		return nil, err
+	}
+
+	if categories == nil {
+		categories = []models.Category{}
+	}
+
+	return categories, nil
+}
+
+// FindByName retrieves a single category by its name
+func (r *CategoriesRepository) FindByName(ctx context.Context, name string) (*models.Category, error) {
+	var category models.Category
+	err := r.collection.FindOne(ctx, bson.M{"_id": name}).Decode(&category)
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// Create inserts a new category; a name that is taken fails with a duplicate key error
+func (r *CategoriesRepository) Create(ctx context.Context, category *models.Category) error {
+	_, err := r.collection.InsertOne(ctx, category)
+	return err
+}
+
+// CreateMany inserts categories, skipping names that are taken
+func (r *CategoriesRepository) CreateMany(ctx context.Context, categories []models.Category) error {
+	docs := make([]interface{}, len(categories))
+	for i := range categories {
+		docs[i] = categories[i]
+	}
+	_, err := r.collection.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		return err
+	}
+	return nil
+}
+
+// SetDescription updates a category's description
+// Returns whether the category exists
+func (r *CategoriesRepository) SetDescription(ctx context.Context, name, description string) (bool, error) {
+	update := bson.M{"$set": bson.M{"description": description}}
+	if description == "" {
+		update = bson.M{"$unset": bson.M{"description": ""}}
+	}
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": name}, update)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// Delete removes a category by name
+// Returns the number of deleted documents
+func (r *CategoriesRepository) Delete(ctx context.Context, name string) (int64, error) {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": name})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ChannelPresetsRepository provides database operations for imported channel presets, keyed by name
+type ChannelPresetsRepository struct {
+	collection *mongo.Collection
+}
+
+// NewChannelPresetsRepository creates a new ChannelPresetsRepository
+func NewChannelPresetsRepository(db *mongo.Database) *ChannelPresetsRepository {
+	return &ChannelPresetsRepository{
+		collection: db.Collection("channel_presets"),
+	}
+}
+
+// FindAll retrieves all imported presets, ordered by name
+func (r *ChannelPresetsRepository) FindAll(ctx context.Context) ([]models.ChannelPreset, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	presets := []models.ChannelPreset{}
+	if err = cursor.All(ctx, &presets); err != nil {
+		return nil, err
+	}
+	return presets, nil
+}
+
+// FindByName retrieves an imported preset, returning nil when there is none with the name
+func (r *ChannelPresetsRepository) FindByName(ctx context.Context, name string) (*models.ChannelPreset, error) {
+	var preset models.ChannelPreset
+	err := r.collection.FindOne(ctx, bson.M{"_id": name}).Decode(&preset)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &preset, nil
+}
+
+// Upsert stores a preset, replacing any earlier one with the same name
+func (r *ChannelPresetsRepository) Upsert(ctx context.Context, preset *models.ChannelPreset) error {
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": preset.Name}, preset, options.Replace().SetUpsert(true))
+	return err
+}
@@ -2,10 +2,13 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"backend/internal/models"
+	"backend/internal/utils"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -22,11 +25,26 @@ func NewChannelSettingsRepository(db *mongo.Database) *ChannelSettingsRepository
 	}
 }
 
-// FindByName retrieves channel settings by channel name
+// FindByChannel retrieves channel settings for a channel on a platform
+// The name matches case/diacritic-insensitively against the channel name and its aliases.
+// Settings saved without a platform (created before channels were namespaced) act as a fallback.
+// An empty platform matches the channel name on any platform. A name without letters or digits
+// has no lookup key and only matches exactly.
 // Returns nil if not found (no error for ErrNoDocuments)
-func (r *ChannelSettingsRepository) FindByName(ctx context.Context, channelName string) (*models.ChannelSettings, error) {
+func (r *ChannelSettingsRepository) FindByChannel(ctx context.Context, platform, channelName string) (*models.ChannelSettings, error) {
+	clauses := []bson.M{{"channel_name": channelName}}
+	if key := utils.NormalizeChannelKey(channelName); key != "" {
+		clauses = append(clauses, bson.M{"channel_key": key}, bson.M{"alias_keys": key})
+	}
+	filter := bson.M{"$or": clauses}
+	if platform != "" {
+		filter["platform"] = bson.M{"$in": []string{platform, ""}}
+	}
+	// Exact platform match sorts ahead of the legacy empty-platform record
+	opts := options.FindOne().SetSort(bson.M{"platform": -1})
+
 	var settings models.ChannelSettings
-	err := r.collection.FindOne(ctx, bson.M{"channel_name": channelName}).Decode(&settings)
+	err := r.collection.FindOne(ctx, filter, opts).Decode(&settings)
 	if err == mongo.ErrNoDocuments {
 		return nil, nil
 	}
@@ -36,6 +54,37 @@ func (r *ChannelSettingsRepository) FindByName(ctx context.Context, channelName
 	return &settings, nil
 }
 
+// FindByChannelKeys retrieves the channel settings whose name or an alias has one of the given
+// lookup keys (see utils.NormalizeChannelKey), on any platform
+func (r *ChannelSettingsRepository) FindByChannelKeys(ctx context.Context, keys []string) ([]models.ChannelSettings, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"$or": []bson.M{
+		{"channel_key": bson.M{"$in": keys}},
+		{"alias_keys": bson.M{"$in": keys}},
+	}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	settings := []models.ChannelSettings{}
+	if err = cursor.All(ctx, &settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// EnsureIndexes creates the unique (platform, channel name) index
+func (r *ChannelSettingsRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "platform", Value: 1},
+			{Key: "channel_name", Value: 1},
+		},
+		Options: options.Index().SetName("platform_channel").SetUnique(true),
+	})
+	return err
+}
+
 // FindAll retrieves all channel settings
 func (r *ChannelSettingsRepository) FindAll(ctx context.Context) ([]models.ChannelSettings, error) {
 	cursor, err := r.collection.Find(ctx, bson.M{})
@@ -56,22 +105,101 @@ func (r *ChannelSettingsRepository) FindAll(ctx context.Context) ([]models.Chann
 	return settings, nil
 }
 
-// Upsert creates or updates channel settings by channel name
+// FindWithChannelURL retrieves the YouTube channel settings that have a channel URL to sync from
+// Settings saved without a platform are included, as channel URLs predate platforms
+func (r *ChannelSettingsRepository) FindWithChannelURL(ctx context.Context) ([]models.ChannelSettings, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{
+		"channel_url": bson.M{"$nin": []interface{}{"", nil}},
+		"platform":    bson.M{"$in": []string{"youtube", ""}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	settings := []models.ChannelSettings{}
+	if err = cursor.All(ctx, &settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// SetSyncStatus records the outcome of a channel sync; an empty error clears the previous one
+func (r *ChannelSettingsRepository) SetSyncStatus(ctx context.Context, id primitive.ObjectID, syncedAt time.Time, syncErr string) error {
+	update := bson.M{"$set": bson.M{"last_synced_at": syncedAt, "last_sync_error": syncErr}}
+	if syncErr == "" {
+		update = bson.M{"$set": bson.M{"last_synced_at": syncedAt}, "$unset": bson.M{"last_sync_error": ""}}
+	}
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	return err
+}
+
+// Upsert creates or updates channel settings by platform and channel name
+// The lookup keys for the name and aliases are derived here so they never drift
 func (r *ChannelSettingsRepository) Upsert(ctx context.Context, settings *models.ChannelSettings) error {
+	settings.ChannelKey = utils.NormalizeChannelKey(settings.ChannelName)
+	settings.AliasKeys = utils.NormalizeChannelKeys(settings.Aliases)
+
 	opts := options.Update().SetUpsert(true)
 	_, err := r.collection.UpdateOne(
 		ctx,
-		bson.M{"channel_name": settings.ChannelName},
+		bson.M{"platform": settings.Platform, "channel_name": settings.ChannelName},
 		bson.M{"$set": settings},
 		opts,
 	)
 	return err
 }
 
-// Delete removes channel settings by channel name
+// BackfillKeys derives lookup keys for settings saved before keys existed,
+// and drops the empty keys once stored for names without letters or digits
+// Returns the number of updated records
+func (r *ChannelSettingsRepository) BackfillKeys(ctx context.Context) (int, error) {
+	result, err := r.collection.UpdateMany(ctx, bson.M{"channel_key": ""}, bson.M{"$unset": bson.M{"channel_key": ""}})
+	if err != nil {
+		return 0, err
+	}
+	updated := int(result.ModifiedCount)
+
+	settings, err := r.FindAll(ctx)
+	if err != nil {
+		return updated, err
+	}
+
+	for _, s := range settings {
+		key := utils.NormalizeChannelKey(s.ChannelName)
+		aliasKeys := utils.NormalizeChannelKeys(s.Aliases)
+		if s.ChannelKey == key && len(s.AliasKeys) == len(aliasKeys) {
+			continue
+		}
+
+		update := bson.M{"$set": bson.M{"channel_key": key, "alias_keys": aliasKeys}}
+		if key == "" {
+			update = bson.M{"$set": bson.M{"alias_keys": aliasKeys}, "$unset": bson.M{"channel_key": ""}}
+		}
+		_, err := r.collection.UpdateOne(ctx, bson.M{"_id": s.ID}, update)
+		if err != nil {
+			return updated, err
+		}
+		updated++
+	}
+	return updated, nil
+}
+
+// SetPlatform assigns a platform to a settings record
+func (r *ChannelSettingsRepository) SetPlatform(ctx context.Context, id primitive.ObjectID, platform string) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"platform": platform}})
+	return err
+}
+
+// Delete removes channel settings by platform and channel name
+// An empty platform deletes the first record with the channel name on any platform.
 // Returns the number of deleted documents
-func (r *ChannelSettingsRepository) Delete(ctx context.Context, channelName string) (int64, error) {
-	result, err := r.collection.DeleteOne(ctx, bson.M{"channel_name": channelName})
+func (r *ChannelSettingsRepository) Delete(ctx context.Context, platform, channelName string) (int64, error) {
+	filter := bson.M{"channel_name": channelName}
+	if platform != "" {
+		filter["platform"] = platform
+	}
+	result, err := r.collection.DeleteOne(ctx, filter)
 	if err != nil {
 		return 0, err
 	}
@@ -8,6 +8,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // ChunksRepository provides database operations for note chunks
@@ -22,13 +23,46 @@ func NewChunksRepository(db *mongo.Database) *ChunksRepository {
 	}
 }
 
-// Create inserts a new chunk and returns the inserted ID
-func (r *ChunksRepository) Create(ctx context.Context, chunk *models.NoteChunk) (primitive.ObjectID, error) {
-	result, err := r.collection.InsertOne(ctx, chunk)
+// Upsert stores a chunk under its ID, replacing the chunk already stored with that ID
+func (r *ChunksRepository) Upsert(ctx context.Context, chunk *models.NoteChunk) error {
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": chunk.ID}, chunk, options.Replace().SetUpsert(true))
+	return err
+}
+
+// MarkEmbedded records that a chunk's embedding has been stored
+func (r *ChunksRepository) MarkEmbedded(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$unset": bson.M{"pending": ""}})
+	return err
+}
+
+// CountByNoteID returns how many chunks a note has and how many of them are embedded
+// Chunks saved before the pending flag existed count as embedded.
+func (r *ChunksRepository) CountByNoteID(ctx context.Context, noteID primitive.ObjectID) (int, int, error) {
+	total, err := r.collection.CountDocuments(ctx, bson.M{"note_id": noteID})
+	if err != nil {
+		return 0, 0, err
+	}
+	pending, err := r.collection.CountDocuments(ctx, bson.M{"note_id": noteID, "pending": true})
 	if err != nil {
-		return primitive.NilObjectID, err
+		return 0, 0, err
 	}
-	return result.InsertedID.(primitive.ObjectID), nil
+	return int(total), int(total - pending), nil
+}
+
+// FindByIDs retrieves the chunks with the given IDs
+func (r *ChunksRepository) FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]models.NoteChunk, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var chunks []models.NoteChunk
+	if err = cursor.All(ctx, &chunks); err != nil {
+		return nil, err
+	}
+
+	return chunks, nil
 }
 
 // DeleteByNoteID removes all chunks associated with a note
@@ -39,3 +73,50 @@ func (r *ChunksRepository) DeleteByNoteID(ctx context.Context, noteID primitive.
 	}
 	return result.DeletedCount, nil
 }
+
+// DeleteByNoteIDAndTypes removes a note's chunks of the given types
+func (r *ChunksRepository) DeleteByNoteIDAndTypes(ctx context.Context, noteID primitive.ObjectID, chunkTypes []string) (int64, error) {
+	result, err := r.collection.DeleteMany(ctx, bson.M{"note_id": noteID, "type": bson.M{"$in": chunkTypes}})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// DeleteByNoteIDs removes all chunks associated with any of the given notes
+func (r *ChunksRepository) DeleteByNoteIDs(ctx context.Context, noteIDs []primitive.ObjectID) (int64, error) {
+	result, err := r.collection.DeleteMany(ctx, bson.M{"note_id": bson.M{"$in": noteIDs}})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// DeleteAll removes every chunk
+func (r *ChunksRepository) DeleteAll(ctx context.Context) (int64, error) {
+	result, err := r.collection.DeleteMany(ctx, bson.M{})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// ForEach streams every chunk to fn, stopping at the first error
+func (r *ChunksRepository) ForEach(ctx context.Context, fn func(*models.NoteChunk) error) error {
+	cursor, err := r.collection.Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var chunk models.NoteChunk
+		if err := cursor.Decode(&chunk); err != nil {
+			return err
+		}
+		if err := fn(&chunk); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ConversationsRepository provides database operations for Q&A conversations
+type ConversationsRepository struct {
+	collection *mongo.Collection
+}
+
+// NewConversationsRepository creates a new ConversationsRepository
+func NewConversationsRepository(db *mongo.Database) *ConversationsRepository {
+	return &ConversationsRepository{
+		collection: db.Collection("conversations"),
+	}
+}
+
+// Create inserts a new conversation
+func (r *ConversationsRepository) Create(ctx context.Context, conversation *models.Conversation) (primitive.ObjectID, error) {
+	result, err := r.collection.InsertOne(ctx, conversation)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	return result.InsertedID.(primitive.ObjectID), nil
+}
+
+// FindByID retrieves a conversation with its full message history
+func (r *ConversationsRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Conversation, error) {
+	var conversation models.Conversation
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&conversation)
+	if err != nil {
+		return nil, err
+	}
+	return &conversation, nil
+}
+
+// FindAll retrieves all conversations without their messages, most recently active first
+func (r *ConversationsRepository) FindAll(ctx context.Context) ([]models.Conversation, error) {
+	opts := options.Find().
+		SetSort(bson.M{"updated_at": -1}).
+		SetProjection(bson.M{"messages": 0})
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var conversations []models.Conversation
+	if err = cursor.All(ctx, &conversations); err != nil {
+		return nil, err
+	}
+
+	if conversations == nil {
+		conversations = []models.Conversation{}
+	}
+
+	return conversations, nil
+}
+
+// AppendMessages adds messages to the end of a conversation's history
+// A non-empty title is set at the same time
+func (r *ConversationsRepository) AppendMessages(ctx context.Context, id primitive.ObjectID, title string, messages ...models.ConversationMessage) error {
+	set := bson.M{"updated_at": time.Now()}
+	if title != "" {
+		set["title"] = title
+	}
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$push": bson.M{"messages": bson.M{"$each": messages}},
+		"$set":  set,
+	})
+	return err
+}
+
+// Delete removes a conversation by ID
+// Returns the number of deleted documents
+func (r *ConversationsRepository) Delete(ctx context.Context, id primitive.ObjectID) (int64, error) {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
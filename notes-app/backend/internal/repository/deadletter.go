@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DeadLetterRepository provides database operations for embedding jobs that failed all their attempts
+type DeadLetterRepository struct {
+	collection *mongo.Collection
+}
+
+// NewDeadLetterRepository creates a new DeadLetterRepository
+func NewDeadLetterRepository(db *mongo.Database) *DeadLetterRepository {
+	return &DeadLetterRepository{
+		collection: db.Collection("dead_letter"),
+	}
+}
+
+// Save stores a dead-letter job under its original job ID, replacing an earlier copy
+func (r *DeadLetterRepository) Save(ctx context.Context, job *models.DeadLetterJob) error {
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": job.ID}, job, options.Replace().SetUpsert(true))
+	return err
+}
+
+// FindRecent retrieves the most recently failed jobs, newest first
+func (r *DeadLetterRepository) FindRecent(ctx context.Context, limit int) ([]models.DeadLetterJob, error) {
+	return r.find(ctx, bson.M{}, options.Find().SetSort(bson.M{"failed_at": -1}).SetLimit(int64(limit)))
+}
+
+// FindByIDs retrieves the dead-letter jobs with the given IDs, or all of them for nil IDs, oldest first
+func (r *DeadLetterRepository) FindByIDs(ctx context.Context, ids []primitive.ObjectID) ([]models.DeadLetterJob, error) {
+	filter := bson.M{}
+	if ids != nil {
+		filter["_id"] = bson.M{"$in": ids}
+	}
+	return r.find(ctx, filter, options.Find().SetSort(bson.M{"_id": 1}))
+}
+
+// Delete removes a job from the dead-letter queue
+func (r *DeadLetterRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+// find runs a query and decodes every matching job
+func (r *DeadLetterRepository) find(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]models.DeadLetterJob, error) {
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	jobs := []models.DeadLetterJob{}
+	if err = cursor.All(ctx, &jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// NoteEventsRepository provides database operations for the note event log
+type NoteEventsRepository struct {
+	collection *mongo.Collection
+}
+
+// NewNoteEventsRepository creates a new NoteEventsRepository
+func NewNoteEventsRepository(db *mongo.Database) *NoteEventsRepository {
+	return &NoteEventsRepository{
+		collection: db.Collection("note_events"),
+	}
+}
+
+// Create appends an event to the log
+func (r *NoteEventsRepository) Create(ctx context.Context, event *models.NoteEvent) (primitive.ObjectID, error) {
+	result, err := r.collection.InsertOne(ctx, event)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	return result.InsertedID.(primitive.ObjectID), nil
+}
+
+// FindAfter retrieves events logged after the cursor, oldest first
+// A nil cursor starts from the beginning of the log
+func (r *NoteEventsRepository) FindAfter(ctx context.Context, cursor primitive.ObjectID, limit int) ([]models.NoteEvent, error) {
+	filter := bson.M{}
+	if !cursor.IsZero() {
+		filter["_id"] = bson.M{"$gt": cursor}
+	}
+	opts := options.Find().SetSort(bson.M{"_id": 1}).SetLimit(int64(limit))
+
+	cursorResult, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursorResult.Close(ctx)
+
+	var events []models.NoteEvent
+	if err = cursorResult.All(ctx, &events); err != nil {
+		return nil, err
+	}
+
+	if events == nil {
+		events = []models.NoteEvent{}
+	}
+
+	return events, nil
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ClassificationExamplesRepository provides database operations for confirmed classification examples
+type ClassificationExamplesRepository struct {
+	collection *mongo.Collection
+}
+
+// NewClassificationExamplesRepository creates a new ClassificationExamplesRepository
+func NewClassificationExamplesRepository(db *mongo.Database) *ClassificationExamplesRepository {
+	return &ClassificationExamplesRepository{
+		collection: db.Collection("classification_examples"),
+	}
+}
+
+// FindRecent retrieves the most recently confirmed examples
+func (r *ClassificationExamplesRepository) FindRecent(ctx context.Context, limit int) ([]models.ClassificationExample, error) {
+	opts := options.Find().SetSort(bson.M{"created_at": -1}).SetLimit(int64(limit))
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var examples []models.ClassificationExample
+	if err = cursor.All(ctx, &examples); err != nil {
+		return nil, err
+	}
+
+	return examples, nil
+}
+
+// Upsert stores the confirmed example for a note, replacing any earlier confirmation
+func (r *ClassificationExamplesRepository) Upsert(ctx context.Context, example *models.ClassificationExample) error {
+	opts := options.Update().SetUpsert(true)
+	_, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"note_id": example.NoteID},
+		bson.M{"$set": example},
+		opts,
+	)
+	return err
+}
+
+// CountInCategories counts the examples confirming any of the categories
+func (r *ClassificationExamplesRepository) CountInCategories(ctx context.Context, categories []string) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{"category": bson.M{"$in": categories}})
+}
+
+// SetCategory moves the examples confirming any of the from categories to the to category
+// Returns the number of modified documents
+func (r *ClassificationExamplesRepository) SetCategory(ctx context.Context, from []string, to string) (int64, error) {
+	result, err := r.collection.UpdateMany(ctx, bson.M{"category": bson.M{"$in": from}}, bson.M{"$set": bson.M{"category": to}})
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
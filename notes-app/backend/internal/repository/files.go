@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// FilesRepository stores the original files of uploaded documents in GridFS
+// GridFS uploads and downloads take no context in this driver version.
+type FilesRepository struct {
+	bucket *gridfs.Bucket
+}
+
+// NewFilesRepository creates a new FilesRepository on the "uploads" bucket
+func NewFilesRepository(db *mongo.Database) (*FilesRepository, error) {
+	bucket, err := gridfs.NewBucket(db, options.GridFSBucket().SetName("uploads"))
+	if err != nil {
+		return nil, err
+	}
+	return &FilesRepository{bucket: bucket}, nil
+}
+
+// Upload stores a file along with its content type and SHA-256 hash
+func (r *FilesRepository) Upload(fileName, contentType, hash string, data []byte) (primitive.ObjectID, error) {
+	opts := options.GridFSUpload().SetMetadata(bson.M{"content_type": contentType, "sha256": hash})
+	return r.bucket.UploadFromStream(fileName, bytes.NewReader(data), opts)
+}
+
+// FindIDByHash returns the ID of a stored file with the SHA-256 hash, or mongo.ErrNoDocuments
+func (r *FilesRepository) FindIDByHash(ctx context.Context, hash string) (primitive.ObjectID, error) {
+	cursor, err := r.bucket.FindContext(ctx, bson.M{"metadata.sha256": hash})
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	defer cursor.Close(ctx)
+
+	if !cursor.Next(ctx) {
+		if err := cursor.Err(); err != nil {
+			return primitive.NilObjectID, err
+		}
+		return primitive.NilObjectID, mongo.ErrNoDocuments
+	}
+	var file struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.Decode(&file); err != nil {
+		return primitive.NilObjectID, err
+	}
+	return file.ID, nil
+}
+
+// Open returns a stream of a stored file's contents; the caller closes it
+// A missing file returns gridfs.ErrFileNotFound.
+func (r *FilesRepository) Open(id primitive.ObjectID) (*gridfs.DownloadStream, error) {
+	return r.bucket.OpenDownloadStream(id)
+}
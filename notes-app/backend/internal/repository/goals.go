@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GoalsRepository provides database operations for tracked goals
+type GoalsRepository struct {
+	collection *mongo.Collection
+}
+
+// NewGoalsRepository creates a new GoalsRepository
+func NewGoalsRepository(db *mongo.Database) *GoalsRepository {
+	return &GoalsRepository{
+		collection: db.Collection("goals"),
+	}
+}
+
+// Create inserts a new goal
+func (r *GoalsRepository) Create(ctx context.Context, goal *models.Goal) (primitive.ObjectID, error) {
+	result, err := r.collection.InsertOne(ctx, goal)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	return result.InsertedID.(primitive.ObjectID), nil
+}
+
+// FindAll retrieves goals matching the filter, soonest target date first; goals without one come last
+func (r *GoalsRepository) FindAll(ctx context.Context, filter bson.M) ([]models.Goal, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "target_date", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	goals := []models.Goal{}
+	if err = cursor.All(ctx, &goals); err != nil {
+		return nil, err
+	}
+
+	// Mongo sorts missing dates first; move them to the end
+	dated := make([]models.Goal, 0, len(goals))
+	var undated []models.Goal
+	for _, goal := range goals {
+		if goal.TargetDate == nil {
+			undated = append(undated, goal)
+		} else {
+			dated = append(dated, goal)
+		}
+	}
+	return append(dated, undated...), nil
+}
+
+// FindByID retrieves a goal by its ID
+func (r *GoalsRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Goal, error) {
+	var goal models.Goal
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&goal)
+	if err != nil {
+		return nil, err
+	}
+	return &goal, nil
+}
+
+// Update applies an update to a goal, reporting whether it exists
+func (r *GoalsRepository) Update(ctx context.Context, id primitive.ObjectID, update bson.M) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// Delete removes a goal by ID
+// Returns the number of deleted documents
+func (r *GoalsRepository) Delete(ctx context.Context, id primitive.ObjectID) (int64, error) {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GraphRepository provides database operations for the edges of the knowledge graph
+type GraphRepository struct {
+	collection *mongo.Collection
+}
+
+// NewGraphRepository creates a new GraphRepository
+func NewGraphRepository(db *mongo.Database) *GraphRepository {
+	return &GraphRepository{
+		collection: db.Collection("graph_edges"),
+	}
+}
+
+// EnsureIndexes creates the indexes edges are replaced by and looked up by
+func (r *GraphRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "note_id", Value: 1}}},
+		{Keys: bson.D{{Key: "source", Value: 1}}},
+		{Keys: bson.D{{Key: "target", Value: 1}}},
+	})
+	return err
+}
+
+// ReplaceNoteEdges replaces the edges derived from a note with the given ones
+func (r *GraphRepository) ReplaceNoteEdges(ctx context.Context, noteID primitive.ObjectID, edges []models.GraphEdge) error {
+	if _, err := r.collection.DeleteMany(ctx, bson.M{"note_id": noteID}); err != nil {
+		return err
+	}
+	if len(edges) == 0 {
+		return nil
+	}
+	docs := make([]interface{}, len(edges))
+	for i := range edges {
+		docs[i] = edges[i]
+	}
+	_, err := r.collection.InsertMany(ctx, docs)
+	return err
+}
+
+// DeleteNode removes the edges derived from a note and the edges of other notes pointing at it
+func (r *GraphRepository) DeleteNode(ctx context.Context, noteID primitive.ObjectID, nodeID string) error {
+	_, err := r.collection.DeleteMany(ctx, bson.M{"$or": []bson.M{{"note_id": noteID}, {"target": nodeID}}})
+	return err
+}
+
+// FindBySources retrieves the edges leaving the given nodes
+func (r *GraphRepository) FindBySources(ctx context.Context, sources []string) ([]models.GraphEdge, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"source": bson.M{"$in": sources}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	edges := []models.GraphEdge{}
+	if err = cursor.All(ctx, &edges); err != nil {
+		return nil, err
+	}
+	return edges, nil
+}
@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// JobsRepository provides database operations for the durable embedding job queue
+type JobsRepository struct {
+	collection *mongo.Collection
+}
+
+// NewJobsRepository creates a new JobsRepository
+func NewJobsRepository(db *mongo.Database) *JobsRepository {
+	return &JobsRepository{
+		collection: db.Collection("processing_jobs"),
+	}
+}
+
+// EnsureIndexes creates the index workers use to find the next claimable job
+func (r *JobsRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "status", Value: 1}, {Key: "available_at", Value: 1}},
+		Options: options.Index().SetName("status_available_at"),
+	})
+	return err
+}
+
+// Enqueue inserts a job as pending, available immediately
+func (r *JobsRepository) Enqueue(ctx context.Context, job *models.ProcessingJob) (primitive.ObjectID, error) {
+	now := time.Now()
+	job.ID = primitive.NilObjectID
+	job.Status = models.JobPending
+	job.Attempts = 0
+	job.AvailableAt = now
+	job.CreatedAt = now
+
+	result, err := r.collection.InsertOne(ctx, job)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	return result.InsertedID.(primitive.ObjectID), nil
+}
+
+// Claim atomically takes the oldest available job, marking it running until leaseUntil, or returns
+// mongo.ErrNoDocuments. Running jobs whose lease expired, e.g. because their worker's process died,
+// are claimed again.
+func (r *JobsRepository) Claim(ctx context.Context, leaseUntil time.Time) (*models.ProcessingJob, error) {
+	now := time.Now()
+	filter := bson.M{"$or": []bson.M{
+		{"status": models.JobPending, "available_at": bson.M{"$lte": now}},
+		{"status": models.JobRunning, "lease_until": bson.M{"$lt": now}},
+	}}
+	update := bson.M{
+		"$set": bson.M{"status": models.JobRunning, "lease_until": leaseUntil},
+		"$inc": bson.M{"attempts": 1},
+	}
+	opts := options.FindOneAndUpdate().SetSort(bson.M{"_id": 1}).SetReturnDocument(options.After)
+
+	var job models.ProcessingJob
+	if err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// DeleteSuperseded removes a note's pending jobs made redundant by a newer job, returning how many
+// were removed: all of them, or only those re-embedding just the summary when summaryOnly is set
+func (r *JobsRepository) DeleteSuperseded(ctx context.Context, noteID primitive.ObjectID, summaryOnly bool) (int64, error) {
+	filter := bson.M{"note_id": noteID, "status": models.JobPending}
+	if summaryOnly {
+		filter["summary_only"] = true
+	}
+	result, err := r.collection.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// ExtendLease moves the lease of a job still claimed until leaseUntil on to newLeaseUntil, or
+// returns mongo.ErrNoDocuments when the lease expired and another worker claimed the job
+func (r *JobsRepository) ExtendLease(ctx context.Context, id primitive.ObjectID, leaseUntil, newLeaseUntil time.Time) error {
+	result, err := r.collection.UpdateOne(ctx, claimFilter(id, leaseUntil), bson.M{"$set": bson.M{"lease_until": newLeaseUntil}})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// Complete removes a finished job from the queue, or returns mongo.ErrNoDocuments when the job is
+// no longer claimed until leaseUntil, so a worker whose lease ran out can't remove another's claim
+func (r *JobsRepository) Complete(ctx context.Context, id primitive.ObjectID, leaseUntil time.Time) error {
+	result, err := r.collection.DeleteOne(ctx, claimFilter(id, leaseUntil))
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// Retry returns a failed job still claimed until leaseUntil to the queue, to be claimed again at
+// availableAt, or returns mongo.ErrNoDocuments when another worker has claimed it since
+func (r *JobsRepository) Retry(ctx context.Context, id primitive.ObjectID, leaseUntil time.Time, lastError string, availableAt time.Time) error {
+	result, err := r.collection.UpdateOne(ctx, claimFilter(id, leaseUntil), bson.M{
+		"$set":   bson.M{"status": models.JobPending, "last_error": lastError, "available_at": availableAt},
+		"$unset": bson.M{"lease_until": ""},
+	})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// claimFilter matches a job only while it is running under the given lease
+func claimFilter(id primitive.ObjectID, leaseUntil time.Time) bson.M {
+	return bson.M{"_id": id, "status": models.JobRunning, "lease_until": leaseUntil}
+}
+
+// Restore queues a job again under its original ID as pending with no attempts, e.g. one taken
+// from the dead-letter queue
+func (r *JobsRepository) Restore(ctx context.Context, job *models.ProcessingJob) error {
+	job.Status = models.JobPending
+	job.Attempts = 0
+	job.AvailableAt = time.Now()
+	job.LeaseUntil = time.Time{}
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": job.ID}, job, options.Replace().SetUpsert(true))
+	return err
+}
+
+// FindByID retrieves a job by ID, or mongo.ErrNoDocuments
+func (r *JobsRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.ProcessingJob, error) {
+	var job models.ProcessingJob
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// FindByStatus retrieves jobs with the given status, or all jobs for an empty status, oldest first
+func (r *JobsRepository) FindByStatus(ctx context.Context, status string, limit int) ([]models.ProcessingJob, error) {
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+	opts := options.Find().SetSort(bson.M{"_id": 1}).SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	jobs := []models.ProcessingJob{}
+	if err = cursor.All(ctx, &jobs); err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// Requeue makes a job that isn't running pending again with its attempts reset, returning the
+// updated job or mongo.ErrNoDocuments when no such job exists
+func (r *JobsRepository) Requeue(ctx context.Context, id primitive.ObjectID) (*models.ProcessingJob, error) {
+	filter := bson.M{"_id": id, "status": bson.M{"$ne": models.JobRunning}}
+	update := bson.M{
+		"$set":   bson.M{"status": models.JobPending, "attempts": 0, "available_at": time.Now()},
+		"$unset": bson.M{"lease_until": ""},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var job models.ProcessingJob
+	if err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
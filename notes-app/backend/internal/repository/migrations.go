@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MigrationRunsRepository provides database operations for the progress of bulk migrations
+type MigrationRunsRepository struct {
+	collection *mongo.Collection
+}
+
+// NewMigrationRunsRepository creates a new MigrationRunsRepository
+func NewMigrationRunsRepository(db *mongo.Database) *MigrationRunsRepository {
+	return &MigrationRunsRepository{
+		collection: db.Collection("migration_runs"),
+	}
+}
+
+// Create inserts a new run
+func (r *MigrationRunsRepository) Create(ctx context.Context, run *models.MigrationRun) (primitive.ObjectID, error) {
+	result, err := r.collection.InsertOne(ctx, run)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	return result.InsertedID.(primitive.ObjectID), nil
+}
+
+// Save replaces a run with its latest progress
+func (r *MigrationRunsRepository) Save(ctx context.Context, run *models.MigrationRun) error {
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": run.ID}, run)
+	return err
+}
+
+// FindUnfinished retrieves the most recent run of a kind that did not complete, or mongo.ErrNoDocuments
+func (r *MigrationRunsRepository) FindUnfinished(ctx context.Context, kind string) (*models.MigrationRun, error) {
+	var run models.MigrationRun
+	opts := options.FindOne().SetSort(bson.M{"started_at": -1})
+	err := r.collection.FindOne(ctx, bson.M{"kind": kind, "status": bson.M{"$ne": models.MigrationCompleted}}, opts).Decode(&run)
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// FindLatest retrieves the most recent run of a kind, or mongo.ErrNoDocuments
+func (r *MigrationRunsRepository) FindLatest(ctx context.Context, kind string) (*models.MigrationRun, error) {
+	var run models.MigrationRun
+	opts := options.FindOne().SetSort(bson.M{"started_at": -1})
+	err := r.collection.FindOne(ctx, bson.M{"kind": kind}, opts).Decode(&run)
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// FindRecent retrieves the most recent runs, newest first
+func (r *MigrationRunsRepository) FindRecent(ctx context.Context, limit int) ([]models.MigrationRun, error) {
+	opts := options.Find().SetSort(bson.M{"started_at": -1}).SetLimit(int64(limit))
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	runs := []models.MigrationRun{}
+	if err = cursor.All(ctx, &runs); err != nil {
+		return nil, err
+	}
+	return runs, nil
+}
@@ -2,6 +2,9 @@ package repository
 
 import (
 	"context"
+	"regexp"
+	"strings"
+	"time"
 
 	"backend/internal/models"
 
@@ -43,6 +46,26 @@ func (r *NotesRepository) FindAll(ctx context.Context, filter bson.M, opts ...*o
 	return notes, nil
 }
 
+// ForEach streams every note matching the filter to fn, stopping at the first error
+func (r *NotesRepository) ForEach(ctx context.Context, filter bson.M, fn func(*models.Note) error, opts ...*options.FindOptions) error {
+	cursor, err := r.collection.Find(ctx, filter, opts...)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var note models.Note
+		if err := cursor.Decode(&note); err != nil {
+			return err
+		}
+		if err := fn(&note); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
 // FindByID retrieves a single note by its ID
 func (r *NotesRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Note, error) {
 	var note models.Note
@@ -59,6 +82,11 @@ func (r *NotesRepository) FindByCategory(ctx context.Context, category string) (
 	return r.FindAll(ctx, bson.M{"category": category}, opts)
 }
 
+// CountByCategory counts the notes in a category
+func (r *NotesRepository) CountByCategory(ctx context.Context, category string) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{"category": category})
+}
+
 // FindByURL retrieves a note by its metadata URL
 func (r *NotesRepository) FindByURL(ctx context.Context, url string) (*models.Note, error) {
 	var note models.Note
@@ -83,8 +111,19 @@ func (r *NotesRepository) ExistsByURL(ctx context.Context, url string) (bool, er
 	return count > 0, nil
 }
 
+// ExistsByFileHash checks if a note was created from an uploaded file with the SHA-256 hash
+func (r *NotesRepository) ExistsByFileHash(ctx context.Context, hash string) (bool, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{"metadata.file_hash": hash})
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
 // Create inserts a new note and returns the inserted ID
 func (r *NotesRepository) Create(ctx context.Context, note *models.Note) (primitive.ObjectID, error) {
+	note.TitleWords = titleWords(note.Title)
 	result, err := r.collection.InsertOne(ctx, note)
 	if err != nil {
 		return primitive.NilObjectID, err
@@ -93,17 +132,476 @@ func (r *NotesRepository) Create(ctx context.Context, note *models.Note) (primit
 }
 
 // Update modifies a note with the given update document
+// A new title also replaces the title words it is suggested by.
 func (r *NotesRepository) Update(ctx context.Context, id primitive.ObjectID, update bson.M) error {
+	if set, ok := update["$set"].(bson.M); ok {
+		if title, ok := set["title"].(string); ok {
+			set["title_words"] = titleWords(title)
+		}
+	}
 	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
 	return err
 }
 
+// IncrementProcessingGeneration bumps a note's processing generation and returns the new value,
+// or mongo.ErrNoDocuments when the note doesn't exist
+func (r *NotesRepository) IncrementProcessingGeneration(ctx context.Context, id primitive.ObjectID) (int64, error) {
+	opts := options.FindOneAndUpdate().
+		SetProjection(bson.M{"processing_generation": 1}).
+		SetReturnDocument(options.After)
+
+	var note models.Note
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": id}, bson.M{"$inc": bson.M{"processing_generation": 1}}, opts).Decode(&note)
+	if err != nil {
+		return 0, err
+	}
+	return note.ProcessingGen, nil
+}
+
+// FindProcessingGeneration returns a note's processing generation, or mongo.ErrNoDocuments when
+// the note doesn't exist
+func (r *NotesRepository) FindProcessingGeneration(ctx context.Context, id primitive.ObjectID) (int64, error) {
+	opts := options.FindOne().SetProjection(bson.M{"processing_generation": 1})
+
+	var note models.Note
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}, opts).Decode(&note); err != nil {
+		return 0, err
+	}
+	return note.ProcessingGen, nil
+}
+
+// EnsureTextIndex creates the full-text index used by keyword and hybrid search
+func (r *NotesRepository) EnsureTextIndex(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "title", Value: "text"},
+			{Key: "summary", Value: "text"},
+			{Key: "content", Value: "text"},
+		},
+		Options: options.Index().
+			SetName("notes_text").
+			SetWeights(bson.D{
+				{Key: "title", Value: 5},
+				{Key: "summary", Value: 2},
+				{Key: "content", Value: 1},
+			}),
+	})
+	return err
+}
+
+// TextSearch retrieves notes matching a full-text query and the filter, best match first
+func (r *NotesRepository) TextSearch(ctx context.Context, query string, filter bson.M, limit int) ([]models.Note, error) {
+	score := bson.M{"$meta": "textScore"}
+	opts := options.Find().
+		SetProjection(bson.M{"text_score": score}).
+		SetSort(bson.M{"text_score": score}).
+		SetLimit(int64(limit))
+
+	textFilter := bson.M{"$text": bson.M{"$search": query}}
+	for key, value := range filter {
+		textFilter[key] = value
+	}
+	return r.FindAll(ctx, textFilter, opts)
+}
+
+// EnsureSuggestIndexes creates the indexes backing tag and title prefix lookups for search suggestions
+func (r *NotesRepository) EnsureSuggestIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "tags", Value: 1}},
+			Options: options.Index().SetName("notes_tags"),
+		},
+		{
+			Keys:    bson.D{{Key: "title_words", Value: 1}},
+			Options: options.Index().SetName("notes_title_words"),
+		},
+	})
+	return err
+}
+
+// BackfillTitleWords derives title words for notes saved before they were stored
+// Returns the number of updated notes
+func (r *NotesRepository) BackfillTitleWords(ctx context.Context) (int, error) {
+	filter := bson.M{"title_words": bson.M{"$exists": false}, "title": bson.M{"$nin": []interface{}{"", nil}}}
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"title": 1}))
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	updated := 0
+	for cursor.Next(ctx) {
+		var note models.Note
+		if err := cursor.Decode(&note); err != nil {
+			return updated, err
+		}
+		_, err := r.collection.UpdateOne(ctx, bson.M{"_id": note.ID}, bson.M{"$set": bson.M{"title_words": titleWords(note.Title)}})
+		if err != nil {
+			return updated, err
+		}
+		updated++
+	}
+	return updated, cursor.Err()
+}
+
+// titleWords returns the distinct lowercased words of a title
+func titleWords(title string) []string {
+	var words []string
+	seen := make(map[string]bool)
+	for _, word := range strings.Fields(strings.ToLower(title)) {
+		if !seen[word] {
+			seen[word] = true
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// EnsureVaultPathIndex creates the unique index on the Obsidian vault path of synced notes
+func (r *NotesRepository) EnsureVaultPathIndex(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "metadata.vault_path", Value: 1}},
+		Options: options.Index().
+			SetName("notes_vault_path").
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"metadata.vault_path": bson.M{"$exists": true}}),
+	})
+	return err
+}
+
+// entityCollation compares entity names case-insensitively, as the entities index does
+var entityCollation = &options.Collation{Locale: "en", Strength: 2}
+
+// EnsureEntityIndex creates the case-insensitive index backing lookups of the notes mentioning an entity
+func (r *NotesRepository) EnsureEntityIndex(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "entities.name", Value: 1}},
+		Options: options.Index().SetName("notes_entities").SetCollation(entityCollation),
+	})
+	return err
+}
+
+// FindByEntity retrieves the notes matching the filter that mention the named entity, newest first
+// Names match case-insensitively; an empty entityType matches entities of any type.
+func (r *NotesRepository) FindByEntity(ctx context.Context, filter bson.M, name, entityType string) ([]models.Note, error) {
+	entity := bson.M{"name": name}
+	if entityType != "" {
+		entity["type"] = entityType
+	}
+	match := bson.M{"entities": bson.M{"$elemMatch": entity}}
+	for key, value := range filter {
+		match[key] = value
+	}
+
+	opts := options.Find().SetSort(bson.M{"created": -1}).SetCollation(entityCollation)
+	return r.FindAll(ctx, match, opts)
+}
+
+// EntityCounts returns the entities mentioned by notes matching the filter and how many notes
+// mention each, most mentioned first. Names differing only in case count as one entity, listed as
+// most recently written; an empty entityType counts entities of every type.
+func (r *NotesRepository) EntityCounts(ctx context.Context, filter bson.M, entityType string, limit int) ([]models.EntityCount, error) {
+	match := bson.M{"entities": bson.M{"$exists": true, "$ne": bson.A{}}}
+	for key, value := range filter {
+		match[key] = value
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$sort", Value: bson.M{"created": -1}}},
+		{{Key: "$unwind", Value: "$entities"}},
+	}
+	if entityType != "" {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: bson.M{"entities.type": entityType}}})
+	}
+	pipeline = append(pipeline,
+		bson.D{{Key: "$group", Value: bson.M{
+			"_id":            bson.M{"key": bson.M{"$toLower": "$entities.name"}, "type": "$entities.type"},
+			"name":           bson.M{"$first": "$entities.name"},
+			"count":          bson.M{"$sum": 1},
+			"last_mentioned": bson.M{"$first": "$created"},
+		}}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id.key", Value: 1}}}},
+	)
+	if limit > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: limit}})
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		ID struct {
+			Type string `bson:"type"`
+		} `bson:"_id"`
+		Name          string    `bson:"name"`
+		Count         int       `bson:"count"`
+		LastMentioned time.Time `bson:"last_mentioned"`
+	}
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	counts := make([]models.EntityCount, 0, len(results))
+	for _, result := range results {
+		counts = append(counts, models.EntityCount{Name: result.Name, Type: result.ID.Type, Count: result.Count, LastMentioned: result.LastMentioned})
+	}
+	return counts, nil
+}
+
+// FindByVaultPaths retrieves the notes synced to the given Obsidian vault paths
+func (r *NotesRepository) FindByVaultPaths(ctx context.Context, paths []string) ([]models.Note, error) {
+	return r.FindAll(ctx, bson.M{"metadata.vault_path": bson.M{"$in": paths}})
+}
+
+// FindByTitlePrefix retrieves unarchived notes with a title word starting with the prefix (case-insensitive),
+// newest first. Only the ID and title are loaded.
+// Title words are stored lowercase, so the anchored prefix can use the title words index.
+func (r *NotesRepository) FindByTitlePrefix(ctx context.Context, prefix string, limit int) ([]models.Note, error) {
+	opts := options.Find().
+		SetProjection(bson.M{"title": 1}).
+		SetSort(bson.M{"created": -1}).
+		SetLimit(int64(limit))
+
+	pattern := "^" + regexp.QuoteMeta(strings.ToLower(prefix))
+	return r.FindAll(ctx, bson.M{"title_words": bson.M{"$regex": pattern}, "archived": bson.M{"$ne": true}}, opts)
+}
+
+// TagCounts returns tags starting with the prefix and how many notes carry each, most used first
+// Tags are stored lowercase, so the anchored prefix can use the tags index. An empty prefix matches all tags.
+func (r *NotesRepository) TagCounts(ctx context.Context, prefix string, limit int) ([]models.TagCount, error) {
+	match := bson.M{"tags": bson.M{"$exists": true, "$ne": bson.A{}}}
+	if prefix != "" {
+		match["tags"] = bson.M{"$regex": "^" + regexp.QuoteMeta(strings.ToLower(prefix))}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$unwind", Value: "$tags"}},
+	}
+	if prefix != "" {
+		// Notes matched on one tag may carry others that don't share the prefix
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: match}})
+	}
+	pipeline = append(pipeline,
+		bson.D{{Key: "$group", Value: bson.M{"_id": "$tags", "count": bson.M{"$sum": 1}}}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+	)
+	if limit > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: limit}})
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Name  string `bson:"_id"`
+		Count int    `bson:"count"`
+	}
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	counts := make([]models.TagCount, 0, len(results))
+	for _, result := range results {
+		counts = append(counts, models.TagCount{Name: result.Name, Count: result.Count})
+	}
+	return counts, nil
+}
+
+// ContentLengths sums the length in characters of the matching notes' titles, content and
+// summaries, by channel (metadata author and platform). Content past maxContentChars of a note
+// isn't counted when maxContentChars is positive.
+func (r *NotesRepository) ContentLengths(ctx context.Context, filter bson.M, maxContentChars int) ([]models.ContentLength, error) {
+	length := func(field string) bson.M {
+		return bson.M{"$strLenCP": bson.M{"$ifNull": bson.A{"$" + field, ""}}}
+	}
+	contentLength := length("content")
+	if maxContentChars > 0 {
+		contentLength = bson.M{"$min": bson.A{contentLength, maxContentChars}}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$group", Value: bson.M{
+			"_id":           bson.M{"author": "$metadata.author", "platform": "$metadata.platform"},
+			"notes":         bson.M{"$sum": 1},
+			"title_chars":   bson.M{"$sum": length("title")},
+			"content_chars": bson.M{"$sum": contentLength},
+			"summary_chars": bson.M{"$sum": length("summary")},
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Channel struct {
+			Author   string `bson:"author"`
+			Platform string `bson:"platform"`
+		} `bson:"_id"`
+		Notes        int `bson:"notes"`
+		TitleChars   int `bson:"title_chars"`
+		ContentChars int `bson:"content_chars"`
+		SummaryChars int `bson:"summary_chars"`
+	}
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	lengths := make([]models.ContentLength, 0, len(results))
+	for _, result := range results {
+		lengths = append(lengths, models.ContentLength{
+			Author:       result.Channel.Author,
+			Platform:     result.Channel.Platform,
+			Notes:        result.Notes,
+			TitleChars:   result.TitleChars,
+			ContentChars: result.ContentChars,
+			SummaryChars: result.SummaryChars,
+		})
+	}
+	return lengths, nil
+}
+
+// DailyCounts returns the number of matching notes created on each day, oldest first
+// Days are calendar days in the given IANA timezone; days without notes are left out
+func (r *NotesRepository) DailyCounts(ctx context.Context, filter bson.M, timezone string) ([]models.DayCount, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{"$dateToString": bson.M{
+				"format":   "%Y-%m-%d",
+				"date":     "$created",
+				"timezone": timezone,
+			}},
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$sort", Value: bson.M{"_id": 1}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Date  string `bson:"_id"`
+		Count int    `bson:"count"`
+	}
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	counts := make([]models.DayCount, 0, len(results))
+	for _, result := range results {
+		counts = append(counts, models.DayCount{Date: result.Date, Count: result.Count})
+	}
+	return counts, nil
+}
+
+// FindAccess retrieves the view counters of matching notes in the given order
+func (r *NotesRepository) FindAccess(ctx context.Context, filter bson.M, sort bson.D, limit int) ([]models.NoteAccess, error) {
+	opts := options.Find().
+		SetProjection(bson.M{"title": 1, "category": 1, "view_count": 1, "last_viewed_at": 1, "created": 1}).
+		SetSort(sort).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	notes := []models.NoteAccess{}
+	if err = cursor.All(ctx, &notes); err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
+
+// ProcessingStatusCounts returns the number of notes with each processing status; notes without
+// one are counted under the empty status
+func (r *NotesRepository) ProcessingStatusCounts(ctx context.Context) (map[string]int, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{"_id": "$processing_status", "count": bson.M{"$sum": 1}}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Status *string `bson:"_id"`
+		Count  int     `bson:"count"`
+	}
+	if err = cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(results))
+	for _, result := range results {
+		status := ""
+		if result.Status != nil {
+			status = *result.Status
+		}
+		counts[status] += result.Count
+	}
+	return counts, nil
+}
+
+// FindByProcessingStatus retrieves the embedding coverage of notes with any of the given processing
+// statuses, oldest first
+func (r *NotesRepository) FindByProcessingStatus(ctx context.Context, statuses []string, limit int) ([]models.NoteIndexing, error) {
+	opts := options.Find().
+		SetProjection(bson.M{"title": 1, "processing_status": 1, "chunk_count": 1, "embedded_chunk_count": 1, "created": 1}).
+		SetSort(bson.M{"created": 1}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, bson.M{"processing_status": bson.M{"$in": statuses}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	notes := []models.NoteIndexing{}
+	if err = cursor.All(ctx, &notes); err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
+
+// UpdateMany applies an update to all notes matching the filter
+func (r *NotesRepository) UpdateMany(ctx context.Context, filter bson.M, update bson.M) (*mongo.UpdateResult, error) {
+	return r.collection.UpdateMany(ctx, filter, update)
+}
+
 // Delete removes a note by its ID
 func (r *NotesRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
 	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
 	return err
 }
 
+// DeleteByIDs removes every note with one of the given IDs
+func (r *NotesRepository) DeleteByIDs(ctx context.Context, ids []primitive.ObjectID) (int64, error) {
+	result, err := r.collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
 // DeleteByAuthor deletes all notes for a given author/channel
 func (r *NotesRepository) DeleteByAuthor(ctx context.Context, author string) (int64, error) {
 	result, err := r.collection.DeleteMany(ctx, bson.M{"metadata.author": author})
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PodcastFeedsRepository provides database operations for followed podcast feeds
+type PodcastFeedsRepository struct {
+	collection *mongo.Collection
+}
+
+// NewPodcastFeedsRepository creates a new PodcastFeedsRepository
+func NewPodcastFeedsRepository(db *mongo.Database) *PodcastFeedsRepository {
+	return &PodcastFeedsRepository{
+		collection: db.Collection("podcast_feeds"),
+	}
+}
+
+// EnsureIndexes creates the unique feed URL index
+func (r *PodcastFeedsRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.M{"url": 1},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+// Create inserts a new feed
+func (r *PodcastFeedsRepository) Create(ctx context.Context, feed *models.PodcastFeed) (primitive.ObjectID, error) {
+	result, err := r.collection.InsertOne(ctx, feed)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	return result.InsertedID.(primitive.ObjectID), nil
+}
+
+// FindAll retrieves all feeds, oldest first
+func (r *PodcastFeedsRepository) FindAll(ctx context.Context) ([]models.PodcastFeed, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"created_at": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	feeds := []models.PodcastFeed{}
+	if err = cursor.All(ctx, &feeds); err != nil {
+		return nil, err
+	}
+	return feeds, nil
+}
+
+// FindByID retrieves a feed by ID
+func (r *PodcastFeedsRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.PodcastFeed, error) {
+	var feed models.PodcastFeed
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&feed)
+	if err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
+
+// SetCheckStatus records the outcome of a feed sync; an empty error clears the previous one
+func (r *PodcastFeedsRepository) SetCheckStatus(ctx context.Context, id primitive.ObjectID, checkedAt time.Time, checkErr string) error {
+	update := bson.M{"$set": bson.M{"last_checked_at": checkedAt, "last_error": checkErr}}
+	if checkErr == "" {
+		update = bson.M{"$set": bson.M{"last_checked_at": checkedAt}, "$unset": bson.M{"last_error": ""}}
+	}
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	return err
+}
+
+// Delete removes a feed by ID
+// Returns the number of deleted documents
+func (r *PodcastFeedsRepository) Delete(ctx context.Context, id primitive.ObjectID) (int64, error) {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
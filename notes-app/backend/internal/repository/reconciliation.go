@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ReconciliationRepository provides database operations for reconciliation reports
+type ReconciliationRepository struct {
+	collection *mongo.Collection
+}
+
+// NewReconciliationRepository creates a new ReconciliationRepository
+func NewReconciliationRepository(db *mongo.Database) *ReconciliationRepository {
+	return &ReconciliationRepository{
+		collection: db.Collection("reconciliation_reports"),
+	}
+}
+
+// Create inserts a new report
+func (r *ReconciliationRepository) Create(ctx context.Context, report *models.ReconciliationReport) (primitive.ObjectID, error) {
+	result, err := r.collection.InsertOne(ctx, report)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	return result.InsertedID.(primitive.ObjectID), nil
+}
+
+// FindRecent retrieves the most recent reports, newest first
+func (r *ReconciliationRepository) FindRecent(ctx context.Context, limit int) ([]models.ReconciliationReport, error) {
+	opts := options.Find().SetSort(bson.M{"started_at": -1}).SetLimit(int64(limit))
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var reports []models.ReconciliationReport
+	if err = cursor.All(ctx, &reports); err != nil {
+		return nil, err
+	}
+
+	if reports == nil {
+		reports = []models.ReconciliationReport{}
+	}
+
+	return reports, nil
+}
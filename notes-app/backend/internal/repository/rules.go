@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CategoryRulesRepository provides database operations for heuristic category rules
+type CategoryRulesRepository struct {
+	collection *mongo.Collection
+}
+
+// NewCategoryRulesRepository creates a new CategoryRulesRepository
+func NewCategoryRulesRepository(db *mongo.Database) *CategoryRulesRepository {
+	return &CategoryRulesRepository{
+		collection: db.Collection("category_rules"),
+	}
+}
+
+// FindAll retrieves rules matching the filter, highest priority first
+func (r *CategoryRulesRepository) FindAll(ctx context.Context, filter bson.M) ([]models.CategoryRule, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "priority", Value: -1}, {Key: "_id", Value: 1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rules []models.CategoryRule
+	if err = cursor.All(ctx, &rules); err != nil {
+		return nil, err
+	}
+
+	if rules == nil {
+		rules = []models.CategoryRule{}
+	}
+
+	return rules, nil
+}
+
+// FindEnabled retrieves all enabled rules, highest priority first
+func (r *CategoryRulesRepository) FindEnabled(ctx context.Context) ([]models.CategoryRule, error) {
+	return r.FindAll(ctx, bson.M{"enabled": true})
+}
+
+// FindByID retrieves a single rule by its ID
+func (r *CategoryRulesRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.CategoryRule, error) {
+	var rule models.CategoryRule
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&rule)
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// Create inserts a new rule and returns the inserted ID
+func (r *CategoryRulesRepository) Create(ctx context.Context, rule *models.CategoryRule) (primitive.ObjectID, error) {
+	result, err := r.collection.InsertOne(ctx, rule)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	return result.InsertedID.(primitive.ObjectID), nil
+}
+
+// Replace overwrites an existing rule
+// Returns the number of matched documents
+func (r *CategoryRulesRepository) Replace(ctx context.Context, id primitive.ObjectID, rule *models.CategoryRule) (int64, error) {
+	result, err := r.collection.ReplaceOne(ctx, bson.M{"_id": id}, rule)
+	if err != nil {
+		return 0, err
+	}
+	return result.MatchedCount, nil
+}
+
+// Delete removes a rule by its ID
+// Returns the number of deleted documents
+func (r *CategoryRulesRepository) Delete(ctx context.Context, id primitive.ObjectID) (int64, error) {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+// CountInCategories counts the rules assigning any of the categories
+func (r *CategoryRulesRepository) CountInCategories(ctx context.Context, categories []string) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{"category": bson.M{"$in": categories}})
+}
+
+// SetCategory points the rules assigning any of the from categories at the to category
+// Returns the number of modified documents
+func (r *CategoryRulesRepository) SetCategory(ctx context.Context, from []string, to string) (int64, error) {
+	result, err := r.collection.UpdateMany(ctx,
+		bson.M{"category": bson.M{"$in": from}},
+		bson.M{"$set": bson.M{"category": to, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.ModifiedCount, nil
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SearchQueriesRepository provides database operations for the recent search query log
+type SearchQueriesRepository struct {
+	collection *mongo.Collection
+}
+
+// NewSearchQueriesRepository creates a new SearchQueriesRepository
+func NewSearchQueriesRepository(db *mongo.Database) *SearchQueriesRepository {
+	return &SearchQueriesRepository{
+		collection: db.Collection("search_queries"),
+	}
+}
+
+// EnsureIndexes creates the unique index on the normalized query key used for prefix lookups
+func (r *SearchQueriesRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "key", Value: 1}},
+		Options: options.Index().SetName("query_key").SetUnique(true),
+	})
+	return err
+}
+
+// Record logs a search, bumping its count and last-used time if it was run before
+func (r *SearchQueriesRepository) Record(ctx context.Context, query string) error {
+	query = strings.Join(strings.Fields(query), " ")
+	if query == "" {
+		return nil
+	}
+
+	opts := options.Update().SetUpsert(true)
+	_, err := r.collection.UpdateOne(
+		ctx,
+		bson.M{"key": strings.ToLower(query)},
+		bson.M{
+			"$set": bson.M{"query": query, "last_used": time.Now()},
+			"$inc": bson.M{"count": 1},
+		},
+		opts,
+	)
+	return err
+}
+
+// FindByPrefix retrieves logged queries starting with the prefix, most recently used first
+func (r *SearchQueriesRepository) FindByPrefix(ctx context.Context, prefix string, limit int) ([]models.SearchQuery, error) {
+	key := strings.ToLower(strings.Join(strings.Fields(prefix), " "))
+	opts := options.Find().
+		SetSort(bson.M{"last_used": -1}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, bson.M{"key": bson.M{"$regex": "^" + regexp.QuoteMeta(key)}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var queries []models.SearchQuery
+	if err = cursor.All(ctx, &queries); err != nil {
+		return nil, err
+	}
+
+	if queries == nil {
+		queries = []models.SearchQuery{}
+	}
+
+	return queries, nil
+}
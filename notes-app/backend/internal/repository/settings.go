@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Settings documents
+const (
+	personaID           = "assistant_persona"    // The assistant persona
+	pipelinesID         = "processing_pipelines" // The note processing pipelines
+	searchIndexCursorID = "search_index_cursor"  // The last note event synced to the search index
+)
+
+// SettingsRepository provides database operations for application-wide settings, one document per setting
+type SettingsRepository struct {
+	collection *mongo.Collection
+}
+
+// NewSettingsRepository creates a new SettingsRepository
+func NewSettingsRepository(db *mongo.Database) *SettingsRepository {
+	return &SettingsRepository{
+		collection: db.Collection("settings"),
+	}
+}
+
+// GetPersona retrieves the assistant persona, returning nil when none has been saved
+func (r *SettingsRepository) GetPersona(ctx context.Context) (*models.AssistantPersona, error) {
+	var persona models.AssistantPersona
+	err := r.collection.FindOne(ctx, bson.M{"_id": personaID}).Decode(&persona)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	persona.Custom = true
+	return &persona, nil
+}
+
+// SavePersona stores the assistant persona, replacing any earlier one
+func (r *SettingsRepository) SavePersona(ctx context.Context, persona *models.AssistantPersona) error {
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": personaID}, persona, options.Replace().SetUpsert(true))
+	return err
+}
+
+// DeletePersona removes the assistant persona so the default applies again
+func (r *SettingsRepository) DeletePersona(ctx context.Context) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": personaID})
+	return err
+}
+
+// GetPipelines retrieves the processing pipeline settings, returning nil when none have been saved
+func (r *SettingsRepository) GetPipelines(ctx context.Context) (*models.PipelineSettings, error) {
+	var pipelines models.PipelineSettings
+	err := r.collection.FindOne(ctx, bson.M{"_id": pipelinesID}).Decode(&pipelines)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	pipelines.Custom = true
+	return &pipelines, nil
+}
+
+// SavePipelines stores the processing pipeline settings, replacing any earlier ones
+func (r *SettingsRepository) SavePipelines(ctx context.Context, pipelines *models.PipelineSettings) error {
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": pipelinesID}, pipelines, options.Replace().SetUpsert(true))
+	return err
+}
+
+// DeletePipelines removes the processing pipeline settings so the default pipeline applies again
+func (r *SettingsRepository) DeletePipelines(ctx context.Context) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": pipelinesID})
+	return err
+}
+
+// GetSearchIndexCursor retrieves the ID of the last note event synced to the search index,
+// reporting false when none has been saved
+func (r *SettingsRepository) GetSearchIndexCursor(ctx context.Context) (primitive.ObjectID, bool, error) {
+	var doc struct {
+		Cursor primitive.ObjectID `bson:"cursor"`
+	}
+	err := r.collection.FindOne(ctx, bson.M{"_id": searchIndexCursorID}).Decode(&doc)
+	if err == mongo.ErrNoDocuments {
+		return primitive.NilObjectID, false, nil
+	}
+	if err != nil {
+		return primitive.NilObjectID, false, err
+	}
+	return doc.Cursor, true, nil
+}
+
+// SaveSearchIndexCursor stores the ID of the last note event synced to the search index
+func (r *SettingsRepository) SaveSearchIndexCursor(ctx context.Context, cursor primitive.ObjectID) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": searchIndexCursorID}, bson.M{"$set": bson.M{"cursor": cursor}}, options.Update().SetUpsert(true))
+	return err
+}
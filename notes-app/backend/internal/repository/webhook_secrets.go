@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// WebhookSecretsRepository provides database operations for webhook signing secrets and outbound
+// credentials, kept apart from the webhooks themselves
+type WebhookSecretsRepository struct {
+	collection *mongo.Collection
+}
+
+// NewWebhookSecretsRepository creates a new WebhookSecretsRepository
+func NewWebhookSecretsRepository(db *mongo.Database) *WebhookSecretsRepository {
+	return &WebhookSecretsRepository{
+		collection: db.Collection("webhook_secrets"),
+	}
+}
+
+// Save stores a webhook's secrets, replacing any saved before
+func (r *WebhookSecretsRepository) Save(ctx context.Context, secrets *models.WebhookSecrets) error {
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": secrets.WebhookID}, secrets, options.Replace().SetUpsert(true))
+	return err
+}
+
+// FindByWebhook retrieves a webhook's secrets, or nil when none are stored
+func (r *WebhookSecretsRepository) FindByWebhook(ctx context.Context, webhookID primitive.ObjectID) (*models.WebhookSecrets, error) {
+	var secrets models.WebhookSecrets
+	err := r.collection.FindOne(ctx, bson.M{"_id": webhookID}).Decode(&secrets)
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &secrets, nil
+}
+
+// Delete removes a webhook's secrets
+func (r *WebhookSecretsRepository) Delete(ctx context.Context, webhookID primitive.ObjectID) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": webhookID})
+	return err
+}
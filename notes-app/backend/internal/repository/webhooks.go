@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// WebhooksRepository provides database operations for webhook subscriptions
+type WebhooksRepository struct {
+	collection *mongo.Collection
+}
+
+// NewWebhooksRepository creates a new WebhooksRepository
+func NewWebhooksRepository(db *mongo.Database) *WebhooksRepository {
+	return &WebhooksRepository{
+		collection: db.Collection("webhooks"),
+	}
+}
+
+// Create inserts a new webhook
+func (r *WebhooksRepository) Create(ctx context.Context, webhook *models.Webhook) (primitive.ObjectID, error) {
+	result, err := r.collection.InsertOne(ctx, webhook)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	return result.InsertedID.(primitive.ObjectID), nil
+}
+
+// FindAll retrieves all webhooks
+func (r *WebhooksRepository) FindAll(ctx context.Context) ([]models.Webhook, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var webhooks []models.Webhook
+	if err = cursor.All(ctx, &webhooks); err != nil {
+		return nil, err
+	}
+
+	if webhooks == nil {
+		webhooks = []models.Webhook{}
+	}
+
+	return webhooks, nil
+}
+
+// FindByID retrieves a single webhook by its ID
+func (r *WebhooksRepository) FindByID(ctx context.Context, id primitive.ObjectID) (*models.Webhook, error) {
+	var webhook models.Webhook
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&webhook); err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// SetAuthType records how a webhook's deliveries authenticate and drops the signing secret kept
+// in the webhook itself, now that it lives with the webhook's secrets
+func (r *WebhooksRepository) SetAuthType(ctx context.Context, id primitive.ObjectID, authType string) (bool, error) {
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set":   bson.M{"auth_type": authType},
+		"$unset": bson.M{"secret": ""},
+	})
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// SetCursor records delivery progress and clears the last error
+// A nil deliveredAt leaves the last delivery time unchanged, as when rewinding for a replay
+func (r *WebhooksRepository) SetCursor(ctx context.Context, id, cursor primitive.ObjectID, deliveredAt *time.Time) (bool, error) {
+	set := bson.M{"cursor": cursor}
+	if deliveredAt != nil {
+		set["last_delivered_at"] = deliveredAt
+	}
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set":   set,
+		"$unset": bson.M{"last_error": ""},
+	})
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// SetError records why the latest delivery failed
+func (r *WebhooksRepository) SetError(ctx context.Context, id primitive.ObjectID, message string) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"last_error": message}})
+	return err
+}
+
+// Delete removes a webhook by ID
+// Returns the number of deleted documents
+func (r *WebhooksRepository) Delete(ctx context.Context, id primitive.ObjectID) (int64, error) {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
@@ -0,0 +1,113 @@
+package searchindex
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"backend/internal/config"
+)
+
+// Meilisearch is a Client backed by a Meilisearch server
+// Index writes are queued as tasks by Meilisearch and applied in order, so calls return
+// before the change is searchable.
+type Meilisearch struct {
+	api *apiClient
+}
+
+// NewMeilisearch creates a client for the Meilisearch server at baseURL
+// apiKey may be empty for an unsecured server
+func NewMeilisearch(baseURL, apiKey string) *Meilisearch {
+	api := newAPIClient(baseURL)
+	if apiKey != "" {
+		api.header.Set("Authorization", "Bearer "+apiKey)
+	}
+	return &Meilisearch{api: api}
+}
+
+// EnsureIndex creates the notes index and sets its searchable and filterable attributes
+func (m *Meilisearch) EnsureIndex(ctx context.Context) error {
+	// Creating an existing index fails asynchronously as a task, not here
+	err := m.api.do(ctx, http.MethodPost, "/indexes", map[string]string{
+		"uid":        config.SEARCH_INDEX_NAME,
+		"primaryKey": "id",
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create index: %w", err)
+	}
+
+	err = m.api.do(ctx, http.MethodPatch, m.indexPath("/settings"), map[string][]string{
+		"searchableAttributes": {"title", "tags", "summary", "author", "content"},
+		"filterableAttributes": {"archived", "tags", "category"},
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to update index settings: %w", err)
+	}
+	return nil
+}
+
+// Upsert adds or replaces documents
+func (m *Meilisearch) Upsert(ctx context.Context, docs []Document) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	return m.api.do(ctx, http.MethodPost, m.indexPath("/documents?primaryKey=id"), docs, nil)
+}
+
+// Delete removes documents by ID
+func (m *Meilisearch) Delete(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return m.api.do(ctx, http.MethodPost, m.indexPath("/documents/delete-batch"), ids, nil)
+}
+
+// Search runs a keyword search, returning matching IDs best first
+func (m *Meilisearch) Search(ctx context.Context, query Query) ([]string, error) {
+	var filters []string
+	if !query.IncludeArchived {
+		filters = append(filters, "archived = false")
+	}
+	for _, tag := range query.Tags {
+		filters = append(filters, "tags = "+meiliString(tag))
+	}
+	for _, category := range query.ExcludeCategories {
+		filters = append(filters, "category != "+meiliString(category))
+	}
+
+	body := map[string]interface{}{
+		"q":                    query.Text,
+		"limit":                query.Limit,
+		"attributesToRetrieve": []string{"id"},
+	}
+	if len(filters) > 0 {
+		body["filter"] = filters // Array entries are ANDed
+	}
+
+	var resp struct {
+		Hits []struct {
+			ID string `json:"id"`
+		} `json:"hits"`
+	}
+	if err := m.api.do(ctx, http.MethodPost, m.indexPath("/search"), body, &resp); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(resp.Hits))
+	for _, hit := range resp.Hits {
+		ids = append(ids, hit.ID)
+	}
+	return ids, nil
+}
+
+// indexPath returns the API path of the notes index plus suffix
+func (m *Meilisearch) indexPath(suffix string) string {
+	return "/indexes/" + config.SEARCH_INDEX_NAME + suffix
+}
+
+// meiliString quotes a filter value
+func meiliString(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	return `"` + strings.ReplaceAll(value, `"`, `\"`) + `"`
+}
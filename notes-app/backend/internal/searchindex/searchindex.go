@@ -0,0 +1,174 @@
+// Package searchindex mirrors notes into an external keyword search engine
+// (Meilisearch or Typesense) for fast, typo-tolerant search as the user types.
+package searchindex
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+)
+
+// Document is the indexed form of a note
+type Document struct {
+	ID       string   `json:"id"`
+	Title    string   `json:"title"`
+	Summary  string   `json:"summary"`
+	Content  string   `json:"content"`
+	Category string   `json:"category"`
+	Tags     []string `json:"tags"`
+	Author   string   `json:"author"`
+	Archived bool     `json:"archived"`
+	Created  int64    `json:"created"` // Unix seconds
+}
+
+// Query is a keyword search against the index
+type Query struct {
+	Text              string
+	Limit             int
+	Tags              []string // Documents must carry all of these
+	ExcludeCategories []string
+	IncludeArchived   bool
+}
+
+// Client is a search engine holding a mirror of the notes collection
+type Client interface {
+	// EnsureIndex creates the index and its settings if they don't exist yet
+	EnsureIndex(ctx context.Context) error
+
+	// Upsert adds or replaces documents by ID
+	Upsert(ctx context.Context, docs []Document) error
+
+	// Delete removes documents by ID; missing IDs are ignored
+	Delete(ctx context.Context, ids []string) error
+
+	// Search returns the IDs of matching documents, best match first
+	Search(ctx context.Context, query Query) ([]string, error)
+}
+
+// NewClient creates a client for the configured provider
+// Returns nil when no provider is configured, so the mirror is simply disabled
+func NewClient(cfg *config.Config) (Client, error) {
+	switch cfg.SearchIndexProvider {
+	case "":
+		return nil, nil
+	case "meilisearch", "typesense":
+		if cfg.SearchIndexURL == "" {
+			return nil, fmt.Errorf("SEARCH_INDEX_URL is required for %s", cfg.SearchIndexProvider)
+		}
+	default:
+		return nil, fmt.Errorf("unknown search index provider %q, must be meilisearch or typesense", cfg.SearchIndexProvider)
+	}
+
+	if cfg.SearchIndexProvider == "typesense" {
+		return NewTypesense(cfg.SearchIndexURL, cfg.SearchIndexAPIKey), nil
+	}
+	return NewMeilisearch(cfg.SearchIndexURL, cfg.SearchIndexAPIKey), nil
+}
+
+// DocumentFromNote converts a note to its indexed form
+func DocumentFromNote(note *models.Note) Document {
+	tags := note.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	author, _ := note.Metadata["author"].(string)
+
+	return Document{
+		ID:       note.ID.Hex(),
+		Title:    note.Title,
+		Summary:  note.Summary,
+		Content:  note.Content,
+		Category: note.Category,
+		Tags:     tags,
+		Author:   author,
+		Archived: note.Archived,
+		Created:  note.Created.Unix(),
+	}
+}
+
+// apiClient sends requests to a search engine's HTTP API
+type apiClient struct {
+	baseURL string
+	header  http.Header
+	client  *http.Client
+}
+
+// newAPIClient creates an apiClient for the server at baseURL
+func newAPIClient(baseURL string) *apiClient {
+	return &apiClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		header:  http.Header{},
+		client:  &http.Client{Timeout: config.SEARCH_INDEX_TIMEOUT_SECONDS * time.Second},
+	}
+}
+
+// statusError is returned for non-2xx responses
+type statusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("search index returned status %d: %s", e.StatusCode, e.Body)
+}
+
+// do sends a request and reads the response into out when non-nil
+// body is sent as-is when it's an io.Reader and JSON-encoded otherwise; likewise the response
+// is copied to out when it's an io.Writer and JSON-decoded into it otherwise
+func (c *apiClient) do(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	contentType := "application/json"
+	switch b := body.(type) {
+	case nil:
+	case io.Reader:
+		reader = b
+		contentType = "text/plain"
+	default:
+		encoded, err := json.Marshal(b)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	for key, values := range c.header {
+		req.Header[key] = values
+	}
+	if reader != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &statusError{StatusCode: resp.StatusCode, Body: string(snippet)}
+	}
+	switch o := out.(type) {
+	case nil:
+		return nil
+	case io.Writer:
+		_, err := io.Copy(o, resp.Body)
+		return err
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode search index response: %w", err)
+	}
+	return nil
+}
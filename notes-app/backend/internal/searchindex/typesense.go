@@ -0,0 +1,169 @@
+package searchindex
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"backend/internal/config"
+)
+
+// typesenseMaxPerPage is the largest page Typesense returns from a search
+const typesenseMaxPerPage = 250
+
+// Typesense is a Client backed by a Typesense server
+type Typesense struct {
+	api *apiClient
+}
+
+// NewTypesense creates a Typesense client for the server at baseURL
+func NewTypesense(baseURL, apiKey string) *Typesense {
+	api := newAPIClient(baseURL)
+	api.header.Set("X-TYPESENSE-API-KEY", apiKey)
+	return &Typesense{api: api}
+}
+
+// EnsureIndex creates the notes collection; an existing collection is left as is
+func (t *Typesense) EnsureIndex(ctx context.Context) error {
+	schema := map[string]interface{}{
+		"name": config.SEARCH_INDEX_NAME,
+		"fields": []map[string]interface{}{
+			{"name": "title", "type": "string"},
+			{"name": "summary", "type": "string"},
+			{"name": "content", "type": "string"},
+			{"name": "category", "type": "string", "facet": true},
+			{"name": "tags", "type": "string[]", "facet": true},
+			{"name": "author", "type": "string"},
+			{"name": "archived", "type": "bool"},
+			{"name": "created", "type": "int64"},
+		},
+		"default_sorting_field": "created",
+	}
+
+	err := t.api.do(ctx, http.MethodPost, "/collections", schema, nil)
+	var statusErr *statusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusConflict {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create collection: %w", err)
+	}
+	return nil
+}
+
+// Upsert adds or replaces documents through the JSONL import endpoint
+// The import responds 200 even when documents are rejected, so each result line is checked
+func (t *Typesense) Upsert(ctx context.Context, docs []Document) error {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for i := range docs {
+		if err := encoder.Encode(&docs[i]); err != nil {
+			return fmt.Errorf("failed to encode document %s: %w", docs[i].ID, err)
+		}
+	}
+
+	var results bytes.Buffer
+	if err := t.api.do(ctx, http.MethodPost, t.collectionPath("/documents/import?action=upsert"), &body, &results); err != nil {
+		return err
+	}
+
+	failed := 0
+	var firstErr string
+	scanner := bufio.NewScanner(&results)
+	for scanner.Scan() {
+		var result struct {
+			Success bool   `json:"success"`
+			Error   string `json:"error"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &result); err != nil || result.Success {
+			continue
+		}
+		if failed == 0 {
+			firstErr = result.Error
+		}
+		failed++
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d documents rejected: %s", failed, len(docs), firstErr)
+	}
+	return nil
+}
+
+// Delete removes documents by ID
+func (t *Typesense) Delete(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	filter := "id:[" + strings.Join(ids, ",") + "]"
+	return t.api.do(ctx, http.MethodDelete, t.collectionPath("/documents?filter_by="+url.QueryEscape(filter)), nil, nil)
+}
+
+// Search runs a keyword search, returning matching IDs best first
+func (t *Typesense) Search(ctx context.Context, query Query) ([]string, error) {
+	var filters []string
+	if !query.IncludeArchived {
+		filters = append(filters, "archived:=false")
+	}
+	for _, tag := range query.Tags {
+		filters = append(filters, "tags:="+typesenseString(tag))
+	}
+	if len(query.ExcludeCategories) > 0 {
+		quoted := make([]string, len(query.ExcludeCategories))
+		for i, category := range query.ExcludeCategories {
+			quoted[i] = typesenseString(category)
+		}
+		filters = append(filters, "category:!=["+strings.Join(quoted, ",")+"]")
+	}
+
+	limit := query.Limit
+	if limit > typesenseMaxPerPage {
+		limit = typesenseMaxPerPage
+	}
+
+	params := url.Values{}
+	params.Set("q", query.Text)
+	params.Set("query_by", "title,tags,summary,author,content")
+	params.Set("per_page", strconv.Itoa(limit))
+	params.Set("include_fields", "id")
+	if len(filters) > 0 {
+		params.Set("filter_by", strings.Join(filters, " && "))
+	}
+
+	var resp struct {
+		Hits []struct {
+			Document struct {
+				ID string `json:"id"`
+			} `json:"document"`
+		} `json:"hits"`
+	}
+	if err := t.api.do(ctx, http.MethodGet, t.collectionPath("/documents/search?"+params.Encode()), nil, &resp); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(resp.Hits))
+	for _, hit := range resp.Hits {
+		ids = append(ids, hit.Document.ID)
+	}
+	return ids, nil
+}
+
+// collectionPath returns the API path of the notes collection plus suffix
+func (t *Typesense) collectionPath(suffix string) string {
+	return "/collections/" + config.SEARCH_INDEX_NAME + suffix
+}
+
+// typesenseString quotes a filter value with backticks, which can't themselves be escaped
+func typesenseString(value string) string {
+	return "`" + strings.ReplaceAll(value, "`", "") + "`"
+}
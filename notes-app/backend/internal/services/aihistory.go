@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+	"unicode/utf8"
+
+	"backend/internal/ai"
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// AICall describes an AI operation on a note, for its AI history
+type AICall struct {
+	Operation    string
+	Content      string      // Note content sent; operations that read only the start of a note are clipped as the prompt clips it
+	PromptText   string      // The channel's custom prompt, when used
+	PromptSchema string      // The channel's custom schema, when used
+	Output       interface{} // The reply: a string, or a result that is stored as JSON
+	Err          error
+}
+
+// aiCallPromptTokens are the instructions each operation adds to the note content
+var aiCallPromptTokens = map[string]int{
+	models.AIOperationAnalysis:       config.ANALYSIS_PROMPT_TOKENS,
+	models.AIOperationClassification: config.CLASSIFY_PROMPT_TOKENS,
+	models.AIOperationTitle:          config.TITLE_PROMPT_TOKENS,
+	models.AIOperationSummary:        config.SUMMARY_PROMPT_TOKENS,
+	models.AIOperationSummaryPatch:   config.SUMMARY_PATCH_PROMPT_TOKENS,
+	models.AIOperationTranslation:    config.TRANSLATION_PROMPT_TOKENS,
+}
+
+// aiCallContentChars are the operations that send only the start of a note's content
+var aiCallContentChars = map[string]int{
+	models.AIOperationAnalysis: config.ANALYSIS_CONTENT_CHARS,
+	models.AIOperationTitle:    config.TITLE_CONTENT_CHARS,
+}
+
+// AIHistoryService records the AI operations performed on notes
+// A nil *AIHistoryService records nothing, so callers needn't check whether history is kept.
+type AIHistoryService struct {
+	aiOperationsRepo *repository.AIOperationsRepository
+	notesRepo        *repository.NotesRepository
+	cfg              *config.Config
+}
+
+// NewAIHistoryService creates a new AIHistoryService
+func NewAIHistoryService(aiOperationsRepo *repository.AIOperationsRepository, notesRepo *repository.NotesRepository, cfg *config.Config) *AIHistoryService {
+	return &AIHistoryService{
+		aiOperationsRepo: aiOperationsRepo,
+		notesRepo:        notesRepo,
+		cfg:              cfg,
+	}
+}
+
+// Record adds calls to a note's AI history; failing to record is logged, never returned, so the
+// operation itself isn't failed by its bookkeeping
+func (s *AIHistoryService) Record(ctx context.Context, noteID primitive.ObjectID, calls ...AICall) {
+	if s == nil {
+		return
+	}
+	for _, call := range calls {
+		op := s.operation(noteID, call)
+		if _, err := s.aiOperationsRepo.Create(ctx, op); err != nil {
+			log.Printf("Failed to record %s of note %s in its AI history: %v", call.Operation, noteID.Hex(), err)
+		}
+	}
+}
+
+// operation builds the history entry of a call, estimating its token usage like GET /admin/estimates
+func (s *AIHistoryService) operation(noteID primitive.ObjectID, call AICall) *models.AIOperation {
+	content := call.Content
+	if limit, ok := aiCallContentChars[call.Operation]; ok && len(content) > limit {
+		content = content[:limit]
+	}
+
+	op := &models.AIOperation{
+		NoteID:        noteID,
+		Operation:     call.Operation,
+		Model:         s.cfg.GenerationModel,
+		PromptVersion: ai.PromptVersions[call.Operation],
+		InputTokens:   ai.EstimateTokens(content) + ai.EstimateTokens(call.PromptText) + ai.EstimateTokens(call.PromptSchema) + aiCallPromptTokens[call.Operation],
+		CreatedAt:     time.Now(),
+	}
+	if call.PromptText != "" || call.PromptSchema != "" {
+		sum := sha256.Sum256([]byte(call.PromptText + "\x00" + call.PromptSchema))
+		op.PromptHash = hex.EncodeToString(sum[:8])
+	}
+	if call.Err != nil {
+		op.Error = call.Err.Error()
+		return op
+	}
+
+	output, ok := call.Output.(string)
+	if !ok && call.Output != nil {
+		if data, err := json.Marshal(call.Output); err == nil {
+			output = string(data)
+		}
+	}
+	op.OutputTokens = ai.EstimateTokens(output)
+	if utf8.RuneCountInString(output) > config.AI_HISTORY_OUTPUT_CHARS {
+		output = string([]rune(output)[:config.AI_HISTORY_OUTPUT_CHARS]) + "..."
+	}
+	op.Output = output
+	return op
+}
+
+// newGeneration returns the provenance of a field the generation model produced just now
+func newGeneration(cfg *config.Config, operation string) *models.GenerationInfo {
+	return &models.GenerationInfo{
+		Model:         cfg.GenerationModel,
+		Operation:     operation,
+		PromptVersion: ai.PromptVersions[operation],
+		GeneratedAt:   time.Now(),
+	}
+}
+
+// outdatedFilter matches notes whose generated field (e.g. "summary_generation") wasn't produced
+// by the current generation model with the current version of its prompt, including fields saved
+// without provenance
+func outdatedFilter(cfg *config.Config, field string) bson.M {
+	outdated := []bson.M{
+		{field: bson.M{"$exists": false}},
+		{field + ".model": bson.M{"$ne": cfg.GenerationModel}},
+	}
+	for operation, version := range ai.PromptVersions {
+		outdated = append(outdated, bson.M{field + ".operation": operation, field + ".prompt_version": bson.M{"$lt": version}})
+	}
+	return bson.M{"$or": outdated}
+}
+
+// GetHistory returns a note's most recent AI operations, newest first
+func (s *AIHistoryService) GetHistory(ctx context.Context, noteID string) ([]models.AIOperation, error) {
+	objID, err := primitive.ObjectIDFromHex(noteID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid note ID: %w", err)
+	}
+	if _, err := s.notesRepo.FindByID(ctx, objID); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("note not found")
+		}
+		return nil, fmt.Errorf("failed to find note: %w", err)
+	}
+
+	ops, err := s.aiOperationsRepo.FindByNote(ctx, objID, config.AI_HISTORY_LIMIT)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get AI history: %w", err)
+	}
+	return ops, nil
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// AISampleService keeps a sample of raw AI generation calls, so bad generations can be debugged
+// without reproducing them
+type AISampleService struct {
+	aiSamplesRepo *repository.AISamplesRepository
+	rate          float64
+}
+
+// NewAISampleService creates a new AISampleService keeping the given fraction of calls
+func NewAISampleService(aiSamplesRepo *repository.AISamplesRepository, rate float64) *AISampleService {
+	return &AISampleService{
+		aiSamplesRepo: aiSamplesRepo,
+		rate:          rate,
+	}
+}
+
+// Sample keeps a generation call with the service's sampling rate, redacting secrets from its
+// prompt and reply; it is given to ai.AIClient.SetSampler. Failing to store a sample is logged,
+// never returned, so the call itself isn't failed by its bookkeeping.
+func (s *AISampleService) Sample(sample models.AISample) {
+	if s.rate <= 0 || rand.Float64() >= s.rate {
+		return
+	}
+
+	sample.ID = primitive.NilObjectID
+	sample.Prompt = clipSampleText(utils.RedactSensitiveData(sample.Prompt))
+	sample.Response = clipSampleText(utils.RedactSensitiveData(sample.Response))
+	sample.Error = utils.RedactSensitiveData(sample.Error)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	id, err := s.aiSamplesRepo.Create(ctx, &sample)
+	if err != nil {
+		log.Printf("Failed to store AI sample of %s: %v", sample.Operation, err)
+		return
+	}
+	log.Printf("Stored AI sample %s of %s", id.Hex(), sample.Operation)
+}
+
+// GetSamples retrieves the most recent samples, newest first, optionally of a single operation
+func (s *AISampleService) GetSamples(ctx context.Context, operation string, limit int) ([]models.AISample, error) {
+	filter := bson.M{}
+	if operation = strings.TrimSpace(operation); operation != "" {
+		filter["operation"] = operation
+	}
+
+	samples, err := s.aiSamplesRepo.FindRecent(ctx, filter, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get AI samples: %w", err)
+	}
+	return samples, nil
+}
+
+// GetSample retrieves a single sample by ID
+func (s *AISampleService) GetSample(ctx context.Context, sampleID string) (*models.AISample, error) {
+	objID, err := primitive.ObjectIDFromHex(sampleID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sample ID: %w", err)
+	}
+
+	sample, err := s.aiSamplesRepo.FindByID(ctx, objID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("AI sample not found")
+		}
+		return nil, fmt.Errorf("failed to find AI sample: %w", err)
+	}
+	return sample, nil
+}
+
+// clipSampleText cuts text off after AI_SAMPLE_MAX_CHARS, keeping whole characters
+func clipSampleText(text string) string {
+	if utf8.RuneCountInString(text) <= config.AI_SAMPLE_MAX_CHARS {
+		return text
+	}
+	return string([]rune(text)[:config.AI_SAMPLE_MAX_CHARS]) + "..."
+}
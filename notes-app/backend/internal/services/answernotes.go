@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"backend/internal/config"
+	"backend/internal/models"
+)
+
+// AnswerNotesService saves Q&A exchanges as notes, so synthesized answers become searchable and can
+// inform later answers
+type AnswerNotesService struct {
+	notesService *NotesService
+}
+
+// NewAnswerNotesService creates a new AnswerNotesService
+func NewAnswerNotesService(notesService *NotesService) *AnswerNotesService {
+	return &AnswerNotesService{
+		notesService: notesService,
+	}
+}
+
+// SaveAnswer creates a note in ANSWER_CATEGORY holding the question, the answer and the notes it
+// cites, and records its ID on the response. Answers drawn from no notes aren't saved.
+func (s *AnswerNotesService) SaveAnswer(ctx context.Context, response *models.QuestionResponse) (*models.Note, error) {
+	if len(response.Sources) == 0 {
+		return nil, nil
+	}
+
+	var content strings.Builder
+	fmt.Fprintf(&content, "Question: %s\n\nAnswer:\n%s\n\nSources:\n", response.Question, response.Answer)
+	sourceIDs := make([]interface{}, 0, len(response.Sources))
+	for _, source := range response.Sources {
+		fmt.Fprintf(&content, "- %s (%s)\n", source.Note.Title, source.Note.ID.Hex())
+		sourceIDs = append(sourceIDs, source.Note.ID.Hex())
+	}
+
+	result, err := s.notesService.CreateNote(ctx, &models.CreateNoteRequest{
+		Title:    answerNoteTitle(response.Question),
+		Content:  content.String(),
+		Category: config.ANSWER_CATEGORY,
+		Metadata: map[string]interface{}{"source": "ask"},
+		StructuredData: map[string]interface{}{
+			"question":      response.Question,
+			"sourceNoteIds": sourceIDs,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save answer: %w", err)
+	}
+
+	response.NoteID = result.Note.ID.Hex()
+	return result.Note, nil
+}
+
+// answerNoteTitle titles a saved answer after its question, shortened to MAX_ANSWER_TITLE_CHARS
+func answerNoteTitle(question string) string {
+	title := []rune(strings.Join(strings.Fields(question), " "))
+	if len(title) > config.MAX_ANSWER_TITLE_CHARS {
+		return "Q: " + strings.TrimSpace(string(title[:config.MAX_ANSWER_TITLE_CHARS])) + "..."
+	}
+	return "Q: " + string(title)
+}
@@ -0,0 +1,247 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// AutomationService manages automation rules and works out which of them a new note triggers
+type AutomationService struct {
+	automationRepo *repository.AutomationRulesRepository
+	presetService  *PresetService
+}
+
+// NewAutomationService creates a new AutomationService
+func NewAutomationService(automationRepo *repository.AutomationRulesRepository, presetService *PresetService) *AutomationService {
+	return &AutomationService{
+		automationRepo: automationRepo,
+		presetService:  presetService,
+	}
+}
+
+// GetRules retrieves all automation rules, oldest first
+func (s *AutomationService) GetRules(ctx context.Context) ([]models.AutomationRule, error) {
+	return s.automationRepo.FindAll(ctx, bson.M{})
+}
+
+// GetRuleByID retrieves a single automation rule by ID
+func (s *AutomationService) GetRuleByID(ctx context.Context, ruleID string) (*models.AutomationRule, error) {
+	objID, err := primitive.ObjectIDFromHex(ruleID)
+	if err != nil {
+		return nil, fmt.Errorf("automation rule not found")
+	}
+
+	rule, err := s.automationRepo.FindByID(ctx, objID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("automation rule not found")
+		}
+		return nil, fmt.Errorf("failed to find automation rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+// CreateRule validates and stores a new automation rule
+func (s *AutomationService) CreateRule(ctx context.Context, rule *models.AutomationRule) (*models.AutomationRule, error) {
+	if err := s.normalizeRule(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	rule.ID = primitive.NilObjectID
+	rule.UpdatedAt = time.Now()
+
+	id, err := s.automationRepo.Create(ctx, rule)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create automation rule: %w", err)
+	}
+	rule.ID = id
+
+	return rule, nil
+}
+
+// UpdateRule validates and replaces an existing automation rule
+func (s *AutomationService) UpdateRule(ctx context.Context, ruleID string, rule *models.AutomationRule) (*models.AutomationRule, error) {
+	objID, err := primitive.ObjectIDFromHex(ruleID)
+	if err != nil {
+		return nil, fmt.Errorf("automation rule not found")
+	}
+
+	if err := s.normalizeRule(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	rule.ID = objID
+	rule.UpdatedAt = time.Now()
+
+	matched, err := s.automationRepo.Replace(ctx, objID, rule)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update automation rule: %w", err)
+	}
+	if matched == 0 {
+		return nil, fmt.Errorf("automation rule not found")
+	}
+
+	return rule, nil
+}
+
+// DeleteRule removes an automation rule by ID
+func (s *AutomationService) DeleteRule(ctx context.Context, ruleID string) error {
+	objID, err := primitive.ObjectIDFromHex(ruleID)
+	if err != nil {
+		return fmt.Errorf("automation rule not found")
+	}
+
+	deleted, err := s.automationRepo.Delete(ctx, objID)
+	if err != nil {
+		return fmt.Errorf("failed to delete automation rule: %w", err)
+	}
+	if deleted == 0 {
+		return fmt.Errorf("automation rule not found")
+	}
+
+	return nil
+}
+
+// Plan combines the actions of the enabled rules triggered by a new note's tags, category and
+// channel, or returns nil when none is. Failing to load the rules, or a preset removed since its
+// rule was saved, is logged rather than returned so the note is still processed.
+func (s *AutomationService) Plan(ctx context.Context, note *models.Note) *models.AutomationPlan {
+	rules, err := s.automationRepo.FindEnabled(ctx)
+	if err != nil {
+		log.Printf("Failed to load automation rules for note %s: %v", note.ID.Hex(), err)
+		return nil
+	}
+
+	author, _ := note.Metadata["author"].(string)
+	channelKey := utils.NormalizeChannelKey(author)
+	var plan *models.AutomationPlan
+	for _, rule := range rules {
+		if !automationTriggered(&rule, note.Tags, note.Category, channelKey) {
+			continue
+		}
+		if plan == nil {
+			plan = &models.AutomationPlan{}
+		}
+		plan.Rules = append(plan.Rules, rule.Name)
+
+		for _, action := range rule.Actions {
+			switch action.Type {
+			case models.AutomationActionRunStep:
+				if !slices.Contains(plan.Steps, action.Value) {
+					plan.Steps = append(plan.Steps, action.Value)
+				}
+			case models.AutomationActionApplyPreset:
+				if plan.Preset != "" {
+					log.Printf("Automation rule '%s' would summarize note %s with preset %q, keeping preset %q", rule.Name, note.ID.Hex(), action.Value, plan.Preset)
+					continue
+				}
+				preset, err := s.presetService.GetPreset(ctx, action.Value)
+				if err != nil {
+					log.Printf("Skipping preset %q of automation rule '%s': %v", action.Value, rule.Name, err)
+					continue
+				}
+				plan.Preset = preset.Name
+				plan.PromptText = preset.PromptText
+				plan.PromptSchema = preset.PromptSchema
+			case models.AutomationActionAddToCollection:
+				if !slices.Contains(plan.Collections, action.Value) {
+					plan.Collections = append(plan.Collections, action.Value)
+				}
+			}
+		}
+	}
+
+	if plan != nil {
+		log.Printf("Automation rules %v matched note %s", plan.Rules, note.ID.Hex())
+	}
+	return plan
+}
+
+// automationTriggered checks whether a new note with the given tags, category and channel key
+// triggers a rule
+func automationTriggered(rule *models.AutomationRule, tags []string, category, channelKey string) bool {
+	switch rule.Trigger {
+	case models.AutomationTriggerTag:
+		return slices.Contains(tags, rule.Value)
+	case models.AutomationTriggerCategory:
+		return category == rule.Value
+	case models.AutomationTriggerChannel:
+		return channelKey != "" && utils.NormalizeChannelKey(rule.Value) == channelKey
+	}
+	return false
+}
+
+// normalizeRule validates an automation rule and cleans up its fields
+func (s *AutomationService) normalizeRule(ctx context.Context, rule *models.AutomationRule) error {
+	rule.Name = strings.TrimSpace(rule.Name)
+	rule.Trigger = strings.ToLower(strings.TrimSpace(rule.Trigger))
+	rule.Value = strings.TrimSpace(rule.Value)
+
+	if rule.Name == "" {
+		return fmt.Errorf("invalid automation rule: name is required")
+	}
+
+	switch rule.Trigger {
+	case models.AutomationTriggerTag:
+		rule.Value = strings.ToLower(rule.Value)
+		if rule.Value == "" {
+			return fmt.Errorf("invalid automation rule: tag triggers require a tag")
+		}
+	case models.AutomationTriggerCategory:
+		rule.Value = strings.ToLower(rule.Value)
+		if !config.IsValidCategory(rule.Value) {
+			return fmt.Errorf("invalid automation rule: unknown category %q", rule.Value)
+		}
+	case models.AutomationTriggerChannel:
+		if utils.NormalizeChannelKey(rule.Value) == "" {
+			return fmt.Errorf("invalid automation rule: channel %q has no letters or digits", rule.Value)
+		}
+	default:
+		return fmt.Errorf("invalid automation rule: trigger must be tag, category or channel")
+	}
+
+	if len(rule.Actions) == 0 {
+		return fmt.Errorf("invalid automation rule: at least one action is required")
+	}
+	for i := range rule.Actions {
+		action := &rule.Actions[i]
+		action.Type = strings.ToLower(strings.TrimSpace(action.Type))
+		action.Value = strings.ToLower(strings.TrimSpace(action.Value))
+
+		switch action.Type {
+		case models.AutomationActionRunStep:
+			if len(workerSteps([]string{action.Value})) == 0 {
+				return fmt.Errorf("invalid automation rule: run_step must name a worker step, %s or %s", models.PipelineExtractEntities, models.PipelineEmbed)
+			}
+		case models.AutomationActionApplyPreset:
+			if _, err := s.presetService.GetPreset(ctx, action.Value); err != nil {
+				if strings.HasPrefix(err.Error(), "preset not found") {
+					return fmt.Errorf("invalid automation rule: %w", err)
+				}
+				return err
+			}
+		case models.AutomationActionAddToCollection:
+			if action.Value == "" {
+				return fmt.Errorf("invalid automation rule: add_to_collection requires a collection")
+			}
+		default:
+			return fmt.Errorf("invalid automation rule: action type must be run_step, apply_preset or add_to_collection")
+		}
+	}
+
+	return nil
+}
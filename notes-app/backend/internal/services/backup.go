@@ -0,0 +1,193 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// WriteJSONBackup writes a complete backup to w in the models.Backup format
+// Notes and chunks are streamed one at a time, so memory use doesn't grow with the collection.
+// Returns the number of notes written.
+func (s *ExportService) WriteJSONBackup(ctx context.Context, w io.Writer, includeChunks bool) (int, error) {
+	settings, err := s.channelSettingsRepo.FindAll(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load channel settings: %w", err)
+	}
+
+	bw := bufio.NewWriter(w)
+	header, err := json.Marshal(struct {
+		Version    int       `json:"version"`
+		ExportedAt time.Time `json:"exportedAt"`
+	}{models.BackupVersion, time.Now()})
+	if err != nil {
+		return 0, err
+	}
+	// Reopen the header object so the arrays can be appended field by field
+	bw.Write(header[:len(header)-1])
+
+	count := 0
+	bw.WriteString(`,"notes":`)
+	opts := options.Find().SetSort(bson.M{"_id": 1})
+	err = writeJSONArray(bw, func(emit func(interface{}) error) error {
+		return s.notesRepo.ForEach(ctx, bson.M{}, func(note *models.Note) error {
+			count++
+			return emit(note)
+		}, opts)
+	})
+	if err != nil {
+		return count, fmt.Errorf("failed to write notes: %w", err)
+	}
+
+	bw.WriteString(`,"channelSettings":`)
+	err = writeJSONArray(bw, func(emit func(interface{}) error) error {
+		for i := range settings {
+			if err := emit(&settings[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return count, fmt.Errorf("failed to write channel settings: %w", err)
+	}
+
+	if includeChunks {
+		bw.WriteString(`,"chunks":`)
+		err = writeJSONArray(bw, func(emit func(interface{}) error) error {
+			return s.chunksRepo.ForEach(ctx, func(chunk *models.NoteChunk) error {
+				return emit(chunk)
+			})
+		})
+		if err != nil {
+			return count, fmt.Errorf("failed to write chunks: %w", err)
+		}
+	}
+
+	bw.WriteString("}\n")
+	return count, bw.Flush()
+}
+
+// RestoreJSONBackup restores notes and channel settings from a backup written by WriteJSONBackup
+// Notes keep their IDs; a note whose ID already exists is skipped rather than overwritten.
+// Channel settings are upserted by platform and channel name. A restored note's chunks in the
+// backup are restored with it and the note isn't re-embedded, so the vectors come back from an
+// embeddings import (POST /admin/embeddings/import) rather than from the AI API. A note the backup
+// has no chunks for is queued for embedding, which rebuilds its chunks and vectors.
+func (s *ExportService) RestoreJSONBackup(ctx context.Context, r io.Reader) (*models.RestoreResponse, error) {
+	var backup models.Backup
+	if err := json.NewDecoder(r).Decode(&backup); err != nil {
+		return nil, fmt.Errorf("invalid backup: %w", err)
+	}
+	if backup.Version < 1 || backup.Version > models.BackupVersion {
+		return nil, fmt.Errorf("invalid backup: unsupported version %d", backup.Version)
+	}
+
+	result := &models.RestoreResponse{}
+
+	chunksByNote := make(map[primitive.ObjectID][]models.NoteChunk)
+	for _, chunk := range backup.Chunks {
+		if chunk.ID.IsZero() || chunk.NoteID.IsZero() {
+			return nil, fmt.Errorf("invalid backup: chunks need an id and a note_id")
+		}
+		chunksByNote[chunk.NoteID] = append(chunksByNote[chunk.NoteID], chunk)
+	}
+
+	for i := range backup.ChannelSettings {
+		settings := &backup.ChannelSettings[i]
+		if settings.ChannelName == "" || settings.Platform == "" {
+			return nil, fmt.Errorf("invalid backup: channel settings %d need a channel name and platform", i)
+		}
+		settings.ID = primitive.NilObjectID // Matched by platform and name, not ID
+		if err := s.channelSettingsRepo.Upsert(ctx, settings); err != nil {
+			return result, fmt.Errorf("failed to restore channel settings: %w", err)
+		}
+		result.ChannelSettingsRestored++
+	}
+
+	for i := range backup.Notes {
+		note := &backup.Notes[i]
+		if note.ID.IsZero() {
+			note.ID = primitive.NewObjectID()
+		}
+		if note.Created.IsZero() {
+			note.Created = time.Now()
+		}
+
+		if _, err := s.notesRepo.Create(ctx, note); err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				result.NotesSkipped++
+				continue
+			}
+			return result, fmt.Errorf("failed to restore note %s: %w", note.ID.Hex(), err)
+		}
+		result.NotesRestored++
+
+		s.webhookService.Emit(ctx, models.NoteEventCreated, note.ID, noteCreatedDelta(note))
+
+		if chunks := chunksByNote[note.ID]; len(chunks) > 0 {
+			for j := range chunks {
+				if err := s.chunksRepo.Upsert(ctx, &chunks[j]); err != nil {
+					return result, fmt.Errorf("failed to restore chunks of note %s: %w", note.ID.Hex(), err)
+				}
+			}
+			result.ChunksRestored += len(chunks)
+			continue
+		}
+		if s.workerPool == nil {
+			continue
+		}
+		err := s.workerPool.Submit(ctx, models.ProcessingJob{
+			NoteID:         note.ID,
+			Title:          note.Title,
+			Content:        note.Content,
+			Category:       note.Category,
+			NoteCreated:    note.Created,
+			Metadata:       note.Metadata,
+			Summary:        note.Summary,
+			StructuredData: note.StructuredData,
+		})
+		if err != nil {
+			return result, err
+		}
+		result.EmbeddingJobsQueued++
+	}
+
+	log.Printf("Restored backup: %d notes (%d skipped), %d chunks, %d channel settings, %d embedding jobs",
+		result.NotesRestored, result.NotesSkipped, result.ChunksRestored, result.ChannelSettingsRestored, result.EmbeddingJobsQueued)
+
+	return result, nil
+}
+
+// writeJSONArray writes the values produced by fill as a JSON array
+func writeJSONArray(w *bufio.Writer, fill func(emit func(interface{}) error) error) error {
+	w.WriteByte('[')
+	first := true
+	err := fill(func(value interface{}) error {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		if !first {
+			w.WriteByte(',')
+		}
+		first = false
+		_, err = w.Write(encoded)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return w.WriteByte(']')
+}
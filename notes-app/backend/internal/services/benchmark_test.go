@@ -0,0 +1,148 @@
+package services
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"backend/internal/vectordb"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Benchmarks for the CPU-bound parts of the service layer. They need no database or AI API:
+//
+//	go test -run '^$' -bench . -benchmem ./internal/services/
+
+var benchWords = strings.Fields("the transformer relies entirely on attention to draw global dependencies " +
+	"between input and output while recurrent models process tokens one step at a time which " +
+	"precludes parallelization within training examples and limits batch sizes across long sequences")
+
+// benchText returns n words of deterministic prose
+func benchText(n int) string {
+	words := make([]string, n)
+	for i := range words {
+		words[i] = benchWords[i%len(benchWords)]
+	}
+	return strings.Join(words, " ")
+}
+
+// benchNotes returns n notes with a mix of the attributes ranking boosts look at
+func benchNotes(n, words int) []models.Note {
+	notes := make([]models.Note, n)
+	for i := range notes {
+		notes[i] = models.Note{
+			ID:        primitive.NewObjectID(),
+			Title:     fmt.Sprintf("Note %d", i),
+			Content:   benchText(words),
+			Pinned:    i%10 == 0,
+			Created:   time.Now().AddDate(0, 0, -i),
+			ViewCount: i % 7,
+			Metadata:  map[string]interface{}{"author": fmt.Sprintf("Channel %d", i%5)},
+		}
+		if i%3 == 0 {
+			notes[i].Summary = "A summary"
+		}
+	}
+	return notes
+}
+
+func BenchmarkChunkNote(b *testing.B) {
+	noteID := primitive.NewObjectID()
+	text := benchText(config.MAX_WORDS)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		chunkNote(noteID, text, config.DEFAULT_CHUNK_SIZE, config.DEFAULT_CHUNK_OVERLAP)
+	}
+}
+
+func BenchmarkChunkNotePaged(b *testing.B) {
+	noteID := primitive.NewObjectID()
+	var pages []string
+	for page := 1; page <= 40; page++ {
+		pages = append(pages, utils.PageMarker(page)+"\n"+benchText(250))
+	}
+	text := strings.Join(pages, "\n\n")
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		chunkNote(noteID, text, config.DEFAULT_CHUNK_SIZE, config.DEFAULT_CHUNK_OVERLAP)
+	}
+}
+
+// benchSearchInput returns notes and the chunk hits Qdrant might return for them, best first
+func benchSearchInput(noteCount, chunksPerNote int) ([]models.Note, []vectordb.VectorSearchResult) {
+	rng := rand.New(rand.NewSource(1))
+	notes := benchNotes(noteCount, 50)
+	var hits []vectordb.VectorSearchResult
+	for _, note := range notes {
+		for c := 0; c < chunksPerNote; c++ {
+			hits = append(hits, vectordb.VectorSearchResult{
+				ChunkID: primitive.NewObjectID().Hex(),
+				NoteID:  note.ID.Hex(),
+				Score:   rng.Float32(),
+			})
+		}
+	}
+	return notes, hits
+}
+
+func benchBoosts() *rankingBoosts {
+	return &rankingBoosts{
+		pinned:         config.DEFAULT_BOOST_PINNED,
+		summary:        config.DEFAULT_BOOST_SUMMARY,
+		recency:        config.DEFAULT_BOOST_RECENCY,
+		halfLifeDays:   config.DEFAULT_RECENCY_HALF_LIFE_DAYS,
+		popularity:     config.DEFAULT_BOOST_POPULARITY,
+		channelWeights: map[string]float64{utils.NormalizeChannelKey("Channel 1"): 1.5},
+	}
+}
+
+func BenchmarkSearchAssembly(b *testing.B) {
+	notes, hits := benchSearchInput(200, 5)
+	boosts := benchBoosts()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		noteScores, _ := groupChunkResults(hits, config.DEFAULT_MIN_SEARCH_SCORE)
+		rankNotes(notes, noteScores, config.DEFAULT_MIN_SEARCH_SCORE, 10, boosts, nil)
+	}
+}
+
+func BenchmarkSearchAssemblyExplained(b *testing.B) {
+	notes, hits := benchSearchInput(200, 5)
+	boosts := benchBoosts()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		noteScores, _ := groupChunkResults(hits, config.DEFAULT_MIN_SEARCH_SCORE)
+		rankNotes(notes, noteScores, config.DEFAULT_MIN_SEARCH_SCORE, 10, boosts, &searchTrace{})
+	}
+}
+
+func BenchmarkAnswerContext(b *testing.B) {
+	var sources []models.SearchResult
+	for _, note := range benchNotes(5, 2000) {
+		sources = append(sources, models.SearchResult{Note: note, Score: 0.8})
+	}
+	history := []models.ConversationMessage{
+		{Role: models.ConversationRoleUser, Content: "What did the paper say about attention?"},
+		{Role: models.ConversationRoleAssistant, Content: benchText(150)},
+		{Role: models.ConversationRoleUser, Content: "And about recurrent models?"},
+		{Role: models.ConversationRoleAssistant, Content: benchText(150)},
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		retrievalQuery("How does it compare on long sequences?", history)
+		answerContext(sources)
+	}
+}
@@ -0,0 +1,411 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/vectordb"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// categoryNamePattern matches category names: lowercase words joined by hyphens
+var categoryNamePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// CategoryService manages the note categories and keeps the cache that classification and
+// validation read them from (config.Categories, config.IsValidCategory) up to date
+type CategoryService struct {
+	categoriesRepo *repository.CategoriesRepository
+	notesRepo      *repository.NotesRepository
+	rulesService   *RulesService
+	automationRepo *repository.AutomationRulesRepository
+	examplesRepo   *repository.ClassificationExamplesRepository
+	settingsRepo   *repository.SettingsRepository
+	webhookService *WebhookService
+	qdrantClient   *vectordb.QdrantClient
+
+	loadMu sync.Mutex // Serializes loads, so an older list never replaces a newer one in the cache
+	stop   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewCategoryService creates a new CategoryService
+func NewCategoryService(categoriesRepo *repository.CategoriesRepository, notesRepo *repository.NotesRepository, rulesService *RulesService, automationRepo *repository.AutomationRulesRepository, examplesRepo *repository.ClassificationExamplesRepository, settingsRepo *repository.SettingsRepository, webhookService *WebhookService, qdrantClient *vectordb.QdrantClient) *CategoryService {
+	return &CategoryService{
+		categoriesRepo: categoriesRepo,
+		notesRepo:      notesRepo,
+		rulesService:   rulesService,
+		automationRepo: automationRepo,
+		examplesRepo:   examplesRepo,
+		settingsRepo:   settingsRepo,
+		webhookService: webhookService,
+		qdrantClient:   qdrantClient,
+	}
+}
+
+// Load reads the categories into the cache, first seeding the collection with DEFAULT_CATEGORIES
+// when it is empty, i.e. on the first run
+func (s *CategoryService) Load(ctx context.Context) error {
+	s.loadMu.Lock()
+	defer s.loadMu.Unlock()
+
+	categories, err := s.categoriesRepo.FindAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load categories: %w", err)
+	}
+	if len(categories) == 0 {
+		now := time.Now()
+		defaults := make([]models.Category, len(config.DEFAULT_CATEGORIES))
+		for i, name := range config.DEFAULT_CATEGORIES {
+			defaults[i] = models.Category{Name: name, CreatedAt: now}
+		}
+		if err := s.categoriesRepo.CreateMany(ctx, defaults); err != nil {
+			return fmt.Errorf("failed to seed categories: %w", err)
+		}
+		log.Printf("Seeded %d default categories", len(defaults))
+		if categories, err = s.categoriesRepo.FindAll(ctx); err != nil {
+			return fmt.Errorf("failed to load categories: %w", err)
+		}
+	}
+
+	names := make([]string, len(categories))
+	for i, category := range categories {
+		names[i] = category.Name
+	}
+	config.SetCategories(names)
+	return nil
+}
+
+// Start reloads the categories every CATEGORY_REFRESH_SECONDS until Stop is called
+func (s *CategoryService) Start() {
+	s.stop = make(chan struct{})
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(config.CATEGORY_REFRESH_SECONDS * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.Load(context.Background()); err != nil {
+					log.Printf("Failed to refresh categories, keeping the cached ones: %v", err)
+				}
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the background refresh and waits for it to finish
+func (s *CategoryService) Stop() {
+	if s.stop == nil {
+		return
+	}
+	close(s.stop)
+	s.wg.Wait()
+}
+
+// GetCategories retrieves all categories, by name
+func (s *CategoryService) GetCategories(ctx context.Context) ([]models.Category, error) {
+	return s.categoriesRepo.FindAll(ctx)
+}
+
+// CreateCategory adds a category that notes can be classified into from now on
+func (s *CategoryService) CreateCategory(ctx context.Context, category *models.Category) (*models.Category, error) {
+	category.Name = normalizeCategoryName(category.Name)
+	category.Description = strings.TrimSpace(category.Description)
+	if err := validateCategoryName(category.Name); err != nil {
+		return nil, err
+	}
+	category.CreatedAt = time.Now()
+
+	if err := s.categoriesRepo.Create(ctx, category); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, fmt.Errorf("category already exists")
+		}
+		return nil, fmt.Errorf("failed to create category: %w", err)
+	}
+	s.reload(ctx)
+
+	return category, nil
+}
+
+// UpdateCategory replaces a category's description
+func (s *CategoryService) UpdateCategory(ctx context.Context, name, description string) (*models.Category, error) {
+	found, err := s.categoriesRepo.SetDescription(ctx, name, strings.TrimSpace(description))
+	if err != nil {
+		return nil, fmt.Errorf("failed to update category: %w", err)
+	}
+	if !found {
+		return nil, fmt.Errorf("category not found")
+	}
+
+	category, err := s.categoriesRepo.FindByName(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find category: %w", err)
+	}
+	return category, nil
+}
+
+// DeleteCategory removes a category no note is in; the fallback category can't be removed
+func (s *CategoryService) DeleteCategory(ctx context.Context, name string) error {
+	if name == config.FALLBACK_CATEGORY {
+		return fmt.Errorf("invalid category: %s is the fallback category and can't be deleted", name)
+	}
+
+	count, err := s.notesRepo.CountByCategory(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to count notes in category: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("category in use: %d notes are in it, move them to another category first", count)
+	}
+
+	deleted, err := s.categoriesRepo.Delete(ctx, name)
+	if err != nil {
+		return fmt.Errorf("failed to delete category: %w", err)
+	}
+	if deleted == 0 {
+		return fmt.Errorf("category not found")
+	}
+	s.reload(ctx)
+
+	return nil
+}
+
+// RenameCategory renames a category, moving its notes, their search index points, its description
+// and the category rules, automation rules, pipeline and classification examples naming it to the
+// new name. With dryRun nothing changes; the result says how many of each would move.
+func (s *CategoryService) RenameCategory(ctx context.Context, from, to string, dryRun bool) (*models.CategoryChange, error) {
+	from = normalizeCategoryName(from)
+	to = normalizeCategoryName(to)
+	if from == config.FALLBACK_CATEGORY {
+		return nil, fmt.Errorf("invalid category: %s is the fallback category and can't be renamed", from)
+	}
+	if err := validateCategoryName(to); err != nil {
+		return nil, err
+	}
+	if from == to {
+		return nil, fmt.Errorf("invalid category: the new name is the same as the old one")
+	}
+
+	category, err := s.findCategory(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.categoriesRepo.FindByName(ctx, to); err == nil {
+		return nil, fmt.Errorf("category already exists")
+	} else if err != mongo.ErrNoDocuments {
+		return nil, fmt.Errorf("failed to find category: %w", err)
+	}
+
+	if dryRun {
+		return s.countChange(ctx, []string{from}, to)
+	}
+
+	renamed := &models.Category{Name: to, Description: category.Description, CreatedAt: time.Now()}
+	if err := s.categoriesRepo.Create(ctx, renamed); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, fmt.Errorf("category already exists")
+		}
+		return nil, fmt.Errorf("failed to create category: %w", err)
+	}
+	return s.moveNotes(ctx, []string{from}, to)
+}
+
+// MergeCategories moves the notes of the from categories, their search index points and everything
+// naming them (see RenameCategory) into an existing category and removes the from categories. With
+// dryRun nothing changes; the result says how many of each would move.
+func (s *CategoryService) MergeCategories(ctx context.Context, from []string, to string, dryRun bool) (*models.CategoryChange, error) {
+	to = normalizeCategoryName(to)
+	var sources []string
+	for _, name := range from {
+		name = normalizeCategoryName(name)
+		if name == config.FALLBACK_CATEGORY {
+			return nil, fmt.Errorf("invalid category: %s is the fallback category and can't be merged into another", name)
+		}
+		if name == to {
+			return nil, fmt.Errorf("invalid category: %s can't be merged into itself", name)
+		}
+		if !slices.Contains(sources, name) {
+			sources = append(sources, name)
+		}
+	}
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("invalid category: at least one category to merge is required")
+	}
+
+	if _, err := s.findCategory(ctx, to); err != nil {
+		return nil, err
+	}
+	for _, name := range sources {
+		if _, err := s.findCategory(ctx, name); err != nil {
+			return nil, err
+		}
+	}
+
+	if dryRun {
+		return s.countChange(ctx, sources, to)
+	}
+	return s.moveNotes(ctx, sources, to)
+}
+
+// countChange reports how many notes and references moving the from categories into to would change
+func (s *CategoryService) countChange(ctx context.Context, from []string, to string) (*models.CategoryChange, error) {
+	change := &models.CategoryChange{From: from, To: to, DryRun: true}
+	for _, name := range from {
+		count, err := s.notesRepo.CountByCategory(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count notes in category: %w", err)
+		}
+		change.Notes += count
+	}
+
+	var err error
+	if change.Rules, err = s.rulesService.CountInCategories(ctx, from); err != nil {
+		return nil, fmt.Errorf("failed to count category rules: %w", err)
+	}
+	if change.Automations, err = s.automationRepo.CountCategoryTriggers(ctx, from); err != nil {
+		return nil, fmt.Errorf("failed to count automation rules: %w", err)
+	}
+	if change.Examples, err = s.examplesRepo.CountInCategories(ctx, from); err != nil {
+		return nil, fmt.Errorf("failed to count classification examples: %w", err)
+	}
+	pipelines, err := s.settingsRepo.GetPipelines(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pipelines: %w", err)
+	}
+	if pipelines != nil {
+		for _, name := range from {
+			if _, ok := pipelines.Categories[name]; ok {
+				change.Pipelines++
+			}
+		}
+	}
+	return change, nil
+}
+
+// moveNotes moves every note in the from categories to the existing to category in a single update,
+// then their search index points and the references to the from categories, and only then removes
+// the from categories, so a failure part way leaves them in place and merging them into to again
+// finishes the job
+func (s *CategoryService) moveNotes(ctx context.Context, from []string, to string) (*models.CategoryChange, error) {
+	filter := bson.M{"category": bson.M{"$in": from}}
+	moved, err := s.notesRepo.FindAll(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find notes in category: %w", err)
+	}
+
+	result, err := s.notesRepo.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"category": to}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to move notes: %w", err)
+	}
+	if err := s.qdrantClient.SetCategory(from, to); err != nil {
+		return nil, fmt.Errorf("failed to move search index points, merge the categories again to retry: %w", err)
+	}
+	change := &models.CategoryChange{From: from, To: to, Notes: result.ModifiedCount}
+	if err := s.moveReferences(ctx, change); err != nil {
+		return nil, err
+	}
+
+	for _, name := range from {
+		if _, err := s.categoriesRepo.Delete(ctx, name); err != nil {
+			return nil, fmt.Errorf("failed to delete category: %w", err)
+		}
+	}
+	s.reload(ctx)
+
+	for _, note := range moved {
+		s.webhookService.Emit(ctx, models.NoteEventUpdated, note.ID, map[string]interface{}{"category": to})
+	}
+	log.Printf("Moved %d notes, %d category rules, %d automation rules, %d pipelines and %d classification examples from categories %v to %s",
+		change.Notes, change.Rules, change.Automations, change.Pipelines, change.Examples, from, to)
+
+	return change, nil
+}
+
+// moveReferences points the category rules, automation rules, per-category pipelines and
+// classification examples naming the change's from categories at its to category, counting them
+// in the change. A from category's pipeline is dropped when to already has one.
+func (s *CategoryService) moveReferences(ctx context.Context, change *models.CategoryChange) error {
+	var err error
+	if change.Rules, err = s.rulesService.MoveCategory(ctx, change.From, change.To); err != nil {
+		return fmt.Errorf("failed to move category rules: %w", err)
+	}
+	if change.Automations, err = s.automationRepo.SetCategoryTrigger(ctx, change.From, change.To); err != nil {
+		return fmt.Errorf("failed to move automation rules: %w", err)
+	}
+	if change.Examples, err = s.examplesRepo.SetCategory(ctx, change.From, change.To); err != nil {
+		return fmt.Errorf("failed to move classification examples: %w", err)
+	}
+
+	pipelines, err := s.settingsRepo.GetPipelines(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get pipelines: %w", err)
+	}
+	if pipelines == nil {
+		return nil
+	}
+	for _, name := range change.From {
+		steps, ok := pipelines.Categories[name]
+		if !ok {
+			continue
+		}
+		if _, exists := pipelines.Categories[change.To]; !exists {
+			pipelines.Categories[change.To] = steps
+		}
+		delete(pipelines.Categories, name)
+		change.Pipelines++
+	}
+	if change.Pipelines > 0 {
+		pipelines.UpdatedAt = time.Now()
+		if err := s.settingsRepo.SavePipelines(ctx, pipelines); err != nil {
+			return fmt.Errorf("failed to save pipelines: %w", err)
+		}
+	}
+	return nil
+}
+
+// findCategory retrieves a category by name, reporting which one is missing
+func (s *CategoryService) findCategory(ctx context.Context, name string) (*models.Category, error) {
+	category, err := s.categoriesRepo.FindByName(ctx, name)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("category not found: %s", name)
+		}
+		return nil, fmt.Errorf("failed to find category: %w", err)
+	}
+	return category, nil
+}
+
+// normalizeCategoryName trims and lowercases a category name as given in a request
+func normalizeCategoryName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// validateCategoryName checks that a normalized name can be used for a new category
+func validateCategoryName(name string) error {
+	if !categoryNamePattern.MatchString(name) || len(name) > config.MAX_CATEGORY_NAME_LENGTH {
+		return fmt.Errorf("invalid category: name must be lowercase letters and digits joined by hyphens, at most %d characters", config.MAX_CATEGORY_NAME_LENGTH)
+	}
+	return nil
+}
+
+// reload refreshes the cache after a change; a failure is logged and left to the next refresh
+func (s *CategoryService) reload(ctx context.Context) {
+	if err := s.Load(ctx); err != nil {
+		log.Printf("Failed to reload categories after a change: %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"backend/internal/clipper"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/utils"
+)
+
+// ClipService saves web pages as notes from just their URL
+type ClipService struct {
+	notesRepo    *repository.NotesRepository
+	notesService *NotesService
+	clipper      *clipper.Client
+}
+
+// NewClipService creates a new ClipService
+func NewClipService(notesRepo *repository.NotesRepository, notesService *NotesService, clipperClient *clipper.Client) *ClipService {
+	return &ClipService{
+		notesRepo:    notesRepo,
+		notesService: notesService,
+		clipper:      clipperClient,
+	}
+}
+
+// Clip fetches a page, extracts its article text and creates a note from it
+// A page already saved under the requested or canonical URL is reported as a duplicate before
+// any AI analysis runs.
+func (s *ClipService) Clip(ctx context.Context, req *models.ClipRequest) (*CreateNoteResult, error) {
+	pageURL := strings.TrimSpace(req.URL)
+	parsed, err := url.Parse(pageURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid URL: %q must be an absolute http(s) URL", req.URL)
+	}
+
+	if duplicate, err := s.isSaved(ctx, pageURL); err != nil || duplicate != nil {
+		return duplicate, err
+	}
+
+	article, err := s.clipper.Clip(ctx, pageURL)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid page") {
+			return nil, err
+		}
+		if errors.Is(err, utils.ErrNonPublicAddress) {
+			return nil, fmt.Errorf("invalid URL: %q points to a non-public address", req.URL)
+		}
+		return nil, fmt.Errorf("failed to fetch page: %w", err)
+	}
+
+	// Web notes group by site when the page names no author
+	author := article.Author
+	if author == "" {
+		author = article.SiteName
+	}
+	metadata := map[string]interface{}{
+		"author":    author,
+		"platform":  "web",
+		"url":       article.URL,
+		"source":    "web-clipper",
+		"site_name": article.SiteName,
+	}
+	if !article.PublishedAt.IsZero() {
+		metadata["timestamp"] = article.PublishedAt.Format(time.RFC3339)
+	}
+	if article.URL != pageURL {
+		metadata["clipped_url"] = pageURL
+		if duplicate, err := s.isSaved(ctx, article.URL); err != nil || duplicate != nil {
+			return duplicate, err
+		}
+	}
+
+	log.Printf("Clipped %s: %d characters of article text", article.URL, len(article.Content))
+	return s.notesService.CreateNote(ctx, &models.CreateNoteRequest{
+		Title:    article.Title,
+		Content:  article.Content,
+		Tags:     req.Tags,
+		Metadata: metadata,
+	})
+}
+
+// isSaved returns a duplicate result when a note already has the URL
+func (s *ClipService) isSaved(ctx context.Context, pageURL string) (*CreateNoteResult, error) {
+	exists, err := s.notesRepo.ExistsByURL(ctx, pageURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for duplicates: %w", err)
+	}
+	if !exists {
+		return nil, nil
+	}
+	return &CreateNoteResult{Duplicate: true, URL: pageURL}, nil
+}
@@ -0,0 +1,148 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/utils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// conversationTitleLength caps titles derived from the first question
+const conversationTitleLength = 80
+
+// ConversationService manages multi-turn Q&A sessions
+type ConversationService struct {
+	conversationsRepo *repository.ConversationsRepository
+	searchService     *SearchService
+}
+
+// NewConversationService creates a new ConversationService
+func NewConversationService(
+	conversationsRepo *repository.ConversationsRepository,
+	searchService *SearchService,
+) *ConversationService {
+	return &ConversationService{
+		conversationsRepo: conversationsRepo,
+		searchService:     searchService,
+	}
+}
+
+// CreateConversation starts a new, empty conversation
+func (s *ConversationService) CreateConversation(ctx context.Context, title string) (*models.Conversation, error) {
+	now := time.Now()
+	conversation := &models.Conversation{
+		Title:     strings.TrimSpace(title),
+		Messages:  []models.ConversationMessage{},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	id, err := s.conversationsRepo.Create(ctx, conversation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create conversation: %w", err)
+	}
+	conversation.ID = id
+
+	return conversation, nil
+}
+
+// GetConversations returns all conversations without their messages
+func (s *ConversationService) GetConversations(ctx context.Context) ([]models.Conversation, error) {
+	return s.conversationsRepo.FindAll(ctx)
+}
+
+// GetConversation returns a conversation with its full message history
+func (s *ConversationService) GetConversation(ctx context.Context, conversationID string) (*models.Conversation, error) {
+	objID, err := primitive.ObjectIDFromHex(conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid conversation ID: %w", err)
+	}
+
+	conversation, err := s.conversationsRepo.FindByID(ctx, objID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("conversation not found")
+		}
+		return nil, fmt.Errorf("failed to find conversation: %w", err)
+	}
+
+	return conversation, nil
+}
+
+// Ask answers a question in the context of a conversation and records both turns
+// Only the most recent messages are passed to the model to keep prompts bounded
+func (s *ConversationService) Ask(ctx context.Context, conversationID, question string, minScore *float32) (*models.ConversationAnswerResponse, error) {
+	conversation, err := s.GetConversation(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	history := conversation.Messages
+	if len(history) > config.MAX_CONVERSATION_HISTORY {
+		history = history[len(history)-config.MAX_CONVERSATION_HISTORY:]
+	}
+
+	response, err := s.searchService.AnswerWithHistory(ctx, question, history, minScore)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceIDs := make([]string, 0, len(response.Sources))
+	for _, source := range response.Sources {
+		sourceIDs = append(sourceIDs, source.Note.ID.Hex())
+	}
+
+	now := time.Now()
+	userMessage := models.ConversationMessage{
+		Role:      models.ConversationRoleUser,
+		Content:   question,
+		CreatedAt: now,
+	}
+	assistantMessage := models.ConversationMessage{
+		Role:      models.ConversationRoleAssistant,
+		Content:   response.Answer,
+		Sources:   sourceIDs,
+		CreatedAt: now,
+	}
+
+	// Untitled conversations are named after their first question
+	title := ""
+	if conversation.Title == "" {
+		title = utils.TruncateWords(question, conversationTitleLength)
+	}
+
+	if err := s.conversationsRepo.AppendMessages(ctx, conversation.ID, title, userMessage, assistantMessage); err != nil {
+		return nil, fmt.Errorf("failed to save conversation messages: %w", err)
+	}
+
+	return &models.ConversationAnswerResponse{
+		ConversationID:   conversation.ID,
+		QuestionResponse: *response,
+	}, nil
+}
+
+// DeleteConversation removes a conversation and its history
+func (s *ConversationService) DeleteConversation(ctx context.Context, conversationID string) error {
+	objID, err := primitive.ObjectIDFromHex(conversationID)
+	if err != nil {
+		return fmt.Errorf("invalid conversation ID: %w", err)
+	}
+
+	deleted, err := s.conversationsRepo.Delete(ctx, objID)
+	if err != nil {
+		return fmt.Errorf("failed to delete conversation: %w", err)
+	}
+	if deleted == 0 {
+		return fmt.Errorf("conversation not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"slices"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/vectordb"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// EmbeddingsService exports and imports raw vectors, so embeddings can be analyzed offline or
+// moved between environments without re-embedding every note
+type EmbeddingsService struct {
+	qdrantClient *vectordb.QdrantClient
+}
+
+// NewEmbeddingsService creates a new EmbeddingsService
+func NewEmbeddingsService(qdrantClient *vectordb.QdrantClient) *EmbeddingsService {
+	return &EmbeddingsService{
+		qdrantClient: qdrantClient,
+	}
+}
+
+// Export passes every stored embedding to write, a page at a time, and returns how many were written
+func (s *EmbeddingsService) Export(ctx context.Context, write func(*models.EmbeddingRecord) error) (int, error) {
+	var offset *uint64
+	exported := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return exported, err
+		}
+
+		points, next, err := s.qdrantClient.ScrollPoints(offset, config.EMBEDDINGS_PAGE_SIZE, true)
+		if err != nil {
+			return exported, err
+		}
+
+		for _, point := range points {
+			err := write(&models.EmbeddingRecord{
+				ID:         point.ID,
+				ChunkID:    point.ChunkID,
+				NoteID:     point.NoteID,
+				Category:   point.Payload.Category,
+				ChannelKey: point.Payload.ChannelKey,
+				Hash:       point.Payload.ContentHash,
+				ChunkType:  point.Payload.ChunkType,
+				Author:     point.Payload.Author,
+				Platform:   point.Payload.Platform,
+				CreatedAt:  unixSeconds(point.Payload.CreatedAt),
+				Vector:     point.Vector,
+			})
+			if err != nil {
+				return exported, err
+			}
+			exported++
+		}
+
+		if next == nil {
+			return exported, nil
+		}
+		offset = next
+	}
+}
+
+// Import upserts embeddings read as JSONL in the Export format
+// Records keep their point IDs, so importing the same export twice is idempotent. Malformed lines and
+// vectors of the wrong dimension are skipped and reported rather than failing the import.
+func (s *EmbeddingsService) Import(ctx context.Context, r io.Reader) (*models.EmbeddingImportResponse, error) {
+	response := &models.EmbeddingImportResponse{}
+	reject := func(line int, reason string) {
+		response.Skipped++
+		if len(response.Errors) < config.MAX_IMPORT_ERRORS {
+			response.Errors = append(response.Errors, fmt.Sprintf("line %d: %s", line, reason))
+		}
+	}
+
+	var batch []vectordb.StoredPoint
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := s.qdrantClient.UpsertPoints(batch); err != nil {
+			return err
+		}
+		response.Imported += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), config.MAX_IMPORT_LINE_BYTES)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var record models.EmbeddingRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			reject(line, "invalid JSON")
+			continue
+		}
+		if reason := validateEmbeddingRecord(&record, s.qdrantClient.Dimension()); reason != "" {
+			reject(line, reason)
+			continue
+		}
+		if record.ID == 0 {
+			chunkID, _ := primitive.ObjectIDFromHex(record.ChunkID) // Checked by validateEmbeddingRecord
+			record.ID = vectordb.PointID(chunkID)
+		}
+
+		batch = append(batch, vectordb.StoredPoint{
+			ID:      record.ID,
+			ChunkID: record.ChunkID,
+			NoteID:  record.NoteID,
+			Vector:  record.Vector,
+			Payload: vectordb.PointPayload{
+				Category:    record.Category,
+				ChannelKey:  record.ChannelKey,
+				ContentHash: record.Hash,
+				ChunkType:   record.ChunkType,
+				Author:      record.Author,
+				Platform:    record.Platform,
+				CreatedAt:   unixTime(record.CreatedAt),
+			},
+		})
+		if len(batch) == config.EMBEDDINGS_PAGE_SIZE {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("invalid import: failed to read line %d: %w", line+1, err)
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Imported %d embeddings, skipped %d", response.Imported, response.Skipped)
+	return response, nil
+}
+
+// validateEmbeddingRecord returns why a record can't be imported into a collection of vectors of the
+// given dimension, or "" if it can
+func validateEmbeddingRecord(record *models.EmbeddingRecord, dimension int) string {
+	if _, err := primitive.ObjectIDFromHex(record.NoteID); err != nil {
+		return "invalid noteId"
+	}
+	if _, err := primitive.ObjectIDFromHex(record.ChunkID); err != nil {
+		return "invalid chunkId"
+	}
+	if len(record.Vector) != dimension {
+		return fmt.Sprintf("vector has %d dimensions, expected %d", len(record.Vector), dimension)
+	}
+	if record.ChunkType != "" && !slices.Contains(models.ChunkTypes, record.ChunkType) {
+		return "invalid chunkType"
+	}
+	return ""
+}
+
+// unixSeconds converts a payload time to a record's Unix seconds, 0 when unset
+func unixSeconds(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+// unixTime converts a record's Unix seconds to a payload time, zero when unset
+func unixTime(seconds int64) time.Time {
+	if seconds <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(seconds, 0).UTC()
+}
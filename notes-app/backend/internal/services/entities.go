@@ -0,0 +1,68 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"backend/internal/models"
+	"backend/internal/repository"
+)
+
+// EntityService lists the people, organizations, places and tools notes mention, and the notes
+// mentioning each
+type EntityService struct {
+	notesRepo *repository.NotesRepository
+}
+
+// NewEntityService creates a new EntityService
+func NewEntityService(notesRepo *repository.NotesRepository) *EntityService {
+	return &EntityService{
+		notesRepo: notesRepo,
+	}
+}
+
+// GetEntities returns the entities unarchived notes mention, most mentioned first
+// entityType limits them to one type; empty lists every type.
+func (s *EntityService) GetEntities(ctx context.Context, entityType string, limit int) ([]models.EntityCount, error) {
+	entityType, err := validEntityType(entityType)
+	if err != nil {
+		return nil, err
+	}
+
+	entities, err := s.notesRepo.EntityCounts(ctx, notesFilter(models.SearchFilters{}), entityType, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entities: %w", err)
+	}
+	return entities, nil
+}
+
+// GetEntityNotes returns the unarchived notes mentioning the named entity, newest first
+// Names match case-insensitively; entityType tells apart entities sharing a name (e.g. a person and
+// the company named after them) and may be empty.
+func (s *EntityService) GetEntityNotes(ctx context.Context, name, entityType string) ([]models.Note, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("invalid entity name: must not be empty")
+	}
+	entityType, err := validEntityType(entityType)
+	if err != nil {
+		return nil, err
+	}
+
+	notes, err := s.notesRepo.FindByEntity(ctx, notesFilter(models.SearchFilters{}), name, entityType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notes mentioning %q: %w", name, err)
+	}
+	return notes, nil
+}
+
+// validEntityType returns the entity type lowercased, or an error when it isn't known; empty is valid
+func validEntityType(entityType string) (string, error) {
+	entityType = strings.ToLower(strings.TrimSpace(entityType))
+	if entityType != "" && !slices.Contains(models.EntityTypes, entityType) {
+		return "", fmt.Errorf("invalid entity type %q, expected one of %s", entityType, strings.Join(models.EntityTypes, ", "))
+	}
+	return entityType, nil
+}
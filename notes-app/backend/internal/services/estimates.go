@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"backend/internal/ai"
+	"backend/internal/config"
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// estimatedOperations are the bulk AI operations EstimateOperations covers, by MigrationRun kind
+var estimatedOperations = []string{models.MigrationClassify, models.MigrationTitles, models.MigrationSummaries, models.MigrationReindex}
+
+// EstimateOperations works out the notes, tokens, cost and time of bulk AI operations before they
+// run, from the stored length of the notes each would cover, without calling the AI. An empty
+// operation estimates them all, leaving re-summarization out in privacy mode.
+func (s *MigrationService) EstimateOperations(ctx context.Context, operation string, opts MigrationOptions) ([]models.AIOperationEstimate, error) {
+	operations := estimatedOperations
+	if operation != "" {
+		if !slices.Contains(estimatedOperations, operation) {
+			return nil, fmt.Errorf("invalid operation: %q, must be one of %s", operation, strings.Join(estimatedOperations, ", "))
+		}
+		operations = []string{operation}
+	} else if s.cfg.PrivacyMode {
+		operations = slices.DeleteFunc(slices.Clone(operations), func(op string) bool { return op == models.MigrationSummaries })
+	}
+
+	estimates := make([]models.AIOperationEstimate, 0, len(operations))
+	for _, op := range operations {
+		estimate, err := s.estimate(ctx, op, opts)
+		if err != nil {
+			return nil, err
+		}
+		estimates = append(estimates, *estimate)
+	}
+	return estimates, nil
+}
+
+// estimate works out what one operation takes. Note lengths are summed by MongoDB, so no content is
+// loaded; with opts.Resume a migration covers only the notes its unfinished run has left.
+// Classification counts every note as going to the AI, though category rules may spare some.
+func (s *MigrationService) estimate(ctx context.Context, operation string, opts MigrationOptions) (*models.AIOperationEstimate, error) {
+	filter, maxContentChars := bson.M{}, 0
+	switch operation {
+	case models.MigrationClassify:
+		filter = classifyFilter
+	case models.MigrationTitles:
+		maxContentChars = config.TITLE_CONTENT_CHARS
+	case models.MigrationSummaries:
+		if err := s.checkResummarize(opts); err != nil {
+			return nil, err
+		}
+		filter = resummarizeFilter
+	}
+
+	estimate := &models.AIOperationEstimate{Operation: operation, Model: s.cfg.GenerationModel}
+	var unfinished *models.MigrationRun
+	if operation != models.MigrationReindex { // A reindex always starts over
+		var err error
+		if unfinished, filter, err = s.pendingQuery(ctx, operation, filter, opts); err != nil {
+			return nil, err
+		}
+		estimate.Resume = unfinished != nil
+	}
+	lengths, err := s.notesRepo.ContentLengths(ctx, filter, maxContentChars)
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure notes: %w", err)
+	}
+	for _, length := range lengths {
+		estimate.Notes += length.Notes
+	}
+
+	switch operation {
+	case models.MigrationClassify:
+		examplesTokens := 0
+		for _, example := range s.reviewService.FewShotExamples(ctx) {
+			examplesTokens += ai.EstimateTokens(example.Title + example.Excerpt + example.Category)
+		}
+		for _, length := range lengths {
+			estimate.InputTokens += ai.TokensForChars(length.TitleChars+length.ContentChars) + length.Notes*(config.CLASSIFY_PROMPT_TOKENS+examplesTokens)
+		}
+		estimate.OutputTokens = estimate.Notes * s.replyTokens(config.CLASSIFY_OUTPUT_TOKENS)
+	case models.MigrationTitles:
+		for _, length := range lengths {
+			estimate.InputTokens += ai.TokensForChars(length.ContentChars) + length.Notes*config.TITLE_PROMPT_TOKENS
+		}
+		estimate.OutputTokens = estimate.Notes * s.replyTokens(config.TITLE_OUTPUT_TOKENS)
+	case models.MigrationSummaries:
+		estimate.PerMinute = s.resummarizeRate(opts, unfinished)
+		for _, length := range lengths {
+			channel := &models.Note{Metadata: map[string]interface{}{"author": length.Author, "platform": length.Platform}}
+			promptText, promptSchema := s.summaryService.ChannelPrompt(ctx, channel)
+			promptTokens := ai.EstimateTokens(promptText) + ai.EstimateTokens(promptSchema) + config.SUMMARY_PROMPT_TOKENS
+			estimate.InputTokens += ai.TokensForChars(length.ContentChars) + length.Notes*promptTokens
+		}
+		estimate.OutputTokens = estimate.Notes * s.replyTokens(config.SUMMARY_OUTPUT_TOKENS)
+	case models.MigrationReindex:
+		// Every note's content and summary is embedded again; nothing is generated
+		estimate.Model = s.cfg.EmbeddingModel
+		for _, length := range lengths {
+			estimate.InputTokens += ai.TokensForChars(length.ContentChars + length.SummaryChars)
+		}
+	}
+
+	inputPrice, outputPrice := s.cfg.AIInputPricePerMillion, s.cfg.AIOutputPricePerMillion
+	if operation == models.MigrationReindex {
+		inputPrice, outputPrice = s.cfg.AIEmbeddingPricePerMillion, 0
+	}
+	if inputPrice > 0 || outputPrice > 0 {
+		cost := float64(estimate.InputTokens)/1e6*inputPrice + float64(estimate.OutputTokens)/1e6*outputPrice
+		cost = math.Round(cost*100) / 100
+		estimate.EstimatedCost = &cost
+	}
+
+	// Each note takes one AI call (a reindex embeds a note's chunks in batches of up to 100)
+	rate := s.cfg.AIRequestsPerMinute
+	if estimate.PerMinute > 0 && (rate <= 0 || estimate.PerMinute < rate) {
+		rate = estimate.PerMinute
+	}
+	if rate > 0 {
+		estimate.EstimatedMinutes = math.Round(float64(estimate.Notes)/float64(rate)*10) / 10
+	}
+	return estimate, nil
+}
+
+// replyTokens returns the estimated length of a reply, capped by GENERATION_MAX_OUTPUT_TOKENS
+func (s *MigrationService) replyTokens(tokens int) int {
+	if s.cfg.GenerationMaxOutputTokens > 0 {
+		return min(tokens, s.cfg.GenerationMaxOutputTokens)
+	}
+	return tokens
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"sync"
+
+	"backend/internal/config"
+	"backend/internal/models"
+)
+
+// EventStream fans note events out to the clients connected to GET /events
+// It subscribes to the WebhookService, so clients see the same events webhooks are sent, as they happen.
+type EventStream struct {
+	mu      sync.Mutex
+	clients map[*eventClient]struct{}
+}
+
+// eventClient is one connected listener and the event types it wants (all when empty)
+type eventClient struct {
+	events chan models.NoteEvent
+	types  map[string]bool
+}
+
+// NewEventStream creates a new EventStream
+func NewEventStream() *EventStream {
+	return &EventStream{
+		clients: make(map[*eventClient]struct{}),
+	}
+}
+
+// Publish passes an event to every listener that wants its type
+// A listener that has fallen EVENT_STREAM_BUFFER events behind misses it rather than blocking the emitter.
+func (s *EventStream) Publish(event models.NoteEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for client := range s.clients {
+		if len(client.types) > 0 && !client.types[event.Type] {
+			continue
+		}
+		select {
+		case client.events <- event:
+		default:
+			log.Printf("Event stream client fell behind, dropped %s event for note %s", event.Type, event.NoteID.Hex())
+		}
+	}
+}
+
+// Listen registers a listener for the given event types (every type when empty) and returns its events
+// along with a function that unregisters it
+func (s *EventStream) Listen(types []string) (<-chan models.NoteEvent, func(), error) {
+	client := &eventClient{
+		events: make(chan models.NoteEvent, config.EVENT_STREAM_BUFFER),
+		types:  make(map[string]bool, len(types)),
+	}
+	for _, eventType := range types {
+		if !isNoteEventType(eventType) {
+			return nil, nil, fmt.Errorf("invalid types: unknown event type %q", eventType)
+		}
+		client.types[eventType] = true
+	}
+
+	s.mu.Lock()
+	s.clients[client] = struct{}{}
+	s.mu.Unlock()
+
+	stop := func() {
+		s.mu.Lock()
+		delete(s.clients, client)
+		s.mu.Unlock()
+	}
+	return client.events, stop, nil
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// searchTrace collects the candidates dropped while ranking a debug search
+// All methods are no-ops on a nil trace, so non-debug searches pass nil and pay nothing
+type searchTrace struct {
+	filtered []models.SearchResult
+}
+
+// ExplainSearch runs a search and returns the ranking breakdown of every result, along with the
+// candidates that were dropped and why
+func (s *SearchService) ExplainSearch(ctx context.Context, req *models.SearchRequest) (*models.SearchExplanation, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+
+	minScore, err := s.SearchThreshold(req.MinScore)
+	if err != nil {
+		return nil, err
+	}
+
+	trace := &searchTrace{}
+	explanation := &models.SearchExplanation{Query: req.Query, Mode: req.Mode, MinScore: minScore}
+
+	var results []models.SearchResult
+	switch req.Mode {
+	case models.SearchModeHybrid:
+		results, err = s.hybridSearch(ctx, req.Query, limit, req.SearchFilters, req.Boosts, trace)
+	default:
+		explanation.Mode = models.SearchModeSemantic
+		var queryEmbedding []float32
+		queryEmbedding, err = s.aiClient.GenerateEmbedding(req.Query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate embedding for query: %w", err)
+		}
+		results, err = s.semanticSearch(ctx, req.Query, queryEmbedding, limit, req.SearchFilters, s.resolveBoosts(ctx, req.Boosts), trace)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if results == nil {
+		results = []models.SearchResult{}
+	}
+	explanation.Results = results
+	explanation.Filtered = trace.filteredResults()
+	if explanation.Filtered == nil {
+		explanation.Filtered = []models.SearchResult{}
+	}
+	return explanation, nil
+}
+
+// vectorExplanation starts the breakdown for a candidate scored by vector similarity
+func vectorExplanation(score, threshold float32) *models.RankingExplanation {
+	return &models.RankingExplanation{
+		VectorScore:     &score,
+		BoostMultiplier: 1,
+		FinalScore:      score,
+		Threshold:       threshold,
+	}
+}
+
+// belowThreshold is the filtered reason for a candidate under a relevance threshold
+func belowThreshold(threshold float32) string {
+	return fmt.Sprintf("below minimum relevance %.2f", threshold)
+}
+
+// drop records a candidate that was removed from the results
+func (t *searchTrace) drop(note models.Note, explain *models.RankingExplanation, reason string) {
+	if t == nil {
+		return
+	}
+	explain.FilteredReason = reason
+	t.filtered = append(t.filtered, models.SearchResult{
+		Note:    note,
+		Score:   explain.FinalScore,
+		Explain: explain,
+	})
+}
+
+// dropResults records ranked results cut from the list, keeping their score breakdown
+func (t *searchTrace) dropResults(results []models.SearchResult, reason string) {
+	if t == nil {
+		return
+	}
+	for _, result := range results {
+		explain := *result.Explain
+		explain.FinalScore = result.Score
+		t.drop(result.Note, &explain, reason)
+	}
+}
+
+// dropUnmatched records vector hits whose notes were not returned by the notes filter
+// Only the note ID is known for these; they were excluded by filters, archived or deleted
+func (t *searchTrace) dropUnmatched(ids []primitive.ObjectID, notes []models.Note, scores map[string]float32, minScore float32) {
+	found := make(map[primitive.ObjectID]bool, len(notes))
+	for _, note := range notes {
+		found[note.ID] = true
+	}
+	for _, id := range ids {
+		if found[id] {
+			continue
+		}
+		t.drop(models.Note{ID: id}, vectorExplanation(scores[id.Hex()], minScore), "excluded by filters")
+	}
+}
+
+// forget removes filtered entries for notes that still made it into the candidate set
+func (t *searchTrace) forget(kept map[primitive.ObjectID]float64) {
+	if t == nil {
+		return
+	}
+	filtered := t.filtered[:0]
+	for _, result := range t.filtered {
+		if _, ok := kept[result.Note.ID]; !ok {
+			filtered = append(filtered, result)
+		}
+	}
+	t.filtered = filtered
+}
+
+// finalize records the final score of each ranked result
+func (t *searchTrace) finalize(results []models.SearchResult) {
+	if t == nil {
+		return
+	}
+	for i := range results {
+		results[i].Explain.FinalScore = results[i].Score
+	}
+}
+
+// filteredResults returns the dropped candidates, or nil for a nil trace
+func (t *searchTrace) filteredResults() []models.SearchResult {
+	if t == nil {
+		return nil
+	}
+	return t.filtered
+}
+
+// intPtr returns a pointer to v
+func intPtr(v int) *int {
+	return &v
+}
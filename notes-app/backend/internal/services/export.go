@@ -0,0 +1,136 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ExportService produces full exports of the notes collection and restores JSON backups
+type ExportService struct {
+	notesRepo           *repository.NotesRepository
+	chunksRepo          *repository.ChunksRepository
+	channelSettingsRepo *repository.ChannelSettingsRepository
+	workerPool          *WorkerPool
+	webhookService      *WebhookService
+}
+
+// NewExportService creates a new ExportService
+func NewExportService(
+	notesRepo *repository.NotesRepository,
+	chunksRepo *repository.ChunksRepository,
+	channelSettingsRepo *repository.ChannelSettingsRepository,
+	workerPool *WorkerPool,
+	webhookService *WebhookService,
+) *ExportService {
+	return &ExportService{
+		notesRepo:           notesRepo,
+		chunksRepo:          chunksRepo,
+		channelSettingsRepo: channelSettingsRepo,
+		workerPool:          workerPool,
+		webhookService:      webhookService,
+	}
+}
+
+// WriteMarkdownZip writes every note, archived included, to w as a zip of Markdown files
+// Each file carries YAML front matter and is named after the note title, so the archive opens
+// directly as an Obsidian vault. Notes are streamed, so memory use doesn't grow with the collection.
+func (s *ExportService) WriteMarkdownZip(ctx context.Context, w io.Writer) (int, error) {
+	archive := zip.NewWriter(w)
+	usedNames := make(map[string]bool)
+	count := 0
+
+	opts := options.Find().SetSort(bson.M{"_id": 1})
+	err := s.notesRepo.ForEach(ctx, bson.M{}, func(note *models.Note) error {
+		name := markdownFileName(note, usedNames)
+		file, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     name,
+			Method:   zip.Deflate,
+			Modified: note.Created,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to add %s: %w", name, err)
+		}
+		if _, err := io.WriteString(file, noteMarkdown(note)); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+		count++
+		return nil
+	}, opts)
+	if err != nil {
+		return count, err
+	}
+
+	return count, archive.Close()
+}
+
+// markdownFileName names a note's file after its title, falling back to the ID on collisions
+func markdownFileName(note *models.Note, used map[string]bool) string {
+	slug := utils.Slugify(note.Title, 80)
+	if slug == "" {
+		slug = "note"
+	}
+
+	name := slug + ".md"
+	if used[name] {
+		name = slug + "-" + note.ID.Hex() + ".md"
+	}
+	used[name] = true
+	return name
+}
+
+// noteMarkdown renders a note as Markdown with YAML front matter
+// Strings are written as JSON, which YAML reads as double-quoted scalars, so titles with
+// colons or quotes stay valid without a YAML dependency
+func noteMarkdown(note *models.Note) string {
+	var b strings.Builder
+	b.WriteString("---\n")
+	writeFrontMatter(&b, "id", note.ID.Hex())
+	writeFrontMatter(&b, "title", note.Title)
+	writeFrontMatter(&b, "category", note.Category)
+	tags := note.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	writeFrontMatter(&b, "tags", tags)
+	b.WriteString("created: " + note.Created.UTC().Format(time.RFC3339) + "\n")
+	if note.Summary != "" {
+		writeFrontMatter(&b, "summary", note.Summary)
+	}
+	if note.Pinned {
+		b.WriteString("pinned: true\n")
+	}
+	if note.Archived {
+		b.WriteString("archived: true\n")
+	}
+	if len(note.Metadata) > 0 {
+		writeFrontMatter(&b, "metadata", note.Metadata)
+	}
+	b.WriteString("---\n\n")
+
+	b.WriteString(note.Content)
+	if !strings.HasSuffix(note.Content, "\n") {
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// writeFrontMatter writes a front matter field with its value in JSON (YAML flow) form
+func writeFrontMatter(b *strings.Builder, key string, value interface{}) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		encoded = []byte(`""`)
+	}
+	b.WriteString(key + ": " + string(encoded) + "\n")
+}
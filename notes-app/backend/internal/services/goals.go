@@ -0,0 +1,352 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"backend/internal/ai"
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// goalProgressPrompt asks for a progress summary of a goal from its dated updates
+const goalProgressPrompt = `Summarize progress toward this goal in 2-3 sentences, based only on the dated updates below.
+Say where things stand now and, if there is a target date, whether the goal looks on track for it.`
+
+// GoalsService tracks goals set in goals notes and the progress reported on them in later notes
+// New notes in GOAL_UPDATE_CATEGORIES are queued from note events and read by the AI in the
+// background; each update links the note that reported it and refreshes the goal's progress summary.
+type GoalsService struct {
+	goalsRepo *repository.GoalsRepository
+	notesRepo *repository.NotesRepository
+	aiClient  ai.Client
+	cfg       *config.Config
+
+	mu      sync.Mutex // Serializes extraction so two notes can't create the same goal twice
+	pending chan primitive.ObjectID
+	stop    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewGoalsService creates a new GoalsService
+func NewGoalsService(
+	goalsRepo *repository.GoalsRepository,
+	notesRepo *repository.NotesRepository,
+	aiClient ai.Client,
+	cfg *config.Config,
+) *GoalsService {
+	return &GoalsService{
+		goalsRepo: goalsRepo,
+		notesRepo: notesRepo,
+		aiClient:  aiClient,
+		cfg:       cfg,
+		pending:   make(chan primitive.ObjectID, config.GOAL_QUEUE_SIZE),
+	}
+}
+
+// HandleEvent queues new notes in the tracked categories for goal extraction; subscribe it to the
+// WebhookService. Never blocks: when the queue is full the note is skipped, and can be extracted
+// later through ExtractFromNote.
+func (s *GoalsService) HandleEvent(event models.NoteEvent) {
+	if event.Type != models.NoteEventCreated {
+		return
+	}
+	category, _ := event.Delta["category"].(string)
+	if !isGoalUpdateCategory(category) {
+		return
+	}
+
+	select {
+	case s.pending <- event.NoteID:
+	default:
+		log.Printf("Goal extraction queue full, skipped note %s", event.NoteID.Hex())
+	}
+}
+
+// Start launches the extraction loop
+func (s *GoalsService) Start() {
+	s.stop = make(chan struct{})
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			select {
+			case id := <-s.pending:
+				if _, err := s.ExtractFromNote(context.Background(), id.Hex()); err != nil {
+					log.Printf("Failed to extract goals from note %s: %v", id.Hex(), err)
+				}
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+	log.Println("Started goal tracking")
+}
+
+// Stop ends the extraction loop and waits for the note in progress to finish
+func (s *GoalsService) Stop() {
+	if s.stop == nil {
+		return
+	}
+	close(s.stop)
+	s.wg.Wait()
+}
+
+// ExtractFromNote reads a note for new goals and progress on active goals, returning the goals it
+// created or updated. Goals are only created from goals notes; running it twice on a note is a no-op.
+func (s *GoalsService) ExtractFromNote(ctx context.Context, noteID string) ([]models.Goal, error) {
+	objID, err := primitive.ObjectIDFromHex(noteID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid note ID: %w", err)
+	}
+	note, err := s.notesRepo.FindByID(ctx, objID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("note not found")
+		}
+		return nil, fmt.Errorf("failed to find note: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	active, err := s.goalsRepo.FindAll(ctx, bson.M{"status": models.GoalStatusActive})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load goals: %w", err)
+	}
+	titles := make([]string, len(active))
+	known := make(map[string]bool, len(active))
+	for i, goal := range active {
+		titles[i] = goal.Title
+		known[strings.ToLower(goal.Title)] = true
+	}
+
+	extraction, err := s.aiClient.ExtractGoals(note.Content, titles, note.Category == config.GOAL_CATEGORY)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := []models.Goal{}
+	now := time.Now()
+	for _, extracted := range extraction.Goals {
+		title := strings.TrimSpace(extracted.Title)
+		if title == "" || known[strings.ToLower(title)] {
+			continue
+		}
+		goal := models.Goal{
+			Title:        title,
+			Status:       models.GoalStatusActive,
+			SourceNoteID: note.ID,
+			Updates:      []models.GoalUpdate{},
+			CreatedAt:    now,
+			UpdatedAt:    now,
+		}
+		if target, err := time.Parse(heatmapDateFormat, extracted.TargetDate); err == nil {
+			goal.TargetDate = &target
+		}
+
+		id, err := s.goalsRepo.Create(ctx, &goal)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create goal: %w", err)
+		}
+		goal.ID = id
+		known[strings.ToLower(title)] = true
+		changed = append(changed, goal)
+	}
+
+	for _, extracted := range extraction.Updates {
+		if extracted.Goal < 1 || extracted.Goal > len(active) {
+			continue
+		}
+		goal := &active[extracted.Goal-1]
+		if goal.SourceNoteID == note.ID || hasGoalUpdateFrom(goal, note.ID) {
+			continue
+		}
+		if !isGoalStatus(extracted.Status) {
+			extracted.Status = models.GoalStatusActive
+		}
+
+		update := models.GoalUpdate{
+			NoteID:    note.ID,
+			Status:    extracted.Status,
+			Summary:   strings.TrimSpace(extracted.Summary),
+			CreatedAt: note.Created,
+		}
+		goal.Updates = append(goal.Updates, update)
+		goal.Status = update.Status
+		goal.Progress = s.summarizeProgress(goal)
+		goal.UpdatedAt = now
+
+		_, err := s.goalsRepo.Update(ctx, goal.ID, bson.M{
+			"$push": bson.M{"updates": update},
+			"$set":  bson.M{"status": goal.Status, "progress": goal.Progress, "updated_at": now},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to update goal: %w", err)
+		}
+		changed = append(changed, *goal)
+	}
+
+	if len(changed) > 0 {
+		log.Printf("Goals from note %s: %d created or updated", note.ID.Hex(), len(changed))
+	}
+	return markOverdue(changed), nil
+}
+
+// GetGoals returns goals, optionally only those in one status, soonest target date first,
+// along with the number of goals in every status
+func (s *GoalsService) GetGoals(ctx context.Context, status string) (*models.GoalsResponse, error) {
+	if status != "" && !isGoalStatus(status) {
+		return nil, fmt.Errorf("invalid status: must be one of %s", strings.Join(models.GoalStatuses, ", "))
+	}
+
+	goals, err := s.goalsRepo.FindAll(ctx, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load goals: %w", err)
+	}
+
+	response := &models.GoalsResponse{Goals: []models.Goal{}, Counts: make(map[string]int, len(models.GoalStatuses))}
+	for _, st := range models.GoalStatuses {
+		response.Counts[st] = 0
+	}
+	for _, goal := range goals {
+		response.Counts[goal.Status]++
+		if status == "" || goal.Status == status {
+			response.Goals = append(response.Goals, goal)
+		}
+	}
+	response.Goals = markOverdue(response.Goals)
+	return response, nil
+}
+
+// GetGoal returns a goal with its updates
+func (s *GoalsService) GetGoal(ctx context.Context, id string) (*models.Goal, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid goal ID: %w", err)
+	}
+	goal, err := s.goalsRepo.FindByID(ctx, objID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("goal not found")
+		}
+		return nil, fmt.Errorf("failed to find goal: %w", err)
+	}
+	return &markOverdue([]models.Goal{*goal})[0], nil
+}
+
+// UpdateGoal sets a goal's status by hand, e.g. to correct the AI
+func (s *GoalsService) UpdateGoal(ctx context.Context, id string, req *models.UpdateGoalRequest) (*models.Goal, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid goal ID: %w", err)
+	}
+	if !isGoalStatus(req.Status) {
+		return nil, fmt.Errorf("invalid status: must be one of %s", strings.Join(models.GoalStatuses, ", "))
+	}
+
+	found, err := s.goalsRepo.Update(ctx, objID, bson.M{"$set": bson.M{"status": req.Status, "updated_at": time.Now()}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update goal: %w", err)
+	}
+	if !found {
+		return nil, fmt.Errorf("goal not found")
+	}
+	return s.GetGoal(ctx, id)
+}
+
+// DeleteGoal removes a goal; the notes it was extracted from are kept
+func (s *GoalsService) DeleteGoal(ctx context.Context, id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid goal ID: %w", err)
+	}
+
+	deleted, err := s.goalsRepo.Delete(ctx, objID)
+	if err != nil {
+		return fmt.Errorf("failed to delete goal: %w", err)
+	}
+	if deleted == 0 {
+		return fmt.Errorf("goal not found")
+	}
+	return nil
+}
+
+// summarizeProgress generates a goal's progress summary from its most recent updates
+// Falls back to the latest update's own summary when the LLM fails or privacy mode is on
+func (s *GoalsService) summarizeProgress(goal *models.Goal) string {
+	updates := goal.Updates
+	if len(updates) > config.GOAL_PROGRESS_UPDATES {
+		updates = updates[len(updates)-config.GOAL_PROGRESS_UPDATES:]
+	}
+	latest := updates[len(updates)-1].Summary
+	if s.cfg.PrivacyMode {
+		return latest
+	}
+
+	var evidence strings.Builder
+	fmt.Fprintf(&evidence, "Goal: %s\n", goal.Title)
+	if goal.TargetDate != nil {
+		fmt.Fprintf(&evidence, "Target date: %s\n", goal.TargetDate.Format(heatmapDateFormat))
+	}
+	evidence.WriteString("Updates, oldest first:\n")
+	for _, update := range updates {
+		fmt.Fprintf(&evidence, "- %s (%s): %s\n", update.CreatedAt.Format(heatmapDateFormat), update.Status, update.Summary)
+	}
+
+	progress, err := s.aiClient.GenerateSummaryWithPrompt(evidence.String(), goalProgressPrompt)
+	if err != nil {
+		log.Printf("Failed to summarize progress of goal %s: %v", goal.ID.Hex(), err)
+		return latest
+	}
+	return strings.TrimSpace(progress)
+}
+
+// markOverdue flags active goals whose target day has ended
+func markOverdue(goals []models.Goal) []models.Goal {
+	now := time.Now()
+	for i := range goals {
+		goal := &goals[i]
+		goal.Overdue = goal.Status == models.GoalStatusActive && goal.TargetDate != nil && goal.TargetDate.AddDate(0, 0, 1).Before(now)
+	}
+	return goals
+}
+
+// hasGoalUpdateFrom reports whether a goal already has an update from a note
+func hasGoalUpdateFrom(goal *models.Goal, noteID primitive.ObjectID) bool {
+	for _, update := range goal.Updates {
+		if update.NoteID == noteID {
+			return true
+		}
+	}
+	return false
+}
+
+// isGoalStatus reports whether a goal status is known
+func isGoalStatus(status string) bool {
+	for _, s := range models.GoalStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// isGoalUpdateCategory reports whether notes in a category are checked for goals
+func isGoalUpdateCategory(category string) bool {
+	for _, c := range config.GOAL_UPDATE_CATEGORIES {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,259 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GraphService maintains the knowledge graph linking notes to the entities they mention and to
+// their most similar notes. Processed and deleted notes are queued from note events and their
+// edges refreshed in the background.
+type GraphService struct {
+	graphRepo     *repository.GraphRepository
+	notesRepo     *repository.NotesRepository
+	searchService *SearchService // Nil without vector search; notes then get no similarity edges
+
+	pending    chan primitive.ObjectID
+	stop       chan struct{}
+	wg         sync.WaitGroup
+	rebuilding atomic.Bool
+}
+
+// NewGraphService creates a new GraphService
+func NewGraphService(graphRepo *repository.GraphRepository, notesRepo *repository.NotesRepository, searchService *SearchService) *GraphService {
+	return &GraphService{
+		graphRepo:     graphRepo,
+		notesRepo:     notesRepo,
+		searchService: searchService,
+		pending:       make(chan primitive.ObjectID, config.GRAPH_QUEUE_SIZE),
+	}
+}
+
+// graphNoteNode returns the graph node ID of a note
+func graphNoteNode(noteID primitive.ObjectID) string {
+	return models.GraphNodeNote + ":" + noteID.Hex()
+}
+
+// graphEntityNode returns the graph node ID of an entity; names differing only in case share a node
+func graphEntityNode(entity models.NoteEntity) string {
+	return models.GraphNodeEntity + ":" + entity.Type + ":" + strings.ToLower(entity.Name)
+}
+
+// HandleEvent queues processed and deleted notes to have their edges refreshed; subscribe it to
+// the WebhookService. Never blocks: when the queue is full the note is skipped, and is picked up
+// by the next POST /admin/graph/rebuild.
+func (s *GraphService) HandleEvent(event models.NoteEvent) {
+	if event.Type != models.NoteEventProcessed && event.Type != models.NoteEventDeleted {
+		return
+	}
+
+	select {
+	case s.pending <- event.NoteID:
+	default:
+		log.Printf("Graph queue full, skipped note %s", event.NoteID.Hex())
+	}
+}
+
+// Start launches the refresh loop
+func (s *GraphService) Start() {
+	s.stop = make(chan struct{})
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			select {
+			case id := <-s.pending:
+				if err := s.RefreshNote(context.Background(), id); err != nil {
+					log.Printf("Failed to refresh graph edges of note %s: %v", id.Hex(), err)
+				}
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+	log.Println("Started knowledge graph maintenance")
+}
+
+// Stop ends the refresh loop and waits for the note in progress to finish
+func (s *GraphService) Stop() {
+	if s.stop == nil {
+		return
+	}
+	close(s.stop)
+	s.wg.Wait()
+}
+
+// RefreshNote replaces a note's edges: one to each entity it mentions and, once it is indexed, one
+// to each of its GRAPH_SIMILAR_NOTES most similar notes at GRAPH_MIN_SIMILARITY or above.
+// A deleted note's edges are removed, along with the edges of other notes pointing at it.
+func (s *GraphService) RefreshNote(ctx context.Context, noteID primitive.ObjectID) error {
+	note, err := s.notesRepo.FindByID(ctx, noteID)
+	if err == mongo.ErrNoDocuments {
+		return s.graphRepo.DeleteNode(ctx, noteID, graphNoteNode(noteID))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find note: %w", err)
+	}
+
+	source := graphNoteNode(note.ID)
+	now := time.Now()
+	var edges []models.GraphEdge
+	for _, entity := range note.Entities {
+		edges = append(edges, models.GraphEdge{
+			NoteID:    note.ID,
+			Source:    source,
+			Target:    graphEntityNode(entity),
+			Type:      models.GraphEdgeMention,
+			Weight:    1,
+			UpdatedAt: now,
+		})
+	}
+
+	// Only indexed notes have stored embeddings; others would have to be embedded just for the graph
+	if s.searchService != nil && note.ProcessingStatus == models.ProcessingIndexed {
+		related, err := s.searchService.RelatedNotes(ctx, note.ID.Hex(), config.GRAPH_SIMILAR_NOTES)
+		if err != nil {
+			return fmt.Errorf("failed to find similar notes: %w", err)
+		}
+		for _, result := range related {
+			if result.Score < config.GRAPH_MIN_SIMILARITY {
+				continue
+			}
+			edges = append(edges, models.GraphEdge{
+				NoteID:    note.ID,
+				Source:    source,
+				Target:    graphNoteNode(result.Note.ID),
+				Type:      models.GraphEdgeSimilar,
+				Weight:    result.Score,
+				UpdatedAt: now,
+			})
+		}
+	}
+
+	if err := s.graphRepo.ReplaceNoteEdges(ctx, note.ID, edges); err != nil {
+		return fmt.Errorf("failed to save graph edges: %w", err)
+	}
+	return nil
+}
+
+// Rebuild refreshes the edges of every unarchived note in the background, e.g. for notes saved
+// before the graph was kept, and returns how many notes it will refresh
+func (s *GraphService) Rebuild(ctx context.Context) (int, error) {
+	if !s.rebuilding.CompareAndSwap(false, true) {
+		return 0, fmt.Errorf("graph rebuild already running")
+	}
+
+	opts := options.Find().SetProjection(bson.M{"_id": 1})
+	notes, err := s.notesRepo.FindAll(ctx, notesFilter(models.SearchFilters{}), opts)
+	if err != nil {
+		s.rebuilding.Store(false)
+		return 0, fmt.Errorf("failed to fetch notes: %w", err)
+	}
+
+	go func() {
+		defer s.rebuilding.Store(false)
+		failed := 0
+		for _, note := range notes {
+			if err := s.RefreshNote(context.Background(), note.ID); err != nil {
+				log.Printf("Failed to refresh graph edges of note %s: %v", note.ID.Hex(), err)
+				failed++
+			}
+		}
+		log.Printf("Rebuilt the knowledge graph of %d notes, %d failed", len(notes), failed)
+	}()
+	return len(notes), nil
+}
+
+// GetGraph returns the most recent unarchived notes, optionally of one category, with the entities
+// they mention and the similarity edges among them. Similarity found from both notes is one edge.
+func (s *GraphService) GetGraph(ctx context.Context, category string, limit int) (*models.Graph, error) {
+	filters := models.SearchFilters{}
+	if category != "" {
+		if !config.IsValidCategory(category) {
+			return nil, fmt.Errorf("invalid category: %q", category)
+		}
+		filters.Categories = []string{category}
+	}
+
+	opts := options.Find().
+		SetProjection(bson.M{"title": 1, "category": 1, "entities": 1}).
+		SetSort(bson.M{"created": -1}).
+		SetLimit(int64(limit))
+	notes, err := s.notesRepo.FindAll(ctx, notesFilter(filters), opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch notes: %w", err)
+	}
+
+	graph := &models.Graph{Nodes: []models.GraphNode{}, Edges: []models.GraphEdge{}}
+	nodes := make(map[string]int) // Node ID to its position in graph.Nodes
+	sources := make([]string, 0, len(notes))
+	entityLabels := make(map[string]models.NoteEntity)
+	for _, note := range notes {
+		id := graphNoteNode(note.ID)
+		nodes[id] = len(graph.Nodes)
+		graph.Nodes = append(graph.Nodes, models.GraphNode{ID: id, Type: models.GraphNodeNote, Label: note.Title, Category: note.Category})
+		sources = append(sources, id)
+		for _, entity := range note.Entities {
+			// Notes are newest first, so entities are labeled as most recently written
+			if _, ok := entityLabels[graphEntityNode(entity)]; !ok {
+				entityLabels[graphEntityNode(entity)] = entity
+			}
+		}
+	}
+	if len(sources) == 0 {
+		return graph, nil
+	}
+
+	edges, err := s.graphRepo.FindBySources(ctx, sources)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch graph edges: %w", err)
+	}
+
+	similar := make(map[[2]string]int) // Unordered note pair to its edge's position in graph.Edges
+	for _, edge := range edges {
+		switch edge.Type {
+		case models.GraphEdgeMention:
+			if _, ok := nodes[edge.Target]; !ok {
+				entity, ok := entityLabels[edge.Target]
+				if !ok {
+					continue // The note's entities changed since its edges were saved
+				}
+				nodes[edge.Target] = len(graph.Nodes)
+				graph.Nodes = append(graph.Nodes, models.GraphNode{ID: edge.Target, Type: models.GraphNodeEntity, Label: entity.Name, EntityType: entity.Type})
+			}
+		case models.GraphEdgeSimilar:
+			if _, ok := nodes[edge.Target]; !ok {
+				continue // Similar to a note outside the graph
+			}
+			pair := [2]string{min(edge.Source, edge.Target), max(edge.Source, edge.Target)}
+			if i, ok := similar[pair]; ok {
+				graph.Edges[i].Weight = max(graph.Edges[i].Weight, edge.Weight)
+				continue
+			}
+			similar[pair] = len(graph.Edges)
+		default:
+			continue
+		}
+		graph.Edges = append(graph.Edges, edge)
+	}
+
+	for _, edge := range graph.Edges {
+		graph.Nodes[nodes[edge.Source]].Degree++
+		graph.Nodes[nodes[edge.Target]].Degree++
+	}
+	return graph, nil
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// JobsService lets admins inspect the embedding job queue and recover failed jobs
+type JobsService struct {
+	jobsRepo       *repository.JobsRepository
+	deadLetterRepo *repository.DeadLetterRepository
+	notesRepo      *repository.NotesRepository
+	workerPool     *WorkerPool // Optional; nil leaves requeued jobs for the next poll
+}
+
+// NewJobsService creates a new JobsService
+func NewJobsService(jobsRepo *repository.JobsRepository, deadLetterRepo *repository.DeadLetterRepository, notesRepo *repository.NotesRepository, workerPool *WorkerPool) *JobsService {
+	return &JobsService{
+		jobsRepo:       jobsRepo,
+		deadLetterRepo: deadLetterRepo,
+		notesRepo:      notesRepo,
+		workerPool:     workerPool,
+	}
+}
+
+// GetJobs lists queued jobs with the given status (all for empty), oldest first, up to MAX_LISTED_JOBS
+func (s *JobsService) GetJobs(ctx context.Context, status string) ([]models.ProcessingJob, error) {
+	switch status {
+	case "", models.JobPending, models.JobRunning:
+	default:
+		return nil, fmt.Errorf("invalid status: must be pending or running")
+	}
+
+	jobs, err := s.jobsRepo.FindByStatus(ctx, status, config.MAX_LISTED_JOBS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// RetryJob runs a job again right away with a fresh set of attempts, whether it is waiting to be
+// retried or in the dead-letter queue. Running jobs can't be retried.
+func (s *JobsService) RetryJob(ctx context.Context, jobID string) (*models.ProcessingJob, error) {
+	objID, err := primitive.ObjectIDFromHex(jobID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid job ID: %w", err)
+	}
+
+	job, err := s.jobsRepo.Requeue(ctx, objID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		if _, findErr := s.jobsRepo.FindByID(ctx, objID); findErr == nil {
+			return nil, fmt.Errorf("job is running")
+		}
+
+		requeued, err := s.requeue(ctx, []primitive.ObjectID{objID})
+		if err != nil {
+			return nil, err
+		}
+		if len(requeued) == 0 {
+			return nil, fmt.Errorf("job not found")
+		}
+		return &requeued[0], nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to retry job: %w", err)
+	}
+
+	s.markPending(ctx, job.NoteID)
+	s.wake()
+	return job, nil
+}
+
+// GetDeadLetter lists the jobs that failed all their attempts, most recent failure first,
+// up to MAX_LISTED_JOBS
+func (s *JobsService) GetDeadLetter(ctx context.Context) ([]models.DeadLetterJob, error) {
+	jobs, err := s.deadLetterRepo.FindRecent(ctx, config.MAX_LISTED_JOBS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dead-letter jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+// RequeueDeadLetter moves dead-letter jobs back onto the queue with a fresh set of attempts,
+// e.g. after an outage; no IDs requeues every one. Returns the requeued jobs.
+func (s *JobsService) RequeueDeadLetter(ctx context.Context, req *models.RequeueDeadLetterRequest) ([]models.ProcessingJob, error) {
+	var objIDs []primitive.ObjectID
+	for _, id := range req.IDs {
+		objID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid requeue: invalid job ID %q", id)
+		}
+		objIDs = append(objIDs, objID)
+	}
+
+	return s.requeue(ctx, objIDs)
+}
+
+// requeue moves the given dead-letter jobs (all for nil IDs) back onto the queue
+// Each job is queued before it leaves the dead-letter queue, so a failure part way never loses one.
+func (s *JobsService) requeue(ctx context.Context, ids []primitive.ObjectID) ([]models.ProcessingJob, error) {
+	deadJobs, err := s.deadLetterRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dead-letter jobs: %w", err)
+	}
+
+	requeued := []models.ProcessingJob{}
+	for _, deadJob := range deadJobs {
+		job := deadJob.ProcessingJob
+		if err := s.jobsRepo.Restore(ctx, &job); err != nil {
+			return requeued, fmt.Errorf("failed to requeue job %s: %w", job.ID.Hex(), err)
+		}
+		if err := s.deadLetterRepo.Delete(ctx, job.ID); err != nil {
+			log.Printf("Failed to remove requeued job %s from the dead-letter queue: %v", job.ID.Hex(), err)
+		}
+		s.markPending(ctx, job.NoteID)
+		requeued = append(requeued, job)
+	}
+
+	if len(requeued) > 0 {
+		log.Printf("Requeued %d dead-letter embedding jobs", len(requeued))
+		s.wake()
+	}
+	return requeued, nil
+}
+
+// GetIndexingReport counts notes by processing status and lists the ones not fully indexed for
+// search, up to MAX_LISTED_JOBS
+func (s *JobsService) GetIndexingReport(ctx context.Context) (*models.IndexingReport, error) {
+	counts, err := s.notesRepo.ProcessingStatusCounts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count notes: %w", err)
+	}
+	if unknown, ok := counts[""]; ok {
+		delete(counts, "")
+		counts["unknown"] = unknown
+	}
+
+	statuses := []string{models.ProcessingPending, models.ProcessingRunning, models.ProcessingFailed}
+	incomplete, err := s.notesRepo.FindByProcessingStatus(ctx, statuses, config.MAX_LISTED_JOBS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get incomplete notes: %w", err)
+	}
+
+	return &models.IndexingReport{StatusCounts: counts, Incomplete: incomplete}, nil
+}
+
+// markPending shows a requeued job's note as waiting to be processed again
+func (s *JobsService) markPending(ctx context.Context, noteID primitive.ObjectID) {
+	update := bson.M{"$set": bson.M{"processing_status": models.ProcessingPending}}
+	if err := s.notesRepo.Update(ctx, noteID, update); err != nil {
+		log.Printf("Failed to update processing status of note %s: %v", noteID.Hex(), err)
+	}
+}
+
+// wake prompts the worker pool, when there is one, to pick up requeued jobs
+func (s *JobsService) wake() {
+	if s.workerPool != nil {
+		s.workerPool.Wake()
+	}
+}
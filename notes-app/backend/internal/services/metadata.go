@@ -0,0 +1,56 @@
+package services
+
+import (
+	"log"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// inferMetadata asks the AI where pasted content came from and returns the fields it found
+// Inferred fields are listed in metadata.inferred so they can be told apart from supplied ones.
+// The URL must appear in the content and the date must not be in the future, which keeps
+// guesses out; a failed extraction leaves the metadata empty.
+func (s *NotesService) inferMetadata(content string) map[string]interface{} {
+	metadata := make(map[string]interface{})
+
+	extracted, err := s.aiClient.ExtractMetadata(content)
+	if err != nil {
+		log.Printf("Failed to infer note metadata: %v", err)
+		return metadata
+	}
+
+	var inferred []string
+	set := func(key, value string) {
+		if value = strings.TrimSpace(value); value != "" {
+			metadata[key] = value
+			inferred = append(inferred, key)
+		}
+	}
+	set("author", extracted.Author)
+	set("source", extracted.Source)
+	if published, err := time.Parse(heatmapDateFormat, strings.TrimSpace(extracted.PublishedDate)); err == nil && published.Before(time.Now()) {
+		set("timestamp", published.Format(time.RFC3339))
+	}
+	if parsed, err := url.Parse(extracted.URL); err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") &&
+		parsed.Host != "" && strings.Contains(content, extracted.URL) {
+		set("url", extracted.URL)
+	}
+
+	if len(inferred) > 0 {
+		metadata["inferred"] = inferred
+		log.Printf("Inferred note metadata: %v", inferred)
+	}
+	return metadata
+}
+
+// isInferred reports whether inferMetadata set a metadata field
+func isInferred(metadata map[string]interface{}, key string) bool {
+	inferred, _ := metadata["inferred"].([]string)
+	for _, field := range inferred {
+		if field == key {
+			return true
+		}
+	}
+	return false
+}
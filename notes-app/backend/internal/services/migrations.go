@@ -0,0 +1,586 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"backend/internal/ai"
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/vectordb"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MigrationService runs the bulk AI migrations over existing notes
+// Notes are processed by a bounded group of workers. When the AI API rate-limits a call, every
+// worker pauses with exponential backoff before retrying. Progress is checkpointed in note ID
+// order, so an interrupted run can resume where it stopped.
+type MigrationService struct {
+	notesRepo      *repository.NotesRepository
+	runsRepo       *repository.MigrationRunsRepository
+	aiClient       ai.Client
+	qdrantClient   *vectordb.QdrantClient
+	rulesService   *RulesService
+	reviewService  *ReviewService
+	summaryService *SummaryService
+	aiHistory      *AIHistoryService
+	cfg            *config.Config
+
+	mu     sync.Mutex
+	active map[string]bool // Kinds with a run in progress in this process
+
+	// Runs started in the background outlive their request; Stop cancels them
+	background context.Context
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+}
+
+// NewMigrationService creates a new MigrationService
+func NewMigrationService(
+	notesRepo *repository.NotesRepository,
+	runsRepo *repository.MigrationRunsRepository,
+	aiClient ai.Client,
+	qdrantClient *vectordb.QdrantClient,
+	rulesService *RulesService,
+	reviewService *ReviewService,
+	summaryService *SummaryService,
+	aiHistory *AIHistoryService,
+	cfg *config.Config,
+) *MigrationService {
+	background, cancel := context.WithCancel(context.Background())
+	return &MigrationService{
+		notesRepo:      notesRepo,
+		runsRepo:       runsRepo,
+		aiClient:       aiClient,
+		qdrantClient:   qdrantClient,
+		rulesService:   rulesService,
+		reviewService:  reviewService,
+		summaryService: summaryService,
+		aiHistory:      aiHistory,
+		cfg:            cfg,
+		active:         make(map[string]bool),
+		background:     background,
+		cancel:         cancel,
+	}
+}
+
+// Stop cancels the runs in progress in the background and waits for them to record their progress
+// They are left interrupted, to be resumed by ResumeResummarize on the next start.
+func (s *MigrationService) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// MigrationOptions are the per-request settings of a migration run
+type MigrationOptions struct {
+	Parallelism int  // Notes processed at once; 0 uses the configured default
+	PerMinute   int  // Notes started per minute; 0 doesn't throttle (re-summarization uses the configured default)
+	Resume      bool // Continue the latest unfinished run of the same kind, if there is one
+	Outdated    bool // Titles and summaries: only notes whose field an older model or prompt generated (see outdatedFilter)
+}
+
+// outdatedFields are the provenance fields of the migrations that regenerate a generated field
+var outdatedFields = map[string]string{
+	models.MigrationTitles:    "title_generation",
+	models.MigrationSummaries: "summary_generation",
+}
+
+// migrationOutcome is what processing one note adds to a run's counts
+type migrationOutcome struct {
+	succeeded   bool
+	failed      bool
+	ruleMatched bool
+	needsReview bool
+}
+
+// classifyFilter selects the notes a classification covers: those without a category
+var classifyFilter = bson.M{
+	"$or": []bson.M{
+		{"category": bson.M{"$exists": false}},
+		{"category": ""},
+	},
+}
+
+// migrateFunc processes one note; it returns an error only for rate limits and cancellation,
+// recording other failures in the outcome
+type migrateFunc func(ctx context.Context, note *models.Note) (migrationOutcome, error)
+
+// ClassifyNotes assigns categories to notes that have none, trying category rules before the AI
+// classifier and flagging low-confidence classifications for review
+func (s *MigrationService) ClassifyNotes(ctx context.Context, opts MigrationOptions) (*models.MigrationRun, error) {
+	examples := s.reviewService.FewShotExamples(ctx)
+
+	return s.run(ctx, models.MigrationClassify, classifyFilter, opts, func(ctx context.Context, note *models.Note) (migrationOutcome, error) {
+		var outcome migrationOutcome
+		update := bson.M{}
+
+		// Heuristic rules are cheaper than the LLM, so try them first
+		match, err := s.rulesService.Evaluate(ctx, note.Title, note.Content, note.Metadata)
+		if err != nil {
+			log.Printf("Failed to evaluate rules for note %s: %v", note.ID.Hex(), err)
+		}
+
+		var confidence float64
+		unset := bson.M{"category_generation": ""}
+		if match != nil {
+			update["category"] = match.Category
+			if len(match.Tags) > 0 {
+				update["tags"] = mergeTags(note.Tags, match.Tags)
+			}
+			confidence = 1
+			outcome.ruleMatched = true
+		} else {
+			category, categoryConfidence, err := s.aiClient.ClassifyNote(note.Title, note.Content, examples)
+			if ai.IsRateLimited(err) {
+				return outcome, err
+			}
+			s.aiHistory.Record(ctx, note.ID, AICall{Operation: models.AIOperationClassification, Content: note.Title + "\n" + note.Content, Output: category, Err: err})
+			if err != nil {
+				log.Printf("Failed to classify note %s: %v", note.ID.Hex(), err)
+				category = "other"
+				categoryConfidence = 0
+				outcome.failed = true
+			}
+			update["category"] = category
+			confidence = categoryConfidence
+			if err == nil {
+				update["category_generation"] = newGeneration(s.cfg, models.AIOperationClassification)
+				unset = nil
+			}
+		}
+		update["category_confidence"] = confidence
+
+		// Low-confidence classifications are kept but flagged for human review
+		if s.reviewService.NeedsReview(confidence) {
+			update["needs_review"] = true
+			outcome.needsReview = true
+		}
+
+		changes := bson.M{"$set": update}
+		if unset != nil {
+			changes["$unset"] = unset
+		}
+		if err := s.notesRepo.Update(ctx, note.ID, changes); err != nil {
+			log.Printf("Failed to update note %s with category: %v", note.ID.Hex(), err)
+			outcome.failed = true
+			return outcome, nil
+		}
+		// A note embedded before it was classified has points without its category
+		if err := s.qdrantClient.SetNoteCategory([]primitive.ObjectID{note.ID}, update["category"].(string)); err != nil {
+			log.Printf("Failed to update the category of note %s in Qdrant: %v", note.ID.Hex(), err)
+		}
+		outcome.succeeded = true
+		return outcome, nil
+	})
+}
+
+// RegenerateTitles regenerates the titles of all notes
+func (s *MigrationService) RegenerateTitles(ctx context.Context, opts MigrationOptions) (*models.MigrationRun, error) {
+	return s.run(ctx, models.MigrationTitles, bson.M{}, opts, func(ctx context.Context, note *models.Note) (migrationOutcome, error) {
+		newTitle, err := s.aiClient.GenerateTitle(note.Content)
+		if ai.IsRateLimited(err) {
+			return migrationOutcome{}, err
+		}
+		s.aiHistory.Record(ctx, note.ID, AICall{Operation: models.AIOperationTitle, Content: note.Content, Output: newTitle, Err: err})
+		if err != nil {
+			log.Printf("Failed to generate title for note %s: %v", note.ID.Hex(), err)
+			return migrationOutcome{failed: true}, nil
+		}
+
+		update := bson.M{"title": newTitle, "title_generation": newGeneration(s.cfg, models.AIOperationTitle)}
+		if err := s.notesRepo.Update(ctx, note.ID, bson.M{"$set": update}); err != nil {
+			log.Printf("Failed to update title for note %s: %v", note.ID.Hex(), err)
+			return migrationOutcome{failed: true}, nil
+		}
+		log.Printf("Updated title for note %s: %s -> %s", note.ID.Hex(), note.Title, newTitle)
+		return migrationOutcome{succeeded: true}, nil
+	})
+}
+
+// resummarizeFilter selects the notes a re-summarization covers: every note with content
+var resummarizeFilter = bson.M{"content": bson.M{"$nin": []interface{}{"", nil}}}
+
+// EstimateResummarize works out the tokens, cost and time re-summarizing every note would take,
+// without calling the AI; with opts.Resume it covers only the notes an unfinished run has left
+func (s *MigrationService) EstimateResummarize(ctx context.Context, opts MigrationOptions) (*models.AIOperationEstimate, error) {
+	return s.estimate(ctx, models.MigrationSummaries, opts)
+}
+
+// StartResummarize re-summarizes every note with its channel's current prompt in the background,
+// starting at most opts.PerMinute notes a minute, and returns the run's estimate up front
+// The run is checkpointed like the other migrations: GET /admin/resummarize follows it, and one
+// cut short by a restart resumes on the next start or with opts.Resume.
+func (s *MigrationService) StartResummarize(ctx context.Context, opts MigrationOptions) (*models.AIOperationEstimate, error) {
+	if err := s.checkResummarize(opts); err != nil {
+		return nil, err
+	}
+	kind := models.MigrationSummaries
+	if !s.begin(kind) {
+		return nil, fmt.Errorf("migration already running: a %s run is in progress", kind)
+	}
+
+	estimate, err := s.estimate(ctx, kind, opts)
+	if err != nil {
+		s.end(kind)
+		return nil, err
+	}
+	opts.PerMinute = estimate.PerMinute
+	log.Printf("Starting re-summarization of %d notes at %d a minute: ~%d input and ~%d output tokens, ~%.0f minutes",
+		estimate.Notes, estimate.PerMinute, estimate.InputTokens, estimate.OutputTokens, estimate.EstimatedMinutes)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer s.end(kind)
+		if _, err := s.execute(s.background, kind, resummarizeFilter, opts, s.resummarize); err != nil {
+			log.Printf("Re-summarization stopped: %v", err)
+		}
+	}()
+	return estimate, nil
+}
+
+// ResumeResummarize continues a re-summarization that a restart cut short, at the rate it was started with
+func (s *MigrationService) ResumeResummarize(ctx context.Context) {
+	unfinished, err := s.runsRepo.FindUnfinished(ctx, models.MigrationSummaries)
+	if err != nil {
+		if err != mongo.ErrNoDocuments {
+			log.Printf("Failed to check for an unfinished re-summarization: %v", err)
+		}
+		return
+	}
+	if _, err := s.StartResummarize(ctx, MigrationOptions{Resume: true, Outdated: unfinished.Outdated}); err != nil {
+		log.Printf("Failed to resume re-summarization: %v", err)
+	}
+}
+
+// GetLatestRun returns the most recent run of a kind
+func (s *MigrationService) GetLatestRun(ctx context.Context, kind string) (*models.MigrationRun, error) {
+	run, err := s.runsRepo.FindLatest(ctx, kind)
+	if err == mongo.ErrNoDocuments {
+		return nil, fmt.Errorf("%s run not found", kind)
+	}
+	return run, err
+}
+
+// resummarize regenerates one note's summary; failures other than rate limits leave the note's
+// summary as it was and count as errors
+func (s *MigrationService) resummarize(ctx context.Context, note *models.Note) (migrationOutcome, error) {
+	err := s.summaryService.ResummarizeNote(ctx, note)
+	if ai.IsRateLimited(err) {
+		return migrationOutcome{}, err
+	}
+	if err != nil {
+		log.Printf("Failed to re-summarize note %s: %v", note.ID.Hex(), err)
+		return migrationOutcome{failed: true}, nil
+	}
+	return migrationOutcome{succeeded: true}, nil
+}
+
+// checkResummarize rejects re-summarizations that can't run: privacy mode keeps notes from the LLM
+func (s *MigrationService) checkResummarize(opts MigrationOptions) error {
+	if s.cfg.PrivacyMode {
+		return fmt.Errorf("invalid request: notes are not summarized by the LLM in privacy mode")
+	}
+	if opts.PerMinute < 0 || opts.PerMinute > config.MAX_RESUMMARIZE_PER_MINUTE {
+		return fmt.Errorf("invalid perMinute: must be between 1 and %d", config.MAX_RESUMMARIZE_PER_MINUTE)
+	}
+	return nil
+}
+
+// resummarizeRate returns the notes per minute of a re-summarization: the requested rate, else
+// the rate the resumed run was started with, else the configured default
+func (s *MigrationService) resummarizeRate(opts MigrationOptions, unfinished *models.MigrationRun) int {
+	if opts.PerMinute > 0 {
+		return opts.PerMinute
+	}
+	if unfinished != nil && unfinished.PerMinute > 0 {
+		return unfinished.PerMinute
+	}
+	return max(1, min(s.cfg.ResummarizePerMinute, config.MAX_RESUMMARIZE_PER_MINUTE))
+}
+
+// GetRuns returns the most recent migration runs, newest first
+func (s *MigrationService) GetRuns(ctx context.Context) ([]models.MigrationRun, error) {
+	return s.runsRepo.FindRecent(ctx, config.MIGRATION_RUNS_LIMIT)
+}
+
+// run applies a migration to the notes matching the filter, in note ID order
+func (s *MigrationService) run(ctx context.Context, kind string, filter bson.M, opts MigrationOptions, migrate migrateFunc) (*models.MigrationRun, error) {
+	if !s.begin(kind) {
+		return nil, fmt.Errorf("migration already running: a %s run is in progress", kind)
+	}
+	defer s.end(kind)
+	return s.execute(ctx, kind, filter, opts, migrate)
+}
+
+// execute runs a migration whose kind the caller has claimed with begin
+func (s *MigrationService) execute(ctx context.Context, kind string, filter bson.M, opts MigrationOptions, migrate migrateFunc) (*models.MigrationRun, error) {
+	parallelism := opts.Parallelism
+	if parallelism <= 0 {
+		parallelism = s.cfg.MigrationParallelism
+	}
+	parallelism = max(1, min(parallelism, config.MAX_MIGRATION_PARALLELISM))
+
+	run, query, err := s.pendingQuery(ctx, kind, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	notes, err := s.notesRepo.FindAll(ctx, query, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find notes: %w", err)
+	}
+
+	now := time.Now()
+	if run == nil {
+		run = &models.MigrationRun{Kind: kind, Total: len(notes), StartedAt: now}
+	} else {
+		run.Resumes++
+		log.Printf("Resuming %s migration %s: %d of %d notes done, %d left", kind, run.ID.Hex(), run.Processed, run.Total, len(notes))
+	}
+	run.Status = models.MigrationRunning
+	run.Parallelism = parallelism
+	run.Outdated = opts.Outdated
+	if opts.PerMinute > 0 {
+		run.PerMinute = opts.PerMinute
+	}
+	run.UpdatedAt = now
+	if run.ID.IsZero() {
+		if run.ID, err = s.runsRepo.Create(ctx, run); err != nil {
+			return nil, fmt.Errorf("failed to record migration run: %w", err)
+		}
+	} else if err := s.runsRepo.Save(ctx, run); err != nil {
+		return nil, fmt.Errorf("failed to record migration run: %w", err)
+	}
+
+	s.process(ctx, run, notes, parallelism, migrate)
+
+	if ctx.Err() != nil {
+		run.Status = models.MigrationInterrupted
+		s.save(run)
+		return run, fmt.Errorf("migration interrupted after %d of %d notes: %w", run.Processed, run.Total, ctx.Err())
+	}
+	finished := time.Now()
+	run.Status = models.MigrationCompleted
+	run.FinishedAt = &finished
+	s.save(run)
+	log.Printf("Completed %s migration %s: %d succeeded, %d errors, %d rate-limited calls", kind, run.ID.Hex(), run.Succeeded, run.Errors, run.RateLimited)
+	return run, nil
+}
+
+// pendingQuery returns the query for the notes a run of the kind has to migrate
+// With opts.Resume, the latest unfinished run is returned too, and the query only matches the notes
+// past its checkpoint. With opts.Outdated, titles and summaries only match notes whose field is outdated.
+func (s *MigrationService) pendingQuery(ctx context.Context, kind string, filter bson.M, opts MigrationOptions) (*models.MigrationRun, bson.M, error) {
+	if field, ok := outdatedFields[kind]; ok && opts.Outdated {
+		filter = bson.M{"$and": []bson.M{filter, outdatedFilter(s.cfg, field)}}
+	}
+
+	var run *models.MigrationRun
+	if opts.Resume {
+		unfinished, err := s.runsRepo.FindUnfinished(ctx, kind)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return nil, nil, fmt.Errorf("failed to find unfinished run: %w", err)
+		}
+		run = unfinished
+	}
+
+	if run != nil && !run.Checkpoint.IsZero() {
+		return run, bson.M{"$and": []bson.M{filter, {"_id": bson.M{"$gt": run.Checkpoint}}}}, nil
+	}
+	return run, filter, nil
+}
+
+// process runs the migration over the notes with a bounded group of workers
+// Results are counted in note order, so the checkpoint only ever passes finished notes; the
+// progress is saved every MIGRATION_CHECKPOINT_EVERY notes. A run with a PerMinute rate hands out
+// a note at most every minute/PerMinute.
+func (s *MigrationService) process(ctx context.Context, run *models.MigrationRun, notes []models.Note, parallelism int, migrate migrateFunc) {
+	type result struct {
+		index   int
+		outcome migrationOutcome
+	}
+
+	pause := &migrationPause{}
+	jobs := make(chan int)
+	results := make(chan result)
+
+	var wg sync.WaitGroup
+	for w := 0; w < parallelism; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				outcome, err := s.migrateWithRetry(ctx, pause, &notes[i], migrate)
+				if err != nil || ctx.Err() != nil {
+					continue // Cancelled; the note is left for a resumed run
+				}
+				results <- result{index: i, outcome: outcome}
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		var throttle <-chan time.Time
+		if run.PerMinute > 0 {
+			ticker := time.NewTicker(time.Minute / time.Duration(run.PerMinute))
+			defer ticker.Stop()
+			throttle = ticker.C
+		}
+		for i := range notes {
+			if throttle != nil && i > 0 {
+				select {
+				case <-throttle:
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	baseRateLimited := run.RateLimited
+	done := make([]*migrationOutcome, len(notes))
+	next, unsaved := 0, 0
+	for r := range results {
+		outcome := r.outcome
+		done[r.index] = &outcome
+		for next < len(notes) && done[next] != nil {
+			run.Processed++
+			if done[next].succeeded {
+				run.Succeeded++
+			}
+			if done[next].failed {
+				run.Errors++
+			}
+			if done[next].ruleMatched {
+				run.RuleMatched++
+			}
+			if done[next].needsReview {
+				run.NeedsReview++
+			}
+			run.Checkpoint = notes[next].ID
+			next++
+			unsaved++
+		}
+		if unsaved >= config.MIGRATION_CHECKPOINT_EVERY {
+			run.RateLimited = baseRateLimited + int(pause.hits.Load())
+			s.save(run)
+			unsaved = 0
+		}
+	}
+	run.RateLimited = baseRateLimited + int(pause.hits.Load())
+}
+
+// migrateWithRetry migrates a note, retrying after a pause while the AI API rate-limits it
+// A note still rate-limited after MIGRATION_MAX_RETRIES attempts counts as an error.
+func (s *MigrationService) migrateWithRetry(ctx context.Context, pause *migrationPause, note *models.Note, migrate migrateFunc) (migrationOutcome, error) {
+	for attempt := 0; ; attempt++ {
+		if err := pause.wait(ctx); err != nil {
+			return migrationOutcome{}, err
+		}
+		outcome, err := migrate(ctx, note)
+		if !ai.IsRateLimited(err) {
+			return outcome, err
+		}
+		if attempt+1 >= config.MIGRATION_MAX_RETRIES {
+			log.Printf("Giving up on note %s after %d rate-limited attempts: %v", note.ID.Hex(), attempt+1, err)
+			return migrationOutcome{failed: true}, nil
+		}
+		pause.extend(attempt)
+	}
+}
+
+// save records a run's progress; it outlives the request so an interrupted run is still recorded
+func (s *MigrationService) save(run *models.MigrationRun) {
+	run.UpdatedAt = time.Now()
+	if err := s.runsRepo.Save(context.Background(), run); err != nil {
+		log.Printf("Failed to save progress of migration %s: %v", run.ID.Hex(), err)
+	}
+}
+
+// begin claims the kind for a new run, reporting false when one is already in progress
+func (s *MigrationService) begin(kind string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.active[kind] {
+		return false
+	}
+	s.active[kind] = true
+	return true
+}
+
+func (s *MigrationService) end(kind string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.active, kind)
+}
+
+// migrationPause holds back every worker of a run after the AI API rate-limits a call
+type migrationPause struct {
+	mu    sync.Mutex
+	until time.Time
+	hits  atomic.Int64 // Rate-limited calls so far
+}
+
+// wait blocks until the pause is over or the context is cancelled
+func (p *migrationPause) wait(ctx context.Context) error {
+	for {
+		p.mu.Lock()
+		remaining := time.Until(p.until)
+		p.mu.Unlock()
+		if remaining <= 0 {
+			return ctx.Err()
+		}
+
+		timer := time.NewTimer(remaining)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// extend pauses all workers after a rate-limited attempt, doubling the pause with each retry
+func (p *migrationPause) extend(attempt int) {
+	backoff := time.Duration(min(config.MIGRATION_BACKOFF_SECONDS<<attempt, config.MIGRATION_MAX_BACKOFF_SECONDS)) * time.Second
+	p.hits.Add(1)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if until := time.Now().Add(backoff); until.After(p.until) {
+		p.until = until
+		log.Printf("AI API rate limit hit, pausing migration workers for %s", backoff)
+	}
+}
+
+// mergeTags appends new tags to existing ones without duplicates
+func mergeTags(existing, added []string) []string {
+	seen := make(map[string]bool)
+	merged := []string{}
+	for _, tag := range append(append([]string{}, existing...), added...) {
+		if !seen[tag] {
+			seen[tag] = true
+			merged = append(merged, tag)
+		}
+	}
+	return merged
+}
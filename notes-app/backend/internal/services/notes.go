@@ -4,16 +4,22 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	"backend/internal/ai"
+	"backend/internal/config"
 	"backend/internal/models"
 	"backend/internal/repository"
+	"backend/internal/utils"
 	"backend/internal/vectordb"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // NotesService handles business logic for note operations
@@ -21,9 +27,17 @@ type NotesService struct {
 	notesRepo           *repository.NotesRepository
 	chunksRepo          *repository.ChunksRepository
 	channelSettingsRepo *repository.ChannelSettingsRepository
+	auditRepo           *repository.AuditRepository
 	aiClient            ai.Client
 	qdrantClient        *vectordb.QdrantClient
 	workerPool          *WorkerPool
+	rulesService        *RulesService
+	reviewService       *ReviewService
+	webhookService      *WebhookService
+	pipelineService     *PipelineService
+	automationService   *AutomationService
+	aiHistory           *AIHistoryService
+	cfg                 *config.Config
 }
 
 // NewNotesService creates a new NotesService
@@ -31,27 +45,170 @@ func NewNotesService(
 	notesRepo *repository.NotesRepository,
 	chunksRepo *repository.ChunksRepository,
 	channelSettingsRepo *repository.ChannelSettingsRepository,
+	auditRepo *repository.AuditRepository,
 	aiClient ai.Client,
 	qdrantClient *vectordb.QdrantClient,
 	workerPool *WorkerPool,
+	rulesService *RulesService,
+	reviewService *ReviewService,
+	webhookService *WebhookService,
+	pipelineService *PipelineService,
+	automationService *AutomationService,
+	aiHistory *AIHistoryService,
+	cfg *config.Config,
 ) *NotesService {
 	return &NotesService{
 		notesRepo:           notesRepo,
 		chunksRepo:          chunksRepo,
 		channelSettingsRepo: channelSettingsRepo,
+		auditRepo:           auditRepo,
 		aiClient:            aiClient,
 		qdrantClient:        qdrantClient,
 		workerPool:          workerPool,
+		rulesService:        rulesService,
+		reviewService:       reviewService,
+		webhookService:      webhookService,
+		pipelineService:     pipelineService,
+		automationService:   automationService,
+		aiHistory:           aiHistory,
+		cfg:                 cfg,
 	}
 }
 
-// GetNotes retrieves notes with optional channel filter
-func (s *NotesService) GetNotes(ctx context.Context, channel string) ([]models.Note, error) {
-	filter := bson.M{}
+// GetNotes retrieves notes with optional channel, tag and collection filters; notes must carry every given tag
+// Archived notes are only returned when includeArchived is set, and pinnedOnly limits the list to pinned notes.
+// Pinned notes are listed first, otherwise notes keep their creation order.
+func (s *NotesService) GetNotes(ctx context.Context, channel string, tags []string, collection string, includeArchived, pinnedOnly bool) ([]models.Note, error) {
+	filter := listFilter(channel, tags, collection, includeArchived, pinnedOnly)
+	opts := options.Find().SetSort(bson.D{{Key: "pinned", Value: -1}, {Key: "_id", Value: 1}})
+	return s.notesRepo.FindAll(ctx, filter, opts)
+}
+
+// GetNotesPage retrieves a page of the notes GetNotes lists, skipping offset notes and returning at
+// most limit, so only the page is read from the database
+func (s *NotesService) GetNotesPage(ctx context.Context, channel string, tags []string, collection string, includeArchived, pinnedOnly bool, offset, limit int) ([]models.Note, error) {
+	filter := listFilter(channel, tags, collection, includeArchived, pinnedOnly)
+	opts := options.Find().
+		SetSort(bson.D{{Key: "pinned", Value: -1}, {Key: "_id", Value: 1}}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+	return s.notesRepo.FindAll(ctx, filter, opts)
+}
+
+// listFilter builds the filter of a GetNotes listing
+func listFilter(channel string, tags []string, collection string, includeArchived, pinnedOnly bool) bson.M {
+	filter := notesFilter(models.SearchFilters{Tags: tags, IncludeArchived: includeArchived})
 	if channel != "" {
 		filter["metadata.author"] = channel
 	}
-	return s.notesRepo.FindAll(ctx, filter)
+	if collection = strings.ToLower(strings.TrimSpace(collection)); collection != "" {
+		filter["collections"] = collection
+	}
+	if pinnedOnly {
+		filter["pinned"] = true
+	}
+	return filter
+}
+
+// GetNotesByChannels returns the unarchived notes of each channel, newest first and at most
+// limit per channel, keyed by channel name; channels match the note author exactly, as in GetNotes
+func (s *NotesService) GetNotesByChannels(ctx context.Context, channels []string, limit int) (map[string][]models.Note, error) {
+	byChannel := make(map[string][]models.Note, len(channels))
+	if len(channels) == 0 {
+		return byChannel, nil
+	}
+
+	filter := notesFilter(models.SearchFilters{})
+	filter["metadata.author"] = bson.M{"$in": channels}
+	opts := options.Find().SetSort(bson.M{"_id": -1})
+	err := s.notesRepo.ForEach(ctx, filter, func(note *models.Note) error {
+		author, _ := note.Metadata["author"].(string)
+		if len(byChannel[author]) < limit {
+			byChannel[author] = append(byChannel[author], *note)
+		}
+		return nil
+	}, opts)
+	if err != nil {
+		return nil, err
+	}
+	return byChannel, nil
+}
+
+// GetRelatedNotes returns, for each given note, up to limit other unarchived notes ranked by the
+// number of tags they share, newest first on ties
+// All notes are matched with a single query over the union of their tags, capped at
+// config.GRAPHQL_RELATED_CANDIDATES of the newest candidates.
+func (s *NotesService) GetRelatedNotes(ctx context.Context, notes []*models.Note, limit int) (map[primitive.ObjectID][]models.Note, error) {
+	related := make(map[primitive.ObjectID][]models.Note, len(notes))
+
+	tagSet := make(map[string]bool)
+	for _, note := range notes {
+		for _, tag := range note.Tags {
+			tagSet[tag] = true
+		}
+	}
+	if len(tagSet) == 0 {
+		return related, nil
+	}
+	tags := make([]string, 0, len(tagSet))
+	for tag := range tagSet {
+		tags = append(tags, tag)
+	}
+
+	filter := notesFilter(models.SearchFilters{})
+	filter["tags"] = bson.M{"$in": tags}
+	opts := options.Find().SetSort(bson.M{"_id": -1}).SetLimit(config.GRAPHQL_RELATED_CANDIDATES)
+	candidates, err := s.notesRepo.FindAll(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, note := range notes {
+		noteTags := make(map[string]bool, len(note.Tags))
+		for _, tag := range note.Tags {
+			noteTags[tag] = true
+		}
+
+		type scored struct {
+			note   models.Note
+			shared int
+		}
+		var matches []scored
+		for _, candidate := range candidates {
+			if candidate.ID == note.ID {
+				continue
+			}
+			shared := 0
+			for _, tag := range candidate.Tags {
+				if noteTags[tag] {
+					shared++
+				}
+			}
+			if shared > 0 {
+				matches = append(matches, scored{note: candidate, shared: shared})
+			}
+		}
+
+		// Candidates are newest first, so a stable sort keeps that order among ties
+		sort.SliceStable(matches, func(i, j int) bool {
+			return matches[i].shared > matches[j].shared
+		})
+		if len(matches) > limit {
+			matches = matches[:limit]
+		}
+
+		list := make([]models.Note, len(matches))
+		for i, match := range matches {
+			list[i] = match.note
+		}
+		related[note.ID] = list
+	}
+	return related, nil
+}
+
+// GetTags returns every tag in use with the number of notes carrying it, most used first
+func (s *NotesService) GetTags(ctx context.Context) ([]models.TagCount, error) {
+	return s.notesRepo.TagCounts(ctx, "", 0)
 }
 
 // CreateNoteResult holds the result of creating a note
@@ -63,27 +220,138 @@ type CreateNoteResult struct {
 
 // CreateNote creates a new note with AI analysis and queues embedding generation
 func (s *NotesService) CreateNote(ctx context.Context, req *models.CreateNoteRequest) (*CreateNoteResult, error) {
+	return s.createNote(ctx, req, nil)
+}
+
+// CreateNotes creates many notes for bulk imports, returning a result or error per request
+// Short notes that need a title or category are analyzed ANALYSIS_BATCH_SIZE at a time in a
+// single AI call; notes the batch couldn't analyze fall back to the usual per-note analysis.
+func (s *NotesService) CreateNotes(ctx context.Context, reqs []*models.CreateNoteRequest) ([]*CreateNoteResult, []error) {
+	results := make([]*CreateNoteResult, len(reqs))
+	errs := make([]error, len(reqs))
+
+	create := func(i int, analysis *models.NoteAnalysis) {
+		if errs[i] = ctx.Err(); errs[i] == nil {
+			results[i], errs[i] = s.createNote(ctx, reqs[i], analysis)
+		}
+	}
+
+	var batch []int
+	flush := func() {
+		analyses := s.analyzeBatch(ctx, reqs, batch)
+		for _, i := range batch {
+			create(i, analyses[i])
+		}
+		batch = batch[:0]
+	}
+	for i, req := range reqs {
+		if !batchAnalyzable(req) {
+			create(i, nil)
+			continue
+		}
+		batch = append(batch, i)
+		if len(batch) == config.ANALYSIS_BATCH_SIZE {
+			flush()
+		}
+	}
+	if len(batch) > 0 {
+		flush()
+	}
+	return results, errs
+}
+
+// batchAnalyzable reports whether a note can share an AI analysis call with other notes
+// Long notes and notes from platforms with transcripts, which also need a summary, can't.
+func batchAnalyzable(req *models.CreateNoteRequest) bool {
+	if (req.Title != "" && req.Category != "") || len(req.Content) > config.ANALYSIS_BATCH_MAX_CHARS {
+		return false
+	}
+	if platform, ok := req.Metadata["platform"].(string); ok {
+		if registered, found := config.LookupPlatform(platform); found && registered.HasTranscripts {
+			return false
+		}
+	}
+	return true
+}
+
+// analyzeBatch analyzes the requests at the given indexes in one AI call, keyed by index
+// A failed call is logged and leaves every note to be analyzed on its own.
+func (s *NotesService) analyzeBatch(ctx context.Context, reqs []*models.CreateNoteRequest, indexes []int) map[int]*models.NoteAnalysis {
+	analyses := make(map[int]*models.NoteAnalysis, len(indexes))
+	if len(indexes) < 2 || ctx.Err() != nil {
+		return analyses
+	}
+
+	contents := make([]string, len(indexes))
+	for j, i := range indexes {
+		contents[j] = reqs[i].Content
+	}
+	results, err := s.aiClient.AnalyzeNotes(contents, s.reviewService.FewShotExamples(ctx))
+	if err != nil {
+		log.Printf("Batch analysis of %d notes failed, analyzing them one by one: %v", len(indexes), err)
+		return analyses
+	}
+	for j, i := range indexes {
+		if j < len(results) && results[j] != nil {
+			analyses[i] = results[j]
+		}
+	}
+	log.Printf("Batch analysis covered %d of %d notes", len(analyses), len(indexes))
+	return analyses
+}
+
+// createNote creates a note, using a batch analysis in place of the AI analysis when given one
+func (s *NotesService) createNote(ctx context.Context, req *models.CreateNoteRequest, batchAnalysis *models.NoteAnalysis) (*CreateNoteResult, error) {
 	log.Printf("=== CREATE NOTE SERVICE CALLED ===")
 	log.Printf("Request parsed: Content length=%d, Metadata=%+v", len(req.Content), req.Metadata)
 
+	if req.Category != "" && !config.IsValidCategory(req.Category) {
+		return nil, fmt.Errorf("invalid category: %q", req.Category)
+	}
+	if err := utils.CheckStructuredData(req.StructuredData, config.MAX_STRUCTURED_DATA_BYTES, config.MAX_STRUCTURED_DATA_DEPTH); err != nil {
+		return nil, fmt.Errorf("invalid structuredData: %w", err)
+	}
+
 	// Initialize metadata if nil
 	metadata := req.Metadata
 	if metadata == nil {
 		metadata = make(map[string]interface{})
 	}
 
-	// Check if this is YouTube content (needs summary)
-	isYouTube := false
+	// Pasted content arrives without metadata; infer where it came from so it groups by channel
+	if len(metadata) == 0 && s.cfg.InferMetadata {
+		metadata = s.inferMetadata(req.Content)
+	}
+
+	// Normalize the platform to its canonical name and check if it carries transcripts (needs summary)
+	hasTranscript := false
 	if platform, exists := metadata["platform"]; exists {
-		if platformStr, ok := platform.(string); ok && platformStr == "youtube" {
-			isYouTube = true
+		platformStr, _ := platform.(string)
+		if strings.TrimSpace(platformStr) != "" {
+			registered, ok := config.LookupPlatform(platformStr)
+			if !ok {
+				return nil, fmt.Errorf("invalid platform: %q", platformStr)
+			}
+			metadata["platform"] = registered.Name
+			hasTranscript = registered.HasTranscripts
 		}
 	}
 
+	// The channel's pipeline, or the category's when the request gives one, decides whether the
+	// note is classified; once classified, its category's pipeline decides the remaining steps
+	author, _ := metadata["author"].(string)
+	pipelines := s.pipelineService.pipelinesOrDefault(ctx)
+	channelKey := utils.NormalizeChannelKey(author)
+	steps := pipelineSteps(pipelines, channelKey, req.Category)
+	classify := slices.Contains(steps, models.PipelineClassify)
+	summarize := slices.Contains(steps, models.PipelineSummarize)
+
 	// Check for custom prompt settings based on author/channel
+	// In privacy mode summaries never go through the LLM, so custom prompts are not used
 	var customPromptText, customPromptSchema string
-	if author, ok := metadata["author"].(string); ok && author != "" {
-		settings, err := s.channelSettingsRepo.FindByName(ctx, author)
+	if author != "" && summarize && !s.cfg.PrivacyMode {
+		platform, _ := metadata["platform"].(string)
+		settings, err := s.channelSettingsRepo.FindByChannel(ctx, platform, author)
 		if err == nil && settings != nil && (settings.PromptText != "" || settings.PromptSchema != "") {
 			customPromptText = settings.PromptText
 			customPromptSchema = settings.PromptSchema
@@ -91,57 +359,124 @@ func (s *NotesService) CreateNote(ctx context.Context, req *models.CreateNoteReq
 		}
 	}
 
-	// Use combined analysis if title not provided (single API call for title + category + optional summary)
+	// Evaluate heuristic category rules first; a match avoids the LLM classifier
+	// Tags from the request, the matching rule and the analysis are merged
+	tags := append([]string{}, req.Tags...)
+	ruleMatch, err := s.rulesService.Evaluate(ctx, req.Title, req.Content, metadata)
+	if err != nil {
+		log.Printf("Failed to evaluate category rules: %v", err)
+	}
+
 	var title, category, summary string
-	var structuredData map[string]interface{}
+	var confidence float64
+	structuredData := req.StructuredData
+	structuredDataTruncated := false
 
-	if req.Title == "" {
-		// Always get title and category from analyzeNote
-		// Only get summary from analyzeNote if no custom prompt exists
-		useDefaultSummary := customPromptText == "" && customPromptSchema == ""
-		analysis, err := s.aiClient.AnalyzeNote(req.Content, isYouTube && useDefaultSummary)
-		if err != nil {
-			log.Printf("Failed to analyze note: %v", err)
-			title = "Untitled Note"
-			category = "other"
+	// The note has no ID until it is stored, so its AI calls are recorded afterwards
+	var aiCalls []AICall
+	var titleGeneration, categoryGeneration, summaryGeneration *models.GenerationInfo
+
+	title = req.Title
+	if req.Category != "" {
+		category = req.Category
+		confidence = 1
+	} else if ruleMatch != nil && classify {
+		category = ruleMatch.Category
+		confidence = 1
+		tags = append(tags, ruleMatch.Tags...)
+		log.Printf("Category rule '%s' matched - Category: %s, Tags: %v", ruleMatch.RuleName, category, ruleMatch.Tags)
+	}
+
+	// Use combined analysis for whatever is still missing (single API call for title + category + optional summary)
+	// Only get summary from analyzeNote if no custom prompt exists
+	useDefaultSummary := customPromptText == "" && customPromptSchema == ""
+	includeSummary := hasTranscript && useDefaultSummary && summarize && !s.cfg.PrivacyMode
+	if (classify && (title == "" || category == "")) || includeSummary {
+		var analysis *models.NoteAnalysis
+		var err error
+		if batchAnalysis != nil && !includeSummary {
+			analysis = batchAnalysis
 		} else {
-			title = analysis.Title
-			category = analysis.Category
-			if useDefaultSummary {
-				summary = analysis.Summary
+			var examples []models.ClassificationExample
+			if category == "" {
+				examples = s.reviewService.FewShotExamples(ctx)
 			}
-			log.Printf("Note analyzed - Title: %s, Category: %s, Summary length: %d", title, category, len(summary))
+			analysis, err = s.aiClient.AnalyzeNote(req.Content, includeSummary, examples)
 		}
-	} else {
-		title = req.Title
-		// If title is provided, we still need category - do a quick analysis
-		useDefaultSummary := customPromptText == "" && customPromptSchema == ""
-		analysis, err := s.aiClient.AnalyzeNote(req.Content, isYouTube && useDefaultSummary)
+		aiCalls = append(aiCalls, AICall{Operation: models.AIOperationAnalysis, Content: req.Content, Output: analysis, Err: err})
 		if err != nil {
-			log.Printf("Failed to analyze note for category: %v", err)
-			category = "other"
+			log.Printf("Failed to analyze note: %v", err)
+			if title == "" {
+				title = "Untitled Note"
+			}
+			if category == "" {
+				category = "other"
+			}
 		} else {
-			category = analysis.Category
-			if useDefaultSummary {
+			generation := newGeneration(s.cfg, models.AIOperationAnalysis)
+			if classify {
+				if title == "" {
+					title = analysis.Title
+					titleGeneration = generation
+				}
+				if category == "" {
+					category = analysis.Category
+					confidence = analysis.Confidence
+					categoryGeneration = generation
+				}
+				tags = append(tags, analysis.Tags...)
+			}
+			if useDefaultSummary && analysis.Summary != "" {
 				summary = analysis.Summary
+				summaryGeneration = generation
 			}
+			log.Printf("Note analyzed - Title: %s, Category: %s (confidence %.2f), Tags: %v, Summary length: %d", title, category, confidence, analysis.Tags, len(summary))
+		}
+	}
+
+	// Notes the pipeline doesn't classify keep the request's title and category
+	needsReview := s.reviewService.NeedsReview(confidence)
+	if title == "" {
+		title = "Untitled Note"
+	}
+	if category == "" {
+		category = "other"
+		needsReview = false
+	}
+
+	// A note classified just now may fall under a category pipeline that doesn't summarize it
+	if req.Category == "" {
+		steps = pipelineSteps(pipelines, channelKey, category)
+		if !slices.Contains(steps, models.PipelineSummarize) {
+			summarize = false
+			summary = ""
+			summaryGeneration = nil
 		}
 	}
 
 	// If custom prompt exists, generate structured summary with it
-	if customPromptText != "" || customPromptSchema != "" {
+	if summarize && (customPromptText != "" || customPromptSchema != "") {
 		log.Printf("Generating summary with custom prompt for new note")
 		customSummary, customStructuredData, err := s.aiClient.GenerateStructuredSummary(req.Content, customPromptText, customPromptSchema)
+		aiCalls = append(aiCalls, AICall{Operation: models.AIOperationSummary, Content: req.Content, PromptText: customPromptText, PromptSchema: customPromptSchema, Output: customSummary, Err: err})
 		if err != nil {
 			log.Printf("Failed to generate custom summary: %v", err)
 			// Fall back to default summary if custom fails
 		} else {
 			summary = customSummary
-			structuredData = customStructuredData
+			structuredData, structuredDataTruncated = limitStructuredData(customStructuredData, author)
+			summaryGeneration = newGeneration(s.cfg, models.AIOperationSummary)
 			log.Printf("Custom summary generated, length: %d, has structured data: %v", len(summary), structuredData != nil)
 		}
 	}
 
+	// Notes that expect a summary always get one, even when the LLM failed or privacy mode is on
+	hasCustomPrompt := customPromptText != "" || customPromptSchema != ""
+	if summary == "" && summarize && (hasTranscript || hasCustomPrompt) {
+		log.Printf("Using extractive summary for new note")
+		summary = utils.ExtractiveSummary(req.Content, config.FALLBACK_SUMMARY_SENTENCES)
+	}
+
 	// Parse SourcePublishedAt from metadata.timestamp if available
 	var sourcePublishedAt *time.Time
 	if ts, ok := metadata["timestamp"].(string); ok && ts != "" {
@@ -160,19 +495,28 @@ func (s *NotesService) CreateNote(ctx context.Context, req *models.CreateNoteReq
 	}
 
 	note := models.Note{
-		Title:             title,
-		Content:           req.Content,
-		Category:          category,
-		Summary:           summary,
-		StructuredData:    structuredData,
-		Created:           time.Now(),
-		SourcePublishedAt: sourcePublishedAt,
-		LastSummarizedAt:  lastSummarizedAt,
-		Metadata:          metadata,
+		Title:              title,
+		Content:            req.Content,
+		Category:           category,
+		CategoryConfidence: confidence,
+		NeedsReview:        needsReview,
+		Tags:               normalizeTags(tags),
+		Summary:            summary,
+		StructuredData:     structuredData,
+		Created:            time.Now(),
+		SourcePublishedAt:  sourcePublishedAt,
+		LastSummarizedAt:   lastSummarizedAt,
+		Metadata:           metadata,
+		TitleGeneration:    titleGeneration,
+		CategoryGeneration: categoryGeneration,
+		SummaryGeneration:  summaryGeneration,
+
+		StructuredDataTruncated: structuredDataTruncated,
 	}
 
 	// Check for duplicate URL before inserting
-	if urlVal, ok := metadata["url"].(string); ok && urlVal != "" {
+	// An inferred URL is only mentioned by the content, so it doesn't make the note a duplicate
+	if urlVal, ok := metadata["url"].(string); ok && urlVal != "" && !isInferred(metadata, "url") {
 		exists, err := s.notesRepo.ExistsByURL(ctx, urlVal)
 		if err != nil {
 			log.Printf("Error checking for duplicate URL: %v", err)
@@ -191,14 +535,13 @@ func (s *NotesService) CreateNote(ctx context.Context, req *models.CreateNoteReq
 	}
 
 	note.ID = noteID
+	s.aiHistory.Record(ctx, note.ID, aiCalls...)
 
-	// Queue job for embedding generation only (title, category, summary already done)
-	s.workerPool.Submit(models.ProcessingJob{
-		NoteID:   note.ID,
-		Title:    note.Title,
-		Content:  note.Content,
-		Metadata: note.Metadata,
-	})
+	s.webhookService.Emit(ctx, models.NoteEventCreated, note.ID, noteCreatedDelta(&note))
+
+	// Queue the rest of the pipeline (title, category, summary already done), along with the
+	// automation rules the note's tags, category and channel trigger
+	s.queuePipeline(ctx, &note, steps, s.automationService.Plan(ctx, &note))
 
 	return &CreateNoteResult{
 		Note:      &note,
@@ -214,7 +557,7 @@ func (s *NotesService) UpdateNote(ctx context.Context, noteID string, req *model
 	}
 
 	// Find the existing note first
-	_, err = s.notesRepo.FindByID(ctx, objID)
+	existing, err := s.notesRepo.FindByID(ctx, objID)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, fmt.Errorf("note not found")
@@ -222,13 +565,24 @@ func (s *NotesService) UpdateNote(ctx context.Context, noteID string, req *model
 		return nil, fmt.Errorf("failed to find note: %w", err)
 	}
 
-	// Generate new title from content
-	newTitle, err := s.aiClient.GenerateTitle(req.Content)
-	if err != nil {
-		log.Printf("Failed to generate title for updated note: %v", err)
-		newTitle = "Updated Note" // fallback
+	// Generate new title from content unless one was given
+	newTitle := req.Title
+	var titleGeneration *models.GenerationInfo
+	if newTitle == "" {
+		newTitle, err = s.aiClient.GenerateTitle(req.Content)
+		s.aiHistory.Record(ctx, objID, AICall{Operation: models.AIOperationTitle, Content: req.Content, Output: newTitle, Err: err})
+		if err != nil {
+			log.Printf("Failed to generate title for updated note: %v", err)
+			newTitle = "Updated Note" // fallback
+		} else {
+			titleGeneration = newGeneration(s.cfg, models.AIOperationTitle)
+		}
 	}
 
+	// A small edit, e.g. a transcript correction, patches the AI summary; larger edits leave it to be
+	// regenerated with POST /notes/:id/summarize
+	summary, summaryGeneration := s.patchSummary(ctx, existing, req.Content)
+
 	// Update the note
 	update := bson.M{
 		"$set": bson.M{
@@ -236,6 +590,16 @@ func (s *NotesService) UpdateNote(ctx context.Context, noteID string, req *model
 			"content": req.Content,
 		},
 	}
+	if titleGeneration != nil {
+		update["$set"].(bson.M)["title_generation"] = titleGeneration
+	} else {
+		update["$unset"] = bson.M{"title_generation": ""}
+	}
+	if summaryGeneration != nil {
+		update["$set"].(bson.M)["summary"] = summary
+		update["$set"].(bson.M)["last_summarized_at"] = summaryGeneration.GeneratedAt
+		update["$set"].(bson.M)["summary_generation"] = summaryGeneration
+	}
 
 	err = s.notesRepo.Update(ctx, objID, update)
 	if err != nil {
@@ -248,17 +612,88 @@ func (s *NotesService) UpdateNote(ctx context.Context, noteID string, req *model
 		return nil, fmt.Errorf("failed to retrieve updated note: %w", err)
 	}
 
-	// Queue re-processing job for embeddings
-	s.workerPool.Submit(models.ProcessingJob{
-		NoteID:   updatedNote.ID,
-		Title:    updatedNote.Title,
-		Content:  updatedNote.Content,
-		Metadata: updatedNote.Metadata,
-	})
+	delta := map[string]interface{}{}
+	if updatedNote.Title != existing.Title {
+		delta["title"] = updatedNote.Title
+	}
+	if updatedNote.Content != existing.Content {
+		delta["content"] = updatedNote.Content
+	}
+	if updatedNote.Summary != existing.Summary {
+		delta["summary"] = updatedNote.Summary
+	}
+	if len(delta) > 0 {
+		s.webhookService.Emit(ctx, models.NoteEventUpdated, updatedNote.ID, delta)
+	}
+
+	// Drop the old content's chunks and embeddings so search can't return it while the job is queued
+	s.deleteEmbeddings(ctx, updatedNote.ID)
+
+	// Re-run the worker steps of the note's pipeline on the new content
+	author, _ := updatedNote.Metadata["author"].(string)
+	steps := pipelineSteps(s.pipelineService.pipelinesOrDefault(ctx), utils.NormalizeChannelKey(author), updatedNote.Category)
+	s.queuePipeline(ctx, updatedNote, steps, nil)
 
 	return updatedNote, nil
 }
 
+// patchSummary updates a note's AI summary for an edit touching at most SUMMARY_PATCH_MAX_CHANGE of
+// its words by sending the old summary and the diff, which is cheaper than regenerating the summary and
+// keeps it stable. Returns the patched summary and its provenance, or nil provenance when the summary
+// is left as it was: it was extractive or set by hand, the edit is too large, or patching failed.
+// Structured data is kept as it was.
+func (s *NotesService) patchSummary(ctx context.Context, note *models.Note, content string) (string, *models.GenerationInfo) {
+	if note.Summary == "" || note.SummaryGeneration == nil || content == note.Content || s.cfg.PrivacyMode {
+		return "", nil
+	}
+	diff := utils.DiffSentences(note.Content, content)
+	if diff == nil || len(diff.Hunks) == 0 || diff.ChangeRatio() > config.SUMMARY_PATCH_MAX_CHANGE {
+		return "", nil
+	}
+
+	edit := diff.String()
+	summary, err := s.aiClient.PatchSummary(note.Summary, edit)
+	s.aiHistory.Record(ctx, note.ID, AICall{Operation: models.AIOperationSummaryPatch, Content: note.Summary + "\n\n" + edit, Output: summary, Err: err})
+	if err != nil {
+		log.Printf("Failed to patch summary of note %s, keeping it: %v", note.ID.Hex(), err)
+		return "", nil
+	}
+	log.Printf("Patched summary of note %s for an edit touching %.0f%% of its words", note.ID.Hex(), diff.ChangeRatio()*100)
+	return summary, newGeneration(s.cfg, models.AIOperationSummaryPatch)
+}
+
+// queuePipeline queues a job running the worker steps of a note's pipeline and the automation
+// plan, if any, with the steps the plan adds
+// A note with no worker steps or plan is marked skipped instead, as nothing will embed it.
+func (s *NotesService) queuePipeline(ctx context.Context, note *models.Note, steps []string, automation *models.AutomationPlan) {
+	jobSteps := workerSteps(steps)
+	if automation != nil {
+		jobSteps = withSteps(jobSteps, automation.Steps)
+	}
+	if len(jobSteps) == 0 && automation == nil {
+		if err := s.notesRepo.Update(ctx, note.ID, bson.M{"$set": bson.M{"processing_status": models.ProcessingSkipped}}); err != nil {
+			log.Printf("Failed to update processing status of note %s: %v", note.ID.Hex(), err)
+		}
+		note.ProcessingStatus = models.ProcessingSkipped
+		return
+	}
+
+	if err := s.workerPool.Submit(ctx, models.ProcessingJob{
+		NoteID:         note.ID,
+		Title:          note.Title,
+		Content:        note.Content,
+		Category:       note.Category,
+		NoteCreated:    note.Created,
+		Metadata:       note.Metadata,
+		Summary:        note.Summary,
+		StructuredData: note.StructuredData,
+		Steps:          jobSteps,
+		Automation:     automation,
+	}); err != nil {
+		log.Printf("Failed to queue processing job: %v", err)
+	}
+}
+
 // DeleteNote removes a note and its associated chunks and embeddings
 func (s *NotesService) DeleteNote(ctx context.Context, noteID string) error {
 	objID, err := primitive.ObjectIDFromHex(noteID)
@@ -280,22 +715,122 @@ func (s *NotesService) DeleteNote(ctx context.Context, noteID string) error {
 	if err != nil {
 		return fmt.Errorf("failed to delete note: %w", err)
 	}
+	s.webhookService.Emit(ctx, models.NoteEventDeleted, objID, nil)
 
-	// Delete associated chunks from MongoDB
-	_, err = s.chunksRepo.DeleteByNoteID(ctx, objID)
-	if err != nil {
-		log.Printf("Failed to delete chunks for note %s: %v", noteID, err)
-		// Don't fail the request, just log the error
+	s.deleteEmbeddings(ctx, objID)
+
+	return nil
+}
+
+// deleteEmbeddings removes a note's chunks from MongoDB and its embeddings from Qdrant
+// Failures are logged rather than returned so they don't fail the request; an embedding job for
+// the note replaces anything left behind.
+func (s *NotesService) deleteEmbeddings(ctx context.Context, noteID primitive.ObjectID) {
+	if _, err := s.chunksRepo.DeleteByNoteID(ctx, noteID); err != nil {
+		log.Printf("Failed to delete chunks for note %s: %v", noteID.Hex(), err)
 	}
+	if _, err := s.qdrantClient.DeleteByNoteID(noteID); err != nil {
+		log.Printf("Failed to delete embeddings for note %s: %v", noteID.Hex(), err)
+	}
+}
 
-	// Delete embeddings from Qdrant
-	_, err = s.qdrantClient.DeleteByNoteID(objID)
-	if err != nil {
-		log.Printf("Failed to delete embeddings for note %s: %v", noteID, err)
-		// Don't fail the request, just log the error
+// BulkDelete deletes many notes with their chunks and embeddings in batches, reporting the outcome per ID
+// Invalid and unknown IDs are reported rather than failing the request. As with DeleteNote, chunk and
+// embedding cleanup failures are logged without failing the notes already deleted.
+func (s *NotesService) BulkDelete(ctx context.Context, req *models.BulkDeleteRequest) (*models.BulkDeleteResponse, error) {
+	if len(req.IDs) == 0 {
+		return nil, fmt.Errorf("invalid bulk delete: at least one note ID is required")
+	}
+	if len(req.IDs) > config.MAX_BULK_DELETE_IDS {
+		return nil, fmt.Errorf("invalid bulk delete: at most %d note IDs are allowed", config.MAX_BULK_DELETE_IDS)
 	}
 
-	return nil
+	results := make([]models.BulkDeleteResult, len(req.IDs))
+	indexesByID := make(map[primitive.ObjectID][]int)
+	var objIDs []primitive.ObjectID
+	for i, id := range req.IDs {
+		results[i] = models.BulkDeleteResult{ID: id, Status: models.BulkDeleteStatusNotFound}
+		objID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			results[i].Status = models.BulkDeleteStatusInvalid
+			continue
+		}
+		if _, seen := indexesByID[objID]; !seen {
+			objIDs = append(objIDs, objID)
+		}
+		indexesByID[objID] = append(indexesByID[objID], i)
+	}
+
+	setStatus := func(ids []primitive.ObjectID, status, errMsg string) {
+		for _, id := range ids {
+			for _, i := range indexesByID[id] {
+				results[i].Status = status
+				results[i].Error = errMsg
+			}
+		}
+	}
+
+	var deletedIDs []primitive.ObjectID
+	for start := 0; start < len(objIDs); start += config.BULK_DELETE_BATCH_SIZE {
+		end := start + config.BULK_DELETE_BATCH_SIZE
+		if end > len(objIDs) {
+			end = len(objIDs)
+		}
+		batch := objIDs[start:end]
+
+		existing, err := s.notesRepo.FindAll(ctx, bson.M{"_id": bson.M{"$in": batch}}, options.Find().SetProjection(bson.M{"_id": 1}))
+		if err != nil {
+			setStatus(batch, models.BulkDeleteStatusFailed, "failed to find notes")
+			log.Printf("Bulk delete failed to find notes: %v", err)
+			continue
+		}
+		if len(existing) == 0 {
+			continue
+		}
+		found := make([]primitive.ObjectID, len(existing))
+		for i, note := range existing {
+			found[i] = note.ID
+		}
+
+		if _, err := s.notesRepo.DeleteByIDs(ctx, found); err != nil {
+			setStatus(found, models.BulkDeleteStatusFailed, "failed to delete note")
+			log.Printf("Bulk delete failed to delete notes: %v", err)
+			continue
+		}
+		setStatus(found, models.BulkDeleteStatusDeleted, "")
+		deletedIDs = append(deletedIDs, found...)
+		for _, id := range found {
+			s.webhookService.Emit(ctx, models.NoteEventDeleted, id, nil)
+		}
+
+		if _, err := s.chunksRepo.DeleteByNoteIDs(ctx, found); err != nil {
+			log.Printf("Failed to delete chunks for %d notes: %v", len(found), err)
+		}
+		if err := s.qdrantClient.DeleteByNoteIDs(found); err != nil {
+			log.Printf("Failed to delete embeddings for %d notes: %v", len(found), err)
+		}
+	}
+
+	if len(deletedIDs) > 0 {
+		_, err := s.auditRepo.Create(ctx, &models.AuditEntry{
+			Action:    "bulk_delete",
+			NoteIDs:   deletedIDs,
+			Changes:   map[string]interface{}{},
+			Matched:   int64(len(deletedIDs)),
+			Modified:  int64(len(deletedIDs)),
+			CreatedAt: time.Now(),
+		})
+		if err != nil {
+			log.Printf("Failed to write audit entry for bulk delete: %v", err)
+		}
+	}
+
+	log.Printf("Bulk delete removed %d of %d notes", len(deletedIDs), len(req.IDs))
+
+	return &models.BulkDeleteResponse{
+		Deleted: len(deletedIDs),
+		Results: results,
+	}, nil
 }
 
 // GetNoteByID retrieves a single note by ID
@@ -315,3 +850,195 @@ func (s *NotesService) GetNoteByID(ctx context.Context, noteID string) (*models.
 
 	return note, nil
 }
+
+// SetArchived archives or unarchives a note and returns the updated note
+func (s *NotesService) SetArchived(ctx context.Context, noteID string, archived bool) (*models.Note, error) {
+	return s.setFlag(ctx, noteID, "archived", archived)
+}
+
+// SetPinned pins or unpins a note and returns the updated note
+func (s *NotesService) SetPinned(ctx context.Context, noteID string, pinned bool) (*models.Note, error) {
+	return s.setFlag(ctx, noteID, "pinned", pinned)
+}
+
+// SetAlwaysInContext marks or unmarks a note as context for every Q&A answer and returns the updated note
+func (s *NotesService) SetAlwaysInContext(ctx context.Context, noteID string, always bool) (*models.Note, error) {
+	return s.setFlag(ctx, noteID, "always_in_context", always)
+}
+
+// setFlag sets or clears a boolean note field; cleared flags are removed to match omitempty
+func (s *NotesService) setFlag(ctx context.Context, noteID, field string, value bool) (*models.Note, error) {
+	objID, err := primitive.ObjectIDFromHex(noteID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid note ID: %w", err)
+	}
+
+	update := bson.M{"$set": bson.M{field: true}}
+	if !value {
+		update = bson.M{"$unset": bson.M{field: ""}}
+	}
+
+	result, err := s.notesRepo.UpdateMany(ctx, bson.M{"_id": objID}, update)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update note: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return nil, fmt.Errorf("note not found")
+	}
+	if result.ModifiedCount > 0 {
+		s.webhookService.Emit(ctx, models.NoteEventUpdated, objID, map[string]interface{}{field: value})
+	}
+
+	return s.GetNoteByID(ctx, noteID)
+}
+
+// RecordView counts an opening of a note and returns the updated note
+// Views don't emit note events, as they don't change the note's content
+func (s *NotesService) RecordView(ctx context.Context, noteID string) (*models.Note, error) {
+	objID, err := primitive.ObjectIDFromHex(noteID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid note ID: %w", err)
+	}
+
+	result, err := s.notesRepo.UpdateMany(ctx, bson.M{"_id": objID}, bson.M{
+		"$inc": bson.M{"view_count": 1},
+		"$set": bson.M{"last_viewed_at": time.Now()},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to record view: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return nil, fmt.Errorf("note not found")
+	}
+
+	return s.GetNoteByID(ctx, noteID)
+}
+
+// BulkUpdate applies category, tag and pin changes to many notes in a single UpdateMany
+// and records the change in the audit log
+func (s *NotesService) BulkUpdate(ctx context.Context, req *models.BulkUpdateRequest) (*models.BulkUpdateResponse, error) {
+	if len(req.IDs) == 0 {
+		return nil, fmt.Errorf("invalid bulk update: at least one note ID is required")
+	}
+	if len(req.IDs) > config.MAX_BULK_UPDATE_IDS {
+		return nil, fmt.Errorf("invalid bulk update: at most %d note IDs are allowed", config.MAX_BULK_UPDATE_IDS)
+	}
+
+	objIDs := make([]primitive.ObjectID, 0, len(req.IDs))
+	for _, id := range req.IDs {
+		objID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bulk update: invalid note ID %q", id)
+		}
+		objIDs = append(objIDs, objID)
+	}
+
+	set := bson.M{}
+	unset := bson.M{}
+	if req.Category != nil {
+		category := strings.ToLower(strings.TrimSpace(*req.Category))
+		if !config.IsValidCategory(category) {
+			return nil, fmt.Errorf("invalid bulk update: unknown category %q", category)
+		}
+		// A manual reassignment is authoritative, so it also clears the review flag
+		set["category"] = category
+		set["category_confidence"] = 1.0
+		unset["needs_review"] = ""
+		unset["category_generation"] = ""
+	}
+	if req.Tags != nil {
+		tags := normalizeTags(*req.Tags)
+		if len(tags) > 0 {
+			set["tags"] = tags
+		} else {
+			unset["tags"] = ""
+		}
+	}
+	if req.Pinned != nil {
+		if *req.Pinned {
+			set["pinned"] = true
+		} else {
+			unset["pinned"] = ""
+		}
+	}
+
+	if len(set) == 0 && len(unset) == 0 {
+		return nil, fmt.Errorf("invalid bulk update: no changes specified")
+	}
+
+	update := bson.M{}
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	result, err := s.notesRepo.UpdateMany(ctx, bson.M{"_id": bson.M{"$in": objIDs}}, update)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update notes: %w", err)
+	}
+	if category, ok := set["category"].(string); ok && result.ModifiedCount > 0 {
+		// Category-scoped searches filter on the embeddings' payload, which isn't re-embedded here
+		if err := s.qdrantClient.SetNoteCategory(objIDs, category); err != nil {
+			log.Printf("Failed to update the category of bulk updated notes in Qdrant: %v", err)
+		}
+	}
+
+	changes := bson.M{}
+	for key, value := range set {
+		changes[key] = value
+	}
+	for key := range unset {
+		if _, ok := changes[key]; !ok {
+			changes[key] = nil
+		}
+	}
+
+	_, err = s.auditRepo.Create(ctx, &models.AuditEntry{
+		Action:    "bulk_update",
+		NoteIDs:   objIDs,
+		Changes:   changes,
+		Matched:   result.MatchedCount,
+		Modified:  result.ModifiedCount,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		log.Printf("Failed to write audit entry for bulk update: %v", err)
+	}
+
+	if result.ModifiedCount > 0 && s.webhookService != nil {
+		s.emitBulkUpdate(ctx, objIDs, req, set)
+	}
+
+	log.Printf("Bulk update matched %d notes, modified %d", result.MatchedCount, result.ModifiedCount)
+
+	return &models.BulkUpdateResponse{
+		Matched:  result.MatchedCount,
+		Modified: result.ModifiedCount,
+	}, nil
+}
+
+// emitBulkUpdate logs an update event for each existing note in a bulk update
+// Deltas use API field names; a nil value means the field was cleared
+func (s *NotesService) emitBulkUpdate(ctx context.Context, objIDs []primitive.ObjectID, req *models.BulkUpdateRequest, set bson.M) {
+	delta := map[string]interface{}{}
+	if req.Category != nil {
+		delta["category"] = set["category"]
+	}
+	if req.Tags != nil {
+		delta["tags"] = set["tags"]
+	}
+	if req.Pinned != nil {
+		delta["pinned"] = *req.Pinned
+	}
+
+	notes, err := s.notesRepo.FindAll(ctx, bson.M{"_id": bson.M{"$in": objIDs}}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		log.Printf("Failed to load bulk updated notes for events: %v", err)
+		return
+	}
+	for _, note := range notes {
+		s.webhookService.Emit(ctx, models.NoteEventUpdated, note.ID, delta)
+	}
+}
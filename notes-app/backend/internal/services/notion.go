@@ -0,0 +1,437 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+)
+
+// notionIDPattern splits the 32-hex-digit page ID Notion appends to exported file and folder names
+var notionIDPattern = regexp.MustCompile(`^(.*?)\s+([0-9a-f]{32})$`)
+
+// notionCreatedProperties are the property names, lowercased, that Notion and common templates use
+// for creation time
+var notionCreatedProperties = map[string]bool{
+	"created":      true,
+	"created time": true,
+	"created at":   true,
+	"date created": true,
+	"created on":   true,
+}
+
+// notionTimeLayouts are the date formats found in Notion exports, which carry no timezone
+var notionTimeLayouts = []string{
+	"January 2, 2006 3:04 PM",
+	"January 2, 2006 15:04",
+	"January 2, 2006",
+	time.RFC3339,
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02 15:04",
+	"2006/01/02",
+}
+
+// notionDatabase is a database read from an exported CSV
+type notionDatabase struct {
+	name    string
+	headers []string
+	rows    []map[string]string
+	used    []bool
+	all     bool // Read from the _all.csv variant, which includes rows hidden by the view
+}
+
+// notionPage is a page or database row about to become a note
+type notionPage struct {
+	path       string
+	id         string
+	title      string
+	body       string
+	database   string
+	properties map[string]string
+}
+
+// NotionImportService turns a Notion workspace export into notes
+type NotionImportService struct {
+	notesService *NotesService
+}
+
+// NewNotionImportService creates a new NotionImportService
+func NewNotionImportService(notesService *NotesService) *NotionImportService {
+	return &NotionImportService{
+		notesService: notesService,
+	}
+}
+
+// Import creates notes from a Notion "Markdown & CSV" export zip
+// Pages become notes; database rows also keep their properties as structured data, and a Created
+// property becomes SourcePublishedAt. Each note links back to its page, so importing the same
+// export again only reports duplicates.
+func (s *NotionImportService) Import(ctx context.Context, r io.ReaderAt, size int64) (*models.NotionImportResponse, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("invalid notion export: %w", err)
+	}
+	files, err := notionFiles(archive)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.NotionImportResponse{}
+	fail := func(name, reason string) {
+		response.Failed++
+		if len(response.Errors) < config.MAX_IMPORT_ERRORS {
+			response.Errors = append(response.Errors, fmt.Sprintf("%s: %s", name, reason))
+		}
+	}
+
+	// Databases are read first so their row pages can be matched up with properties
+	databases := make(map[string]*notionDatabase)
+	for _, file := range files {
+		if !strings.EqualFold(path.Ext(file.Name), ".csv") {
+			continue
+		}
+		data, err := readNotionFile(file)
+		if err != nil {
+			fail(file.Name, err.Error())
+			continue
+		}
+		key, all := notionDatabaseKey(file.Name)
+		if existing, ok := databases[key]; ok && existing.all && !all {
+			continue
+		}
+		db, err := parseNotionCSV(data)
+		if err != nil {
+			fail(file.Name, err.Error())
+			continue
+		}
+		db.name, _ = notionName(path.Base(key))
+		db.all = all
+		databases[key] = db
+	}
+
+	var pages []notionPage
+	for _, file := range files {
+		if !strings.EqualFold(path.Ext(file.Name), ".md") {
+			continue
+		}
+		data, err := readNotionFile(file)
+		if err != nil {
+			fail(file.Name, err.Error())
+			continue
+		}
+
+		name, id := notionName(strings.TrimSuffix(path.Base(file.Name), path.Ext(file.Name)))
+		page := notionPage{path: file.Name, id: id, title: name}
+		db := databases[path.Dir(file.Name)]
+		var headers []string
+		if db != nil {
+			headers = db.headers
+		}
+		title, body := parseNotionMarkdown(string(data), headers)
+		if title != "" {
+			page.title = title
+		}
+		page.body = body
+		if db != nil {
+			page.database = db.name
+			page.properties = db.takeRow(page.title)
+		}
+		pages = append(pages, page)
+	}
+
+	// Rows without an exported page still become notes, built from their properties
+	keys := make([]string, 0, len(databases))
+	for key := range databases {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		db := databases[key]
+		for i, row := range db.rows {
+			if db.used[i] {
+				continue
+			}
+			pages = append(pages, notionPage{
+				path:       key,
+				title:      row[db.headers[0]],
+				database:   db.name,
+				properties: row,
+			})
+		}
+	}
+
+	if len(pages) == 0 {
+		return nil, fmt.Errorf("invalid notion export: no markdown pages or CSV databases found")
+	}
+	if len(pages) > config.MAX_NOTION_PAGES {
+		over := len(pages) - config.MAX_NOTION_PAGES
+		response.Failed += over
+		response.Errors = append(response.Errors, fmt.Sprintf("%d pages over the limit of %d were not imported", over, config.MAX_NOTION_PAGES))
+		pages = pages[:config.MAX_NOTION_PAGES]
+	}
+
+	reqs := make([]*models.CreateNoteRequest, len(pages))
+	for i := range pages {
+		reqs[i] = notionNoteRequest(&pages[i])
+	}
+	results, errs := s.notesService.CreateNotes(ctx, reqs)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	for i, page := range pages {
+		if errs[i] != nil {
+			fail(page.path, errs[i].Error())
+			continue
+		}
+		if results[i].Duplicate {
+			response.Duplicates++
+			continue
+		}
+		response.Imported++
+	}
+
+	log.Printf("Notion import: %d imported, %d duplicates, %d failed", response.Imported, response.Duplicates, response.Failed)
+	return response, nil
+}
+
+// notionFiles lists the files in an export, sorted by path
+// Large workspaces are exported as a zip of zips, so one level of nested archives is opened
+func notionFiles(archive *zip.Reader) ([]*zip.File, error) {
+	var files []*zip.File
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		if !strings.EqualFold(path.Ext(file.Name), ".zip") {
+			files = append(files, file)
+			continue
+		}
+
+		if file.UncompressedSize64 > config.MAX_NOTION_IMPORT_BYTES {
+			return nil, fmt.Errorf("invalid notion export: %s is too large", file.Name)
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("invalid notion export: %w", err)
+		}
+		data, err := io.ReadAll(io.LimitReader(rc, config.MAX_NOTION_IMPORT_BYTES))
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("invalid notion export: %w", err)
+		}
+		nested, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid notion export: %s: %w", file.Name, err)
+		}
+		for _, inner := range nested.File {
+			if !inner.FileInfo().IsDir() {
+				files = append(files, inner)
+			}
+		}
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].Name < files[j].Name })
+	return files, nil
+}
+
+// readNotionFile reads one file from an export, refusing files over MAX_NOTION_FILE_BYTES
+func readNotionFile(file *zip.File) ([]byte, error) {
+	if file.UncompressedSize64 > config.MAX_NOTION_FILE_BYTES {
+		return nil, fmt.Errorf("file is larger than %d bytes", config.MAX_NOTION_FILE_BYTES)
+	}
+	rc, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, config.MAX_NOTION_FILE_BYTES+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > config.MAX_NOTION_FILE_BYTES {
+		return nil, fmt.Errorf("file is larger than %d bytes", config.MAX_NOTION_FILE_BYTES)
+	}
+	return bytes.TrimPrefix(data, []byte("\ufeff")), nil
+}
+
+// notionName splits an exported file or folder name into the page name and its ID, if present
+func notionName(name string) (string, string) {
+	if m := notionIDPattern.FindStringSubmatch(name); m != nil {
+		return m[1], m[2]
+	}
+	return name, ""
+}
+
+// notionDatabaseKey returns the path of the folder holding a database CSV's row pages, and whether
+// the CSV is the _all variant
+func notionDatabaseKey(name string) (string, bool) {
+	key := strings.TrimSuffix(name, path.Ext(name))
+	if trimmed := strings.TrimSuffix(key, "_all"); trimmed != key {
+		return trimmed, true
+	}
+	return key, false
+}
+
+// parseNotionCSV reads a database export; the first column is the row title
+func parseNotionCSV(data []byte) (*notionDatabase, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV: %w", err)
+	}
+	if len(records) == 0 || len(records[0]) == 0 {
+		return nil, fmt.Errorf("invalid CSV: no header row")
+	}
+
+	db := &notionDatabase{headers: records[0]}
+	for _, record := range records[1:] {
+		row := make(map[string]string, len(db.headers))
+		for i, header := range db.headers {
+			if i < len(record) && strings.TrimSpace(record[i]) != "" {
+				row[header] = strings.TrimSpace(record[i])
+			}
+		}
+		if len(row) > 0 {
+			db.rows = append(db.rows, row)
+		}
+	}
+	db.used = make([]bool, len(db.rows))
+	return db, nil
+}
+
+// takeRow returns the first unmatched row with the given title and marks it matched
+func (db *notionDatabase) takeRow(title string) map[string]string {
+	for i, row := range db.rows {
+		if !db.used[i] && row[db.headers[0]] == title {
+			db.used[i] = true
+			return row
+		}
+	}
+	return nil
+}
+
+// parseNotionMarkdown splits an exported page into its title heading and body
+// Row pages repeat their properties as "Name: value" lines under the title; those lines are
+// dropped when the name is one of the database's columns.
+func parseNotionMarkdown(text string, headers []string) (string, string) {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	i := 0
+	for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
+		i++
+	}
+
+	var title string
+	if i < len(lines) && strings.HasPrefix(lines[i], "# ") {
+		title = strings.TrimSpace(strings.TrimPrefix(lines[i], "# "))
+		i++
+	}
+
+	columns := make(map[string]bool, len(headers))
+	for _, header := range headers {
+		columns[header] = true
+	}
+	for i < len(lines) {
+		line := strings.TrimSpace(lines[i])
+		name, _, found := strings.Cut(line, ":")
+		if line != "" && !(found && columns[name]) {
+			break
+		}
+		i++
+	}
+
+	return title, strings.TrimSpace(strings.Join(lines[i:], "\n"))
+}
+
+// notionNoteRequest builds the note for a page
+func notionNoteRequest(page *notionPage) *models.CreateNoteRequest {
+	metadata := map[string]interface{}{
+		"source":      "notion",
+		"notion_path": page.path,
+	}
+	if page.id != "" {
+		metadata["notion_id"] = page.id
+		metadata["url"] = "https://www.notion.so/" + page.id
+	}
+	if page.database != "" {
+		metadata["notion_database"] = page.database
+	}
+
+	var tags []string
+	var structuredData map[string]interface{}
+	var lines []string
+	if len(page.properties) > 0 {
+		structuredData = make(map[string]interface{}, len(page.properties))
+		names := make([]string, 0, len(page.properties))
+		for name, value := range page.properties {
+			structuredData[name] = value
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			value := page.properties[name]
+			lower := strings.ToLower(name)
+			if lower == "tags" {
+				for _, tag := range strings.Split(value, ",") {
+					if tag = strings.TrimSpace(tag); tag != "" {
+						tags = append(tags, tag)
+					}
+				}
+			}
+			if _, set := metadata["timestamp"]; !set && notionCreatedProperties[lower] {
+				if created, ok := parseNotionTime(value); ok {
+					metadata["timestamp"] = created.Format(time.RFC3339)
+				}
+			}
+			if value != page.title {
+				lines = append(lines, name+": "+value)
+			}
+		}
+	}
+
+	// Pages without a body are described by their properties, or at least their title
+	content := page.body
+	if content == "" {
+		content = strings.Join(lines, "\n")
+	}
+	if content == "" {
+		content = page.title
+	}
+
+	return &models.CreateNoteRequest{
+		Title:          page.title,
+		Content:        content,
+		Tags:           tags,
+		Metadata:       metadata,
+		StructuredData: structuredData,
+	}
+}
+
+// parseNotionTime parses a Notion date as UTC; for a range only the start is used
+func parseNotionTime(value string) (time.Time, bool) {
+	value, _, _ = strings.Cut(value, " → ")
+	value = strings.TrimSpace(value)
+	for _, layout := range notionTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetOnThisDay returns unarchived notes created or published on the same date in earlier years, or
+// with the month scope on the same day of earlier months, grouped by year
+// date is YYYY-MM-DD, today when empty; days are calendar days in the timezone (IANA, UTC when empty).
+// A note published on the date is grouped by its publication year even if it was saved later.
+func (s *NotesService) GetOnThisDay(ctx context.Context, date, scope, timezone string) (*models.OnThisDay, error) {
+	loc, err := loadTimezone(timezone)
+	if err != nil {
+		return nil, err
+	}
+	if scope == "" {
+		scope = models.OnThisDayScopeYear
+	}
+	if scope != models.OnThisDayScopeYear && scope != models.OnThisDayScopeMonth {
+		return nil, fmt.Errorf("invalid scope: must be year or month")
+	}
+
+	now := time.Now().In(loc)
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	if date != "" {
+		if day, err = time.ParseInLocation(heatmapDateFormat, date, loc); err != nil {
+			return nil, fmt.Errorf("invalid date: must be YYYY-MM-DD")
+		}
+	}
+
+	// Dates are compared in Mongo so only matching notes are loaded; a missing
+	// source_published_at makes $dayOfMonth null, which never matches
+	matchDay := func(field string) bson.M {
+		conditions := bson.A{
+			bson.M{"$lt": bson.A{field, day}},
+			bson.M{"$eq": bson.A{bson.M{"$dayOfMonth": bson.M{"date": field, "timezone": loc.String()}}, day.Day()}},
+		}
+		if scope == models.OnThisDayScopeYear {
+			conditions = append(conditions, bson.M{"$eq": bson.A{bson.M{"$month": bson.M{"date": field, "timezone": loc.String()}}, int(day.Month())}})
+		}
+		return bson.M{"$and": conditions}
+	}
+	filter := notesFilter(models.SearchFilters{})
+	filter["$expr"] = bson.M{"$or": bson.A{matchDay("$created"), matchDay("$source_published_at")}}
+	opts := options.Find().SetSort(bson.M{"created": -1}).SetLimit(config.MAX_ON_THIS_DAY_NOTES)
+
+	notes, err := s.notesRepo.FindAll(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find notes: %w", err)
+	}
+
+	matches := func(t time.Time) bool {
+		t = t.In(loc)
+		return t.Before(day) && t.Day() == day.Day() && (scope == models.OnThisDayScopeMonth || t.Month() == day.Month())
+	}
+	dates := make(map[int]time.Time, len(notes))
+	byYear := make(map[int][]int)
+	for i, note := range notes {
+		dates[i] = note.Created.In(loc)
+		if note.SourcePublishedAt != nil && matches(*note.SourcePublishedAt) {
+			dates[i] = note.SourcePublishedAt.In(loc)
+		}
+		byYear[dates[i].Year()] = append(byYear[dates[i].Year()], i)
+	}
+
+	result := &models.OnThisDay{
+		Date:     day.Format(heatmapDateFormat),
+		Timezone: loc.String(),
+		Scope:    scope,
+		Years:    []models.OnThisDayYear{},
+	}
+	for year, indexes := range byYear {
+		sort.SliceStable(indexes, func(a, b int) bool { return dates[indexes[a]].After(dates[indexes[b]]) })
+		group := models.OnThisDayYear{Year: year, YearsAgo: day.Year() - year, Notes: make([]models.Note, 0, len(indexes))}
+		for _, i := range indexes {
+			group.Notes = append(group.Notes, notes[i])
+		}
+		result.Years = append(result.Years, group)
+	}
+	sort.Slice(result.Years, func(a, b int) bool { return result.Years[a].Year > result.Years[b].Year })
+
+	return result, nil
+}
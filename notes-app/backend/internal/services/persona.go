@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"backend/internal/ai"
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repository"
+)
+
+// PersonaService manages the assistant persona that shapes Q&A and conversation answers
+type PersonaService struct {
+	settingsRepo *repository.SettingsRepository
+}
+
+// NewPersonaService creates a new PersonaService
+func NewPersonaService(settingsRepo *repository.SettingsRepository) *PersonaService {
+	return &PersonaService{
+		settingsRepo: settingsRepo,
+	}
+}
+
+// GetPersona returns the configured persona, or the default one (Custom false) when none is set
+func (s *PersonaService) GetPersona(ctx context.Context) (*models.AssistantPersona, error) {
+	persona, err := s.settingsRepo.GetPersona(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get persona: %w", err)
+	}
+	if persona == nil {
+		return &models.AssistantPersona{Instructions: ai.DefaultAnswerInstructions}, nil
+	}
+	return persona, nil
+}
+
+// UpdatePersona validates and saves a new persona; it applies to the next answer
+func (s *PersonaService) UpdatePersona(ctx context.Context, req *models.UpdatePersonaRequest) (*models.AssistantPersona, error) {
+	instructions := strings.TrimSpace(req.Instructions)
+	if instructions == "" {
+		return nil, fmt.Errorf("invalid persona: instructions are required")
+	}
+	if len(req.Rules) > config.MAX_PERSONA_RULES {
+		return nil, fmt.Errorf("invalid persona: at most %d rules are allowed", config.MAX_PERSONA_RULES)
+	}
+
+	size := utf8.RuneCountInString(instructions)
+	var rules []string
+	for _, rule := range req.Rules {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			return nil, fmt.Errorf("invalid persona: rules can't be empty")
+		}
+		size += utf8.RuneCountInString(rule)
+		rules = append(rules, rule)
+	}
+	if size > config.MAX_PERSONA_CHARS {
+		return nil, fmt.Errorf("invalid persona: instructions and rules exceed %d characters", config.MAX_PERSONA_CHARS)
+	}
+
+	persona := &models.AssistantPersona{
+		Instructions: instructions,
+		Rules:        rules,
+		Custom:       true,
+		UpdatedAt:    time.Now(),
+	}
+	if err := s.settingsRepo.SavePersona(ctx, persona); err != nil {
+		return nil, fmt.Errorf("failed to save persona: %w", err)
+	}
+	return persona, nil
+}
+
+// ResetPersona removes the configured persona and returns the default one
+func (s *PersonaService) ResetPersona(ctx context.Context) (*models.AssistantPersona, error) {
+	if err := s.settingsRepo.DeletePersona(ctx); err != nil {
+		return nil, fmt.Errorf("failed to reset persona: %w", err)
+	}
+	return &models.AssistantPersona{Instructions: ai.DefaultAnswerInstructions}, nil
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/utils"
+)
+
+// PipelineService manages which processing steps run for new notes, per category and channel
+type PipelineService struct {
+	settingsRepo *repository.SettingsRepository
+}
+
+// NewPipelineService creates a new PipelineService
+func NewPipelineService(settingsRepo *repository.SettingsRepository) *PipelineService {
+	return &PipelineService{
+		settingsRepo: settingsRepo,
+	}
+}
+
+// GetPipelines returns the configured pipelines, or the default ones (Custom false) when none are set
+func (s *PipelineService) GetPipelines(ctx context.Context) (*models.PipelineSettings, error) {
+	pipelines, err := s.settingsRepo.GetPipelines(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pipelines: %w", err)
+	}
+	if pipelines == nil {
+		return defaultPipelines(), nil
+	}
+	return pipelines, nil
+}
+
+// UpdatePipelines validates and saves new pipeline settings; they apply to notes created afterwards
+func (s *PipelineService) UpdatePipelines(ctx context.Context, req *models.UpdatePipelinesRequest) (*models.PipelineSettings, error) {
+	if req.Default == nil {
+		return nil, fmt.Errorf("invalid pipeline: a default pipeline is required")
+	}
+	defaultSteps, err := validPipeline(req.Default)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pipeline for default: %w", err)
+	}
+
+	pipelines := &models.PipelineSettings{
+		Default:   defaultSteps,
+		Custom:    true,
+		UpdatedAt: time.Now(),
+	}
+	for category, steps := range req.Categories {
+		if !config.IsValidCategory(category) {
+			return nil, fmt.Errorf("invalid category: %q", category)
+		}
+		if steps, err = validPipeline(steps); err != nil {
+			return nil, fmt.Errorf("invalid pipeline for category %q: %w", category, err)
+		}
+		if pipelines.Categories == nil {
+			pipelines.Categories = make(map[string][]string)
+		}
+		pipelines.Categories[category] = steps
+	}
+	for channel, steps := range req.Channels {
+		key := utils.NormalizeChannelKey(channel)
+		if key == "" {
+			return nil, fmt.Errorf("invalid pipeline: channel %q has no letters or digits", channel)
+		}
+		if steps, err = validPipeline(steps); err != nil {
+			return nil, fmt.Errorf("invalid pipeline for channel %q: %w", channel, err)
+		}
+		if pipelines.Channels == nil {
+			pipelines.Channels = make(map[string][]string)
+		}
+		pipelines.Channels[key] = steps
+	}
+
+	if err := s.settingsRepo.SavePipelines(ctx, pipelines); err != nil {
+		return nil, fmt.Errorf("failed to save pipelines: %w", err)
+	}
+	return pipelines, nil
+}
+
+// ResetPipelines removes the configured pipelines and returns the default ones
+func (s *PipelineService) ResetPipelines(ctx context.Context) (*models.PipelineSettings, error) {
+	if err := s.settingsRepo.DeletePipelines(ctx); err != nil {
+		return nil, fmt.Errorf("failed to reset pipelines: %w", err)
+	}
+	return defaultPipelines(), nil
+}
+
+// pipelinesOrDefault returns the configured pipelines, logging a failure to load them and
+// falling back to the default ones so notes are still processed
+func (s *PipelineService) pipelinesOrDefault(ctx context.Context) *models.PipelineSettings {
+	pipelines, err := s.GetPipelines(ctx)
+	if err != nil {
+		log.Printf("Failed to load processing pipelines, using the default: %v", err)
+		return defaultPipelines()
+	}
+	return pipelines
+}
+
+// pipelineSteps returns the steps for a note from the given channel and category
+// Either may be empty when not known (yet), in which case only the other is looked up.
+func pipelineSteps(pipelines *models.PipelineSettings, channelKey, category string) []string {
+	if steps, ok := pipelines.Channels[channelKey]; ok && channelKey != "" {
+		return steps
+	}
+	if steps, ok := pipelines.Categories[category]; ok && category != "" {
+		return steps
+	}
+	return pipelines.Default
+}
+
+// workerSteps returns the steps of a pipeline that run in the worker, in order
+func workerSteps(steps []string) []string {
+	var worker []string
+	for _, step := range steps {
+		if step == models.PipelineExtractEntities || step == models.PipelineEmbed {
+			worker = append(worker, step)
+		}
+	}
+	return worker
+}
+
+// withSteps returns the steps followed by the extra ones not already among them
+func withSteps(steps, extra []string) []string {
+	merged := slices.Clone(steps)
+	for _, step := range extra {
+		if !slices.Contains(merged, step) {
+			merged = append(merged, step)
+		}
+	}
+	return merged
+}
+
+// validPipeline checks a pipeline's steps are known and listed once, returning them trimmed
+// and lowercased; an empty pipeline only stores the note
+func validPipeline(steps []string) ([]string, error) {
+	valid := []string{}
+	for _, step := range steps {
+		step = strings.ToLower(strings.TrimSpace(step))
+		if !slices.Contains(models.PipelineSteps, step) {
+			return nil, fmt.Errorf("unknown step %q, expected one of %s", step, strings.Join(models.PipelineSteps, ", "))
+		}
+		if slices.Contains(valid, step) {
+			return nil, fmt.Errorf("step %q is listed twice", step)
+		}
+		valid = append(valid, step)
+	}
+	return valid, nil
+}
+
+// defaultPipelines returns the settings used when no pipelines are configured
+func defaultPipelines() *models.PipelineSettings {
+	return &models.PipelineSettings{Default: slices.Clone(models.DefaultPipeline)}
+}
@@ -0,0 +1,350 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/podcast"
+	"backend/internal/repository"
+	"backend/internal/transcribe"
+	"backend/internal/utils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// PodcastService follows podcast RSS feeds and saves new episodes as podcast-transcripts notes
+// An episode's published transcript is used when it has one; otherwise its audio is sent to the
+// transcription API when one is configured. Notes are created the same way the browser extension
+// saves an episode, with the feed title as author, so the feed's channel custom prompt applies.
+type PodcastService struct {
+	feedsRepo    *repository.PodcastFeedsRepository
+	notesRepo    *repository.NotesRepository
+	notesService *NotesService
+	podcast      *podcast.Client
+	transcriber  *transcribe.Client // nil when no transcription API is configured
+	cfg          *config.Config
+
+	mu   sync.Mutex // Serializes syncs so a manual sync can't overlap the scheduled one
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewPodcastService creates a new PodcastService
+func NewPodcastService(
+	feedsRepo *repository.PodcastFeedsRepository,
+	notesRepo *repository.NotesRepository,
+	notesService *NotesService,
+	podcastClient *podcast.Client,
+	transcriber *transcribe.Client,
+	cfg *config.Config,
+) *PodcastService {
+	return &PodcastService{
+		feedsRepo:    feedsRepo,
+		notesRepo:    notesRepo,
+		notesService: notesService,
+		podcast:      podcastClient,
+		transcriber:  transcriber,
+		cfg:          cfg,
+	}
+}
+
+// Start syncs on the configured interval until Stop is called
+// A zero or negative interval leaves the schedule disabled
+func (s *PodcastService) Start() {
+	if s.cfg.PodcastSyncIntervalHours <= 0 {
+		log.Println("Podcast feed sync schedule disabled")
+		return
+	}
+
+	interval := time.Duration(s.cfg.PodcastSyncIntervalHours * float64(time.Hour))
+	s.stop = make(chan struct{})
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := s.Sync(context.Background(), ""); err != nil {
+					log.Printf("Scheduled podcast feed sync failed: %v", err)
+				}
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+	log.Printf("Podcast feed sync scheduled every %s", interval)
+}
+
+// Stop ends the schedule and waits for a sync in progress to finish
+func (s *PodcastService) Stop() {
+	if s.stop == nil {
+		return
+	}
+	close(s.stop)
+	s.wg.Wait()
+}
+
+// AddFeed follows a podcast feed
+// The feed is downloaded once up front so a wrong URL is reported right away
+func (s *PodcastService) AddFeed(ctx context.Context, req *models.AddPodcastRequest) (*models.PodcastFeed, error) {
+	feedURL := strings.TrimSpace(req.URL)
+	parsed, err := url.Parse(feedURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid feed URL: %q", req.URL)
+	}
+
+	feed, err := s.podcast.FetchFeed(ctx, feedURL)
+	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid feed") {
+			return nil, err
+		}
+		if errors.Is(err, utils.ErrNonPublicAddress) {
+			return nil, fmt.Errorf("invalid feed URL: %q points to a non-public address", req.URL)
+		}
+		return nil, fmt.Errorf("invalid feed: %w", err)
+	}
+
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		title = feed.Title
+	}
+	if title == "" {
+		return nil, fmt.Errorf("invalid feed: the feed has no title, set one in the request")
+	}
+
+	podcastFeed := &models.PodcastFeed{
+		URL:       feedURL,
+		Title:     title,
+		CreatedAt: time.Now(),
+	}
+	id, err := s.feedsRepo.Create(ctx, podcastFeed)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, fmt.Errorf("feed already exists")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to save feed: %w", err)
+	}
+	podcastFeed.ID = id
+	return podcastFeed, nil
+}
+
+// GetFeeds returns the followed feeds with their last sync status
+func (s *PodcastService) GetFeeds(ctx context.Context) ([]models.PodcastFeed, error) {
+	return s.feedsRepo.FindAll(ctx)
+}
+
+// DeleteFeed stops following a feed; notes already created from it are kept
+func (s *PodcastService) DeleteFeed(ctx context.Context, id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid feed ID: %w", err)
+	}
+
+	deleted, err := s.feedsRepo.Delete(ctx, objID)
+	if err != nil {
+		return fmt.Errorf("failed to delete feed: %w", err)
+	}
+	if deleted == 0 {
+		return fmt.Errorf("feed not found")
+	}
+	return nil
+}
+
+// Sync checks the followed feeds for new episodes, or only the feed with the given ID
+// A feed that fails is recorded in the report and on the feed without stopping the rest
+func (s *PodcastService) Sync(ctx context.Context, feedID string) (*models.PodcastSyncReport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var feeds []models.PodcastFeed
+	if feedID != "" {
+		objID, err := primitive.ObjectIDFromHex(feedID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid feed ID: %w", err)
+		}
+		feed, err := s.feedsRepo.FindByID(ctx, objID)
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("feed not found")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load feed: %w", err)
+		}
+		feeds = []models.PodcastFeed{*feed}
+	} else {
+		var err error
+		if feeds, err = s.feedsRepo.FindAll(ctx); err != nil {
+			return nil, fmt.Errorf("failed to load feeds: %w", err)
+		}
+	}
+
+	report := &models.PodcastSyncReport{StartedAt: time.Now(), Feeds: []models.PodcastFeedSync{}}
+	for i := range feeds {
+		result := s.syncFeed(ctx, &feeds[i])
+		if err := s.feedsRepo.SetCheckStatus(ctx, feeds[i].ID, time.Now(), result.Error); err != nil {
+			log.Printf("Failed to record sync status of feed %s: %v", feeds[i].Title, err)
+		}
+		if result.Created > 0 || result.Error != "" {
+			log.Printf("Podcast sync of %s: %d new notes, %d without transcript, %d failed %s",
+				result.Feed, result.Created, result.NoTranscript, result.Failed, result.Error)
+		}
+		report.Feeds = append(report.Feeds, result)
+	}
+	report.FinishedAt = time.Now()
+	return report, nil
+}
+
+// syncFeed creates notes for a feed's latest episodes that aren't saved yet
+func (s *PodcastService) syncFeed(ctx context.Context, podcastFeed *models.PodcastFeed) models.PodcastFeedSync {
+	result := models.PodcastFeedSync{Feed: podcastFeed.Title}
+
+	feed, err := s.podcast.FetchFeed(ctx, podcastFeed.URL)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	episodes := feed.Episodes
+	if len(episodes) > config.PODCAST_SYNC_MAX_EPISODES {
+		episodes = episodes[:config.PODCAST_SYNC_MAX_EPISODES]
+	}
+	for i := range episodes {
+		episode := &episodes[i]
+		result.Checked++
+
+		// Checked before downloading anything, so saved episodes cost no transcription or AI calls
+		exists, err := s.notesRepo.ExistsByURL(ctx, episode.URL())
+		if err != nil {
+			s.episodeFailed(&result, episode, err)
+			continue
+		}
+		if exists {
+			result.Existing++
+			continue
+		}
+
+		transcript, err := s.episodeTranscript(ctx, episode)
+		if errors.Is(err, podcast.ErrNoTranscript) {
+			result.NoTranscript++
+			continue
+		}
+		if err != nil {
+			s.episodeFailed(&result, episode, err)
+			continue
+		}
+
+		metadata := map[string]interface{}{
+			"author":       podcastFeed.Title,
+			"platform":     "podcast",
+			"url":          episode.URL(),
+			"source":       "podcast-feed",
+			"feed_url":     podcastFeed.URL,
+			"episode_guid": episode.GUID,
+		}
+		if !episode.PublishedAt.IsZero() {
+			metadata["timestamp"] = episode.PublishedAt.Format(time.RFC3339)
+		}
+		if episode.AudioURL != "" {
+			metadata["audio_url"] = episode.AudioURL
+		}
+		if episode.Duration != "" {
+			metadata["duration"] = episode.Duration
+		}
+
+		created, err := s.notesService.CreateNote(ctx, &models.CreateNoteRequest{
+			Title:    episode.Title,
+			Content:  transcript,
+			Category: "podcast-transcripts",
+			Metadata: metadata,
+		})
+		if err != nil {
+			s.episodeFailed(&result, episode, err)
+			continue
+		}
+		if created.Duplicate {
+			result.Existing++
+			continue
+		}
+		result.Created++
+	}
+	return result
+}
+
+// episodeTranscript returns the published transcript of an episode, or transcribes its audio
+// Returns podcast.ErrNoTranscript when neither is possible
+func (s *PodcastService) episodeTranscript(ctx context.Context, episode *podcast.Episode) (string, error) {
+	transcript, err := s.podcast.FetchTranscript(ctx, episode)
+	if err == nil {
+		return transcript, nil
+	}
+	if s.transcriber == nil || episode.AudioURL == "" {
+		return "", err
+	}
+	if !errors.Is(err, podcast.ErrNoTranscript) {
+		// A broken transcript link falls back to transcribing the audio
+		log.Printf("Published transcript of %q unavailable, transcribing audio: %v", episode.Title, err)
+	}
+
+	audio, err := s.podcast.FetchAudio(ctx, episode)
+	if err != nil {
+		return "", err
+	}
+	transcript, err = s.transcriber.Transcribe(ctx, audioFileName(episode), audio)
+	if err != nil {
+		return "", err
+	}
+	if transcript == "" {
+		return "", podcast.ErrNoTranscript
+	}
+	return transcript, nil
+}
+
+// episodeFailed records an episode that couldn't be saved
+func (s *PodcastService) episodeFailed(result *models.PodcastFeedSync, episode *podcast.Episode, err error) {
+	result.Failed++
+	result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", episode.Title, err))
+}
+
+// audioExtensions maps enclosure types to the file extension transcription APIs detect formats by
+var audioExtensions = map[string]string{
+	"audio/mpeg":  ".mp3",
+	"audio/mp3":   ".mp3",
+	"audio/mp4":   ".m4a",
+	"audio/x-m4a": ".m4a",
+	"audio/aac":   ".m4a",
+	"audio/ogg":   ".ogg",
+	"audio/wav":   ".wav",
+	"audio/x-wav": ".wav",
+	"audio/webm":  ".webm",
+	"audio/flac":  ".flac",
+}
+
+// audioFileName names an episode's audio file for upload, keeping the extension of its URL
+func audioFileName(episode *podcast.Episode) string {
+	name := "episode"
+	if parsed, err := url.Parse(episode.AudioURL); err == nil {
+		if base := path.Base(parsed.Path); base != "." && base != "/" {
+			name = base
+		}
+	}
+	if path.Ext(name) == "" {
+		ext, ok := audioExtensions[strings.ToLower(episode.AudioType)]
+		if !ok {
+			ext = ".mp3"
+		}
+		name += ext
+	}
+	return name
+}
@@ -0,0 +1,230 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repository"
+)
+
+// builtinPresets are the channel presets shipped with the app; an imported preset with the same name replaces one
+var builtinPresets = []models.ChannelPreset{
+	{
+		Name:        "podcast",
+		Description: "Conversational episodes: guests, takeaways and the topics discussed",
+		Platform:    "podcast",
+		PromptText:  "Summarize this podcast episode. Name the host and guests, list the key takeaways a listener should remember, and the main topics discussed in order.",
+		PromptSchema: `{"summary": "2-3 sentence overview of the episode", "guests": ["guest name"], ` +
+			`"key_takeaways": ["takeaway"], "topics": ["topic"]}`,
+		// Transcripts wander between topics; smaller chunks keep each embedding on one of them
+		Chunking: &models.ChunkingHints{ChunkSize: 600, ChunkOverlap: 100},
+	},
+	{
+		Name:        "tutorial-video",
+		Description: "How-to videos: prerequisites, tools and the steps in order",
+		Platform:    "youtube",
+		PromptText:  "Summarize this tutorial. List what the viewer needs beforehand, the tools or software used, and the steps shown, in order and each in one sentence.",
+		PromptSchema: `{"summary": "what the tutorial teaches", "prerequisites": ["prerequisite"], ` +
+			`"tools": ["tool or software"], "steps": ["step"]}`,
+		Chunking: &models.ChunkingHints{ChunkSize: 500, ChunkOverlap: 75},
+	},
+	{
+		Name:        "finance-news",
+		Description: "Market and company news: tickers, figures and sentiment",
+		Platform:    "web",
+		PromptText:  "Summarize this finance news item. List the companies and their tickers, the figures reported (prices, earnings, percentages) with what they measure, and whether the news is positive, negative or neutral for them.",
+		PromptSchema: `{"summary": "1-2 sentence summary", "companies": [{"name": "company", "ticker": "TICKER"}], ` +
+			`"figures": ["figure and what it measures"], "sentiment": "positive|negative|neutral"}`,
+		// Short, dense articles; small chunks keep figures next to the company they belong to
+		Chunking: &models.ChunkingHints{ChunkSize: 300, ChunkOverlap: 30},
+	},
+}
+
+// PresetService manages channel presets and configures channels from them
+type PresetService struct {
+	presetsRepo         *repository.ChannelPresetsRepository
+	channelSettingsRepo *repository.ChannelSettingsRepository
+}
+
+// NewPresetService creates a new PresetService
+func NewPresetService(presetsRepo *repository.ChannelPresetsRepository, channelSettingsRepo *repository.ChannelSettingsRepository) *PresetService {
+	return &PresetService{
+		presetsRepo:         presetsRepo,
+		channelSettingsRepo: channelSettingsRepo,
+	}
+}
+
+// ListPresets returns the built-in and imported presets, ordered by name
+func (s *PresetService) ListPresets(ctx context.Context) ([]models.ChannelPreset, error) {
+	imported, err := s.presetsRepo.FindAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get presets: %w", err)
+	}
+
+	presets := imported
+	for _, builtin := range builtinPresets {
+		if !slices.ContainsFunc(imported, func(p models.ChannelPreset) bool { return p.Name == builtin.Name }) {
+			builtin.BuiltIn = true
+			presets = append(presets, builtin)
+		}
+	}
+	slices.SortFunc(presets, func(a, b models.ChannelPreset) int { return strings.Compare(a.Name, b.Name) })
+	return presets, nil
+}
+
+// GetPreset returns the preset with the given name
+func (s *PresetService) GetPreset(ctx context.Context, name string) (*models.ChannelPreset, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	preset, err := s.presetsRepo.FindByName(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get preset: %w", err)
+	}
+	if preset != nil {
+		return preset, nil
+	}
+	for _, builtin := range builtinPresets {
+		if builtin.Name == name {
+			builtin.BuiltIn = true
+			return &builtin, nil
+		}
+	}
+	return nil, fmt.Errorf("preset not found: %q", name)
+}
+
+// ExportPresets returns a library of the named presets, or of every preset when no names are given
+func (s *PresetService) ExportPresets(ctx context.Context, names []string) (*models.ChannelPresetLibrary, error) {
+	var presets []models.ChannelPreset
+	if len(names) == 0 {
+		var err error
+		if presets, err = s.ListPresets(ctx); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range names {
+		preset, err := s.GetPreset(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		presets = append(presets, *preset)
+	}
+
+	return &models.ChannelPresetLibrary{
+		Format:  models.ChannelPresetFormat,
+		Version: models.ChannelPresetVersion,
+		Presets: presets,
+	}, nil
+}
+
+// ImportPresets validates a preset library and saves its presets, replacing those with the same
+// names. Nothing is saved unless every preset is valid. Returns the number of presets imported.
+func (s *PresetService) ImportPresets(ctx context.Context, library *models.ChannelPresetLibrary) (int, error) {
+	if library.Format != models.ChannelPresetFormat {
+		return 0, fmt.Errorf("invalid preset library: format must be %q", models.ChannelPresetFormat)
+	}
+	if library.Version < 1 || library.Version > models.ChannelPresetVersion {
+		return 0, fmt.Errorf("invalid preset library: unsupported version %d", library.Version)
+	}
+	if len(library.Presets) == 0 {
+		return 0, fmt.Errorf("invalid preset library: no presets")
+	}
+
+	presets := make([]models.ChannelPreset, 0, len(library.Presets))
+	for _, preset := range library.Presets {
+		preset.Name = strings.ToLower(strings.TrimSpace(preset.Name))
+		if preset.Name == "" {
+			return 0, fmt.Errorf("invalid preset library: every preset needs a name")
+		}
+		if slices.ContainsFunc(presets, func(p models.ChannelPreset) bool { return p.Name == preset.Name }) {
+			return 0, fmt.Errorf("invalid preset library: preset %q is listed twice", preset.Name)
+		}
+		if err := validPreset(&preset); err != nil {
+			return 0, fmt.Errorf("invalid preset %q: %w", preset.Name, err)
+		}
+		preset.BuiltIn = false
+		presets = append(presets, preset)
+	}
+
+	for i := range presets {
+		if err := s.presetsRepo.Upsert(ctx, &presets[i]); err != nil {
+			return i, fmt.Errorf("failed to save preset %q: %w", presets[i].Name, err)
+		}
+	}
+	return len(presets), nil
+}
+
+// ApplyPreset configures a channel's prompt, schema and chunking from a preset
+// The channel's other settings (URL, aliases, search weight) are kept.
+func (s *PresetService) ApplyPreset(ctx context.Context, channel string, req *models.ApplyChannelPresetRequest) (*models.ChannelSettings, error) {
+	preset, err := s.GetPreset(ctx, req.Preset)
+	if err != nil {
+		return nil, err
+	}
+
+	platform := preset.Platform
+	if req.Platform != "" {
+		if !config.IsValidPlatform(req.Platform) {
+			return nil, fmt.Errorf("invalid platform: %q", req.Platform)
+		}
+		platform = req.Platform
+	}
+	platform = config.NormalizePlatform(platform)
+
+	settings := models.ChannelSettings{ChannelName: channel, Platform: platform}
+	existing, err := s.channelSettingsRepo.FindByChannel(ctx, platform, channel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get channel settings: %w", err)
+	}
+	if existing != nil && existing.ChannelName == channel && existing.Platform == platform {
+		settings = *existing
+	}
+
+	settings.PromptText = preset.PromptText
+	settings.PromptSchema = preset.PromptSchema
+	settings.Chunking = preset.Chunking
+	settings.UpdatedAt = time.Now()
+	if err := s.channelSettingsRepo.Upsert(ctx, &settings); err != nil {
+		return nil, fmt.Errorf("failed to save channel settings: %w", err)
+	}
+	return &settings, nil
+}
+
+// validPreset checks a preset's platform, prompt, schema and chunking hints, normalizing its platform
+func validPreset(preset *models.ChannelPreset) error {
+	if preset.Platform != "" {
+		platform, ok := config.LookupPlatform(preset.Platform)
+		if !ok {
+			return fmt.Errorf("unknown platform %q", preset.Platform)
+		}
+		preset.Platform = platform.Name
+	}
+	if strings.TrimSpace(preset.PromptText) == "" && strings.TrimSpace(preset.PromptSchema) == "" {
+		return fmt.Errorf("a prompt or schema is required")
+	}
+	if preset.PromptSchema != "" {
+		var schema json.RawMessage
+		if err := json.Unmarshal([]byte(preset.PromptSchema), &schema); err != nil {
+			return fmt.Errorf("invalid JSON in promptSchema")
+		}
+	}
+	return ValidateChunking(preset.Chunking)
+}
+
+// ValidateChunking checks a channel's chunking hints; nil hints keep the defaults
+func ValidateChunking(hints *models.ChunkingHints) error {
+	if hints == nil {
+		return nil
+	}
+	if hints.ChunkSize < 0 || hints.ChunkOverlap < 0 {
+		return fmt.Errorf("chunkSize and chunkOverlap must not be negative")
+	}
+	if hints.ChunkSize > 0 && hints.ChunkOverlap >= hints.ChunkSize {
+		return fmt.Errorf("chunkOverlap must be less than chunkSize")
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"log"
+	"math"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/utils"
+)
+
+// rankingBoosts are the resolved score boosts for a single search
+type rankingBoosts struct {
+	pinned         float64
+	summary        float64
+	recency        float64
+	halfLifeDays   float64
+	popularity     float64
+	channelWeights map[string]float64 // Normalized channel key -> score multiplier
+}
+
+// resolveBoosts merges per-request overrides over the configured boosts and channel search weights
+// Channel weights are best-effort: if settings can't be loaded, channels are unweighted
+func (s *SearchService) resolveBoosts(ctx context.Context, override *models.RankingBoosts) *rankingBoosts {
+	boosts := &rankingBoosts{
+		pinned:         s.cfg.BoostPinned,
+		summary:        s.cfg.BoostSummary,
+		recency:        s.cfg.BoostRecency,
+		halfLifeDays:   s.cfg.RecencyHalfLifeDays,
+		popularity:     s.cfg.BoostPopularity,
+		channelWeights: make(map[string]float64),
+	}
+
+	settings, err := s.channelSettingsRepo.FindAll(ctx)
+	if err != nil {
+		log.Printf("Failed to load channel search weights: %v", err)
+	}
+	for _, cs := range settings {
+		if cs.SearchWeight <= 0 {
+			continue
+		}
+		boosts.channelWeights[utils.NormalizeChannelKey(cs.ChannelName)] = cs.SearchWeight
+		for _, key := range cs.AliasKeys {
+			boosts.channelWeights[key] = cs.SearchWeight
+		}
+	}
+
+	if override == nil {
+		return boosts
+	}
+	if override.Pinned != nil {
+		boosts.pinned = *override.Pinned
+	}
+	if override.Summary != nil {
+		boosts.summary = *override.Summary
+	}
+	if override.Recency != nil {
+		boosts.recency = *override.Recency
+	}
+	if override.RecencyHalfLifeDays != nil {
+		boosts.halfLifeDays = *override.RecencyHalfLifeDays
+	}
+	if override.Popularity != nil {
+		boosts.popularity = *override.Popularity
+	}
+	for channel, weight := range override.ChannelWeights {
+		boosts.channelWeights[utils.NormalizeChannelKey(channel)] = weight
+	}
+
+	return boosts
+}
+
+// multiplier returns the factor a note's relevance score is scaled by, and the contribution of
+// each boost to it ("channel" is the channel weight the sum is scaled by)
+func (b *rankingBoosts) multiplier(note *models.Note, now time.Time) (float64, map[string]float64) {
+	multiplier := 1.0
+	breakdown := make(map[string]float64)
+	if note.Pinned && b.pinned != 0 {
+		multiplier += b.pinned
+		breakdown["pinned"] = b.pinned
+	}
+	if note.Summary != "" && b.summary != 0 {
+		multiplier += b.summary
+		breakdown["summary"] = b.summary
+	}
+	if b.recency != 0 && b.halfLifeDays > 0 {
+		ageDays := now.Sub(note.Created).Hours() / 24
+		if ageDays < 0 {
+			ageDays = 0
+		}
+		recency := b.recency * math.Exp2(-ageDays/b.halfLifeDays)
+		multiplier += recency
+		breakdown["recency"] = recency
+	}
+	if b.popularity != 0 && note.ViewCount > 0 {
+		popularity := b.popularity * (1 - math.Exp2(-float64(note.ViewCount)/config.POPULARITY_HALF_VIEWS))
+		multiplier += popularity
+		breakdown["popularity"] = popularity
+	}
+
+	if author, ok := note.Metadata["author"].(string); ok && author != "" {
+		if weight, ok := b.channelWeights[utils.NormalizeChannelKey(author)]; ok {
+			multiplier *= weight
+			breakdown["channel"] = weight
+		}
+	}
+
+	return multiplier, breakdown
+}
+
+// apply scales each result's score by its boost multiplier; callers re-sort afterwards
+// Results carrying an explanation get the multiplier and its breakdown recorded
+func (b *rankingBoosts) apply(results []models.SearchResult) {
+	if b == nil {
+		return
+	}
+
+	now := time.Now()
+	for i := range results {
+		multiplier, breakdown := b.multiplier(&results[i].Note, now)
+		results[i].Score = float32(float64(results[i].Score) * multiplier)
+		if results[i].Explain != nil {
+			results[i].Explain.BoostMultiplier = multiplier
+			results[i].Explain.Boosts = breakdown
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/vectordb"
+)
+
+// ReadinessService warms up vector search at startup and reports whether search is serviceable,
+// so load balancers only route traffic to an instance once its first searches won't be slow or fail
+type ReadinessService struct {
+	qdrantClient *vectordb.QdrantClient
+
+	warmedUp atomic.Bool
+	stop     chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewReadinessService creates a new ReadinessService
+func NewReadinessService(qdrantClient *vectordb.QdrantClient) *ReadinessService {
+	return &ReadinessService{
+		qdrantClient: qdrantClient,
+	}
+}
+
+// Start retries the warm-up in the background until it succeeds or Stop is called
+func (s *ReadinessService) Start() {
+	s.stop = make(chan struct{})
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			err := s.warmUp()
+			if err == nil {
+				s.warmedUp.Store(true)
+				log.Println("Vector search warmed up")
+				return
+			}
+			log.Printf("Vector search warm-up failed, retrying in %ds: %v", config.WARMUP_RETRY_SECONDS, err)
+
+			select {
+			case <-time.After(config.WARMUP_RETRY_SECONDS * time.Second):
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop abandons a warm-up still in progress
+func (s *ReadinessService) Stop() {
+	if s.stop == nil {
+		return
+	}
+	close(s.stop)
+	s.wg.Wait()
+}
+
+// warmUp makes a single warm-up attempt
+func (s *ReadinessService) warmUp() error {
+	ctx, cancel := context.WithTimeout(context.Background(), config.WARMUP_TIMEOUT_SECONDS*time.Second)
+	defer cancel()
+	return s.qdrantClient.WarmUp(ctx)
+}
+
+// Check returns an error unless the warm-up has finished and the collection is still available
+func (s *ReadinessService) Check(ctx context.Context) error {
+	if !s.warmedUp.Load() {
+		return fmt.Errorf("vector search is warming up")
+	}
+	ctx, cancel := context.WithTimeout(ctx, config.READINESS_TIMEOUT_SECONDS*time.Second)
+	defer cancel()
+	return s.qdrantClient.CheckCollection(ctx)
+}
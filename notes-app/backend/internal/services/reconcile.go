@@ -0,0 +1,215 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/utils"
+	"backend/internal/vectordb"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReconciliationService compares chunks in MongoDB against vectors in Qdrant, so missing or stale
+// embeddings are noticed before search quality drops
+type ReconciliationService struct {
+	chunksRepo   *repository.ChunksRepository
+	reportsRepo  *repository.ReconciliationRepository
+	qdrantClient *vectordb.QdrantClient
+	cfg          *config.Config
+
+	mu   sync.Mutex // Serializes runs so a manual run can't overlap the scheduled one
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewReconciliationService creates a new ReconciliationService
+func NewReconciliationService(
+	chunksRepo *repository.ChunksRepository,
+	reportsRepo *repository.ReconciliationRepository,
+	qdrantClient *vectordb.QdrantClient,
+	cfg *config.Config,
+) *ReconciliationService {
+	return &ReconciliationService{
+		chunksRepo:   chunksRepo,
+		reportsRepo:  reportsRepo,
+		qdrantClient: qdrantClient,
+		cfg:          cfg,
+	}
+}
+
+// Start runs reconciliation on the configured interval until Stop is called
+// A zero or negative interval leaves the schedule disabled
+func (s *ReconciliationService) Start() {
+	if s.cfg.ReconcileIntervalHours <= 0 {
+		log.Println("Reconciliation schedule disabled")
+		return
+	}
+
+	interval := time.Duration(s.cfg.ReconcileIntervalHours * float64(time.Hour))
+	s.stop = make(chan struct{})
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := s.Run(context.Background()); err != nil {
+					log.Printf("Scheduled reconciliation failed: %v", err)
+				}
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+	log.Printf("Reconciliation scheduled every %s", interval)
+}
+
+// Stop ends the schedule and waits for a run in progress to finish
+func (s *ReconciliationService) Stop() {
+	if s.stop == nil {
+		return
+	}
+	close(s.stop)
+	s.wg.Wait()
+}
+
+// Run reconciles every chunk against the stored points and saves the report
+// A failed run is still recorded, with its error, so gaps in the history are visible
+func (s *ReconciliationService) Run(ctx context.Context) (*models.ReconciliationReport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	report := &models.ReconciliationReport{
+		StartedAt:      time.Now(),
+		AlertThreshold: s.cfg.ReconcileDriftAlert,
+		DriftedNoteIDs: []primitive.ObjectID{},
+	}
+
+	runErr := s.reconcile(ctx, report)
+	report.FinishedAt = time.Now()
+	if runErr != nil {
+		report.Error = runErr.Error()
+	}
+
+	if _, err := s.reportsRepo.Create(ctx, report); err != nil {
+		log.Printf("Failed to save reconciliation report: %v", err)
+	}
+
+	if runErr != nil {
+		return nil, runErr
+	}
+
+	if report.Alert {
+		log.Printf("ALERT: vector drift %.2f%% exceeds %.2f%% (missing=%d orphan=%d duplicate=%d mismatched=%d)",
+			report.DriftRatio*100, report.AlertThreshold*100,
+			report.MissingVectors, report.OrphanVectors, report.DuplicateVectors, report.HashMismatches)
+	} else {
+		log.Printf("Reconciliation checked %d chunks and %d points, drift %.2f%%",
+			report.Chunks, report.Points, report.DriftRatio*100)
+	}
+
+	return report, nil
+}
+
+// GetReports returns the most recent reconciliation reports, newest first
+func (s *ReconciliationService) GetReports(ctx context.Context, limit int) ([]models.ReconciliationReport, error) {
+	return s.reportsRepo.FindRecent(ctx, limit)
+}
+
+// reconcile fills in the report counts
+// Points are indexed by chunk ID first, then each chunk is checked off against them; whatever is
+// left over has no chunk in MongoDB.
+func (s *ReconciliationService) reconcile(ctx context.Context, report *models.ReconciliationReport) error {
+	type pointInfo struct {
+		noteID string
+		hash   string
+	}
+
+	points := make(map[string]pointInfo)
+	var offset *uint64
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		page, next, err := s.qdrantClient.ScrollPoints(offset, config.EMBEDDINGS_PAGE_SIZE, false)
+		if err != nil {
+			return err
+		}
+		for _, point := range page {
+			report.Points++
+			if _, seen := points[point.ChunkID]; seen {
+				report.DuplicateVectors++
+				continue
+			}
+			points[point.ChunkID] = pointInfo{noteID: point.NoteID, hash: point.Payload.ContentHash}
+		}
+		if next == nil {
+			break
+		}
+		offset = next
+	}
+
+	drifted := make(map[primitive.ObjectID]bool)
+	markDrift := func(noteID primitive.ObjectID) {
+		if len(drifted) < config.MAX_RECONCILE_DRIFT_NOTES {
+			drifted[noteID] = true
+		}
+	}
+
+	err := s.chunksRepo.ForEach(ctx, func(chunk *models.NoteChunk) error {
+		report.Chunks++
+		point, ok := points[chunk.ID.Hex()]
+		if !ok {
+			report.MissingVectors++
+			markDrift(chunk.NoteID)
+			return nil
+		}
+		delete(points, chunk.ID.Hex())
+
+		switch point.hash {
+		case "":
+			report.Unverified++
+		case utils.ContentHash(chunk.Content):
+		default:
+			report.HashMismatches++
+			markDrift(chunk.NoteID)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to scan chunks: %w", err)
+	}
+
+	report.OrphanVectors = len(points)
+	for _, point := range points {
+		if noteID, err := primitive.ObjectIDFromHex(point.noteID); err == nil {
+			markDrift(noteID)
+		}
+	}
+
+	for noteID := range drifted {
+		report.DriftedNoteIDs = append(report.DriftedNoteIDs, noteID)
+	}
+
+	drift := report.MissingVectors + report.OrphanVectors + report.DuplicateVectors + report.HashMismatches
+	total := report.Chunks
+	if report.Points > total {
+		total = report.Points
+	}
+	if total > 0 {
+		report.DriftRatio = float64(drift) / float64(total)
+	}
+	report.Alert = report.DriftRatio > report.AlertThreshold
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/vectordb"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ReindexService rebuilds the vector index from scratch, e.g. after switching EMBEDDING_MODEL
+// Embeddings from different models can't be compared, so the Qdrant collection is wiped rather
+// than updated in place and every note is re-chunked and re-embedded by the worker pool. Search
+// and Q&A only see the notes re-embedded so far until the run completes.
+type ReindexService struct {
+	notesRepo    *repository.NotesRepository
+	chunksRepo   *repository.ChunksRepository
+	runsRepo     *repository.MigrationRunsRepository
+	workerPool   *WorkerPool
+	qdrantClient *vectordb.QdrantClient
+	cfg          *config.Config
+}
+
+// NewReindexService creates a new ReindexService
+func NewReindexService(
+	notesRepo *repository.NotesRepository,
+	chunksRepo *repository.ChunksRepository,
+	runsRepo *repository.MigrationRunsRepository,
+	workerPool *WorkerPool,
+	qdrantClient *vectordb.QdrantClient,
+	cfg *config.Config,
+) *ReindexService {
+	return &ReindexService{
+		notesRepo:    notesRepo,
+		chunksRepo:   chunksRepo,
+		runsRepo:     runsRepo,
+		workerPool:   workerPool,
+		qdrantClient: qdrantClient,
+		cfg:          cfg,
+	}
+}
+
+// Reindex wipes every chunk and vector and queues an embedding job for each note, in note ID order
+// The run is recorded as a reindex migration run; GetProgress reports how far the workers have got.
+func (s *ReindexService) Reindex(ctx context.Context) (*models.ReindexProgress, error) {
+	if unfinished, err := s.runsRepo.FindUnfinished(ctx, models.MigrationReindex); err == nil {
+		progress, err := s.progress(ctx, unfinished)
+		if err != nil {
+			return nil, err
+		}
+		if progress.Remaining > 0 {
+			return nil, fmt.Errorf("reindex already running: %d notes left to re-embed", progress.Remaining)
+		}
+	} else if err != mongo.ErrNoDocuments {
+		return nil, fmt.Errorf("failed to find unfinished reindex: %w", err)
+	}
+
+	notes, err := s.notesRepo.FindAll(ctx, bson.M{}, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find notes: %w", err)
+	}
+
+	if err := s.qdrantClient.Recreate(); err != nil {
+		return nil, fmt.Errorf("failed to reset vector index: %w", err)
+	}
+	deleted, err := s.chunksRepo.DeleteAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete chunks: %w", err)
+	}
+	log.Printf("Reindexing %d notes with %s: deleted %d chunks and their vectors", len(notes), s.cfg.EmbeddingModel, deleted)
+
+	now := time.Now()
+	run := &models.MigrationRun{
+		Kind:        models.MigrationReindex,
+		Status:      models.MigrationRunning,
+		Parallelism: s.workerPool.workerCount,
+		Total:       len(notes),
+		StartedAt:   now,
+		UpdatedAt:   now,
+	}
+	if run.ID, err = s.runsRepo.Create(ctx, run); err != nil {
+		return nil, fmt.Errorf("failed to record reindex run: %w", err)
+	}
+
+	for i := range notes {
+		note := &notes[i]
+		err := s.workerPool.Submit(ctx, models.ProcessingJob{
+			NoteID:         note.ID,
+			Title:          note.Title,
+			Content:        note.Content,
+			Category:       note.Category,
+			NoteCreated:    note.Created,
+			Metadata:       note.Metadata,
+			Summary:        note.Summary,
+			StructuredData: note.StructuredData,
+		})
+		if err != nil {
+			run.Status = models.MigrationInterrupted
+			s.save(run)
+			return nil, fmt.Errorf("reindex interrupted after queueing %d of %d notes: %w", i, len(notes), err)
+		}
+		run.Checkpoint = note.ID
+	}
+	s.save(run)
+
+	return s.progress(ctx, run)
+}
+
+// GetProgress reports the progress of the latest reindex, completing its run once no note is left to re-embed
+func (s *ReindexService) GetProgress(ctx context.Context) (*models.ReindexProgress, error) {
+	run, err := s.runsRepo.FindLatest(ctx, models.MigrationReindex)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("reindex not found")
+		}
+		return nil, fmt.Errorf("failed to find reindex: %w", err)
+	}
+	return s.progress(ctx, run)
+}
+
+// progress counts the notes by processing status and updates the run's counts from them
+// Every note is queued by the run, so the statuses track its progress; notes created since are
+// counted too, as they are embedded by the same workers.
+func (s *ReindexService) progress(ctx context.Context, run *models.MigrationRun) (*models.ReindexProgress, error) {
+	counts, err := s.notesRepo.ProcessingStatusCounts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count processing statuses: %w", err)
+	}
+
+	progress := &models.ReindexProgress{
+		Run:            run,
+		EmbeddingModel: s.cfg.EmbeddingModel,
+		StatusCounts:   counts,
+		Remaining:      counts[models.ProcessingPending] + counts[models.ProcessingRunning],
+	}
+	if run.Status != models.MigrationRunning {
+		return progress, nil
+	}
+
+	run.Succeeded = counts[models.ProcessingIndexed] + counts[models.ProcessingSkipped]
+	run.Errors = counts[models.ProcessingFailed]
+	run.Processed = run.Succeeded + run.Errors
+	if progress.Remaining == 0 {
+		finished := time.Now()
+		run.Status = models.MigrationCompleted
+		run.FinishedAt = &finished
+		log.Printf("Completed reindex %s: %d notes re-embedded, %d failed", run.ID.Hex(), run.Succeeded, run.Errors)
+	}
+	s.save(run)
+	return progress, nil
+}
+
+// save records a run's progress; it outlives the request so a cut-short run is still recorded
+func (s *ReindexService) save(run *models.MigrationRun) {
+	run.UpdatedAt = time.Now()
+	if err := s.runsRepo.Save(context.Background(), run); err != nil {
+		log.Printf("Failed to save progress of reindex %s: %v", run.ID.Hex(), err)
+	}
+}
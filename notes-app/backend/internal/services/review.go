@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/vectordb"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ReviewService manages the low-confidence classification review queue
+// and the few-shot examples learned from confirmed reviews
+type ReviewService struct {
+	notesRepo      *repository.NotesRepository
+	examplesRepo   *repository.ClassificationExamplesRepository
+	webhookService *WebhookService
+	qdrantClient   *vectordb.QdrantClient
+	cfg            *config.Config
+}
+
+// NewReviewService creates a new ReviewService
+func NewReviewService(
+	notesRepo *repository.NotesRepository,
+	examplesRepo *repository.ClassificationExamplesRepository,
+	webhookService *WebhookService,
+	qdrantClient *vectordb.QdrantClient,
+	cfg *config.Config,
+) *ReviewService {
+	return &ReviewService{
+		notesRepo:      notesRepo,
+		examplesRepo:   examplesRepo,
+		webhookService: webhookService,
+		qdrantClient:   qdrantClient,
+		cfg:            cfg,
+	}
+}
+
+// NeedsReview reports whether a classification confidence is below the review threshold
+func (s *ReviewService) NeedsReview(confidence float64) bool {
+	return confidence < s.cfg.ReviewConfidenceThreshold
+}
+
+// FewShotExamples returns recent confirmed classifications to include in prompts
+// Errors are logged and treated as "no examples" so classification never fails because of them
+func (s *ReviewService) FewShotExamples(ctx context.Context) []models.ClassificationExample {
+	examples, err := s.examplesRepo.FindRecent(ctx, config.FEW_SHOT_EXAMPLES)
+	if err != nil {
+		log.Printf("Failed to load classification examples: %v", err)
+		return nil
+	}
+	return examples
+}
+
+// GetReviewQueue returns notes whose classification needs review, lowest confidence first
+func (s *ReviewService) GetReviewQueue(ctx context.Context, limit int) ([]models.Note, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "category_confidence", Value: 1}, {Key: "created", Value: -1}}).
+		SetLimit(int64(limit))
+
+	return s.notesRepo.FindAll(ctx, bson.M{"needs_review": true}, opts)
+}
+
+// ReviewItemError describes a review decision that could not be applied
+type ReviewItemError struct {
+	NoteID string `json:"noteId"`
+	Error  string `json:"error"`
+}
+
+// ReviewResult summarizes a bulk review submission
+type ReviewResult struct {
+	Confirmed int               `json:"confirmed"`
+	Corrected int               `json:"corrected"`
+	Errors    []ReviewItemError `json:"errors"`
+}
+
+// ApplyDecisions confirms or corrects categories in bulk and records them as few-shot examples
+func (s *ReviewService) ApplyDecisions(ctx context.Context, decisions []models.ReviewDecision) (*ReviewResult, error) {
+	result := &ReviewResult{Errors: []ReviewItemError{}}
+
+	for _, decision := range decisions {
+		category := strings.ToLower(strings.TrimSpace(decision.Category))
+		if !config.IsValidCategory(category) {
+			result.Errors = append(result.Errors, ReviewItemError{NoteID: decision.NoteID, Error: "invalid category"})
+			continue
+		}
+
+		objID, err := primitive.ObjectIDFromHex(decision.NoteID)
+		if err != nil {
+			result.Errors = append(result.Errors, ReviewItemError{NoteID: decision.NoteID, Error: "invalid note ID"})
+			continue
+		}
+
+		note, err := s.notesRepo.FindByID(ctx, objID)
+		if err != nil {
+			result.Errors = append(result.Errors, ReviewItemError{NoteID: decision.NoteID, Error: "note not found"})
+			continue
+		}
+
+		update := bson.M{
+			"$set": bson.M{
+				"category":            category,
+				"category_confidence": 1.0,
+			},
+			"$unset": bson.M{"needs_review": "", "category_generation": ""},
+		}
+		if err := s.notesRepo.Update(ctx, objID, update); err != nil {
+			result.Errors = append(result.Errors, ReviewItemError{NoteID: decision.NoteID, Error: "failed to update note"})
+			continue
+		}
+
+		if category == note.Category {
+			result.Confirmed++
+		} else {
+			result.Corrected++
+			// Category-scoped searches filter on the embeddings' payload, which isn't re-embedded here
+			if err := s.qdrantClient.SetNoteCategory([]primitive.ObjectID{objID}, category); err != nil {
+				log.Printf("Failed to update the category of note %s in Qdrant: %v", decision.NoteID, err)
+			}
+		}
+		s.webhookService.Emit(ctx, models.NoteEventUpdated, objID, map[string]interface{}{"category": category, "needsReview": nil})
+
+		// Feed the decision back as a few-shot example for future classifications
+		example := &models.ClassificationExample{
+			NoteID:    objID,
+			Title:     note.Title,
+			Excerpt:   exampleExcerpt(note.Content),
+			Category:  category,
+			CreatedAt: time.Now(),
+		}
+		if err := s.examplesRepo.Upsert(ctx, example); err != nil {
+			log.Printf("Failed to store classification example for note %s: %v", decision.NoteID, err)
+		}
+	}
+
+	if len(decisions) > 0 && len(result.Errors) == len(decisions) {
+		return result, fmt.Errorf("no review decisions could be applied")
+	}
+
+	return result, nil
+}
+
+// exampleExcerpt shortens note content for use in prompts
+func exampleExcerpt(content string) string {
+	content = strings.Join(strings.Fields(content), " ")
+	if len(content) > 300 {
+		return content[:300] + "..."
+	}
+	return content
+}
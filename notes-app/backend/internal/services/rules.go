@@ -0,0 +1,288 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// RulesService manages heuristic category rules and evaluates them before the LLM classifier
+type RulesService struct {
+	rulesRepo *repository.CategoryRulesRepository
+
+	mu     sync.Mutex
+	rules  []compiledRule // Enabled rules by priority, cached until a rule changes
+	loaded bool
+}
+
+// compiledRule is an enabled rule with its regex compiled once for every note it is evaluated against
+type compiledRule struct {
+	models.CategoryRule
+	re *regexp.Regexp // Set for regex rules
+}
+
+// NewRulesService creates a new RulesService
+func NewRulesService(rulesRepo *repository.CategoryRulesRepository) *RulesService {
+	return &RulesService{
+		rulesRepo: rulesRepo,
+	}
+}
+
+// GetRules retrieves all rules, highest priority first
+func (s *RulesService) GetRules(ctx context.Context) ([]models.CategoryRule, error) {
+	return s.rulesRepo.FindAll(ctx, bson.M{})
+}
+
+// CreateRule validates and stores a new rule
+func (s *RulesService) CreateRule(ctx context.Context, rule *models.CategoryRule) (*models.CategoryRule, error) {
+	if err := normalizeRule(rule); err != nil {
+		return nil, err
+	}
+
+	rule.ID = primitive.NilObjectID
+	rule.UpdatedAt = time.Now()
+
+	id, err := s.rulesRepo.Create(ctx, rule)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rule: %w", err)
+	}
+	rule.ID = id
+	s.invalidate()
+
+	return rule, nil
+}
+
+// UpdateRule validates and replaces an existing rule
+func (s *RulesService) UpdateRule(ctx context.Context, ruleID string, rule *models.CategoryRule) (*models.CategoryRule, error) {
+	objID, err := primitive.ObjectIDFromHex(ruleID)
+	if err != nil {
+		return nil, fmt.Errorf("rule not found")
+	}
+
+	if err := normalizeRule(rule); err != nil {
+		return nil, err
+	}
+
+	rule.ID = objID
+	rule.UpdatedAt = time.Now()
+
+	matched, err := s.rulesRepo.Replace(ctx, objID, rule)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update rule: %w", err)
+	}
+	s.invalidate()
+	if matched == 0 {
+		return nil, fmt.Errorf("rule not found")
+	}
+
+	return rule, nil
+}
+
+// DeleteRule removes a rule by ID
+func (s *RulesService) DeleteRule(ctx context.Context, ruleID string) error {
+	objID, err := primitive.ObjectIDFromHex(ruleID)
+	if err != nil {
+		return fmt.Errorf("rule not found")
+	}
+
+	deleted, err := s.rulesRepo.Delete(ctx, objID)
+	if err != nil {
+		return fmt.Errorf("failed to delete rule: %w", err)
+	}
+	s.invalidate()
+	if deleted == 0 {
+		return fmt.Errorf("rule not found")
+	}
+
+	return nil
+}
+
+// GetRuleByID retrieves a single rule by ID
+func (s *RulesService) GetRuleByID(ctx context.Context, ruleID string) (*models.CategoryRule, error) {
+	objID, err := primitive.ObjectIDFromHex(ruleID)
+	if err != nil {
+		return nil, fmt.Errorf("rule not found")
+	}
+
+	rule, err := s.rulesRepo.FindByID(ctx, objID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("rule not found")
+		}
+		return nil, fmt.Errorf("failed to find rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+// CountInCategories counts the rules assigning one of the categories
+func (s *RulesService) CountInCategories(ctx context.Context, categories []string) (int64, error) {
+	return s.rulesRepo.CountInCategories(ctx, categories)
+}
+
+// MoveCategory points the rules assigning one of the from categories at the to category
+// Returns the number of moved rules
+func (s *RulesService) MoveCategory(ctx context.Context, from []string, to string) (int64, error) {
+	moved, err := s.rulesRepo.SetCategory(ctx, from, to)
+	s.invalidate()
+	return moved, err
+}
+
+// Evaluate returns the first enabled rule (by priority) that matches the note, or nil if none match
+func (s *RulesService) Evaluate(ctx context.Context, title, content string, metadata map[string]interface{}) (*models.RuleMatch, error) {
+	rules, err := s.getRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rule := range rules {
+		if ruleMatches(&rule, title, content, metadata) {
+			return &models.RuleMatch{
+				RuleID:   rule.ID,
+				RuleName: rule.Name,
+				Category: rule.Category,
+				Tags:     rule.Tags,
+			}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// getRules returns the cached enabled rules, loading and compiling them after a rule changed
+func (s *RulesService) getRules(ctx context.Context) ([]compiledRule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.loaded {
+		return s.rules, nil
+	}
+
+	rules, err := s.rulesRepo.FindEnabled(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load rules: %w", err)
+	}
+
+	compiled := make([]compiledRule, 0, len(rules))
+	for _, rule := range rules {
+		c := compiledRule{CategoryRule: rule}
+		if rule.MatchType == models.RuleMatchRegex {
+			// Rules are validated when saved; one that no longer compiles never matches
+			if c.re, err = regexp.Compile(rule.Pattern); err != nil {
+				continue
+			}
+		}
+		compiled = append(compiled, c)
+	}
+
+	s.rules = compiled
+	s.loaded = true
+	return s.rules, nil
+}
+
+// invalidate drops the cached rules, so the next evaluation reloads them
+// Taking the lock waits out a load in progress, which may have read the rules before the change.
+func (s *RulesService) invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rules = nil
+	s.loaded = false
+}
+
+// normalizeRule validates a rule and cleans up its fields
+func normalizeRule(rule *models.CategoryRule) error {
+	rule.Name = strings.TrimSpace(rule.Name)
+	rule.MatchType = strings.ToLower(strings.TrimSpace(rule.MatchType))
+	rule.Field = strings.TrimSpace(rule.Field)
+	rule.Category = strings.ToLower(strings.TrimSpace(rule.Category))
+
+	if rule.Name == "" {
+		return fmt.Errorf("invalid rule: name is required")
+	}
+	if strings.TrimSpace(rule.Pattern) == "" {
+		return fmt.Errorf("invalid rule: pattern is required")
+	}
+	if !config.IsValidCategory(rule.Category) {
+		return fmt.Errorf("invalid rule: unknown category %q", rule.Category)
+	}
+
+	switch rule.MatchType {
+	case models.RuleMatchRegex:
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			return fmt.Errorf("invalid rule: bad regex: %v", err)
+		}
+	case models.RuleMatchKeyword:
+	case models.RuleMatchMetadata:
+		if rule.Field == "" {
+			return fmt.Errorf("invalid rule: metadata rules require a field")
+		}
+	default:
+		return fmt.Errorf("invalid rule: matchType must be regex, keyword or metadata")
+	}
+
+	if rule.MatchType != models.RuleMatchMetadata && rule.Field != "" && rule.Field != "title" && rule.Field != "content" {
+		return fmt.Errorf("invalid rule: field must be title, content or empty")
+	}
+
+	rule.Tags = normalizeTags(rule.Tags)
+	return nil
+}
+
+// normalizeTags lowercases, trims and de-duplicates tags
+func normalizeTags(tags []string) []string {
+	seen := make(map[string]bool)
+	normalized := []string{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized
+}
+
+// ruleMatches checks a single rule against the note fields
+func ruleMatches(rule *compiledRule, title, content string, metadata map[string]interface{}) bool {
+	if rule.MatchType == models.RuleMatchMetadata {
+		value, ok := metadata[rule.Field].(string)
+		return ok && strings.EqualFold(strings.TrimSpace(value), strings.TrimSpace(rule.Pattern))
+	}
+
+	var text string
+	switch rule.Field {
+	case "title":
+		text = title
+	case "content":
+		text = content
+	default:
+		text = title + "\n" + content
+	}
+
+	switch rule.MatchType {
+	case models.RuleMatchRegex:
+		return rule.re.MatchString(text)
+	case models.RuleMatchKeyword:
+		lowered := strings.ToLower(text)
+		for _, keyword := range strings.Split(rule.Pattern, ",") {
+			keyword = strings.ToLower(strings.TrimSpace(keyword))
+			if keyword != "" && strings.Contains(lowered, keyword) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -2,7 +2,13 @@ package services
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"math"
+	"regexp"
+	"slices"
 	"sort"
 	"strings"
 
@@ -10,34 +16,78 @@ import (
 	"backend/internal/config"
 	"backend/internal/models"
 	"backend/internal/repository"
+	"backend/internal/searchindex"
+	"backend/internal/utils"
 	"backend/internal/vectordb"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // SearchService handles semantic search and Q&A operations
 type SearchService struct {
-	notesRepo    *repository.NotesRepository
-	aiClient     ai.Client
-	qdrantClient *vectordb.QdrantClient
+	notesRepo           *repository.NotesRepository
+	chunksRepo          *repository.ChunksRepository
+	channelSettingsRepo *repository.ChannelSettingsRepository
+	settingsRepo        *repository.SettingsRepository
+	aiClient            ai.Client
+	qdrantClient        *vectordb.QdrantClient
+	keywordIndex        searchindex.Client // Optional external index; nil searches keywords in MongoDB
+	cfg                 *config.Config
 }
 
 // NewSearchService creates a new SearchService
 func NewSearchService(
 	notesRepo *repository.NotesRepository,
+	chunksRepo *repository.ChunksRepository,
+	channelSettingsRepo *repository.ChannelSettingsRepository,
+	settingsRepo *repository.SettingsRepository,
 	aiClient ai.Client,
 	qdrantClient *vectordb.QdrantClient,
+	keywordIndex searchindex.Client,
+	cfg *config.Config,
 ) *SearchService {
 	return &SearchService{
-		notesRepo:    notesRepo,
-		aiClient:     aiClient,
-		qdrantClient: qdrantClient,
+		notesRepo:           notesRepo,
+		chunksRepo:          chunksRepo,
+		channelSettingsRepo: channelSettingsRepo,
+		settingsRepo:        settingsRepo,
+		aiClient:            aiClient,
+		qdrantClient:        qdrantClient,
+		keywordIndex:        keywordIndex,
+		cfg:                 cfg,
 	}
 }
 
-// SemanticSearch performs a vector similarity search across notes
-func (s *SearchService) SemanticSearch(ctx context.Context, query string, limit int) ([]models.SearchResult, error) {
+// SearchThreshold returns the minimum vector similarity of search results: the override when
+// given, the configured MinSearchScore otherwise
+func (s *SearchService) SearchThreshold(override *float32) (float32, error) {
+	return threshold(override, s.cfg.MinSearchScore)
+}
+
+// AnswerThreshold returns the minimum vector similarity of notes used as Q&A context: the override
+// when given, the configured MinAnswerScore otherwise
+func (s *SearchService) AnswerThreshold(override *float32) (float32, error) {
+	return threshold(override, s.cfg.MinAnswerScore)
+}
+
+// threshold validates a per-request minimum score override
+func threshold(override *float32, configured float32) (float32, error) {
+	if override == nil {
+		return configured, nil
+	}
+	if *override < 0 || *override > 1 {
+		return 0, fmt.Errorf("invalid minScore: must be between 0 and 1")
+	}
+	return *override, nil
+}
+
+// SemanticSearch performs a vector similarity search across notes matching the filters
+// Scores include the configured ranking boosts, with boosts overriding them for this search.
+// Notes whose title nearly matches the query rank first.
+func (s *SearchService) SemanticSearch(ctx context.Context, query string, limit int, filters models.SearchFilters, boosts *models.RankingBoosts) ([]models.SearchResult, error) {
 	if limit <= 0 {
 		limit = 10
 	}
@@ -47,23 +97,138 @@ func (s *SearchService) SemanticSearch(ctx context.Context, query string, limit
 		return nil, fmt.Errorf("failed to generate embedding for query: %w", err)
 	}
 
-	searchResults, err := s.qdrantClient.Search(queryEmbedding, limit*2)
+	return s.semanticSearch(ctx, query, queryEmbedding, limit, filters, s.resolveBoosts(ctx, boosts), nil)
+}
+
+// SimilarToText finds the notes most related to a long piece of text, such as a draft being written
+// The text is split into chunks that are embedded separately and averaged, so every part of it
+// contributes rather than only what fits in a single embedding
+func (s *SearchService) SimilarToText(ctx context.Context, text string, limit int, filters models.SearchFilters) ([]models.SearchResult, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+
+	chunks := sampleChunks(utils.ChunkText(text, config.SIMILAR_TEXT_CHUNK_WORDS, 0), config.SIMILAR_TEXT_MAX_CHUNKS)
+	if len(chunks) == 0 {
+		return []models.SearchResult{}, nil
+	}
+
+	embeddings, err := s.aiClient.GenerateEmbeddings(chunks)
 	if err != nil {
-		return nil, fmt.Errorf("search failed: %w", err)
+		return nil, fmt.Errorf("failed to generate embeddings for text: %w", err)
 	}
 
-	noteScores := make(map[string]float32)
-	noteIDs := make(map[string]bool)
+	return s.searchByEmbedding(ctx, averageEmbeddings(embeddings), limit, filters, s.resolveBoosts(ctx, nil), nil)
+}
 
-	for _, result := range searchResults {
-		if existingScore, exists := noteScores[result.NoteID]; !exists || result.Score > existingScore {
-			noteScores[result.NoteID] = result.Score
+// RelatedNotes finds the other notes most similar to a note, best first, for "see also" links
+// The note's stored content embeddings are averaged, so nothing is embedded again; a note not yet
+// embedded has its content embedded as SimilarToText would. The note itself never matches.
+func (s *SearchService) RelatedNotes(ctx context.Context, noteID string, limit int) ([]models.SearchResult, error) {
+	objID, err := primitive.ObjectIDFromHex(noteID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid note ID: %w", err)
+	}
+	note, err := s.notesRepo.FindByID(ctx, objID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("note not found")
 		}
-		noteIDs[result.NoteID] = true
+		return nil, fmt.Errorf("failed to find note: %w", err)
+	}
+	if limit <= 0 {
+		limit = config.DEFAULT_RELATED_NOTES_LIMIT
+	}
+
+	points, err := s.qdrantClient.NotePoints(objID, config.RELATED_NOTE_MAX_VECTORS)
+	if err != nil {
+		return nil, err
+	}
+	var embeddings [][]float32
+	for _, point := range points {
+		// Summaries and structured data restate the content; matching on it alone keeps them from outweighing it
+		chunkType := point.Payload.ChunkType
+		if (chunkType == "" || chunkType == models.ChunkTypeContent) && len(point.Vector) > 0 {
+			embeddings = append(embeddings, point.Vector)
+		}
+	}
+	if len(embeddings) == 0 {
+		chunks := sampleChunks(utils.ChunkText(note.Content, config.SIMILAR_TEXT_CHUNK_WORDS, 0), config.SIMILAR_TEXT_MAX_CHUNKS)
+		if len(chunks) == 0 {
+			return []models.SearchResult{}, nil
+		}
+		if embeddings, err = s.aiClient.GenerateEmbeddings(chunks); err != nil {
+			return nil, fmt.Errorf("failed to generate embeddings for note: %w", err)
+		}
+	}
+
+	filters := models.SearchFilters{ExcludeNoteIDs: []string{note.ID.Hex()}}
+	return s.searchByEmbedding(ctx, averageEmbeddings(embeddings), limit, filters, s.resolveBoosts(ctx, nil), nil)
+}
+
+// sampleChunks keeps at most max chunks, spaced evenly through the text
+func sampleChunks(chunks []string, max int) []string {
+	if len(chunks) <= max {
+		return chunks
+	}
+
+	sampled := make([]string, 0, max)
+	for i := 0; i < max; i++ {
+		sampled = append(sampled, chunks[i*len(chunks)/max])
+	}
+	return sampled
+}
+
+// averageEmbeddings returns the unit-length mean of the given embeddings
+func averageEmbeddings(embeddings [][]float32) []float32 {
+	mean := make([]float32, len(embeddings[0]))
+	for _, embedding := range embeddings {
+		for i, v := range embedding {
+			mean[i] += v
+		}
+	}
+
+	var norm float64
+	for _, v := range mean {
+		norm += float64(v) * float64(v)
+	}
+	if norm == 0 {
+		return mean
+	}
+
+	scale := float32(1 / math.Sqrt(norm))
+	for i := range mean {
+		mean[i] *= scale
 	}
+	return mean
+}
+
+// searchByEmbedding finds the notes whose chunks are closest to an embedding, best first
+// Exclusions are pushed down to Qdrant as must_not conditions and re-checked against the notes,
+// which also covers points embedded before their payload carried category and channel.
+// The relevance threshold applies to raw similarity; boosts (nil for none) only reorder what passes it.
+// filters.ChunkTypes limits which embedded passages (content, summary, structured data) can match.
+// A non-nil trace records the score breakdown of each result and every candidate that was dropped.
+func (s *SearchService) searchByEmbedding(ctx context.Context, queryEmbedding []float32, limit int, filters models.SearchFilters, boosts *rankingBoosts, trace *searchTrace) ([]models.SearchResult, error) {
+	minScore, err := s.SearchThreshold(filters.MinScore)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateChunkTypes(filters.ChunkTypes); err != nil {
+		return nil, err
+	}
+
+	// Fetch extra chunks so long notes can contribute several matching passages
+	searchResults, err := s.qdrantClient.SearchFiltered(queryEmbedding, limit*config.MAX_CHUNK_MATCHES, vectorFilter(filters))
+	if err != nil {
+		return nil, fmt.Errorf("search failed: %w", err)
+	}
+	searchResults = filterChunkTypes(searchResults, filters.ChunkTypes)
+
+	noteScores, noteChunks := groupChunkResults(searchResults, minScore)
 
 	var objectIDs []primitive.ObjectID
-	for noteIDStr := range noteIDs {
+	for noteIDStr := range noteScores {
 		if objID, err := primitive.ObjectIDFromHex(noteIDStr); err == nil {
 			objectIDs = append(objectIDs, objID)
 		}
@@ -73,30 +238,269 @@ func (s *SearchService) SemanticSearch(ctx context.Context, query string, limit
 		return []models.SearchResult{}, nil
 	}
 
-	notes, err := s.notesRepo.FindAll(ctx, bson.M{"_id": bson.M{"$in": objectIDs}})
+	filter := notesFilter(filters)
+	filter["_id"] = bson.M{"$in": objectIDs}
+	notes, err := s.notesRepo.FindAll(ctx, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch notes: %w", err)
 	}
+	if trace != nil {
+		trace.dropUnmatched(objectIDs, notes, noteScores, minScore)
+	}
+	notes = dropExcludedChannels(notes, filters.ExcludeChannels, func(note models.Note) {
+		trace.drop(note, vectorExplanation(noteScores[note.ID.Hex()], minScore), "excluded channel")
+	})
+
+	results := rankNotes(notes, noteScores, minScore, limit, boosts, trace)
+	s.attachChunkMatches(ctx, results, noteChunks)
+
+	return results, nil
+}
+
+// groupChunkResults collects each note's best chunk score and its chunks above the relevance threshold
+func groupChunkResults(searchResults []vectordb.VectorSearchResult, minScore float32) (map[string]float32, map[string][]vectordb.VectorSearchResult) {
+	noteScores := make(map[string]float32)
+	noteChunks := make(map[string][]vectordb.VectorSearchResult)
+
+	for _, result := range searchResults {
+		if existingScore, exists := noteScores[result.NoteID]; !exists || result.Score > existingScore {
+			noteScores[result.NoteID] = result.Score
+		}
+		if result.Score >= minScore {
+			noteChunks[result.NoteID] = append(noteChunks[result.NoteID], result)
+		}
+	}
+	return noteScores, noteChunks
+}
 
+// rankNotes turns the fetched notes into results above the relevance threshold, boosted, sorted
+// best first and cut to the limit
+func rankNotes(notes []models.Note, noteScores map[string]float32, minScore float32, limit int, boosts *rankingBoosts, trace *searchTrace) []models.SearchResult {
 	var results []models.SearchResult
 	for _, note := range notes {
 		score := noteScores[note.ID.Hex()]
 
 		// Only include results above the minimum relevance threshold
-		if score >= config.MIN_RELEVANCE_SCORE {
-			results = append(results, models.SearchResult{
-				Note:  note,
-				Score: score,
-			})
+		if score < minScore {
+			trace.drop(note, vectorExplanation(score, minScore), belowThreshold(minScore))
+			continue
+		}
+		result := models.SearchResult{
+			Note:  note,
+			Score: score,
 		}
+		if trace != nil {
+			result.Explain = vectorExplanation(score, minScore)
+		}
+		results = append(results, result)
 	}
 
+	boosts.apply(results)
+
 	// Sort results by score in descending order (highest relevance first)
 	sort.Slice(results, func(i, j int) bool {
 		return results[i].Score > results[j].Score
 	})
 
 	// Apply limit after filtering and sorting
+	if len(results) > limit {
+		trace.dropResults(results[limit:], "beyond result limit")
+		results = results[:limit]
+	}
+	trace.finalize(results)
+
+	return results
+}
+
+// attachChunkMatches fills in the best matching passages for each result
+// Qdrant returns chunks highest score first, so each note's list is already ordered.
+// Snippets are best-effort: a failed chunk lookup leaves results without matches.
+func (s *SearchService) attachChunkMatches(ctx context.Context, results []models.SearchResult, noteChunks map[string][]vectordb.VectorSearchResult) {
+	var chunkIDs []primitive.ObjectID
+	for i := range results {
+		chunks := noteChunks[results[i].Note.ID.Hex()]
+		if len(chunks) > config.MAX_CHUNK_MATCHES {
+			chunks = chunks[:config.MAX_CHUNK_MATCHES]
+		}
+		for _, chunk := range chunks {
+			if objID, err := primitive.ObjectIDFromHex(chunk.ChunkID); err == nil {
+				chunkIDs = append(chunkIDs, objID)
+			}
+		}
+	}
+
+	if len(chunkIDs) == 0 {
+		return
+	}
+
+	chunks, err := s.chunksRepo.FindByIDs(ctx, chunkIDs)
+	if err != nil {
+		log.Printf("Failed to load matching chunks: %v", err)
+		return
+	}
+
+	chunksByID := make(map[string]models.NoteChunk, len(chunks))
+	for _, chunk := range chunks {
+		chunksByID[chunk.ID.Hex()] = chunk
+	}
+
+	for i := range results {
+		for _, match := range noteChunks[results[i].Note.ID.Hex()] {
+			if len(results[i].Matches) == config.MAX_CHUNK_MATCHES {
+				break
+			}
+			chunk, ok := chunksByID[match.ChunkID]
+			if !ok {
+				continue
+			}
+			results[i].Matches = append(results[i].Matches, models.ChunkMatch{
+				ChunkID:  chunk.ID,
+				ChunkIdx: chunk.ChunkIdx,
+				Page:     chunk.Page,
+				Type:     chunkType(match.ChunkType),
+				Snippet:  utils.TruncateWords(chunk.Content, config.SNIPPET_LENGTH),
+				Score:    match.Score,
+			})
+		}
+	}
+}
+
+// HybridSearch combines MongoDB full-text matches with vector results using reciprocal rank fusion,
+// so exact terms (product names, error codes) rank well even when embeddings blur them.
+// As in SemanticSearch, notes whose title nearly matches the query rank first.
+func (s *SearchService) HybridSearch(ctx context.Context, query string, limit int, filters models.SearchFilters, boosts *models.RankingBoosts) ([]models.SearchResult, error) {
+	return s.hybridSearch(ctx, query, limit, filters, boosts, nil)
+}
+
+// hybridSearch implements HybridSearch, recording the ranking breakdown in trace when non-nil
+func (s *SearchService) hybridSearch(ctx context.Context, query string, limit int, filters models.SearchFilters, boosts *models.RankingBoosts, trace *searchTrace) ([]models.SearchResult, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+	minScore, err := s.SearchThreshold(filters.MinScore)
+	if err != nil {
+		return nil, err
+	}
+
+	queryEmbedding, err := s.aiClient.GenerateEmbedding(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate embedding for query: %w", err)
+	}
+
+	// Boosts are applied once to the fused scores rather than to the semantic ranking
+	semanticResults, err := s.searchByEmbedding(ctx, queryEmbedding, limit*2, filters, nil, trace)
+	if err != nil {
+		return nil, err
+	}
+
+	keywordNotes, err := s.notesRepo.TextSearch(ctx, keywordQuery(query, filters.ExcludeTerms), notesFilter(filters), limit*2)
+	if err != nil {
+		return nil, fmt.Errorf("keyword search failed: %w", err)
+	}
+	keywordNotes = dropExcludedChannels(keywordNotes, filters.ExcludeChannels, func(note models.Note) {
+		trace.drop(note, &models.RankingExplanation{BoostMultiplier: 1}, "excluded channel")
+	})
+
+	fused := make(map[primitive.ObjectID]float64)
+	notesByID := make(map[primitive.ObjectID]models.Note)
+	matchesByID := make(map[primitive.ObjectID][]models.ChunkMatch)
+	explainByID := make(map[primitive.ObjectID]*models.RankingExplanation)
+
+	for rank, result := range semanticResults {
+		fused[result.Note.ID] += reciprocalRank(rank)
+		notesByID[result.Note.ID] = result.Note
+		matchesByID[result.Note.ID] = result.Matches
+		if trace != nil {
+			result.Explain.VectorRank = intPtr(rank + 1)
+			explainByID[result.Note.ID] = result.Explain
+		}
+	}
+	for rank, note := range keywordNotes {
+		fused[note.ID] += reciprocalRank(rank)
+		notesByID[note.ID] = note
+		if trace != nil {
+			if explainByID[note.ID] == nil {
+				explainByID[note.ID] = &models.RankingExplanation{Threshold: minScore}
+			}
+			explainByID[note.ID].KeywordRank = intPtr(rank + 1)
+		}
+	}
+
+	// Normalize so a note ranked first in both lists scores 1.0
+	maxScore := 2 * reciprocalRank(0)
+
+	results := make([]models.SearchResult, 0, len(fused))
+	for id, score := range fused {
+		result := models.SearchResult{
+			Note:    notesByID[id],
+			Score:   float32(score / maxScore),
+			Matches: matchesByID[id],
+		}
+		if trace != nil {
+			fusedScore := score / maxScore
+			result.Explain = explainByID[id]
+			result.Explain.FusedScore = &fusedScore
+			result.Explain.BoostMultiplier = 1
+		}
+		results = append(results, result)
+	}
+
+	s.resolveBoosts(ctx, boosts).apply(results)
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	if trace != nil {
+		// Candidates cut from the semantic list may still have been fused in via keyword search
+		trace.forget(fused)
+	}
+	if len(results) > limit {
+		trace.dropResults(results[limit:], "beyond result limit")
+		results = results[:limit]
+	}
+	trace.finalize(results)
+
+	return s.promoteTitleMatches(ctx, query, queryEmbedding, results, limit, filters, trace)
+}
+
+// KeywordSearch finds notes containing the query terms, with no embedding involved
+// Searches go to the external search index when keyword routing is enabled, falling back to the
+// MongoDB text index if it fails. Scores come from rank, 1.0 for the top match, before boosts.
+func (s *SearchService) KeywordSearch(ctx context.Context, query string, limit int, filters models.SearchFilters, boosts *models.RankingBoosts) ([]models.SearchResult, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+
+	var notes []models.Note
+	var err error
+	if s.keywordIndex != nil && s.cfg.SearchIndexKeywordRouting {
+		notes, err = s.indexKeywordSearch(ctx, query, limit*2, filters)
+		if err != nil {
+			log.Printf("Search index query failed, falling back to MongoDB: %v", err)
+		}
+	}
+	if notes == nil {
+		notes, err = s.notesRepo.TextSearch(ctx, keywordQuery(query, filters.ExcludeTerms), notesFilter(filters), limit*2)
+		if err != nil {
+			return nil, fmt.Errorf("keyword search failed: %w", err)
+		}
+	}
+	notes = dropExcludedChannels(notes, filters.ExcludeChannels, func(models.Note) {})
+
+	results := make([]models.SearchResult, 0, len(notes))
+	for rank, note := range notes {
+		results = append(results, models.SearchResult{
+			Note:  note,
+			Score: float32(reciprocalRank(rank) / reciprocalRank(0)),
+		})
+	}
+
+	s.resolveBoosts(ctx, boosts).apply(results)
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
 	if len(results) > limit {
 		results = results[:limit]
 	}
@@ -104,60 +508,694 @@ func (s *SearchService) SemanticSearch(ctx context.Context, query string, limit
 	return results, nil
 }
 
+// indexKeywordSearch queries the external search index and loads the matching notes in its order
+// Notes are re-checked against the full filters, so exclusions the index can't express still apply
+func (s *SearchService) indexKeywordSearch(ctx context.Context, query string, limit int, filters models.SearchFilters) ([]models.Note, error) {
+	hits, err := s.keywordIndex.Search(ctx, searchindex.Query{
+		Text:              query,
+		Limit:             limit,
+		Tags:              normalizeTags(filters.Tags),
+		ExcludeCategories: filters.ExcludeCategories,
+		IncludeArchived:   filters.IncludeArchived,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(hits))
+	for _, hit := range hits {
+		if id, err := primitive.ObjectIDFromHex(hit); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return []models.Note{}, nil
+	}
+
+	filter := notesFilter(filters)
+	filter["_id"] = bson.M{"$in": ids}
+	found, err := s.notesRepo.FindAll(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load notes: %w", err)
+	}
+
+	byID := make(map[primitive.ObjectID]models.Note, len(found))
+	for _, note := range found {
+		byID[note.ID] = note
+	}
+	notes := make([]models.Note, 0, len(found))
+	for _, id := range ids {
+		if note, ok := byID[id]; ok {
+			notes = append(notes, note)
+		}
+	}
+	return notes, nil
+}
+
+// notesFilter builds a notes filter requiring every given tag and scope and dropping archived notes
+// (unless requested) and excluded categories and terms
+// Channel exclusions use normalized names and are applied by dropExcludedChannels instead
+func notesFilter(filters models.SearchFilters) bson.M {
+	filter := bson.M{}
+	if !filters.IncludeArchived {
+		filter["archived"] = bson.M{"$ne": true}
+	}
+	if tags := normalizeTags(filters.Tags); len(tags) > 0 {
+		filter["tags"] = bson.M{"$all": tags}
+	}
+	if len(filters.ExcludeCategories) > 0 || len(filters.Categories) > 0 {
+		category := bson.M{}
+		if len(filters.ExcludeCategories) > 0 {
+			category["$nin"] = filters.ExcludeCategories
+		}
+		if len(filters.Categories) > 0 {
+			category["$in"] = filters.Categories
+		}
+		filter["category"] = category
+	}
+	if len(filters.Authors) > 0 {
+		filter["metadata.author"] = bson.M{"$in": filters.Authors}
+	}
+	if platforms := canonicalPlatforms(filters.Platforms); len(platforms) > 0 {
+		filter["metadata.platform"] = bson.M{"$in": platforms}
+	}
+	if filters.CreatedAfter != nil || filters.CreatedBefore != nil {
+		created := bson.M{}
+		if filters.CreatedAfter != nil {
+			created["$gte"] = *filters.CreatedAfter
+		}
+		if filters.CreatedBefore != nil {
+			created["$lt"] = *filters.CreatedBefore
+		}
+		filter["created"] = created
+	}
+
+	var excluded []bson.M
+	for _, term := range filters.ExcludeTerms {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		pattern := bson.M{"$regex": `\b` + regexp.QuoteMeta(term) + `\b`, "$options": "i"}
+		excluded = append(excluded, bson.M{"title": pattern}, bson.M{"summary": pattern}, bson.M{"content": pattern})
+	}
+	if len(excluded) > 0 {
+		filter["$nor"] = excluded
+	}
+
+	return filter
+}
+
+// vectorFilter translates search scopes and exclusions into Qdrant payload conditions, so Qdrant
+// returns only chunks that can pass notesFilter rather than the results being filtered afterwards
+// Chunk types outside filters.ChunkTypes are excluded rather than required, since content points
+// stored before chunk types existed have none. An empty filter searches every point.
+func vectorFilter(filters models.SearchFilters) *vectordb.SearchFilter {
+	var excludeTypes []string
+	if len(filters.ChunkTypes) > 0 {
+		for _, chunkType := range models.ChunkTypes {
+			if !slices.Contains(filters.ChunkTypes, chunkType) {
+				excludeTypes = append(excludeTypes, chunkType)
+			}
+		}
+	}
+	filter := vectordb.SearchFilter{
+		ExcludeCategories:  filters.ExcludeCategories,
+		ExcludeChannelKeys: utils.NormalizeChannelKeys(filters.ExcludeChannels),
+		ExcludeChunkTypes:  excludeTypes,
+		ExcludeNoteIDs:     filters.ExcludeNoteIDs,
+		Categories:         filters.Categories,
+		Authors:            filters.Authors,
+		Platforms:          canonicalPlatforms(filters.Platforms),
+	}
+	if filters.CreatedAfter != nil {
+		filter.CreatedAfter = *filters.CreatedAfter
+	}
+	if filters.CreatedBefore != nil {
+		filter.CreatedBefore = *filters.CreatedBefore
+	}
+	return &filter
+}
+
+// canonicalPlatforms maps platform names and aliases to the canonical names notes are saved with
+// Platforms missing from the registry are kept, lowercased; no note can match them.
+func canonicalPlatforms(platforms []string) []string {
+	var canonical []string
+	for _, platform := range platforms {
+		if registered, ok := config.LookupPlatform(platform); ok {
+			canonical = append(canonical, registered.Name)
+		} else if platform = strings.ToLower(strings.TrimSpace(platform)); platform != "" {
+			canonical = append(canonical, platform)
+		}
+	}
+	return canonical
+}
+
+// ValidateChunkTypes rejects chunk types that aren't one of models.ChunkTypes
+func ValidateChunkTypes(chunkTypes []string) error {
+	for _, chunkType := range chunkTypes {
+		if !slices.Contains(models.ChunkTypes, chunkType) {
+			return fmt.Errorf("invalid chunkTypes: %q is not one of %s", chunkType, strings.Join(models.ChunkTypes, ", "))
+		}
+	}
+	return nil
+}
+
+// filterChunkTypes keeps the results whose chunk type is allowed; every type is allowed when none are given
+// Points embedded before chunk types existed have no type in their payload and count as content,
+// which the must_not condition in Qdrant can't exclude.
+func filterChunkTypes(results []vectordb.VectorSearchResult, chunkTypes []string) []vectordb.VectorSearchResult {
+	if len(chunkTypes) == 0 {
+		return results
+	}
+	filtered := results[:0]
+	for _, result := range results {
+		if slices.Contains(chunkTypes, chunkType(result.ChunkType)) {
+			filtered = append(filtered, result)
+		}
+	}
+	return filtered
+}
+
+// chunkType returns the type of a stored chunk, treating an untyped one as content
+func chunkType(stored string) string {
+	if stored == "" {
+		return models.ChunkTypeContent
+	}
+	return stored
+}
+
+// dropExcludedChannels removes notes whose author matches an excluded channel name,
+// passing each removed note to onDrop
+func dropExcludedChannels(notes []models.Note, channels []string, onDrop func(models.Note)) []models.Note {
+	if len(channels) == 0 {
+		return notes
+	}
+
+	excluded := make(map[string]bool)
+	for _, key := range utils.NormalizeChannelKeys(channels) {
+		excluded[key] = true
+	}
+
+	kept := notes[:0]
+	for _, note := range notes {
+		author, _ := note.Metadata["author"].(string)
+		if author != "" && excluded[utils.NormalizeChannelKey(author)] {
+			onDrop(note)
+			continue
+		}
+		kept = append(kept, note)
+	}
+	return kept
+}
+
+// noteChannelKey returns the normalized channel of a note's author; empty for notes without one
+func noteChannelKey(note *models.Note) string {
+	author, _ := note.Metadata["author"].(string)
+	if author == "" {
+		return ""
+	}
+	return utils.NormalizeChannelKey(author)
+}
+
+// keywordQuery appends MongoDB text-search negations for excluded terms
+func keywordQuery(query string, excludeTerms []string) string {
+	var b strings.Builder
+	b.WriteString(query)
+	for _, term := range excludeTerms {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+		if strings.ContainsAny(term, " \t") {
+			b.WriteString(` -"` + strings.ReplaceAll(term, `"`, "") + `"`)
+		} else {
+			b.WriteString(" -" + term)
+		}
+	}
+	return b.String()
+}
+
+// reciprocalRank returns the RRF contribution of a zero-based rank
+func reciprocalRank(rank int) float64 {
+	return 1.0 / float64(config.RRF_K+rank+1)
+}
+
 // AnswerQuestion answers a question using relevant notes as context
-func (s *SearchService) AnswerQuestion(ctx context.Context, question string) (*models.QuestionResponse, error) {
-	// Step 1: Search for relevant notes using semantic search
-	queryEmbedding, err := s.aiClient.GenerateEmbedding(question)
+// minScore (nil for the configured value) is the minimum similarity of context notes. With debug set,
+// sources carry their retrieval scores and dropped candidates are returned as Filtered. format lays out
+// the answer (see ValidateAnswerFormat); json answers follow schema and are also returned as Data.
+// multiHop runs follow-up searches for chained questions (see retrieveMultiHopContext)
+func (s *SearchService) AnswerQuestion(ctx context.Context, question string, minScore *float32, debug bool, format string, schema map[string]interface{}, multiHop bool) (*models.QuestionResponse, error) {
+	if err := ValidateAnswerFormat(format, schema); err != nil {
+		return nil, err
+	}
+
+	var trace *searchTrace
+	if debug {
+		trace = &searchTrace{}
+	}
+	return s.answer(ctx, question, nil, minScore, trace, format, schema, multiHop)
+}
+
+// ValidateAnswerFormat checks a requested answer format; a schema is only allowed for json answers
+func ValidateAnswerFormat(format string, schema map[string]interface{}) error {
+	switch format {
+	case "", models.AnswerFormatText, models.AnswerFormatBullets, models.AnswerFormatTable, models.AnswerFormatJSON:
+	default:
+		return fmt.Errorf("invalid responseFormat: must be text, bullets, table or json")
+	}
+	if schema != nil && format != models.AnswerFormatJSON {
+		return fmt.Errorf("invalid schema: only allowed with responseFormat json")
+	}
+	return nil
+}
+
+// AnswerWithHistory answers a follow-up question within a conversation
+// Recent user turns are folded into the retrieval query so references like "that book" still find
+// the right notes, and the history itself is passed to the model
+func (s *SearchService) AnswerWithHistory(ctx context.Context, question string, history []models.ConversationMessage, minScore *float32) (*models.QuestionResponse, error) {
+	return s.answer(ctx, question, history, minScore, nil, "", nil, false)
+}
+
+// answer retrieves context for a question and generates an answer in format, recording retrieval in trace when non-nil
+func (s *SearchService) answer(ctx context.Context, question string, history []models.ConversationMessage, minScoreOverride *float32, trace *searchTrace, format string, schema map[string]interface{}, multiHop bool) (*models.QuestionResponse, error) {
+	minScore, err := s.AnswerThreshold(minScoreOverride)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate embedding for question: %w", err)
+		return nil, err
 	}
 
-	searchResults, err := s.qdrantClient.Search(queryEmbedding, 5) // Get top 5 most relevant notes
+	query := retrievalQuery(question, history)
+	relevantNotes, contextText, hops, err := s.retrieveContext(ctx, question, query, minScore, multiHop, trace)
+	degraded := errors.Is(err, vectordb.ErrUnavailable)
+	if degraded {
+		log.Printf("Vector search unavailable, answering from keyword matches: %v", err)
+		relevantNotes, contextText, err = s.retrieveKeywordContext(ctx, query)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("search failed: %w", err)
+		return nil, err
 	}
 
-	// Step 2: Get relevant notes and prepare context
-	var relevantNotes []models.SearchResult
-	var contextText strings.Builder
-	noteIDs := make(map[string]bool)
+	if len(relevantNotes) == 0 {
+		response := noRelevantNotesResponse(question, minScore)
+		response.Filtered = trace.filteredResults()
+		response.Degraded = degraded
+		if format == models.AnswerFormatJSON {
+			response.Data = map[string]interface{}{"answer": response.Answer}
+		}
+		return response, nil
+	}
+
+	// Step 3: Generate answer using relevant context
+	persona := s.answerPersona(ctx, format, multiHop)
+	var answer string
+	var data map[string]interface{}
+	if format == models.AnswerFormatJSON {
+		var schemaText []byte
+		if schema != nil {
+			schemaText, err = json.Marshal(schema)
+			if err != nil {
+				return nil, fmt.Errorf("invalid schema: %w", err)
+			}
+		}
+		answer, data, err = s.aiClient.GenerateStructuredAnswer(question, contextText, persona, string(schemaText))
+	} else {
+		answer, err = s.aiClient.GenerateAnswer(question, contextText, history, persona)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate answer: %w", err)
+	}
+
+	return &models.QuestionResponse{
+		Answer:   answer,
+		Sources:  relevantNotes,
+		Question: question,
+		MinScore: minScore,
+		Filtered: trace.filteredResults(),
+		Data:     data,
+		Hops:     hops,
+		Degraded: degraded,
+	}, nil
+}
+
+// AnswerQuestionStream answers a question like AnswerQuestion, passing answer text to onChunk
+// as it is generated. The returned response carries the full answer and its sources.
+// json answers can't be streamed; the markdown formats can.
+func (s *SearchService) AnswerQuestionStream(ctx context.Context, question string, minScoreOverride *float32, format string, multiHop bool, onChunk func(string) error) (*models.QuestionResponse, error) {
+	if err := ValidateStreamFormat(format); err != nil {
+		return nil, err
+	}
+
+	minScore, err := s.AnswerThreshold(minScoreOverride)
+	if err != nil {
+		return nil, err
+	}
+
+	relevantNotes, contextText, hops, err := s.retrieveContext(ctx, question, question, minScore, multiHop, nil)
+	degraded := errors.Is(err, vectordb.ErrUnavailable)
+	if degraded {
+		log.Printf("Vector search unavailable, answering from keyword matches: %v", err)
+		relevantNotes, contextText, err = s.retrieveKeywordContext(ctx, question)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(relevantNotes) == 0 {
+		response := noRelevantNotesResponse(question, minScore)
+		response.Degraded = degraded
+		if err := onChunk(response.Answer); err != nil {
+			return nil, err
+		}
+		return response, nil
+	}
+
+	answer, err := s.aiClient.GenerateAnswerStream(ctx, question, contextText, s.answerPersona(ctx, format, multiHop), onChunk)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate answer: %w", err)
+	}
+
+	return &models.QuestionResponse{
+		Answer:   answer,
+		Sources:  relevantNotes,
+		Question: question,
+		MinScore: minScore,
+		Hops:     hops,
+		Degraded: degraded,
+	}, nil
+}
+
+// ValidateStreamFormat checks an answer format for a streamed answer
+func ValidateStreamFormat(format string) error {
+	if format == models.AnswerFormatJSON {
+		return fmt.Errorf("invalid responseFormat: json answers can't be streamed")
+	}
+	return ValidateAnswerFormat(format, nil)
+}
 
+// persona loads the configured assistant persona; nil (the default persona) when unset or unavailable
+func (s *SearchService) persona(ctx context.Context) *models.AssistantPersona {
+	if s.settingsRepo == nil {
+		return nil
+	}
+	persona, err := s.settingsRepo.GetPersona(ctx)
+	if err != nil {
+		log.Printf("Failed to load assistant persona, using the default: %v", err)
+		return nil
+	}
+	return persona
+}
+
+// answerPersona is the configured persona with the rules for format and, for multi-hop answers, citing each hop added
+func (s *SearchService) answerPersona(ctx context.Context, format string, multiHop bool) *models.AssistantPersona {
+	persona := ai.WithAnswerFormat(s.persona(ctx), format)
+	if multiHop {
+		persona = ai.WithMultiHopCitations(persona)
+	}
+	return persona
+}
+
+// retrieveContext retrieves the context for answering question from query, the searches made when multiHop
+func (s *SearchService) retrieveContext(ctx context.Context, question, query string, minScore float32, multiHop bool, trace *searchTrace) ([]models.SearchResult, string, []models.RetrievalHop, error) {
+	if multiHop {
+		return s.retrieveMultiHopContext(ctx, question, query, minScore, trace)
+	}
+	relevantNotes, contextText, err := s.retrieveAnswerContext(ctx, query, minScore, trace)
+	return relevantNotes, contextText, nil, err
+}
+
+// retrieveAnswerContext finds the notes scoring at least minScore for a query and formats them as
+// prompt context. A non-nil trace records each source's score and the candidates that were not used.
+func (s *SearchService) retrieveAnswerContext(ctx context.Context, query string, minScore float32, trace *searchTrace) ([]models.SearchResult, string, error) {
+	sources := newAnswerSources()
+	if err := s.searchAnswerSources(ctx, sources, query, minScore, config.ANSWER_SOURCES, trace); err != nil {
+		return nil, "", err
+	}
+
+	relevantNotes := append(s.pinnedContext(ctx, sources.results, trace), sources.results...)
+	return relevantNotes, answerContext(relevantNotes), nil
+}
+
+// retrieveKeywordContext retrieves context from the notes matching query's terms, for answering
+// while Qdrant is unavailable. Sources carry keyword rank scores rather than similarity.
+func (s *SearchService) retrieveKeywordContext(ctx context.Context, query string) ([]models.SearchResult, string, error) {
+	results, err := s.KeywordSearch(ctx, query, config.ANSWER_SOURCES, models.SearchFilters{}, nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	relevantNotes := append(s.pinnedContext(ctx, results, nil), results...)
+	return relevantNotes, answerContext(relevantNotes), nil
+}
+
+// retrieveMultiHopContext retrieves context like retrieveAnswerContext, then for up to MULTI_HOP_MAX_HOPS
+// rounds lets the model propose follow-up searches for facts the notes found so far point to but don't
+// contain, e.g. what the author named in one note wrote elsewhere. Returns the searches made along with
+// the notes each one added; the context groups notes under the search that found them.
+// Follow-up searches are best-effort: if proposing them fails the answer uses the notes found so far.
+func (s *SearchService) retrieveMultiHopContext(ctx context.Context, question, query string, minScore float32, trace *searchTrace) ([]models.SearchResult, string, []models.RetrievalHop, error) {
+	sources := newAnswerSources()
+	hop, err := s.searchHop(ctx, sources, 1, query, minScore, config.ANSWER_SOURCES, trace)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	hops := []models.RetrievalHop{hop}
+	searched := []string{query}
+
+	for round := 2; round <= config.MULTI_HOP_MAX_HOPS; round++ {
+		if len(sources.results) == 0 || len(sources.results) >= config.MULTI_HOP_MAX_SOURCES {
+			break
+		}
+		queries, err := s.aiClient.ProposeFollowUpQueries(question, hopContext(sources.results, hops), searched)
+		if err != nil {
+			log.Printf("Failed to propose follow-up searches for %q, answering from the notes found so far: %v", question, err)
+			break
+		}
+		if len(queries) > config.MULTI_HOP_QUERIES {
+			queries = queries[:config.MULTI_HOP_QUERIES]
+		}
+
+		found := false
+		for _, followUp := range queries {
+			limit := min(len(sources.results)+config.MULTI_HOP_QUERY_SOURCES, config.MULTI_HOP_MAX_SOURCES)
+			hop, err := s.searchHop(ctx, sources, round, followUp, minScore, limit, trace)
+			if err != nil {
+				return nil, "", nil, err
+			}
+			hops = append(hops, hop)
+			searched = append(searched, followUp)
+			found = found || len(hop.NoteIDs) > 0
+		}
+		if !found {
+			break
+		}
+	}
+
+	// A note below the threshold for one search may have been used by a later one
+	used := make(map[primitive.ObjectID]float64, len(sources.results))
+	for _, source := range sources.results {
+		used[source.Note.ID] = 0
+	}
+	trace.forget(used)
+
+	pinned := s.pinnedContext(ctx, sources.results, trace)
+	relevantNotes := append(pinned, sources.results...)
+	return relevantNotes, answerContext(pinned) + hopContext(sources.results, hops), hops, nil
+}
+
+// searchHop runs one search of a multi-hop retrieval, recording the notes it adds to sources
+func (s *SearchService) searchHop(ctx context.Context, sources *answerSources, hop int, query string, minScore float32, limit int, trace *searchTrace) (models.RetrievalHop, error) {
+	before := len(sources.results)
+	if err := s.searchAnswerSources(ctx, sources, query, minScore, limit, trace); err != nil {
+		return models.RetrievalHop{}, err
+	}
+
+	noteIDs := make([]string, 0, len(sources.results)-before)
+	for _, source := range sources.results[before:] {
+		noteIDs = append(noteIDs, source.Note.ID.Hex())
+	}
+	return models.RetrievalHop{Hop: hop, Query: query, NoteIDs: noteIDs}, nil
+}
+
+// answerSources accumulates the notes used as Q&A context over one or more searches
+type answerSources struct {
+	results  []models.SearchResult
+	used     map[string]bool // Notes already used, or ruled out by the channel cap
+	traced   map[string]bool // Notes recorded as below the threshold; a later search may still use them
+	channels map[string]int  // Notes used per channel
+}
+
+// newAnswerSources creates an empty answerSources
+func newAnswerSources() *answerSources {
+	return &answerSources{
+		used:     make(map[string]bool),
+		traced:   make(map[string]bool),
+		channels: make(map[string]int),
+	}
+}
+
+// searchAnswerSources adds the notes scoring at least minScore for query to sources until they hold limit notes
+func (s *SearchService) searchAnswerSources(ctx context.Context, sources *answerSources, query string, minScore float32, limit int, trace *searchTrace) error {
+	// Step 1: Search for relevant notes using semantic search
+	queryEmbedding, err := s.aiClient.GenerateEmbedding(query)
+	if err != nil {
+		return fmt.Errorf("failed to generate embedding for question: %w", err)
+	}
+
+	searchResults, err := s.qdrantClient.Search(queryEmbedding, config.ANSWER_CANDIDATE_CHUNKS)
+	if err != nil {
+		return fmt.Errorf("search failed: %w", err)
+	}
+
+	// Step 2: Get relevant notes
+	// Each note is used once, in full, and at most MaxSourcesPerChannel notes come from one channel,
+	// so a single prolific note or channel can't fill the context on its own
+	considered := make(map[string]bool)
 	for _, result := range searchResults {
+		if len(sources.results) >= limit {
+			break
+		}
+		if sources.used[result.NoteID] || considered[result.NoteID] {
+			continue
+		}
+
 		// Only include highly relevant notes (higher threshold for Q&A)
-		if result.Score >= 0.4 && !noteIDs[result.NoteID] {
+		if result.Score >= minScore {
 			if objID, err := primitive.ObjectIDFromHex(result.NoteID); err == nil {
 				note, err := s.notesRepo.FindByID(ctx, objID)
 				if err == nil {
-					relevantNotes = append(relevantNotes, models.SearchResult{
+					sources.used[result.NoteID] = true
+					channel := noteChannelKey(note)
+					if channel != "" && s.cfg.MaxSourcesPerChannel > 0 && sources.channels[channel] >= s.cfg.MaxSourcesPerChannel {
+						trace.drop(*note, vectorExplanation(result.Score, minScore), "channel already has the maximum number of sources")
+						continue
+					}
+					sources.channels[channel]++
+
+					source := models.SearchResult{
 						Note:  *note,
 						Score: result.Score,
-					})
-
-					// Add to context with clear delineation
-					contextText.WriteString(fmt.Sprintf("Title: %s\nContent: %s\n\n", note.Title, note.Content))
-					noteIDs[result.NoteID] = true
+					}
+					if trace != nil {
+						source.Explain = vectorExplanation(result.Score, minScore)
+					}
+					sources.results = append(sources.results, source)
+				}
+			}
+		} else if trace != nil {
+			// Results arrive best first, so the first low-scoring chunk is the note's best
+			considered[result.NoteID] = true
+			if sources.traced[result.NoteID] {
+				continue
+			}
+			sources.traced[result.NoteID] = true
+			note := models.Note{}
+			if objID, err := primitive.ObjectIDFromHex(result.NoteID); err == nil {
+				if found, err := s.notesRepo.FindByID(ctx, objID); err == nil {
+					note = *found
+				} else {
+					note.ID = objID
 				}
 			}
+			trace.drop(note, vectorExplanation(result.Score, minScore), belowThreshold(minScore))
 		}
 	}
+	return nil
+}
 
-	if len(relevantNotes) == 0 {
-		return &models.QuestionResponse{
-			Answer:   "I couldn't find any relevant information in your notes to answer that question.",
-			Sources:  []models.SearchResult{},
-			Question: question,
-		}, nil
+// pinnedContext loads the always-in-context notes that aren't among the retrieved sources, oldest
+// first, skipping any that would take them past MAX_PINNED_CONTEXT_CHARS
+// Pinned context is best-effort: a failed lookup answers from the retrieved notes alone.
+func (s *SearchService) pinnedContext(ctx context.Context, sources []models.SearchResult, trace *searchTrace) []models.SearchResult {
+	opts := options.Find().SetSort(bson.M{"_id": 1})
+	notes, err := s.notesRepo.FindAll(ctx, bson.M{"always_in_context": true, "archived": bson.M{"$ne": true}}, opts)
+	if err != nil {
+		log.Printf("Failed to load always-in-context notes: %v", err)
+		return nil
 	}
 
-	// Step 3: Generate answer using relevant context
-	answer, err := s.aiClient.GenerateAnswer(question, contextText.String())
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate answer: %w", err)
+	retrieved := make(map[primitive.ObjectID]bool, len(sources))
+	for _, source := range sources {
+		retrieved[source.Note.ID] = true
 	}
 
+	var pinned []models.SearchResult
+	kept := make(map[primitive.ObjectID]float64)
+	budget := config.MAX_PINNED_CONTEXT_CHARS
+	for _, note := range notes {
+		if retrieved[note.ID] {
+			continue
+		}
+		size := len(note.Title) + len(note.Content)
+		if size > budget {
+			trace.drop(note, &models.RankingExplanation{BoostMultiplier: 1}, "over pinned context budget")
+			continue
+		}
+		budget -= size
+
+		source := models.SearchResult{Note: note}
+		if trace != nil {
+			source.Explain = &models.RankingExplanation{BoostMultiplier: 1}
+		}
+		pinned = append(pinned, source)
+		kept[note.ID] = 0
+	}
+
+	// Pinned notes are used even when their similarity fell below the threshold
+	trace.forget(kept)
+	return pinned
+}
+
+// answerContext formats the source notes into the context the answer is generated from
+func answerContext(sources []models.SearchResult) string {
+	var contextText strings.Builder
+	for _, source := range sources {
+		// Each note is clearly delineated
+		fmt.Fprintf(&contextText, "Title: %s\nContent: %s\n\n", source.Note.Title, source.Note.Content)
+	}
+	return contextText.String()
+}
+
+// hopContext formats multi-hop sources under the search that found them, so the answer can cite each step
+func hopContext(sources []models.SearchResult, hops []models.RetrievalHop) string {
+	byID := make(map[string]models.SearchResult, len(sources))
+	for _, source := range sources {
+		byID[source.Note.ID.Hex()] = source
+	}
+
+	var contextText strings.Builder
+	for _, hop := range hops {
+		if len(hop.NoteIDs) == 0 {
+			continue
+		}
+		hopSources := make([]models.SearchResult, 0, len(hop.NoteIDs))
+		for _, id := range hop.NoteIDs {
+			hopSources = append(hopSources, byID[id])
+		}
+		fmt.Fprintf(&contextText, "Step %d, searching for %q:\n\n%s", hop.Hop, hop.Query, answerContext(hopSources))
+	}
+	return contextText.String()
+}
+
+// retrievalQuery combines the question with the most recent user turns for note retrieval
+func retrievalQuery(question string, history []models.ConversationMessage) string {
+	const maxPriorQuestions = 2
+
+	var prior []string
+	for i := len(history) - 1; i >= 0 && len(prior) < maxPriorQuestions; i-- {
+		if history[i].Role == models.ConversationRoleUser {
+			prior = append([]string{history[i].Content}, prior...)
+		}
+	}
+	return strings.Join(append(prior, question), "\n")
+}
+
+// noRelevantNotesResponse is returned when no notes are relevant enough to answer from
+func noRelevantNotesResponse(question string, minScore float32) *models.QuestionResponse {
 	return &models.QuestionResponse{
-		Answer:   answer,
-		Sources:  relevantNotes,
+		Answer:   "I couldn't find any relevant information in your notes to answer that question.",
+		Sources:  []models.SearchResult{},
 		Question: question,
-	}, nil
+		MinScore: minScore,
+	}
 }
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/searchindex"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SearchIndexService keeps the external search index in step with the notes collection
+// A background loop reads the note event log from a stored cursor, re-reads the changed notes and
+// upserts or deletes them, so the index converges on whatever MongoDB holds regardless of the kind
+// of change. The cursor only advances past events once their notes are synced, so changes made
+// while the index is unreachable, or the server is down, are synced once it is back.
+type SearchIndexService struct {
+	notesRepo    *repository.NotesRepository
+	eventsRepo   *repository.NoteEventsRepository
+	settingsRepo *repository.SettingsRepository
+	index        searchindex.Client
+
+	mu     sync.Mutex // Serializes sync passes and cursor resets
+	notify chan struct{}
+	stop   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewSearchIndexService creates a new SearchIndexService
+func NewSearchIndexService(notesRepo *repository.NotesRepository, eventsRepo *repository.NoteEventsRepository, settingsRepo *repository.SettingsRepository, index searchindex.Client) *SearchIndexService {
+	return &SearchIndexService{
+		notesRepo:    notesRepo,
+		eventsRepo:   eventsRepo,
+		settingsRepo: settingsRepo,
+		index:        index,
+		notify:       make(chan struct{}, 1),
+	}
+}
+
+// HandleEvent wakes the sync loop; subscribe it to the WebhookService
+// Never blocks: the event itself is read back from the event log.
+func (s *SearchIndexService) HandleEvent(event models.NoteEvent) {
+	select {
+	case s.notify <- struct{}{}:
+	default: // A pass is already pending
+	}
+}
+
+// Start launches the sync loop, which runs on every new event and retries failed syncs periodically
+func (s *SearchIndexService) Start() {
+	s.stop = make(chan struct{})
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(config.SEARCH_INDEX_RETRY_SECONDS * time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.notify:
+			case <-ticker.C:
+			case <-s.stop:
+				return
+			}
+			if err := s.SyncEvents(context.Background()); err != nil {
+				log.Printf("Failed to sync search index, retrying later: %v", err)
+			}
+		}
+	}()
+	log.Println("Started search index sync")
+}
+
+// Stop ends the sync loop and waits for an in-progress pass to finish
+func (s *SearchIndexService) Stop() {
+	if s.stop == nil {
+		return
+	}
+	close(s.stop)
+	s.wg.Wait()
+}
+
+// SyncEvents syncs the notes of every event logged after the stored cursor, a batch at a time,
+// advancing the cursor past each batch once it is synced
+// Without a stored cursor syncing starts from now: a new index is populated by a reindex.
+func (s *SearchIndexService) SyncEvents(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cursor, found, err := s.settingsRepo.GetSearchIndexCursor(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load cursor: %w", err)
+	}
+	if !found {
+		log.Printf("Search index sync starting from now; run a reindex to index existing notes")
+		return s.settingsRepo.SaveSearchIndexCursor(ctx, primitive.NewObjectID())
+	}
+
+	for {
+		events, err := s.eventsRepo.FindAfter(ctx, cursor, config.SEARCH_INDEX_BATCH_SIZE)
+		if err != nil {
+			return fmt.Errorf("failed to load events: %w", err)
+		}
+		if len(events) == 0 {
+			return nil
+		}
+
+		ids := make([]primitive.ObjectID, len(events))
+		for i, event := range events {
+			ids[i] = event.NoteID
+		}
+		if err := s.Sync(ctx, ids); err != nil {
+			return err
+		}
+
+		cursor = events[len(events)-1].ID
+		if err := s.settingsRepo.SaveSearchIndexCursor(ctx, cursor); err != nil {
+			return fmt.Errorf("failed to save cursor: %w", err)
+		}
+	}
+}
+
+// Sync brings the given notes up to date in the index: existing notes are upserted and
+// deleted ones removed
+func (s *SearchIndexService) Sync(ctx context.Context, ids []primitive.ObjectID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	notes, err := s.notesRepo.FindAll(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return fmt.Errorf("failed to load notes: %w", err)
+	}
+
+	found := make(map[primitive.ObjectID]bool, len(notes))
+	docs := make([]searchindex.Document, 0, len(notes))
+	for i := range notes {
+		found[notes[i].ID] = true
+		docs = append(docs, searchindex.DocumentFromNote(&notes[i]))
+	}
+
+	var deleted []string
+	for _, id := range ids {
+		if !found[id] {
+			found[id] = true // Queued IDs may repeat
+			deleted = append(deleted, id.Hex())
+		}
+	}
+
+	if err := s.index.Upsert(ctx, docs); err != nil {
+		return fmt.Errorf("failed to upsert documents: %w", err)
+	}
+	if err := s.index.Delete(ctx, deleted); err != nil {
+		return fmt.Errorf("failed to delete documents: %w", err)
+	}
+	return nil
+}
+
+// Reindex upserts every note into the index, creating the index first if needed
+// Used to populate a new index. Event syncing continues from the start of the reindex, since
+// every note changed before then is read by it. Documents of notes deleted before the index
+// was synced are not removed. Returns the number of notes indexed.
+func (s *SearchIndexService) Reindex(ctx context.Context) (int, error) {
+	if err := s.index.EnsureIndex(ctx); err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	err := s.settingsRepo.SaveSearchIndexCursor(ctx, primitive.NewObjectID())
+	s.mu.Unlock()
+	if err != nil {
+		return 0, fmt.Errorf("failed to save cursor: %w", err)
+	}
+
+	count := 0
+	batch := make([]searchindex.Document, 0, config.SEARCH_INDEX_BATCH_SIZE)
+	flush := func() error {
+		if err := s.index.Upsert(ctx, batch); err != nil {
+			return fmt.Errorf("failed to upsert documents: %w", err)
+		}
+		count += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	err = s.notesRepo.ForEach(ctx, bson.M{}, func(note *models.Note) error {
+		batch = append(batch, searchindex.DocumentFromNote(note))
+		if len(batch) == config.SEARCH_INDEX_BATCH_SIZE {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return count, err
+	}
+	if err := flush(); err != nil {
+		return count, err
+	}
+
+	log.Printf("Reindexed %d notes into search index", count)
+	return count, nil
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SpellService suggests corrections for misspelled search queries using the vocabulary of the notes corpus
+type SpellService struct {
+	notesRepo *repository.NotesRepository
+
+	mu         sync.Mutex
+	vocabulary map[string]int // Word -> occurrences across titles, summaries and content
+	builtAt    time.Time
+}
+
+// NewSpellService creates a new SpellService
+func NewSpellService(notesRepo *repository.NotesRepository) *SpellService {
+	return &SpellService{
+		notesRepo: notesRepo,
+	}
+}
+
+// DidYouMean checks each query word against the corpus vocabulary and suggests the most frequent
+// known word within edit distance 1 (2 for words of 8+ characters). Known and short words are kept as-is.
+func (s *SpellService) DidYouMean(ctx context.Context, query string) (*models.DidYouMeanResponse, error) {
+	vocabulary, err := s.getVocabulary(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.DidYouMeanResponse{
+		Query:       query,
+		Corrections: []models.SpellCorrection{},
+	}
+
+	fields := strings.Fields(query)
+	changed := false
+	for i, field := range fields {
+		words := utils.Words(field)
+		// Only correct plain words; tokens like "ERR_IMAGE_PULL" or "v1.2" are left alone
+		if len(words) != 1 || words[0] != strings.ToLower(field) {
+			continue
+		}
+		word := words[0]
+		if len([]rune(word)) < config.SPELL_MIN_WORD_LENGTH || vocabulary[word] > 0 {
+			continue
+		}
+
+		if correction, distance, ok := closestWord(word, vocabulary); ok {
+			response.Corrections = append(response.Corrections, models.SpellCorrection{
+				Original:  field,
+				Corrected: correction,
+				Distance:  distance,
+			})
+			fields[i] = correction
+			changed = true
+		}
+	}
+
+	if changed {
+		response.CorrectedQuery = strings.Join(fields, " ")
+	}
+
+	return response, nil
+}
+
+// closestWord finds the best known replacement for a word: smallest distance, then highest frequency
+func closestWord(word string, vocabulary map[string]int) (string, int, bool) {
+	maxDistance := 1
+	if len([]rune(word)) >= 8 {
+		maxDistance = 2
+	}
+
+	best, bestDistance, bestCount := "", maxDistance+1, 0
+	for candidate, count := range vocabulary {
+		// Length difference is a lower bound on the distance, so skip the DP when it is already too far
+		if diff := len([]rune(candidate)) - len([]rune(word)); diff > maxDistance || -diff > maxDistance {
+			continue
+		}
+		distance := utils.EditDistance(word, candidate)
+		if distance > maxDistance {
+			continue
+		}
+		if distance < bestDistance || (distance == bestDistance && (count > bestCount || (count == bestCount && candidate < best))) {
+			best, bestDistance, bestCount = candidate, distance, count
+		}
+	}
+
+	return best, bestDistance, best != ""
+}
+
+// getVocabulary returns the cached corpus vocabulary, rebuilding it once it is older than the TTL
+func (s *SpellService) getVocabulary(ctx context.Context) (map[string]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.vocabulary != nil && time.Since(s.builtAt) < config.SPELL_VOCABULARY_TTL_MINUTES*time.Minute {
+		return s.vocabulary, nil
+	}
+
+	opts := options.Find().SetProjection(bson.M{"title": 1, "summary": 1, "content": 1})
+	notes, err := s.notesRepo.FindAll(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load notes vocabulary: %w", err)
+	}
+
+	vocabulary := make(map[string]int)
+	for _, note := range notes {
+		for _, text := range []string{note.Title, note.Summary, note.Content} {
+			for _, word := range utils.Words(text) {
+				vocabulary[word]++
+			}
+		}
+	}
+
+	s.vocabulary = vocabulary
+	s.builtAt = time.Now()
+
+	return vocabulary, nil
+}
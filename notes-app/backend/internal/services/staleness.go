@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"backend/internal/ai"
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// StalenessService finds old notes in fast-moving categories that newer notes have overtaken
+// Each old note's title and tags are searched for newer notes in the same category, and the AI
+// judges whether each match supersedes or contradicts the old note.
+type StalenessService struct {
+	notesRepo *repository.NotesRepository
+	aiClient  ai.Client
+}
+
+// NewStalenessService creates a new StalenessService
+func NewStalenessService(notesRepo *repository.NotesRepository, aiClient ai.Client) *StalenessService {
+	return &StalenessService{
+		notesRepo: notesRepo,
+		aiClient:  aiClient,
+	}
+}
+
+// FindOutdated reports old notes that newer notes supersede or contradict, with the newer notes
+// as suggested successors. Only one category is checked when given, otherwise STALE_CATEGORIES;
+// staleAfterDays of 0 uses DEFAULT_STALE_AFTER_DAYS. At most MAX_STALE_NOTES_CHECKED old notes are
+// compared per report, oldest first, since every comparison is an AI call.
+func (s *StalenessService) FindOutdated(ctx context.Context, category string, staleAfterDays int) (*models.OutdatedReport, error) {
+	categories := config.STALE_CATEGORIES
+	if category != "" {
+		if !config.IsValidCategory(category) {
+			return nil, fmt.Errorf("invalid category: %s", category)
+		}
+		categories = []string{category}
+	}
+	if staleAfterDays <= 0 {
+		staleAfterDays = config.DEFAULT_STALE_AFTER_DAYS
+	}
+
+	now := time.Now()
+	filter := bson.M{
+		"category": bson.M{"$in": categories},
+		"created":  bson.M{"$lt": now.AddDate(0, 0, -staleAfterDays)},
+		"archived": bson.M{"$ne": true},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "created", Value: 1}}).SetLimit(config.MAX_STALE_NOTES_CHECKED + 1)
+	oldNotes, err := s.notesRepo.FindAll(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load notes: %w", err)
+	}
+
+	report := &models.OutdatedReport{
+		Categories:     categories,
+		StaleAfterDays: staleAfterDays,
+		Candidates:     []models.OutdatedCandidate{},
+	}
+	if len(oldNotes) > config.MAX_STALE_NOTES_CHECKED {
+		oldNotes = oldNotes[:config.MAX_STALE_NOTES_CHECKED]
+		report.Truncated = true
+	}
+
+	for i := range oldNotes {
+		note := &oldNotes[i]
+		successors, err := s.findSuccessors(ctx, note)
+		if err != nil {
+			return nil, err
+		}
+		report.Checked++
+		if len(successors) == 0 {
+			continue
+		}
+		report.Candidates = append(report.Candidates, models.OutdatedCandidate{
+			ID:         note.ID,
+			Title:      note.Title,
+			Category:   note.Category,
+			Created:    note.Created,
+			AgeDays:    int(now.Sub(note.Created).Hours() / 24),
+			Successors: successors,
+		})
+	}
+	return report, nil
+}
+
+// findSuccessors returns the newer notes on a note's subject that supersede or contradict it
+// A failed comparison is logged and skipped so one bad response doesn't sink the report
+func (s *StalenessService) findSuccessors(ctx context.Context, note *models.Note) ([]models.SuccessorNote, error) {
+	query := strings.TrimSpace(note.Title + " " + strings.Join(note.Tags, " "))
+	if query == "" {
+		return nil, nil
+	}
+
+	newer, err := s.notesRepo.TextSearch(ctx, query, bson.M{
+		"_id":      bson.M{"$ne": note.ID},
+		"category": note.Category,
+		"created":  bson.M{"$gt": note.Created},
+		"archived": bson.M{"$ne": true},
+	}, config.STALE_SUCCESSOR_CANDIDATES)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search newer notes: %w", err)
+	}
+
+	var successors []models.SuccessorNote
+	for _, candidate := range newer {
+		comparison, err := s.aiClient.CompareNotes(describeForComparison(note), describeForComparison(&candidate))
+		if err != nil {
+			log.Printf("Failed to compare note %s with %s: %v", note.ID.Hex(), candidate.ID.Hex(), err)
+			continue
+		}
+		if comparison.Relation != models.NoteRelationSupersedes && comparison.Relation != models.NoteRelationContradicts {
+			continue
+		}
+		successors = append(successors, models.SuccessorNote{
+			ID:       candidate.ID,
+			Title:    candidate.Title,
+			Created:  candidate.Created,
+			Relation: comparison.Relation,
+			Reason:   comparison.Reason,
+		})
+	}
+	return successors, nil
+}
+
+// describeForComparison formats a note with its title and date for the AI to compare
+func describeForComparison(note *models.Note) string {
+	return fmt.Sprintf("Title: %s\nWritten: %s\n\n%s", note.Title, note.Created.Format(heatmapDateFormat), note.Content)
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// heatmapDateFormat is the day format shared with the Mongo aggregation
+const heatmapDateFormat = "2006-01-02"
+
+// StatsService computes aggregate statistics over the notes collection
+type StatsService struct {
+	notesRepo *repository.NotesRepository
+}
+
+// NewStatsService creates a new StatsService
+func NewStatsService(notesRepo *repository.NotesRepository) *StatsService {
+	return &StatsService{
+		notesRepo: notesRepo,
+	}
+}
+
+// GetHeatmap returns daily note creation counts for the past year, ending today, as week columns
+// Counting happens in Mongo; archived notes are included since they were still created.
+// category and channel (the note author, matched exactly) narrow the notes counted; timezone is
+// an IANA name deciding where days start, UTC when empty.
+func (s *StatsService) GetHeatmap(ctx context.Context, category, channel, timezone string) (*models.Heatmap, error) {
+	loc, err := loadTimezone(timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().In(loc)
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	from := to.AddDate(-1, 0, 1)
+
+	filter := bson.M{"created": bson.M{"$gte": from}}
+	if category != "" {
+		filter["category"] = category
+	}
+	if channel != "" {
+		filter["metadata.author"] = channel
+	}
+
+	counts, err := s.notesRepo.DailyCounts(ctx, filter, loc.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to count notes: %w", err)
+	}
+	byDay := make(map[string]int, len(counts))
+	for _, count := range counts {
+		byDay[count.Date] = count.Count
+	}
+
+	heatmap := &models.Heatmap{
+		From:     from.Format(heatmapDateFormat),
+		To:       to.Format(heatmapDateFormat),
+		Timezone: loc.String(),
+		Weeks:    [][]int{},
+	}
+
+	// Columns start on the Sunday on or before from and end with the week containing to
+	day := from.AddDate(0, 0, -int(from.Weekday()))
+	for !day.After(to) {
+		week := make([]int, 7)
+		for i := range week {
+			if day.Before(from) || day.After(to) {
+				week[i] = -1
+			} else {
+				count := byDay[day.Format(heatmapDateFormat)]
+				week[i] = count
+				heatmap.Total += count
+				if count > heatmap.Max {
+					heatmap.Max = count
+				}
+			}
+			day = day.AddDate(0, 0, 1)
+		}
+		heatmap.Weeks = append(heatmap.Weeks, week)
+	}
+
+	return heatmap, nil
+}
+
+// GetAccessReport returns the most or least viewed unarchived notes
+func (s *StatsService) GetAccessReport(ctx context.Context, order string, limit int) (*models.AccessReport, error) {
+	if order == "" {
+		order = models.AccessOrderMost
+	}
+	var sort bson.D
+	switch order {
+	case models.AccessOrderMost:
+		sort = bson.D{{Key: "view_count", Value: -1}, {Key: "last_viewed_at", Value: -1}, {Key: "_id", Value: 1}}
+	case models.AccessOrderLeast:
+		sort = bson.D{{Key: "view_count", Value: 1}, {Key: "last_viewed_at", Value: 1}, {Key: "_id", Value: 1}}
+	default:
+		return nil, fmt.Errorf("invalid order: must be most or least")
+	}
+	if limit <= 0 {
+		limit = config.DEFAULT_ACCESS_REPORT_LIMIT
+	}
+	if limit > config.MAX_ACCESS_REPORT_LIMIT {
+		limit = config.MAX_ACCESS_REPORT_LIMIT
+	}
+
+	notes, err := s.notesRepo.FindAccess(ctx, bson.M{"archived": bson.M{"$ne": true}}, sort, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load view counts: %w", err)
+	}
+	return &models.AccessReport{Order: order, Notes: notes}, nil
+}
+
+// loadTimezone resolves an IANA timezone name, UTC when empty
+func loadTimezone(timezone string) (*time.Location, error) {
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone: %q", timezone)
+	}
+	return loc, nil
+}
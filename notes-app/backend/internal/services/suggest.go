@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repository"
+)
+
+// Relative weight of each suggestion type; a prefix match on the whole text outranks any type weight
+var suggestionTypeWeights = map[string]float64{
+	models.SuggestionQuery:    1.0,
+	models.SuggestionTitle:    0.8,
+	models.SuggestionTag:      0.6,
+	models.SuggestionCategory: 0.4,
+}
+
+// SuggestService provides search-box typeahead from titles, tags, categories and recent queries
+type SuggestService struct {
+	notesRepo         *repository.NotesRepository
+	searchQueriesRepo *repository.SearchQueriesRepository
+}
+
+// NewSuggestService creates a new SuggestService
+func NewSuggestService(
+	notesRepo *repository.NotesRepository,
+	searchQueriesRepo *repository.SearchQueriesRepository,
+) *SuggestService {
+	return &SuggestService{
+		notesRepo:         notesRepo,
+		searchQueriesRepo: searchQueriesRepo,
+	}
+}
+
+// RecordQuery logs a search so it can be suggested later
+func (s *SuggestService) RecordQuery(ctx context.Context, query string) error {
+	return s.searchQueriesRepo.Record(ctx, query)
+}
+
+// Suggest returns typeahead completions for a prefix, best first
+// Each source is queried for up to limit candidates; duplicates across sources keep the best score.
+func (s *SuggestService) Suggest(ctx context.Context, prefix string, limit int) ([]models.Suggestion, error) {
+	prefix = strings.TrimSpace(prefix)
+	if prefix == "" {
+		return []models.Suggestion{}, nil
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+
+	var candidates []models.Suggestion
+
+	queries, err := s.searchQueriesRepo.FindByPrefix(ctx, prefix, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find recent queries: %w", err)
+	}
+	for _, q := range queries {
+		candidates = append(candidates, models.Suggestion{Type: models.SuggestionQuery, Text: q.Query, Count: q.Count})
+	}
+
+	notes, err := s.notesRepo.FindByTitlePrefix(ctx, prefix, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find matching titles: %w", err)
+	}
+	for _, note := range notes {
+		id := note.ID
+		candidates = append(candidates, models.Suggestion{Type: models.SuggestionTitle, Text: note.Title, NoteID: &id})
+	}
+
+	tags, err := s.notesRepo.TagCounts(ctx, prefix, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find matching tags: %w", err)
+	}
+	for _, tag := range tags {
+		candidates = append(candidates, models.Suggestion{Type: models.SuggestionTag, Text: tag.Name, Count: tag.Count})
+	}
+
+	lowerPrefix := strings.ToLower(prefix)
+	for _, category := range config.Categories() {
+		if strings.HasPrefix(category, lowerPrefix) {
+			candidates = append(candidates, models.Suggestion{Type: models.SuggestionCategory, Text: category})
+		}
+	}
+
+	// Score and de-duplicate by text across sources
+	best := make(map[string]models.Suggestion)
+	for _, candidate := range candidates {
+		candidate.Score = suggestionScore(candidate, lowerPrefix)
+		key := strings.ToLower(candidate.Text)
+		if existing, ok := best[key]; !ok || candidate.Score > existing.Score {
+			best[key] = candidate
+		}
+	}
+
+	suggestions := make([]models.Suggestion, 0, len(best))
+	for _, suggestion := range best {
+		suggestions = append(suggestions, suggestion)
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		return suggestions[i].Text < suggestions[j].Text
+	})
+
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+
+	return suggestions, nil
+}
+
+// suggestionScore ranks a candidate: whole-text prefix matches beat word matches,
+// then type weight, then a small popularity bonus
+func suggestionScore(suggestion models.Suggestion, lowerPrefix string) float64 {
+	score := 1.0 // Matched a later word
+	if strings.HasPrefix(strings.ToLower(suggestion.Text), lowerPrefix) {
+		score = 2.0
+	}
+	score += suggestionTypeWeights[suggestion.Type]
+	score += 0.1 * math.Log1p(float64(suggestion.Count))
+	return score
+}
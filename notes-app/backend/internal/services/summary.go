@@ -2,16 +2,22 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
+	"regexp"
+	"slices"
 	"time"
 
 	"backend/internal/ai"
+	"backend/internal/config"
 	"backend/internal/models"
 	"backend/internal/repository"
+	"backend/internal/utils"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // SummaryService handles summary generation operations
@@ -19,6 +25,10 @@ type SummaryService struct {
 	notesRepo           *repository.NotesRepository
 	channelSettingsRepo *repository.ChannelSettingsRepository
 	aiClient            ai.Client
+	workerPool          *WorkerPool // Optional; nil leaves new summaries unembedded
+	webhookService      *WebhookService
+	aiHistory           *AIHistoryService
+	cfg                 *config.Config
 }
 
 // NewSummaryService creates a new SummaryService
@@ -26,11 +36,19 @@ func NewSummaryService(
 	notesRepo *repository.NotesRepository,
 	channelSettingsRepo *repository.ChannelSettingsRepository,
 	aiClient ai.Client,
+	workerPool *WorkerPool,
+	webhookService *WebhookService,
+	aiHistory *AIHistoryService,
+	cfg *config.Config,
 ) *SummaryService {
 	return &SummaryService{
 		notesRepo:           notesRepo,
 		channelSettingsRepo: channelSettingsRepo,
 		aiClient:            aiClient,
+		workerPool:          workerPool,
+		webhookService:      webhookService,
+		aiHistory:           aiHistory,
+		cfg:                 cfg,
 	}
 }
 
@@ -60,40 +78,12 @@ func (s *SummaryService) GenerateSummary(ctx context.Context, req *GenerateSumma
 	promptText := req.PromptText
 	promptSchema := req.PromptSchema
 
-	if promptText == "" && promptSchema == "" && note.Metadata != nil {
-		if author, ok := note.Metadata["author"].(string); ok && author != "" {
-			settings, _ := s.channelSettingsRepo.FindByName(ctx, author)
-			if settings != nil {
-				promptText = settings.PromptText
-				promptSchema = settings.PromptSchema
-			}
-		}
+	if promptText == "" && promptSchema == "" {
+		promptText, promptSchema = s.ChannelPrompt(ctx, note)
 	}
 
 	// Generate structured summary using Gemini
-	summary, structuredData, err := s.aiClient.GenerateStructuredSummary(req.Content, promptText, promptSchema)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate summary: %w", err)
-	}
-
-	// Update the note in the database with summary, structured data, and last summarized timestamp
-	updateFields := bson.M{
-		"summary":            summary,
-		"last_summarized_at": time.Now(),
-	}
-	if structuredData != nil {
-		updateFields["structured_data"] = structuredData
-	}
-
-	err = s.notesRepo.Update(ctx, objID, bson.M{"$set": updateFields})
-	if err != nil {
-		return nil, fmt.Errorf("failed to save summary: %w", err)
-	}
-
-	return &models.SummarizeResponse{
-		Summary:        summary,
-		StructuredData: structuredData,
-	}, nil
+	return s.summarizeAndSave(ctx, note, req.Content, promptText, promptSchema)
 }
 
 // GenerateSummaryByID generates a summary for a note using its stored content
@@ -111,86 +101,302 @@ func (s *SummaryService) GenerateSummaryByID(ctx context.Context, noteID string,
 	}
 
 	// If no override provided, check channel settings
-	if promptText == "" && promptSchema == "" && note.Metadata != nil {
-		if author, ok := note.Metadata["author"].(string); ok && author != "" {
-			settings, _ := s.channelSettingsRepo.FindByName(ctx, author)
-			if settings != nil {
-				promptText = settings.PromptText
-				promptSchema = settings.PromptSchema
-				if promptText != "" || promptSchema != "" {
-					log.Printf("Using custom prompt/schema for channel %s", author)
-				}
-			}
+	if promptText == "" && promptSchema == "" {
+		promptText, promptSchema = s.ChannelPrompt(ctx, note)
+		if promptText != "" || promptSchema != "" {
+			log.Printf("Using custom prompt/schema for channel %s", note.Metadata["author"])
 		}
 	}
 
 	// Generate structured summary using Gemini with the note's content
+	return s.summarizeAndSave(ctx, note, note.Content, promptText, promptSchema)
+}
+
+// summarizeAndSave generates a structured summary and stores it on the note, queueing it to be
+// embedded for search. An extractive summary is saved instead when privacy mode is on or the LLM fails.
+// If Gemini blocked the content, the response is returned together with an error
+// wrapping ai.ErrContentBlocked so callers can report it distinctly.
+func (s *SummaryService) summarizeAndSave(ctx context.Context, note *models.Note, content, promptText, promptSchema string) (*models.SummarizeResponse, error) {
+	objID := note.ID
+	var summary string
+	var structuredData map[string]interface{}
+	fallback, blocked := false, false
+
+	if s.cfg.PrivacyMode {
+		summary = utils.ExtractiveSummary(content, config.FALLBACK_SUMMARY_SENTENCES)
+		fallback = true
+	} else {
+		var err error
+		summary, structuredData, err = s.aiClient.GenerateStructuredSummary(content, promptText, promptSchema)
+		s.aiHistory.Record(ctx, objID, AICall{Operation: models.AIOperationSummary, Content: content, PromptText: promptText, PromptSchema: promptSchema, Output: summary, Err: err})
+		if err != nil {
+			blocked = ai.IsContentBlocked(err)
+			if blocked {
+				log.Printf("Summary for note %s blocked by safety filters, using extractive fallback: %v", objID.Hex(), err)
+			} else {
+				log.Printf("Failed to generate summary for note %s, using extractive fallback: %v", objID.Hex(), err)
+			}
+			summary = utils.ExtractiveSummary(content, config.FALLBACK_SUMMARY_SENTENCES)
+			structuredData = nil
+			fallback = true
+		}
+	}
+
+	structuredData, err := s.saveSummary(ctx, note, summary, structuredData, fallback)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.SummarizeResponse{
+		Summary:        summary,
+		StructuredData: structuredData,
+		Fallback:       fallback,
+	}
+	if blocked {
+		return response, fmt.Errorf("failed to generate summary: %w", ai.ErrContentBlocked)
+	}
+
+	return response, nil
+}
+
+// ResummarizeNote regenerates a note's summary with its channel's current prompt and schema
+// Unlike GenerateSummaryByID it never falls back to an extractive summary: when generation fails
+// the note keeps its summary and the error is returned, rate limits included, so the caller can retry.
+func (s *SummaryService) ResummarizeNote(ctx context.Context, note *models.Note) error {
+	promptText, promptSchema := s.ChannelPrompt(ctx, note)
 	summary, structuredData, err := s.aiClient.GenerateStructuredSummary(note.Content, promptText, promptSchema)
+	if !ai.IsRateLimited(err) { // A rate-limited call is retried, so only its retry is recorded
+		s.aiHistory.Record(ctx, note.ID, AICall{Operation: models.AIOperationSummary, Content: note.Content, PromptText: promptText, PromptSchema: promptSchema, Output: summary, Err: err})
+	}
 	if err != nil {
-		log.Printf("Failed to generate summary: %v", err)
-		return nil, fmt.Errorf("failed to generate summary: %w", err)
+		return err
+	}
+	_, err = s.saveSummary(ctx, note, summary, structuredData, false)
+	return err
+}
+
+// ChannelPrompt returns the custom prompt and schema saved for the channel a note came from, if any
+func (s *SummaryService) ChannelPrompt(ctx context.Context, note *models.Note) (promptText, promptSchema string) {
+	author, _ := note.Metadata["author"].(string)
+	if author == "" {
+		return "", ""
+	}
+	settings, _ := s.channelSettingsRepo.FindByChannel(ctx, notePlatform(note), author)
+	if settings == nil {
+		return "", ""
 	}
+	return settings.PromptText, settings.PromptSchema
+}
+
+// saveSummary stores a summary on the note and queues it to be embedded for search
+// Returns the structured data the note is left with: the new data, or the note's existing data
+// when the summary came without any.
+func (s *SummaryService) saveSummary(ctx context.Context, note *models.Note, summary string, structuredData map[string]interface{}, fallback bool) (map[string]interface{}, error) {
+	objID := note.ID
 
 	// Update the note in the database with summary, structured data, and last summarized timestamp
 	updateFields := bson.M{
 		"summary":            summary,
 		"last_summarized_at": time.Now(),
 	}
+	unsetFields := bson.M{}
 	if structuredData != nil {
+		author, _ := note.Metadata["author"].(string)
+		var truncated bool
+		structuredData, truncated = limitStructuredData(structuredData, author)
 		updateFields["structured_data"] = structuredData
+		if truncated {
+			updateFields["structured_data_truncated"] = true
+		} else {
+			unsetFields["structured_data_truncated"] = ""
+		}
+	}
+	if fallback {
+		unsetFields["summary_generation"] = ""
+	} else {
+		updateFields["summary_generation"] = newGeneration(s.cfg, models.AIOperationSummary)
+	}
+	update := bson.M{"$set": updateFields}
+	if len(unsetFields) > 0 {
+		update["$unset"] = unsetFields
 	}
 
-	err = s.notesRepo.Update(ctx, objID, bson.M{"$set": updateFields})
-	if err != nil {
+	if err := s.notesRepo.Update(ctx, objID, update); err != nil {
 		return nil, fmt.Errorf("failed to save summary: %w", err)
 	}
+	s.webhookService.Emit(ctx, models.NoteEventSummaryGenerated, objID, map[string]interface{}{
+		"summary":        summary,
+		"structuredData": structuredData,
+		"fallback":       fallback,
+	})
 
-	return &models.SummarizeResponse{
-		Summary:        summary,
-		StructuredData: structuredData,
-	}, nil
+	if s.workerPool != nil {
+		if structuredData == nil {
+			structuredData = note.StructuredData // Kept by the update above
+		}
+		if err := s.workerPool.Submit(ctx, models.ProcessingJob{
+			NoteID:         objID,
+			Category:       note.Category,
+			NoteCreated:    note.Created,
+			Metadata:       note.Metadata,
+			Summary:        summary,
+			StructuredData: structuredData,
+			SummaryOnly:    true,
+		}); err != nil {
+			log.Printf("Failed to queue summary embedding job: %v", err)
+		}
+	}
+	return structuredData, nil
 }
 
-// RegenerateTitlesResult holds the result of regenerating titles
-type RegenerateTitlesResult struct {
-	Regenerated int
-	Errors      int
-	Total       int
+// limitStructuredData cuts generated structured data down to MAX_STRUCTURED_DATA_BYTES and
+// MAX_STRUCTURED_DATA_DEPTH, logging when it had to so the channel's prompt can be fixed
+func limitStructuredData(structuredData map[string]interface{}, channel string) (map[string]interface{}, bool) {
+	limited, truncated := utils.LimitStructuredData(structuredData, config.MAX_STRUCTURED_DATA_BYTES, config.MAX_STRUCTURED_DATA_DEPTH)
+	if truncated {
+		log.Printf("Structured data generated with the prompt of channel '%s' was over the size limits and was cut down: %v",
+			channel, utils.CheckStructuredData(structuredData, config.MAX_STRUCTURED_DATA_BYTES, config.MAX_STRUCTURED_DATA_DEPTH))
+	}
+	return limited, truncated
 }
 
-// RegenerateAllTitles regenerates titles for all notes
-func (s *SummaryService) RegenerateAllTitles(ctx context.Context) (*RegenerateTitlesResult, error) {
-	// Find all notes
-	notes, err := s.notesRepo.FindAll(ctx, bson.M{})
-	if err != nil {
-		return nil, fmt.Errorf("failed to find notes: %w", err)
+// PreviewChannelPrompt runs a channel prompt on the channel's most recent notes and returns what it
+// generated, without saving anything, so a prompt and schema can be checked against real content
+func (s *SummaryService) PreviewChannelPrompt(ctx context.Context, channel string, req *models.ChannelPromptPreviewRequest) (*models.ChannelPromptPreview, error) {
+	if s.cfg.PrivacyMode {
+		return nil, fmt.Errorf("invalid request: custom prompts are not used in privacy mode")
+	}
+	if req.Platform != "" && !config.IsValidPlatform(req.Platform) {
+		return nil, fmt.Errorf("invalid platform: %q", req.Platform)
+	}
+	limit := req.Limit
+	if limit == 0 {
+		limit = config.DEFAULT_PROMPT_PREVIEW_SAMPLES
+	}
+	if limit < 1 || limit > config.MAX_PROMPT_PREVIEW_SAMPLES {
+		return nil, fmt.Errorf("invalid limit: must be between 1 and %d", config.MAX_PROMPT_PREVIEW_SAMPLES)
 	}
 
-	result := &RegenerateTitlesResult{
-		Total: len(notes),
+	platform := config.NormalizePlatform(req.Platform)
+	promptText, promptSchema := req.PromptText, req.PromptSchema
+	if promptText == "" && promptSchema == "" {
+		settings, err := s.channelSettingsRepo.FindByChannel(ctx, platform, channel)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get channel settings: %w", err)
+		}
+		if settings != nil {
+			promptText, promptSchema = settings.PromptText, settings.PromptSchema
+		}
+	}
+
+	var schemaFields []string
+	if promptSchema != "" {
+		var schema interface{}
+		if err := json.Unmarshal([]byte(promptSchema), &schema); err != nil {
+			return nil, fmt.Errorf("invalid JSON in promptSchema: %w", err)
+		}
+		if object, ok := schema.(map[string]interface{}); ok {
+			for field := range object {
+				schemaFields = append(schemaFields, field)
+			}
+			slices.Sort(schemaFields)
+		}
 	}
 
+	notes, err := s.recentChannelNotes(ctx, channel, platform, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &models.ChannelPromptPreview{
+		Channel:      channel,
+		Platform:     platform,
+		PromptText:   promptText,
+		PromptSchema: promptSchema,
+		Samples:      make([]models.ChannelPromptSample, 0, len(notes)),
+	}
 	for _, note := range notes {
-		newTitle, err := s.aiClient.GenerateTitle(note.Content)
+		sample := models.ChannelPromptSample{NoteID: note.ID, Title: note.Title}
+		summary, structuredData, err := s.aiClient.GenerateStructuredSummary(note.Content, promptText, promptSchema)
 		if err != nil {
-			log.Printf("Failed to generate title for note %s: %v", note.ID.Hex(), err)
-			result.Errors++
+			log.Printf("Failed to preview channel prompt on note %s: %v", note.ID.Hex(), err)
+			sample.Error = err.Error()
+			preview.Samples = append(preview.Samples, sample)
 			continue
 		}
 
-		err = s.notesRepo.Update(
-			ctx,
-			note.ID,
-			bson.M{"$set": bson.M{"title": newTitle}},
-		)
-		if err != nil {
-			log.Printf("Failed to update title for note %s: %v", note.ID.Hex(), err)
-			result.Errors++
-		} else {
-			result.Regenerated++
-			log.Printf("Updated title for note %s: %s -> %s", note.ID.Hex(), note.Title, newTitle)
+		sample.Summary = summary
+		sample.StructuredData, sample.StructuredDataTruncated = utils.LimitStructuredData(structuredData, config.MAX_STRUCTURED_DATA_BYTES, config.MAX_STRUCTURED_DATA_DEPTH)
+		for _, field := range schemaFields {
+			if _, ok := structuredData[field]; !ok {
+				sample.MissingFields = append(sample.MissingFields, field)
+			}
 		}
+		preview.Samples = append(preview.Samples, sample)
+	}
+
+	return preview, nil
+}
+
+// InferChannelPrompt has the AI propose a prompt and schema from the channel's most recent notes
+// Nothing is saved; the suggestion is returned for review and saved with PUT /channel-settings/:channel.
+func (s *SummaryService) InferChannelPrompt(ctx context.Context, channel string, req *models.InferChannelPromptRequest) (*models.ChannelPromptSuggestion, error) {
+	if s.cfg.PrivacyMode {
+		return nil, fmt.Errorf("invalid request: custom prompts are not used in privacy mode")
+	}
+	if req.Platform != "" && !config.IsValidPlatform(req.Platform) {
+		return nil, fmt.Errorf("invalid platform: %q", req.Platform)
+	}
+	limit := req.Limit
+	if limit == 0 {
+		limit = config.DEFAULT_SCHEMA_SAMPLE_NOTES
 	}
+	if limit < 1 || limit > config.MAX_SCHEMA_SAMPLE_NOTES {
+		return nil, fmt.Errorf("invalid limit: must be between 1 and %d", config.MAX_SCHEMA_SAMPLE_NOTES)
+	}
+
+	platform := config.NormalizePlatform(req.Platform)
+	notes, err := s.recentChannelNotes(ctx, channel, platform, limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(notes) == 0 {
+		return nil, fmt.Errorf("no notes found for channel %q", channel)
+	}
+
+	suggestion := &models.ChannelPromptSuggestion{
+		Channel:       channel,
+		Platform:      platform,
+		SampleNoteIDs: make([]primitive.ObjectID, 0, len(notes)),
+	}
+	samples := make([]string, 0, len(notes))
+	for _, note := range notes {
+		samples = append(samples, note.Title+"\n\n"+note.Content)
+		suggestion.SampleNoteIDs = append(suggestion.SampleNoteIDs, note.ID)
+	}
+
+	suggestion.PromptText, suggestion.PromptSchema, err = s.aiClient.InferChannelPrompt(samples)
+	if err != nil {
+		return nil, err
+	}
+	return suggestion, nil
+}
+
+// recentChannelNotes returns a channel's newest notes, on the given platform when it isn't empty
+func (s *SummaryService) recentChannelNotes(ctx context.Context, channel, platform string, limit int) ([]models.Note, error) {
+	filter := bson.M{"metadata.author": channel}
+	if platform != "" {
+		filter["metadata.platform"] = primitive.Regex{Pattern: "^" + regexp.QuoteMeta(platform) + "$", Options: "i"}
+	}
+	notes, err := s.notesRepo.FindAll(ctx, filter, options.Find().SetSort(bson.D{{Key: "created", Value: -1}}).SetLimit(int64(limit)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find channel notes: %w", err)
+	}
+	return notes, nil
+}
 
-	return result, nil
+// notePlatform returns the normalized platform from a note's metadata
+func notePlatform(note *models.Note) string {
+	platform, _ := note.Metadata["platform"].(string)
+	return config.NormalizePlatform(platform)
 }
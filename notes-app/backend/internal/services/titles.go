@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"backend/internal/config"
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// semanticSearch runs a vector search for the query, ranking notes whose title matches it first
+func (s *SearchService) semanticSearch(ctx context.Context, query string, queryEmbedding []float32, limit int, filters models.SearchFilters, boosts *rankingBoosts, trace *searchTrace) ([]models.SearchResult, error) {
+	results, err := s.searchByEmbedding(ctx, queryEmbedding, limit, filters, boosts, trace)
+	if err != nil {
+		return nil, err
+	}
+	return s.promoteTitleMatches(ctx, query, queryEmbedding, results, limit, filters, trace)
+}
+
+// promoteTitleMatches moves notes whose title nearly equals the query to the front of the results,
+// best title match first, adding them when the ranking left them out
+// People often search for a note by the title they remember, which chunk similarity alone can bury.
+func (s *SearchService) promoteTitleMatches(ctx context.Context, query string, queryEmbedding []float32, results []models.SearchResult, limit int, filters models.SearchFilters, trace *searchTrace) ([]models.SearchResult, error) {
+	notes, titleScores, err := s.titleMatches(ctx, query, queryEmbedding, filters)
+	if err != nil {
+		return nil, err
+	}
+	if len(notes) == 0 {
+		return results, nil
+	}
+	minScore, err := s.SearchThreshold(filters.MinScore)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[primitive.ObjectID]models.SearchResult, len(results))
+	for _, result := range results {
+		existing[result.Note.ID] = result
+	}
+
+	promoted := make([]models.SearchResult, 0, len(notes))
+	kept := make(map[primitive.ObjectID]float64, len(notes))
+	for _, note := range notes {
+		titleScore := titleScores[note.ID]
+		result, ok := existing[note.ID]
+		if !ok {
+			result = models.SearchResult{Note: note}
+			if trace != nil {
+				result.Explain = &models.RankingExplanation{BoostMultiplier: 1, Threshold: minScore}
+			}
+		}
+		result.Score = max(result.Score, titleScore)
+		result.TitleMatch = true
+		if result.Explain != nil {
+			result.Explain.TitleScore = &titleScore
+			result.Explain.FinalScore = result.Score
+		}
+		promoted = append(promoted, result)
+		kept[note.ID] = float64(result.Score)
+	}
+
+	for _, result := range results {
+		if _, ok := kept[result.Note.ID]; !ok {
+			promoted = append(promoted, result)
+		}
+	}
+
+	// Promoted notes may have been dropped earlier, e.g. for scoring below the threshold
+	trace.forget(kept)
+	if len(promoted) > limit {
+		trace.dropResults(promoted[limit:], "beyond result limit")
+		promoted = promoted[:limit]
+	}
+	return promoted, nil
+}
+
+// titleMatches finds up to MAX_TITLE_MATCHES notes passing the filters whose title has the same
+// words as the query, ignoring case and punctuation, or whose title embedding is at least
+// TITLE_MATCH_MIN_SCORE similar to it. Notes are returned best match first with their title scores,
+// 1 for a word-for-word match.
+func (s *SearchService) titleMatches(ctx context.Context, query string, queryEmbedding []float32, filters models.SearchFilters) ([]models.Note, map[primitive.ObjectID]float32, error) {
+	scores := make(map[primitive.ObjectID]float32)
+	var matchers []bson.M
+
+	words := titleWords(query)
+	if len(words) > 0 {
+		quoted := make([]string, len(words))
+		for i, word := range words {
+			quoted[i] = regexp.QuoteMeta(word)
+		}
+		pattern := `^[\W_]*` + strings.Join(quoted, `[\W_]+`) + `[\W_]*$`
+		matchers = append(matchers, bson.M{"title": bson.M{"$regex": pattern, "$options": "i"}})
+	}
+
+	if len(filters.ChunkTypes) == 0 || slices.Contains(filters.ChunkTypes, models.ChunkTypeTitle) {
+		titleFilters := filters
+		titleFilters.ChunkTypes = []string{models.ChunkTypeTitle}
+		searchResults, err := s.qdrantClient.SearchFiltered(queryEmbedding, config.MAX_TITLE_MATCHES, vectorFilter(titleFilters))
+		if err != nil {
+			return nil, nil, fmt.Errorf("title search failed: %w", err)
+		}
+
+		var ids []primitive.ObjectID
+		for _, result := range searchResults {
+			if result.ChunkType != models.ChunkTypeTitle || result.Score < config.TITLE_MATCH_MIN_SCORE {
+				continue
+			}
+			if id, err := primitive.ObjectIDFromHex(result.NoteID); err == nil {
+				ids = append(ids, id)
+				scores[id] = max(scores[id], result.Score)
+			}
+		}
+		if len(ids) > 0 {
+			matchers = append(matchers, bson.M{"_id": bson.M{"$in": ids}})
+		}
+	}
+
+	if len(matchers) == 0 {
+		return nil, nil, nil
+	}
+
+	filter := notesFilter(filters)
+	filter["$or"] = matchers
+	notes, err := s.notesRepo.FindAll(ctx, filter, options.Find().SetLimit(2*config.MAX_TITLE_MATCHES))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch title matches: %w", err)
+	}
+	notes = dropExcludedChannels(notes, filters.ExcludeChannels, func(models.Note) {})
+
+	for _, note := range notes {
+		if slices.Equal(titleWords(note.Title), words) {
+			scores[note.ID] = 1
+		}
+	}
+	sort.SliceStable(notes, func(i, j int) bool {
+		return scores[notes[i].ID] > scores[notes[j].ID]
+	})
+	if len(notes) > config.MAX_TITLE_MATCHES {
+		notes = notes[:config.MAX_TITLE_MATCHES]
+	}
+	return notes, scores, nil
+}
+
+// titleWords splits text into lowercase words, dropping punctuation
+func titleWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
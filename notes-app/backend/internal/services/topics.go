@@ -0,0 +1,280 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"sort"
+
+	"backend/internal/ai"
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/vectordb"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TopicService clusters notes by their embeddings, so they can be browsed by emergent topic
+// rather than by fixed category
+type TopicService struct {
+	notesRepo    *repository.NotesRepository
+	aiClient     ai.Client
+	qdrantClient *vectordb.QdrantClient
+}
+
+// NewTopicService creates a new TopicService
+func NewTopicService(notesRepo *repository.NotesRepository, aiClient ai.Client, qdrantClient *vectordb.QdrantClient) *TopicService {
+	return &TopicService{
+		notesRepo:    notesRepo,
+		aiClient:     aiClient,
+		qdrantClient: qdrantClient,
+	}
+}
+
+// ClusterNotes groups the embedded, unarchived notes into k topics with k-means over their stored
+// content embeddings, and names each topic with the AI from its most typical notes' titles
+// k of 0 picks one from the number of notes. Clustering is seeded, so the same notes give the same topics.
+// When naming fails, a topic is named after its most common tag instead.
+func (s *TopicService) ClusterNotes(ctx context.Context, k int) (*models.TopicClusters, error) {
+	if k < 0 || k > config.MAX_TOPIC_CLUSTERS {
+		return nil, fmt.Errorf("invalid k: must be between 1 and %d", config.MAX_TOPIC_CLUSTERS)
+	}
+
+	vectors, truncated, err := s.noteVectors(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(vectors))
+	for id := range vectors {
+		ids = append(ids, id)
+	}
+	filter := notesFilter(models.SearchFilters{})
+	filter["_id"] = bson.M{"$in": ids}
+	opts := options.Find().SetSort(bson.M{"_id": 1}).SetProjection(bson.M{"title": 1, "category": 1, "tags": 1})
+	notes, err := s.notesRepo.FindAll(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch notes: %w", err)
+	}
+
+	result := &models.TopicClusters{Clusters: []models.TopicCluster{}, Notes: len(notes), Truncated: truncated}
+	if len(notes) == 0 {
+		return result, nil
+	}
+	if k == 0 {
+		k = int(math.Round(math.Sqrt(float64(len(notes)) / 2)))
+		k = max(2, min(k, config.MAX_TOPIC_CLUSTERS))
+	}
+	k = min(k, len(notes))
+
+	points := make([][]float32, len(notes))
+	for i, note := range notes {
+		points[i] = vectors[note.ID]
+	}
+	assignments, centroids, err := kMeans(ctx, points, k)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([][]models.TopicNote, k)
+	memberNotes := make([][]models.Note, k)
+	for i, note := range notes {
+		cluster := assignments[i]
+		members[cluster] = append(members[cluster], models.TopicNote{
+			ID:         note.ID,
+			Title:      note.Title,
+			Category:   note.Category,
+			Similarity: dot(points[i], centroids[cluster]),
+		})
+		memberNotes[cluster] = append(memberNotes[cluster], note)
+	}
+
+	var groups [][]string
+	for cluster := range members {
+		if len(members[cluster]) == 0 {
+			continue
+		}
+		sort.SliceStable(members[cluster], func(i, j int) bool {
+			return members[cluster][i].Similarity > members[cluster][j].Similarity
+		})
+		var titles []string
+		for _, member := range members[cluster][:min(len(members[cluster]), config.TOPIC_LABEL_TITLES)] {
+			titles = append(titles, member.Title)
+		}
+		groups = append(groups, titles)
+		result.Clusters = append(result.Clusters, models.TopicCluster{
+			Size:  len(members[cluster]),
+			Tags:  topTags(memberNotes[cluster], config.TOPIC_CLUSTER_TAGS),
+			Notes: members[cluster],
+		})
+	}
+
+	labels, err := s.aiClient.NameTopics(groups)
+	if err != nil {
+		log.Printf("Failed to name %d topics, naming them after their tags: %v", len(groups), err)
+		labels = make([]string, len(groups))
+	}
+	for i := range result.Clusters {
+		result.Clusters[i].Label = labels[i]
+		if result.Clusters[i].Label == "" && len(result.Clusters[i].Tags) > 0 {
+			result.Clusters[i].Label = result.Clusters[i].Tags[0]
+		}
+		if result.Clusters[i].Label == "" {
+			result.Clusters[i].Label = fmt.Sprintf("Topic %d", i+1)
+		}
+	}
+
+	sort.SliceStable(result.Clusters, func(i, j int) bool {
+		return result.Clusters[i].Size > result.Clusters[j].Size
+	})
+	return result, nil
+}
+
+// noteVectors returns the unit-length mean of each note's stored content embeddings, for at most
+// TOPIC_MAX_NOTES notes; truncated reports that more notes were embedded
+// Summary and structured data embeddings are left out, as they restate the content.
+func (s *TopicService) noteVectors(ctx context.Context) (map[primitive.ObjectID][]float32, bool, error) {
+	byNote := make(map[primitive.ObjectID][][]float32)
+	truncated := false
+
+	var offset *uint64
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, false, err
+		}
+		points, next, err := s.qdrantClient.ScrollPoints(offset, config.EMBEDDINGS_PAGE_SIZE, true)
+		if err != nil {
+			return nil, false, err
+		}
+
+		for _, point := range points {
+			chunkType := point.Payload.ChunkType
+			if (chunkType != "" && chunkType != models.ChunkTypeContent) || len(point.Vector) == 0 {
+				continue
+			}
+			noteID, err := primitive.ObjectIDFromHex(point.NoteID)
+			if err != nil {
+				continue
+			}
+			if _, seen := byNote[noteID]; !seen && len(byNote) >= config.TOPIC_MAX_NOTES {
+				truncated = true
+				continue
+			}
+			byNote[noteID] = append(byNote[noteID], point.Vector)
+		}
+
+		if next == nil {
+			break
+		}
+		offset = next
+	}
+
+	vectors := make(map[primitive.ObjectID][]float32, len(byNote))
+	for noteID, embeddings := range byNote {
+		vectors[noteID] = averageEmbeddings(embeddings)
+	}
+	return vectors, truncated, nil
+}
+
+// kMeans clusters unit-length points into k groups by cosine similarity (spherical k-means), seeded
+// with k-means++ from a fixed seed. Returns each point's cluster and the unit-length cluster centers;
+// a cluster can end up empty when fewer than k distinct points exist.
+func kMeans(ctx context.Context, points [][]float32, k int) ([]int, [][]float32, error) {
+	rng := rand.New(rand.NewSource(1))
+
+	// k-means++: each next center is picked with probability growing with its distance to the closest center
+	centroids := [][]float32{points[rng.Intn(len(points))]}
+	distances := make([]float64, len(points))
+	for len(centroids) < k {
+		total := 0.0
+		for i, point := range points {
+			distances[i] = math.Inf(1)
+			for _, centroid := range centroids {
+				distances[i] = min(distances[i], 1-float64(dot(point, centroid)))
+			}
+			distances[i] = max(distances[i], 0)
+			total += distances[i]
+		}
+		pick := 0
+		if total > 0 {
+			target := rng.Float64() * total
+			for pick = 0; pick < len(points)-1 && target >= distances[pick]; pick++ {
+				target -= distances[pick]
+			}
+		}
+		centroids = append(centroids, points[pick])
+	}
+
+	assignments := make([]int, len(points))
+	for i := range assignments {
+		assignments[i] = -1
+	}
+	for iteration := 0; iteration < config.TOPIC_KMEANS_ITERATIONS; iteration++ {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+
+		changed := false
+		for i, point := range points {
+			best, bestScore := 0, float32(math.Inf(-1))
+			for c, centroid := range centroids {
+				if score := dot(point, centroid); score > bestScore {
+					best, bestScore = c, score
+				}
+			}
+			if assignments[i] != best {
+				assignments[i] = best
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+
+		members := make([][][]float32, k)
+		for i, point := range points {
+			members[assignments[i]] = append(members[assignments[i]], point)
+		}
+		for c := range centroids {
+			if len(members[c]) > 0 {
+				centroids[c] = averageEmbeddings(members[c])
+			}
+		}
+	}
+	return assignments, centroids, nil
+}
+
+// dot returns the dot product of two vectors, their cosine similarity when both are unit length
+func dot(a, b []float32) float32 {
+	var sum float32
+	for i := range a[:min(len(a), len(b))] {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
+
+// topTags returns the most common tags among the notes, most common first, ties alphabetically
+func topTags(notes []models.Note, limit int) []string {
+	counts := make(map[string]int)
+	for _, note := range notes {
+		for _, tag := range note.Tags {
+			counts[tag]++
+		}
+	}
+	tags := make([]string, 0, len(counts))
+	for tag := range counts {
+		tags = append(tags, tag)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if counts[tags[i]] != counts[tags[j]] {
+			return counts[tags[i]] > counts[tags[j]]
+		}
+		return tags[i] < tags[j]
+	})
+	return tags[:min(len(tags), limit)]
+}
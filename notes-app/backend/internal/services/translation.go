@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"regexp"
+	"strings"
+	"time"
+
+	"backend/internal/ai"
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// languageCodePattern matches ISO 639 language codes with an optional region or script, e.g. "pt-br"
+var languageCodePattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})?$`)
+
+// TranslationService translates notes into other languages
+type TranslationService struct {
+	notesRepo      *repository.NotesRepository
+	aiClient       ai.Client
+	webhookService *WebhookService
+	aiHistory      *AIHistoryService
+	cfg            *config.Config
+}
+
+// NewTranslationService creates a new TranslationService
+func NewTranslationService(notesRepo *repository.NotesRepository, aiClient ai.Client, webhookService *WebhookService, aiHistory *AIHistoryService, cfg *config.Config) *TranslationService {
+	return &TranslationService{
+		notesRepo:      notesRepo,
+		aiClient:       aiClient,
+		webhookService: webhookService,
+		aiHistory:      aiHistory,
+		cfg:            cfg,
+	}
+}
+
+// TranslateNote translates a note's content and summary into the given language
+// With save, the translation is also kept in the note's structuredData.translations under the
+// language, replacing an earlier one. Summaries regenerated with a schema replace structuredData,
+// translations included, so those are made again once the summary changes.
+func (s *TranslationService) TranslateNote(ctx context.Context, noteID, language string, save bool) (*models.NoteTranslation, error) {
+	objID, err := primitive.ObjectIDFromHex(noteID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid note ID: %w", err)
+	}
+	language = strings.ToLower(strings.TrimSpace(language))
+	if !languageCodePattern.MatchString(language) {
+		return nil, fmt.Errorf("invalid language: lang must be a language code such as fr or pt-br")
+	}
+	if s.cfg.PrivacyMode {
+		return nil, fmt.Errorf("invalid request: notes are not sent for translation in privacy mode")
+	}
+
+	note, err := s.notesRepo.FindByID(ctx, objID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("note not found")
+		}
+		return nil, fmt.Errorf("failed to find note: %w", err)
+	}
+
+	translation, err := s.aiClient.TranslateNote(note.Content, note.Summary, language)
+	s.aiHistory.Record(ctx, objID, AICall{Operation: models.AIOperationTranslation, Content: note.Content + "\n\n" + note.Summary, Output: translation, Err: err})
+	if err != nil {
+		return nil, err
+	}
+	translation.Language = language
+	translation.Model = s.cfg.GenerationModel
+	translation.TranslatedAt = time.Now()
+	if !save {
+		return translation, nil
+	}
+
+	structuredData := maps.Clone(note.StructuredData)
+	if structuredData == nil {
+		structuredData = map[string]interface{}{}
+	}
+	translations, _ := structuredData["translations"].(map[string]interface{})
+	translations = maps.Clone(translations)
+	if translations == nil {
+		translations = map[string]interface{}{}
+	}
+	translations[language] = map[string]interface{}{
+		"content":      translation.Content,
+		"summary":      translation.Summary,
+		"model":        translation.Model,
+		"translatedAt": translation.TranslatedAt,
+	}
+	structuredData["translations"] = translations
+	if err := utils.CheckStructuredData(structuredData, config.MAX_STRUCTURED_DATA_BYTES, config.MAX_STRUCTURED_DATA_DEPTH); err != nil {
+		return nil, fmt.Errorf("translation too large to save: %w", err)
+	}
+
+	if err := s.notesRepo.Update(ctx, objID, bson.M{"$set": bson.M{"structured_data": structuredData}}); err != nil {
+		return nil, fmt.Errorf("failed to save translation: %w", err)
+	}
+	s.webhookService.Emit(ctx, models.NoteEventUpdated, objID, map[string]interface{}{"structuredData": structuredData})
+
+	translation.Saved = true
+	return translation, nil
+}
@@ -0,0 +1,299 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"backend/internal/ai"
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/pdf"
+	"backend/internal/repository"
+	"backend/internal/utils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+)
+
+// UploadService creates notes from uploaded documents, keeping the original files
+type UploadService struct {
+	notesRepo    *repository.NotesRepository
+	filesRepo    *repository.FilesRepository
+	notesService *NotesService
+	aiClient     ai.Client
+}
+
+// NewUploadService creates a new UploadService
+func NewUploadService(notesRepo *repository.NotesRepository, filesRepo *repository.FilesRepository, notesService *NotesService, aiClient ai.Client) *UploadService {
+	return &UploadService{
+		notesRepo:    notesRepo,
+		filesRepo:    filesRepo,
+		notesService: notesService,
+		aiClient:     aiClient,
+	}
+}
+
+// UploadResult holds the result of uploading a document
+type UploadResult struct {
+	Upload    *models.UploadResponse
+	Duplicate bool
+	FileHash  string
+}
+
+// pageRange is a run of pages that becomes one note
+type pageRange struct {
+	first, last int
+	content     string
+}
+
+// Upload creates notes from an uploaded PDF or image, telling them apart by their content
+func (s *UploadService) Upload(ctx context.Context, fileName string, data []byte, tags []string) (*UploadResult, error) {
+	if mimeType := imageType(data); mimeType != "" {
+		return s.UploadImage(ctx, fileName, mimeType, data, tags)
+	}
+	return s.UploadPDF(ctx, fileName, data, tags)
+}
+
+// UploadPDF extracts the text of a PDF page by page and creates notes from it
+// Each page starts with a "[Page N]" line so the chunks embedded from it cite their page. PDFs
+// over PDF_NOTE_MAX_WORDS are split into several notes at page boundaries. A file already
+// uploaded, by content hash, is reported as a duplicate.
+func (s *UploadService) UploadPDF(ctx context.Context, fileName string, data []byte, tags []string) (*UploadResult, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n"), []byte("%PDF-")) {
+		return nil, fmt.Errorf("invalid file: only PDFs and PNG, JPEG, WebP or HEIC images can be uploaded")
+	}
+
+	hash, duplicate, err := s.checkDuplicate(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	if duplicate {
+		return &UploadResult{Duplicate: true, FileHash: hash}, nil
+	}
+
+	pages, err := pdf.ExtractPages(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PDF: %w", err)
+	}
+	ranges := splitPageRanges(pages, config.PDF_NOTE_MAX_WORDS)
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("invalid PDF: no extractable text, scanned pages need OCR first")
+	}
+
+	fileID, err := s.storeFile(ctx, fileName, "application/pdf", hash, data)
+	if err != nil {
+		return nil, err
+	}
+
+	title := strings.TrimSuffix(filepath.Base(fileName), filepath.Ext(fileName))
+	upload := &models.UploadResponse{FileID: fileID, FileName: fileName, Pages: len(pages), Notes: []models.Note{}}
+	for i, r := range ranges {
+		metadata := map[string]interface{}{
+			"source":       "pdf-upload",
+			"content_type": "application/pdf",
+			"file_id":      fileID.Hex(),
+			"file_name":    fileName,
+			"file_hash":    hash,
+			"page_count":   len(pages),
+			"page_start":   r.first,
+			"page_end":     r.last,
+		}
+		req := &models.CreateNoteRequest{Title: title, Content: r.content, Tags: tags, Metadata: metadata}
+		if len(ranges) > 1 {
+			metadata["part"] = i + 1
+			metadata["parts"] = len(ranges)
+			if title != "" {
+				req.Title = fmt.Sprintf("%s (pages %d-%d)", title, r.first, r.last)
+			}
+		}
+
+		result, err := s.notesService.CreateNote(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create note for pages %d-%d: %w", r.first, r.last, err)
+		}
+		upload.Notes = append(upload.Notes, *result.Note)
+	}
+
+	log.Printf("Uploaded %s: %d pages as %d notes", fileName, len(pages), len(upload.Notes))
+	return &UploadResult{Upload: upload, FileHash: hash}, nil
+}
+
+// UploadImage creates a note from the text and description the AI extracts from an image,
+// keeping the image as the note's file
+// The title is left to the AI, since photo file names rarely describe their content.
+func (s *UploadService) UploadImage(ctx context.Context, fileName, mimeType string, data []byte, tags []string) (*UploadResult, error) {
+	if len(data) > config.MAX_IMAGE_UPLOAD_BYTES {
+		return nil, fmt.Errorf("invalid image: images are limited to %d MB", config.MAX_IMAGE_UPLOAD_BYTES>>20)
+	}
+
+	hash, duplicate, err := s.checkDuplicate(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	if duplicate {
+		return &UploadResult{Duplicate: true, FileHash: hash}, nil
+	}
+
+	extraction, err := s.aiClient.ExtractImage(data, mimeType)
+	if err != nil {
+		if errors.Is(err, ai.ErrContentBlocked) {
+			return nil, fmt.Errorf("invalid image: %w", err)
+		}
+		return nil, fmt.Errorf("failed to extract image content: %w", err)
+	}
+	content := imageNoteContent(extraction)
+	if content == "" {
+		return nil, fmt.Errorf("invalid image: no text or content could be extracted")
+	}
+
+	fileID, err := s.storeFile(ctx, fileName, mimeType, hash, data)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := map[string]interface{}{
+		"source":       "image-upload",
+		"content_type": mimeType,
+		"file_id":      fileID.Hex(),
+		"file_name":    fileName,
+		"file_hash":    hash,
+	}
+	result, err := s.notesService.CreateNote(ctx, &models.CreateNoteRequest{Content: content, Tags: tags, Metadata: metadata})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create note: %w", err)
+	}
+
+	log.Printf("Uploaded image %s as note %s", fileName, result.Note.ID.Hex())
+	upload := &models.UploadResponse{FileID: fileID, FileName: fileName, Notes: []models.Note{*result.Note}}
+	return &UploadResult{Upload: upload, FileHash: hash}, nil
+}
+
+// imageNoteContent puts an image's description first, followed by the text read off it
+func imageNoteContent(extraction *models.ImageExtraction) string {
+	switch {
+	case extraction.Text == "":
+		return extraction.Description
+	case extraction.Description == "":
+		return extraction.Text
+	}
+	return extraction.Description + "\n\nText in the image:\n" + extraction.Text
+}
+
+// imageType returns the MIME type of the image formats the AI can read, or "" for anything else
+func imageType(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return "image/png"
+	case bytes.HasPrefix(data, []byte("\xff\xd8\xff")):
+		return "image/jpeg"
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return "image/webp"
+	case len(data) >= 12 && string(data[4:8]) == "ftyp":
+		// HEIF images, as taken by iPhones, are ISO media files branded by their major brand
+		switch string(data[8:12]) {
+		case "heic", "heix", "heim", "heis":
+			return "image/heic"
+		case "mif1", "msf1", "heif":
+			return "image/heif"
+		}
+	}
+	return ""
+}
+
+// checkDuplicate hashes an uploaded file, reporting whether notes were already created from it
+func (s *UploadService) checkDuplicate(ctx context.Context, data []byte) (string, bool, error) {
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	exists, err := s.notesRepo.ExistsByFileHash(ctx, hash)
+	if err != nil {
+		return hash, false, fmt.Errorf("failed to check for duplicates: %w", err)
+	}
+	return hash, exists, nil
+}
+
+// storeFile keeps the original of an uploaded file
+// A file stored by an earlier upload whose notes were since deleted is reused.
+func (s *UploadService) storeFile(ctx context.Context, fileName, contentType, hash string, data []byte) (primitive.ObjectID, error) {
+	fileID, err := s.filesRepo.FindIDByHash(ctx, hash)
+	if err == mongo.ErrNoDocuments {
+		fileID, err = s.filesRepo.Upload(fileName, contentType, hash, data)
+	}
+	if err != nil {
+		return primitive.NilObjectID, fmt.Errorf("failed to store file: %w", err)
+	}
+	return fileID, nil
+}
+
+// splitPageRanges groups pages into notes of at most maxWords, marking where each page starts
+// Pages without text are skipped; a single page over the limit becomes a note of its own.
+func splitPageRanges(pages []string, maxWords int) []pageRange {
+	var ranges []pageRange
+	var current *pageRange
+	words := 0
+	for i, text := range pages {
+		pageWords := len(strings.Fields(text))
+		if pageWords == 0 {
+			continue
+		}
+		if current != nil && words+pageWords > maxWords {
+			ranges = append(ranges, *current)
+			current = nil
+		}
+		section := utils.PageMarker(i+1) + "\n" + text
+		if current == nil {
+			current = &pageRange{first: i + 1, content: section}
+			words = 0
+		} else {
+			current.content += "\n\n" + section
+		}
+		current.last = i + 1
+		words += pageWords
+	}
+	if current != nil {
+		ranges = append(ranges, *current)
+	}
+	return ranges
+}
+
+// OpenNoteFile returns the original uploaded file of a note, along with its content type;
+// the caller closes the stream
+func (s *UploadService) OpenNoteFile(ctx context.Context, noteID string) (*gridfs.DownloadStream, string, error) {
+	objID, err := primitive.ObjectIDFromHex(noteID)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid note ID: %w", err)
+	}
+	note, err := s.notesRepo.FindByID(ctx, objID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, "", fmt.Errorf("note not found")
+		}
+		return nil, "", fmt.Errorf("failed to find note: %w", err)
+	}
+
+	fileIDHex, _ := note.Metadata["file_id"].(string)
+	fileID, err := primitive.ObjectIDFromHex(fileIDHex)
+	if err != nil {
+		return nil, "", fmt.Errorf("file not found: the note was not created from an upload")
+	}
+	stream, err := s.filesRepo.Open(fileID)
+	if err != nil {
+		if errors.Is(err, gridfs.ErrFileNotFound) {
+			return nil, "", fmt.Errorf("file not found")
+		}
+		return nil, "", fmt.Errorf("failed to open file: %w", err)
+	}
+
+	contentType, _ := note.Metadata["content_type"].(string)
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return stream, contentType, nil
+}
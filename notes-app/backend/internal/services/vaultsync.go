@@ -0,0 +1,264 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path"
+	"strings"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// VaultSyncService keeps an Obsidian vault and the notes in step
+// Every note maps to one Markdown file whose content is the note content, identified by its path in
+// metadata.vault_path, assigned to notes written in the app by AssignPaths. The plugin compares the manifest hashes against the hashes it last synced to
+// decide what to pull and push; pushes carry that base hash so concurrent edits become conflicts
+// instead of silently overwriting each other.
+type VaultSyncService struct {
+	notesRepo    *repository.NotesRepository
+	notesService *NotesService
+}
+
+// NewVaultSyncService creates a new VaultSyncService
+func NewVaultSyncService(notesRepo *repository.NotesRepository, notesService *NotesService) *VaultSyncService {
+	return &VaultSyncService{
+		notesRepo:    notesRepo,
+		notesService: notesService,
+	}
+}
+
+// Manifest lists every note with a vault path as a vault file
+func (s *VaultSyncService) Manifest(ctx context.Context) (*models.VaultManifest, error) {
+	manifest := &models.VaultManifest{Files: []models.VaultFileInfo{}}
+	opts := options.Find().SetProjection(bson.M{"content": 1, "metadata.vault_path": 1})
+	err := s.notesRepo.ForEach(ctx, bson.M{"metadata.vault_path": bson.M{"$exists": true}}, func(note *models.Note) error {
+		if vaultPath, ok := note.Metadata["vault_path"].(string); ok {
+			manifest.Files = append(manifest.Files, models.VaultFileInfo{
+				Path:   vaultPath,
+				Hash:   utils.ContentHash(note.Content),
+				NoteID: note.ID.Hex(),
+			})
+		}
+		return nil
+	}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load notes: %w", err)
+	}
+	return manifest, nil
+}
+
+// AssignPaths gives every note that was never synced a vault path named after its title, which is
+// saved so it stays the same on later syncs. Returns the number of notes given a path.
+func (s *VaultSyncService) AssignPaths(ctx context.Context) (int, error) {
+	var used map[string]bool
+	assigned := 0
+	unsynced := bson.M{"metadata.vault_path": bson.M{"$exists": false}}
+	err := s.notesRepo.ForEach(ctx, unsynced, func(note *models.Note) error {
+		// The paths in use are only loaded once there is a note to name
+		if used == nil {
+			var err error
+			if used, err = s.usedPaths(ctx); err != nil {
+				return err
+			}
+		}
+		if err := s.setVaultPath(ctx, note, markdownFileName(note, used)); err != nil {
+			return err
+		}
+		assigned++
+		return nil
+	}, options.Find().SetProjection(bson.M{"title": 1, "metadata": 1}))
+	if err != nil {
+		return assigned, fmt.Errorf("failed to assign vault paths: %w", err)
+	}
+	return assigned, nil
+}
+
+// usedPaths loads the vault paths already assigned to notes
+func (s *VaultSyncService) usedPaths(ctx context.Context) (map[string]bool, error) {
+	used := make(map[string]bool)
+	opts := options.Find().SetProjection(bson.M{"metadata.vault_path": 1})
+	err := s.notesRepo.ForEach(ctx, bson.M{"metadata.vault_path": bson.M{"$exists": true}}, func(note *models.Note) error {
+		if vaultPath, ok := note.Metadata["vault_path"].(string); ok {
+			used[vaultPath] = true
+		}
+		return nil
+	}, opts)
+	return used, err
+}
+
+// Pull returns the content of the requested vault files
+func (s *VaultSyncService) Pull(ctx context.Context, req *models.VaultPullRequest) (*models.VaultPullResponse, error) {
+	if len(req.Paths) > config.MAX_VAULT_SYNC_FILES {
+		return nil, fmt.Errorf("invalid sync request: at most %d paths per pull", config.MAX_VAULT_SYNC_FILES)
+	}
+
+	byPath, err := s.notesByPath(ctx, req.Paths)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.VaultPullResponse{Files: []models.VaultFile{}, Missing: []string{}}
+	for _, vaultPath := range req.Paths {
+		note, ok := byPath[vaultPath]
+		if !ok {
+			response.Missing = append(response.Missing, vaultPath)
+			continue
+		}
+		response.Files = append(response.Files, models.VaultFile{
+			Path:    vaultPath,
+			Content: note.Content,
+			Hash:    utils.ContentHash(note.Content),
+		})
+	}
+	return response, nil
+}
+
+// Push applies files changed in the vault
+// A file is only written or deleted when its base hash matches the server's copy, so edits made
+// on the server since the vault last synced are reported as conflicts rather than lost. Files are
+// applied one by one; a failure is reported for that file without stopping the rest. Notes written
+// in the app since the last push are then given paths, so the next manifest lists them.
+func (s *VaultSyncService) Push(ctx context.Context, req *models.VaultPushRequest) (*models.VaultPushResponse, error) {
+	if len(req.Files) > config.MAX_VAULT_SYNC_FILES {
+		return nil, fmt.Errorf("invalid sync request: at most %d files per push", config.MAX_VAULT_SYNC_FILES)
+	}
+	paths := make([]string, 0, len(req.Files))
+	seen := make(map[string]bool, len(req.Files))
+	for _, file := range req.Files {
+		if err := validateVaultPath(file.Path); err != nil {
+			return nil, err
+		}
+		if seen[file.Path] {
+			return nil, fmt.Errorf("invalid sync request: %s is pushed more than once", file.Path)
+		}
+		seen[file.Path] = true
+		paths = append(paths, file.Path)
+	}
+
+	byPath, err := s.notesByPath(ctx, paths)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.VaultPushResponse{Results: make([]models.VaultPushResult, 0, len(req.Files))}
+	for i := range req.Files {
+		var existing *models.Note
+		if note, ok := byPath[req.Files[i].Path]; ok {
+			existing = &note
+		}
+		response.Results = append(response.Results, s.pushFile(ctx, &req.Files[i], existing))
+	}
+
+	// Assigned after the pushed files, so a new app note never takes the path of a new vault file
+	if _, err := s.AssignPaths(ctx); err != nil {
+		log.Printf("Failed to assign vault paths after push: %v", err)
+	}
+	return response, nil
+}
+
+// pushFile applies one pushed file against the note currently at its path, if any
+func (s *VaultSyncService) pushFile(ctx context.Context, file *models.VaultPushFile, existing *models.Note) models.VaultPushResult {
+	result := models.VaultPushResult{Path: file.Path}
+	failed := func(err error) models.VaultPushResult {
+		result.Status = models.VaultStatusFailed
+		result.Error = err.Error()
+		return result
+	}
+
+	var serverHash string
+	if existing != nil {
+		serverHash = utils.ContentHash(existing.Content)
+		result.Hash = serverHash
+		result.NoteID = existing.ID.Hex()
+	}
+
+	switch {
+	case file.Deleted && existing == nil:
+		result.Status = models.VaultStatusUnchanged
+	case file.Deleted && file.BaseHash != serverHash:
+		result.Status = models.VaultStatusConflict
+	case file.Deleted:
+		if err := s.notesService.DeleteNote(ctx, existing.ID.Hex()); err != nil {
+			return failed(err)
+		}
+		result.Status = models.VaultStatusDeleted
+		result.Hash = ""
+	case existing == nil:
+		created, err := s.notesService.CreateNote(ctx, &models.CreateNoteRequest{
+			Title:   strings.TrimSuffix(path.Base(file.Path), path.Ext(file.Path)),
+			Content: file.Content,
+			Metadata: map[string]interface{}{
+				"source":     "obsidian",
+				"vault_path": file.Path,
+			},
+		})
+		if err != nil {
+			return failed(err)
+		}
+		result.Status = models.VaultStatusCreated
+		result.Hash = utils.ContentHash(created.Note.Content)
+		result.NoteID = created.Note.ID.Hex()
+	case utils.ContentHash(file.Content) == serverHash:
+		result.Status = models.VaultStatusUnchanged
+	case file.BaseHash != serverHash:
+		result.Status = models.VaultStatusConflict
+	default:
+		// The title is kept: vault file names are slugs, and regenerating it would rename the note
+		updated, err := s.notesService.UpdateNote(ctx, existing.ID.Hex(), &models.UpdateNoteRequest{
+			Content: file.Content,
+			Title:   existing.Title,
+		})
+		if err != nil {
+			return failed(err)
+		}
+		result.Status = models.VaultStatusUpdated
+		result.Hash = utils.ContentHash(updated.Content)
+	}
+	return result
+}
+
+// notesByPath loads the notes at the given vault paths, keyed by path
+func (s *VaultSyncService) notesByPath(ctx context.Context, paths []string) (map[string]models.Note, error) {
+	notes, err := s.notesRepo.FindByVaultPaths(ctx, paths)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load notes: %w", err)
+	}
+	byPath := make(map[string]models.Note, len(notes))
+	for _, note := range notes {
+		if vaultPath, ok := note.Metadata["vault_path"].(string); ok {
+			byPath[vaultPath] = note
+		}
+	}
+	return byPath, nil
+}
+
+// setVaultPath saves the vault path assigned to a note
+func (s *VaultSyncService) setVaultPath(ctx context.Context, note *models.Note, vaultPath string) error {
+	set := bson.M{"metadata.vault_path": vaultPath}
+	if note.Metadata == nil {
+		set = bson.M{"metadata": bson.M{"vault_path": vaultPath}}
+	}
+	if err := s.notesRepo.Update(ctx, note.ID, bson.M{"$set": set}); err != nil {
+		return fmt.Errorf("failed to assign vault path to note %s: %w", note.ID.Hex(), err)
+	}
+	return nil
+}
+
+// validateVaultPath checks that a path is a clean, relative Markdown file path inside the vault
+func validateVaultPath(vaultPath string) error {
+	if vaultPath == "" || path.IsAbs(vaultPath) || path.Clean(vaultPath) != vaultPath ||
+		vaultPath == ".." || strings.HasPrefix(vaultPath, "../") {
+		return fmt.Errorf("invalid sync request: %q is not a relative vault path", vaultPath)
+	}
+	if !strings.EqualFold(path.Ext(vaultPath), ".md") {
+		return fmt.Errorf("invalid sync request: %q is not a Markdown file", vaultPath)
+	}
+	return nil
+}
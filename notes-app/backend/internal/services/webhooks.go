@@ -0,0 +1,576 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/utils"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// WebhookService records note events and delivers them to registered webhooks
+// Events are logged before delivery, so webhooks that were offline catch up in order once their
+// endpoint recovers, and any consumer can replay the log from a cursor.
+type WebhookService struct {
+	eventsRepo   *repository.NoteEventsRepository
+	webhooksRepo *repository.WebhooksRepository
+	secretsRepo  *repository.WebhookSecretsRepository
+	client       *http.Client // Shared by webhooks without a client certificate or CA of their own
+	checkAddress utils.AddressCheck
+
+	mu          sync.Mutex // Serializes delivery passes and cursor rewinds
+	notify      chan struct{}
+	stop        chan struct{}
+	wg          sync.WaitGroup
+	subscribers []func(models.NoteEvent)
+}
+
+// NewWebhookService creates a new WebhookService delivering only to addresses checkAddress allows;
+// pass utils.PublicAddress, since anyone can register a webhook
+func NewWebhookService(eventsRepo *repository.NoteEventsRepository, webhooksRepo *repository.WebhooksRepository, secretsRepo *repository.WebhookSecretsRepository, checkAddress utils.AddressCheck) *WebhookService {
+	return &WebhookService{
+		eventsRepo:   eventsRepo,
+		webhooksRepo: webhooksRepo,
+		secretsRepo:  secretsRepo,
+		client:       utils.PublicHTTPClient(config.WEBHOOK_TIMEOUT_SECONDS*time.Second, checkAddress),
+		checkAddress: checkAddress,
+		notify:       make(chan struct{}, 1),
+	}
+}
+
+// Start launches the delivery loop, which runs on every new event and retries failed webhooks periodically
+func (s *WebhookService) Start() {
+	s.stop = make(chan struct{})
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(config.WEBHOOK_RETRY_SECONDS * time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.notify:
+			case <-ticker.C:
+			case <-s.stop:
+				return
+			}
+			s.deliverAll(context.Background())
+		}
+	}()
+	log.Println("Started webhook delivery")
+}
+
+// Stop ends the delivery loop and waits for an in-progress pass to finish
+func (s *WebhookService) Stop() {
+	if s.stop == nil {
+		return
+	}
+	close(s.stop)
+	s.wg.Wait()
+}
+
+// Subscribe registers fn to receive every emitted event in-process, whether or not logging it succeeded
+// fn runs on the emitting request's goroutine, so it must not block. Subscribe before serving requests.
+func (s *WebhookService) Subscribe(fn func(models.NoteEvent)) {
+	s.subscribers = append(s.subscribers, fn)
+}
+
+// Emit logs a note event, passes it to subscribers and wakes the delivery loop
+// Event logging is best-effort: failures are logged without failing the note operation.
+// A nil service ignores events, so callers without webhooks configured need no checks.
+func (s *WebhookService) Emit(ctx context.Context, eventType string, noteID primitive.ObjectID, delta map[string]interface{}) {
+	if s == nil {
+		return
+	}
+
+	event := &models.NoteEvent{
+		Type:      eventType,
+		NoteID:    noteID,
+		Delta:     delta,
+		CreatedAt: time.Now(),
+	}
+	id, err := s.eventsRepo.Create(ctx, event)
+	event.ID = id
+	for _, fn := range s.subscribers {
+		fn(*event)
+	}
+	if err != nil {
+		log.Printf("Failed to log %s event for note %s: %v", eventType, noteID.Hex(), err)
+		return
+	}
+
+	select {
+	case s.notify <- struct{}{}:
+	default: // A pass is already pending
+	}
+}
+
+// CreateWebhook registers a webhook that receives events logged from now on
+// The secret signs every delivery and is returned only here; one is generated when not given.
+// The secret and the credentials of req.Auth are stored apart from the webhook.
+func (s *WebhookService) CreateWebhook(ctx context.Context, req *models.CreateWebhookRequest) (*models.Webhook, error) {
+	parsed, err := url.Parse(req.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid webhook: url must be an absolute http(s) URL")
+	}
+	if err := s.checkHost(ctx, parsed); err != nil {
+		return nil, err
+	}
+	for _, eventType := range req.EventTypes {
+		if !isNoteEventType(eventType) {
+			return nil, fmt.Errorf("invalid webhook: unknown event type %q", eventType)
+		}
+	}
+	auth := req.Auth
+	if auth == nil {
+		auth = &models.WebhookAuth{}
+	}
+	if err := validWebhookAuth(auth, parsed); err != nil {
+		return nil, err
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+		}
+		secret = hex.EncodeToString(buf)
+	}
+
+	webhook := &models.Webhook{
+		URL:        req.URL,
+		EventTypes: req.EventTypes,
+		Cursor:     primitive.NewObjectID(), // Events are logged with later IDs
+		CreatedAt:  time.Now(),
+		AuthType:   auth.Type,
+	}
+	id, err := s.webhooksRepo.Create(ctx, webhook)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+	webhook.ID = id
+
+	if err := s.secretsRepo.Save(ctx, webhookSecrets(id, secret, auth)); err != nil {
+		// A webhook without its secret couldn't sign deliveries, so it isn't kept
+		if _, deleteErr := s.webhooksRepo.Delete(ctx, id); deleteErr != nil {
+			log.Printf("Failed to remove webhook %s left without secrets: %v", id.Hex(), deleteErr)
+		}
+		return nil, fmt.Errorf("failed to save webhook secrets: %w", err)
+	}
+	webhook.Secret = secret
+
+	return webhook, nil
+}
+
+// UpdateAuth replaces how a webhook's deliveries authenticate, e.g. to rotate its bearer token or
+// client certificate; the signing secret is kept
+func (s *WebhookService) UpdateAuth(ctx context.Context, id string, auth *models.WebhookAuth) (*models.Webhook, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook ID: %w", err)
+	}
+	webhook, err := s.webhooksRepo.FindByID(ctx, objID)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("webhook not found")
+		}
+		return nil, fmt.Errorf("failed to find webhook: %w", err)
+	}
+	endpoint, err := url.Parse(webhook.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse webhook url: %w", err)
+	}
+	if err := s.checkHost(ctx, endpoint); err != nil {
+		return nil, err
+	}
+	if err := validWebhookAuth(auth, endpoint); err != nil {
+		return nil, err
+	}
+
+	// Serialized with delivery passes, so no pass mixes the old and new credentials
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, err := s.secretsRepo.FindByWebhook(ctx, objID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load webhook secrets: %w", err)
+	}
+	secret := webhook.Secret
+	if existing != nil {
+		secret = existing.Secret
+	}
+	if err := s.secretsRepo.Save(ctx, webhookSecrets(objID, secret, auth)); err != nil {
+		return nil, fmt.Errorf("failed to save webhook secrets: %w", err)
+	}
+	if _, err := s.webhooksRepo.SetAuthType(ctx, objID, auth.Type); err != nil {
+		return nil, fmt.Errorf("failed to update webhook: %w", err)
+	}
+
+	webhook.AuthType = auth.Type
+	webhook.Secret = ""
+	return webhook, nil
+}
+
+// GetWebhooks returns all webhooks with their delivery state; secrets are not included
+func (s *WebhookService) GetWebhooks(ctx context.Context) ([]models.Webhook, error) {
+	webhooks, err := s.webhooksRepo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+	return webhooks, nil
+}
+
+// DeleteWebhook removes a webhook
+func (s *WebhookService) DeleteWebhook(ctx context.Context, id string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid webhook ID: %w", err)
+	}
+
+	deleted, err := s.webhooksRepo.Delete(ctx, objID)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+	if deleted == 0 {
+		return fmt.Errorf("webhook not found")
+	}
+	if err := s.secretsRepo.Delete(ctx, objID); err != nil {
+		log.Printf("Failed to delete secrets of webhook %s: %v", id, err)
+	}
+	return nil
+}
+
+// Replay rewinds a webhook to a cursor so every later event is delivered again
+func (s *WebhookService) Replay(ctx context.Context, id, cursor string) error {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid webhook ID: %w", err)
+	}
+	from, err := parseEventCursor(cursor)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	found, err := s.webhooksRepo.SetCursor(ctx, objID, from, nil)
+	s.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to rewind webhook: %w", err)
+	}
+	if !found {
+		return fmt.Errorf("webhook not found")
+	}
+
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// GetEvents returns logged events after a cursor, oldest first, for consumers that pull instead
+func (s *WebhookService) GetEvents(ctx context.Context, cursor string, limit int) ([]models.NoteEvent, error) {
+	from, err := parseEventCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	return s.eventsRepo.FindAfter(ctx, from, limit)
+}
+
+// deliverAll sends pending events to every webhook
+func (s *WebhookService) deliverAll(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	webhooks, err := s.webhooksRepo.FindAll(ctx)
+	if err != nil {
+		log.Printf("Failed to load webhooks: %v", err)
+		return
+	}
+	for i := range webhooks {
+		s.deliverPending(ctx, &webhooks[i])
+	}
+}
+
+// deliverPending sends a webhook's events in order, stopping at the first failure so nothing is skipped
+func (s *WebhookService) deliverPending(ctx context.Context, webhook *models.Webhook) {
+	var secrets *models.WebhookSecrets
+	var client *http.Client
+	for {
+		events, err := s.eventsRepo.FindAfter(ctx, webhook.Cursor, config.WEBHOOK_BATCH_SIZE)
+		if err != nil {
+			log.Printf("Failed to load events for webhook %s: %v", webhook.ID.Hex(), err)
+			return
+		}
+		if len(events) == 0 {
+			return
+		}
+
+		// Credentials are only loaded for webhooks with events to deliver
+		if client == nil {
+			if secrets, client, err = s.credentials(ctx, webhook); err != nil {
+				log.Printf("Webhook %s can't be delivered to: %v", webhook.ID.Hex(), err)
+				if err := s.webhooksRepo.SetError(ctx, webhook.ID, err.Error()); err != nil {
+					log.Printf("Failed to record webhook error: %v", err)
+				}
+				return
+			}
+			if client != s.client {
+				defer client.CloseIdleConnections()
+			}
+		}
+
+		cursor := webhook.Cursor
+		var deliveryErr error
+		for _, event := range events {
+			if webhookWants(webhook, event.Type) {
+				if deliveryErr = s.send(ctx, client, webhook, secrets, &event); deliveryErr != nil {
+					break
+				}
+			}
+			cursor = event.ID
+		}
+
+		if cursor != webhook.Cursor {
+			now := time.Now()
+			if _, err := s.webhooksRepo.SetCursor(ctx, webhook.ID, cursor, &now); err != nil {
+				log.Printf("Failed to save cursor for webhook %s: %v", webhook.ID.Hex(), err)
+				return
+			}
+			webhook.Cursor = cursor
+		}
+
+		if deliveryErr != nil {
+			log.Printf("Webhook %s delivery failed, retrying later: %v", webhook.ID.Hex(), deliveryErr)
+			if err := s.webhooksRepo.SetError(ctx, webhook.ID, deliveryErr.Error()); err != nil {
+				log.Printf("Failed to record webhook error: %v", err)
+			}
+			return
+		}
+	}
+}
+
+// credentials loads a webhook's secrets and the client its deliveries go through: the shared one,
+// or one presenting the webhook's client certificate and trusting its CA
+func (s *WebhookService) credentials(ctx context.Context, webhook *models.Webhook) (*models.WebhookSecrets, *http.Client, error) {
+	secrets, err := s.secretsRepo.FindByWebhook(ctx, webhook.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load webhook secrets: %w", err)
+	}
+	if secrets == nil { // Created before secrets were kept apart from webhooks
+		secrets = &models.WebhookSecrets{WebhookID: webhook.ID, Secret: webhook.Secret}
+	}
+
+	tlsConfig, err := webhookTLSConfig(secrets.ClientCert, secrets.ClientKey, secrets.CACert)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tlsConfig == nil {
+		return secrets, s.client, nil
+	}
+	transport := utils.PublicTransport(s.checkAddress)
+	transport.TLSClientConfig = tlsConfig
+	return secrets, &http.Client{Timeout: s.client.Timeout, Transport: transport}, nil
+}
+
+// send POSTs one event, signed with the webhook secret and carrying the webhook's bearer token
+// The X-Webhook-Signature header is "sha256=" followed by the hex HMAC-SHA256 of the body
+func (s *WebhookService) send(ctx context.Context, client *http.Client, webhook *models.Webhook, secrets *models.WebhookSecrets, event *models.NoteEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Event", event.Type)
+	req.Header.Set("X-Webhook-Delivery", event.ID.Hex())
+	req.Header.Set("X-Webhook-Signature", "sha256="+SignWebhookPayload(secrets.Secret, body))
+	if webhook.AuthType == models.WebhookAuthBearer {
+		req.Header.Set("Authorization", "Bearer "+secrets.Token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("endpoint returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SignWebhookPayload returns the hex HMAC-SHA256 of a payload, as sent in X-Webhook-Signature
+func SignWebhookPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// checkHost rejects webhook endpoints on addresses deliveries would be refused for, so a bad URL
+// fails when it is registered rather than on every delivery attempt
+func (s *WebhookService) checkHost(ctx context.Context, endpoint *url.URL) error {
+	if err := utils.CheckHost(ctx, endpoint.Hostname(), s.checkAddress); err != nil {
+		if errors.Is(err, utils.ErrNonPublicAddress) {
+			return fmt.Errorf("invalid webhook: url points to a non-public address")
+		}
+		return fmt.Errorf("invalid webhook: %w", err)
+	}
+	return nil
+}
+
+// validWebhookAuth checks a webhook's outbound auth suits its endpoint and cleans up its fields
+// An empty type is hmac. A CA certificate may be given with any type for an https endpoint.
+func validWebhookAuth(auth *models.WebhookAuth, endpoint *url.URL) error {
+	auth.Type = strings.ToLower(strings.TrimSpace(auth.Type))
+	auth.Token = strings.TrimSpace(auth.Token)
+	if auth.Type == "" {
+		auth.Type = models.WebhookAuthHMAC
+	}
+
+	switch auth.Type {
+	case models.WebhookAuthHMAC:
+		if auth.Token != "" || auth.ClientCert != "" || auth.ClientKey != "" {
+			return fmt.Errorf("invalid webhook: hmac auth takes no token or client certificate")
+		}
+	case models.WebhookAuthBearer:
+		if auth.Token == "" {
+			return fmt.Errorf("invalid webhook: bearer auth requires a token")
+		}
+		if auth.ClientCert != "" || auth.ClientKey != "" {
+			return fmt.Errorf("invalid webhook: bearer auth takes no client certificate")
+		}
+	case models.WebhookAuthMTLS:
+		if auth.ClientCert == "" || auth.ClientKey == "" {
+			return fmt.Errorf("invalid webhook: mtls auth requires a clientCert and clientKey")
+		}
+		if auth.Token != "" {
+			return fmt.Errorf("invalid webhook: mtls auth takes no token")
+		}
+	default:
+		return fmt.Errorf("invalid webhook: auth type must be hmac, bearer or mtls")
+	}
+
+	if endpoint.Scheme != "https" && (auth.ClientCert != "" || auth.CACert != "") {
+		return fmt.Errorf("invalid webhook: client and CA certificates require an https url")
+	}
+	if _, err := webhookTLSConfig(auth.ClientCert, auth.ClientKey, auth.CACert); err != nil {
+		return fmt.Errorf("invalid webhook: %w", err)
+	}
+	return nil
+}
+
+// webhookTLSConfig returns the TLS settings presenting a PEM client certificate and trusting PEM CA
+// certificates, or nil when neither is given
+func webhookTLSConfig(clientCert, clientKey, caCert string) (*tls.Config, error) {
+	if clientCert == "" && caCert == "" {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if clientCert != "" {
+		cert, err := tls.X509KeyPair([]byte(clientCert), []byte(clientKey))
+		if err != nil {
+			return nil, fmt.Errorf("bad client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	if caCert != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(caCert)) {
+			return nil, fmt.Errorf("caCert holds no PEM certificates")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
+}
+
+// webhookSecrets returns the secrets stored for a webhook with the given signing secret and auth
+func webhookSecrets(webhookID primitive.ObjectID, secret string, auth *models.WebhookAuth) *models.WebhookSecrets {
+	return &models.WebhookSecrets{
+		WebhookID:  webhookID,
+		Secret:     secret,
+		Token:      auth.Token,
+		ClientCert: auth.ClientCert,
+		ClientKey:  auth.ClientKey,
+		CACert:     auth.CACert,
+		UpdatedAt:  time.Now(),
+	}
+}
+
+// webhookWants reports whether a webhook subscribes to an event type
+func webhookWants(webhook *models.Webhook, eventType string) bool {
+	if len(webhook.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range webhook.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// isNoteEventType reports whether an event type is known
+func isNoteEventType(eventType string) bool {
+	for _, t := range models.NoteEventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// parseEventCursor parses an event ID cursor; empty means the start of the log
+func parseEventCursor(cursor string) (primitive.ObjectID, error) {
+	if cursor == "" {
+		return primitive.NilObjectID, nil
+	}
+	objID, err := primitive.ObjectIDFromHex(cursor)
+	if err != nil {
+		return primitive.NilObjectID, fmt.Errorf("invalid cursor: %w", err)
+	}
+	return objID, nil
+}
+
+// noteCreatedDelta is the delta of a note.created event: every field an indexer needs
+func noteCreatedDelta(note *models.Note) map[string]interface{} {
+	return map[string]interface{}{
+		"title":    note.Title,
+		"content":  note.Content,
+		"summary":  note.Summary,
+		"category": note.Category,
+		"tags":     note.Tags,
+		"created":  note.Created,
+		"metadata": note.Metadata,
+	}
+}
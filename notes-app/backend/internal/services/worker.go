@@ -1,10 +1,16 @@
 package services
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
 	"log"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"backend/internal/ai"
 	"backend/internal/config"
@@ -12,37 +18,75 @@ import (
 	"backend/internal/repository"
 	"backend/internal/utils"
 	"backend/internal/vectordb"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 // WorkerPool manages background job processing for note embeddings
+// Jobs are queued in MongoDB and claimed atomically, so pending work survives restarts, several
+// instances can share the queue and no job is dropped when the workers fall behind.
+// A note is processed by one job at a time, and a newer job for a note replaces the ones before it.
 type WorkerPool struct {
-	jobQueue     chan models.ProcessingJob
-	workerCount  int
-	wg           sync.WaitGroup
-	chunksRepo   *repository.ChunksRepository
-	aiClient     ai.Client
-	qdrantClient *vectordb.QdrantClient
+	jobsRepo       *repository.JobsRepository
+	deadLetterRepo *repository.DeadLetterRepository
+	notesRepo      *repository.NotesRepository
+	webhookService *WebhookService
+	workerCount    int
+	notify         chan struct{}
+	stop           chan struct{}
+	wg             sync.WaitGroup
+	chunksRepo     *repository.ChunksRepository
+	aiClient       ai.Client
+	qdrantClient   *vectordb.QdrantClient
+	cfg            *config.Config
+
+	channelSettingsRepo *repository.ChannelSettingsRepository // Channels' chunking hints
+
+	mu      sync.Mutex
+	running map[primitive.ObjectID]*noteRun // The job running for each note, so a note is processed by one job at a time
+}
+
+// noteRun is a job being processed for a note
+type noteRun struct {
+	job    models.ProcessingJob
+	cancel context.CancelFunc // Stops the job once a newer job supersedes it
+	done   chan struct{}      // Closed when the job has finished
 }
 
 // NewWorkerPool creates a new WorkerPool with the specified number of workers
 func NewWorkerPool(
 	workerCount int,
-	queueSize int,
+	jobsRepo *repository.JobsRepository,
+	deadLetterRepo *repository.DeadLetterRepository,
+	notesRepo *repository.NotesRepository,
+	webhookService *WebhookService,
 	chunksRepo *repository.ChunksRepository,
+	channelSettingsRepo *repository.ChannelSettingsRepository,
 	aiClient ai.Client,
 	qdrantClient *vectordb.QdrantClient,
+	cfg *config.Config,
 ) *WorkerPool {
 	return &WorkerPool{
-		jobQueue:     make(chan models.ProcessingJob, queueSize),
-		workerCount:  workerCount,
-		chunksRepo:   chunksRepo,
-		aiClient:     aiClient,
-		qdrantClient: qdrantClient,
+		jobsRepo:            jobsRepo,
+		deadLetterRepo:      deadLetterRepo,
+		notesRepo:           notesRepo,
+		webhookService:      webhookService,
+		workerCount:         workerCount,
+		notify:              make(chan struct{}, workerCount),
+		chunksRepo:          chunksRepo,
+		aiClient:            aiClient,
+		channelSettingsRepo: channelSettingsRepo,
+		qdrantClient:        qdrantClient,
+		cfg:                 cfg,
+		running:             make(map[primitive.ObjectID]*noteRun),
 	}
 }
 
-// Start launches the worker goroutines
+// Start launches the worker goroutines, which first pick up any jobs left from a previous run
 func (wp *WorkerPool) Start() {
+	wp.stop = make(chan struct{})
 	for i := 0; i < wp.workerCount; i++ {
 		wp.wg.Add(1)
 		go wp.worker()
@@ -50,82 +94,683 @@ func (wp *WorkerPool) Start() {
 	log.Printf("Started %d background workers", wp.workerCount)
 }
 
-// Stop gracefully shuts down the worker pool
+// Stop gracefully shuts down the worker pool, letting in-progress jobs finish
+// Jobs still queued stay in MongoDB for the next start.
 func (wp *WorkerPool) Stop() {
-	close(wp.jobQueue)
+	if wp.stop == nil {
+		return
+	}
+	close(wp.stop)
 	wp.wg.Wait()
 	log.Println("All background workers stopped")
 }
 
-// Submit adds a job to the queue
-// Returns true if the job was queued, false if the queue is full
-func (wp *WorkerPool) Submit(job models.ProcessingJob) bool {
+// errSuperseded fails a job whose note was queued for processing again since
+var errSuperseded = errors.New("superseded by a newer job")
+
+// Submit adds a job to the queue and wakes an idle worker
+// The note's earlier jobs the new one supersedes are dropped from the queue, or cancelled if running,
+// so rapid successive edits only process the latest content. A job re-embedding the whole note
+// starts a new processing generation of the note; workers drop jobs of an older generation.
+func (wp *WorkerPool) Submit(ctx context.Context, job models.ProcessingJob) error {
+	job.MaxAttempts = config.JOB_MAX_ATTEMPTS
+	var err error
+	if job.SummaryOnly {
+		job.Generation, err = wp.notesRepo.FindProcessingGeneration(ctx, job.NoteID)
+	} else {
+		job.Generation, err = wp.notesRepo.IncrementProcessingGeneration(ctx, job.NoteID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to start processing generation of note %s: %w", job.NoteID.Hex(), err)
+	}
+
+	if dropped, err := wp.jobsRepo.DeleteSuperseded(ctx, job.NoteID, job.SummaryOnly); err != nil {
+		log.Printf("Failed to drop superseded jobs for note %s: %v", job.NoteID.Hex(), err)
+	} else if dropped > 0 {
+		log.Printf("Dropped %d superseded jobs for note %s", dropped, job.NoteID.Hex())
+	}
+
+	// Marked before queueing so a worker claiming the job right away isn't overwritten
+	wp.updateProcessing(ctx, job.NoteID, models.ProcessingPending)
+	id, err := wp.jobsRepo.Enqueue(ctx, &job)
+	if err != nil {
+		return fmt.Errorf("failed to queue embedding job for note %s: %w", job.NoteID.Hex(), err)
+	}
+	job.ID = id
+	log.Printf("Queued embedding job for note: %s", job.NoteID.Hex())
+
+	wp.mu.Lock()
+	if run := wp.running[job.NoteID]; run != nil && supersedes(&job, &run.job) {
+		run.cancel()
+	}
+	wp.mu.Unlock()
+
+	wp.Wake()
+	return nil
+}
+
+// Wake prompts an idle worker to check the queue, e.g. after a job was requeued
+func (wp *WorkerPool) Wake() {
 	select {
-	case wp.jobQueue <- job:
-		log.Printf("Queued embedding job for note: %s", job.NoteID.Hex())
-		return true
-	default:
-		log.Printf("Job queue full, skipping embedding for note: %s", job.NoteID.Hex())
-		return false
+	case wp.notify <- struct{}{}:
+	default: // Every worker already has a wakeup pending
 	}
 }
 
-// worker processes jobs from the queue
+// worker claims and processes jobs until the queue is empty, then waits for a new job or the next poll
 func (wp *WorkerPool) worker() {
 	defer wp.wg.Done()
 
-	for job := range wp.jobQueue {
-		if err := wp.processJob(job); err != nil {
-			log.Printf("Error processing job for note %s: %v", job.NoteID.Hex(), err)
+	ticker := time.NewTicker(config.JOB_POLL_SECONDS * time.Second)
+	defer ticker.Stop()
+
+	for {
+		wp.drain()
+		select {
+		case <-wp.notify:
+		case <-ticker.C:
+		case <-wp.stop:
+			return
+		}
+	}
+}
+
+// drain processes claimable jobs one at a time until none are left or the pool stops
+// Failed jobs are retried with exponential backoff until they have used their MaxAttempts, then
+// move to the dead-letter queue.
+func (wp *WorkerPool) drain() {
+	ctx := context.Background()
+	for {
+		select {
+		case <-wp.stop:
+			return
+		default:
+		}
+
+		job, err := wp.jobsRepo.Claim(ctx, time.Now().Add(config.JOB_LEASE_SECONDS*time.Second))
+		if err != nil {
+			if !errors.Is(err, mongo.ErrNoDocuments) {
+				log.Printf("Failed to claim embedding job: %v", err)
+			}
+			return
+		}
+
+		// The lease is kept for as long as the job waits for its note and runs, however long that is
+		stopRenewing := wp.renewLease(job.ID, job.LeaseUntil)
+		jobCtx, release, ok := wp.acquireNote(ctx, job)
+		if !ok {
+			job.LeaseUntil = stopRenewing()
+			wp.completeSuperseded(ctx, job)
+			continue
+		}
+		wp.updateProcessing(ctx, job.NoteID, models.ProcessingRunning)
+		status, err := wp.processJob(jobCtx, *job)
+		superseded := jobCtx.Err() != nil || errors.Is(err, errSuperseded)
+		release()
+		job.LeaseUntil = stopRenewing()
+		if superseded {
+			wp.completeSuperseded(ctx, job)
+			continue
+		}
+		if err != nil {
+			log.Printf("Error processing job for note %s (attempt %d): %v", job.NoteID.Hex(), job.Attempts, err)
+			maxAttempts := job.MaxAttempts
+			if maxAttempts == 0 { // Queued before jobs stored their attempt limit
+				maxAttempts = config.JOB_MAX_ATTEMPTS
+			}
+			if job.Attempts >= maxAttempts {
+				job.LastError = err.Error()
+				err = wp.deadLetter(ctx, job)
+				wp.updateProcessing(ctx, job.NoteID, models.ProcessingFailed)
+			} else {
+				err = wp.jobsRepo.Retry(ctx, job.ID, job.LeaseUntil, err.Error(), time.Now().Add(jobRetryDelay(job.Attempts)))
+				wp.updateProcessing(ctx, job.NoteID, models.ProcessingPending)
+			}
+			if err != nil {
+				log.Printf("Failed to reschedule job for note %s: %v", job.NoteID.Hex(), err)
+			}
+			continue
+		}
+		wp.updateProcessing(ctx, job.NoteID, status)
+		if err := wp.jobsRepo.Complete(ctx, job.ID, job.LeaseUntil); err != nil {
+			log.Printf("Failed to remove completed job for note %s: %v", job.NoteID.Hex(), err)
+		}
+	}
+}
+
+// renewLease extends the lease of a claimed job every JOB_RENEW_SECONDS until the returned function
+// is called, which returns the job's current lease. A lease found taken over by another worker is
+// no longer renewed; the job's completion or retry then fails rather than touching the other claim.
+func (wp *WorkerPool) renewLease(jobID primitive.ObjectID, leaseUntil time.Time) func() time.Time {
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(config.JOB_RENEW_SECONDS * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+			// MongoDB keeps milliseconds, and the lease is matched exactly
+			renewed := time.Now().Add(config.JOB_LEASE_SECONDS * time.Second).Truncate(time.Millisecond)
+			err := wp.jobsRepo.ExtendLease(context.Background(), jobID, leaseUntil, renewed)
+			if errors.Is(err, mongo.ErrNoDocuments) {
+				log.Printf("Lease of job %s was taken over by another worker", jobID.Hex())
+				<-stop
+				return
+			}
+			if err != nil {
+				log.Printf("Failed to extend the lease of job %s: %v", jobID.Hex(), err)
+				continue
+			}
+			leaseUntil = renewed
+		}
+	}()
+
+	return func() time.Time {
+		close(stop)
+		<-done
+		return leaseUntil
+	}
+}
+
+// acquireNote waits until no other job is processing the job's note and registers the job as its
+// running one, returning a context cancelled once a newer job supersedes it and a function to call
+// when the job is done. It reports false, without registering, when the job is already superseded.
+func (wp *WorkerPool) acquireNote(ctx context.Context, job *models.ProcessingJob) (context.Context, func(), bool) {
+	for {
+		wp.mu.Lock()
+		run := wp.running[job.NoteID]
+		if run == nil {
+			break
+		}
+		if supersedes(&run.job, job) {
+			wp.mu.Unlock()
+			return nil, nil, false
+		}
+		if supersedes(job, &run.job) {
+			run.cancel()
 		}
+		wp.mu.Unlock()
+		<-run.done
+	}
+
+	jobCtx, cancel := context.WithCancel(ctx)
+	run := &noteRun{job: *job, cancel: cancel, done: make(chan struct{})}
+	wp.running[job.NoteID] = run
+	wp.mu.Unlock()
+
+	release := func() {
+		wp.mu.Lock()
+		delete(wp.running, job.NoteID)
+		wp.mu.Unlock()
+		cancel()
+		close(run.done)
+	}
+
+	// A newer job may be queued already, e.g. by another instance sharing the queue
+	if err := wp.checkGeneration(ctx, job); err != nil {
+		release()
+		return nil, nil, false
 	}
+	return jobCtx, release, true
 }
 
-// processJob handles the embedding generation for a single note
-func (wp *WorkerPool) processJob(job models.ProcessingJob) error {
-	// Note: Title, category, and summary are now generated during createNote()
-	// This job only handles embedding generation
+// checkGeneration returns errSuperseded when the job's note was deleted or queued for
+// processing again since the job was; failing to check is logged and lets the job run
+func (wp *WorkerPool) checkGeneration(ctx context.Context, job *models.ProcessingJob) error {
+	current, err := wp.notesRepo.FindProcessingGeneration(ctx, job.NoteID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return errSuperseded
+	}
+	if err != nil {
+		log.Printf("Failed to check the processing generation of note %s: %v", job.NoteID.Hex(), err)
+		return nil
+	}
+	if job.Generation < current {
+		return errSuperseded
+	}
+	return nil
+}
 
-	fullText := job.Title + "\n\n" + job.Content
+// completeSuperseded removes a job made redundant by a newer job for the same note, which records
+// the note's processing status instead
+func (wp *WorkerPool) completeSuperseded(ctx context.Context, job *models.ProcessingJob) {
+	log.Printf("Job for note %s was superseded by a newer job", job.NoteID.Hex())
+	if err := wp.jobsRepo.Complete(ctx, job.ID, job.LeaseUntil); err != nil {
+		log.Printf("Failed to remove superseded job for note %s: %v", job.NoteID.Hex(), err)
+	}
+}
+
+// supersedes reports whether job makes other redundant: it is newer and re-embeds at least as much
+func supersedes(job, other *models.ProcessingJob) bool {
+	return bytes.Compare(job.ID[:], other.ID[:]) > 0 && (!job.SummaryOnly || other.SummaryOnly)
+}
+
+// updateProcessing records a note's processing status along with how many of its chunks are embedded,
+// emitting a note.processed event once processing has finished
+// Failures are logged rather than returned: the status is informational and must not fail the job.
+func (wp *WorkerPool) updateProcessing(ctx context.Context, noteID primitive.ObjectID, status string) {
+	set := bson.M{"processing_status": status}
+	total, embedded, countErr := wp.chunksRepo.CountByNoteID(ctx, noteID)
+	if countErr != nil {
+		log.Printf("Failed to count chunks of note %s: %v", noteID.Hex(), countErr)
+	} else {
+		set["chunk_count"] = total
+		set["embedded_chunk_count"] = embedded
+	}
+
+	if err := wp.notesRepo.Update(ctx, noteID, bson.M{"$set": set}); err != nil {
+		log.Printf("Failed to update processing status of note %s: %v", noteID.Hex(), err)
+	}
+
+	switch status {
+	case models.ProcessingIndexed, models.ProcessingSkipped, models.ProcessingFailed:
+		delta := map[string]interface{}{"processingStatus": status}
+		if countErr == nil {
+			delta["chunkCount"] = total
+			delta["embeddedChunkCount"] = embedded
+		}
+		wp.webhookService.Emit(ctx, models.NoteEventProcessed, noteID, delta)
+	}
+}
+
+// deadLetter moves a job that failed its last attempt from the queue to the dead-letter queue
+// The copy is saved first, so a failure in between leaves the job in both rather than losing it.
+func (wp *WorkerPool) deadLetter(ctx context.Context, job *models.ProcessingJob) error {
+	leaseUntil := job.LeaseUntil
+	job.LeaseUntil = time.Time{}
+	if err := wp.deadLetterRepo.Save(ctx, &models.DeadLetterJob{ProcessingJob: *job, FailedAt: time.Now()}); err != nil {
+		return fmt.Errorf("failed to dead-letter job: %w", err)
+	}
+	log.Printf("Moved embedding job for note %s to the dead-letter queue after %d attempts", job.NoteID.Hex(), job.Attempts)
+	return wp.jobsRepo.Complete(ctx, job.ID, leaseUntil)
+}
+
+// jobRetryDelay returns the pause before retrying a job that failed its attempts-th attempt
+func jobRetryDelay(attempts int) time.Duration {
+	delay := config.JOB_RETRY_SECONDS * time.Second << (attempts - 1)
+	if delay <= 0 || delay > config.JOB_MAX_RETRY_SECONDS*time.Second {
+		return config.JOB_MAX_RETRY_SECONDS * time.Second
+	}
+	return delay
+}
+
+// retryChunk runs op up to CHUNK_RETRIES times, pausing CHUNK_RETRY_MS after the first failure
+// and doubling the pause after each further one, so brief Gemini or Qdrant hiccups don't fail the job
+func retryChunk(op func() error) error {
+	pause := config.CHUNK_RETRY_MS * time.Millisecond
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = op(); err == nil || attempt == config.CHUNK_RETRIES {
+			return err
+		}
+		time.Sleep(pause)
+		pause *= 2
+	}
+}
+
+// processJob runs a note's worker pipeline steps in order and returns the note's resulting
+// processing status; notes whose pipeline doesn't embed them are skipped
+func (wp *WorkerPool) processJob(ctx context.Context, job models.ProcessingJob) (string, error) {
+	// Note: Title, category, and summary are generated during createNote()
+	steps := job.Steps
+	if len(steps) == 0 && job.Automation == nil { // Jobs queued without steps only embed, e.g. after an update or re-summarization
+		steps = []string{models.PipelineEmbed}
+	}
+
+	// Automation runs first, so the steps after it embed the summary it may generate
+	if job.Automation != nil {
+		if err := wp.runAutomation(ctx, &job); err != nil {
+			return "", err
+		}
+	}
+
+	status := models.ProcessingSkipped
+	for _, step := range steps {
+		var err error
+		switch step {
+		case models.PipelineExtractEntities:
+			err = wp.extractEntities(ctx, job)
+		case models.PipelineEmbed:
+			status, err = wp.embedNote(ctx, job)
+		default:
+			log.Printf("Skipping unknown pipeline step %q for note %s", step, job.NoteID.Hex())
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return status, nil
+}
+
+// runAutomation adds a new note to the collections of the automation rules it matched and
+// summarizes it with their preset, updating the job with the new summary
+// A failed preset summary is logged and the note keeps the summary it was created with.
+func (wp *WorkerPool) runAutomation(ctx context.Context, job *models.ProcessingJob) error {
+	plan := job.Automation
+	if len(plan.Collections) > 0 {
+		update := bson.M{"$addToSet": bson.M{"collections": bson.M{"$each": plan.Collections}}}
+		if err := wp.notesRepo.Update(ctx, job.NoteID, update); err != nil {
+			return fmt.Errorf("failed to add note to collections: %w", err)
+		}
+	}
+
+	// In privacy mode summaries never go through the LLM, so presets are not applied
+	if (plan.PromptText == "" && plan.PromptSchema == "") || wp.cfg.PrivacyMode {
+		return nil
+	}
+	summary, structuredData, err := wp.aiClient.GenerateStructuredSummary(job.Content, plan.PromptText, plan.PromptSchema)
+	if err != nil {
+		log.Printf("Failed to summarize note %s with preset %q, keeping its summary: %v", job.NoteID.Hex(), plan.Preset, err)
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	author, _ := job.Metadata["author"].(string)
+	structuredData, truncated := limitStructuredData(structuredData, author)
+	generation := newGeneration(wp.cfg, models.AIOperationSummary)
+	update := bson.M{"$set": bson.M{
+		"summary":            summary,
+		"structured_data":    structuredData,
+		"last_summarized_at": generation.GeneratedAt,
+		"summary_generation": generation,
+	}}
+	if truncated {
+		update["$set"].(bson.M)["structured_data_truncated"] = true
+	} else {
+		update["$unset"] = bson.M{"structured_data_truncated": ""}
+	}
+	if err := wp.notesRepo.Update(ctx, job.NoteID, update); err != nil {
+		return fmt.Errorf("failed to save preset summary: %w", err)
+	}
+	wp.webhookService.Emit(ctx, models.NoteEventSummaryGenerated, job.NoteID, map[string]interface{}{
+		"summary":        summary,
+		"structuredData": structuredData,
+		"fallback":       false,
+	})
+
+	job.Summary, job.StructuredData = summary, structuredData
+	log.Printf("Summarized note %s with preset %q", job.NoteID.Hex(), plan.Preset)
+	return nil
+}
+
+// extractEntities stores the people, organizations, places and tools a note mentions
+func (wp *WorkerPool) extractEntities(ctx context.Context, job models.ProcessingJob) error {
+	entities, err := wp.aiClient.ExtractEntities(job.Title + "\n\n" + job.Content)
+	if err != nil {
+		return fmt.Errorf("failed to extract entities: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := wp.notesRepo.Update(ctx, job.NoteID, bson.M{"$set": bson.M{"entities": entities}}); err != nil {
+		return fmt.Errorf("failed to save entities: %w", err)
+	}
+	log.Printf("Extracted %d entities from note %s", len(entities), job.NoteID.Hex())
+	return nil
+}
+
+// chunking returns the chunk size and overlap for a job's note: its channel's chunking hints,
+// falling back to CHUNK_SIZE and CHUNK_OVERLAP
+func (wp *WorkerPool) chunking(ctx context.Context, job models.ProcessingJob) (int, int) {
+	size, overlap := wp.cfg.ChunkSize, wp.cfg.ChunkOverlap
+	author, _ := job.Metadata["author"].(string)
+	if author == "" {
+		return size, overlap
+	}
+
+	platform, _ := job.Metadata["platform"].(string)
+	settings, err := wp.channelSettingsRepo.FindByChannel(ctx, config.NormalizePlatform(platform), author)
+	if err != nil {
+		log.Printf("Failed to load chunking hints for channel %s, using the defaults: %v", author, err)
+		return size, overlap
+	}
+	if settings == nil || settings.Chunking == nil {
+		return size, overlap
+	}
+
+	if settings.Chunking.ChunkSize > 0 {
+		size = settings.Chunking.ChunkSize
+	}
+	if settings.Chunking.ChunkOverlap > 0 {
+		overlap = settings.Chunking.ChunkOverlap
+	}
+	if overlap >= size {
+		// The default overlap doesn't fit the channel's smaller chunks
+		overlap = size / 2
+	}
+	return size, overlap
+}
+
+// embedNote chunks a note and stores the embedding of every chunk, returning the note's
+// resulting processing status
+// It stops between requests once ctx is cancelled.
+func (wp *WorkerPool) embedNote(ctx context.Context, job models.ProcessingJob) (string, error) {
+	fullText := job.Summary + "\n\n" + structuredText(job.StructuredData)
+	if !job.SummaryOnly {
+		fullText = job.Title + "\n\n" + job.Content + "\n\n" + fullText
+	}
 
 	// Skip embedding if sensitive data detected
 	if utils.ContainsSensitiveData(fullText) {
 		log.Printf("Skipping embedding for note %s: Sensitive data detected (API keys, passwords, etc.)", job.NoteID.Hex())
-		return nil // Not an error, just skip embedding for security
+		return models.ProcessingSkipped, nil // Not an error, just skip embedding for security
 	}
 
-	words := strings.Fields(fullText)
+	chunkSize, chunkOverlap := wp.chunking(ctx, job)
+	var chunks []models.NoteChunk
+	if !job.SummaryOnly {
+		chunks = chunkNote(job.NoteID, job.Title+"\n\n"+job.Content, chunkSize, chunkOverlap)
+		if title := strings.TrimSpace(job.Title); title != "" {
+			// Embedded on its own too, so a search for a remembered title finds it
+			chunks = append(chunks, models.NoteChunk{NoteID: job.NoteID, Content: title, Type: models.ChunkTypeTitle})
+		}
+	}
+	chunks = append(chunks, summaryChunks(job.NoteID, job.Summary, job.StructuredData, chunkSize, chunkOverlap)...)
 
-	if len(words) > config.MAX_WORDS {
-		words = words[:config.MAX_WORDS]
-		fullText = strings.Join(words, " ")
+	// Vectors from an earlier run are replaced, so re-processing a note doesn't duplicate them
+	if err := wp.deleteVectors(ctx, job); err != nil {
+		return "", err
 	}
 
-	chunks := utils.ChunkText(fullText, config.CHUNK_SIZE)
+	author, _ := job.Metadata["author"].(string)
+	platform, _ := job.Metadata["platform"].(string)
+	payload := vectordb.PointPayload{
+		Category:   job.Category,
+		ChannelKey: utils.NormalizeChannelKey(author),
+		Author:     author,
+		Platform:   platform,
+		CreatedAt:  job.NoteCreated,
+	}
+	if payload.CreatedAt.IsZero() {
+		// Queued before jobs carried the note's creation time; its ID is assigned when it is created
+		payload.CreatedAt = job.NoteID.Timestamp()
+	}
 
-	for i, chunk := range chunks {
-		chunkDoc := models.NoteChunk{
-			NoteID:   job.NoteID,
-			Content:  chunk,
-			ChunkIdx: i,
+	// Any failure fails the job so it is retried; the retry replaces the chunks stored so far.
+	// Chunk IDs are derived from their content and position, so a chunk saved twice (e.g. by a
+	// retry that couldn't delete the first attempt's chunks) is replaced rather than duplicated.
+	// Chunks are saved as pending until their embedding is stored, so the note's embedding
+	// coverage can be counted.
+	for i := range chunks {
+		chunks[i].ID = deriveChunkID(chunks[i])
+		chunks[i].Pending = true
+		if err := wp.chunksRepo.Upsert(ctx, &chunks[i]); err != nil {
+			return "", fmt.Errorf("failed to save chunk: %w", err)
 		}
+	}
 
-		chunkID, err := wp.chunksRepo.Create(context.Background(), &chunkDoc)
-		if err != nil {
-			log.Printf("Error saving chunk: %v", err)
-			continue
+	// Chunks are embedded a batch at a time, so a long note takes a few requests rather than one per chunk
+	for start := 0; start < len(chunks); start += config.EMBEDDING_BATCH_SIZE {
+		batch := chunks[start:min(start+config.EMBEDDING_BATCH_SIZE, len(chunks))]
+		texts := make([]string, len(batch))
+		for i, chunkDoc := range batch {
+			texts[i] = chunkDoc.Content
 		}
 
-		embedding, err := wp.aiClient.GenerateEmbedding(chunk)
+		// Each batch spends quota, so a job superseded elsewhere stops as soon as possible
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		if err := wp.checkGeneration(ctx, &job); err != nil {
+			return "", err
+		}
+		var embeddings [][]float32
+		err := retryChunk(func() (err error) {
+			embeddings, err = wp.aiClient.GenerateEmbeddings(texts)
+			return err
+		})
 		if err != nil {
-			log.Printf("Error generating embedding: %v", err)
-			continue
+			return "", fmt.Errorf("failed to generate embeddings: %w", err)
+		}
+		if len(embeddings) != len(batch) {
+			return "", fmt.Errorf("failed to generate embeddings: expected %d embeddings, got %d", len(batch), len(embeddings))
 		}
 
-		if err := wp.qdrantClient.StoreEmbedding(chunkID, job.NoteID, embedding); err != nil {
-			log.Printf("Error storing embedding: %v", err)
+		for i, chunkDoc := range batch {
+			payload.ContentHash = utils.ContentHash(chunkDoc.Content)
+			payload.ChunkType = chunkDoc.Type
+			err := retryChunk(func() error {
+				return wp.qdrantClient.StoreEmbedding(chunkDoc.ID, job.NoteID, embeddings[i], payload)
+			})
+			if err != nil {
+				return "", fmt.Errorf("failed to store embedding: %w", err)
+			}
+			if err := wp.chunksRepo.MarkEmbedded(ctx, chunkDoc.ID); err != nil {
+				return "", fmt.Errorf("failed to mark chunk embedded: %w", err)
+			}
 		}
 	}
 
-	return nil
+	return models.ProcessingIndexed, nil
+}
+
+// deriveChunkID derives a chunk's ObjectID from its note, type, position and content, so the same
+// chunk always gets the same ID and, through PointID, the same Qdrant point
+func deriveChunkID(chunk models.NoteChunk) primitive.ObjectID {
+	hash := sha256.New()
+	hash.Write(chunk.NoteID[:])
+	fmt.Fprintf(hash, "\x00%s\x00%d\x00%d\x00", chunk.Type, chunk.Page, chunk.ChunkIdx)
+	hash.Write([]byte(chunk.Content))
+
+	var id primitive.ObjectID
+	copy(id[:], hash.Sum(nil))
+	return id
+}
+
+// chunkNote splits a note's text into the chunks that get embedded, up to MAX_WORDS in total
+// Paged documents are chunked page by page so every chunk can cite its page
+func chunkNote(noteID primitive.ObjectID, fullText string, chunkSize, overlap int) []models.NoteChunk {
+	var chunks []models.NoteChunk
+	budget := config.MAX_WORDS
+	for _, page := range utils.SplitPages(fullText) {
+		text, words := utils.FirstWords(page.Text, budget)
+		budget -= words
+
+		for _, chunk := range utils.ChunkText(text, chunkSize, overlap) {
+			chunks = append(chunks, models.NoteChunk{
+				NoteID:   noteID,
+				Content:  chunk,
+				ChunkIdx: len(chunks),
+				Page:     page.Page,
+				Type:     models.ChunkTypeContent,
+			})
+		}
+	}
+	return chunks
+}
+
+// summaryChunks splits a note's summary and the key fields of its structured data into the
+// chunks that get embedded alongside its content
+func summaryChunks(noteID primitive.ObjectID, summary string, structuredData map[string]interface{}, chunkSize, overlap int) []models.NoteChunk {
+	parts := []struct{ chunkType, text string }{
+		{models.ChunkTypeSummary, summary},
+		{models.ChunkTypeStructured, structuredText(structuredData)},
+	}
+
+	var chunks []models.NoteChunk
+	for _, part := range parts {
+		for i, chunk := range utils.ChunkText(part.text, chunkSize, overlap) {
+			chunks = append(chunks, models.NoteChunk{
+				NoteID:   noteID,
+				Content:  chunk,
+				ChunkIdx: i,
+				Type:     part.chunkType,
+			})
+		}
+	}
+	return chunks
+}
+
+// structuredText renders the fields of structured data holding text, numbers or lists of them as
+// "key: value" lines, sorted by key; nested objects are left out
+func structuredText(data map[string]interface{}) string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var lines []string
+	for _, key := range keys {
+		var values []string
+		switch value := data[key].(type) {
+		case []interface{}:
+			for _, item := range value {
+				if text, ok := scalarText(item); ok {
+					values = append(values, text)
+				}
+			}
+		case primitive.A:
+			for _, item := range value {
+				if text, ok := scalarText(item); ok {
+					values = append(values, text)
+				}
+			}
+		default:
+			if text, ok := scalarText(value); ok {
+				values = append(values, text)
+			}
+		}
+		if len(values) > 0 {
+			lines = append(lines, key+": "+strings.Join(values, ", "))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// scalarText formats a non-empty string, number or boolean
+func scalarText(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		v = strings.TrimSpace(v)
+		return v, v != ""
+	case bool, int, int32, int64, float64:
+		return fmt.Sprint(v), true
+	}
+	return "", false
+}
+
+// deleteVectors removes the chunks and embeddings a job is about to replace: the summary and
+// structured data ones for summary-only jobs, all of the note's otherwise
+func (wp *WorkerPool) deleteVectors(ctx context.Context, job models.ProcessingJob) error {
+	if job.SummaryOnly {
+		types := []string{models.ChunkTypeSummary, models.ChunkTypeStructured}
+		if _, err := wp.chunksRepo.DeleteByNoteIDAndTypes(ctx, job.NoteID, types); err != nil {
+			return fmt.Errorf("failed to delete summary chunks: %w", err)
+		}
+		return wp.qdrantClient.DeleteByNoteIDAndTypes(job.NoteID, types)
+	}
+
+	if _, err := wp.chunksRepo.DeleteByNoteID(ctx, job.NoteID); err != nil {
+		return fmt.Errorf("failed to delete chunks: %w", err)
+	}
+	_, err := wp.qdrantClient.DeleteByNoteID(job.NoteID)
+	return err
 }
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/utils"
+	"backend/internal/youtube"
+)
+
+// YouTubeSyncService saves new uploads of channels that have a channel URL in their settings
+// Each sync looks at every channel's latest uploads and creates a note from the transcript of
+// each one not saved yet, the same way the browser extension saves a video, so the channel's
+// custom prompt applies. Uploads without captions are picked up by a later sync.
+type YouTubeSyncService struct {
+	channelSettingsRepo *repository.ChannelSettingsRepository
+	notesRepo           *repository.NotesRepository
+	notesService        *NotesService
+	youtube             *youtube.Client
+	cfg                 *config.Config
+
+	mu   sync.Mutex // Serializes syncs so a manual sync can't overlap the scheduled one
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewYouTubeSyncService creates a new YouTubeSyncService
+func NewYouTubeSyncService(
+	channelSettingsRepo *repository.ChannelSettingsRepository,
+	notesRepo *repository.NotesRepository,
+	notesService *NotesService,
+	youtubeClient *youtube.Client,
+	cfg *config.Config,
+) *YouTubeSyncService {
+	return &YouTubeSyncService{
+		channelSettingsRepo: channelSettingsRepo,
+		notesRepo:           notesRepo,
+		notesService:        notesService,
+		youtube:             youtubeClient,
+		cfg:                 cfg,
+	}
+}
+
+// Start syncs on the configured interval until Stop is called
+// A zero or negative interval leaves the schedule disabled
+func (s *YouTubeSyncService) Start() {
+	if s.cfg.YouTubeSyncIntervalHours <= 0 {
+		log.Println("YouTube channel sync schedule disabled")
+		return
+	}
+
+	interval := time.Duration(s.cfg.YouTubeSyncIntervalHours * float64(time.Hour))
+	s.stop = make(chan struct{})
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if _, err := s.Sync(context.Background(), ""); err != nil {
+					log.Printf("Scheduled YouTube channel sync failed: %v", err)
+				}
+			case <-s.stop:
+				return
+			}
+		}
+	}()
+	log.Printf("YouTube channel sync scheduled every %s", interval)
+}
+
+// Stop ends the schedule and waits for a sync in progress to finish
+func (s *YouTubeSyncService) Stop() {
+	if s.stop == nil {
+		return
+	}
+	close(s.stop)
+	s.wg.Wait()
+}
+
+// Sync checks the channels with a channel URL for new uploads, or only the named channel
+// A channel that fails is recorded in the report and its settings without stopping the rest
+func (s *YouTubeSyncService) Sync(ctx context.Context, channel string) (*models.YouTubeSyncReport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	settings, err := s.channelSettingsRepo.FindWithChannelURL(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load channel settings: %w", err)
+	}
+	if channel != "" {
+		key := utils.NormalizeChannelKey(channel)
+		matched := []models.ChannelSettings{}
+		for _, setting := range settings {
+			if setting.ChannelName == channel || setting.ChannelKey == key {
+				matched = append(matched, setting)
+			}
+		}
+		if len(matched) == 0 {
+			return nil, fmt.Errorf("channel not found")
+		}
+		settings = matched
+	}
+
+	report := &models.YouTubeSyncReport{StartedAt: time.Now(), Channels: []models.YouTubeChannelSync{}}
+	for i := range settings {
+		result := s.syncChannel(ctx, &settings[i])
+		if err := s.channelSettingsRepo.SetSyncStatus(ctx, settings[i].ID, time.Now(), result.Error); err != nil {
+			log.Printf("Failed to record sync status of channel %s: %v", settings[i].ChannelName, err)
+		}
+		if result.Created > 0 || result.Error != "" {
+			log.Printf("YouTube sync of %s: %d new notes, %d without transcript, %d failed %s",
+				result.Channel, result.Created, result.NoTranscript, result.Failed, result.Error)
+		}
+		report.Channels = append(report.Channels, result)
+	}
+	report.FinishedAt = time.Now()
+	return report, nil
+}
+
+// syncChannel creates notes for a channel's latest uploads that aren't saved yet
+func (s *YouTubeSyncService) syncChannel(ctx context.Context, settings *models.ChannelSettings) models.YouTubeChannelSync {
+	result := models.YouTubeChannelSync{Channel: settings.ChannelName}
+
+	playlistID, err := s.youtube.UploadsPlaylist(ctx, settings.ChannelUrl)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	videos, err := s.youtube.RecentUploads(ctx, playlistID, config.YOUTUBE_SYNC_MAX_VIDEOS)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	failed := func(video youtube.Video, err error) {
+		result.Failed++
+		result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", video.ID, err))
+	}
+	for _, video := range videos {
+		result.Checked++
+		videoURL := youtube.WatchURL(video.ID)
+
+		// Checked before fetching the transcript, so saved uploads cost no requests or AI calls
+		exists, err := s.notesRepo.ExistsByURL(ctx, videoURL)
+		if err != nil {
+			failed(video, err)
+			continue
+		}
+		if exists {
+			result.Existing++
+			continue
+		}
+
+		transcript, err := s.youtube.Transcript(ctx, video.ID)
+		if errors.Is(err, youtube.ErrNoTranscript) {
+			result.NoTranscript++
+			continue
+		}
+		if err != nil {
+			failed(video, err)
+			continue
+		}
+
+		created, err := s.notesService.CreateNote(ctx, &models.CreateNoteRequest{
+			Title:   video.Title,
+			Content: transcript,
+			Metadata: map[string]interface{}{
+				"author":    settings.ChannelName,
+				"platform":  "youtube",
+				"url":       videoURL,
+				"timestamp": video.PublishedAt.Format(time.RFC3339),
+				"source":    "youtube-sync",
+				"video_id":  video.ID,
+			},
+		})
+		if err != nil {
+			failed(video, err)
+			continue
+		}
+		if created.Duplicate {
+			result.Existing++
+			continue
+		}
+		result.Created++
+	}
+	return result
+}
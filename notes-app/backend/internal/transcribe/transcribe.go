@@ -0,0 +1,79 @@
+// Package transcribe turns audio into text through an OpenAI-compatible transcription API,
+// such as OpenAI's own or a self-hosted Whisper server.
+package transcribe
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+
+	"backend/internal/config"
+)
+
+// Client calls the configured transcription API
+type Client struct {
+	baseURL string
+	apiKey  string
+	model   string
+	client  *http.Client
+}
+
+// NewClient creates a client for the configured transcription API
+// Returns nil when none is configured, so audio-only episodes are skipped
+func NewClient(cfg *config.Config) *Client {
+	if cfg.TranscriptionURL == "" {
+		return nil
+	}
+	return &Client{
+		baseURL: strings.TrimSuffix(cfg.TranscriptionURL, "/"),
+		apiKey:  cfg.TranscriptionAPIKey,
+		model:   cfg.TranscriptionModel,
+		client:  &http.Client{Timeout: config.TRANSCRIPTION_TIMEOUT_SECONDS * time.Second},
+	}
+}
+
+// Transcribe returns the text spoken in an audio file
+// The file name's extension tells the API the audio format
+func (c *Client) Transcribe(ctx context.Context, fileName string, audio []byte) (string, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", fileName)
+	if err != nil {
+		return "", err
+	}
+	part.Write(audio)
+	form.WriteField("model", c.model)
+	form.WriteField("response_format", "text")
+	if err := form.Close(); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/audio/transcriptions", &body)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("transcription request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	text, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read transcription: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("transcription API returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(text)))
+	}
+	return strings.TrimSpace(string(text)), nil
+}
@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizeChannelKey folds a channel name into a lookup key that ignores case,
+// diacritics, whitespace and punctuation, so "Lex Fridman", "lexfridman" and "@LexFridman" match
+func NormalizeChannelKey(name string) string {
+	folded, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), name)
+	if err != nil {
+		folded = name
+	}
+
+	var b strings.Builder
+	for _, r := range strings.ToLower(folded) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// NormalizeChannelKeys folds a list of names into unique, non-empty lookup keys
+func NormalizeChannelKeys(names []string) []string {
+	seen := make(map[string]bool)
+	keys := []string{}
+	for _, name := range names {
+		key := NormalizeChannelKey(name)
+		if key != "" && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
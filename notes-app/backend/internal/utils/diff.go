@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"strings"
+)
+
+// maxDiffCells bounds the O(n·m) sentence comparison of the changed middle of two texts
+const maxDiffCells = 1_000_000
+
+// DiffHunk is a run of sentences removed from a text and the sentences added in their place
+type DiffHunk struct {
+	Removed []string
+	Added   []string
+}
+
+// TextDiff is how one text was edited into another, compared sentence by sentence
+type TextDiff struct {
+	Hunks        []DiffHunk
+	ChangedWords int // Words in the removed and added sentences
+	TotalWords   int // Words in both texts
+}
+
+// ChangeRatio returns the share of words the edit touched, from 0 for identical texts to 1 for
+// texts sharing no sentence
+func (d *TextDiff) ChangeRatio() float64 {
+	if d.TotalWords == 0 {
+		return 0
+	}
+	return float64(d.ChangedWords) / float64(d.TotalWords)
+}
+
+// String renders the hunks as "- " lines for removed sentences and "+ " lines for added ones,
+// hunks separated by a blank line
+func (d *TextDiff) String() string {
+	var sb strings.Builder
+	for i, hunk := range d.Hunks {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		for _, sentence := range hunk.Removed {
+			sb.WriteString("- " + sentence + "\n")
+		}
+		for _, sentence := range hunk.Added {
+			sb.WriteString("+ " + sentence + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// DiffSentences compares two texts sentence by sentence
+// Returns nil when the edited stretch between the common start and end is too long to compare,
+// which only happens when the texts differ a lot.
+func DiffSentences(oldText, newText string) *TextDiff {
+	oldSentences, newSentences := SplitSentences(oldText), SplitSentences(newText)
+	diff := &TextDiff{TotalWords: len(strings.Fields(oldText)) + len(strings.Fields(newText))}
+
+	// Small edits leave most sentences in place; only the stretch between the common start and end needs comparing
+	prefix := 0
+	for prefix < len(oldSentences) && prefix < len(newSentences) && oldSentences[prefix] == newSentences[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(oldSentences)-prefix && suffix < len(newSentences)-prefix &&
+		oldSentences[len(oldSentences)-1-suffix] == newSentences[len(newSentences)-1-suffix] {
+		suffix++
+	}
+	a := oldSentences[prefix : len(oldSentences)-suffix]
+	b := newSentences[prefix : len(newSentences)-suffix]
+	if len(a)*len(b) > maxDiffCells {
+		return nil
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var hunk DiffHunk
+	flush := func() {
+		if len(hunk.Removed) > 0 || len(hunk.Added) > 0 {
+			diff.Hunks = append(diff.Hunks, hunk)
+			hunk = DiffHunk{}
+		}
+	}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			flush()
+			i++
+			j++
+		case j < len(b) && (i == len(a) || lcs[i][j+1] >= lcs[i+1][j]):
+			hunk.Added = append(hunk.Added, b[j])
+			diff.ChangedWords += len(strings.Fields(b[j]))
+			j++
+		default:
+			hunk.Removed = append(hunk.Removed, a[i])
+			diff.ChangedWords += len(strings.Fields(a[i]))
+			i++
+		}
+	}
+	flush()
+	return diff
+}
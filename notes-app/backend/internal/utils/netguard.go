@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// ErrNonPublicAddress is returned when a fetch would connect to a loopback, private, link-local
+// or unspecified address
+var ErrNonPublicAddress = errors.New("address is not publicly routable")
+
+// AddressCheck decides whether a client may connect to an address, returning an error wrapping
+// ErrNonPublicAddress when it may not
+type AddressCheck func(addr netip.Addr) error
+
+// PublicAddress is the AddressCheck for user-supplied URLs: it refuses loopback, private,
+// link-local, unspecified and multicast addresses
+func PublicAddress(addr netip.Addr) error {
+	addr = addr.Unmap()
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() || addr.IsMulticast() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() || addr.IsInterfaceLocalMulticast() {
+		return fmt.Errorf("%w: %s", ErrNonPublicAddress, addr)
+	}
+	return nil
+}
+
+// PublicHTTPClient creates an HTTP client for fetching user-supplied URLs that only connects to
+// addresses check allows. The check runs on the resolved address of every connection it dials, so
+// redirects and DNS rebinding can't reach the database, the vector store or a cloud metadata
+// endpoint either.
+func PublicHTTPClient(timeout time.Duration, check AddressCheck) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: PublicTransport(check)}
+}
+
+// PublicTransport returns a copy of the default transport whose dialer runs check on the resolved
+// address of every connection, for clients that need their own TLS settings
+func PublicTransport(check AddressCheck) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return fmt.Errorf("%w: %s", ErrNonPublicAddress, address)
+			}
+			return check(addrPort.Addr())
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	// A proxy would make the connection on our behalf, out of reach of the check
+	transport.Proxy = nil
+	return transport
+}
+
+// CheckHost resolves a URL host and runs check on each of its addresses, so a URL every request
+// would be refused for can be rejected when it is registered
+func CheckHost(ctx context.Context, host string, check AddressCheck) error {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return check(addr)
+	}
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", host, err)
+	}
+	for _, addr := range addrs {
+		if err := check(addr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
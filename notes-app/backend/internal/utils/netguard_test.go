@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestPublicAddress(t *testing.T) {
+	for _, tc := range []struct {
+		addr   string
+		public bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:4700::6810:85e5", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"0.0.0.0", false},
+		{"::", false},
+		{"224.0.0.1", false},
+		{"::ffff:127.0.0.1", false},
+	} {
+		err := PublicAddress(netip.MustParseAddr(tc.addr))
+		if tc.public && err != nil {
+			t.Errorf("%s: expected a public address, got %v", tc.addr, err)
+		}
+		if !tc.public && !errors.Is(err, ErrNonPublicAddress) {
+			t.Errorf("%s: expected ErrNonPublicAddress, got %v", tc.addr, err)
+		}
+	}
+}
+
+func TestPublicHTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	t.Run("refuses addresses the check rejects", func(t *testing.T) {
+		client := PublicHTTPClient(5*time.Second, PublicAddress)
+		for _, url := range []string{server.URL, "http://localhost:1/", "http://169.254.169.254/"} {
+			if _, err := client.Get(url); !errors.Is(err, ErrNonPublicAddress) {
+				t.Errorf("%s: expected ErrNonPublicAddress, got %v", url, err)
+			}
+		}
+	})
+
+	t.Run("connects to addresses the check allows", func(t *testing.T) {
+		client := PublicHTTPClient(5*time.Second, func(netip.Addr) error { return nil })
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("Expected the request to succeed, got %v", err)
+		}
+		resp.Body.Close()
+	})
+}
+
+func TestCheckHost(t *testing.T) {
+	ctx := context.Background()
+	for _, host := range []string{"127.0.0.1", "fd00::1", "localhost"} {
+		if err := CheckHost(ctx, host, PublicAddress); !errors.Is(err, ErrNonPublicAddress) {
+			t.Errorf("%s: expected ErrNonPublicAddress, got %v", host, err)
+		}
+	}
+	if err := CheckHost(ctx, "93.184.216.34", PublicAddress); err != nil {
+		t.Errorf("Expected a public address to pass, got %v", err)
+	}
+}
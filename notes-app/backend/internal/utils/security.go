@@ -48,3 +48,11 @@ func ContainsSensitiveData(text string) bool {
 	}
 	return false
 }
+
+// RedactSensitiveData replaces whatever ContainsSensitiveData would flag in text with [REDACTED]
+func RedactSensitiveData(text string) string {
+	for _, pattern := range sensitivePatterns {
+		text = pattern.ReplaceAllString(text, "[REDACTED]")
+	}
+	return text
+}
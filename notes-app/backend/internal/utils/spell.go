@@ -0,0 +1,45 @@
+package utils
+
+import "strings"
+
+// Words returns the lowercase word tokens in text
+func Words(text string) []string {
+	return wordPattern.FindAllString(strings.ToLower(text), -1)
+}
+
+// EditDistance returns the optimal string alignment distance between a and b:
+// the number of insertions, deletions, substitutions and adjacent transpositions needed to turn one into the other
+func EditDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 {
+		return len(rb)
+	}
+	if len(rb) == 0 {
+		return len(ra)
+	}
+
+	// Three rolling rows are enough for transpositions
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				curr[j] = min(curr[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+
+	return prev[len(rb)]
+}
@@ -0,0 +1,157 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// CheckStructuredData returns an error when data encodes to more than maxBytes of JSON or nests
+// objects and lists more than maxDepth levels deep; a flat object is 1 level deep
+func CheckStructuredData(data map[string]interface{}, maxBytes, maxDepth int) error {
+	if data == nil {
+		return nil
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("structured data is not valid JSON: %w", err)
+	}
+	if len(encoded) > maxBytes {
+		return fmt.Errorf("structured data is %d bytes, over the limit of %d", len(encoded), maxBytes)
+	}
+
+	var normalized interface{}
+	if err := json.Unmarshal(encoded, &normalized); err != nil {
+		return fmt.Errorf("structured data is not valid JSON: %w", err)
+	}
+	if depth := jsonDepth(normalized); depth > maxDepth {
+		return fmt.Errorf("structured data nests %d levels deep, over the limit of %d", depth, maxDepth)
+	}
+	return nil
+}
+
+// LimitStructuredData cuts data down to at most maxBytes of JSON and maxDepth levels of nesting, and
+// reports whether anything was cut. Objects and lists nested too deep are dropped; past the size
+// limit, fields are kept in key order and lists from their start while they fit, and the string
+// that doesn't fit is clipped with "...". Data within the limits is returned as is.
+func LimitStructuredData(data map[string]interface{}, maxBytes, maxDepth int) (map[string]interface{}, bool) {
+	if CheckStructuredData(data, maxBytes, maxDepth) == nil {
+		return data, false
+	}
+
+	// Round-trip through JSON so the data holds only the types the limiter knows
+	var normalized map[string]interface{}
+	encoded, err := json.Marshal(data)
+	if err == nil {
+		err = json.Unmarshal(encoded, &normalized)
+	}
+	if err != nil {
+		return map[string]interface{}{}, true
+	}
+
+	limiter := &jsonLimiter{maxDepth: maxDepth}
+	limited, _, ok := limiter.fit(normalized, maxBytes, 1)
+	if !ok {
+		return map[string]interface{}{}, true
+	}
+	return limited.(map[string]interface{}), true
+}
+
+// jsonDepth returns how many levels of objects and lists a decoded JSON value nests
+func jsonDepth(value interface{}) int {
+	depth := 0
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, item := range v {
+			depth = max(depth, jsonDepth(item))
+		}
+		return depth + 1
+	case []interface{}:
+		for _, item := range v {
+			depth = max(depth, jsonDepth(item))
+		}
+		return depth + 1
+	}
+	return 0
+}
+
+// jsonLimiter fits decoded JSON values into a byte budget
+type jsonLimiter struct {
+	maxDepth int
+}
+
+// fit returns the part of value that encodes in at most budget bytes at the given nesting depth,
+// with its encoded size; ok is false when none of it fits
+func (l *jsonLimiter) fit(value interface{}, budget, depth int) (interface{}, int, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if depth > l.maxDepth || budget < len("{}") {
+			return nil, 0, false
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		fitted := make(map[string]interface{}, len(v))
+		size := len("{}")
+		for _, key := range keys {
+			encodedKey, _ := json.Marshal(key)
+			overhead := len(encodedKey) + len(":")
+			if len(fitted) > 0 {
+				overhead += len(",")
+			}
+			// A field that doesn't fit is skipped; a smaller one after it may still
+			if item, n, ok := l.fit(v[key], budget-size-overhead, depth+1); ok {
+				fitted[key] = item
+				size += overhead + n
+			}
+		}
+		return fitted, size, true
+
+	case []interface{}:
+		if depth > l.maxDepth || budget < len("[]") {
+			return nil, 0, false
+		}
+		fitted := make([]interface{}, 0, len(v))
+		size := len("[]")
+		for _, item := range v {
+			overhead := 0
+			if len(fitted) > 0 {
+				overhead = len(",")
+			}
+			item, n, ok := l.fit(item, budget-size-overhead, depth+1)
+			if !ok {
+				break
+			}
+			fitted = append(fitted, item)
+			size += overhead + n
+		}
+		return fitted, size, true
+
+	case string:
+		encoded, _ := json.Marshal(v)
+		if len(encoded) <= budget {
+			return v, len(encoded), true
+		}
+		runes := []rune(v)
+		for cut := min(len(runes), budget-len(`"..."`)); cut > 0; {
+			clipped := string(runes[:cut]) + "..."
+			encoded, _ := json.Marshal(clipped)
+			if len(encoded) <= budget {
+				return clipped, len(encoded), true
+			}
+			// Runes encode to a byte or more, so scale the cut by how far over budget it came out
+			cut = min(cut-1, cut*budget/len(encoded))
+		}
+		return nil, 0, false
+
+	default:
+		encoded, _ := json.Marshal(v)
+		if len(encoded) > budget {
+			return nil, 0, false
+		}
+		return v, len(encoded), true
+	}
+}
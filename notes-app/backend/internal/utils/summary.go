@@ -0,0 +1,155 @@
+package utils
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// sentenceBoundary matches sentence-ending punctuation followed by whitespace
+var sentenceBoundary = regexp.MustCompile(`([.!?])\s+`)
+
+// wordPattern extracts lowercase word tokens for sentence similarity
+var wordPattern = regexp.MustCompile(`[\p{L}\p{N}']+`)
+
+// stopWords are ignored when comparing sentences
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true,
+	"but": true, "by": true, "for": true, "from": true, "has": true, "have": true, "he": true,
+	"her": true, "his": true, "i": true, "if": true, "in": true, "is": true, "it": true,
+	"its": true, "of": true, "on": true, "or": true, "she": true, "so": true, "that": true,
+	"the": true, "their": true, "them": true, "they": true, "this": true, "to": true,
+	"was": true, "we": true, "were": true, "what": true, "which": true, "will": true,
+	"with": true, "you": true, "your": true,
+}
+
+const (
+	textRankDamping    = 0.85
+	textRankIterations = 30
+	// textRankMaxSentences bounds the O(n²) similarity graph for very long transcripts
+	textRankMaxSentences = 400
+)
+
+// SplitSentences splits text into trimmed, non-empty sentences
+func SplitSentences(text string) []string {
+	marked := sentenceBoundary.ReplaceAllString(text, "$1\n")
+
+	var sentences []string
+	for _, line := range strings.Split(marked, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			sentences = append(sentences, line)
+		}
+	}
+	return sentences
+}
+
+// ExtractiveSummary builds a summary from the most central sentences of the text
+// using TextRank, keeping the selected sentences in their original order.
+// Used whenever the LLM cannot (or must not) produce a summary.
+func ExtractiveSummary(text string, maxSentences int) string {
+	sentences := SplitSentences(text)
+	if len(sentences) > textRankMaxSentences {
+		sentences = sentences[:textRankMaxSentences]
+	}
+	if len(sentences) <= maxSentences {
+		return strings.Join(sentences, " ")
+	}
+
+	scores := textRankScores(sentences)
+
+	indexes := make([]int, len(sentences))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(a, b int) bool {
+		return scores[indexes[a]] > scores[indexes[b]]
+	})
+
+	selected := indexes[:maxSentences]
+	sort.Ints(selected)
+
+	picked := make([]string, 0, len(selected))
+	for _, idx := range selected {
+		picked = append(picked, sentences[idx])
+	}
+	return strings.Join(picked, " ")
+}
+
+// textRankScores ranks sentences with PageRank over a word-overlap similarity graph
+func textRankScores(sentences []string) []float64 {
+	n := len(sentences)
+
+	tokens := make([]map[string]bool, n)
+	for i, sentence := range sentences {
+		tokens[i] = sentenceTokens(sentence)
+	}
+
+	// Build the weighted similarity graph
+	weights := make([][]float64, n)
+	outWeight := make([]float64, n)
+	for i := range weights {
+		weights[i] = make([]float64, n)
+	}
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			sim := sentenceSimilarity(tokens[i], tokens[j])
+			weights[i][j] = sim
+			weights[j][i] = sim
+			outWeight[i] += sim
+			outWeight[j] += sim
+		}
+	}
+
+	scores := make([]float64, n)
+	for i := range scores {
+		scores[i] = 1.0
+	}
+
+	for iter := 0; iter < textRankIterations; iter++ {
+		next := make([]float64, n)
+		for i := 0; i < n; i++ {
+			var rank float64
+			for j := 0; j < n; j++ {
+				if weights[j][i] > 0 && outWeight[j] > 0 {
+					rank += weights[j][i] / outWeight[j] * scores[j]
+				}
+			}
+			next[i] = (1 - textRankDamping) + textRankDamping*rank
+		}
+		scores = next
+	}
+
+	return scores
+}
+
+// sentenceTokens returns the set of non-stop-word tokens in a sentence
+func sentenceTokens(sentence string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range wordPattern.FindAllString(strings.ToLower(sentence), -1) {
+		if !stopWords[word] {
+			set[word] = true
+		}
+	}
+	return set
+}
+
+// sentenceSimilarity is the TextRank overlap measure normalised by sentence length
+func sentenceSimilarity(a, b map[string]bool) float64 {
+	if len(a) < 2 || len(b) < 2 {
+		return 0
+	}
+
+	overlap := 0
+	for word := range a {
+		if b[word] {
+			overlap++
+		}
+	}
+	if overlap == 0 {
+		return 0
+	}
+
+	return float64(overlap) / (math.Log(float64(len(a))) + math.Log(float64(len(b))))
+}
@@ -1,23 +1,180 @@
 package utils
 
-import "strings"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// pageMarkerPattern matches the lines that start each page in the content of a paged document
+var pageMarkerPattern = regexp.MustCompile(`(?m)^\[Page (\d+)\]$`)
+
+// TextPage is a page of a paged document's content
+type TextPage struct {
+	Page int // 1-based page number; 0 for content without page markers
+	Text string
+}
+
+// paragraphBreakPattern matches the blank lines between paragraphs
+var paragraphBreakPattern = regexp.MustCompile(`\n\s*\n`)
+
+// textSentence is a sentence's words, or a chunkSize slice of a longer sentence
+type textSentence struct {
+	words     []string
+	paragraph int // Index of the paragraph the sentence belongs to
+}
+
+// ChunkText splits text into chunks of at most chunkSize words that end on natural breaks
+// Chunks are filled sentence by sentence, and close early at the end of a paragraph once half full
+// when the next paragraph wouldn't fit. Each chunk after the first repeats the whole sentences
+// ending the previous one that fit in overlap words, so context isn't lost at the boundary.
+// Sentences longer than chunkSize are split between words.
+func ChunkText(text string, chunkSize, overlap int) []string {
+	sentences := splitSentences(text, chunkSize)
+	paragraphWords := make(map[int]int)
+	for _, sentence := range sentences {
+		paragraphWords[sentence.paragraph] += len(sentence.words)
+	}
 
-// ChunkText splits text into chunks of specified word count
-func ChunkText(text string, chunkSize int) []string {
-	words := strings.Fields(text)
 	var chunks []string
+	var current []textSentence
+	count := 0
+	fresh := false // Whether current holds sentences not already in a chunk
+	emit := func() {
+		var words []string
+		for _, sentence := range current {
+			words = append(words, sentence.words...)
+		}
+		chunks = append(chunks, strings.Join(words, " "))
+		current = overlapSentences(current, overlap)
+		count = 0
+		for _, sentence := range current {
+			count += len(sentence.words)
+		}
+		fresh = false
+	}
+
+	for i, sentence := range sentences {
+		if fresh && count+len(sentence.words) > chunkSize {
+			emit()
+		}
+		if count+len(sentence.words) > chunkSize {
+			// Not even the overlap fits alongside this sentence
+			current, count = nil, 0
+		}
+		current = append(current, sentence)
+		count += len(sentence.words)
+		fresh = true
 
-	for i := 0; i < len(words); i += chunkSize {
-		end := i + chunkSize
-		if end > len(words) {
-			end = len(words)
+		if i+1 < len(sentences) && sentences[i+1].paragraph != sentence.paragraph &&
+			count >= chunkSize/2 && count+paragraphWords[sentences[i+1].paragraph] > chunkSize {
+			emit()
 		}
-		chunks = append(chunks, strings.Join(words[i:end], " "))
+	}
+	if fresh {
+		emit()
 	}
 
 	return chunks
 }
 
+// splitSentences splits text into sentences of at most chunkSize words, numbered by paragraph
+// Line breaks end a sentence too, so list items and transcript lines aren't run together.
+func splitSentences(text string, chunkSize int) []textSentence {
+	var sentences []textSentence
+	for paragraph, text := range paragraphBreakPattern.Split(text, -1) {
+		for _, sentence := range SplitSentences(text) {
+			words := strings.Fields(sentence)
+			for len(words) > chunkSize {
+				sentences = append(sentences, textSentence{words: words[:chunkSize], paragraph: paragraph})
+				words = words[chunkSize:]
+			}
+			if len(words) > 0 {
+				sentences = append(sentences, textSentence{words: words, paragraph: paragraph})
+			}
+		}
+	}
+	return sentences
+}
+
+// overlapSentences returns the sentences ending a chunk that fit in overlap words, leaving out at
+// least the first so the next chunk can't repeat the whole chunk
+func overlapSentences(sentences []textSentence, overlap int) []textSentence {
+	start := len(sentences)
+	words := 0
+	for start > 1 && words+len(sentences[start-1].words) <= overlap {
+		start--
+		words += len(sentences[start].words)
+	}
+	return append([]textSentence(nil), sentences[start:]...)
+}
+
+// FirstWords returns the start of text holding at most n words, keeping its line breaks, and the
+// number of words it holds
+func FirstWords(text string, n int) (string, int) {
+	words := 0
+	inWord := false
+	for i, r := range text {
+		if unicode.IsSpace(r) {
+			inWord = false
+			continue
+		}
+		if !inWord {
+			if words == n {
+				return strings.TrimRightFunc(text[:i], unicode.IsSpace), n
+			}
+			inWord = true
+			words++
+		}
+	}
+	return text, words
+}
+
+// PageMarker returns the line that starts a page in the content of a paged document, such as an uploaded PDF
+func PageMarker(page int) string {
+	return fmt.Sprintf("[Page %d]", page)
+}
+
+// SplitPages splits content at its page markers. Text before the first marker, such as the
+// title, belongs to the first page; content without markers is a single page numbered 0.
+func SplitPages(text string) []TextPage {
+	matches := pageMarkerPattern.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return []TextPage{{Text: text}}
+	}
+
+	pages := make([]TextPage, 0, len(matches))
+	for i, match := range matches {
+		end := len(text)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+		page, _ := strconv.Atoi(text[match[2]:match[3]])
+		pages = append(pages, TextPage{Page: page, Text: text[match[1]:end]})
+	}
+	pages[0].Text = text[:matches[0][0]] + pages[0].Text
+	return pages
+}
+
+// TruncateWords shortens text to at most maxLen characters, cutting on a word boundary
+// and appending "..." when anything was removed
+func TruncateWords(text string, maxLen int) string {
+	runes := []rune(strings.TrimSpace(text))
+	if len(runes) <= maxLen {
+		return string(runes)
+	}
+
+	truncated := string(runes[:maxLen])
+	if i := strings.LastIndex(truncated, " "); i > 0 {
+		truncated = truncated[:i]
+	}
+	return truncated + "..."
+}
+
 // CleanMarkdownCodeBlocks removes markdown code block formatting from text
 // This is commonly used when cleaning up AI-generated JSON responses
 func CleanMarkdownCodeBlocks(text string) string {
@@ -29,3 +186,37 @@ func CleanMarkdownCodeBlocks(text string) string {
 	text = strings.TrimSpace(text)
 	return text
 }
+
+// ContentHash returns the hex SHA-256 of text, used to detect drift between stored copies
+func ContentHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// Slugify converts text to a lowercase, dash-separated name safe for file names
+// Letters and digits in any script are kept; the result is at most maxLen runes
+func Slugify(text string, maxLen int) string {
+	var b strings.Builder
+	pendingDash := false
+	length := 0
+	for _, r := range strings.ToLower(text) {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			pendingDash = length > 0
+			continue
+		}
+		if pendingDash {
+			if length+1 >= maxLen {
+				break
+			}
+			b.WriteRune('-')
+			length++
+			pendingDash = false
+		}
+		if length >= maxLen {
+			break
+		}
+		b.WriteRune(r)
+		length++
+	}
+	return b.String()
+}
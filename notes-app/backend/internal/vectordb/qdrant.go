@@ -2,7 +2,9 @@ package vectordb
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"hash/fnv"
 	"log"
 	"time"
 
@@ -11,21 +13,105 @@ import (
 	pb "github.com/qdrant/go-client/qdrant"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 )
 
 // VectorSearchResult represents a search result from Qdrant
 type VectorSearchResult struct {
-	ChunkID string
-	NoteID  string
-	Score   float32
+	ChunkID   string
+	NoteID    string
+	ChunkType string // Empty for content points stored before chunk types existed
+	Score     float32
+}
+
+// PointPayload holds the note attributes stored with each embedding for filtering
+// Points stored before Author, Platform and CreatedAt existed lack them until a reindex.
+type PointPayload struct {
+	Category    string
+	ChannelKey  string // Normalized channel name (see utils.NormalizeChannelKey)
+	ContentHash string // Hash of the embedded chunk text (see utils.ContentHash); empty for older points
+	ChunkType   string // models.ChunkType* of the embedded chunk; empty for older content points
+	Author      string // The note's metadata author, as saved
+	Platform    string // Canonical platform name (see config.LookupPlatform)
+	CreatedAt   time.Time
 }
 
+// payloadIndexes lists the payload fields searches and deletes filter on, indexed by Initialize
+var payloadIndexes = map[string]pb.FieldType{
+	"note_id":     pb.FieldType_FieldTypeKeyword,
+	"category":    pb.FieldType_FieldTypeKeyword,
+	"channel_key": pb.FieldType_FieldTypeKeyword,
+	"chunk_type":  pb.FieldType_FieldTypeKeyword,
+	"author":      pb.FieldType_FieldTypeKeyword,
+	"platform":    pb.FieldType_FieldTypeKeyword,
+	"created_at":  pb.FieldType_FieldTypeInteger, // Unix seconds, so it can be range-filtered
+}
+
+// toQdrant builds the stored payload for a point
+func (p PointPayload) toQdrant(chunkID, noteID string) map[string]*pb.Value {
+	payload := map[string]*pb.Value{
+		"chunk_id":     {Kind: &pb.Value_StringValue{StringValue: chunkID}},
+		"note_id":      {Kind: &pb.Value_StringValue{StringValue: noteID}},
+		"category":     {Kind: &pb.Value_StringValue{StringValue: p.Category}},
+		"channel_key":  {Kind: &pb.Value_StringValue{StringValue: p.ChannelKey}},
+		"content_hash": {Kind: &pb.Value_StringValue{StringValue: p.ContentHash}},
+		"chunk_type":   {Kind: &pb.Value_StringValue{StringValue: p.ChunkType}},
+		"author":       {Kind: &pb.Value_StringValue{StringValue: p.Author}},
+		"platform":     {Kind: &pb.Value_StringValue{StringValue: p.Platform}},
+	}
+	if !p.CreatedAt.IsZero() {
+		payload["created_at"] = &pb.Value{Kind: &pb.Value_IntegerValue{IntegerValue: p.CreatedAt.Unix()}}
+	}
+	return payload
+}
+
+// payloadFromQdrant reads the filterable attributes back from a stored payload
+func payloadFromQdrant(payload map[string]*pb.Value) PointPayload {
+	p := PointPayload{
+		Category:    payload["category"].GetStringValue(),
+		ChannelKey:  payload["channel_key"].GetStringValue(),
+		ContentHash: payload["content_hash"].GetStringValue(),
+		ChunkType:   payload["chunk_type"].GetStringValue(),
+		Author:      payload["author"].GetStringValue(),
+		Platform:    payload["platform"].GetStringValue(),
+	}
+	if createdAt := payload["created_at"].GetIntegerValue(); createdAt > 0 {
+		p.CreatedAt = time.Unix(createdAt, 0).UTC()
+	}
+	return p
+}
+
+// SearchFilter limits a search to points by payload; empty fields are ignored
+// Exclusions never match points stored before their field existed, so callers should re-check
+// them; inclusions never match such points at all, so scoped searches miss them until a reindex.
+type SearchFilter struct {
+	ExcludeCategories  []string
+	ExcludeChannelKeys []string
+	ExcludeChunkTypes  []string
+	ExcludeNoteIDs     []string // Hex IDs of notes whose points never match
+
+	Categories    []string  // Only points in one of these categories
+	Authors       []string  // Only points by one of these authors
+	Platforms     []string  // Only points from one of these platforms
+	CreatedAfter  time.Time // Only points of notes created at or after this time
+	CreatedBefore time.Time // Only points of notes created before this time
+}
+
+// ErrUnavailable is returned by searches when Qdrant can't be reached, so callers can fall back
+// to keyword search
+var ErrUnavailable = errors.New("vector database unavailable")
+
+// ErrDimensionMismatch is returned when the collection's vectors don't have the embedding model's dimension
+var ErrDimensionMismatch = errors.New("embedding dimension mismatch")
+
 // QdrantClient provides vector database operations
 type QdrantClient struct {
 	conn              *grpc.ClientConn
 	collectionsClient pb.CollectionsClient
 	pointsClient      pb.PointsClient
+	dimension         int // Length of the collection's vectors, set by Initialize
 }
 
 // NewQdrantClient creates a new QdrantClient and establishes connection
@@ -50,8 +136,10 @@ func (q *QdrantClient) Close() error {
 	return nil
 }
 
-// Initialize creates the Qdrant collection if it doesn't exist
-func (q *QdrantClient) Initialize() error {
+// Initialize creates the Qdrant collection for vectors of the given dimension if it doesn't exist
+// An existing collection must store vectors of that dimension (ErrDimensionMismatch otherwise); a
+// dimension of 0 adopts the existing collection's, when the embedding model's couldn't be detected.
+func (q *QdrantClient) Initialize(dimension int) error {
 	ctx := context.Background()
 
 	collections, err := q.collectionsClient.List(ctx, &pb.ListCollectionsRequest{})
@@ -67,35 +155,127 @@ func (q *QdrantClient) Initialize() error {
 		}
 	}
 
-	if !collectionExists {
-		log.Printf("Creating Qdrant collection: %s", config.COLLECTION_NAME)
-		_, err := q.collectionsClient.Create(ctx, &pb.CreateCollection{
-			CollectionName: config.COLLECTION_NAME,
-			VectorsConfig: &pb.VectorsConfig{
-				Config: &pb.VectorsConfig_Params{
-					Params: &pb.VectorParams{
-						Size:     config.EMBEDDING_DIM,
-						Distance: pb.Distance_Cosine,
-					},
+	if collectionExists {
+		info, err := q.collectionsClient.Get(ctx, &pb.GetCollectionInfoRequest{CollectionName: config.COLLECTION_NAME})
+		if err != nil {
+			return fmt.Errorf("failed to get collection info: %w", err)
+		}
+		stored := int(info.GetResult().GetConfig().GetParams().GetVectorsConfig().GetParams().GetSize())
+		if dimension != 0 && stored != dimension {
+			return fmt.Errorf("%w: collection %s stores %d-dimensional vectors but the embedding model returns %d dimensions",
+				ErrDimensionMismatch, config.COLLECTION_NAME, stored, dimension)
+		}
+		q.dimension = stored
+		return q.ensurePayloadIndexes(ctx)
+	}
+
+	if dimension == 0 {
+		return fmt.Errorf("can't create collection %s: the embedding dimension is unknown, set EMBEDDING_DIM", config.COLLECTION_NAME)
+	}
+	log.Printf("Creating Qdrant collection: %s (%d dimensions)", config.COLLECTION_NAME, dimension)
+	_, err = q.collectionsClient.Create(ctx, &pb.CreateCollection{
+		CollectionName: config.COLLECTION_NAME,
+		VectorsConfig: &pb.VectorsConfig{
+			Config: &pb.VectorsConfig_Params{
+				Params: &pb.VectorParams{
+					Size:     uint64(dimension),
+					Distance: pb.Distance_Cosine,
 				},
 			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create collection: %w", err)
+	}
+	q.dimension = dimension
+
+	return q.ensurePayloadIndexes(ctx)
+}
+
+// ensurePayloadIndexes indexes the filtered payload fields, so filtered searches don't scan every
+// point; indexing a field that is already indexed does nothing
+func (q *QdrantClient) ensurePayloadIndexes(ctx context.Context) error {
+	wait := true
+	for field, fieldType := range payloadIndexes {
+		_, err := q.pointsClient.CreateFieldIndex(ctx, &pb.CreateFieldIndexCollection{
+			CollectionName: config.COLLECTION_NAME,
+			Wait:           &wait,
+			FieldName:      field,
+			FieldType:      fieldType.Enum(),
 		})
 		if err != nil {
-			return fmt.Errorf("failed to create collection: %w", err)
+			return fmt.Errorf("failed to create payload index on %s: %w", field, err)
 		}
 	}
+	return nil
+}
+
+// Dimension returns the length of the vectors the collection stores
+func (q *QdrantClient) Dimension() int {
+	return q.dimension
+}
+
+// Recreate deletes the collection with all of its points and creates it again empty, e.g. before
+// re-embedding every note with a different embedding model
+func (q *QdrantClient) Recreate() error {
+	ctx := context.Background()
+	if _, err := q.collectionsClient.Delete(ctx, &pb.DeleteCollection{CollectionName: config.COLLECTION_NAME}); err != nil {
+		return fmt.Errorf("failed to delete collection: %w", err)
+	}
+	return q.Initialize(q.dimension)
+}
+
+// CheckCollection reports an error unless the collection's info loads and its status isn't red
+func (q *QdrantClient) CheckCollection(ctx context.Context) error {
+	info, err := q.collectionsClient.Get(ctx, &pb.GetCollectionInfoRequest{CollectionName: config.COLLECTION_NAME})
+	if err != nil {
+		return fmt.Errorf("failed to get collection info: %w", err)
+	}
+	if status := info.GetResult().GetStatus(); status == pb.CollectionStatus_Red {
+		return fmt.Errorf("collection %s is in status %s", config.COLLECTION_NAME, status)
+	}
+	return nil
+}
 
+// WarmUp checks the collection and runs a search with a placeholder vector, so the first user
+// search doesn't pay for Qdrant loading the collection
+func (q *QdrantClient) WarmUp(ctx context.Context) error {
+	if err := q.CheckCollection(ctx); err != nil {
+		return err
+	}
+	vector := make([]float32, q.dimension)
+	for i := range vector {
+		vector[i] = 1
+	}
+	_, err := q.pointsClient.Search(ctx, &pb.SearchPoints{
+		CollectionName: config.COLLECTION_NAME,
+		Vector:         vector,
+		Limit:          1,
+	})
+	if err != nil {
+		return fmt.Errorf("warm-up search failed: %w", err)
+	}
 	return nil
 }
 
-// StoreEmbedding stores an embedding in Qdrant with chunk and note references
-func (q *QdrantClient) StoreEmbedding(chunkID, noteID primitive.ObjectID, embedding []float32) error {
+// PointID derives the Qdrant point ID of a chunk's embedding from the chunk's ObjectID, so storing
+// the embedding again (e.g. on a retry) replaces the point rather than duplicating it
+// Points stored before IDs were derived have timestamp IDs; a reindex replaces them.
+func PointID(chunkID primitive.ObjectID) uint64 {
+	hash := fnv.New64a()
+	hash.Write(chunkID[:])
+	return hash.Sum64()
+}
+
+// StoreEmbedding stores an embedding in Qdrant with chunk and note references and filterable note attributes
+// Its point ID is derived from chunkID (see PointID), so storing the same chunk twice is idempotent
+func (q *QdrantClient) StoreEmbedding(chunkID, noteID primitive.ObjectID, embedding []float32, payload PointPayload) error {
 	ctx := context.Background()
 
 	point := &pb.PointStruct{
 		Id: &pb.PointId{
 			PointIdOptions: &pb.PointId_Num{
-				Num: uint64(time.Now().UnixNano()), // Use timestamp as unique ID
+				Num: PointID(chunkID),
 			},
 		},
 		Vectors: &pb.Vectors{
@@ -103,10 +283,7 @@ func (q *QdrantClient) StoreEmbedding(chunkID, noteID primitive.ObjectID, embedd
 				Vector: &pb.Vector{Data: embedding},
 			},
 		},
-		Payload: map[string]*pb.Value{
-			"chunk_id": {Kind: &pb.Value_StringValue{StringValue: chunkID.Hex()}},
-			"note_id":  {Kind: &pb.Value_StringValue{StringValue: noteID.Hex()}},
-		},
+		Payload: payload.toQdrant(chunkID.Hex(), noteID.Hex()),
 	}
 
 	_, err := q.pointsClient.Upsert(ctx, &pb.UpsertPoints{
@@ -119,30 +296,120 @@ func (q *QdrantClient) StoreEmbedding(chunkID, noteID primitive.ObjectID, embedd
 
 // Search performs a vector similarity search and returns matching results
 func (q *QdrantClient) Search(vector []float32, limit int) ([]VectorSearchResult, error) {
+	return q.SearchFiltered(vector, limit, nil)
+}
+
+// SearchFiltered performs a vector similarity search, skipping points excluded by the filter
+func (q *QdrantClient) SearchFiltered(vector []float32, limit int, filter *SearchFilter) ([]VectorSearchResult, error) {
 	ctx := context.Background()
 
 	searchResult, err := q.pointsClient.Search(ctx, &pb.SearchPoints{
 		CollectionName: config.COLLECTION_NAME,
 		Vector:         vector,
+		Filter:         filter.toQdrant(),
 		Limit:          uint64(limit),
 		WithPayload:    &pb.WithPayloadSelector{SelectorOptions: &pb.WithPayloadSelector_Enable{Enable: true}},
 	})
 	if err != nil {
-		return nil, fmt.Errorf("search failed: %w", err)
+		return nil, fmt.Errorf("search failed: %w", unavailable(err))
 	}
 
 	var results []VectorSearchResult
 	for _, point := range searchResult.Result {
 		results = append(results, VectorSearchResult{
-			ChunkID: point.Payload["chunk_id"].GetStringValue(),
-			NoteID:  point.Payload["note_id"].GetStringValue(),
-			Score:   point.Score,
+			ChunkID:   point.Payload["chunk_id"].GetStringValue(),
+			NoteID:    point.Payload["note_id"].GetStringValue(),
+			ChunkType: point.Payload["chunk_type"].GetStringValue(),
+			Score:     point.Score,
 		})
 	}
 
 	return results, nil
 }
 
+// unavailable marks errors from Qdrant being unreachable or too slow to answer with ErrUnavailable
+func unavailable(err error) error {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	return err
+}
+
+// toQdrant converts the filter into must and must_not payload conditions; nil means no filter
+func (f *SearchFilter) toQdrant() *pb.Filter {
+	if f == nil {
+		return nil
+	}
+
+	var must []*pb.Condition
+	if len(f.Categories) > 0 {
+		must = append(must, keywordsCondition("category", f.Categories))
+	}
+	if len(f.Authors) > 0 {
+		must = append(must, keywordsCondition("author", f.Authors))
+	}
+	if len(f.Platforms) > 0 {
+		must = append(must, keywordsCondition("platform", f.Platforms))
+	}
+	if !f.CreatedAfter.IsZero() || !f.CreatedBefore.IsZero() {
+		must = append(must, createdCondition(f.CreatedAfter, f.CreatedBefore))
+	}
+
+	var mustNot []*pb.Condition
+	if len(f.ExcludeCategories) > 0 {
+		mustNot = append(mustNot, keywordsCondition("category", f.ExcludeCategories))
+	}
+	if len(f.ExcludeChannelKeys) > 0 {
+		mustNot = append(mustNot, keywordsCondition("channel_key", f.ExcludeChannelKeys))
+	}
+	if len(f.ExcludeChunkTypes) > 0 {
+		mustNot = append(mustNot, keywordsCondition("chunk_type", f.ExcludeChunkTypes))
+	}
+	if len(f.ExcludeNoteIDs) > 0 {
+		mustNot = append(mustNot, keywordsCondition("note_id", f.ExcludeNoteIDs))
+	}
+	if len(must) == 0 && len(mustNot) == 0 {
+		return nil
+	}
+
+	return &pb.Filter{Must: must, MustNot: mustNot}
+}
+
+// createdCondition matches points created in [after, before); a zero bound is open
+func createdCondition(after, before time.Time) *pb.Condition {
+	var createdRange pb.Range
+	if !after.IsZero() {
+		gte := float64(after.Unix())
+		createdRange.Gte = &gte
+	}
+	if !before.IsZero() {
+		lt := float64(before.Unix())
+		createdRange.Lt = &lt
+	}
+	return &pb.Condition{
+		ConditionOneOf: &pb.Condition_Field{
+			Field: &pb.FieldCondition{Key: "created_at", Range: &createdRange},
+		},
+	}
+}
+
+// keywordsCondition matches points whose payload key equals any of the values
+func keywordsCondition(key string, values []string) *pb.Condition {
+	return &pb.Condition{
+		ConditionOneOf: &pb.Condition_Field{
+			Field: &pb.FieldCondition{
+				Key: key,
+				Match: &pb.Match{
+					MatchValue: &pb.Match_Keywords{
+						Keywords: &pb.RepeatedStrings{Strings: values},
+					},
+				},
+			},
+		},
+	}
+}
+
 // DeleteByNoteID removes all embeddings associated with a note
 func (q *QdrantClient) DeleteByNoteID(noteID primitive.ObjectID) (int, error) {
 	ctx := context.Background()
@@ -179,3 +446,188 @@ func (q *QdrantClient) DeleteByNoteID(noteID primitive.ObjectID) (int, error) {
 	_ = result
 	return 0, nil // We can't get exact count from Qdrant delete response
 }
+
+// DeleteByNoteIDs removes all embeddings associated with any of the given notes in one request
+func (q *QdrantClient) DeleteByNoteIDs(noteIDs []primitive.ObjectID) error {
+	ids := make([]string, len(noteIDs))
+	for i, id := range noteIDs {
+		ids[i] = id.Hex()
+	}
+
+	_, err := q.pointsClient.Delete(context.Background(), &pb.DeletePoints{
+		CollectionName: config.COLLECTION_NAME,
+		Points: &pb.PointsSelector{
+			PointsSelectorOneOf: &pb.PointsSelector_Filter{
+				Filter: &pb.Filter{
+					Must: []*pb.Condition{keywordsCondition("note_id", ids)},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete embeddings: %w", err)
+	}
+	return nil
+}
+
+// SetCategory moves the points of notes in any of the from categories to the to category
+func (q *QdrantClient) SetCategory(from []string, to string) error {
+	return q.setCategory(keywordsCondition("category", from), to)
+}
+
+// SetNoteCategory sets the category in the payload of every point of the given notes, so scoped
+// searches follow a note moved to another category without re-embedding it
+func (q *QdrantClient) SetNoteCategory(noteIDs []primitive.ObjectID, category string) error {
+	ids := make([]string, len(noteIDs))
+	for i, id := range noteIDs {
+		ids[i] = id.Hex()
+	}
+	return q.setCategory(keywordsCondition("note_id", ids), category)
+}
+
+// setCategory sets the category in the payload of the points matching the condition
+func (q *QdrantClient) setCategory(condition *pb.Condition, category string) error {
+	_, err := q.pointsClient.SetPayload(context.Background(), &pb.SetPayloadPoints{
+		CollectionName: config.COLLECTION_NAME,
+		Payload: map[string]*pb.Value{
+			"category": {Kind: &pb.Value_StringValue{StringValue: category}},
+		},
+		PointsSelector: &pb.PointsSelector{
+			PointsSelectorOneOf: &pb.PointsSelector_Filter{
+				Filter: &pb.Filter{
+					Must: []*pb.Condition{condition},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update category payloads: %w", err)
+	}
+	return nil
+}
+
+// DeleteByNoteIDAndTypes removes a note's embeddings of the given chunk types
+func (q *QdrantClient) DeleteByNoteIDAndTypes(noteID primitive.ObjectID, chunkTypes []string) error {
+	_, err := q.pointsClient.Delete(context.Background(), &pb.DeletePoints{
+		CollectionName: config.COLLECTION_NAME,
+		Points: &pb.PointsSelector{
+			PointsSelectorOneOf: &pb.PointsSelector_Filter{
+				Filter: &pb.Filter{
+					Must: []*pb.Condition{
+						keywordsCondition("note_id", []string{noteID.Hex()}),
+						keywordsCondition("chunk_type", chunkTypes),
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete embeddings: %w", err)
+	}
+	return nil
+}
+
+// StoredPoint is an embedding as stored in Qdrant, with its references and payload
+type StoredPoint struct {
+	ID      uint64
+	ChunkID string
+	NoteID  string
+	Vector  []float32
+	Payload PointPayload
+}
+
+// ScrollPoints returns a page of stored points, starting at offset (nil for the first page)
+// Vectors are only loaded when withVectors is set. The returned offset is nil once there are no more pages.
+func (q *QdrantClient) ScrollPoints(offset *uint64, limit int, withVectors bool) ([]StoredPoint, *uint64, error) {
+	ctx := context.Background()
+
+	pageLimit := uint32(limit)
+	request := &pb.ScrollPoints{
+		CollectionName: config.COLLECTION_NAME,
+		Limit:          &pageLimit,
+		WithPayload:    &pb.WithPayloadSelector{SelectorOptions: &pb.WithPayloadSelector_Enable{Enable: true}},
+		WithVectors:    &pb.WithVectorsSelector{SelectorOptions: &pb.WithVectorsSelector_Enable{Enable: withVectors}},
+	}
+	if offset != nil {
+		request.Offset = &pb.PointId{PointIdOptions: &pb.PointId_Num{Num: *offset}}
+	}
+
+	response, err := q.pointsClient.Scroll(ctx, request)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to scroll points: %w", err)
+	}
+
+	points := make([]StoredPoint, 0, len(response.Result))
+	for _, point := range response.Result {
+		points = append(points, StoredPoint{
+			ID:      point.Id.GetNum(),
+			ChunkID: point.Payload["chunk_id"].GetStringValue(),
+			NoteID:  point.Payload["note_id"].GetStringValue(),
+			Vector:  point.Vectors.GetVector().GetData(),
+			Payload: payloadFromQdrant(point.Payload),
+		})
+	}
+
+	var next *uint64
+	if response.NextPageOffset != nil {
+		num := response.NextPageOffset.GetNum()
+		next = &num
+	}
+	return points, next, nil
+}
+
+// NotePoints returns up to limit of a note's stored points, with their vectors
+func (q *QdrantClient) NotePoints(noteID primitive.ObjectID, limit int) ([]StoredPoint, error) {
+	pageLimit := uint32(limit)
+	response, err := q.pointsClient.Scroll(context.Background(), &pb.ScrollPoints{
+		CollectionName: config.COLLECTION_NAME,
+		Filter:         &pb.Filter{Must: []*pb.Condition{keywordsCondition("note_id", []string{noteID.Hex()})}},
+		Limit:          &pageLimit,
+		WithPayload:    &pb.WithPayloadSelector{SelectorOptions: &pb.WithPayloadSelector_Enable{Enable: true}},
+		WithVectors:    &pb.WithVectorsSelector{SelectorOptions: &pb.WithVectorsSelector_Enable{Enable: true}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get note points: %w", unavailable(err))
+	}
+
+	points := make([]StoredPoint, 0, len(response.Result))
+	for _, point := range response.Result {
+		points = append(points, StoredPoint{
+			ID:      point.Id.GetNum(),
+			ChunkID: point.Payload["chunk_id"].GetStringValue(),
+			NoteID:  point.Payload["note_id"].GetStringValue(),
+			Vector:  point.Vectors.GetVector().GetData(),
+			Payload: payloadFromQdrant(point.Payload),
+		})
+	}
+	return points, nil
+}
+
+// UpsertPoints writes stored points in a single request, replacing points with the same ID
+func (q *QdrantClient) UpsertPoints(points []StoredPoint) error {
+	ctx := context.Background()
+
+	structs := make([]*pb.PointStruct, 0, len(points))
+	for _, point := range points {
+		structs = append(structs, &pb.PointStruct{
+			Id: &pb.PointId{
+				PointIdOptions: &pb.PointId_Num{Num: point.ID},
+			},
+			Vectors: &pb.Vectors{
+				VectorsOptions: &pb.Vectors_Vector{
+					Vector: &pb.Vector{Data: point.Vector},
+				},
+			},
+			Payload: point.Payload.toQdrant(point.ChunkID, point.NoteID),
+		})
+	}
+
+	_, err := q.pointsClient.Upsert(ctx, &pb.UpsertPoints{
+		CollectionName: config.COLLECTION_NAME,
+		Points:         structs,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upsert points: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,277 @@
+// Package youtube reads channel uploads from the YouTube Data API and video transcripts from
+// the caption tracks listed on a video's watch page.
+package youtube
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/config"
+)
+
+// ErrNoTranscript is returned for videos without captions, e.g. premieres and fresh uploads
+// whose automatic captions haven't been generated yet
+var ErrNoTranscript = errors.New("no transcript available")
+
+// Video is an upload listed in a channel's uploads playlist
+type Video struct {
+	ID          string
+	Title       string
+	PublishedAt time.Time
+}
+
+// Client calls the YouTube Data API with an API key
+type Client struct {
+	apiKey   string
+	apiURL   string
+	watchURL string
+	client   *http.Client
+}
+
+// NewClient creates a client from the configured API key
+// Returns nil when no key is configured, so channel sync is simply disabled
+func NewClient(cfg *config.Config) *Client {
+	if cfg.YouTubeAPIKey == "" {
+		return nil
+	}
+	return &Client{
+		apiKey:   cfg.YouTubeAPIKey,
+		apiURL:   strings.TrimSuffix(cfg.YouTubeAPIURL, "/"),
+		watchURL: cfg.YouTubeWatchURL,
+		client:   &http.Client{Timeout: config.YOUTUBE_TIMEOUT_SECONDS * time.Second},
+	}
+}
+
+// WatchURL returns the canonical URL of a video, as saved in metadata.url by the browser extension
+func WatchURL(videoID string) string {
+	return "https://www.youtube.com/watch?v=" + url.QueryEscape(videoID)
+}
+
+// UploadsPlaylist resolves a channel URL to the ID of the channel's uploads playlist
+// Accepts /channel/<id>, /@handle, /user/<name> and /c/<name> URLs, with or without a trailing tab
+func (c *Client) UploadsPlaylist(ctx context.Context, channelURL string) (string, error) {
+	params, err := channelLookup(channelURL)
+	if err != nil {
+		return "", err
+	}
+	params.Set("part", "contentDetails")
+
+	var resp struct {
+		Items []struct {
+			ContentDetails struct {
+				RelatedPlaylists struct {
+					Uploads string `json:"uploads"`
+				} `json:"relatedPlaylists"`
+			} `json:"contentDetails"`
+		} `json:"items"`
+	}
+	if err := c.get(ctx, "/channels", params, &resp); err != nil {
+		return "", err
+	}
+	if len(resp.Items) == 0 || resp.Items[0].ContentDetails.RelatedPlaylists.Uploads == "" {
+		return "", fmt.Errorf("channel not found: %s", channelURL)
+	}
+	return resp.Items[0].ContentDetails.RelatedPlaylists.Uploads, nil
+}
+
+// RecentUploads returns up to max of the latest videos in an uploads playlist, newest first
+// Private and deleted videos, which have no publish date, are left out
+func (c *Client) RecentUploads(ctx context.Context, playlistID string, max int) ([]Video, error) {
+	params := url.Values{}
+	params.Set("part", "snippet,contentDetails")
+	params.Set("playlistId", playlistID)
+	params.Set("maxResults", strconv.Itoa(max))
+
+	var resp struct {
+		Items []struct {
+			Snippet struct {
+				Title string `json:"title"`
+			} `json:"snippet"`
+			ContentDetails struct {
+				VideoID          string `json:"videoId"`
+				VideoPublishedAt string `json:"videoPublishedAt"`
+			} `json:"contentDetails"`
+		} `json:"items"`
+	}
+	if err := c.get(ctx, "/playlistItems", params, &resp); err != nil {
+		return nil, err
+	}
+
+	videos := []Video{}
+	for _, item := range resp.Items {
+		published, err := time.Parse(time.RFC3339, item.ContentDetails.VideoPublishedAt)
+		if item.ContentDetails.VideoID == "" || err != nil {
+			continue
+		}
+		videos = append(videos, Video{
+			ID:          item.ContentDetails.VideoID,
+			Title:       item.Snippet.Title,
+			PublishedAt: published,
+		})
+	}
+	return videos, nil
+}
+
+// Transcript returns the text of a video's captions, preferring English and uploaded captions
+// over automatic ones. The Data API only serves captions to the video owner, so the tracks are
+// read from the public watch page instead.
+func (c *Client) Transcript(ctx context.Context, videoID string) (string, error) {
+	page, err := c.fetch(ctx, c.watchURL+"?v="+url.QueryEscape(videoID))
+	if err != nil {
+		return "", fmt.Errorf("failed to load watch page: %w", err)
+	}
+
+	const marker = `"captionTracks":`
+	start := bytes.Index(page, []byte(marker))
+	if start < 0 {
+		return "", ErrNoTranscript
+	}
+	var tracks []captionTrack
+	if err := json.NewDecoder(bytes.NewReader(page[start+len(marker):])).Decode(&tracks); err != nil {
+		return "", fmt.Errorf("failed to parse caption tracks: %w", err)
+	}
+	track := pickTrack(tracks)
+	if track == nil {
+		return "", ErrNoTranscript
+	}
+
+	body, err := c.fetch(ctx, track.BaseURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to load captions: %w", err)
+	}
+	var captions struct {
+		Texts []string `xml:"text"`
+	}
+	if err := xml.Unmarshal(body, &captions); err != nil {
+		return "", fmt.Errorf("failed to parse captions: %w", err)
+	}
+
+	lines := make([]string, 0, len(captions.Texts))
+	for _, text := range captions.Texts {
+		// Caption text is HTML-escaped inside the XML, e.g. &amp;#39; for an apostrophe
+		if line := strings.Join(strings.Fields(html.UnescapeString(text)), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		return "", ErrNoTranscript
+	}
+	return strings.Join(lines, " "), nil
+}
+
+// captionTrack is an entry of the captionTracks list embedded in a watch page
+type captionTrack struct {
+	BaseURL      string `json:"baseUrl"`
+	LanguageCode string `json:"languageCode"`
+	Kind         string `json:"kind"` // "asr" for automatic captions
+}
+
+// pickTrack chooses the caption track to transcribe
+func pickTrack(tracks []captionTrack) *captionTrack {
+	best, bestRank := -1, -1
+	for i, track := range tracks {
+		if track.BaseURL == "" {
+			continue
+		}
+		rank := 0
+		if strings.HasPrefix(track.LanguageCode, "en") {
+			rank += 2
+		}
+		if track.Kind != "asr" {
+			rank++
+		}
+		if rank > bestRank {
+			best, bestRank = i, rank
+		}
+	}
+	if best < 0 {
+		return nil
+	}
+	return &tracks[best]
+}
+
+// channelLookup returns the channels.list parameters identifying the channel at a URL
+func channelLookup(channelURL string) (url.Values, error) {
+	raw := strings.TrimSpace(channelURL)
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid channel URL: %w", err)
+	}
+	if host := parsed.Hostname(); host != "youtube.com" && !strings.HasSuffix(host, ".youtube.com") {
+		return nil, fmt.Errorf("invalid channel URL: %q is not a YouTube URL", channelURL)
+	}
+
+	segments := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	params := url.Values{}
+	switch {
+	case strings.HasPrefix(segments[0], "@") && len(segments[0]) > 1:
+		params.Set("forHandle", segments[0])
+	case segments[0] == "channel" && len(segments) > 1 && segments[1] != "":
+		params.Set("id", segments[1])
+	case segments[0] == "user" && len(segments) > 1 && segments[1] != "":
+		params.Set("forUsername", segments[1])
+	case segments[0] == "c" && len(segments) > 1 && segments[1] != "":
+		// Legacy custom URLs can't be looked up; most match the channel's handle
+		params.Set("forHandle", "@"+segments[1])
+	default:
+		return nil, fmt.Errorf("invalid channel URL: %q is not a channel URL", channelURL)
+	}
+	return params, nil
+}
+
+// get calls a Data API endpoint and decodes the JSON response into out
+func (c *Client) get(ctx context.Context, path string, params url.Values, out interface{}) error {
+	params.Set("key", c.apiKey)
+	body, err := c.fetch(ctx, c.apiURL+path+"?"+params.Encode())
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to decode YouTube API response: %w", err)
+	}
+	return nil
+}
+
+// fetch GETs a URL, returning the body of a 2xx response
+func (c *Client) fetch(ctx context.Context, rawURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept-Language", "en")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		// The Data API explains failures such as an exhausted quota in error.message
+		var apiErr struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 2048))
+		if json.Unmarshal(snippet, &apiErr) == nil && apiErr.Error.Message != "" {
+			return nil, fmt.Errorf("YouTube returned status %d: %s", resp.StatusCode, apiErr.Error.Message)
+		}
+		return nil, fmt.Errorf("YouTube returned status %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, config.MAX_YOUTUBE_RESPONSE_BYTES))
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
 	"time"
 
@@ -9,17 +10,24 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"backend/internal/ai"
+	"backend/internal/clipper"
 	"backend/internal/config"
+	"backend/internal/graphql"
 	"backend/internal/handlers"
+	"backend/internal/podcast"
 	"backend/internal/repository"
+	"backend/internal/searchindex"
 	"backend/internal/services"
+	"backend/internal/transcribe"
+	"backend/internal/utils"
 	"backend/internal/vectordb"
+	"backend/internal/youtube"
 )
 
 func main() {
 	// Load configuration
 	cfg := config.LoadConfig()
-	if cfg.GeminiAPIKey == "" {
+	if cfg.AIProvider == config.AI_PROVIDER_GEMINI && cfg.GeminiAPIKey == "" {
 		log.Fatal("GEMINI_API_KEY environment variable is required")
 	}
 
@@ -33,6 +41,90 @@ func main() {
 	notesRepo := repository.NewNotesRepository(mongoClient.GetDatabase())
 	chunksRepo := repository.NewChunksRepository(mongoClient.GetDatabase())
 	channelSettingsRepo := repository.NewChannelSettingsRepository(mongoClient.GetDatabase())
+	rulesRepo := repository.NewCategoryRulesRepository(mongoClient.GetDatabase())
+	examplesRepo := repository.NewClassificationExamplesRepository(mongoClient.GetDatabase())
+	auditRepo := repository.NewAuditRepository(mongoClient.GetDatabase())
+	conversationsRepo := repository.NewConversationsRepository(mongoClient.GetDatabase())
+	searchQueriesRepo := repository.NewSearchQueriesRepository(mongoClient.GetDatabase())
+	reconciliationRepo := repository.NewReconciliationRepository(mongoClient.GetDatabase())
+	noteEventsRepo := repository.NewNoteEventsRepository(mongoClient.GetDatabase())
+	webhooksRepo := repository.NewWebhooksRepository(mongoClient.GetDatabase())
+	webhookSecretsRepo := repository.NewWebhookSecretsRepository(mongoClient.GetDatabase())
+	goalsRepo := repository.NewGoalsRepository(mongoClient.GetDatabase())
+	podcastFeedsRepo := repository.NewPodcastFeedsRepository(mongoClient.GetDatabase())
+	migrationRunsRepo := repository.NewMigrationRunsRepository(mongoClient.GetDatabase())
+	jobsRepo := repository.NewJobsRepository(mongoClient.GetDatabase())
+	deadLetterRepo := repository.NewDeadLetterRepository(mongoClient.GetDatabase())
+	settingsRepo := repository.NewSettingsRepository(mongoClient.GetDatabase())
+	channelPresetsRepo := repository.NewChannelPresetsRepository(mongoClient.GetDatabase())
+	aiOperationsRepo := repository.NewAIOperationsRepository(mongoClient.GetDatabase())
+	aiSamplesRepo := repository.NewAISamplesRepository(mongoClient.GetDatabase())
+	graphRepo := repository.NewGraphRepository(mongoClient.GetDatabase())
+	automationRepo := repository.NewAutomationRulesRepository(mongoClient.GetDatabase())
+	categoriesRepo := repository.NewCategoriesRepository(mongoClient.GetDatabase())
+	filesRepo, err := repository.NewFilesRepository(mongoClient.GetDatabase())
+	if err != nil {
+		log.Fatal("Failed to open uploads bucket:", err)
+	}
+
+	if err := notesRepo.EnsureTextIndex(context.Background()); err != nil {
+		log.Printf("Warning: Failed to create notes text index: %v", err)
+	}
+	if err := notesRepo.EnsureSuggestIndexes(context.Background()); err != nil {
+		log.Printf("Warning: Failed to create notes suggestion indexes: %v", err)
+	}
+	if updated, err := notesRepo.BackfillTitleWords(context.Background()); err != nil {
+		log.Printf("Warning: Failed to backfill note title words: %v", err)
+	} else if updated > 0 {
+		log.Printf("Backfilled title words for %d notes", updated)
+	}
+	if err := notesRepo.EnsureVaultPathIndex(context.Background()); err != nil {
+		log.Printf("Warning: Failed to create notes vault path index: %v", err)
+	}
+	if err := notesRepo.EnsureEntityIndex(context.Background()); err != nil {
+		log.Printf("Warning: Failed to create notes entities index: %v", err)
+	}
+	if err := channelSettingsRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("Warning: Failed to create channel settings index: %v", err)
+	}
+	if err := searchQueriesRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("Warning: Failed to create search queries index: %v", err)
+	}
+	if err := podcastFeedsRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("Warning: Failed to create podcast feeds index: %v", err)
+	}
+	if err := jobsRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("Warning: Failed to create processing jobs index: %v", err)
+	}
+	if err := aiOperationsRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("Warning: Failed to create AI operations index: %v", err)
+	}
+	if err := graphRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Printf("Warning: Failed to create graph edges index: %v", err)
+	}
+
+	// Initialize AI client
+	aiClient, err := ai.NewAIClient(context.Background(), cfg)
+	if err != nil {
+		log.Fatal("Failed to create AI client:", err)
+	}
+	defer aiClient.Close()
+
+	// With AI_SAMPLE_RATE, a sample of raw prompts and replies is kept in a capped collection for debugging
+	aiSampleService := services.NewAISampleService(aiSamplesRepo, cfg.AISampleRate)
+	if cfg.AISampleRate > 0 {
+		if err := aiSamplesRepo.EnsureCollection(context.Background(), config.AI_SAMPLES_MAX_BYTES); err != nil {
+			log.Printf("Warning: Failed to create AI samples collection, AI sampling disabled: %v", err)
+		} else {
+			aiClient.SetSampler(aiSampleService.Sample)
+		}
+	}
+
+	// The collection is created for, and checked against, the embedding model's vector length
+	embeddingDim, err := ai.DetectEmbeddingDimension(aiClient, cfg.EmbeddingDim)
+	if err != nil {
+		log.Fatal("Invalid embedding configuration: ", err)
+	}
 
 	// Initialize Qdrant vector database client
 	qdrantClient, err := vectordb.NewQdrantClient(cfg.QdrantURL)
@@ -41,49 +133,184 @@ func main() {
 	}
 	defer qdrantClient.Close()
 
-	if err := qdrantClient.Initialize(); err != nil {
+	if err := qdrantClient.Initialize(embeddingDim); err != nil {
+		if errors.Is(err, vectordb.ErrDimensionMismatch) {
+			log.Fatalf("Failed to initialize Qdrant: %v. The embedding model has changed: switch back to the previous one, or delete the collection and run POST /admin/reindex once the server is up", err)
+		}
 		log.Fatal("Failed to initialize Qdrant:", err)
 	}
 
-	// Initialize AI client
-	aiClient, err := ai.NewAIClient(context.Background(), cfg.GeminiAPIKey)
-	if err != nil {
-		log.Fatal("Failed to create AI client:", err)
+	// Warm up vector search in the background; GET /ready reports 503 until it is done
+	readinessService := services.NewReadinessService(qdrantClient)
+	readinessService.Start()
+	defer readinessService.Stop()
+
+	// Deliver note events to registered webhooks in the background
+	webhookService := services.NewWebhookService(noteEventsRepo, webhooksRepo, webhookSecretsRepo, utils.PublicAddress)
+	webhookService.Start()
+	defer webhookService.Stop()
+
+	// Push note events to clients connected to GET /events
+	eventStream := services.NewEventStream()
+	webhookService.Subscribe(eventStream.Publish)
+
+	// Category rules are evaluated from a cache dropped whenever a rule changes, renames included
+	rulesService := services.NewRulesService(rulesRepo)
+
+	// Classification and validation read the categories from a cache kept in sync with the collection
+	categoryService := services.NewCategoryService(categoriesRepo, notesRepo, rulesService, automationRepo, examplesRepo, settingsRepo, webhookService, qdrantClient)
+	if err := categoryService.Load(context.Background()); err != nil {
+		log.Printf("Warning: %v, using the default categories until the next refresh", err)
 	}
-	defer aiClient.Close()
+	categoryService.Start()
+	defer categoryService.Stop()
 
 	// Initialize worker pool for background embedding generation
-	workerPool := services.NewWorkerPool(3, 100, chunksRepo, aiClient, qdrantClient)
+	workerPool := services.NewWorkerPool(3, jobsRepo, deadLetterRepo, notesRepo, webhookService, chunksRepo, channelSettingsRepo, aiClient, qdrantClient, cfg)
 	workerPool.Start()
 	defer workerPool.Stop()
 
+	// Optionally mirror notes into Meilisearch/Typesense, synced from note events
+	keywordIndex, err := searchindex.NewClient(cfg)
+	if err != nil {
+		log.Fatal("Failed to create search index client:", err)
+	}
+	var searchIndexService *services.SearchIndexService
+	if keywordIndex != nil {
+		if err := keywordIndex.EnsureIndex(context.Background()); err != nil {
+			log.Printf("Warning: Failed to create search index: %v", err)
+		}
+		searchIndexService = services.NewSearchIndexService(notesRepo, noteEventsRepo, settingsRepo, keywordIndex)
+		webhookService.Subscribe(searchIndexService.HandleEvent)
+		searchIndexService.Start()
+		defer searchIndexService.Stop()
+	}
+
+	// Track goals and their progress from new notes in the background
+	goalsService := services.NewGoalsService(goalsRepo, notesRepo, aiClient, cfg)
+	webhookService.Subscribe(goalsService.HandleEvent)
+	goalsService.Start()
+	defer goalsService.Stop()
+
 	// Create services
+	reviewService := services.NewReviewService(notesRepo, examplesRepo, webhookService, qdrantClient, cfg)
+	pipelineService := services.NewPipelineService(settingsRepo)
+	aiHistoryService := services.NewAIHistoryService(aiOperationsRepo, notesRepo, cfg)
+	presetService := services.NewPresetService(channelPresetsRepo, channelSettingsRepo)
+	automationService := services.NewAutomationService(automationRepo, presetService)
+
 	notesService := services.NewNotesService(
 		notesRepo,
 		chunksRepo,
 		channelSettingsRepo,
+		auditRepo,
 		aiClient,
 		qdrantClient,
 		workerPool,
+		rulesService,
+		reviewService,
+		webhookService,
+		pipelineService,
+		automationService,
+		aiHistoryService,
+		cfg,
 	)
 
 	searchService := services.NewSearchService(
 		notesRepo,
+		chunksRepo,
+		channelSettingsRepo,
+		settingsRepo,
 		aiClient,
 		qdrantClient,
+		keywordIndex,
+		cfg,
 	)
 
+	// Keep the knowledge graph's edges current as notes are processed and deleted
+	graphService := services.NewGraphService(graphRepo, notesRepo, searchService)
+	webhookService.Subscribe(graphService.HandleEvent)
+	graphService.Start()
+	defer graphService.Stop()
+
+	suggestService := services.NewSuggestService(notesRepo, searchQueriesRepo)
+	spellService := services.NewSpellService(notesRepo)
+	conversationService := services.NewConversationService(conversationsRepo, searchService)
+	embeddingsService := services.NewEmbeddingsService(qdrantClient)
+	exportService := services.NewExportService(notesRepo, chunksRepo, channelSettingsRepo, workerPool, webhookService)
+
+	// Periodically check Mongo chunks against Qdrant vectors for drift
+	reconciliationService := services.NewReconciliationService(chunksRepo, reconciliationRepo, qdrantClient, cfg)
+	reconciliationService.Start()
+	defer reconciliationService.Stop()
+
+	// Periodically save new uploads of YouTube channels that have a channel URL
+	var youtubeSyncService *services.YouTubeSyncService
+	if youtubeClient := youtube.NewClient(cfg); youtubeClient != nil {
+		youtubeSyncService = services.NewYouTubeSyncService(channelSettingsRepo, notesRepo, notesService, youtubeClient, cfg)
+		youtubeSyncService.Start()
+		defer youtubeSyncService.Stop()
+	}
+
+	// Periodically save new episodes of followed podcast feeds
+	podcastService := services.NewPodcastService(podcastFeedsRepo, notesRepo, notesService, podcast.NewClient(utils.PublicAddress), transcribe.NewClient(cfg), cfg)
+	podcastService.Start()
+	defer podcastService.Stop()
+
 	summaryService := services.NewSummaryService(
 		notesRepo,
 		channelSettingsRepo,
 		aiClient,
+		workerPool,
+		webhookService,
+		aiHistoryService,
+		cfg,
 	)
 
+	// A re-summarization cut short by a restart picks up where it stopped
+	migrationService := services.NewMigrationService(notesRepo, migrationRunsRepo, aiClient, qdrantClient, rulesService, reviewService, summaryService, aiHistoryService, cfg)
+	migrationService.ResumeResummarize(context.Background())
+	defer migrationService.Stop()
+
 	// Create handlers
 	notesHandler := handlers.NewNotesHandler(notesService)
-	searchHandler := handlers.NewSearchHandler(searchService, aiClient)
-	categoriesHandler := handlers.NewCategoriesHandler(notesRepo, aiClient)
+	searchHandler := handlers.NewSearchHandler(searchService, suggestService, spellService, services.NewAnswerNotesService(notesService), aiClient)
+	categoriesHandler := handlers.NewCategoriesHandler(notesRepo, categoryService)
 	summaryHandler := handlers.NewSummaryHandler(summaryService)
+	migrationsHandler := handlers.NewMigrationsHandler(migrationService)
+	resummarizeHandler := handlers.NewResummarizeHandler(migrationService, cfg.AdminToken)
+	estimatesHandler := handlers.NewEstimatesHandler(migrationService, cfg.AdminToken)
+	aiSamplesHandler := handlers.NewAISamplesHandler(aiSampleService, cfg.AdminToken)
+	aiHistoryHandler := handlers.NewAIHistoryHandler(aiHistoryService)
+	translationHandler := handlers.NewTranslationHandler(services.NewTranslationService(notesRepo, aiClient, webhookService, aiHistoryService, cfg))
+	jobsHandler := handlers.NewJobsHandler(services.NewJobsService(jobsRepo, deadLetterRepo, notesRepo, workerPool), cfg.AdminToken)
+	rulesHandler := handlers.NewRulesHandler(rulesService)
+	reviewHandler := handlers.NewReviewHandler(reviewService)
+	conversationsHandler := handlers.NewConversationsHandler(conversationService)
+	embeddingsHandler := handlers.NewEmbeddingsHandler(embeddingsService, cfg.AdminToken)
+	topicsHandler := handlers.NewTopicsHandler(services.NewTopicService(notesRepo, aiClient, qdrantClient))
+	reconciliationHandler := handlers.NewReconciliationHandler(reconciliationService, cfg.AdminToken)
+	reindexHandler := handlers.NewReindexHandler(services.NewReindexService(notesRepo, chunksRepo, migrationRunsRepo, workerPool, qdrantClient, cfg), cfg.AdminToken)
+	exportHandler := handlers.NewExportHandler(exportService)
+	notionHandler := handlers.NewNotionHandler(services.NewNotionImportService(notesService))
+	vaultSyncHandler := handlers.NewVaultSyncHandler(services.NewVaultSyncService(notesRepo, notesService))
+	webhooksHandler := handlers.NewWebhooksHandler(webhookService)
+	eventsHandler := handlers.NewEventsHandler(eventStream)
+	goalsHandler := handlers.NewGoalsHandler(goalsService)
+	statsHandler := handlers.NewStatsHandler(services.NewStatsService(notesRepo))
+	entitiesHandler := handlers.NewEntitiesHandler(services.NewEntityService(notesRepo))
+	graphHandler := handlers.NewGraphHandler(graphService, cfg.AdminToken)
+	stalenessHandler := handlers.NewStalenessHandler(services.NewStalenessService(notesRepo, aiClient))
+	podcastsHandler := handlers.NewPodcastsHandler(podcastService)
+	clipHandler := handlers.NewClipHandler(services.NewClipService(notesRepo, notesService, clipper.NewClient(utils.PublicAddress)))
+	uploadsHandler := handlers.NewUploadsHandler(services.NewUploadService(notesRepo, filesRepo, notesService, aiClient))
+	graphqlHandler := handlers.NewGraphQLHandler(graphql.NewSchema(notesService, searchService, channelSettingsRepo))
+	personaHandler := handlers.NewPersonaHandler(services.NewPersonaService(settingsRepo))
+	pipelineHandler := handlers.NewPipelineHandler(pipelineService)
+	presetsHandler := handlers.NewPresetsHandler(presetService)
+	automationsHandler := handlers.NewAutomationsHandler(automationService)
+	platformsHandler := handlers.NewPlatformsHandler(notesRepo, channelSettingsRepo)
+	healthHandler := handlers.NewHealthHandler(readinessService)
 	channelsHandler := handlers.NewChannelsHandler(
 		notesRepo,
 		chunksRepo,
@@ -93,22 +320,79 @@ func main() {
 
 	// Configure Gin router
 	r := gin.Default()
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Fatal("Invalid TRUSTED_PROXIES: ", err)
+	}
 
 	r.Use(cors.New(cors.Config{
 		AllowAllOrigins:  true,
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		ExposeHeaders:    []string{"Content-Length", "X-Corrected-Query", "X-Search-Degraded", "X-Min-Score"},
 		AllowCredentials: false,
 		MaxAge:           12 * time.Hour,
 	}))
+	if len(cfg.AdminAllowedIPs) > 0 {
+		adminNetworks, err := handlers.ParseNetworks(cfg.AdminAllowedIPs)
+		if err != nil {
+			log.Fatal("Invalid ADMIN_ALLOWED_IPS: ", err)
+		}
+		r.Use(handlers.RestrictAdminNetwork(adminNetworks))
+	}
 
 	// Register routes
+	healthHandler.RegisterRoutes(r)
 	notesHandler.RegisterRoutes(r)
 	searchHandler.RegisterRoutes(r)
 	categoriesHandler.RegisterRoutes(r)
 	summaryHandler.RegisterRoutes(r)
+	migrationsHandler.RegisterRoutes(r)
+	jobsHandler.RegisterRoutes(r)
 	channelsHandler.RegisterRoutes(r)
+	rulesHandler.RegisterRoutes(r)
+	reviewHandler.RegisterRoutes(r)
+	platformsHandler.RegisterRoutes(r)
+	conversationsHandler.RegisterRoutes(r)
+	personaHandler.RegisterRoutes(r)
+	pipelineHandler.RegisterRoutes(r)
+	presetsHandler.RegisterRoutes(r)
+	automationsHandler.RegisterRoutes(r)
+	embeddingsHandler.RegisterRoutes(r)
+	topicsHandler.RegisterRoutes(r)
+	reconciliationHandler.RegisterRoutes(r)
+	reindexHandler.RegisterRoutes(r)
+	resummarizeHandler.RegisterRoutes(r)
+	estimatesHandler.RegisterRoutes(r)
+	aiSamplesHandler.RegisterRoutes(r)
+	aiHistoryHandler.RegisterRoutes(r)
+	translationHandler.RegisterRoutes(r)
+	exportHandler.RegisterRoutes(r)
+	notionHandler.RegisterRoutes(r)
+	vaultSyncHandler.RegisterRoutes(r)
+	webhooksHandler.RegisterRoutes(r)
+	eventsHandler.RegisterRoutes(r)
+	goalsHandler.RegisterRoutes(r)
+	statsHandler.RegisterRoutes(r)
+	entitiesHandler.RegisterRoutes(r)
+	graphHandler.RegisterRoutes(r)
+	stalenessHandler.RegisterRoutes(r)
+	podcastsHandler.RegisterRoutes(r)
+	clipHandler.RegisterRoutes(r)
+	uploadsHandler.RegisterRoutes(r)
+	graphqlHandler.RegisterRoutes(r)
+	if searchIndexService != nil {
+		handlers.NewSearchIndexHandler(searchIndexService, cfg.AdminToken).RegisterRoutes(r)
+	}
+	if youtubeSyncService != nil {
+		handlers.NewYouTubeSyncHandler(youtubeSyncService).RegisterRoutes(r)
+	}
+	if cfg.EnablePprof {
+		if cfg.AdminToken != "" {
+			handlers.NewProfilingHandler(cfg.AdminToken).RegisterRoutes(r)
+		} else {
+			log.Println("ENABLE_PPROF is set but ADMIN_TOKEN is empty; profiling endpoints disabled")
+		}
+	}
 
 	// Start server
 	log.Println("Server starting on :8080")
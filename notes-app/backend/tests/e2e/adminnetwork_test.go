@@ -0,0 +1,101 @@
+package e2e
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"backend/internal/handlers"
+)
+
+func TestAdminNetworkPolicy(t *testing.T) {
+	networks, err := handlers.ParseNetworks([]string{"203.0.113.0/24", "2001:db8::1"})
+	if err != nil {
+		t.Fatalf("Failed to parse networks: %v", err)
+	}
+
+	router := gin.New()
+	if err := router.SetTrustedProxies([]string{"10.0.0.1"}); err != nil {
+		t.Fatalf("Failed to set trusted proxies: %v", err)
+	}
+	router.Use(handlers.RestrictAdminNetwork(networks))
+	handlers.NewProfilingHandler(testAdminToken).RegisterRoutes(router)
+	ok := func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{}) }
+	router.POST("/migrate/classify", ok)
+	router.POST("/notes/bulk-delete", ok)
+	router.DELETE("/channels/:channel/notes", ok)
+	router.GET("/channels/:channel/notes", ok)
+	router.GET("/notes", ok)
+	router.GET("/administrators", ok)
+
+	request := func(method, path, remoteAddr, forwardedFor string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("Authorization", "Bearer "+testAdminToken)
+		if forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	t.Run("admin routes accept allowed addresses", func(t *testing.T) {
+		if code := request("GET", "/debug/pprof/", "203.0.113.7:5000", ""); code != http.StatusOK {
+			t.Errorf("Expected status 200 from an allowed network, got %d", code)
+		}
+		if code := request("POST", "/migrate/classify", "[2001:db8::1]:5000", ""); code != http.StatusOK {
+			t.Errorf("Expected status 200 from an allowed address, got %d", code)
+		}
+	})
+
+	t.Run("admin routes reject other addresses even with the token", func(t *testing.T) {
+		if code := request("GET", "/debug/pprof/", "198.51.100.7:5000", ""); code != http.StatusForbidden {
+			t.Errorf("Expected status 403, got %d", code)
+		}
+		if code := request("POST", "/migrate/classify", "[2001:db8::2]:5000", ""); code != http.StatusForbidden {
+			t.Errorf("Expected status 403, got %d", code)
+		}
+	})
+
+	t.Run("bulk delete routes are admin routes", func(t *testing.T) {
+		for _, route := range [][2]string{{"POST", "/notes/bulk-delete"}, {"DELETE", "/channels/some-channel/notes"}} {
+			if code := request(route[0], route[1], "198.51.100.7:5000", ""); code != http.StatusForbidden {
+				t.Errorf("%s %s: expected status 403 from another address, got %d", route[0], route[1], code)
+			}
+			if code := request(route[0], route[1], "203.0.113.7:5000", ""); code != http.StatusOK {
+				t.Errorf("%s %s: expected status 200 from an allowed address, got %d", route[0], route[1], code)
+			}
+		}
+	})
+
+	t.Run("X-Forwarded-For only counts from trusted proxies", func(t *testing.T) {
+		if code := request("POST", "/migrate/classify", "10.0.0.1:5000", "203.0.113.7"); code != http.StatusOK {
+			t.Errorf("Expected status 200 for an allowed client behind the proxy, got %d", code)
+		}
+		if code := request("POST", "/migrate/classify", "10.0.0.1:5000", "198.51.100.7"); code != http.StatusForbidden {
+			t.Errorf("Expected status 403 for another client behind the proxy, got %d", code)
+		}
+		if code := request("POST", "/migrate/classify", "198.51.100.7:5000", "203.0.113.7"); code != http.StatusForbidden {
+			t.Errorf("Expected status 403 for a forged header, got %d", code)
+		}
+	})
+
+	t.Run("other routes accept any address", func(t *testing.T) {
+		for _, path := range []string{"/notes", "/administrators", "/channels/some-channel/notes"} {
+			if code := request("GET", path, "198.51.100.7:5000", ""); code != http.StatusOK {
+				t.Errorf("%s: expected status 200, got %d", path, code)
+			}
+		}
+	})
+
+	t.Run("ParseNetworks rejects invalid entries", func(t *testing.T) {
+		for _, entry := range []string{"10.0.0.0/33", "not-an-ip", "10.0.0"} {
+			if _, err := handlers.ParseNetworks([]string{entry}); err == nil {
+				t.Errorf("%q: expected an error", entry)
+			}
+		}
+	})
+}
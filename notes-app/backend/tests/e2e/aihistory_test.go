@@ -0,0 +1,84 @@
+package e2e
+
+import (
+	"net/http"
+	"testing"
+
+	"backend/internal/ai"
+	"backend/internal/models"
+)
+
+func TestAIHistoryAPI(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	CleanupCollections(t, env)
+
+	w := HTTPRequest(t, env, "POST", "/notes", map[string]interface{}{
+		"content": "Notes from the planning meeting about the spring release",
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var note models.Note
+	ParseResponse(t, w, &note)
+
+	t.Run("creating a note records its analysis", func(t *testing.T) {
+		var history []models.AIOperation
+		ParseResponse(t, HTTPRequest(t, env, "GET", "/notes/"+note.ID.Hex()+"/ai-history", nil), &history)
+		if len(history) != 1 {
+			t.Fatalf("Expected 1 operation, got %+v", history)
+		}
+
+		op := history[0]
+		if op.Operation != models.AIOperationAnalysis || op.NoteID != note.ID || op.Error != "" {
+			t.Errorf("Expected the note's analysis, got %+v", op)
+		}
+		if op.PromptVersion != ai.PromptVersions[models.AIOperationAnalysis] || op.Model == "" {
+			t.Errorf("Expected the model and prompt version recorded, got %+v", op)
+		}
+		if op.InputTokens == 0 || op.OutputTokens == 0 || op.Output == "" {
+			t.Errorf("Expected token usage and output recorded, got %+v", op)
+		}
+	})
+
+	t.Run("re-titling an updated note is listed first", func(t *testing.T) {
+		w := HTTPRequest(t, env, "PUT", "/notes/"+note.ID.Hex(), map[string]interface{}{
+			"content": "Revised notes from the planning meeting",
+		})
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var history []models.AIOperation
+		ParseResponse(t, HTTPRequest(t, env, "GET", "/notes/"+note.ID.Hex()+"/ai-history", nil), &history)
+		if len(history) != 2 || history[0].Operation != models.AIOperationTitle || history[1].Operation != models.AIOperationAnalysis {
+			t.Fatalf("Expected the title then the analysis, got %+v", history)
+		}
+	})
+
+	t.Run("a note given its title and category has no AI history", func(t *testing.T) {
+		w := HTTPRequest(t, env, "POST", "/notes", map[string]interface{}{
+			"title":    "Groceries",
+			"content":  "Milk, eggs, bread",
+			"category": "other",
+		})
+		var manual models.Note
+		ParseResponse(t, w, &manual)
+
+		var history []models.AIOperation
+		ParseResponse(t, HTTPRequest(t, env, "GET", "/notes/"+manual.ID.Hex()+"/ai-history", nil), &history)
+		if len(history) != 0 {
+			t.Errorf("Expected no operations, got %+v", history)
+		}
+	})
+
+	t.Run("GET /notes/:id/ai-history rejects unknown notes", func(t *testing.T) {
+		if w := HTTPRequest(t, env, "GET", "/notes/not-an-id/ai-history", nil); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for an invalid ID, got %d", w.Code)
+		}
+		if w := HTTPRequest(t, env, "GET", "/notes/000000000000000000000000/ai-history", nil); w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 for a missing note, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,92 @@
+package e2e
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/services"
+)
+
+func TestAISamples(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	CleanupCollections(t, env)
+
+	// Samples as the AI client passes them on: the raw prompt and reply
+	sampler := services.NewAISampleService(repository.NewAISamplesRepository(env.Database), 1)
+	sampler.Sample(models.AISample{
+		Operation: "GenerateTitle",
+		Model:     "test-model",
+		Prompt:    "Title this note: deploy with api_key=abcdef1234567890 tonight",
+		Response:  "Tonight's deploy",
+		CreatedAt: time.Now().Add(-time.Minute),
+	})
+	sampler.Sample(models.AISample{
+		Operation: "ClassifyNote",
+		Model:     "test-model",
+		Prompt:    "Classify this note: " + strings.Repeat("x", 30000),
+		Error:     "rate limited",
+		CreatedAt: time.Now(),
+	})
+	services.NewAISampleService(repository.NewAISamplesRepository(env.Database), 0).Sample(models.AISample{Operation: "GenerateSummary"})
+
+	var sampleID string
+
+	t.Run("GET /admin/ai-samples requires the admin token", func(t *testing.T) {
+		if w := adminRequest(env, "GET", "/admin/ai-samples", ""); w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401, got %d", w.Code)
+		}
+	})
+
+	t.Run("GET /admin/ai-samples lists the sampled calls newest first", func(t *testing.T) {
+		w := adminRequest(env, "GET", "/admin/ai-samples", testAdminToken)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var samples []models.AISample
+		ParseResponse(t, w, &samples)
+		if len(samples) != 2 || samples[0].Operation != "ClassifyNote" || samples[1].Operation != "GenerateTitle" {
+			t.Fatalf("Expected the two sampled calls and not the one at rate 0, got %+v", samples)
+		}
+		if !strings.HasSuffix(samples[0].Prompt, "...") || len(samples[0].Prompt) > 20100 || samples[0].Error != "rate limited" {
+			t.Errorf("Expected the long prompt cut off and the error kept, got %d chars and %q", len(samples[0].Prompt), samples[0].Error)
+		}
+
+		if w := adminRequest(env, "GET", "/admin/ai-samples?limit=0", testAdminToken); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for an invalid limit, got %d", w.Code)
+		}
+	})
+
+	t.Run("GET /admin/ai-samples filters by operation", func(t *testing.T) {
+		var samples []models.AISample
+		ParseResponse(t, adminRequest(env, "GET", "/admin/ai-samples?operation=GenerateTitle", testAdminToken), &samples)
+		if len(samples) != 1 {
+			t.Fatalf("Expected one GenerateTitle sample, got %d", len(samples))
+		}
+		sampleID = samples[0].ID.Hex()
+	})
+
+	t.Run("GET /admin/ai-samples/:id returns a sample with secrets redacted", func(t *testing.T) {
+		w := adminRequest(env, "GET", "/admin/ai-samples/"+sampleID, testAdminToken)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var sample models.AISample
+		ParseResponse(t, w, &sample)
+		if sample.Prompt != "Title this note: deploy with [REDACTED] tonight" || sample.Response != "Tonight's deploy" {
+			t.Errorf("Expected the API key redacted, got %q and %q", sample.Prompt, sample.Response)
+		}
+
+		if w := adminRequest(env, "GET", "/admin/ai-samples/000000000000000000000000", testAdminToken); w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 for an unknown sample, got %d", w.Code)
+		}
+		if w := adminRequest(env, "GET", "/admin/ai-samples/not-an-id", testAdminToken); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for an invalid ID, got %d", w.Code)
+		}
+	})
+}
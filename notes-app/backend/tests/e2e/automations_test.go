@@ -0,0 +1,195 @@
+package e2e
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+)
+
+func TestAutomationsAPI(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	CleanupCollections(t, env)
+
+	var ruleID string
+
+	t.Run("POST /automations creates a rule", func(t *testing.T) {
+		w := HTTPRequest(t, env, "POST", "/automations", map[string]interface{}{
+			"name":    "Action items",
+			"trigger": "Tag",
+			"value":   " Action-Items ",
+			"actions": []map[string]string{
+				{"type": "run_step", "value": "extract_entities"},
+				{"type": "add_to_collection", "value": "Todo"},
+			},
+			"enabled": true,
+		})
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var rule models.AutomationRule
+		ParseResponse(t, w, &rule)
+		ruleID = rule.ID.Hex()
+		if rule.Trigger != models.AutomationTriggerTag || rule.Value != "action-items" || rule.Actions[1].Value != "todo" {
+			t.Errorf("Expected the trigger, tag and collection normalized, got %+v", rule)
+		}
+	})
+
+	t.Run("POST /automations rejects invalid rules", func(t *testing.T) {
+		addToCollection := []map[string]string{{"type": "add_to_collection", "value": "todo"}}
+		for name, reqBody := range map[string]map[string]interface{}{
+			"missing name":     {"trigger": "tag", "value": "urgent", "actions": addToCollection},
+			"unknown trigger":  {"name": "x", "trigger": "title", "value": "urgent", "actions": addToCollection},
+			"unknown category": {"name": "x", "trigger": "category", "value": "not-a-category", "actions": addToCollection},
+			"blank channel":    {"name": "x", "trigger": "channel", "value": "  ", "actions": addToCollection},
+			"no actions":       {"name": "x", "trigger": "tag", "value": "urgent"},
+			"unknown step":     {"name": "x", "trigger": "tag", "value": "urgent", "actions": []map[string]string{{"type": "run_step", "value": "classify"}}},
+			"unknown preset":   {"name": "x", "trigger": "tag", "value": "urgent", "actions": []map[string]string{{"type": "apply_preset", "value": "no-such-preset"}}},
+			"blank collection": {"name": "x", "trigger": "tag", "value": "urgent", "actions": []map[string]string{{"type": "add_to_collection", "value": " "}}},
+			"unknown action":   {"name": "x", "trigger": "tag", "value": "urgent", "actions": []map[string]string{{"type": "email", "value": "me"}}},
+		} {
+			w := HTTPRequest(t, env, "POST", "/automations", reqBody)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status 400, got %d", name, w.Code)
+			}
+		}
+	})
+
+	t.Run("GET /automations lists rules and GET /automations/:id returns one", func(t *testing.T) {
+		var rules []models.AutomationRule
+		ParseResponse(t, HTTPRequest(t, env, "GET", "/automations", nil), &rules)
+		if len(rules) != 1 || rules[0].ID.Hex() != ruleID {
+			t.Errorf("Expected the created rule, got %+v", rules)
+		}
+
+		if w := HTTPRequest(t, env, "GET", "/automations/"+ruleID, nil); w.Code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", w.Code)
+		}
+		if w := HTTPRequest(t, env, "GET", "/automations/000000000000000000000000", nil); w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 for an unknown rule, got %d", w.Code)
+		}
+	})
+
+	t.Run("PUT /automations/:id replaces a rule", func(t *testing.T) {
+		w := HTTPRequest(t, env, "PUT", "/automations/"+ruleID, map[string]interface{}{
+			"name":    "Action items",
+			"trigger": "tag",
+			"value":   "action-items",
+			"actions": []map[string]string{
+				{"type": "run_step", "value": "extract_entities"},
+				{"type": "add_to_collection", "value": "todo"},
+			},
+			"enabled": true,
+		})
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		w = HTTPRequest(t, env, "PUT", "/automations/000000000000000000000000", map[string]interface{}{
+			"name": "x", "trigger": "tag", "value": "x", "actions": []map[string]string{{"type": "add_to_collection", "value": "x"}},
+		})
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 for an unknown rule, got %d", w.Code)
+		}
+	})
+
+	t.Run("new notes triggering rules run their actions in the worker", func(t *testing.T) {
+		if env.QdrantURL == "" {
+			t.Skip("The worker needs Qdrant")
+		}
+
+		// A pipeline without entity extraction or summaries, so only the rules add them
+		w := HTTPRequest(t, env, "PUT", "/settings/pipelines", map[string]interface{}{"default": []string{"classify", "embed"}})
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		for _, rule := range []map[string]interface{}{
+			{"name": "Recipes", "trigger": "category", "value": "recipes", "enabled": true,
+				"actions": []map[string]string{{"type": "apply_preset", "value": "podcast"}}},
+			{"name": "Jane's reads", "trigger": "channel", "value": "Jane's Journal", "enabled": true,
+				"actions": []map[string]string{{"type": "add_to_collection", "value": "reading"}}},
+			{"name": "Disabled", "trigger": "tag", "value": "action-items", "enabled": false,
+				"actions": []map[string]string{{"type": "add_to_collection", "value": "never"}}},
+		} {
+			if w := HTTPRequest(t, env, "POST", "/automations", rule); w.Code != http.StatusCreated {
+				t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+			}
+		}
+
+		create := func(body map[string]interface{}) models.Note {
+			w := HTTPRequest(t, env, "POST", "/notes", body)
+			if w.Code != http.StatusCreated {
+				t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+			}
+			var note models.Note
+			ParseResponse(t, w, &note)
+			return note
+		}
+		waitForProcessing := func(id string) models.Note {
+			var note models.Note
+			deadline := time.Now().Add(5 * time.Second)
+			for time.Now().Before(deadline) {
+				ParseResponse(t, HTTPRequest(t, env, "GET", "/notes/"+id, nil), &note)
+				if note.ProcessingStatus == models.ProcessingIndexed || note.ProcessingStatus == models.ProcessingSkipped {
+					break
+				}
+				time.Sleep(50 * time.Millisecond)
+			}
+			return note
+		}
+
+		todo := create(map[string]interface{}{
+			"content": "Call the plumber about the leak\nPerson: Grace Hopper",
+			"tags":    []string{"Action-Items"},
+		})
+		recipe := create(map[string]interface{}{
+			"content":  "Whisk two eggs with a tablespoon of milk",
+			"category": "recipes",
+		})
+		reading := create(map[string]interface{}{
+			"content":  "Thoughts on a long novel",
+			"metadata": map[string]interface{}{"author": "Janes Journal"},
+		})
+
+		note := waitForProcessing(todo.ID.Hex())
+		if len(note.Entities) != 1 || note.Entities[0].Name != "Grace Hopper" {
+			t.Errorf("Expected the rule to extract entities, got %+v", note.Entities)
+		}
+		if !slices.Equal(note.Collections, []string{"todo"}) {
+			t.Errorf("Expected the note in the todo collection only, got %v", note.Collections)
+		}
+
+		note = waitForProcessing(recipe.ID.Hex())
+		if note.Summary == "" || note.StructuredData == nil || note.SummaryGeneration == nil {
+			t.Errorf("Expected the note summarized with the preset, got %+v", note)
+		}
+		if len(note.Entities) != 0 || len(note.Collections) != 0 {
+			t.Errorf("Expected no other rule to run, got %+v and %v", note.Entities, note.Collections)
+		}
+
+		note = waitForProcessing(reading.ID.Hex())
+		if !slices.Equal(note.Collections, []string{"reading"}) {
+			t.Errorf("Expected the channel rule to add the note to reading, got %v", note.Collections)
+		}
+
+		var listed []models.Note
+		ParseResponse(t, HTTPRequest(t, env, "GET", "/notes?collection=Todo", nil), &listed)
+		if len(listed) != 1 || listed[0].ID != todo.ID {
+			t.Errorf("Expected GET /notes?collection=Todo to list the action item, got %d notes", len(listed))
+		}
+	})
+
+	t.Run("DELETE /automations/:id removes a rule", func(t *testing.T) {
+		if w := HTTPRequest(t, env, "DELETE", "/automations/"+ruleID, nil); w.Code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", w.Code)
+		}
+		if w := HTTPRequest(t, env, "DELETE", "/automations/"+ruleID, nil); w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 once deleted, got %d", w.Code)
+		}
+	})
+}
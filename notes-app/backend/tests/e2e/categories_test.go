@@ -3,10 +3,13 @@ package e2e
 import (
 	"context"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
 	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 func TestCategoriesAPI(t *testing.T) {
@@ -177,3 +180,292 @@ func TestCategoryMigration(t *testing.T) {
 		}
 	})
 }
+
+func TestCategoriesCRUD(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+	CleanupCollections(t, env)
+
+	// Left over from an interrupted run; the category is empty once the notes are cleaned up
+	HTTPRequest(t, env, "DELETE", "/categories/gardening", nil)
+
+	var noteID string
+
+	t.Run("POST /categories adds a category notes can be created in", func(t *testing.T) {
+		w := HTTPRequest(t, env, "POST", "/categories", map[string]string{"name": " Gardening ", "description": "Plants"})
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+		}
+		var category models.Category
+		ParseResponse(t, w, &category)
+		if category.Name != "gardening" || category.Description != "Plants" {
+			t.Errorf("Expected the gardening category, got %+v", category)
+		}
+
+		w = HTTPRequest(t, env, "POST", "/notes", map[string]interface{}{"content": "Plant tomatoes after the last frost", "category": "gardening"})
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201 creating a note in the new category, got %d: %s", w.Code, w.Body.String())
+		}
+		var note models.Note
+		ParseResponse(t, w, &note)
+		noteID = note.ID.Hex()
+	})
+
+	t.Run("POST /categories rejects invalid and taken names", func(t *testing.T) {
+		for _, name := range []string{"", "Bad Name!", "double--hyphen", strings.Repeat("a", 51)} {
+			if w := HTTPRequest(t, env, "POST", "/categories", map[string]string{"name": name}); w.Code != http.StatusBadRequest {
+				t.Errorf("%q: expected status 400, got %d", name, w.Code)
+			}
+		}
+		if w := HTTPRequest(t, env, "POST", "/categories", map[string]string{"name": "recipes"}); w.Code != http.StatusConflict {
+			t.Errorf("Expected status 409 for a default category, got %d", w.Code)
+		}
+	})
+
+	t.Run("GET /categories lists stored categories with descriptions", func(t *testing.T) {
+		var categories []models.CategoryCount
+		ParseResponse(t, HTTPRequest(t, env, "GET", "/categories", nil), &categories)
+		var gardening, recipes *models.CategoryCount
+		for i := range categories {
+			switch categories[i].Name {
+			case "gardening":
+				gardening = &categories[i]
+			case "recipes":
+				recipes = &categories[i]
+			}
+		}
+		if gardening == nil || gardening.Count != 1 || gardening.Description != "Plants" {
+			t.Errorf("Expected gardening with 1 note, got %+v", gardening)
+		}
+		if recipes == nil || recipes.Count != 0 {
+			t.Errorf("Expected the seeded recipes category, got %+v", recipes)
+		}
+	})
+
+	t.Run("PUT /categories/:name updates the description", func(t *testing.T) {
+		w := HTTPRequest(t, env, "PUT", "/categories/gardening", map[string]string{"description": "Plants and vegetables"})
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var category models.Category
+		ParseResponse(t, w, &category)
+		if category.Description != "Plants and vegetables" {
+			t.Errorf("Expected the new description, got %+v", category)
+		}
+
+		if w := HTTPRequest(t, env, "PUT", "/categories/no-such-category", map[string]string{"description": "x"}); w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 for an unknown category, got %d", w.Code)
+		}
+	})
+
+	t.Run("DELETE /categories/:name only deletes empty categories", func(t *testing.T) {
+		if w := HTTPRequest(t, env, "DELETE", "/categories/gardening", nil); w.Code != http.StatusConflict {
+			t.Errorf("Expected status 409 while a note is in the category, got %d", w.Code)
+		}
+		if w := HTTPRequest(t, env, "DELETE", "/categories/other", nil); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for the fallback category, got %d", w.Code)
+		}
+
+		if w := HTTPRequest(t, env, "DELETE", "/notes/"+noteID, nil); w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200 deleting the note, got %d", w.Code)
+		}
+		if w := HTTPRequest(t, env, "DELETE", "/categories/gardening", nil); w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if w := HTTPRequest(t, env, "DELETE", "/categories/gardening", nil); w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 once deleted, got %d", w.Code)
+		}
+
+		w := HTTPRequest(t, env, "POST", "/notes", map[string]interface{}{"content": "Water the roses", "category": "gardening"})
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 creating a note in the deleted category, got %d", w.Code)
+		}
+	})
+}
+
+func TestCategoryRenameAndMerge(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+	CleanupCollections(t, env)
+
+	// Left over from an interrupted run; the categories are empty once the notes are cleaned up
+	for _, name := range []string{"hiking", "trails", "camping"} {
+		HTTPRequest(t, env, "DELETE", "/categories/"+name, nil)
+	}
+	for _, name := range []string{"hiking", "camping"} {
+		if w := HTTPRequest(t, env, "POST", "/categories", map[string]string{"name": name, "description": "Outdoors"}); w.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+		}
+	}
+	for _, note := range []models.Note{
+		{Content: "Ridge loop, 12km", Category: "hiking", Created: time.Now()},
+		{Content: "Summit at dawn", Category: "hiking", Created: time.Now()},
+		{Content: "Pitch the tent early", Category: "camping", Created: time.Now()},
+		{Content: "Lasagne", Category: "recipes", Created: time.Now()},
+	} {
+		if _, err := env.Database.Collection("notes").InsertOne(context.Background(), note); err != nil {
+			t.Fatalf("Failed to create test note: %v", err)
+		}
+	}
+	countIn := func(category string) int64 {
+		count, err := env.Database.Collection("notes").CountDocuments(context.Background(), map[string]string{"category": category})
+		if err != nil {
+			t.Fatalf("Failed to count notes: %v", err)
+		}
+		return count
+	}
+
+	// References to the categories that have to follow them
+	ctx := context.Background()
+	if _, err := env.Database.Collection("category_rules").InsertOne(ctx, models.CategoryRule{
+		Name: "Trail reports", MatchType: models.RuleMatchKeyword, Pattern: "trail", Category: "hiking", Enabled: true,
+	}); err != nil {
+		t.Fatalf("Failed to create category rule: %v", err)
+	}
+	if _, err := env.Database.Collection("automation_rules").InsertOne(ctx, models.AutomationRule{
+		Name: "Hike log", Trigger: models.AutomationTriggerCategory, Value: "hiking", Enabled: true,
+		Actions: []models.AutomationAction{{Type: models.AutomationActionAddToCollection, Value: "hikes"}},
+	}); err != nil {
+		t.Fatalf("Failed to create automation rule: %v", err)
+	}
+	if _, err := env.Database.Collection("classification_examples").InsertOne(ctx, models.ClassificationExample{
+		NoteID: primitive.NewObjectID(), Title: "Pitch the tent early", Category: "camping", CreatedAt: time.Now(),
+	}); err != nil {
+		t.Fatalf("Failed to create classification example: %v", err)
+	}
+	if w := HTTPRequest(t, env, "PUT", "/settings/pipelines", map[string]interface{}{
+		"default":    models.DefaultPipeline,
+		"categories": map[string][]string{"hiking": {models.PipelineSummarize, models.PipelineEmbed}, "camping": {models.PipelineEmbed}},
+	}); w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	categoryPipelines := func() map[string][]string {
+		var pipelines models.PipelineSettings
+		ParseResponse(t, HTTPRequest(t, env, "GET", "/settings/pipelines", nil), &pipelines)
+		return pipelines.Categories
+	}
+
+	t.Run("POST /categories/rename with dryRun only reports the notes", func(t *testing.T) {
+		w := HTTPRequest(t, env, "POST", "/categories/rename", map[string]interface{}{"from": "hiking", "to": "trails", "dryRun": true})
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var change models.CategoryChange
+		ParseResponse(t, w, &change)
+		if change.Notes != 2 || !change.DryRun {
+			t.Errorf("Expected a dry run moving 2 notes, got %+v", change)
+		}
+		if change.Rules != 1 || change.Automations != 1 || change.Pipelines != 1 || change.Examples != 0 {
+			t.Errorf("Expected the dry run to count the rule, automation and pipeline naming hiking, got %+v", change)
+		}
+		if countIn("hiking") != 2 || countIn("trails") != 0 {
+			t.Error("Expected the dry run to leave the notes alone")
+		}
+	})
+
+	t.Run("POST /categories/rename rejects invalid renames", func(t *testing.T) {
+		for name, reqBody := range map[string]map[string]interface{}{
+			"fallback":     {"from": "other", "to": "misc"},
+			"invalid name": {"from": "hiking", "to": "Bad Name!"},
+			"same name":    {"from": "hiking", "to": "Hiking"},
+		} {
+			if w := HTTPRequest(t, env, "POST", "/categories/rename", reqBody); w.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status 400, got %d", name, w.Code)
+			}
+		}
+		if w := HTTPRequest(t, env, "POST", "/categories/rename", map[string]string{"from": "no-such-category", "to": "trails"}); w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 for an unknown category, got %d", w.Code)
+		}
+		if w := HTTPRequest(t, env, "POST", "/categories/rename", map[string]string{"from": "hiking", "to": "recipes"}); w.Code != http.StatusConflict {
+			t.Errorf("Expected status 409 renaming to an existing category, got %d", w.Code)
+		}
+	})
+
+	t.Run("POST /categories/rename moves the notes to the new name", func(t *testing.T) {
+		if env.QdrantURL == "" {
+			t.Skip("Moving the search index points needs Qdrant")
+		}
+		w := HTTPRequest(t, env, "POST", "/categories/rename", map[string]string{"from": "hiking", "to": "trails"})
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var change models.CategoryChange
+		ParseResponse(t, w, &change)
+		if change.Notes != 2 || change.DryRun {
+			t.Errorf("Expected 2 notes moved, got %+v", change)
+		}
+		if countIn("hiking") != 0 || countIn("trails") != 2 {
+			t.Error("Expected the notes in the renamed category")
+		}
+
+		if change.Rules != 1 || change.Automations != 1 || change.Pipelines != 1 {
+			t.Errorf("Expected the rule, automation and pipeline moved, got %+v", change)
+		}
+		var rule models.CategoryRule
+		if err := env.Database.Collection("category_rules").FindOne(ctx, map[string]string{"name": "Trail reports"}).Decode(&rule); err != nil || rule.Category != "trails" {
+			t.Errorf("Expected the category rule to assign trails, got %q (%v)", rule.Category, err)
+		}
+		var automation models.AutomationRule
+		if err := env.Database.Collection("automation_rules").FindOne(ctx, map[string]string{"name": "Hike log"}).Decode(&automation); err != nil || automation.Value != "trails" {
+			t.Errorf("Expected the automation rule to trigger on trails, got %q (%v)", automation.Value, err)
+		}
+		if pipelines := categoryPipelines(); len(pipelines["trails"]) != 2 || pipelines["hiking"] != nil {
+			t.Errorf("Expected the hiking pipeline under trails, got %v", pipelines)
+		}
+
+		var categories []models.CategoryCount
+		ParseResponse(t, HTTPRequest(t, env, "GET", "/categories", nil), &categories)
+		for _, category := range categories {
+			if category.Name == "hiking" {
+				t.Error("Expected the old category removed")
+			}
+			if category.Name == "trails" && category.Description != "Outdoors" {
+				t.Errorf("Expected the description kept, got %q", category.Description)
+			}
+		}
+	})
+
+	t.Run("POST /categories/merge moves the notes into an existing category", func(t *testing.T) {
+		if env.QdrantURL == "" {
+			t.Skip("Moving the search index points needs Qdrant")
+		}
+		if w := HTTPRequest(t, env, "POST", "/categories/merge", map[string]interface{}{"from": []string{"trails"}, "to": "trails"}); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 merging a category into itself, got %d", w.Code)
+		}
+		if w := HTTPRequest(t, env, "POST", "/categories/merge", map[string]interface{}{"from": []string{"camping", "no-such-category"}, "to": "trails"}); w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 for an unknown category, got %d", w.Code)
+		}
+
+		var change models.CategoryChange
+		ParseResponse(t, HTTPRequest(t, env, "POST", "/categories/merge", map[string]interface{}{"from": []string{"camping"}, "to": "trails", "dryRun": true}), &change)
+		if change.Notes != 1 || countIn("camping") != 1 {
+			t.Errorf("Expected a dry run moving 1 note, got %+v", change)
+		}
+		if change.Examples != 1 || change.Pipelines != 1 {
+			t.Errorf("Expected the dry run to count the example and pipeline naming camping, got %+v", change)
+		}
+
+		w := HTTPRequest(t, env, "POST", "/categories/merge", map[string]interface{}{"from": []string{" Camping "}, "to": "trails"})
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if countIn("camping") != 0 || countIn("trails") != 3 || countIn("recipes") != 1 {
+			t.Error("Expected only the camping note moved into trails")
+		}
+		if count, _ := env.Database.Collection("classification_examples").CountDocuments(ctx, map[string]string{"category": "trails"}); count != 1 {
+			t.Errorf("Expected the camping example to confirm trails, got %d examples", count)
+		}
+		if pipelines := categoryPipelines(); len(pipelines["trails"]) != 2 || pipelines["camping"] != nil {
+			t.Errorf("Expected the trails pipeline kept and the camping one dropped, got %v", pipelines)
+		}
+		if w := HTTPRequest(t, env, "POST", "/notes", map[string]interface{}{"content": "Fire pit", "category": "camping"}); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 creating a note in the merged category, got %d", w.Code)
+		}
+	})
+
+	// Leave the categories as seeded for the other tests
+	CleanupCollections(t, env)
+	for _, name := range []string{"hiking", "trails", "camping"} {
+		HTTPRequest(t, env, "DELETE", "/categories/"+name, nil)
+	}
+}
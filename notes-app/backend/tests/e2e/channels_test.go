@@ -1,7 +1,9 @@
 package e2e
 
 import (
+	"encoding/json"
 	"net/http"
+	"net/url"
 	"testing"
 
 	"backend/internal/models"
@@ -58,6 +60,30 @@ func TestChannelsAPI(t *testing.T) {
 				}
 			}
 		})
+
+		// Test 2: Configured channels without notes are listed
+		t.Run("GET /channels includes channels with settings only", func(t *testing.T) {
+			CreateTestChannelSettings(t, env, "UpcomingChannel", "youtube")
+
+			w := HTTPRequest(t, env, "GET", "/channels", nil)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", w.Code)
+			}
+
+			var channels []map[string]interface{}
+			ParseResponse(t, w, &channels)
+
+			if len(channels) != 3 {
+				t.Errorf("Expected 3 channels, got %d", len(channels))
+			}
+
+			w = HTTPRequest(t, env, "GET", "/channels?configuredOnly=true", nil)
+			ParseResponse(t, w, &channels)
+
+			if len(channels) != 1 || channels[0]["name"] != "UpcomingChannel" || channels[0]["hasNotes"] != false {
+				t.Errorf("Expected only UpcomingChannel without notes, got %v", channels)
+			}
+		})
 	})
 
 	t.Run("Channel Settings CRUD", func(t *testing.T) {
@@ -139,7 +165,7 @@ func TestChannelsAPI(t *testing.T) {
 				t.Errorf("Expected status 200, got %d", w.Code)
 			}
 
-			var settings []models.ChannelSettings
+			var settings []models.ChannelSettingsWithStats
 			ParseResponse(t, w, &settings)
 
 			if len(settings) != 2 {
@@ -147,6 +173,38 @@ func TestChannelsAPI(t *testing.T) {
 			}
 		})
 
+		// Test: Settings include note statistics for the channel
+		t.Run("GET /channel-settings includes note counts", func(t *testing.T) {
+			CreateTestNote(t, env, "Thread one", map[string]interface{}{
+				"author":   "TwitterUser",
+				"platform": "twitter",
+			})
+			CreateTestNote(t, env, "Thread two", map[string]interface{}{
+				"author":   "TwitterUser",
+				"platform": "youtube",
+			})
+
+			w := HTTPRequest(t, env, "GET", "/channel-settings", nil)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", w.Code)
+			}
+
+			var settings []models.ChannelSettingsWithStats
+			ParseResponse(t, w, &settings)
+
+			for _, s := range settings {
+				if s.ChannelName != "TwitterUser" {
+					continue
+				}
+				if s.NoteCount != 2 || s.LastNoteAt == nil {
+					t.Errorf("Expected 2 notes with lastNoteAt for TwitterUser, got %d (%v)", s.NoteCount, s.LastNoteAt)
+				}
+				if s.PlatformConsistent {
+					t.Errorf("Expected platform mismatch to be reported for TwitterUser")
+				}
+			}
+		})
+
 		// Test 5: Update channel settings
 		t.Run("PUT /channel-settings/:channel updates existing settings", func(t *testing.T) {
 			reqBody := map[string]interface{}{
@@ -216,6 +274,118 @@ func TestChannelsAPI(t *testing.T) {
 		})
 	})
 
+	t.Run("Channel Platform Namespaces", func(t *testing.T) {
+		CleanupCollections(t, env)
+
+		HTTPRequest(t, env, "PUT", "/channel-settings/SameName", map[string]interface{}{
+			"platform":   "YouTube",
+			"promptText": "Video prompt",
+		})
+		HTTPRequest(t, env, "PUT", "/channel-settings/SameName", map[string]interface{}{
+			"platform":   "twitter",
+			"promptText": "Tweet prompt",
+		})
+
+		// Test: Same channel name on different platforms keeps separate settings
+		t.Run("GET /channel-settings/:channel?platform= selects the platform", func(t *testing.T) {
+			w := HTTPRequest(t, env, "GET", "/channel-settings/SameName?platform=twitter", nil)
+
+			var settings models.ChannelSettings
+			ParseResponse(t, w, &settings)
+
+			if settings.PromptText != "Tweet prompt" {
+				t.Errorf("Expected twitter prompt, got '%s'", settings.PromptText)
+			}
+
+			w = HTTPRequest(t, env, "GET", "/channel-settings/SameName?platform=youtube", nil)
+			ParseResponse(t, w, &settings)
+
+			if settings.PromptText != "Video prompt" || settings.Platform != "youtube" {
+				t.Errorf("Expected normalized youtube settings, got %+v", settings)
+			}
+		})
+
+		// Test: Legacy settings without a platform get one from the channel's notes
+		t.Run("POST /migrate/channel-platforms assigns platforms", func(t *testing.T) {
+			CreateTestChannelSettings(t, env, "LegacyChannel", "")
+			CreateTestNote(t, env, "Legacy video", map[string]interface{}{
+				"author":   "LegacyChannel",
+				"platform": "youtube",
+			})
+
+			w := HTTPRequest(t, env, "POST", "/migrate/channel-platforms", nil)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", w.Code)
+			}
+
+			w = HTTPRequest(t, env, "GET", "/channel-settings/LegacyChannel", nil)
+			var settings models.ChannelSettings
+			ParseResponse(t, w, &settings)
+
+			if settings.Platform != "youtube" {
+				t.Errorf("Expected migrated platform 'youtube', got '%s'", settings.Platform)
+			}
+		})
+	})
+
+	t.Run("Channel Name Matching", func(t *testing.T) {
+		CleanupCollections(t, env)
+
+		HTTPRequest(t, env, "PUT", "/channel-settings/Lex Fridman", map[string]interface{}{
+			"platform":   "youtube",
+			"promptText": "Interview prompt",
+			"aliases":    []string{"Lex Clips"},
+		})
+
+		// Test: Lookups ignore case, spacing and diacritics
+		t.Run("GET /channel-settings/:channel matches normalized names", func(t *testing.T) {
+			for _, name := range []string{"lexfridman", "LEX FRÍDMAN", "lex-clips"} {
+				w := HTTPRequest(t, env, "GET", "/channel-settings/"+url.PathEscape(name)+"?platform=youtube", nil)
+
+				var settings models.ChannelSettings
+				ParseResponse(t, w, &settings)
+
+				if settings.PromptText != "Interview prompt" {
+					t.Errorf("Expected '%s' to match Lex Fridman settings, got %+v", name, settings)
+				}
+			}
+		})
+
+		// Test: Notes under a variant name count towards the configured channel
+		t.Run("GET /channels merges variant names with settings", func(t *testing.T) {
+			CreateTestNote(t, env, "Episode", map[string]interface{}{
+				"author":   "lexfridman",
+				"platform": "youtube",
+			})
+
+			w := HTTPRequest(t, env, "GET", "/channels", nil)
+
+			var channels []map[string]interface{}
+			ParseResponse(t, w, &channels)
+
+			if len(channels) != 1 || channels[0]["hasSettings"] != true {
+				t.Errorf("Expected a single configured channel, got %v", channels)
+			}
+		})
+
+		// Test: Names without letters or digits have no lookup key, so only match exactly
+		t.Run("GET /channel-settings/:channel keeps names without a key apart", func(t *testing.T) {
+			HTTPRequest(t, env, "PUT", "/channel-settings/"+url.PathEscape("!!!"), map[string]interface{}{
+				"platform":   "youtube",
+				"promptText": "Punctuation prompt",
+			})
+
+			for name, want := range map[string]string{"!!!": "Punctuation prompt", "???": ""} {
+				var settings models.ChannelSettings
+				ParseResponse(t, HTTPRequest(t, env, "GET", "/channel-settings/"+url.PathEscape(name)+"?platform=youtube", nil), &settings)
+
+				if settings.PromptText != want || settings.ChannelKey != "" {
+					t.Errorf("Expected '%s' to have prompt %q and no key, got %+v", name, want, settings)
+				}
+			}
+		})
+	})
+
 	t.Run("Delete Channel Notes", func(t *testing.T) {
 		CleanupCollections(t, env)
 
@@ -258,4 +428,97 @@ func TestChannelsAPI(t *testing.T) {
 			}
 		})
 	})
+
+	t.Run("Channel Prompt Preview", func(t *testing.T) {
+		CleanupCollections(t, env)
+
+		for _, content := range []string{"Oldest episode notes", "Middle episode notes", "Newest episode notes"} {
+			CreateTestNote(t, env, content, map[string]interface{}{
+				"author":   "PreviewChannel",
+				"platform": "youtube",
+			})
+		}
+
+		t.Run("POST /channel-settings/:channel/preview runs the prompt on recent notes", func(t *testing.T) {
+			w := HTTPRequest(t, env, "POST", "/channel-settings/PreviewChannel/preview", map[string]interface{}{
+				"platform":     "youtube",
+				"promptText":   "List the topics",
+				"promptSchema": `{"summary": "", "topics": []}`,
+				"limit":        2,
+			})
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+
+			var preview models.ChannelPromptPreview
+			ParseResponse(t, w, &preview)
+			if len(preview.Samples) != 2 {
+				t.Fatalf("Expected 2 samples, got %d", len(preview.Samples))
+			}
+			for _, sample := range preview.Samples {
+				if sample.Summary == "" || sample.Error != "" {
+					t.Errorf("Expected a generated summary, got %+v", sample)
+				}
+				// The mock AI only returns a summary, so the schema's topics are reported missing
+				if len(sample.MissingFields) != 1 || sample.MissingFields[0] != "topics" {
+					t.Errorf("Expected topics reported missing, got %v", sample.MissingFields)
+				}
+			}
+
+			// Nothing is saved by a preview
+			w = HTTPRequest(t, env, "GET", "/channel-settings/PreviewChannel?platform=youtube", nil)
+			var settings models.ChannelSettings
+			ParseResponse(t, w, &settings)
+			if settings.PromptText != "" {
+				t.Errorf("Expected no saved prompt, got '%s'", settings.PromptText)
+			}
+		})
+
+		t.Run("POST /channel-settings/:channel/preview rejects an invalid schema or limit", func(t *testing.T) {
+			for _, reqBody := range []map[string]interface{}{
+				{"promptSchema": "this is not valid json"},
+				{"promptText": "Summarize", "limit": 100},
+			} {
+				w := HTTPRequest(t, env, "POST", "/channel-settings/PreviewChannel/preview", reqBody)
+				if w.Code != http.StatusBadRequest {
+					t.Errorf("Expected status 400 for %v, got %d", reqBody, w.Code)
+				}
+			}
+		})
+	})
+
+	t.Run("Channel Prompt Inference", func(t *testing.T) {
+		CleanupCollections(t, env)
+
+		for _, content := range []string{"Episode one with a guest on gardening", "Episode two with a guest on cooking"} {
+			CreateTestNote(t, env, content, map[string]interface{}{
+				"author":   "InferChannel",
+				"platform": "podcast",
+			})
+		}
+
+		t.Run("POST /channel-settings/:channel/infer-schema proposes a prompt and schema", func(t *testing.T) {
+			w := HTTPRequest(t, env, "POST", "/channel-settings/InferChannel/infer-schema", map[string]interface{}{"platform": "podcast"})
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+
+			var suggestion models.ChannelPromptSuggestion
+			ParseResponse(t, w, &suggestion)
+			if suggestion.PromptText == "" || len(suggestion.SampleNoteIDs) != 2 {
+				t.Errorf("Expected a prompt inferred from 2 notes, got %+v", suggestion)
+			}
+			var schema map[string]interface{}
+			if err := json.Unmarshal([]byte(suggestion.PromptSchema), &schema); err != nil || schema["summary"] == nil {
+				t.Errorf("Expected a JSON schema with a summary field, got %q", suggestion.PromptSchema)
+			}
+		})
+
+		t.Run("POST /channel-settings/:channel/infer-schema for a channel without notes returns 404", func(t *testing.T) {
+			w := HTTPRequest(t, env, "POST", "/channel-settings/EmptyChannel/infer-schema", nil)
+			if w.Code != http.StatusNotFound {
+				t.Errorf("Expected status 404, got %d", w.Code)
+			}
+		})
+	})
 }
@@ -0,0 +1,128 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"backend/internal/clipper"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/services"
+	"backend/internal/utils"
+)
+
+// newFakeSite serves an article page wrapped in navigation, sidebar and comments, plus pages
+// the clipper must reject
+func newFakeSite() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/posts/small-interfaces", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, `<!DOCTYPE html><html><head>
+<title>Why Go Interfaces Are Small | Gopher Weekly</title>
+<meta property="og:site_name" content="Gopher Weekly">
+<meta name="author" content="Ada Lovelace">
+<meta property="article:published_time" content="2024-05-02T10:00:00Z">
+<link rel="canonical" href="/posts/small-interfaces">
+</head><body>
+<header><nav><a href="/">Home</a><a href="/archive">Archive</a></nav></header>
+<aside class="sidebar"><p>Subscribe to the newsletter for a weekly digest of the best Go articles.</p></aside>
+<article>
+<h1>Why Go Interfaces Are Small</h1>
+<p>Go interfaces tend to be small, often with a single method, and that is by design. Small interfaces are easier to implement, easier to mock, and compose well.</p>
+<p>The io.Reader interface has exactly one method, yet it powers files, network connections, compressors and much more.</p>
+<div class="comments"><p>Great post, thanks a lot for writing this, I learned so much from it today!</p></div>
+</article>
+<footer><p>Copyright Gopher Weekly, all rights reserved.</p></footer>
+</body></html>`)
+	})
+	mux.HandleFunc("/data.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"not": "a page"}`)
+	})
+	mux.HandleFunc("/empty", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html><body><nav><a href="/">Home</a></nav></body></html>`)
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestClipAPI(t *testing.T) {
+	site := newFakeSite()
+	defer site.Close()
+
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	CleanupCollections(t, env)
+
+	t.Run("POST /clip saves the article text with page metadata", func(t *testing.T) {
+		w := HTTPRequest(t, env, "POST", "/clip", map[string]interface{}{
+			"url":  site.URL + "/posts/small-interfaces?utm_source=feed",
+			"tags": []string{"go"},
+		})
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var note models.Note
+		ParseResponse(t, w, &note)
+		if note.Title != "Why Go Interfaces Are Small" {
+			t.Errorf("Expected the page title without the site name, got %q", note.Title)
+		}
+		if !strings.HasPrefix(note.Content, "Go interfaces tend to be small") || !strings.Contains(note.Content, "io.Reader") {
+			t.Errorf("Expected the article text, got %q", note.Content)
+		}
+		for _, boilerplate := range []string{"Archive", "newsletter", "Great post", "Copyright"} {
+			if strings.Contains(note.Content, boilerplate) {
+				t.Errorf("Expected %q stripped from the article text, got %q", boilerplate, note.Content)
+			}
+		}
+		if note.Metadata["url"] != site.URL+"/posts/small-interfaces" || note.Metadata["author"] != "Ada Lovelace" ||
+			note.Metadata["platform"] != "web" || note.Metadata["site_name"] != "Gopher Weekly" {
+			t.Errorf("Unexpected metadata: %v", note.Metadata)
+		}
+		if note.SourcePublishedAt == nil || note.SourcePublishedAt.Format("2006-01-02") != "2024-05-02" {
+			t.Errorf("Expected the published date as source date, got %v", note.SourcePublishedAt)
+		}
+	})
+
+	t.Run("POST /clip reports pages saved under their canonical URL as duplicates", func(t *testing.T) {
+		w := HTTPRequest(t, env, "POST", "/clip", map[string]interface{}{"url": site.URL + "/posts/small-interfaces?ref=home"})
+		if w.Code != http.StatusConflict {
+			t.Errorf("Expected status 409, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("POST /clip rejects pages without article text", func(t *testing.T) {
+		for _, path := range []string{"/data.json", "/empty"} {
+			w := HTTPRequest(t, env, "POST", "/clip", map[string]interface{}{"url": site.URL + path})
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400 for %s, got %d", path, w.Code)
+			}
+		}
+		w := HTTPRequest(t, env, "POST", "/clip", map[string]interface{}{"url": "not a url"})
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for an invalid URL, got %d", w.Code)
+		}
+	})
+
+	t.Run("Clip refuses pages on non-public addresses", func(t *testing.T) {
+		clipService := services.NewClipService(repository.NewNotesRepository(env.Database), nil, clipper.NewClient(utils.PublicAddress))
+		for _, pageURL := range []string{site.URL + "/missing", "http://169.254.169.254/latest/meta-data/", "http://[::1]:6333/collections"} {
+			_, err := clipService.Clip(context.Background(), &models.ClipRequest{URL: pageURL})
+			if err == nil || !strings.HasPrefix(err.Error(), "invalid URL") {
+				t.Errorf("Expected an invalid URL error for %s, got %v", pageURL, err)
+			}
+		}
+	})
+
+	t.Run("POST /clip returns 502 when the page can't be fetched", func(t *testing.T) {
+		w := HTTPRequest(t, env, "POST", "/clip", map[string]interface{}{"url": site.URL + "/missing"})
+		if w.Code != http.StatusBadGateway {
+			t.Errorf("Expected status 502, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,104 @@
+package e2e
+
+import (
+	"net/http"
+	"os"
+	"testing"
+
+	"backend/internal/models"
+)
+
+func TestConversationsAPI(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	// Skip if AI is not configured
+	if os.Getenv("GEMINI_API_KEY") == "" {
+		t.Skip("Skipping conversation tests: GEMINI_API_KEY not set")
+	}
+
+	t.Run("Conversation Operations", func(t *testing.T) {
+		CleanupCollections(t, env)
+
+		var conversation models.Conversation
+
+		// Test 1: Create an untitled conversation
+		t.Run("POST /conversations creates a conversation", func(t *testing.T) {
+			w := HTTPRequest(t, env, "POST", "/conversations", nil)
+
+			if w.Code != http.StatusCreated {
+				t.Fatalf("Expected status 201, got %d", w.Code)
+			}
+
+			ParseResponse(t, w, &conversation)
+
+			if conversation.ID.IsZero() {
+				t.Error("Expected conversation to have an ID")
+			}
+		})
+
+		// Test 2: Questions are answered and recorded in the history
+		t.Run("POST /conversations/:id/messages records both turns", func(t *testing.T) {
+			path := "/conversations/" + conversation.ID.Hex() + "/messages"
+
+			w := HTTPRequest(t, env, "POST", path, map[string]interface{}{"question": "What books have I read?"})
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", w.Code)
+			}
+
+			w = HTTPRequest(t, env, "POST", path, map[string]interface{}{"question": "Which one was the longest?"})
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", w.Code)
+			}
+
+			w = HTTPRequest(t, env, "GET", "/conversations/"+conversation.ID.Hex(), nil)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", w.Code)
+			}
+
+			var stored models.Conversation
+			ParseResponse(t, w, &stored)
+
+			if len(stored.Messages) != 4 {
+				t.Fatalf("Expected 4 messages, got %d", len(stored.Messages))
+			}
+			if stored.Messages[0].Role != models.ConversationRoleUser || stored.Messages[1].Role != models.ConversationRoleAssistant {
+				t.Errorf("Expected user/assistant turns, got %s/%s", stored.Messages[0].Role, stored.Messages[1].Role)
+			}
+			if stored.Title != "What books have I read?" {
+				t.Errorf("Expected title from first question, got %q", stored.Title)
+			}
+		})
+
+		// Test 3: Ask validation - missing question
+		t.Run("POST /conversations/:id/messages without question returns 400", func(t *testing.T) {
+			w := HTTPRequest(t, env, "POST", "/conversations/"+conversation.ID.Hex()+"/messages", map[string]interface{}{})
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400, got %d", w.Code)
+			}
+		})
+
+		// Test 4: Delete and verify
+		t.Run("DELETE /conversations/:id removes the conversation", func(t *testing.T) {
+			w := HTTPRequest(t, env, "DELETE", "/conversations/"+conversation.ID.Hex(), nil)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", w.Code)
+			}
+
+			w = HTTPRequest(t, env, "GET", "/conversations/"+conversation.ID.Hex(), nil)
+			if w.Code != http.StatusNotFound {
+				t.Errorf("Expected status 404, got %d", w.Code)
+			}
+		})
+
+		// Test 5: Invalid ID
+		t.Run("GET /conversations/:id with invalid ID returns 404", func(t *testing.T) {
+			w := HTTPRequest(t, env, "GET", "/conversations/not-an-id", nil)
+
+			if w.Code != http.StatusNotFound {
+				t.Errorf("Expected status 404, got %d", w.Code)
+			}
+		})
+	})
+}
@@ -0,0 +1,88 @@
+package e2e
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"backend/internal/ai"
+	"backend/internal/config"
+	"backend/internal/handlers"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/services"
+	"backend/internal/vectordb"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestVectorSearchOutage(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	CleanupCollections(t, env)
+
+	// Nothing listens on port 1, so every vector search fails as unavailable
+	qdrantClient, err := vectordb.NewQdrantClient("localhost:1")
+	if err != nil {
+		t.Fatalf("Failed to create Qdrant client: %v", err)
+	}
+	defer qdrantClient.Close()
+
+	ctx := context.Background()
+	notesRepo := repository.NewNotesRepository(env.Database)
+	if err := notesRepo.EnsureTextIndex(ctx); err != nil {
+		t.Fatalf("Failed to create text index: %v", err)
+	}
+	aiClient := ai.NewMockAIClient()
+	searchService := services.NewSearchService(
+		notesRepo,
+		repository.NewChunksRepository(env.Database),
+		repository.NewChannelSettingsRepository(env.Database),
+		repository.NewSettingsRepository(env.Database),
+		aiClient,
+		qdrantClient,
+		nil,
+		config.LoadConfig(),
+	)
+	outage := &TestEnv{Router: gin.New()}
+	handlers.NewSearchHandler(
+		searchService,
+		services.NewSuggestService(notesRepo, repository.NewSearchQueriesRepository(env.Database)),
+		services.NewSpellService(notesRepo),
+		nil,
+		aiClient,
+	).RegisterRoutes(outage.Router)
+
+	noteID := CreateTestNote(t, env, "Feed the sourdough starter twice a day", nil)
+
+	t.Run("POST /search falls back to keyword search", func(t *testing.T) {
+		for _, mode := range []string{models.SearchModeSemantic, models.SearchModeHybrid} {
+			w := HTTPRequest(t, outage, "POST", "/search", map[string]interface{}{"query": "sourdough", "mode": mode})
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200 for %s, got %d: %s", mode, w.Code, w.Body.String())
+			}
+			if w.Header().Get("X-Search-Degraded") != "true" {
+				t.Errorf("Expected X-Search-Degraded header for %s", mode)
+			}
+			var results []models.SearchResult
+			ParseResponse(t, w, &results)
+			if len(results) != 1 || results[0].Note.ID != noteID {
+				t.Errorf("Expected the keyword match for %s, got %d results", mode, len(results))
+			}
+		}
+	})
+
+	t.Run("AnswerQuestion answers from keyword matches", func(t *testing.T) {
+		response, err := searchService.AnswerQuestion(ctx, "How often do I feed the sourdough starter?", nil, false, "", nil, false)
+		if err != nil {
+			t.Fatalf("AnswerQuestion failed: %v", err)
+		}
+		if !response.Degraded {
+			t.Error("Expected the response to be marked degraded")
+		}
+		if len(response.Sources) != 1 || response.Sources[0].Note.ID != noteID {
+			t.Errorf("Expected the keyword match as the source, got %d sources", len(response.Sources))
+		}
+	})
+}
@@ -0,0 +1,140 @@
+package e2e
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/vectordb"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestEmbeddingsAPI(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	noteID := primitive.NewObjectID()
+	chunkID := primitive.NewObjectID()
+	vector := make([]float32, config.EMBEDDING_DIM)
+	vector[0] = 1
+
+	importBody := func(records ...interface{}) *bytes.Buffer {
+		var body bytes.Buffer
+		for _, record := range records {
+			line, _ := json.Marshal(record)
+			body.Write(line)
+			body.WriteByte('\n')
+		}
+		return &body
+	}
+
+	t.Run("embedding routes require the admin token", func(t *testing.T) {
+		if w := adminRequest(env, "GET", "/admin/embeddings/export", ""); w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401 without a token, got %d", w.Code)
+		}
+		if w := adminRequest(env, "POST", "/admin/embeddings/import", ""); w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401 without a token, got %d", w.Code)
+		}
+	})
+
+	t.Run("POST /admin/embeddings/import upserts valid records and skips invalid ones", func(t *testing.T) {
+		body := importBody(
+			models.EmbeddingRecord{
+				ChunkID:  chunkID.Hex(),
+				NoteID:   noteID.Hex(),
+				Category: "technology",
+				Vector:   vector,
+			},
+			models.EmbeddingRecord{
+				ChunkID: primitive.NewObjectID().Hex(),
+				NoteID:  noteID.Hex(),
+				Vector:  []float32{1, 2, 3},
+			},
+		)
+
+		req, _ := http.NewRequest("POST", "/admin/embeddings/import", body)
+		req.Header.Set("Content-Type", "application/x-ndjson")
+		req.Header.Set("Authorization", "Bearer "+testAdminToken)
+		w := httptest.NewRecorder()
+		env.Router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var result models.EmbeddingImportResponse
+		ParseResponse(t, w, &result)
+
+		if result.Imported != 1 || result.Skipped != 1 {
+			t.Errorf("Expected 1 imported and 1 skipped, got %+v", result)
+		}
+		if len(result.Errors) != 1 {
+			t.Errorf("Expected 1 error describing the skipped line, got %v", result.Errors)
+		}
+	})
+
+	t.Run("GET /admin/embeddings/export streams imported records", func(t *testing.T) {
+		w := adminRequest(env, "GET", "/admin/embeddings/export", testAdminToken)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+
+		found := false
+		scanner := bufio.NewScanner(w.Body)
+		scanner.Buffer(make([]byte, 0, 64*1024), config.MAX_IMPORT_LINE_BYTES)
+		for scanner.Scan() {
+			var record models.EmbeddingRecord
+			if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+				t.Fatalf("Failed to parse export line: %v", err)
+			}
+			if record.NoteID == noteID.Hex() {
+				found = true
+				if record.Category != "technology" || len(record.Vector) != config.EMBEDDING_DIM {
+					t.Errorf("Expected exported record to round-trip, got category '%s' and %d dims", record.Category, len(record.Vector))
+				}
+				if record.ID != vectordb.PointID(chunkID) {
+					t.Errorf("Expected the point ID derived from the chunk ID, got %d", record.ID)
+				}
+			}
+		}
+
+		if !found {
+			t.Error("Expected imported embedding in export")
+		}
+	})
+
+	t.Run("POST /admin/embeddings/import of the same chunk again replaces its point", func(t *testing.T) {
+		body := importBody(models.EmbeddingRecord{ChunkID: chunkID.Hex(), NoteID: noteID.Hex(), Category: "science", Vector: vector})
+		req, _ := http.NewRequest("POST", "/admin/embeddings/import", body)
+		req.Header.Set("Content-Type", "application/x-ndjson")
+		req.Header.Set("Authorization", "Bearer "+testAdminToken)
+		w := httptest.NewRecorder()
+		env.Router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		w = adminRequest(env, "GET", "/admin/embeddings/export", testAdminToken)
+		var categories []string
+		scanner := bufio.NewScanner(w.Body)
+		scanner.Buffer(make([]byte, 0, 64*1024), config.MAX_IMPORT_LINE_BYTES)
+		for scanner.Scan() {
+			var record models.EmbeddingRecord
+			if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+				t.Fatalf("Failed to parse export line: %v", err)
+			}
+			if record.ChunkID == chunkID.Hex() {
+				categories = append(categories, record.Category)
+			}
+		}
+		if len(categories) != 1 || categories[0] != "science" {
+			t.Errorf("Expected a single replaced point for the chunk, got categories %v", categories)
+		}
+	})
+}
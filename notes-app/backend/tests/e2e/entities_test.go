@@ -0,0 +1,85 @@
+package e2e
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+)
+
+func TestEntitiesAPI(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	CleanupCollections(t, env)
+
+	ctx := context.Background()
+	now := time.Now()
+	for _, note := range []models.Note{
+		{Title: "Kickoff", Content: "Met Ada about the Postgres migration", Category: "work", Created: now.Add(-2 * time.Hour),
+			Entities: []models.NoteEntity{{Name: "Ada Lovelace", Type: models.EntityTypePerson}, {Name: "Postgres", Type: models.EntityTypeTool}}},
+		{Title: "Follow-up", Content: "Ada sent the schema", Category: "work", Created: now.Add(-time.Hour),
+			Entities: []models.NoteEntity{{Name: "ada lovelace", Type: models.EntityTypePerson}}},
+		{Title: "Old plan", Content: "Archived plan", Category: "work", Created: now, Archived: true,
+			Entities: []models.NoteEntity{{Name: "Postgres", Type: models.EntityTypeTool}}},
+	} {
+		if _, err := env.Database.Collection("notes").InsertOne(ctx, note); err != nil {
+			t.Fatalf("Failed to create test note: %v", err)
+		}
+	}
+
+	t.Run("GET /entities counts unarchived mentions, ignoring case", func(t *testing.T) {
+		w := HTTPRequest(t, env, "GET", "/entities", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var entities []models.EntityCount
+		ParseResponse(t, w, &entities)
+		if len(entities) != 2 {
+			t.Fatalf("Expected 2 entities, got %+v", entities)
+		}
+		if entities[0].Name != "ada lovelace" || entities[0].Type != models.EntityTypePerson || entities[0].Count != 2 {
+			t.Errorf("Expected Ada Lovelace mentioned twice, as last written, got %+v", entities[0])
+		}
+		if entities[1].Name != "Postgres" || entities[1].Count != 1 {
+			t.Errorf("Expected Postgres mentioned once, got %+v", entities[1])
+		}
+	})
+
+	t.Run("GET /entities filters by type", func(t *testing.T) {
+		var entities []models.EntityCount
+		ParseResponse(t, HTTPRequest(t, env, "GET", "/entities?type=Tool", nil), &entities)
+		if len(entities) != 1 || entities[0].Name != "Postgres" {
+			t.Errorf("Expected only Postgres, got %+v", entities)
+		}
+	})
+
+	t.Run("GET /entities/:name/notes lists the notes mentioning it, newest first", func(t *testing.T) {
+		w := HTTPRequest(t, env, "GET", "/entities/ADA%20LOVELACE/notes", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var notes []models.Note
+		ParseResponse(t, w, &notes)
+		if len(notes) != 2 || notes[0].Title != "Follow-up" || notes[1].Title != "Kickoff" {
+			t.Errorf("Expected both notes mentioning Ada, newest first, got %+v", notes)
+		}
+
+		ParseResponse(t, HTTPRequest(t, env, "GET", "/entities/Ada%20Lovelace/notes?type=place", nil), &notes)
+		if len(notes) != 0 {
+			t.Errorf("Expected no notes mentioning a place named Ada Lovelace, got %d", len(notes))
+		}
+	})
+
+	t.Run("invalid types and limits return 400", func(t *testing.T) {
+		for _, path := range []string{"/entities?type=animal", "/entities?limit=0", "/entities/Postgres/notes?type=animal"} {
+			if w := HTTPRequest(t, env, "GET", path, nil); w.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status 400, got %d", path, w.Code)
+			}
+		}
+	})
+}
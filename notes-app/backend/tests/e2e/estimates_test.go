@@ -0,0 +1,91 @@
+package e2e
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+)
+
+func TestEstimatesAPI(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	CleanupCollections(t, env)
+
+	// 400 and 800 characters of categorized content, and 40 uncategorized
+	CreateTestNote(t, env, strings.Repeat("a", 400), nil)
+	CreateTestNote(t, env, strings.Repeat("b", 800), nil)
+	uncategorized := models.Note{Content: strings.Repeat("c", 40), Created: time.Now()}
+	if _, err := env.Database.Collection("notes").InsertOne(context.Background(), uncategorized); err != nil {
+		t.Fatalf("Failed to insert note: %v", err)
+	}
+
+	t.Run("GET /admin/estimates without the admin token returns 401", func(t *testing.T) {
+		w := adminRequest(env, "GET", "/admin/estimates", "")
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401, got %d", w.Code)
+		}
+	})
+
+	t.Run("GET /admin/estimates estimates every bulk operation from stored note lengths", func(t *testing.T) {
+		w := adminRequest(env, "GET", "/admin/estimates", testAdminToken)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var result struct {
+			Estimates []models.AIOperationEstimate `json:"estimates"`
+		}
+		ParseResponse(t, w, &result)
+		estimates := make(map[string]models.AIOperationEstimate)
+		for _, estimate := range result.Estimates {
+			estimates[estimate.Operation] = estimate
+		}
+		if len(estimates) != 4 {
+			t.Fatalf("Expected 4 operations, got %+v", result.Estimates)
+		}
+
+		// Only the uncategorized note is classified: 40 characters and the prompt
+		if classify := estimates[models.MigrationClassify]; classify.Notes != 1 || classify.InputTokens != 10+config.CLASSIFY_PROMPT_TOKENS {
+			t.Errorf("Unexpected classify estimate %+v", classify)
+		}
+		// Titles only read the first 500 characters: 400 + 500 + 40
+		if titles := estimates[models.MigrationTitles]; titles.Notes != 3 || titles.InputTokens != 235+3*config.TITLE_PROMPT_TOKENS || titles.OutputTokens != 3*config.TITLE_OUTPUT_TOKENS {
+			t.Errorf("Unexpected titles estimate %+v", titles)
+		}
+		if reindex := estimates[models.MigrationReindex]; reindex.Notes != 3 || reindex.InputTokens != 310 || reindex.OutputTokens != 0 {
+			t.Errorf("Unexpected reindex estimate %+v", reindex)
+		}
+		for _, estimate := range estimates {
+			if estimate.EstimatedCost != nil {
+				t.Errorf("Expected no cost without token prices, got %+v", estimate)
+			}
+		}
+	})
+
+	t.Run("GET /admin/estimates?operation= estimates one operation", func(t *testing.T) {
+		w := adminRequest(env, "GET", "/admin/estimates?operation=summaries&perMinute=30", testAdminToken)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var result struct {
+			Estimates []models.AIOperationEstimate `json:"estimates"`
+		}
+		ParseResponse(t, w, &result)
+		if len(result.Estimates) != 1 || result.Estimates[0].Notes != 3 || result.Estimates[0].EstimatedMinutes != 0.1 {
+			t.Errorf("Expected 3 notes re-summarized at 30 a minute, got %+v", result.Estimates)
+		}
+	})
+
+	t.Run("GET /admin/estimates rejects an unknown operation", func(t *testing.T) {
+		w := adminRequest(env, "GET", "/admin/estimates?operation=translate", testAdminToken)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+}
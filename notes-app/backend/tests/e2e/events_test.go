@@ -0,0 +1,75 @@
+package e2e
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+)
+
+func TestEventsAPI(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	CleanupCollections(t, env)
+
+	t.Run("GET /events with unknown event type returns 400", func(t *testing.T) {
+		w := HTTPRequest(t, env, "GET", "/events?types=note.exploded", nil)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("GET /events pushes note events to connected clients", func(t *testing.T) {
+		server := httptest.NewServer(env.Router)
+		defer server.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		req, _ := http.NewRequestWithContext(ctx, "GET", server.URL+"/events?types=note.updated", nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Failed to connect to event stream: %v", err)
+		}
+		defer resp.Body.Close()
+		if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+			t.Fatalf("Expected text/event-stream, got %s", ct)
+		}
+
+		noteID := CreateTestNote(t, env, "Streamed note", nil)
+		w := HTTPRequest(t, env, "POST", "/notes/"+noteID.Hex()+"/pin", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200 pinning note, got %d", w.Code)
+		}
+
+		var eventName string
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if name, ok := strings.CutPrefix(line, "event:"); ok {
+				eventName = name
+				continue
+			}
+			data, ok := strings.CutPrefix(line, "data:")
+			if !ok {
+				continue
+			}
+
+			var event models.NoteEvent
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				t.Fatalf("Failed to parse event data %q: %v", data, err)
+			}
+			if eventName != models.NoteEventUpdated || event.NoteID != noteID || event.Delta["pinned"] != true {
+				t.Errorf("Expected note.updated pinning %s, got %s %+v", noteID.Hex(), eventName, event)
+			}
+			return
+		}
+		t.Fatalf("Stream ended before an event arrived: %v", scanner.Err())
+	})
+}
@@ -0,0 +1,189 @@
+package e2e
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestExportAPI(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	CleanupCollections(t, env)
+
+	firstID := CreateTestNote(t, env, "First note body", map[string]interface{}{"author": "Tester"})
+	secondID := CreateTestNote(t, env, "Second note body", nil)
+
+	t.Run("GET /export/markdown returns a zip with one file per note", func(t *testing.T) {
+		w := HTTPRequest(t, env, "GET", "/export/markdown", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		if contentType := w.Header().Get("Content-Type"); contentType != "application/zip" {
+			t.Errorf("Expected application/zip, got '%s'", contentType)
+		}
+
+		archive, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+		if err != nil {
+			t.Fatalf("Failed to read zip: %v", err)
+		}
+
+		files := make(map[string]string)
+		for _, file := range archive.File {
+			rc, err := file.Open()
+			if err != nil {
+				t.Fatalf("Failed to open %s: %v", file.Name, err)
+			}
+			content, _ := io.ReadAll(rc)
+			rc.Close()
+			files[file.Name] = string(content)
+		}
+
+		// Both test notes share a title, so the second is disambiguated by ID
+		first, ok := files["test-note.md"]
+		if !ok {
+			t.Fatalf("Expected test-note.md in archive, got %d files", len(files))
+		}
+		if _, ok := files["test-note-"+secondID.Hex()+".md"]; !ok {
+			t.Error("Expected second note file named with its ID")
+		}
+
+		if !strings.HasPrefix(first, "---\n") {
+			t.Error("Expected YAML front matter")
+		}
+		for _, want := range []string{`id: "` + firstID.Hex() + `"`, `title: "Test Note"`, `category: "other"`, `"author":"Tester"`, "First note body"} {
+			if !strings.Contains(first, want) {
+				t.Errorf("Expected file to contain %q, got:\n%s", want, first)
+			}
+		}
+	})
+
+	t.Run("GET /export/json and POST /import/json round-trip a backup", func(t *testing.T) {
+		CreateTestChannelSettings(t, env, "Backup Channel", "youtube")
+
+		w := HTTPRequest(t, env, "GET", "/export/json", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+
+		var backup models.Backup
+		ParseResponse(t, w, &backup)
+		if backup.Version != models.BackupVersion {
+			t.Errorf("Expected version %d, got %d", models.BackupVersion, backup.Version)
+		}
+		if len(backup.Notes) != 2 {
+			t.Fatalf("Expected 2 notes, got %d", len(backup.Notes))
+		}
+		if len(backup.ChannelSettings) != 1 {
+			t.Fatalf("Expected 1 channel settings entry, got %d", len(backup.ChannelSettings))
+		}
+		if backup.Chunks != nil {
+			t.Error("Expected no chunks without includeChunks")
+		}
+
+		// Lose one note, then restore: it comes back with its ID and the other is skipped
+		ctx := context.Background()
+		if _, err := env.Database.Collection("notes").DeleteOne(ctx, bson.M{"_id": secondID}); err != nil {
+			t.Fatalf("Failed to delete note: %v", err)
+		}
+
+		w = HTTPRequest(t, env, "POST", "/import/json", backup)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var result models.RestoreResponse
+		ParseResponse(t, w, &result)
+		if result.NotesRestored != 1 || result.NotesSkipped != 1 {
+			t.Errorf("Expected 1 restored and 1 skipped, got %+v", result)
+		}
+		if result.ChannelSettingsRestored != 1 {
+			t.Errorf("Expected 1 channel settings restored, got %d", result.ChannelSettingsRestored)
+		}
+
+		var restored models.Note
+		if err := env.Database.Collection("notes").FindOne(ctx, bson.M{"_id": secondID}).Decode(&restored); err != nil {
+			t.Fatalf("Expected note restored with its original ID: %v", err)
+		}
+		if restored.Content != "Second note body" {
+			t.Errorf("Expected restored content, got '%s'", restored.Content)
+		}
+
+		count, err := env.Database.Collection("channel_settings").CountDocuments(ctx, bson.M{})
+		if err != nil {
+			t.Fatalf("Failed to count channel settings: %v", err)
+		}
+		if count != 1 {
+			t.Errorf("Expected channel settings upserted in place, got %d documents", count)
+		}
+	})
+
+	t.Run("POST /import/json restores chunks instead of re-embedding their notes", func(t *testing.T) {
+		ctx := context.Background()
+		withChunks := models.Note{ID: primitive.NewObjectID(), Title: "Chunked", Content: "Restored with its chunks", Category: "other", Created: time.Now()}
+		withoutChunks := models.Note{ID: primitive.NewObjectID(), Title: "Unchunked", Content: "Restored without chunks", Category: "other", Created: time.Now()}
+		chunk := models.NoteChunk{ID: primitive.NewObjectID(), NoteID: withChunks.ID, Content: "Chunked\n\nRestored with its chunks", Type: models.ChunkTypeContent}
+		// Chunks of a note that isn't restored are left out
+		orphan := models.NoteChunk{ID: primitive.NewObjectID(), NoteID: firstID, Content: "First note body", Type: models.ChunkTypeContent}
+
+		w := HTTPRequest(t, env, "POST", "/import/json", models.Backup{
+			Version: models.BackupVersion,
+			Notes:   []models.Note{withChunks, withoutChunks},
+			Chunks:  []models.NoteChunk{chunk, orphan},
+		})
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var result models.RestoreResponse
+		ParseResponse(t, w, &result)
+		if result.NotesRestored != 2 || result.ChunksRestored != 1 {
+			t.Errorf("Expected 2 notes and 1 chunk restored, got %+v", result)
+		}
+		if err := env.Database.Collection("chunks").FindOne(ctx, bson.M{"_id": chunk.ID, "note_id": withChunks.ID}).Err(); err != nil {
+			t.Errorf("Expected the chunk restored: %v", err)
+		}
+		if err := env.Database.Collection("chunks").FindOne(ctx, bson.M{"_id": orphan.ID}).Err(); err != mongo.ErrNoDocuments {
+			t.Errorf("Expected the chunk of a skipped note left out, got %v", err)
+		}
+
+		// Jobs may have run already, so check nothing re-embedded the restored chunks' note
+		jobs, err := env.Database.Collection("processing_jobs").CountDocuments(ctx, bson.M{"note_id": withChunks.ID})
+		if err != nil || jobs != 0 {
+			t.Errorf("Expected no embedding job for the note with chunks, got %d (%v)", jobs, err)
+		}
+		chunks, err := env.Database.Collection("chunks").CountDocuments(ctx, bson.M{"note_id": withChunks.ID})
+		if err != nil || chunks != 1 {
+			t.Errorf("Expected only the restored chunk, got %d (%v)", chunks, err)
+		}
+	})
+
+	t.Run("POST /import/json rejects chunks without IDs", func(t *testing.T) {
+		w := HTTPRequest(t, env, "POST", "/import/json", models.Backup{
+			Version: models.BackupVersion,
+			Chunks:  []models.NoteChunk{{Content: "No IDs"}},
+		})
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("POST /import/json rejects an unsupported version", func(t *testing.T) {
+		w := HTTPRequest(t, env, "POST", "/import/json", map[string]interface{}{"version": 99})
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,129 @@
+package e2e
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+)
+
+func TestGoalsAPI(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	CleanupCollections(t, env)
+
+	// Resolutions are classified as goals notes, which set new goals
+	w := HTTPRequest(t, env, "POST", "/rules", map[string]interface{}{
+		"name":      "Resolutions are goals",
+		"matchType": "keyword",
+		"field":     "content",
+		"pattern":   "resolution",
+		"category":  "goals",
+		"enabled":   true,
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Failed to create rule: %d %s", w.Code, w.Body.String())
+	}
+
+	var goal models.Goal
+	t.Run("goals notes create goals in the background", func(t *testing.T) {
+		sourceID := CreateTestNote(t, env, "New year resolution\nGoal: Run a marathon by 2030-10-01", nil)
+
+		var result models.GoalsResponse
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			ParseResponse(t, HTTPRequest(t, env, "GET", "/goals", nil), &result)
+			if len(result.Goals) > 0 {
+				break
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+
+		if len(result.Goals) != 1 {
+			t.Fatalf("Expected 1 goal, got %d", len(result.Goals))
+		}
+		goal = result.Goals[0]
+		if goal.Title != "Run a marathon" || goal.Status != models.GoalStatusActive || goal.SourceNoteID != sourceID {
+			t.Errorf("Unexpected goal %+v", goal)
+		}
+		if goal.TargetDate == nil || goal.TargetDate.Format("2006-01-02") != "2030-10-01" {
+			t.Errorf("Expected target date 2030-10-01, got %v", goal.TargetDate)
+		}
+		if result.Counts[models.GoalStatusActive] != 1 {
+			t.Errorf("Expected 1 active goal counted, got %v", result.Counts)
+		}
+	})
+
+	t.Run("POST /goals/extract links progress from later notes", func(t *testing.T) {
+		noteID := CreateTestNote(t, env, "Training log: the plan to run a marathon is on schedule", nil)
+
+		var response struct {
+			Goals []models.Goal `json:"goals"`
+		}
+		w := HTTPRequest(t, env, "POST", "/goals/extract", map[string]string{"noteId": noteID.Hex()})
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		ParseResponse(t, w, &response)
+		if len(response.Goals) != 1 || len(response.Goals[0].Updates) != 1 || response.Goals[0].Updates[0].NoteID != noteID {
+			t.Fatalf("Expected one update linked to the note, got %+v", response.Goals)
+		}
+		if response.Goals[0].Progress == "" {
+			t.Error("Expected a progress summary")
+		}
+
+		// Extracting the same note again links nothing new
+		ParseResponse(t, HTTPRequest(t, env, "POST", "/goals/extract", map[string]string{"noteId": noteID.Hex()}), &response)
+		if len(response.Goals) != 0 {
+			t.Errorf("Expected no changes on a second extraction, got %d", len(response.Goals))
+		}
+	})
+
+	t.Run("a note reporting completion marks the goal achieved", func(t *testing.T) {
+		noteID := CreateTestNote(t, env, "Completed! I managed to run a marathon this weekend", nil)
+		HTTPRequest(t, env, "POST", "/goals/extract", map[string]string{"noteId": noteID.Hex()})
+
+		var result models.GoalsResponse
+		ParseResponse(t, HTTPRequest(t, env, "GET", "/goals?status=achieved", nil), &result)
+		if len(result.Goals) != 1 || len(result.Goals[0].Updates) != 2 {
+			t.Fatalf("Expected the achieved goal with 2 updates, got %+v", result.Goals)
+		}
+		if result.Counts[models.GoalStatusActive] != 0 || result.Counts[models.GoalStatusAchieved] != 1 {
+			t.Errorf("Unexpected counts %v", result.Counts)
+		}
+	})
+
+	t.Run("PATCH /goals/:id sets the status by hand", func(t *testing.T) {
+		w := HTTPRequest(t, env, "PATCH", "/goals/"+goal.ID.Hex(), map[string]string{"status": "paused"})
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for an unknown status, got %d", w.Code)
+		}
+
+		w = HTTPRequest(t, env, "PATCH", "/goals/"+goal.ID.Hex(), map[string]string{"status": models.GoalStatusAbandoned})
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		var updated models.Goal
+		ParseResponse(t, w, &updated)
+		if updated.Status != models.GoalStatusAbandoned {
+			t.Errorf("Expected abandoned, got %s", updated.Status)
+		}
+	})
+
+	t.Run("GET /goals rejects an unknown status", func(t *testing.T) {
+		if w := HTTPRequest(t, env, "GET", "/goals?status=paused", nil); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("DELETE /goals/:id removes the goal", func(t *testing.T) {
+		if w := HTTPRequest(t, env, "DELETE", "/goals/"+goal.ID.Hex(), nil); w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		if w := HTTPRequest(t, env, "GET", "/goals/"+goal.ID.Hex(), nil); w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+	})
+}
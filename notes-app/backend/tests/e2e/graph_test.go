@@ -0,0 +1,122 @@
+package e2e
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestGraphAPI(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	CleanupCollections(t, env)
+
+	ctx := context.Background()
+	now := time.Now()
+	var ids []primitive.ObjectID
+	for _, note := range []models.Note{
+		{Title: "Kickoff", Content: "Met Ada about the Postgres migration", Category: "work", Created: now.Add(-2 * time.Hour),
+			Entities: []models.NoteEntity{{Name: "Ada Lovelace", Type: models.EntityTypePerson}, {Name: "Postgres", Type: models.EntityTypeTool}}},
+		{Title: "Follow-up", Content: "Ada sent the schema", Category: "work", Created: now.Add(-time.Hour),
+			Entities: []models.NoteEntity{{Name: "ada lovelace", Type: models.EntityTypePerson}}},
+		{Title: "Old plan", Content: "Archived plan", Category: "work", Created: now, Archived: true,
+			Entities: []models.NoteEntity{{Name: "Postgres", Type: models.EntityTypeTool}}},
+	} {
+		result, err := env.Database.Collection("notes").InsertOne(ctx, note)
+		if err != nil {
+			t.Fatalf("Failed to create test note: %v", err)
+		}
+		ids = append(ids, result.InsertedID.(primitive.ObjectID))
+	}
+
+	// waitForGraph polls GET /graph until it has the given number of edges
+	waitForGraph := func(t *testing.T, edges int) models.Graph {
+		var graph models.Graph
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			ParseResponse(t, HTTPRequest(t, env, "GET", "/graph", nil), &graph)
+			if len(graph.Edges) == edges {
+				break
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		return graph
+	}
+
+	t.Run("POST /admin/graph/rebuild requires the admin token", func(t *testing.T) {
+		if w := adminRequest(env, "POST", "/admin/graph/rebuild", ""); w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401, got %d", w.Code)
+		}
+	})
+
+	t.Run("GET /graph links unarchived notes to the entities they mention", func(t *testing.T) {
+		w := adminRequest(env, "POST", "/admin/graph/rebuild", testAdminToken)
+		if w.Code != http.StatusAccepted {
+			t.Fatalf("Expected status 202, got %d: %s", w.Code, w.Body.String())
+		}
+		var rebuild struct {
+			Notes int `json:"notes"`
+		}
+		ParseResponse(t, w, &rebuild)
+		if rebuild.Notes != 2 {
+			t.Errorf("Expected the 2 unarchived notes rebuilt, got %d", rebuild.Notes)
+		}
+
+		graph := waitForGraph(t, 3)
+		if len(graph.Nodes) != 4 || len(graph.Edges) != 3 {
+			t.Fatalf("Expected 2 notes and 2 entities linked by 3 mentions, got %+v", graph)
+		}
+		for _, node := range graph.Nodes {
+			if node.ID == "entity:person:ada lovelace" && (node.Label != "ada lovelace" || node.Degree != 2) {
+				t.Errorf("Expected Ada Lovelace mentioned by both notes, labeled as last written, got %+v", node)
+			}
+			if node.Label == "Old plan" {
+				t.Errorf("Expected the archived note left out")
+			}
+		}
+		for _, edge := range graph.Edges {
+			if edge.Type != models.GraphEdgeMention || edge.Weight != 1 {
+				t.Errorf("Expected mention edges, got %+v", edge)
+			}
+		}
+	})
+
+	t.Run("deleting a note removes its edges", func(t *testing.T) {
+		if w := HTTPRequest(t, env, "DELETE", "/notes/"+ids[1].Hex(), nil); w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var stored int64
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			stored, _ = env.Database.Collection("graph_edges").CountDocuments(ctx, bson.M{"note_id": ids[1]})
+			if stored == 0 {
+				break
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		if stored != 0 {
+			t.Errorf("Expected the deleted note's edges removed, %d left", stored)
+		}
+
+		graph := waitForGraph(t, 2)
+		if len(graph.Nodes) != 3 || len(graph.Edges) != 2 {
+			t.Errorf("Expected the remaining note and its 2 entities, got %+v", graph)
+		}
+	})
+
+	t.Run("GET /graph rejects invalid categories and limits", func(t *testing.T) {
+		for _, path := range []string{"/graph?category=not-a-category", "/graph?limit=0", "/graph?limit=5000"} {
+			if w := HTTPRequest(t, env, "GET", path, nil); w.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status 400, got %d", path, w.Code)
+			}
+		}
+	})
+}
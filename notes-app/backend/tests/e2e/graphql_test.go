@@ -0,0 +1,164 @@
+package e2e
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestGraphQLAPI(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	CleanupCollections(t, env)
+
+	ctx := context.Background()
+	notes := env.Database.Collection("notes")
+
+	goID := CreateTestNote(t, env, "Goroutines and channels", map[string]interface{}{"author": "Go Time", "platform": "youtube"})
+	genericsID := CreateTestNote(t, env, "Generics in practice", map[string]interface{}{"author": "Go Time", "platform": "youtube"})
+	otherID := CreateTestNote(t, env, "Sourdough basics", nil)
+	for id, tags := range map[interface{}][]string{goID: {"go", "concurrency"}, genericsID: {"go"}, otherID: {"baking"}} {
+		if _, err := notes.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"tags": tags}}); err != nil {
+			t.Fatalf("Failed to tag note: %v", err)
+		}
+	}
+
+	err := repository.NewChannelSettingsRepository(env.Database).Upsert(ctx, &models.ChannelSettings{
+		ChannelName: "Go Time",
+		Platform:    "youtube",
+		PromptText:  "Summarize the episode",
+		UpdatedAt:   time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create channel settings: %v", err)
+	}
+
+	t.Run("POST /graphql resolves nested note, related notes and channel settings", func(t *testing.T) {
+		query := `query Note($id: ID!) {
+			note(id: $id) {
+				id
+				title
+				tags
+				related(limit: 3) { id channel { channelName promptText } }
+				channel { channelName platform }
+			}
+		}`
+		w := HTTPRequest(t, env, "POST", "/graphql", map[string]interface{}{
+			"query":     query,
+			"variables": map[string]interface{}{"id": goID.Hex()},
+		})
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var resp struct {
+			Data struct {
+				Note struct {
+					ID      string   `json:"id"`
+					Title   string   `json:"title"`
+					Tags    []string `json:"tags"`
+					Related []struct {
+						ID      string `json:"id"`
+						Channel *struct {
+							ChannelName string `json:"channelName"`
+							PromptText  string `json:"promptText"`
+						} `json:"channel"`
+					} `json:"related"`
+					Channel *struct {
+						ChannelName string `json:"channelName"`
+						Platform    string `json:"platform"`
+					} `json:"channel"`
+				} `json:"note"`
+			} `json:"data"`
+			Errors []map[string]interface{} `json:"errors"`
+		}
+		ParseResponse(t, w, &resp)
+
+		if len(resp.Errors) > 0 {
+			t.Fatalf("Expected no errors, got %v", resp.Errors)
+		}
+		note := resp.Data.Note
+		if note.ID != goID.Hex() || note.Title != "Test Note" || len(note.Tags) != 2 {
+			t.Errorf("Unexpected note fields: %+v", note)
+		}
+		if note.Channel == nil || note.Channel.ChannelName != "Go Time" || note.Channel.Platform != "youtube" {
+			t.Errorf("Expected Go Time channel settings, got %+v", note.Channel)
+		}
+		if len(note.Related) != 1 || note.Related[0].ID != genericsID.Hex() {
+			t.Fatalf("Expected the other Go note as related, got %+v", note.Related)
+		}
+		if note.Related[0].Channel == nil || note.Related[0].Channel.PromptText != "Summarize the episode" {
+			t.Errorf("Expected channel settings on related note, got %+v", note.Related[0].Channel)
+		}
+	})
+
+	t.Run("POST /graphql lists channels with their notes", func(t *testing.T) {
+		w := HTTPRequest(t, env, "POST", "/graphql", map[string]interface{}{
+			"query": `{ channels { channelName notes(limit: 1) { id } } }`,
+		})
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var resp struct {
+			Data struct {
+				Channels []struct {
+					ChannelName string `json:"channelName"`
+					Notes       []struct {
+						ID string `json:"id"`
+					} `json:"notes"`
+				} `json:"channels"`
+			} `json:"data"`
+		}
+		ParseResponse(t, w, &resp)
+
+		if len(resp.Data.Channels) != 1 || len(resp.Data.Channels[0].Notes) != 1 {
+			t.Fatalf("Expected one channel with one note, got %+v", resp.Data.Channels)
+		}
+		if resp.Data.Channels[0].Notes[0].ID != genericsID.Hex() {
+			t.Errorf("Expected newest channel note first, got %s", resp.Data.Channels[0].Notes[0].ID)
+		}
+	})
+
+	t.Run("POST /graphql returns null for a missing note", func(t *testing.T) {
+		w := HTTPRequest(t, env, "POST", "/graphql", map[string]interface{}{
+			"query": `{ note(id: "000000000000000000000000") { id } }`,
+		})
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		if body := w.Body.String(); body != `{"data":{"note":null}}` {
+			t.Errorf("Expected null note, got %s", body)
+		}
+	})
+
+	t.Run("POST /graphql rejects invalid queries", func(t *testing.T) {
+		for _, query := range []string{
+			`{ note(id: "x") { missingField } }`,
+			`{ notes { id `,
+			`mutation { deleteNote(id: "x") }`,
+		} {
+			w := HTTPRequest(t, env, "POST", "/graphql", map[string]interface{}{"query": query})
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400 for %q, got %d", query, w.Code)
+			}
+		}
+	})
+
+	t.Run("GET /graphql runs a query from the URL", func(t *testing.T) {
+		w := HTTPRequest(t, env, "GET", "/graphql?query=%7Bnotes(tags%3A%5B%22baking%22%5D)%7Bid%7D%7D", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if body := w.Body.String(); body != `{"data":{"notes":[{"id":"`+otherID.Hex()+`"}]}}` {
+			t.Errorf("Unexpected response: %s", body)
+		}
+	})
+}
@@ -0,0 +1,47 @@
+package e2e
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestHealthAPI(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	t.Run("GET /health reports the server is up", func(t *testing.T) {
+		w := HTTPRequest(t, env, "GET", "/health", nil)
+		if w.Code == http.StatusNotFound {
+			t.Skip("Health routes require Qdrant")
+		}
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", w.Code)
+		}
+	})
+
+	t.Run("GET /ready reports ready once vector search has warmed up", func(t *testing.T) {
+		deadline := time.Now().Add(30 * time.Second)
+		for {
+			w := HTTPRequest(t, env, "GET", "/ready", nil)
+			if w.Code == http.StatusNotFound {
+				t.Skip("Health routes require Qdrant")
+			}
+			if w.Code == http.StatusOK {
+				var result map[string]interface{}
+				ParseResponse(t, w, &result)
+				if result["status"] != "ready" {
+					t.Errorf("Expected status 'ready', got %v", result["status"])
+				}
+				return
+			}
+			if w.Code != http.StatusServiceUnavailable {
+				t.Fatalf("Expected status 200 or 503, got %d: %s", w.Code, w.Body.String())
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("Search never became ready: %s", w.Body.String())
+			}
+			time.Sleep(200 * time.Millisecond)
+		}
+	})
+}
@@ -0,0 +1,407 @@
+package e2e
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"backend/internal/ai"
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// insertDeadLetterJob stores a job that failed all its attempts and returns its ID
+func insertDeadLetterJob(t *testing.T, env *TestEnv) primitive.ObjectID {
+	job := models.DeadLetterJob{
+		ProcessingJob: models.ProcessingJob{
+			ID:          primitive.NewObjectID(),
+			NoteID:      primitive.NewObjectID(),
+			Title:       "Unembedded note",
+			Status:      models.JobRunning,
+			Attempts:    5,
+			MaxAttempts: 5,
+			LastError:   "failed to generate embedding: rate limited",
+			AvailableAt: time.Now(),
+			CreatedAt:   time.Now(),
+		},
+		FailedAt: time.Now(),
+	}
+	if _, err := env.Database.Collection("dead_letter").InsertOne(context.Background(), job); err != nil {
+		t.Fatalf("Failed to insert dead-letter job: %v", err)
+	}
+	return job.ID
+}
+
+func TestJobsAPI(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	CleanupCollections(t, env)
+
+	jobID := insertDeadLetterJob(t, env)
+
+	t.Run("GET /admin/dead-letter requires the admin token", func(t *testing.T) {
+		if w := adminRequest(env, "GET", "/admin/dead-letter", ""); w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401, got %d", w.Code)
+		}
+	})
+
+	t.Run("GET /admin/dead-letter lists jobs with their error", func(t *testing.T) {
+		w := adminRequest(env, "GET", "/admin/dead-letter", testAdminToken)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var response struct {
+			Jobs []models.DeadLetterJob `json:"jobs"`
+		}
+		ParseResponse(t, w, &response)
+		if len(response.Jobs) != 1 || response.Jobs[0].ID != jobID || response.Jobs[0].LastError == "" {
+			t.Errorf("Expected the dead-letter job with its error, got %+v", response.Jobs)
+		}
+	})
+
+	t.Run("POST /jobs/:id/retry requeues a dead-letter job with fresh attempts", func(t *testing.T) {
+		w := adminRequest(env, "POST", "/jobs/"+jobID.Hex()+"/retry", testAdminToken)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var job models.ProcessingJob
+		ParseResponse(t, w, &job)
+		if job.ID != jobID || job.Status != models.JobPending || job.Attempts != 0 {
+			t.Errorf("Expected a pending job with no attempts, got %+v", job)
+		}
+	})
+
+	t.Run("POST /admin/dead-letter/requeue without a body requeues every job", func(t *testing.T) {
+		insertDeadLetterJob(t, env)
+		insertDeadLetterJob(t, env)
+
+		w := adminRequest(env, "POST", "/admin/dead-letter/requeue", testAdminToken)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var response struct {
+			Requeued int `json:"requeued"`
+		}
+		ParseResponse(t, w, &response)
+		if response.Requeued != 2 {
+			t.Errorf("Expected 2 jobs requeued, got %d", response.Requeued)
+		}
+
+		count, err := env.Database.Collection("dead_letter").CountDocuments(context.Background(), map[string]interface{}{})
+		if err != nil || count != 0 {
+			t.Errorf("Expected an empty dead-letter queue, got %d (%v)", count, err)
+		}
+	})
+
+	t.Run("POST /admin/dead-letter/requeue with an invalid ID returns 400", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/admin/dead-letter/requeue", strings.NewReader(`{"ids": ["not-an-id"]}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+testAdminToken)
+		w := httptest.NewRecorder()
+		env.Router.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("GET /jobs with an unknown status returns 400", func(t *testing.T) {
+		if w := adminRequest(env, "GET", "/jobs?status=failed", testAdminToken); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("POST /jobs/:id/retry with unknown ID returns 404", func(t *testing.T) {
+		w := adminRequest(env, "POST", "/jobs/"+primitive.NewObjectID().Hex()+"/retry", testAdminToken)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+	})
+
+	t.Run("GET /admin/indexing reports notes that aren't fully indexed", func(t *testing.T) {
+		failedID := primitive.NewObjectID()
+		notes := []interface{}{
+			models.Note{ID: primitive.NewObjectID(), Title: "Indexed", ProcessingStatus: models.ProcessingIndexed, ChunkCount: 3, EmbeddedChunkCount: 3, Created: time.Now()},
+			models.Note{ID: failedID, Title: "Partly embedded", ProcessingStatus: models.ProcessingFailed, ChunkCount: 5, EmbeddedChunkCount: 2, Created: time.Now()},
+			models.Note{ID: primitive.NewObjectID(), Title: "From before statuses", Created: time.Now()},
+		}
+		if _, err := env.Database.Collection("notes").InsertMany(context.Background(), notes); err != nil {
+			t.Fatalf("Failed to insert notes: %v", err)
+		}
+
+		w := adminRequest(env, "GET", "/admin/indexing", testAdminToken)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var report models.IndexingReport
+		ParseResponse(t, w, &report)
+		if report.StatusCounts[models.ProcessingIndexed] != 1 || report.StatusCounts[models.ProcessingFailed] != 1 || report.StatusCounts["unknown"] != 1 {
+			t.Errorf("Unexpected status counts %v", report.StatusCounts)
+		}
+		if len(report.Incomplete) != 1 || report.Incomplete[0].ID != failedID || report.Incomplete[0].EmbeddedChunkCount != 2 {
+			t.Errorf("Expected only the partly embedded note, got %+v", report.Incomplete)
+		}
+	})
+}
+
+func TestSupersededJobs(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	CleanupCollections(t, env)
+
+	t.Run("a newer job drops the note's queued jobs", func(t *testing.T) {
+		noteID := CreateTestNote(t, env, "First draft of the packing list", nil)
+		queued := models.ProcessingJob{
+			ID:          primitive.NewObjectID(),
+			NoteID:      noteID,
+			Content:     "First draft of the packing list",
+			Status:      models.JobPending,
+			MaxAttempts: 5,
+			AvailableAt: time.Now().Add(time.Hour), // Not claimed before the edit
+			CreatedAt:   time.Now(),
+		}
+		if _, err := env.Database.Collection("processing_jobs").InsertOne(context.Background(), queued); err != nil {
+			t.Fatalf("Failed to insert job: %v", err)
+		}
+
+		w := HTTPRequest(t, env, "PUT", "/notes/"+noteID.Hex(), map[string]interface{}{"content": "Final packing list: tent, stove, headlamp"})
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		err := env.Database.Collection("processing_jobs").FindOne(context.Background(), bson.M{"_id": queued.ID}).Err()
+		if err != mongo.ErrNoDocuments {
+			t.Errorf("Expected the queued job to be dropped, got %v", err)
+		}
+	})
+
+	t.Run("workers drop jobs of an older processing generation", func(t *testing.T) {
+		if env.QdrantURL == "" {
+			t.Skip("The worker needs Qdrant")
+		}
+
+		noteID := CreateTestNote(t, env, "Itinerary for the coastal walk", nil)
+		_, err := env.Database.Collection("notes").UpdateOne(context.Background(), bson.M{"_id": noteID}, bson.M{"$set": bson.M{"processing_generation": 2}})
+		if err != nil {
+			t.Fatalf("Failed to update note: %v", err)
+		}
+		stale := models.ProcessingJob{
+			ID:          primitive.NewObjectID(),
+			NoteID:      noteID,
+			Content:     "Itinerary for the coastal walk",
+			Generation:  1,
+			Status:      models.JobPending,
+			MaxAttempts: 5,
+			AvailableAt: time.Now(),
+			CreatedAt:   time.Now(),
+		}
+		if _, err := env.Database.Collection("processing_jobs").InsertOne(context.Background(), stale); err != nil {
+			t.Fatalf("Failed to insert job: %v", err)
+		}
+		// Retrying the job wakes a worker rather than waiting for its next poll; a worker polling
+		// meanwhile may have taken the job already
+		adminRequest(env, "POST", "/jobs/"+stale.ID.Hex()+"/retry", testAdminToken)
+
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			if env.Database.Collection("processing_jobs").FindOne(context.Background(), bson.M{"_id": stale.ID}).Err() == mongo.ErrNoDocuments {
+				break
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+
+		if err := env.Database.Collection("processing_jobs").FindOne(context.Background(), bson.M{"_id": stale.ID}).Err(); err != mongo.ErrNoDocuments {
+			t.Fatalf("Expected the stale job to be dropped, got %v", err)
+		}
+		chunks, err := env.Database.Collection("chunks").CountDocuments(context.Background(), bson.M{"note_id": noteID})
+		if err != nil || chunks != 0 {
+			t.Errorf("Expected the stale job not to embed the note, got %d chunks (%v)", chunks, err)
+		}
+	})
+}
+
+func TestJobLeases(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	CleanupCollections(t, env)
+	jobsRepo := repository.NewJobsRepository(env.Database)
+	ctx := context.Background()
+
+	// Leased well into the future, so the test's own workers leave it alone
+	lease := time.Now().Add(time.Hour).Truncate(time.Millisecond)
+	job := models.ProcessingJob{
+		ID:          primitive.NewObjectID(),
+		NoteID:      primitive.NewObjectID(),
+		Status:      models.JobRunning,
+		Attempts:    1,
+		MaxAttempts: 5,
+		AvailableAt: time.Now(),
+		LeaseUntil:  lease,
+		CreatedAt:   time.Now(),
+	}
+	if _, err := env.Database.Collection("processing_jobs").InsertOne(ctx, job); err != nil {
+		t.Fatalf("Failed to insert job: %v", err)
+	}
+	stale := lease.Add(-time.Minute)
+
+	t.Run("a worker holding an older lease can't complete or retry the job", func(t *testing.T) {
+		if err := jobsRepo.Complete(ctx, job.ID, stale); err != mongo.ErrNoDocuments {
+			t.Errorf("Expected Complete to fail with the old lease, got %v", err)
+		}
+		if err := jobsRepo.Retry(ctx, job.ID, stale, "timed out", time.Now()); err != mongo.ErrNoDocuments {
+			t.Errorf("Expected Retry to fail with the old lease, got %v", err)
+		}
+		stored, err := jobsRepo.FindByID(ctx, job.ID)
+		if err != nil || stored.Status != models.JobRunning {
+			t.Errorf("Expected the job still running, got %+v (%v)", stored, err)
+		}
+	})
+
+	t.Run("an extended lease replaces the old one", func(t *testing.T) {
+		renewed := lease.Add(10 * time.Minute)
+		if err := jobsRepo.ExtendLease(ctx, job.ID, lease, renewed); err != nil {
+			t.Fatalf("Failed to extend the lease: %v", err)
+		}
+		if err := jobsRepo.ExtendLease(ctx, job.ID, lease, renewed.Add(time.Minute)); err != mongo.ErrNoDocuments {
+			t.Errorf("Expected extending the replaced lease to fail, got %v", err)
+		}
+		if err := jobsRepo.Complete(ctx, job.ID, lease); err != mongo.ErrNoDocuments {
+			t.Errorf("Expected Complete to fail with the replaced lease, got %v", err)
+		}
+		if err := jobsRepo.Complete(ctx, job.ID, renewed); err != nil {
+			t.Errorf("Expected Complete to succeed with the current lease, got %v", err)
+		}
+	})
+}
+
+func TestEmbeddingBatches(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	CleanupCollections(t, env)
+	if env.QdrantURL == "" {
+		t.Skip("The worker needs Qdrant")
+	}
+	if env.MockAI == nil {
+		t.Skip("Counting embedding batches needs the mock AI client")
+	}
+
+	// Ten-word chunks split a 1,500-word note into more than one batch
+	const channel = "Batch Test Channel"
+	settings := models.ChannelSettings{
+		ChannelName: channel,
+		Chunking:    &models.ChunkingHints{ChunkSize: 10, ChunkOverlap: 2},
+		UpdatedAt:   time.Now(),
+	}
+	if err := repository.NewChannelSettingsRepository(env.Database).Upsert(context.Background(), &settings); err != nil {
+		t.Fatalf("Failed to save channel settings: %v", err)
+	}
+	content := strings.TrimSpace(strings.Repeat("tide pools fill and empty twice a day along the rocky shore ", 150))
+	metadata := map[string]interface{}{"author": channel}
+
+	var mu sync.Mutex
+	var batches []int
+	embeddings := ai.NewMockAIClient()
+
+	// runJob queues an embedding job for a new note, wakes a worker and waits until the job is
+	// done or has failed, returning it if it is still queued
+	runJob := func(t *testing.T) (primitive.ObjectID, *models.ProcessingJob) {
+		noteID := CreateTestNote(t, env, content, metadata)
+		job := models.ProcessingJob{
+			ID:          primitive.NewObjectID(),
+			NoteID:      noteID,
+			Title:       "Test Note",
+			Content:     content,
+			Metadata:    metadata,
+			Status:      models.JobPending,
+			MaxAttempts: 5,
+			AvailableAt: time.Now(),
+			CreatedAt:   time.Now(),
+		}
+		if _, err := env.Database.Collection("processing_jobs").InsertOne(context.Background(), job); err != nil {
+			t.Fatalf("Failed to insert job: %v", err)
+		}
+		adminRequest(env, "POST", "/jobs/"+job.ID.Hex()+"/retry", testAdminToken)
+
+		jobsRepo := repository.NewJobsRepository(env.Database)
+		deadline := time.Now().Add(10 * time.Second)
+		for time.Now().Before(deadline) {
+			stored, err := jobsRepo.FindByID(context.Background(), job.ID)
+			if err == mongo.ErrNoDocuments {
+				return noteID, nil
+			}
+			if err == nil && stored.LastError != "" {
+				return noteID, stored
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		t.Fatal("Timed out waiting for the job")
+		return noteID, nil
+	}
+
+	t.Run("chunks are embedded EMBEDDING_BATCH_SIZE at a time", func(t *testing.T) {
+		env.MockAI.GenerateEmbeddingsFunc = func(texts []string) ([][]float32, error) {
+			mu.Lock()
+			batches = append(batches, len(texts))
+			mu.Unlock()
+			return embeddings.GenerateEmbeddings(texts)
+		}
+		defer func() { env.MockAI.GenerateEmbeddingsFunc = nil }()
+
+		noteID, failed := runJob(t)
+		if failed != nil {
+			t.Fatalf("Expected the job to succeed, got %s", failed.LastError)
+		}
+
+		chunks, err := env.Database.Collection("chunks").CountDocuments(context.Background(), bson.M{"note_id": noteID})
+		if err != nil {
+			t.Fatalf("Failed to count chunks: %v", err)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if chunks <= config.EMBEDDING_BATCH_SIZE {
+			t.Fatalf("Expected more than %d chunks, got %d", config.EMBEDDING_BATCH_SIZE, chunks)
+		}
+		total := 0
+		for i, size := range batches {
+			total += size
+			if size > config.EMBEDDING_BATCH_SIZE || (i < len(batches)-1 && size != config.EMBEDDING_BATCH_SIZE) {
+				t.Errorf("Expected full batches of %d before the last, got %v", config.EMBEDDING_BATCH_SIZE, batches)
+				break
+			}
+		}
+		if total != int(chunks) {
+			t.Errorf("Expected every one of the %d chunks embedded once, got batches %v", chunks, batches)
+		}
+	})
+
+	t.Run("a batch reply with the wrong number of embeddings fails the job", func(t *testing.T) {
+		env.MockAI.GenerateEmbeddingsFunc = func(texts []string) ([][]float32, error) {
+			return embeddings.GenerateEmbeddings(texts[1:])
+		}
+		defer func() { env.MockAI.GenerateEmbeddingsFunc = nil }()
+
+		noteID, failed := runJob(t)
+		if failed == nil {
+			t.Fatal("Expected the job to fail")
+		}
+		if !strings.Contains(failed.LastError, "expected 100 embeddings") {
+			t.Errorf("Expected the count mismatch as the job's error, got %q", failed.LastError)
+		}
+		embedded, err := env.Database.Collection("chunks").CountDocuments(context.Background(), bson.M{"note_id": noteID, "pending": bson.M{"$ne": true}})
+		if err != nil || embedded != 0 {
+			t.Errorf("Expected no chunk marked embedded, got %d (%v)", embedded, err)
+		}
+	})
+}
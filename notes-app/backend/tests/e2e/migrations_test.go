@@ -0,0 +1,127 @@
+package e2e
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMigrationsAPI(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	CleanupCollections(t, env)
+
+	var noteIDs []primitive.ObjectID
+	for _, content := range []string{"First note about gardening", "Second note about cooking", "Third note about travel", "Fourth note about music"} {
+		noteIDs = append(noteIDs, CreateTestNote(t, env, content, nil))
+	}
+
+	t.Run("POST /migrate/titles regenerates every title with bounded parallelism", func(t *testing.T) {
+		w := HTTPRequest(t, env, "POST", "/migrate/titles?parallelism=2", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var result struct {
+			Regenerated int                 `json:"regenerated"`
+			Total       int                 `json:"total"`
+			Run         models.MigrationRun `json:"run"`
+		}
+		ParseResponse(t, w, &result)
+		if result.Regenerated != 4 || result.Total != 4 {
+			t.Errorf("Expected 4 of 4 titles regenerated, got %d of %d", result.Regenerated, result.Total)
+		}
+		if result.Run.Status != models.MigrationCompleted || result.Run.Parallelism != 2 || result.Run.Checkpoint != noteIDs[3] {
+			t.Errorf("Unexpected run %+v", result.Run)
+		}
+	})
+
+	t.Run("POST /migrate/titles?resume=true continues an interrupted run after its checkpoint", func(t *testing.T) {
+		interrupted := models.MigrationRun{
+			Kind:       models.MigrationTitles,
+			Status:     models.MigrationInterrupted,
+			Total:      4,
+			Processed:  2,
+			Succeeded:  2,
+			Checkpoint: noteIDs[1],
+			StartedAt:  time.Now(),
+			UpdatedAt:  time.Now(),
+		}
+		if _, err := env.Database.Collection("migration_runs").InsertOne(context.Background(), interrupted); err != nil {
+			t.Fatalf("Failed to insert run: %v", err)
+		}
+
+		w := HTTPRequest(t, env, "POST", "/migrate/titles?resume=true", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var result struct {
+			Run models.MigrationRun `json:"run"`
+		}
+		ParseResponse(t, w, &result)
+		if result.Run.Processed != 4 || result.Run.Succeeded != 4 || result.Run.Resumes != 1 || result.Run.Status != models.MigrationCompleted {
+			t.Errorf("Expected the run to finish the last 2 notes, got %+v", result.Run)
+		}
+	})
+
+	t.Run("GET /migrate/runs lists runs newest first", func(t *testing.T) {
+		w := HTTPRequest(t, env, "GET", "/migrate/runs", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		var result struct {
+			Runs []models.MigrationRun `json:"runs"`
+		}
+		ParseResponse(t, w, &result)
+		if len(result.Runs) != 2 {
+			t.Fatalf("Expected 2 runs, got %d", len(result.Runs))
+		}
+		for _, run := range result.Runs {
+			if run.Status != models.MigrationCompleted {
+				t.Errorf("Expected every run completed, got %+v", run)
+			}
+		}
+	})
+
+	t.Run("POST /migrate/classify rejects an invalid parallelism", func(t *testing.T) {
+		w := HTTPRequest(t, env, "POST", "/migrate/classify?parallelism=0", nil)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("POST /migrate/titles?outdated=true only regenerates titles of an older model", func(t *testing.T) {
+		var note models.Note
+		ParseResponse(t, HTTPRequest(t, env, "GET", "/notes/"+noteIDs[0].Hex(), nil), &note)
+		if note.TitleGeneration == nil || note.TitleGeneration.Model == "" || note.TitleGeneration.Operation != models.AIOperationTitle {
+			t.Fatalf("Expected the regenerated title's provenance, got %+v", note.TitleGeneration)
+		}
+
+		_, err := env.Database.Collection("notes").UpdateOne(context.Background(), bson.M{"_id": noteIDs[2]},
+			bson.M{"$set": bson.M{"title_generation.model": "retired-model"}})
+		if err != nil {
+			t.Fatalf("Failed to age the note's title: %v", err)
+		}
+		legacyID := CreateTestNote(t, env, "Fifth note about astronomy", nil)
+
+		w := HTTPRequest(t, env, "POST", "/migrate/titles?outdated=true", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var result struct {
+			Regenerated int                 `json:"regenerated"`
+			Run         models.MigrationRun `json:"run"`
+		}
+		ParseResponse(t, w, &result)
+		if result.Regenerated != 2 || !result.Run.Outdated || result.Run.Checkpoint != legacyID {
+			t.Errorf("Expected the retired model's and the unattributed titles regenerated, got %d in %+v", result.Regenerated, result.Run)
+		}
+	})
+}
@@ -1,10 +1,17 @@
 package e2e
 
 import (
+	"context"
 	"net/http"
+	"strings"
 	"testing"
+	"time"
 
+	"backend/internal/config"
 	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 func TestNotesAPI(t *testing.T) {
@@ -80,6 +87,26 @@ func TestNotesAPI(t *testing.T) {
 			}
 		})
 
+		// Test: Fetch a single note
+		t.Run("GET /notes/:id returns the note", func(t *testing.T) {
+			if createdNoteID == "" {
+				t.Skip("No note was created")
+			}
+
+			w := HTTPRequest(t, env, "GET", "/notes/"+createdNoteID, nil)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", w.Code)
+			}
+
+			var note models.Note
+			ParseResponse(t, w, &note)
+
+			if note.ID.Hex() != createdNoteID {
+				t.Errorf("Expected note %s, got %s", createdNoteID, note.ID.Hex())
+			}
+		})
+
 		// Test 4: Update a note
 		t.Run("PUT /notes/:id updates a note", func(t *testing.T) {
 			if createdNoteID == "" {
@@ -149,6 +176,24 @@ func TestNotesAPI(t *testing.T) {
 			}
 		})
 
+		// Test: Get with invalid note ID
+		t.Run("GET /notes/:id with invalid ID returns 404", func(t *testing.T) {
+			w := HTTPRequest(t, env, "GET", "/notes/invalid-id", nil)
+
+			if w.Code != http.StatusNotFound {
+				t.Errorf("Expected status 404, got %d", w.Code)
+			}
+		})
+
+		// Test: Get a note that does not exist
+		t.Run("GET /notes/:id with unknown ID returns 404", func(t *testing.T) {
+			w := HTTPRequest(t, env, "GET", "/notes/"+primitive.NewObjectID().Hex(), nil)
+
+			if w.Code != http.StatusNotFound {
+				t.Errorf("Expected status 404, got %d", w.Code)
+			}
+		})
+
 		// Test: Update with invalid note ID
 		t.Run("PUT /notes/:id with invalid ID returns 404", func(t *testing.T) {
 			reqBody := map[string]interface{}{
@@ -265,3 +310,545 @@ func TestNotesDuplicateDetection(t *testing.T) {
 		}
 	})
 }
+
+func TestNotesInferredMetadata(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+	CleanupCollections(t, env)
+
+	CreateTestNote(t, env, "Saved from the browser", map[string]interface{}{
+		"url": "https://example.com/essays/slow-software",
+	})
+
+	t.Run("POST /notes without metadata infers where the content came from", func(t *testing.T) {
+		w := HTTPRequest(t, env, "POST", "/notes", map[string]interface{}{
+			"content": "By Jane Doe\nSource: Example Essays\nPublished 2023-05-04 at https://example.com/essays/slow-software\nSoftware keeps getting slower.",
+		})
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var note models.Note
+		ParseResponse(t, w, &note)
+		if note.Metadata["author"] != "Jane Doe" || note.Metadata["source"] != "Example Essays" {
+			t.Errorf("Expected inferred author and source, got %v", note.Metadata)
+		}
+		if note.Metadata["url"] != "https://example.com/essays/slow-software" {
+			t.Errorf("Expected the mentioned URL, got %v", note.Metadata["url"])
+		}
+		if note.SourcePublishedAt == nil || note.SourcePublishedAt.Format("2006-01-02") != "2023-05-04" {
+			t.Errorf("Expected the published date as source date, got %v", note.SourcePublishedAt)
+		}
+		inferred, _ := note.Metadata["inferred"].([]interface{})
+		if len(inferred) != 4 {
+			t.Errorf("Expected 4 fields flagged as inferred, got %v", note.Metadata["inferred"])
+		}
+	})
+
+	t.Run("POST /notes with metadata keeps it as given", func(t *testing.T) {
+		w := HTTPRequest(t, env, "POST", "/notes", map[string]interface{}{
+			"content":  "By Jane Doe\nAnother essay.",
+			"metadata": map[string]interface{}{"author": "Someone Else"},
+		})
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var note models.Note
+		ParseResponse(t, w, &note)
+		if note.Metadata["author"] != "Someone Else" || note.Metadata["inferred"] != nil {
+			t.Errorf("Expected supplied metadata only, got %v", note.Metadata)
+		}
+	})
+
+	t.Run("POST /notes rejects oversized or deeply nested structuredData", func(t *testing.T) {
+		nested := map[string]interface{}{"value": 1}
+		for i := 0; i < config.MAX_STRUCTURED_DATA_DEPTH; i++ {
+			nested = map[string]interface{}{"child": nested}
+		}
+		for name, structuredData := range map[string]map[string]interface{}{
+			"oversized": {"blob": strings.Repeat("x", config.MAX_STRUCTURED_DATA_BYTES)},
+			"nested":    nested,
+		} {
+			w := HTTPRequest(t, env, "POST", "/notes", map[string]interface{}{
+				"title":          "Imported page",
+				"content":        "A page with too many properties",
+				"category":       "other",
+				"structuredData": structuredData,
+			})
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status 400, got %d", name, w.Code)
+			}
+		}
+	})
+}
+
+func TestNotesBulkUpdate(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+	CleanupCollections(t, env)
+
+	firstID := CreateTestNote(t, env, "Misclassified recipe one", nil)
+	secondID := CreateTestNote(t, env, "Misclassified recipe two", nil)
+	untouchedID := CreateTestNote(t, env, "Unrelated note", nil)
+
+	t.Run("POST /notes/bulk-update applies changes to listed notes", func(t *testing.T) {
+		reqBody := map[string]interface{}{
+			"ids":      []string{firstID.Hex(), secondID.Hex()},
+			"category": "recipes",
+			"tags":     []string{"Cooking"},
+			"pinned":   true,
+		}
+
+		w := HTTPRequest(t, env, "POST", "/notes/bulk-update", reqBody)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var result models.BulkUpdateResponse
+		ParseResponse(t, w, &result)
+		if result.Matched != 2 || result.Modified != 2 {
+			t.Errorf("Expected 2 matched and modified, got %+v", result)
+		}
+
+		var notes []models.Note
+		ParseResponse(t, HTTPRequest(t, env, "GET", "/notes", nil), &notes)
+		for _, note := range notes {
+			updated := note.Category == "recipes" && note.Pinned && len(note.Tags) == 1 && note.Tags[0] == "cooking"
+			if note.ID == untouchedID && updated {
+				t.Errorf("Expected note %s to be untouched", note.ID.Hex())
+			}
+			if note.ID != untouchedID && !updated {
+				t.Errorf("Expected note %s to be updated, got %+v", note.ID.Hex(), note)
+			}
+		}
+
+		count, _ := env.Database.Collection("audit_log").CountDocuments(context.Background(), bson.M{"action": "bulk_update"})
+		if count != 1 {
+			t.Errorf("Expected 1 audit entry, got %d", count)
+		}
+	})
+
+	t.Run("POST /notes/bulk-update with invalid category returns 400", func(t *testing.T) {
+		reqBody := map[string]interface{}{
+			"ids":      []string{firstID.Hex()},
+			"category": "not-a-category",
+		}
+
+		w := HTTPRequest(t, env, "POST", "/notes/bulk-update", reqBody)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("POST /notes/bulk-update without changes returns 400", func(t *testing.T) {
+		reqBody := map[string]interface{}{
+			"ids": []string{firstID.Hex()},
+		}
+
+		w := HTTPRequest(t, env, "POST", "/notes/bulk-update", reqBody)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("POST /notes/bulk-delete reports the outcome per ID", func(t *testing.T) {
+		missingID := primitive.NewObjectID().Hex()
+		reqBody := map[string]interface{}{
+			"ids": []string{firstID.Hex(), missingID, "not-an-id"},
+		}
+
+		w := HTTPRequest(t, env, "POST", "/notes/bulk-delete", reqBody)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var result models.BulkDeleteResponse
+		ParseResponse(t, w, &result)
+
+		if result.Deleted != 1 {
+			t.Errorf("Expected 1 deleted note, got %d", result.Deleted)
+		}
+		expected := []string{models.BulkDeleteStatusDeleted, models.BulkDeleteStatusNotFound, models.BulkDeleteStatusInvalid}
+		if len(result.Results) != len(expected) {
+			t.Fatalf("Expected %d results, got %d", len(expected), len(result.Results))
+		}
+		for i, status := range expected {
+			if result.Results[i].Status != status {
+				t.Errorf("Expected status '%s' for %s, got '%s'", status, result.Results[i].ID, result.Results[i].Status)
+			}
+		}
+
+		w = HTTPRequest(t, env, "GET", "/notes/"+firstID.Hex(), nil)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected deleted note to return 404, got %d", w.Code)
+		}
+
+		count, _ := env.Database.Collection("chunks").CountDocuments(context.Background(), bson.M{"note_id": firstID})
+		if count != 0 {
+			t.Errorf("Expected chunks of deleted note to be removed, got %d", count)
+		}
+	})
+
+	t.Run("POST /notes/bulk-delete without IDs returns 400", func(t *testing.T) {
+		w := HTTPRequest(t, env, "POST", "/notes/bulk-delete", map[string]interface{}{"ids": []string{}})
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("PUT /notes/:id removes the previous content's chunks", func(t *testing.T) {
+		noteID := CreateTestNote(t, env, "Original content about sourdough starters", nil)
+		staleID := primitive.NewObjectID()
+		_, err := env.Database.Collection("chunks").InsertOne(context.Background(), models.NoteChunk{
+			ID:      staleID,
+			NoteID:  noteID,
+			Content: "Original content about sourdough starters",
+		})
+		if err != nil {
+			t.Fatalf("Failed to insert chunk: %v", err)
+		}
+
+		w := HTTPRequest(t, env, "PUT", "/notes/"+noteID.Hex(), map[string]interface{}{"content": "Rewritten content about rye bread"})
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		count, _ := env.Database.Collection("chunks").CountDocuments(context.Background(), bson.M{"_id": staleID})
+		if count != 0 {
+			t.Errorf("Expected the stale chunk to be removed on update")
+		}
+	})
+}
+
+func TestNotesTags(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	CleanupCollections(t, env)
+
+	for _, req := range []map[string]interface{}{
+		{"title": "Sourdough starter", "content": "Feed the starter twice a day", "tags": []string{"Baking", "sourdough"}},
+		{"title": "Rye loaf", "content": "Rye needs a longer proof", "tags": []string{"baking"}},
+		{"title": "Deploy notes", "content": "Roll back on failed health checks", "tags": []string{"ops"}},
+	} {
+		w := HTTPRequest(t, env, "POST", "/notes", req)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+		}
+	}
+
+	t.Run("GET /notes?tags= returns notes carrying all tags", func(t *testing.T) {
+		w := HTTPRequest(t, env, "GET", "/notes?tags=baking,sourdough", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+
+		var notes []models.Note
+		ParseResponse(t, w, &notes)
+
+		if len(notes) != 1 || notes[0].Title != "Sourdough starter" {
+			t.Errorf("Expected only 'Sourdough starter', got %d notes", len(notes))
+		}
+	})
+
+	t.Run("GET /tags returns tag counts", func(t *testing.T) {
+		w := HTTPRequest(t, env, "GET", "/tags", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+
+		var tags []models.TagCount
+		ParseResponse(t, w, &tags)
+
+		if len(tags) == 0 || tags[0].Name != "baking" || tags[0].Count != 2 {
+			t.Errorf("Expected 'baking' with 2 notes first, got %+v", tags)
+		}
+	})
+}
+
+func TestNotesArchive(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	CleanupCollections(t, env)
+
+	archivedID := CreateTestNote(t, env, "Old meeting notes", nil)
+	CreateTestNote(t, env, "Current meeting notes", nil)
+
+	t.Run("PATCH /notes/:id/archive archives the note", func(t *testing.T) {
+		w := HTTPRequest(t, env, "PATCH", "/notes/"+archivedID.Hex()+"/archive", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+
+		var note models.Note
+		ParseResponse(t, w, &note)
+
+		if !note.Archived {
+			t.Error("Expected note to be archived")
+		}
+	})
+
+	t.Run("GET /notes hides archived notes by default", func(t *testing.T) {
+		w := HTTPRequest(t, env, "GET", "/notes", nil)
+
+		var notes []models.Note
+		ParseResponse(t, w, &notes)
+
+		if len(notes) != 1 {
+			t.Errorf("Expected 1 unarchived note, got %d", len(notes))
+		}
+
+		w = HTTPRequest(t, env, "GET", "/notes?includeArchived=true", nil)
+		ParseResponse(t, w, &notes)
+
+		if len(notes) != 2 {
+			t.Errorf("Expected 2 notes including archived, got %d", len(notes))
+		}
+	})
+
+	t.Run("PATCH /notes/:id/unarchive restores the note", func(t *testing.T) {
+		w := HTTPRequest(t, env, "PATCH", "/notes/"+archivedID.Hex()+"/unarchive", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+
+		w = HTTPRequest(t, env, "GET", "/notes", nil)
+
+		var notes []models.Note
+		ParseResponse(t, w, &notes)
+
+		if len(notes) != 2 {
+			t.Errorf("Expected 2 notes after unarchiving, got %d", len(notes))
+		}
+	})
+
+	t.Run("PATCH /notes/:id/archive with invalid ID returns 404", func(t *testing.T) {
+		w := HTTPRequest(t, env, "PATCH", "/notes/invalid-id/archive", nil)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+	})
+}
+
+func TestNotesPin(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	CleanupCollections(t, env)
+
+	CreateTestNote(t, env, "Grocery list", nil)
+	pinnedID := CreateTestNote(t, env, "Emergency contacts", nil)
+
+	t.Run("POST /notes/:id/pin pins the note", func(t *testing.T) {
+		w := HTTPRequest(t, env, "POST", "/notes/"+pinnedID.Hex()+"/pin", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+
+		var note models.Note
+		ParseResponse(t, w, &note)
+
+		if !note.Pinned {
+			t.Error("Expected note to be pinned")
+		}
+	})
+
+	t.Run("GET /notes lists pinned notes first", func(t *testing.T) {
+		w := HTTPRequest(t, env, "GET", "/notes", nil)
+
+		var notes []models.Note
+		ParseResponse(t, w, &notes)
+
+		if len(notes) != 2 || notes[0].ID != pinnedID {
+			t.Errorf("Expected pinned note first of 2, got %d notes", len(notes))
+		}
+
+		w = HTTPRequest(t, env, "GET", "/notes?pinned=true", nil)
+		ParseResponse(t, w, &notes)
+
+		if len(notes) != 1 || notes[0].ID != pinnedID {
+			t.Errorf("Expected only the pinned note, got %d notes", len(notes))
+		}
+	})
+
+	t.Run("POST /notes/:id/unpin unpins the note", func(t *testing.T) {
+		w := HTTPRequest(t, env, "POST", "/notes/"+pinnedID.Hex()+"/unpin", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+
+		var note models.Note
+		ParseResponse(t, w, &note)
+
+		if note.Pinned {
+			t.Error("Expected note to be unpinned")
+		}
+	})
+
+	t.Run("POST /notes/:id/pin with invalid ID returns 404", func(t *testing.T) {
+		w := HTTPRequest(t, env, "POST", "/notes/invalid-id/pin", nil)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+	})
+}
+
+func TestNotesOnThisDay(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	CleanupCollections(t, env)
+
+	ctx := context.Background()
+	notes := env.Database.Collection("notes")
+
+	oneYearID := CreateTestNote(t, env, "A year before", nil)
+	threeYearsID := CreateTestNote(t, env, "Three years before", nil)
+	lastMonthID := CreateTestNote(t, env, "A month before", nil)
+	publishedID := CreateTestNote(t, env, "Saved later, published three years before", nil)
+	archivedID := CreateTestNote(t, env, "Archived", nil)
+	backdate := map[primitive.ObjectID]bson.M{
+		oneYearID:    {"created": time.Date(2023, 6, 15, 12, 0, 0, 0, time.UTC)},
+		threeYearsID: {"created": time.Date(2021, 6, 15, 8, 0, 0, 0, time.UTC)},
+		lastMonthID:  {"created": time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)},
+		publishedID:  {"created": time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC), "source_published_at": time.Date(2021, 6, 15, 20, 0, 0, 0, time.UTC)},
+		archivedID:   {"created": time.Date(2022, 6, 15, 12, 0, 0, 0, time.UTC), "archived": true},
+	}
+	for id, set := range backdate {
+		if _, err := notes.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": set}); err != nil {
+			t.Fatalf("Failed to backdate note: %v", err)
+		}
+	}
+
+	t.Run("GET /onthisday groups previous years by year", func(t *testing.T) {
+		w := HTTPRequest(t, env, "GET", "/onthisday?date=2024-06-15", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var result models.OnThisDay
+		ParseResponse(t, w, &result)
+
+		if len(result.Years) != 2 {
+			t.Fatalf("Expected 2 years, got %+v", result.Years)
+		}
+		if result.Years[0].Year != 2023 || result.Years[0].YearsAgo != 1 || len(result.Years[0].Notes) != 1 {
+			t.Errorf("Expected one note from 2023 first, got %+v", result.Years[0])
+		}
+		// The published note is grouped by its publication date, the later one first
+		older := result.Years[1]
+		if older.Year != 2021 || len(older.Notes) != 2 || older.Notes[0].ID != publishedID || older.Notes[1].ID != threeYearsID {
+			t.Errorf("Expected both 2021 notes, published one first, got %+v", older)
+		}
+	})
+
+	t.Run("GET /onthisday?scope=month includes previous months", func(t *testing.T) {
+		var result models.OnThisDay
+		ParseResponse(t, HTTPRequest(t, env, "GET", "/onthisday?date=2024-06-15&scope=month", nil), &result)
+
+		if len(result.Years) != 3 || result.Years[0].Year != 2024 || result.Years[0].Notes[0].ID != lastMonthID {
+			t.Errorf("Expected last month's note under 2024, got %+v", result.Years)
+		}
+	})
+
+	t.Run("GET /onthisday uses the timezone to decide the day", func(t *testing.T) {
+		var result models.OnThisDay
+		ParseResponse(t, HTTPRequest(t, env, "GET", "/onthisday?date=2024-06-16&tz=Asia/Tokyo", nil), &result)
+
+		// 2021-06-15 20:00 UTC is the morning of June 16 in Tokyo
+		if len(result.Years) != 1 || len(result.Years[0].Notes) != 1 || result.Years[0].Notes[0].ID != publishedID {
+			t.Errorf("Expected only the note published on June 16 Tokyo time, got %+v", result.Years)
+		}
+	})
+
+	t.Run("GET /onthisday rejects invalid parameters", func(t *testing.T) {
+		for _, query := range []string{"date=June", "scope=week", "tz=Nowhere/City"} {
+			if w := HTTPRequest(t, env, "GET", "/onthisday?"+query, nil); w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400 for %s, got %d", query, w.Code)
+			}
+		}
+	})
+}
+
+func TestNotesSummaryPatch(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	CleanupCollections(t, env)
+
+	content := "The team met on Monday to plan the release. Alice will update the docs. " +
+		"Bob owns the migration scripts. The launch is set for the first of May. " +
+		"Marketing will prepare the announcement. Support will be briefed a week ahead. " +
+		"The budget review is scheduled for Friday afternoon with finance."
+	createNote := func(generation *models.GenerationInfo) primitive.ObjectID {
+		note := models.Note{
+			Title:             "Release planning",
+			Content:           content,
+			Summary:           "Alice will update the docs. The launch is set for the first of May.",
+			Category:          "work",
+			Created:           time.Now(),
+			SummaryGeneration: generation,
+		}
+		result, err := env.Database.Collection("notes").InsertOne(context.Background(), note)
+		if err != nil {
+			t.Fatalf("Failed to create test note: %v", err)
+		}
+		return result.InsertedID.(primitive.ObjectID)
+	}
+	generated := &models.GenerationInfo{Model: "test-model", Operation: models.AIOperationSummary, PromptVersion: 1, GeneratedAt: time.Now()}
+
+	t.Run("a small correction patches the AI summary", func(t *testing.T) {
+		id := createNote(generated)
+		corrected := strings.Replace(content, "the first of May", "the third of May", 1)
+		w := HTTPRequest(t, env, "PUT", "/notes/"+id.Hex(), map[string]interface{}{"content": corrected})
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var note models.Note
+		ParseResponse(t, w, &note)
+		if note.Summary != "Alice will update the docs. The launch is set for the third of May." {
+			t.Errorf("Expected the summary patched, got %q", note.Summary)
+		}
+		if note.SummaryGeneration == nil || note.SummaryGeneration.Operation != models.AIOperationSummaryPatch {
+			t.Errorf("Expected the summary's provenance to be the patch, got %+v", note.SummaryGeneration)
+		}
+
+		var history []models.AIOperation
+		ParseResponse(t, HTTPRequest(t, env, "GET", "/notes/"+id.Hex()+"/ai-history", nil), &history)
+		patched := false
+		for _, op := range history {
+			patched = patched || op.Operation == models.AIOperationSummaryPatch
+		}
+		if !patched {
+			t.Errorf("Expected the patch in the AI history, got %+v", history)
+		}
+	})
+
+	t.Run("a rewrite leaves the summary for regeneration", func(t *testing.T) {
+		id := createNote(generated)
+		w := HTTPRequest(t, env, "PUT", "/notes/"+id.Hex(), map[string]interface{}{
+			"content": "Completely different notes about a garden. Tomatoes need staking in June.",
+		})
+		var note models.Note
+		ParseResponse(t, w, &note)
+		if note.Summary != "Alice will update the docs. The launch is set for the first of May." || note.SummaryGeneration.Operation != models.AIOperationSummary {
+			t.Errorf("Expected the summary kept, got %q (%+v)", note.Summary, note.SummaryGeneration)
+		}
+	})
+
+	t.Run("a summary not generated by the AI is never patched", func(t *testing.T) {
+		id := createNote(nil)
+		corrected := strings.Replace(content, "the first of May", "the third of May", 1)
+		w := HTTPRequest(t, env, "PUT", "/notes/"+id.Hex(), map[string]interface{}{"content": corrected})
+		var note models.Note
+		ParseResponse(t, w, &note)
+		if note.Summary != "Alice will update the docs. The launch is set for the first of May." {
+			t.Errorf("Expected the summary kept, got %q", note.Summary)
+		}
+	})
+}
@@ -0,0 +1,220 @@
+package e2e
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// notionExport builds a Notion "Markdown & CSV" export zip from file paths and contents
+func notionExport(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := archive.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to add %s: %v", name, err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatalf("Failed to write zip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// uploadNotionExport POSTs an export zip to /import/notion as a multipart upload
+func uploadNotionExport(t *testing.T, env *TestEnv, data []byte) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", "export.zip")
+	if err != nil {
+		t.Fatalf("Failed to create form file: %v", err)
+	}
+	part.Write(data)
+	form.Close()
+
+	req := httptest.NewRequest("POST", "/import/notion", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	w := httptest.NewRecorder()
+	env.Router.ServeHTTP(w, req)
+	return w
+}
+
+func TestNotionImportAPI(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	CleanupCollections(t, env)
+
+	const pageID = "0123456789abcdef0123456789abcdef"
+	const rowID = "fedcba9876543210fedcba9876543210"
+	export := notionExport(t, map[string]string{
+		"Meeting Notes " + pageID + ".md":                                     "# Meeting Notes\n\nDiscussed the roadmap.\n",
+		"Reading List 11111111111111111111111111111111.csv":                   "\ufeffName,Author,Tags,Created\nDune,Frank Herbert,\"fiction, classics\",\"March 4, 2021 9:15 AM\"\n",
+		"Reading List 11111111111111111111111111111111_all.csv":               "\ufeffName,Author,Tags,Created\nDune,Frank Herbert,\"fiction, classics\",\"March 4, 2021 9:15 AM\"\nNeuromancer,William Gibson,,\"May 1, 2022\"\n",
+		"Reading List 11111111111111111111111111111111/Dune " + rowID + ".md": "# Dune\n\nAuthor: Frank Herbert\nTags: fiction, classics\nCreated: March 4, 2021 9:15 AM\n\nA desert planet epic.\n",
+		"Meeting Notes " + pageID + "/diagram.png":                            "not markdown",
+	})
+
+	t.Run("POST /import/notion creates notes from pages and database rows", func(t *testing.T) {
+		w := uploadNotionExport(t, env, export)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var result models.NotionImportResponse
+		ParseResponse(t, w, &result)
+		if result.Imported != 3 || result.Failed != 0 {
+			t.Fatalf("Expected 3 imported and 0 failed, got %+v", result)
+		}
+
+		var page models.Note
+		err := env.Database.Collection("notes").FindOne(context.Background(), bson.M{"metadata.notion_id": pageID}).Decode(&page)
+		if err != nil {
+			t.Fatalf("Expected page note: %v", err)
+		}
+		if page.Title != "Meeting Notes" || page.Content != "Discussed the roadmap." {
+			t.Errorf("Unexpected page note: title=%q content=%q", page.Title, page.Content)
+		}
+		if page.Metadata["url"] != "https://www.notion.so/"+pageID {
+			t.Errorf("Expected Notion URL in metadata, got %v", page.Metadata["url"])
+		}
+
+		var row models.Note
+		err = env.Database.Collection("notes").FindOne(context.Background(), bson.M{"metadata.notion_id": rowID}).Decode(&row)
+		if err != nil {
+			t.Fatalf("Expected database row note: %v", err)
+		}
+		if row.Content != "A desert planet epic." {
+			t.Errorf("Expected property lines stripped from content, got %q", row.Content)
+		}
+		if row.StructuredData["Author"] != "Frank Herbert" {
+			t.Errorf("Expected properties as structured data, got %v", row.StructuredData)
+		}
+		if row.Metadata["notion_database"] != "Reading List" {
+			t.Errorf("Expected database name in metadata, got %v", row.Metadata["notion_database"])
+		}
+		want := time.Date(2021, 3, 4, 9, 15, 0, 0, time.UTC)
+		if row.SourcePublishedAt == nil || !row.SourcePublishedAt.Equal(want) {
+			t.Errorf("Expected SourcePublishedAt %v, got %v", want, row.SourcePublishedAt)
+		}
+		for _, tag := range []string{"fiction", "classics"} {
+			found := false
+			for _, got := range row.Tags {
+				found = found || got == tag
+			}
+			if !found {
+				t.Errorf("Expected tag %q, got %v", tag, row.Tags)
+			}
+		}
+
+		// Neuromancer is only in the _all CSV and has no page, so it is built from its properties
+		var rowOnly models.Note
+		err = env.Database.Collection("notes").FindOne(context.Background(), bson.M{"title": "Neuromancer"}).Decode(&rowOnly)
+		if err != nil {
+			t.Fatalf("Expected note for row without a page: %v", err)
+		}
+		if rowOnly.SourcePublishedAt == nil || rowOnly.SourcePublishedAt.Format("2006-01-02") != "2022-05-01" {
+			t.Errorf("Expected SourcePublishedAt 2022-05-01, got %v", rowOnly.SourcePublishedAt)
+		}
+	})
+
+	t.Run("POST /import/notion skips pages imported before", func(t *testing.T) {
+		w := uploadNotionExport(t, env, export)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+
+		var result models.NotionImportResponse
+		ParseResponse(t, w, &result)
+		if result.Imported != 1 || result.Duplicates != 2 {
+			t.Errorf("Expected the two linked pages as duplicates, got %+v", result)
+		}
+	})
+
+	t.Run("POST /import/notion rejects a file that is not a zip", func(t *testing.T) {
+		w := uploadNotionExport(t, env, []byte("not a zip"))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("POST /import/notion requires a file", func(t *testing.T) {
+		w := HTTPRequest(t, env, "POST", "/import/notion", nil)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+}
+
+func TestNotionImportBatchAnalysis(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	CleanupCollections(t, env)
+	if env.MockAI == nil {
+		t.Skip("Batch analysis fallback needs the mock AI client")
+	}
+
+	// The batch reply leaves out the note about tides, as a model that skips an item would
+	var batches [][]string
+	env.MockAI.AnalyzeNotesFunc = func(contents []string, examples []models.ClassificationExample) ([]*models.NoteAnalysis, error) {
+		batches = append(batches, contents)
+		analyses := make([]*models.NoteAnalysis, len(contents))
+		for i, content := range contents {
+			if !strings.Contains(content, "tides") {
+				analyses[i] = &models.NoteAnalysis{Title: "Batch title", Category: "recipes", Confidence: 0.9}
+			}
+		}
+		return analyses, nil
+	}
+	var single []string
+	env.MockAI.AnalyzeNoteFunc = func(content string, includeSummary bool, examples []models.ClassificationExample) (*models.NoteAnalysis, error) {
+		single = append(single, content)
+		return &models.NoteAnalysis{Title: "Single title", Category: "journal", Confidence: 0.9}, nil
+	}
+
+	export := notionExport(t, map[string]string{
+		"Bread 11111111111111111111111111111111.md": "# Bread\n\nKnead the dough for ten minutes.\n",
+		"Soup 22222222222222222222222222222222.md":  "# Soup\n\nSimmer the stock for an hour.\n",
+		"Beach 33333333333333333333333333333333.md": "# Beach\n\nCheck the tides before walking out.\n",
+	})
+
+	w := uploadNotionExport(t, env, export)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var result models.NotionImportResponse
+	ParseResponse(t, w, &result)
+	if result.Imported != 3 || result.Failed != 0 {
+		t.Fatalf("Expected 3 imported and 0 failed, got %+v", result)
+	}
+
+	if len(batches) != 1 || len(batches[0]) != 3 {
+		t.Fatalf("Expected the three notes analyzed in one batch, got %v", batches)
+	}
+	if len(single) != 1 || !strings.Contains(single[0], "tides") {
+		t.Errorf("Expected only the note left out of the batch analyzed on its own, got %q", single)
+	}
+
+	for title, category := range map[string]string{"Bread": "recipes", "Soup": "recipes", "Beach": "journal"} {
+		var note models.Note
+		if err := env.Database.Collection("notes").FindOne(context.Background(), bson.M{"title": title}).Decode(&note); err != nil {
+			t.Fatalf("Expected note %q: %v", title, err)
+		}
+		if note.Category != category {
+			t.Errorf("Expected %q in category %s, got %s", title, category, note.Category)
+		}
+	}
+}
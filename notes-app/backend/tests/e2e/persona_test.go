@@ -0,0 +1,75 @@
+package e2e
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"backend/internal/ai"
+	"backend/internal/models"
+)
+
+func TestAssistantPersonaAPI(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	CleanupCollections(t, env)
+
+	t.Run("GET /settings/assistant returns the default persona", func(t *testing.T) {
+		w := HTTPRequest(t, env, "GET", "/settings/assistant", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+
+		var persona models.AssistantPersona
+		ParseResponse(t, w, &persona)
+		if persona.Custom || persona.Instructions != ai.DefaultAnswerInstructions {
+			t.Errorf("Expected the default persona, got %+v", persona)
+		}
+	})
+
+	t.Run("PUT /settings/assistant saves a custom persona", func(t *testing.T) {
+		reqBody := map[string]interface{}{
+			"instructions": "  You are a terse research librarian.  ",
+			"rules":        []string{"Answer in at most three sentences", "Cite note titles in brackets"},
+		}
+		w := HTTPRequest(t, env, "PUT", "/settings/assistant", reqBody)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		w = HTTPRequest(t, env, "GET", "/settings/assistant", nil)
+		var persona models.AssistantPersona
+		ParseResponse(t, w, &persona)
+		if !persona.Custom || persona.Instructions != "You are a terse research librarian." || len(persona.Rules) != 2 {
+			t.Errorf("Expected the saved persona, got %+v", persona)
+		}
+	})
+
+	t.Run("PUT /settings/assistant rejects invalid personas", func(t *testing.T) {
+		for name, reqBody := range map[string]map[string]interface{}{
+			"missing instructions": {"instructions": " "},
+			"empty rule":           {"instructions": "Be brief", "rules": []string{""}},
+			"too long":             {"instructions": strings.Repeat("a", 4001)},
+		} {
+			w := HTTPRequest(t, env, "PUT", "/settings/assistant", reqBody)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status 400, got %d", name, w.Code)
+			}
+		}
+	})
+
+	t.Run("DELETE /settings/assistant restores the default persona", func(t *testing.T) {
+		w := HTTPRequest(t, env, "DELETE", "/settings/assistant", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+
+		w = HTTPRequest(t, env, "GET", "/settings/assistant", nil)
+		var persona models.AssistantPersona
+		ParseResponse(t, w, &persona)
+		if persona.Custom || persona.Instructions != ai.DefaultAnswerInstructions {
+			t.Errorf("Expected the default persona after reset, got %+v", persona)
+		}
+	})
+}
@@ -0,0 +1,132 @@
+package e2e
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+)
+
+func TestPipelinesAPI(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	CleanupCollections(t, env)
+
+	t.Run("GET /settings/pipelines returns the default pipeline", func(t *testing.T) {
+		w := HTTPRequest(t, env, "GET", "/settings/pipelines", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+
+		var pipelines models.PipelineSettings
+		ParseResponse(t, w, &pipelines)
+		if pipelines.Custom || !slices.Equal(pipelines.Default, models.DefaultPipeline) {
+			t.Errorf("Expected the default pipeline, got %+v", pipelines)
+		}
+	})
+
+	t.Run("PUT /settings/pipelines rejects invalid pipelines", func(t *testing.T) {
+		for name, reqBody := range map[string]map[string]interface{}{
+			"missing default":  {"categories": map[string][]string{"journal": {"embed"}}},
+			"unknown step":     {"default": []string{"classify", "translate"}},
+			"duplicate step":   {"default": []string{"embed", "embed"}},
+			"unknown category": {"default": []string{"embed"}, "categories": map[string][]string{"not-a-category": {"embed"}}},
+			"blank channel":    {"default": []string{"embed"}, "channels": map[string][]string{"  ": {"embed"}}},
+		} {
+			w := HTTPRequest(t, env, "PUT", "/settings/pipelines", reqBody)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status 400, got %d", name, w.Code)
+			}
+		}
+	})
+
+	t.Run("PUT /settings/pipelines saves pipelines by category and channel key", func(t *testing.T) {
+		w := HTTPRequest(t, env, "PUT", "/settings/pipelines", map[string]interface{}{
+			"default":    []string{"classify", "summarize", "embed"},
+			"categories": map[string][]string{"journal": {}},
+			"channels":   map[string][]string{"Jane's Journal": {"Extract_Entities"}},
+		})
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var pipelines models.PipelineSettings
+		ParseResponse(t, HTTPRequest(t, env, "GET", "/settings/pipelines", nil), &pipelines)
+		if !pipelines.Custom || !slices.Equal(pipelines.Channels["janesjournal"], []string{models.PipelineExtractEntities}) {
+			t.Errorf("Expected the saved pipelines, got %+v", pipelines)
+		}
+		if steps, ok := pipelines.Categories["journal"]; !ok || len(steps) != 0 {
+			t.Errorf("Expected an empty journal pipeline, got %+v", pipelines.Categories)
+		}
+	})
+
+	t.Run("a category pipeline without steps only stores the note", func(t *testing.T) {
+		w := HTTPRequest(t, env, "POST", "/notes", map[string]interface{}{
+			"content":  "Quiet evening, wrote a few lines before bed",
+			"category": "journal",
+		})
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var note models.Note
+		ParseResponse(t, w, &note)
+		if note.Title != "Untitled Note" || note.Category != "journal" || note.ProcessingStatus != models.ProcessingSkipped {
+			t.Errorf("Expected an unclassified, unprocessed journal note, got %+v", note)
+		}
+	})
+
+	t.Run("a channel pipeline runs its worker steps", func(t *testing.T) {
+		if env.QdrantURL == "" {
+			t.Skip("The worker needs Qdrant")
+		}
+
+		w := HTTPRequest(t, env, "POST", "/notes", map[string]interface{}{
+			"title":    "Morning pages",
+			"content":  "Coffee with an old friend.\nPerson: Ada Lovelace\nPlace: London",
+			"metadata": map[string]interface{}{"author": "Jane's Journal"},
+		})
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+		}
+		var created models.Note
+		ParseResponse(t, w, &created)
+		if created.Category != "other" || len(created.Tags) != 0 {
+			t.Errorf("Expected the note to skip classification, got %+v", created)
+		}
+
+		var note models.Note
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			ParseResponse(t, HTTPRequest(t, env, "GET", "/notes/"+created.ID.Hex(), nil), &note)
+			if note.ProcessingStatus == models.ProcessingSkipped {
+				break
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+
+		want := []models.NoteEntity{{Name: "Ada Lovelace", Type: models.EntityTypePerson}, {Name: "London", Type: models.EntityTypePlace}}
+		if !slices.Equal(note.Entities, want) {
+			t.Errorf("Expected entities %v, got %v", want, note.Entities)
+		}
+		if note.ProcessingStatus != models.ProcessingSkipped || note.EmbeddedChunkCount != 0 {
+			t.Errorf("Expected the note not to be embedded, got status %q with %d embedded chunks", note.ProcessingStatus, note.EmbeddedChunkCount)
+		}
+	})
+
+	t.Run("DELETE /settings/pipelines restores the default pipeline", func(t *testing.T) {
+		w := HTTPRequest(t, env, "DELETE", "/settings/pipelines", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+
+		var pipelines models.PipelineSettings
+		ParseResponse(t, HTTPRequest(t, env, "GET", "/settings/pipelines", nil), &pipelines)
+		if pipelines.Custom || len(pipelines.Channels) != 0 {
+			t.Errorf("Expected the default pipeline, got %+v", pipelines)
+		}
+	})
+}
@@ -0,0 +1,216 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/podcast"
+	"backend/internal/repository"
+	"backend/internal/services"
+	"backend/internal/utils"
+)
+
+// newFakePodcast serves a feed with an episode that publishes a transcript, an audio-only
+// episode, and a transcription API for the audio-only one
+func newFakePodcast(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	var server *httptest.Server
+
+	mux.HandleFunc("/feed.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd" xmlns:podcast="https://podcastindex.org/namespace/1.0">
+<channel>
+	<title>Fake Cast</title>
+	<item>
+		<title>Episode 2: Audio only</title>
+		<guid>fake-cast-2</guid>
+		<pubDate>Tue, 05 Mar 2024 08:00:00 +0000</pubDate>
+		<enclosure url="%[1]s/audio/episode2.mp3" type="audio/mpeg" length="10"/>
+	</item>
+	<item>
+		<title>Episode 1: Transcribed</title>
+		<guid>fake-cast-1</guid>
+		<pubDate>Fri, 01 Mar 2024 08:00:00 +0000</pubDate>
+		<itunes:duration>00:42:00</itunes:duration>
+		<enclosure url="%[1]s/audio/episode1.mp3" type="audio/mpeg" length="10"/>
+		<podcast:transcript url="%[1]s/transcripts/episode1.vtt" type="text/vtt"/>
+	</item>
+</channel>
+</rss>`, server.URL)
+	})
+	mux.HandleFunc("/transcripts/episode1.vtt", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "WEBVTT\n\n1\n00:00:00.000 --> 00:00:02.000\n<v Host>Welcome to Fake Cast\n\n2\n00:00:02.000 --> 00:00:04.000\ntoday we talk about feeds\n")
+	})
+	mux.HandleFunc("/audio/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/audio/episode1.mp3" {
+			t.Errorf("Audio downloaded for an episode with a published transcript")
+		}
+		w.Write([]byte("fake mp3"))
+	})
+	mux.HandleFunc("/v1/audio/transcriptions", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-key" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		audio, _ := io.ReadAll(file)
+		if header.Filename != "episode2.mp3" || string(audio) != "fake mp3" {
+			t.Errorf("Unexpected upload %q with %q", header.Filename, audio)
+		}
+		fmt.Fprint(w, "Audio only episode transcribed by the fake API\n")
+	})
+	mux.HandleFunc("/not-a-feed", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "<html><body>Not a feed</body></html>")
+	})
+
+	server = httptest.NewServer(mux)
+	return server
+}
+
+func TestPodcastsAPI(t *testing.T) {
+	fake := newFakePodcast(t)
+	defer fake.Close()
+
+	t.Setenv("TRANSCRIPTION_URL", fake.URL+"/v1")
+	t.Setenv("TRANSCRIPTION_API_KEY", "test-key")
+
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	CleanupCollections(t, env)
+
+	var feed models.PodcastFeed
+
+	t.Run("POST /podcasts follows a feed under its own title", func(t *testing.T) {
+		w := HTTPRequest(t, env, "POST", "/podcasts", map[string]interface{}{"url": fake.URL + "/feed.xml"})
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+		}
+		ParseResponse(t, w, &feed)
+		if feed.Title != "Fake Cast" {
+			t.Errorf("Expected the feed title, got %q", feed.Title)
+		}
+
+		w = HTTPRequest(t, env, "POST", "/podcasts", map[string]interface{}{"url": fake.URL + "/feed.xml"})
+		if w.Code != http.StatusConflict {
+			t.Errorf("Expected status 409 for a followed feed, got %d", w.Code)
+		}
+	})
+
+	t.Run("POST /podcasts rejects URLs that aren't feeds", func(t *testing.T) {
+		w := HTTPRequest(t, env, "POST", "/podcasts", map[string]interface{}{"url": fake.URL + "/not-a-feed"})
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+		w = HTTPRequest(t, env, "POST", "/podcasts", map[string]interface{}{"url": "ftp://example.com/feed"})
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("AddFeed refuses feeds on non-public addresses", func(t *testing.T) {
+		podcastService := services.NewPodcastService(repository.NewPodcastFeedsRepository(env.Database), repository.NewNotesRepository(env.Database), nil, podcast.NewClient(utils.PublicAddress), nil, config.LoadConfig())
+		for _, feedURL := range []string{fake.URL + "/feed.xml", "http://10.0.0.1/feed.xml"} {
+			_, err := podcastService.AddFeed(context.Background(), &models.AddPodcastRequest{URL: feedURL, Title: "Internal"})
+			if err == nil || !strings.Contains(err.Error(), "non-public address") {
+				t.Errorf("Expected an error naming the non-public address for %s, got %v", feedURL, err)
+			}
+		}
+	})
+
+	t.Run("POST /podcasts/sync saves new episodes as transcript notes", func(t *testing.T) {
+		w := HTTPRequest(t, env, "POST", "/podcasts/sync", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var report models.PodcastSyncReport
+		ParseResponse(t, w, &report)
+		if len(report.Feeds) != 1 {
+			t.Fatalf("Expected 1 synced feed, got %d", len(report.Feeds))
+		}
+		if result := report.Feeds[0]; result.Error != "" || result.Checked != 2 || result.Created != 2 {
+			t.Fatalf("Unexpected sync result: %+v", result)
+		}
+
+		w = HTTPRequest(t, env, "GET", "/notes?channel=Fake%20Cast", nil)
+		var notes []models.Note
+		ParseResponse(t, w, &notes)
+		byGUID := map[string]models.Note{}
+		for _, note := range notes {
+			if guid, ok := note.Metadata["episode_guid"].(string); ok {
+				byGUID[guid] = note
+			}
+		}
+
+		published, ok := byGUID["fake-cast-1"]
+		if !ok {
+			t.Fatalf("Expected a note for the transcribed episode among %d notes", len(notes))
+		}
+		if published.Content != "Welcome to Fake Cast today we talk about feeds" {
+			t.Errorf("Unexpected transcript: %q", published.Content)
+		}
+		if published.Category != "podcast-transcripts" || published.Title != "Episode 1: Transcribed" {
+			t.Errorf("Expected a podcast-transcripts note titled after the episode, got %q %q", published.Category, published.Title)
+		}
+		if published.Metadata["platform"] != "podcast" || published.Metadata["duration"] != "00:42:00" ||
+			published.Metadata["url"] != fake.URL+"/audio/episode1.mp3" {
+			t.Errorf("Unexpected episode metadata: %v", published.Metadata)
+		}
+
+		transcribed, ok := byGUID["fake-cast-2"]
+		if !ok {
+			t.Fatal("Expected a note for the audio-only episode")
+		}
+		if transcribed.Content != "Audio only episode transcribed by the fake API" {
+			t.Errorf("Unexpected transcription: %q", transcribed.Content)
+		}
+	})
+
+	t.Run("POST /podcasts/sync skips episodes saved by an earlier sync", func(t *testing.T) {
+		w := HTTPRequest(t, env, "POST", "/podcasts/sync?feed="+feed.ID.Hex(), nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var report models.PodcastSyncReport
+		ParseResponse(t, w, &report)
+		if len(report.Feeds) != 1 || report.Feeds[0].Created != 0 || report.Feeds[0].Existing != 2 {
+			t.Errorf("Expected nothing new, got %+v", report.Feeds)
+		}
+
+		w = HTTPRequest(t, env, "GET", "/podcasts", nil)
+		var feeds []models.PodcastFeed
+		ParseResponse(t, w, &feeds)
+		if len(feeds) != 1 || feeds[0].LastCheckedAt == nil || feeds[0].LastError != "" {
+			t.Errorf("Expected a successful sync recorded, got %+v", feeds)
+		}
+	})
+
+	t.Run("DELETE /podcasts/:id stops following a feed", func(t *testing.T) {
+		w := HTTPRequest(t, env, "DELETE", "/podcasts/"+feed.ID.Hex(), nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+
+		w = HTTPRequest(t, env, "POST", "/podcasts/sync?feed="+feed.ID.Hex(), nil)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 for a deleted feed, got %d", w.Code)
+		}
+		w = HTTPRequest(t, env, "DELETE", "/podcasts/not-an-id", nil)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+	})
+}
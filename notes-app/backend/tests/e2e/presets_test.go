@@ -0,0 +1,104 @@
+package e2e
+
+import (
+	"net/http"
+	"testing"
+
+	"backend/internal/models"
+)
+
+func TestChannelPresetsAPI(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	CleanupCollections(t, env)
+
+	t.Run("GET /channel-presets lists the built-in presets", func(t *testing.T) {
+		w := HTTPRequest(t, env, "GET", "/channel-presets", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+
+		var presets []models.ChannelPreset
+		ParseResponse(t, w, &presets)
+		names := []string{}
+		for _, preset := range presets {
+			names = append(names, preset.Name)
+			if !preset.BuiltIn {
+				t.Errorf("Expected only built-in presets, got %+v", preset)
+			}
+		}
+		if len(names) != 3 || names[0] != "finance-news" || names[1] != "podcast" || names[2] != "tutorial-video" {
+			t.Errorf("Expected the built-in presets by name, got %v", names)
+		}
+	})
+
+	t.Run("POST /channel-presets/import rejects invalid libraries", func(t *testing.T) {
+		for name, library := range map[string]map[string]interface{}{
+			"wrong format":   {"format": "other", "version": 1, "presets": []map[string]interface{}{{"name": "a", "promptText": "x"}}},
+			"newer version":  {"format": models.ChannelPresetFormat, "version": 2, "presets": []map[string]interface{}{{"name": "a", "promptText": "x"}}},
+			"no prompt":      {"format": models.ChannelPresetFormat, "version": 1, "presets": []map[string]interface{}{{"name": "a"}}},
+			"bad schema":     {"format": models.ChannelPresetFormat, "version": 1, "presets": []map[string]interface{}{{"name": "a", "promptSchema": "{not json"}}},
+			"bad chunking":   {"format": models.ChannelPresetFormat, "version": 1, "presets": []map[string]interface{}{{"name": "a", "promptText": "x", "chunking": map[string]int{"chunkSize": 100, "chunkOverlap": 100}}}},
+			"unknown source": {"format": models.ChannelPresetFormat, "version": 1, "presets": []map[string]interface{}{{"name": "a", "promptText": "x", "platform": "myspace"}}},
+		} {
+			w := HTTPRequest(t, env, "POST", "/channel-presets/import", library)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status 400, got %d", name, w.Code)
+			}
+		}
+	})
+
+	t.Run("an exported library imports back", func(t *testing.T) {
+		w := HTTPRequest(t, env, "GET", "/channel-presets/export?name=podcast", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		var library models.ChannelPresetLibrary
+		ParseResponse(t, w, &library)
+		if library.Format != models.ChannelPresetFormat || len(library.Presets) != 1 {
+			t.Fatalf("Unexpected library %+v", library)
+		}
+
+		library.Presets[0].Name = "Interview Show"
+		library.Presets[0].PromptText = "Summarize the interview"
+		w = HTTPRequest(t, env, "POST", "/channel-presets/import", library)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var presets []models.ChannelPreset
+		ParseResponse(t, HTTPRequest(t, env, "GET", "/channel-presets", nil), &presets)
+		if len(presets) != 4 || presets[1].Name != "interview show" || presets[1].BuiltIn {
+			t.Errorf("Expected the imported preset listed with the built-in ones, got %+v", presets)
+		}
+	})
+
+	t.Run("POST /channel-settings/:channel/preset configures a channel in one call", func(t *testing.T) {
+		HTTPRequest(t, env, "PUT", "/channel-settings/MoneyDaily", map[string]interface{}{
+			"platform":   "web",
+			"channelUrl": "https://example.com/moneydaily",
+		})
+
+		w := HTTPRequest(t, env, "POST", "/channel-settings/MoneyDaily/preset", map[string]interface{}{"preset": "finance-news"})
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var settings models.ChannelSettings
+		ParseResponse(t, HTTPRequest(t, env, "GET", "/channel-settings/MoneyDaily?platform=web", nil), &settings)
+		if settings.PromptSchema == "" || settings.Chunking == nil || settings.Chunking.ChunkSize != 300 {
+			t.Errorf("Expected the finance-news prompt and chunking, got %+v", settings)
+		}
+		if settings.ChannelUrl != "https://example.com/moneydaily" {
+			t.Errorf("Expected the channel URL kept, got '%s'", settings.ChannelUrl)
+		}
+	})
+
+	t.Run("POST /channel-settings/:channel/preset with an unknown preset returns 404", func(t *testing.T) {
+		w := HTTPRequest(t, env, "POST", "/channel-settings/Someone/preset", map[string]interface{}{"preset": "nope"})
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,38 @@
+package e2e
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestProfilingAPI(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	t.Run("GET /debug/pprof/ requires the admin token", func(t *testing.T) {
+		if w := adminRequest(env, "GET", "/debug/pprof/", ""); w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401 without a token, got %d", w.Code)
+		}
+		if w := adminRequest(env, "GET", "/debug/pprof/", "wrong-token"); w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401 with a wrong token, got %d", w.Code)
+		}
+	})
+
+	t.Run("GET /debug/pprof/ lists the profiles for admins", func(t *testing.T) {
+		w := adminRequest(env, "GET", "/debug/pprof/", testAdminToken)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if !strings.Contains(w.Body.String(), "goroutine") {
+			t.Errorf("Expected the profile index, got %s", w.Body.String())
+		}
+	})
+
+	t.Run("GET /debug/pprof/goroutine returns a named profile", func(t *testing.T) {
+		w := adminRequest(env, "GET", "/debug/pprof/goroutine?debug=1", testAdminToken)
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "goroutine profile") {
+			t.Errorf("Expected a goroutine profile, got %d: %.200s", w.Code, w.Body.String())
+		}
+	})
+}
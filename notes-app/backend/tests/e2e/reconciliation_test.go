@@ -0,0 +1,85 @@
+package e2e
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestReconciliationAPI(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	CleanupCollections(t, env)
+
+	// A chunk with no embedding, as left behind by a failed embedding call
+	noteID := primitive.NewObjectID()
+	_, err := env.Database.Collection("chunks").InsertOne(context.Background(), models.NoteChunk{
+		NoteID:  noteID,
+		Content: "chunk that was never embedded",
+	})
+	if err != nil {
+		t.Fatalf("Failed to insert chunk: %v", err)
+	}
+
+	t.Run("reconciliation routes require the admin token", func(t *testing.T) {
+		if w := adminRequest(env, "POST", "/admin/reconciliation/run", ""); w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401 without a token, got %d", w.Code)
+		}
+		if w := adminRequest(env, "GET", "/admin/reconciliation", "wrong-token"); w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401 with a wrong token, got %d", w.Code)
+		}
+	})
+
+	t.Run("POST /admin/reconciliation/run reports missing vectors", func(t *testing.T) {
+		w := adminRequest(env, "POST", "/admin/reconciliation/run", testAdminToken)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var report models.ReconciliationReport
+		ParseResponse(t, w, &report)
+
+		if report.Chunks != 1 || report.MissingVectors != 1 {
+			t.Errorf("Expected 1 chunk missing its vector, got chunks=%d missing=%d", report.Chunks, report.MissingVectors)
+		}
+		if !report.Alert {
+			t.Error("Expected drift alert")
+		}
+
+		found := false
+		for _, id := range report.DriftedNoteIDs {
+			if id == noteID {
+				found = true
+			}
+		}
+		if !found {
+			t.Error("Expected note with missing vector in drifted notes")
+		}
+	})
+
+	t.Run("GET /admin/reconciliation lists saved reports", func(t *testing.T) {
+		w := adminRequest(env, "GET", "/admin/reconciliation", testAdminToken)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+
+		var reports []models.ReconciliationReport
+		ParseResponse(t, w, &reports)
+
+		if len(reports) != 1 {
+			t.Errorf("Expected 1 report, got %d", len(reports))
+		}
+	})
+
+	t.Run("GET /admin/reconciliation with invalid limit returns 400", func(t *testing.T) {
+		w := adminRequest(env, "GET", "/admin/reconciliation?limit=0", testAdminToken)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,108 @@
+package e2e
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestReindexAPI(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	CleanupCollections(t, env)
+
+	t.Run("GET /admin/reindex before any reindex returns 404", func(t *testing.T) {
+		w := adminRequest(env, "GET", "/admin/reindex", testAdminToken)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+	})
+
+	t.Run("POST /admin/reindex without the admin token returns 401", func(t *testing.T) {
+		w := adminRequest(env, "POST", "/admin/reindex", "")
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401, got %d", w.Code)
+		}
+	})
+
+	for _, content := range []string{"Notes on pruning apple trees in late winter", "Notes on repotting a fiddle leaf fig"} {
+		w := HTTPRequest(t, env, "POST", "/notes", map[string]interface{}{"content": content})
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+		}
+	}
+
+	// chunkIDs lists the IDs of the embedded chunks, sorted
+	chunkIDs := func(t *testing.T) []string {
+		var chunks []models.NoteChunk
+		cursor, err := env.Database.Collection("chunks").Find(context.Background(), bson.M{"pending": bson.M{"$ne": true}})
+		if err != nil {
+			t.Fatalf("Failed to find chunks: %v", err)
+		}
+		if err := cursor.All(context.Background(), &chunks); err != nil {
+			t.Fatalf("Failed to decode chunks: %v", err)
+		}
+		var ids []string
+		for _, chunk := range chunks {
+			ids = append(ids, chunk.ID.Hex())
+		}
+		slices.Sort(ids)
+		return ids
+	}
+
+	t.Run("POST /admin/reindex re-embeds every note and reports progress", func(t *testing.T) {
+		var before []string
+		for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(200 * time.Millisecond) {
+			pending, err := env.Database.Collection("chunks").CountDocuments(context.Background(), bson.M{"pending": true})
+			if err != nil {
+				t.Fatalf("Failed to count chunks: %v", err)
+			}
+			if before = chunkIDs(t); len(before) > 0 && pending == 0 {
+				break
+			}
+		}
+
+		w := adminRequest(env, "POST", "/admin/reindex", testAdminToken)
+		if w.Code != http.StatusAccepted {
+			t.Fatalf("Expected status 202, got %d: %s", w.Code, w.Body.String())
+		}
+		var progress models.ReindexProgress
+		ParseResponse(t, w, &progress)
+		if progress.Run.Kind != models.MigrationReindex || progress.Run.Total != 2 {
+			t.Fatalf("Expected a reindex run of 2 notes, got %+v", progress.Run)
+		}
+
+		deadline := time.Now().Add(10 * time.Second)
+		for progress.Run.Status != models.MigrationCompleted {
+			if time.Now().After(deadline) {
+				t.Fatalf("Timed out waiting for the reindex, last progress %+v", progress)
+			}
+			time.Sleep(200 * time.Millisecond)
+
+			w := adminRequest(env, "GET", "/admin/reindex", testAdminToken)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+			progress = models.ReindexProgress{}
+			ParseResponse(t, w, &progress)
+		}
+
+		if progress.Run.Succeeded != 2 || progress.Remaining != 0 {
+			t.Errorf("Expected both notes re-embedded, got %+v", progress)
+		}
+		after := chunkIDs(t)
+		if len(after) == 0 {
+			t.Error("Expected the notes to have embedded chunks again")
+		}
+		if !slices.Equal(before, after) {
+			t.Errorf("Expected re-embedding to store the same chunk IDs, had %v, got %v", before, after)
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package e2e
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestResummarizeAPI(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	CleanupCollections(t, env)
+
+	t.Run("GET /admin/resummarize before any run returns 404", func(t *testing.T) {
+		w := adminRequest(env, "GET", "/admin/resummarize", testAdminToken)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+	})
+
+	t.Run("POST /admin/resummarize without the admin token returns 401", func(t *testing.T) {
+		w := adminRequest(env, "POST", "/admin/resummarize", "")
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401, got %d", w.Code)
+		}
+	})
+
+	var noteIDs []primitive.ObjectID
+	for _, content := range []string{
+		"Pruning apple trees in late winter keeps them productive.",
+		"Repotting a fiddle leaf fig in spring gives its roots room.",
+		"Mulching tomatoes holds moisture through the summer.",
+	} {
+		noteIDs = append(noteIDs, CreateTestNote(t, env, content, map[string]interface{}{"author": "GardenTalk"}))
+	}
+
+	t.Run("GET /admin/resummarize/estimate estimates tokens and time up front", func(t *testing.T) {
+		w := adminRequest(env, "GET", "/admin/resummarize/estimate?perMinute=60", testAdminToken)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var estimate models.AIOperationEstimate
+		ParseResponse(t, w, &estimate)
+		if estimate.Notes != 3 || estimate.PerMinute != 60 || estimate.EstimatedMinutes != 0.1 {
+			t.Errorf("Expected 3 notes at 60 a minute, got %+v", estimate)
+		}
+		if estimate.InputTokens <= 3*config.SUMMARY_PROMPT_TOKENS || estimate.OutputTokens != 3*config.SUMMARY_OUTPUT_TOKENS {
+			t.Errorf("Unexpected token estimate %+v", estimate)
+		}
+		if estimate.EstimatedCost != nil {
+			t.Errorf("Expected no cost without token prices, got %v", *estimate.EstimatedCost)
+		}
+	})
+
+	t.Run("POST /admin/resummarize rejects an invalid rate", func(t *testing.T) {
+		for _, perMinute := range []string{"0", "fast", "100000"} {
+			w := adminRequest(env, "POST", "/admin/resummarize?perMinute="+perMinute, testAdminToken)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("perMinute=%s: expected status 400, got %d", perMinute, w.Code)
+			}
+		}
+	})
+
+	t.Run("POST /admin/resummarize re-summarizes every note in the background", func(t *testing.T) {
+		w := adminRequest(env, "POST", "/admin/resummarize?perMinute=600", testAdminToken)
+		if w.Code != http.StatusAccepted {
+			t.Fatalf("Expected status 202, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var run models.MigrationRun
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			ParseResponse(t, adminRequest(env, "GET", "/admin/resummarize", testAdminToken), &run)
+			if run.Status == models.MigrationCompleted {
+				break
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		if run.Status != models.MigrationCompleted || run.Succeeded != 3 || run.PerMinute != 600 || run.Checkpoint != noteIDs[2] {
+			t.Fatalf("Expected a completed run over 3 notes, got %+v", run)
+		}
+
+		for _, id := range noteIDs {
+			var note models.Note
+			ParseResponse(t, HTTPRequest(t, env, "GET", "/notes/"+id.Hex(), nil), &note)
+			if note.Summary == "" {
+				t.Errorf("Expected note %s to have a summary", id.Hex())
+			}
+		}
+	})
+}
@@ -0,0 +1,102 @@
+package e2e
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestClassificationReviewAPI(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	t.Run("Review Queue", func(t *testing.T) {
+		CleanupCollections(t, env)
+
+		lowID := CreateTestNote(t, env, "Notes about something ambiguous", nil)
+		CreateTestNote(t, env, "A confidently classified note", nil)
+
+		_, err := env.Database.Collection("notes").UpdateByID(context.Background(), lowID, bson.M{
+			"$set": bson.M{"needs_review": true, "category_confidence": 0.3},
+		})
+		if err != nil {
+			t.Fatalf("Failed to flag note for review: %v", err)
+		}
+
+		// Test 1: Only flagged notes are returned
+		t.Run("GET /review/classification returns flagged notes", func(t *testing.T) {
+			w := HTTPRequest(t, env, "GET", "/review/classification", nil)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", w.Code)
+			}
+
+			var notes []models.Note
+			ParseResponse(t, w, &notes)
+
+			if len(notes) != 1 || notes[0].ID != lowID {
+				t.Errorf("Expected only the flagged note, got %d notes", len(notes))
+			}
+		})
+
+		// Test 2: Correcting a category clears the flag and records an example
+		t.Run("POST /review/classification applies corrections", func(t *testing.T) {
+			reqBody := map[string]interface{}{
+				"decisions": []map[string]interface{}{
+					{"noteId": lowID.Hex(), "category": "reflections"},
+				},
+			}
+
+			w := HTTPRequest(t, env, "POST", "/review/classification", reqBody)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+
+			var result struct {
+				Confirmed int `json:"confirmed"`
+				Corrected int `json:"corrected"`
+			}
+			ParseResponse(t, w, &result)
+
+			if result.Corrected != 1 || result.Confirmed != 0 {
+				t.Errorf("Expected 1 corrected, got %+v", result)
+			}
+
+			var note models.Note
+			if err := env.Database.Collection("notes").FindOne(context.Background(), bson.M{"_id": lowID}).Decode(&note); err != nil {
+				t.Fatalf("Failed to load note: %v", err)
+			}
+			if note.Category != "reflections" || note.NeedsReview {
+				t.Errorf("Expected reviewed note in 'reflections', got '%s' (needsReview=%v)", note.Category, note.NeedsReview)
+			}
+
+			count, _ := env.Database.Collection("classification_examples").CountDocuments(context.Background(), bson.M{})
+			if count != 1 {
+				t.Errorf("Expected 1 classification example, got %d", count)
+			}
+
+			var event models.NoteEvent
+			err := env.Database.Collection("note_events").FindOne(context.Background(), bson.M{"note_id": lowID, "type": models.NoteEventUpdated}).Decode(&event)
+			if err != nil || event.Delta["category"] != "reflections" {
+				t.Errorf("Expected a note.updated event with the new category, got %+v (%v)", event, err)
+			}
+		})
+
+		// Test 3: Invalid categories are rejected
+		t.Run("POST /review/classification with invalid category returns 400", func(t *testing.T) {
+			reqBody := map[string]interface{}{
+				"decisions": []map[string]interface{}{
+					{"noteId": lowID.Hex(), "category": "not-a-category"},
+				},
+			}
+
+			w := HTTPRequest(t, env, "POST", "/review/classification", reqBody)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400, got %d", w.Code)
+			}
+		})
+	})
+}
@@ -0,0 +1,224 @@
+package e2e
+
+import (
+	"net/http"
+	"testing"
+
+	"backend/internal/models"
+)
+
+func TestRulesAPI(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	t.Run("Rules CRUD", func(t *testing.T) {
+		CleanupCollections(t, env)
+
+		var ruleID string
+
+		// Test 1: Create a keyword rule
+		t.Run("POST /rules creates a rule", func(t *testing.T) {
+			reqBody := map[string]interface{}{
+				"name":      "Recipes by keyword",
+				"matchType": "keyword",
+				"field":     "content",
+				"pattern":   "tablespoon, preheat the oven",
+				"category":  "recipes",
+				"tags":      []string{"Cooking", "cooking"},
+				"priority":  10,
+				"enabled":   true,
+			}
+
+			w := HTTPRequest(t, env, "POST", "/rules", reqBody)
+
+			if w.Code != http.StatusCreated {
+				t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+			}
+
+			var rule models.CategoryRule
+			ParseResponse(t, w, &rule)
+			ruleID = rule.ID.Hex()
+
+			if len(rule.Tags) != 1 || rule.Tags[0] != "cooking" {
+				t.Errorf("Expected tags to be normalized to [cooking], got %v", rule.Tags)
+			}
+		})
+
+		// Test 2: List rules
+		t.Run("GET /rules returns created rule", func(t *testing.T) {
+			w := HTTPRequest(t, env, "GET", "/rules", nil)
+
+			if w.Code != http.StatusOK {
+				t.Errorf("Expected status 200, got %d", w.Code)
+			}
+
+			var rules []models.CategoryRule
+			ParseResponse(t, w, &rules)
+
+			if len(rules) != 1 {
+				t.Errorf("Expected 1 rule, got %d", len(rules))
+			}
+		})
+
+		// Test 3: Update rule
+		t.Run("PUT /rules/:id updates a rule", func(t *testing.T) {
+			if ruleID == "" {
+				t.Skip("No rule was created")
+			}
+
+			reqBody := map[string]interface{}{
+				"name":      "Recipes by keyword",
+				"matchType": "keyword",
+				"pattern":   "tablespoon",
+				"category":  "recipes",
+				"priority":  5,
+				"enabled":   true,
+			}
+
+			w := HTTPRequest(t, env, "PUT", "/rules/"+ruleID, reqBody)
+
+			if w.Code != http.StatusOK {
+				t.Errorf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+
+		// Test 4: Delete rule
+		t.Run("DELETE /rules/:id deletes a rule", func(t *testing.T) {
+			if ruleID == "" {
+				t.Skip("No rule was created")
+			}
+
+			w := HTTPRequest(t, env, "DELETE", "/rules/"+ruleID, nil)
+			if w.Code != http.StatusOK {
+				t.Errorf("Expected status 200, got %d", w.Code)
+			}
+
+			w = HTTPRequest(t, env, "DELETE", "/rules/"+ruleID, nil)
+			if w.Code != http.StatusNotFound {
+				t.Errorf("Expected status 404 on second delete, got %d", w.Code)
+			}
+		})
+	})
+
+	t.Run("Rules Validation", func(t *testing.T) {
+		CleanupCollections(t, env)
+
+		t.Run("POST /rules with unknown category returns 400", func(t *testing.T) {
+			reqBody := map[string]interface{}{
+				"name":      "Bad category",
+				"matchType": "keyword",
+				"pattern":   "garden",
+				"category":  "not-a-category",
+			}
+
+			w := HTTPRequest(t, env, "POST", "/rules", reqBody)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400, got %d", w.Code)
+			}
+		})
+
+		t.Run("POST /rules with invalid regex returns 400", func(t *testing.T) {
+			reqBody := map[string]interface{}{
+				"name":      "Bad regex",
+				"matchType": "regex",
+				"pattern":   "([a-z",
+				"category":  "other",
+			}
+
+			w := HTTPRequest(t, env, "POST", "/rules", reqBody)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400, got %d", w.Code)
+			}
+		})
+	})
+
+	t.Run("Rules Evaluation", func(t *testing.T) {
+		CleanupCollections(t, env)
+
+		HTTPRequest(t, env, "POST", "/rules", map[string]interface{}{
+			"name":      "Podcast platform",
+			"matchType": "metadata",
+			"field":     "platform",
+			"pattern":   "podcast",
+			"category":  "podcast-transcripts",
+			"priority":  1,
+			"enabled":   true,
+		})
+		errorCodes := map[string]interface{}{
+			"name":      "Error codes",
+			"matchType": "regex",
+			"pattern":   `(?i)\bERR_[A-Z_]+\b`,
+			"category":  "troubleshooting",
+			"tags":      []string{"errors"},
+			"priority":  10,
+			"enabled":   true,
+		}
+		var errorRule models.CategoryRule
+		ParseResponse(t, HTTPRequest(t, env, "POST", "/rules", errorCodes), &errorRule)
+
+		t.Run("POST /rules/test matches highest priority rule", func(t *testing.T) {
+			reqBody := map[string]interface{}{
+				"content":  "Got ERR_CONNECTION_RESET while recording the episode",
+				"metadata": map[string]interface{}{"platform": "Podcast"},
+			}
+
+			w := HTTPRequest(t, env, "POST", "/rules/test", reqBody)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", w.Code)
+			}
+
+			var response struct {
+				Matched bool             `json:"matched"`
+				Match   models.RuleMatch `json:"match"`
+			}
+			ParseResponse(t, w, &response)
+
+			if !response.Matched || response.Match.Category != "troubleshooting" {
+				t.Errorf("Expected troubleshooting rule to match, got %+v", response)
+			}
+		})
+
+		t.Run("POST /notes applies matching rule category and tags", func(t *testing.T) {
+			reqBody := map[string]interface{}{
+				"title":   "Deploy failure",
+				"content": "The deploy died with ERR_IMAGE_PULL again",
+			}
+
+			w := HTTPRequest(t, env, "POST", "/notes", reqBody)
+			if w.Code != http.StatusCreated {
+				t.Skipf("Note creation unavailable: %d %s", w.Code, w.Body.String())
+			}
+
+			var note models.Note
+			ParseResponse(t, w, &note)
+
+			if note.Category != "troubleshooting" {
+				t.Errorf("Expected category 'troubleshooting', got '%s'", note.Category)
+			}
+			if len(note.Tags) != 1 || note.Tags[0] != "errors" {
+				t.Errorf("Expected tags [errors], got %v", note.Tags)
+			}
+		})
+
+		t.Run("PUT /rules/:id applies to the next evaluation", func(t *testing.T) {
+			errorCodes["enabled"] = false
+			if w := HTTPRequest(t, env, "PUT", "/rules/"+errorRule.ID.Hex(), errorCodes); w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+
+			reqBody := map[string]interface{}{
+				"content":  "Got ERR_CONNECTION_RESET while recording the episode",
+				"metadata": map[string]interface{}{"platform": "Podcast"},
+			}
+			var response struct {
+				Matched bool             `json:"matched"`
+				Match   models.RuleMatch `json:"match"`
+			}
+			ParseResponse(t, HTTPRequest(t, env, "POST", "/rules/test", reqBody), &response)
+
+			if !response.Matched || response.Match.Category != "podcast-transcripts" {
+				t.Errorf("Expected the disabled rule skipped for the podcast rule, got %+v", response)
+			}
+		})
+	})
+}
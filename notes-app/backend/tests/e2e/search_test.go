@@ -1,11 +1,17 @@
 package e2e
 
 import (
+	"context"
 	"net/http"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"backend/internal/models"
+	"backend/internal/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
 )
 
 func TestSearchAPI(t *testing.T) {
@@ -41,6 +47,49 @@ func TestSearchAPI(t *testing.T) {
 			}
 		})
 
+		t.Run("POST /search reports the minimum score it applied", func(t *testing.T) {
+			w := HTTPRequest(t, env, "POST", "/search", map[string]interface{}{"query": "test query"})
+			if w.Code != http.StatusOK || w.Header().Get("X-Min-Score") != "0.3" {
+				t.Errorf("Expected status 200 with the default X-Min-Score 0.3, got %d with %q", w.Code, w.Header().Get("X-Min-Score"))
+			}
+
+			w = HTTPRequest(t, env, "POST", "/search", map[string]interface{}{"query": "test query", "minScore": 0.55})
+			if w.Code != http.StatusOK || w.Header().Get("X-Min-Score") != "0.55" {
+				t.Errorf("Expected status 200 with X-Min-Score 0.55, got %d with %q", w.Code, w.Header().Get("X-Min-Score"))
+			}
+		})
+
+		t.Run("POST /search with minScore outside 0-1 returns 400", func(t *testing.T) {
+			w := HTTPRequest(t, env, "POST", "/search", map[string]interface{}{"query": "test query", "minScore": 1.5})
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400, got %d", w.Code)
+			}
+		})
+
+		t.Run("POST /search limits matches to the given chunk types", func(t *testing.T) {
+			w := HTTPRequest(t, env, "POST", "/search", map[string]interface{}{"query": "test query", "chunkTypes": []string{"summary", "structured"}})
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+
+			var results []models.SearchResult
+			ParseResponse(t, w, &results)
+			for _, result := range results {
+				for _, match := range result.Matches {
+					if match.Type == "content" {
+						t.Errorf("Expected no content matches, got %+v", match)
+					}
+				}
+			}
+		})
+
+		t.Run("POST /search with an unknown chunk type returns 400", func(t *testing.T) {
+			w := HTTPRequest(t, env, "POST", "/search", map[string]interface{}{"query": "test query", "chunkTypes": []string{"heading"}})
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400, got %d", w.Code)
+			}
+		})
+
 		// Test 2: Search validation - missing query
 		t.Run("POST /search without query returns 400", func(t *testing.T) {
 			reqBody := map[string]interface{}{
@@ -66,6 +115,430 @@ func TestSearchAPI(t *testing.T) {
 				t.Errorf("Expected status 400, got %d", w.Code)
 			}
 		})
+
+		// Test 4: Search validation - unknown mode
+		t.Run("POST /search with unknown mode returns 400", func(t *testing.T) {
+			reqBody := map[string]interface{}{
+				"query": "test query",
+				"mode":  "fuzzy",
+			}
+
+			w := HTTPRequest(t, env, "POST", "/search", reqBody)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400, got %d", w.Code)
+			}
+		})
+
+		t.Run("POST /search ranks a note first when its title matches the query", func(t *testing.T) {
+			note := models.Note{
+				Title:    "Quarterly Planning Offsite",
+				Content:  "Agenda, venue options and travel budget for the team retreat",
+				Category: "other",
+				Created:  time.Now(),
+			}
+			inserted, err := env.Database.Collection("notes").InsertOne(context.Background(), note)
+			if err != nil {
+				t.Fatalf("Failed to create note: %v", err)
+			}
+
+			w := HTTPRequest(t, env, "POST", "/search", map[string]interface{}{"query": "quarterly planning - offsite"})
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+
+			var results []models.SearchResult
+			ParseResponse(t, w, &results)
+			if len(results) == 0 || results[0].Note.ID != inserted.InsertedID || !results[0].TitleMatch {
+				t.Errorf("Expected the title match to rank first, got %d results", len(results))
+			}
+		})
+
+		// Test 5: Hybrid search finds exact terms through the text index
+		t.Run("POST /search in hybrid mode matches exact keywords", func(t *testing.T) {
+			noteID := CreateTestNote(t, env, "Deploy failed with ERR_IMAGE_PULL on the staging cluster", nil)
+
+			reqBody := map[string]interface{}{
+				"query": "ERR_IMAGE_PULL",
+				"mode":  "hybrid",
+			}
+
+			w := HTTPRequest(t, env, "POST", "/search", reqBody)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+
+			var results []models.SearchResult
+			ParseResponse(t, w, &results)
+
+			if len(results) == 0 || results[0].Note.ID != noteID {
+				t.Errorf("Expected keyword match to rank first, got %d results", len(results))
+			}
+		})
+
+		// Test 6: Suggestions include recent queries and categories
+		t.Run("GET /search/suggest returns recent queries and categories", func(t *testing.T) {
+			w := HTTPRequest(t, env, "GET", "/search/suggest?q=err", nil)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", w.Code)
+			}
+
+			var suggestions []models.Suggestion
+			ParseResponse(t, w, &suggestions)
+
+			if len(suggestions) == 0 || suggestions[0].Type != models.SuggestionQuery || suggestions[0].Text != "ERR_IMAGE_PULL" {
+				t.Errorf("Expected recent query 'ERR_IMAGE_PULL' first, got %+v", suggestions)
+			}
+
+			w = HTTPRequest(t, env, "GET", "/search/suggest?q=reci", nil)
+			ParseResponse(t, w, &suggestions)
+
+			found := false
+			for _, s := range suggestions {
+				if s.Type == models.SuggestionCategory && s.Text == "recipes" {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("Expected category 'recipes' in suggestions, got %+v", suggestions)
+			}
+		})
+
+		// Test 7: Suggest validation - missing prefix
+		t.Run("GET /search/suggest without q returns 400", func(t *testing.T) {
+			w := HTTPRequest(t, env, "GET", "/search/suggest", nil)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400, got %d", w.Code)
+			}
+		})
+
+		// Test 8: Misspelled words are corrected against the notes vocabulary
+		t.Run("GET /search/did-you-mean corrects misspelled words", func(t *testing.T) {
+			w := HTTPRequest(t, env, "GET", "/search/did-you-mean?q=stagign%20clustr", nil)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", w.Code)
+			}
+
+			var suggestion models.DidYouMeanResponse
+			ParseResponse(t, w, &suggestion)
+
+			if suggestion.CorrectedQuery != "staging cluster" {
+				t.Errorf("Expected 'staging cluster', got %q", suggestion.CorrectedQuery)
+			}
+		})
+
+		// Test 9: autoCorrect searches with the corrected query
+		t.Run("POST /search with autoCorrect reports the corrected query", func(t *testing.T) {
+			reqBody := map[string]interface{}{
+				"query":       "stagign clustr",
+				"mode":        "hybrid",
+				"autoCorrect": true,
+			}
+
+			w := HTTPRequest(t, env, "POST", "/search", reqBody)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", w.Code)
+			}
+
+			if got := w.Header().Get("X-Corrected-Query"); got != "staging cluster" {
+				t.Errorf("Expected X-Corrected-Query 'staging cluster', got %q", got)
+			}
+		})
+
+		// Test 10: Long pasted text is accepted for similarity search
+		t.Run("POST /search/similar-to-text returns results for long text", func(t *testing.T) {
+			reqBody := map[string]interface{}{
+				"text": strings.Repeat("The staging deploy kept failing while pulling container images. ", 80),
+			}
+
+			w := HTTPRequest(t, env, "POST", "/search/similar-to-text", reqBody)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+
+			var results []models.SearchResult
+			ParseResponse(t, w, &results)
+		})
+
+		// Test 11: Similar-to-text validation - missing text
+		t.Run("POST /search/similar-to-text without text returns 400", func(t *testing.T) {
+			w := HTTPRequest(t, env, "POST", "/search/similar-to-text", map[string]interface{}{})
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400, got %d", w.Code)
+			}
+		})
+
+		t.Run("GET /notes/:id/related returns other notes, never the note itself", func(t *testing.T) {
+			noteID := CreateTestNote(t, env, "The staging deploy failed again while pulling container images from the registry", nil)
+
+			w := HTTPRequest(t, env, "GET", "/notes/"+noteID.Hex()+"/related?limit=3", nil)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+
+			var results []models.SearchResult
+			ParseResponse(t, w, &results)
+			if len(results) > 3 {
+				t.Errorf("Expected at most 3 results, got %d", len(results))
+			}
+			for _, result := range results {
+				if result.Note.ID == noteID {
+					t.Errorf("Expected the note itself to be left out")
+				}
+			}
+		})
+
+		t.Run("GET /notes/:id/related rejects bad IDs and limits", func(t *testing.T) {
+			if w := HTTPRequest(t, env, "GET", "/notes/not-an-id/related", nil); w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400 for an invalid ID, got %d", w.Code)
+			}
+			if w := HTTPRequest(t, env, "GET", "/notes/000000000000000000000000/related", nil); w.Code != http.StatusNotFound {
+				t.Errorf("Expected status 404 for a missing note, got %d", w.Code)
+			}
+			if w := HTTPRequest(t, env, "GET", "/notes/000000000000000000000000/related?limit=0", nil); w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400 for limit=0, got %d", w.Code)
+			}
+		})
+
+		t.Run("GET /topics puts each embedded note in one labeled topic", func(t *testing.T) {
+			w := HTTPRequest(t, env, "GET", "/topics?k=2", nil)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+
+			var topics models.TopicClusters
+			ParseResponse(t, w, &topics)
+			if len(topics.Clusters) > 2 {
+				t.Errorf("Expected at most 2 topics, got %d", len(topics.Clusters))
+			}
+			seen := make(map[string]bool)
+			for _, cluster := range topics.Clusters {
+				if cluster.Label == "" || cluster.Size != len(cluster.Notes) {
+					t.Errorf("Expected a labeled topic listing its notes, got %+v", cluster)
+				}
+				for _, note := range cluster.Notes {
+					if seen[note.ID.Hex()] {
+						t.Errorf("Expected note %s in one topic only", note.ID.Hex())
+					}
+					seen[note.ID.Hex()] = true
+				}
+			}
+			if len(seen) != topics.Notes {
+				t.Errorf("Expected %d clustered notes, got %d", topics.Notes, len(seen))
+			}
+		})
+
+		t.Run("GET /topics rejects k outside 1 to the maximum", func(t *testing.T) {
+			for _, k := range []string{"0", "31", "many"} {
+				if w := HTTPRequest(t, env, "GET", "/topics?k="+k, nil); w.Code != http.StatusBadRequest {
+					t.Errorf("Expected status 400 for k=%s, got %d", k, w.Code)
+				}
+			}
+		})
+
+		// Test 12: Exclusions drop matching notes from hybrid results
+		t.Run("POST /search excludes terms and channels", func(t *testing.T) {
+			videoID := CreateTestNote(t, env, "CrashLoopBackOff walkthrough from the video", map[string]interface{}{"author": "Kube Channel"})
+			blogID := CreateTestNote(t, env, "CrashLoopBackOff checklist for production", nil)
+
+			reqBody := map[string]interface{}{
+				"query":           "CrashLoopBackOff",
+				"mode":            "hybrid",
+				"excludeChannels": []string{"kubechannel"},
+				"excludeTerms":    []string{"production"},
+			}
+
+			w := HTTPRequest(t, env, "POST", "/search", reqBody)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+
+			var results []models.SearchResult
+			ParseResponse(t, w, &results)
+
+			for _, result := range results {
+				if result.Note.ID == videoID || result.Note.ID == blogID {
+					t.Errorf("Expected excluded note %s to be filtered out", result.Note.ID.Hex())
+				}
+			}
+		})
+
+		t.Run("POST /search scopes vector matches by author and platform", func(t *testing.T) {
+			for _, metadata := range []map[string]interface{}{
+				{"author": "Scoped Sue", "platform": "youtube"},
+				{"author": "Other Olly", "platform": "youtube"},
+			} {
+				w := HTTPRequest(t, env, "POST", "/notes", map[string]interface{}{
+					"content":  "Scoped search walkthrough for tuning vector payload indexes",
+					"metadata": metadata,
+				})
+				if w.Code != http.StatusCreated {
+					t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+				}
+			}
+			time.Sleep(3 * time.Second) // Let the worker embed the notes
+
+			w := HTTPRequest(t, env, "POST", "/search", map[string]interface{}{
+				"query":     "vector payload indexes",
+				"authors":   []string{"Scoped Sue"},
+				"platforms": []string{"YouTube"},
+				"minScore":  0,
+			})
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+
+			var results []models.SearchResult
+			ParseResponse(t, w, &results)
+			if len(results) == 0 {
+				t.Fatal("Expected the scoped note in the results")
+			}
+			for _, result := range results {
+				if result.Note.Metadata["author"] != "Scoped Sue" {
+					t.Errorf("Expected only notes by Scoped Sue, got %v", result.Note.Metadata["author"])
+				}
+			}
+		})
+
+		// Test 13: Per-request boosts lift pinned notes
+		t.Run("POST /search with pinned boost ranks pinned notes first", func(t *testing.T) {
+			CreateTestNote(t, env, "Flamegraph reading guide", nil)
+			pinnedID := CreateTestNote(t, env, "Flamegraph notes I curated", nil)
+
+			w := HTTPRequest(t, env, "POST", "/notes/bulk-update", map[string]interface{}{
+				"ids":    []string{pinnedID.Hex()},
+				"pinned": true,
+			})
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200 pinning note, got %d", w.Code)
+			}
+
+			reqBody := map[string]interface{}{
+				"query":  "Flamegraph",
+				"mode":   "hybrid",
+				"boosts": map[string]interface{}{"pinned": 5},
+			}
+
+			w = HTTPRequest(t, env, "POST", "/search", reqBody)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+
+			var results []models.SearchResult
+			ParseResponse(t, w, &results)
+
+			if len(results) == 0 || results[0].Note.ID != pinnedID {
+				t.Errorf("Expected pinned note to rank first, got %d results", len(results))
+			}
+		})
+
+		// Test 14: Debug mode explains how each result was ranked
+		t.Run("POST /search with debug returns ranking breakdown", func(t *testing.T) {
+			CreateTestNote(t, env, "Profiling with pprof", nil)
+
+			reqBody := map[string]interface{}{
+				"query": "pprof",
+				"mode":  "hybrid",
+				"debug": true,
+			}
+
+			w := HTTPRequest(t, env, "POST", "/search", reqBody)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+
+			var explanation models.SearchExplanation
+			ParseResponse(t, w, &explanation)
+
+			if explanation.Mode != "hybrid" {
+				t.Errorf("Expected mode 'hybrid', got '%s'", explanation.Mode)
+			}
+			if explanation.Filtered == nil {
+				t.Error("Expected filtered to be an array")
+			}
+			if len(explanation.Results) == 0 {
+				t.Fatal("Expected keyword match in results")
+			}
+			for _, result := range explanation.Results {
+				if result.Explain == nil {
+					t.Fatalf("Expected explanation for note %s", result.Note.ID.Hex())
+				}
+				if result.Explain.FusedScore == nil {
+					t.Error("Expected fused score in hybrid explanation")
+				}
+				if result.Explain.FinalScore != result.Score {
+					t.Errorf("Expected final score %f to match score %f", result.Explain.FinalScore, result.Score)
+				}
+			}
+		})
+
+		// Test 15: Keyword mode matches exact terms without embeddings
+		t.Run("POST /search in keyword mode matches exact keywords", func(t *testing.T) {
+			reqBody := map[string]interface{}{
+				"query": "ERR_IMAGE_PULL",
+				"mode":  "keyword",
+			}
+
+			w := HTTPRequest(t, env, "POST", "/search", reqBody)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+
+			var results []models.SearchResult
+			ParseResponse(t, w, &results)
+
+			if len(results) == 0 || !strings.Contains(results[0].Note.Content, "ERR_IMAGE_PULL") {
+				t.Errorf("Expected keyword match first, got %d results", len(results))
+			}
+			for _, result := range results {
+				if !strings.Contains(result.Note.Content, "ERR_IMAGE_PULL") {
+					t.Errorf("Expected only keyword matches, got '%s'", result.Note.Content)
+				}
+			}
+		})
+
+		// Test 16: Title suggestions match the start of any title word, ignoring case
+		t.Run("GET /search/suggest matches title words by prefix", func(t *testing.T) {
+			notesRepo := repository.NewNotesRepository(env.Database)
+			id, err := notesRepo.Create(context.Background(), &models.Note{Title: "Weekend Sourdough Schedule", Category: "recipes", Created: time.Now()})
+			if err != nil {
+				t.Fatalf("Failed to create note: %v", err)
+			}
+			if err := notesRepo.Update(context.Background(), id, bson.M{"$set": bson.M{"title": "Weekday Sourdough Schedule"}}); err != nil {
+				t.Fatalf("Failed to rename note: %v", err)
+			}
+
+			hasTitle := func(prefix string) bool {
+				w := HTTPRequest(t, env, "GET", "/search/suggest?q="+prefix, nil)
+				var suggestions []models.Suggestion
+				ParseResponse(t, w, &suggestions)
+				for _, s := range suggestions {
+					if s.Type == models.SuggestionTitle && s.Text == "Weekday Sourdough Schedule" {
+						return true
+					}
+				}
+				return false
+			}
+
+			if !hasTitle("SOURD") || !hasTitle("weekd") {
+				t.Error("Expected the renamed note suggested for a prefix of any title word")
+			}
+			if hasTitle("ough") || hasTitle("weekend") {
+				t.Error("Expected no suggestion for a word's middle or the old title")
+			}
+
+			w := HTTPRequest(t, env, "PATCH", "/notes/"+id.Hex()+"/archive", nil)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200 archiving the note, got %d", w.Code)
+			}
+			if hasTitle("sourd") {
+				t.Error("Expected an archived note's title not suggested")
+			}
+		})
 	})
 }
 
@@ -105,6 +578,102 @@ func TestQuestionAnswerAPI(t *testing.T) {
 		})
 
 		// Test 2: Ask validation - missing question
+		t.Run("POST /ask with debug returns filtered candidates", func(t *testing.T) {
+			reqBody := map[string]interface{}{
+				"question": "What do my notes say about tuning?",
+				"debug":    true,
+			}
+
+			w := HTTPRequest(t, env, "POST", "/ask", reqBody)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+
+			var response models.QuestionResponse
+			ParseResponse(t, w, &response)
+
+			for _, source := range response.Sources {
+				if source.Explain == nil {
+					t.Errorf("Expected explanation for source %s", source.Note.ID.Hex())
+				}
+			}
+			for _, candidate := range response.Filtered {
+				if candidate.Explain == nil || candidate.Explain.FilteredReason == "" {
+					t.Errorf("Expected filtered reason for candidate %s", candidate.Note.ID.Hex())
+				}
+			}
+		})
+
+		t.Run("POST /ask draws at most two sources from one channel", func(t *testing.T) {
+			for _, content := range []string{
+				"Feed the sourdough starter twice a day with equal flour and water",
+				"A sourdough starter is ready when it doubles within six hours",
+				"Keep a sourdough starter in the fridge between weekly bakes",
+			} {
+				w := HTTPRequest(t, env, "POST", "/notes", map[string]interface{}{
+					"content":  content,
+					"metadata": map[string]interface{}{"author": "Sourdough Sam"},
+				})
+				if w.Code != http.StatusCreated {
+					t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+				}
+			}
+			time.Sleep(3 * time.Second) // Let the worker embed the notes
+
+			w := HTTPRequest(t, env, "POST", "/ask", map[string]interface{}{"question": "How do I look after a sourdough starter?", "debug": true})
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+			var response models.QuestionResponse
+			ParseResponse(t, w, &response)
+
+			fromChannel := 0
+			for _, source := range response.Sources {
+				if source.Note.Metadata["author"] == "Sourdough Sam" {
+					fromChannel++
+				}
+			}
+			if fromChannel > 2 {
+				t.Errorf("Expected at most 2 sources from one channel, got %d", fromChannel)
+			}
+		})
+
+		t.Run("POST /ask with multiHop lists the searches made", func(t *testing.T) {
+			for _, content := range []string{
+				"Kombucha brewing notes from the fermentation workshop, taught by Priya Raman",
+				"Priya Raman recommends a second fermentation of three days for fizzier kombucha",
+			} {
+				w := HTTPRequest(t, env, "POST", "/notes", map[string]interface{}{"content": content})
+				if w.Code != http.StatusCreated {
+					t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+				}
+			}
+			time.Sleep(3 * time.Second) // Let the worker embed the notes
+
+			question := "What did the teacher of the fermentation workshop recommend for fizzier kombucha?"
+			w := HTTPRequest(t, env, "POST", "/ask", map[string]interface{}{"question": question, "multiHop": true})
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+			var response models.QuestionResponse
+			ParseResponse(t, w, &response)
+
+			if len(response.Hops) == 0 || response.Hops[0].Hop != 1 || response.Hops[0].Query != question {
+				t.Fatalf("Expected the first hop to search for the question, got %+v", response.Hops)
+			}
+			sources := make(map[string]bool)
+			for _, source := range response.Sources {
+				sources[source.Note.ID.Hex()] = true
+			}
+			for _, hop := range response.Hops {
+				for _, id := range hop.NoteIDs {
+					if !sources[id] {
+						t.Errorf("Expected hop %d note %s among the sources", hop.Hop, id)
+					}
+				}
+			}
+		})
+
 		t.Run("POST /ask without question returns 400", func(t *testing.T) {
 			reqBody := map[string]interface{}{}
 
@@ -114,6 +683,138 @@ func TestQuestionAnswerAPI(t *testing.T) {
 				t.Errorf("Expected status 400, got %d", w.Code)
 			}
 		})
+
+		t.Run("POST /ask reports the minimum score of context notes", func(t *testing.T) {
+			w := HTTPRequest(t, env, "POST", "/ask", map[string]interface{}{"question": "What is the meaning of life?", "minScore": 0.6})
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+
+			var response models.QuestionResponse
+			ParseResponse(t, w, &response)
+			if response.MinScore != 0.6 {
+				t.Errorf("Expected minScore 0.6, got %v", response.MinScore)
+			}
+		})
+
+		t.Run("POST /ask with minScore outside 0-1 returns 400", func(t *testing.T) {
+			w := HTTPRequest(t, env, "POST", "/ask", map[string]interface{}{"question": "What is the meaning of life?", "minScore": -0.1})
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400, got %d", w.Code)
+			}
+		})
+
+		t.Run("POST /ask with responseFormat json returns structured data", func(t *testing.T) {
+			reqBody := map[string]interface{}{
+				"question":       "What is the meaning of life?",
+				"responseFormat": "json",
+				"schema":         map[string]interface{}{"answer": "string", "confidence": "number"},
+			}
+			w := HTTPRequest(t, env, "POST", "/ask", reqBody)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+
+			var response models.QuestionResponse
+			ParseResponse(t, w, &response)
+			if _, ok := response.Data["answer"]; !ok {
+				t.Errorf("Expected an answer field in data, got %v", response.Data)
+			}
+		})
+
+		t.Run("POST /ask rejects an unknown responseFormat or a schema without json", func(t *testing.T) {
+			for _, reqBody := range []map[string]interface{}{
+				{"question": "What is the meaning of life?", "responseFormat": "haiku"},
+				{"question": "What is the meaning of life?", "responseFormat": "table", "schema": map[string]interface{}{"answer": "string"}},
+			} {
+				w := HTTPRequest(t, env, "POST", "/ask", reqBody)
+				if w.Code != http.StatusBadRequest {
+					t.Errorf("Expected status 400 for %v, got %d", reqBody, w.Code)
+				}
+			}
+
+			w := HTTPRequest(t, env, "POST", "/ask/stream", map[string]interface{}{"question": "What is the meaning of life?", "responseFormat": "json"})
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400 for a streamed json answer, got %d", w.Code)
+			}
+		})
+
+		// Test 3: Streaming answer emits tokens followed by sources
+		t.Run("POST /ask/stream streams tokens and sources", func(t *testing.T) {
+			reqBody := map[string]interface{}{
+				"question": "What is the meaning of life?",
+			}
+
+			w := HTTPRequest(t, env, "POST", "/ask/stream", reqBody)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", w.Code)
+			}
+
+			body := w.Body.String()
+			if !strings.Contains(body, "event:token") {
+				t.Errorf("Expected token events in stream, got %q", body)
+			}
+			if !strings.Contains(body, "event:sources") {
+				t.Errorf("Expected final sources event in stream, got %q", body)
+			}
+		})
+
+		t.Run("POST /ask always uses context-pinned notes", func(t *testing.T) {
+			glossaryID := CreateTestNote(t, env, "Glossary: OKR means objectives and key results", nil)
+			w := HTTPRequest(t, env, "POST", "/notes/"+glossaryID.Hex()+"/context-pin", nil)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+
+			w = HTTPRequest(t, env, "POST", "/ask", map[string]interface{}{"question": "What is the meaning of life?"})
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+			var response models.QuestionResponse
+			ParseResponse(t, w, &response)
+			if len(response.Sources) == 0 || response.Sources[0].Note.ID != glossaryID {
+				t.Errorf("Expected the pinned note as the first source, got %d sources", len(response.Sources))
+			}
+
+			w = HTTPRequest(t, env, "POST", "/notes/"+glossaryID.Hex()+"/context-unpin", nil)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", w.Code)
+			}
+			var note models.Note
+			ParseResponse(t, w, &note)
+			if note.AlwaysInContext {
+				t.Error("Expected the note to be unpinned from context")
+			}
+		})
+
+		t.Run("POST /ask with saveAsNote saves the exchange as a note", func(t *testing.T) {
+			sourceID := CreateTestNote(t, env, "Deploys happen every Tuesday after the standup", nil)
+			if w := HTTPRequest(t, env, "POST", "/notes/"+sourceID.Hex()+"/context-pin", nil); w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", w.Code)
+			}
+			defer HTTPRequest(t, env, "POST", "/notes/"+sourceID.Hex()+"/context-unpin", nil)
+
+			w := HTTPRequest(t, env, "POST", "/ask", map[string]interface{}{"question": "When do deploys happen?", "saveAsNote": true})
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+			var response models.QuestionResponse
+			ParseResponse(t, w, &response)
+			if response.NoteID == "" {
+				t.Fatal("Expected the answer to be saved as a note")
+			}
+
+			w = HTTPRequest(t, env, "GET", "/notes/"+response.NoteID, nil)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", w.Code)
+			}
+			var note models.Note
+			ParseResponse(t, w, &note)
+			if note.Category != "qa-answers" || !strings.Contains(note.Content, response.Answer) || !strings.Contains(note.Content, sourceID.Hex()) {
+				t.Errorf("Expected a qa-answers note citing its source, got %+v", note)
+			}
+		})
 	})
 }
 
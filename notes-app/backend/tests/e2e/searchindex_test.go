@@ -0,0 +1,177 @@
+package e2e
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/searchindex"
+	"backend/internal/services"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakeMeilisearch stores documents in memory and matches queries by substring
+type fakeMeilisearch struct {
+	mu   sync.Mutex
+	docs map[string]searchindex.Document
+	// order preserves insertion order so search results are deterministic
+	order []string
+	down  bool // Answers every request as unavailable
+}
+
+func (f *fakeMeilisearch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case f.down:
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	case strings.HasSuffix(r.URL.Path, "/documents") && r.Method == http.MethodPost:
+		var docs []searchindex.Document
+		json.NewDecoder(r.Body).Decode(&docs)
+		for _, doc := range docs {
+			if _, ok := f.docs[doc.ID]; !ok {
+				f.order = append(f.order, doc.ID)
+			}
+			f.docs[doc.ID] = doc
+		}
+	case strings.HasSuffix(r.URL.Path, "/documents/delete-batch"):
+		var ids []string
+		json.NewDecoder(r.Body).Decode(&ids)
+		for _, id := range ids {
+			delete(f.docs, id)
+		}
+	case strings.HasSuffix(r.URL.Path, "/search"):
+		var req struct {
+			Q string `json:"q"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		hits := []map[string]string{}
+		for _, id := range f.order {
+			if doc, ok := f.docs[id]; ok && strings.Contains(doc.Content, req.Q) {
+				hits = append(hits, map[string]string{"id": id})
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"hits": hits})
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+	w.Write([]byte(`{}`))
+}
+
+func TestSearchIndexSync(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	CleanupCollections(t, env)
+
+	fake := &fakeMeilisearch{docs: make(map[string]searchindex.Document)}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	index := searchindex.NewMeilisearch(server.URL, "")
+	notesRepo := repository.NewNotesRepository(env.Database)
+	eventsRepo := repository.NewNoteEventsRepository(env.Database)
+	settingsRepo := repository.NewSettingsRepository(env.Database)
+	indexService := services.NewSearchIndexService(notesRepo, eventsRepo, settingsRepo, index)
+
+	cfg := config.LoadConfig()
+	cfg.SearchIndexKeywordRouting = true
+	searchService := services.NewSearchService(notesRepo, nil, repository.NewChannelSettingsRepository(env.Database), nil, nil, nil, index, cfg)
+
+	ctx := context.Background()
+	firstID := CreateTestNote(t, env, "Rotate the vault token before Friday", map[string]interface{}{"author": "Ops"})
+	secondID := CreateTestNote(t, env, "The vault migration is done", nil)
+
+	t.Run("Reindex pushes every note", func(t *testing.T) {
+		count, err := indexService.Reindex(ctx)
+		if err != nil {
+			t.Fatalf("Reindex failed: %v", err)
+		}
+		if count != 2 || len(fake.docs) != 2 {
+			t.Fatalf("Expected 2 indexed notes, got %d (%d stored)", count, len(fake.docs))
+		}
+		if doc := fake.docs[firstID.Hex()]; doc.Author != "Ops" || doc.Title != "Test Note" {
+			t.Errorf("Expected note fields in document, got %+v", doc)
+		}
+	})
+
+	t.Run("Keyword search is routed to the index", func(t *testing.T) {
+		results, err := searchService.KeywordSearch(ctx, "vault", 10, models.SearchFilters{}, nil)
+		if err != nil {
+			t.Fatalf("Keyword search failed: %v", err)
+		}
+		if len(results) != 2 || results[0].Note.ID != firstID || results[1].Note.ID != secondID {
+			t.Fatalf("Expected both notes in index order, got %d results", len(results))
+		}
+		if results[0].Score <= results[1].Score {
+			t.Errorf("Expected scores to follow index rank, got %f and %f", results[0].Score, results[1].Score)
+		}
+	})
+
+	t.Run("Sync removes deleted notes and updates changed ones", func(t *testing.T) {
+		if _, err := env.Database.Collection("notes").DeleteOne(ctx, bson.M{"_id": secondID}); err != nil {
+			t.Fatalf("Failed to delete note: %v", err)
+		}
+		if _, err := env.Database.Collection("notes").UpdateOne(ctx, bson.M{"_id": firstID}, bson.M{"$set": bson.M{"archived": true}}); err != nil {
+			t.Fatalf("Failed to archive note: %v", err)
+		}
+
+		if err := indexService.Sync(ctx, []primitive.ObjectID{firstID, secondID}); err != nil {
+			t.Fatalf("Sync failed: %v", err)
+		}
+		if _, ok := fake.docs[secondID.Hex()]; ok {
+			t.Error("Expected deleted note removed from the index")
+		}
+		if !fake.docs[firstID.Hex()].Archived {
+			t.Error("Expected archived flag synced to the index")
+		}
+	})
+
+	t.Run("SyncEvents syncs logged events from the stored cursor", func(t *testing.T) {
+		before, _, err := settingsRepo.GetSearchIndexCursor(ctx)
+		if err != nil {
+			t.Fatalf("Failed to load cursor: %v", err)
+		}
+
+		// Changes made while the index is down are kept in the log until it is back
+		noteID := CreateTestNote(t, env, "Renew the vault certificate", nil)
+		eventID, err := eventsRepo.Create(ctx, &models.NoteEvent{Type: models.NoteEventCreated, NoteID: noteID, CreatedAt: time.Now()})
+		if err != nil {
+			t.Fatalf("Failed to log event: %v", err)
+		}
+		fake.mu.Lock()
+		fake.down = true
+		fake.mu.Unlock()
+		if err := indexService.SyncEvents(ctx); err == nil {
+			t.Fatal("Expected the sync to fail while the index is down")
+		}
+		if cursor, _, _ := settingsRepo.GetSearchIndexCursor(ctx); cursor != before {
+			t.Errorf("Expected the cursor kept at %s after a failed sync, got %s", before.Hex(), cursor.Hex())
+		}
+
+		fake.mu.Lock()
+		fake.down = false
+		fake.mu.Unlock()
+		if err := indexService.SyncEvents(ctx); err != nil {
+			t.Fatalf("SyncEvents failed: %v", err)
+		}
+		if _, ok := fake.docs[noteID.Hex()]; !ok {
+			t.Error("Expected the note of the logged event indexed")
+		}
+		if cursor, _, _ := settingsRepo.GetSearchIndexCursor(ctx); cursor != eventID {
+			t.Errorf("Expected the cursor moved to the synced event %s, got %s", eventID.Hex(), cursor.Hex())
+		}
+	})
+}
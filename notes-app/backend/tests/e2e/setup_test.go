@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/netip"
 	"os"
 	"testing"
 	"time"
@@ -19,11 +20,17 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"backend/internal/ai"
+	"backend/internal/clipper"
+	"backend/internal/config"
+	"backend/internal/graphql"
 	"backend/internal/handlers"
 	"backend/internal/models"
+	"backend/internal/podcast"
 	"backend/internal/repository"
 	"backend/internal/services"
+	"backend/internal/transcribe"
 	"backend/internal/vectordb"
+	"backend/internal/youtube"
 )
 
 // TestEnv holds all test dependencies
@@ -32,11 +39,15 @@ type TestEnv struct {
 	MongoClient *mongo.Client
 	Database    *mongo.Database
 	QdrantURL   string
+	MockAI      *ai.MockAIClient // The AI client, unless USE_REAL_AI=true
 	CleanupFns  []func()
 }
 
 var testEnv *TestEnv
 
+// testAdminToken guards the admin-only routes of the test router
+const testAdminToken = "test-admin-token"
+
 // SetupTestEnv initializes the test environment
 func SetupTestEnv(t *testing.T) *TestEnv {
 	if testEnv != nil {
@@ -56,7 +67,8 @@ func SetupTestEnv(t *testing.T) *TestEnv {
 		qdrantURL = "localhost:6334"
 	}
 
-	geminiAPIKey := os.Getenv("GEMINI_API_KEY")
+	cfg := config.LoadConfig()
+	geminiAPIKey := cfg.GeminiAPIKey
 	useRealAI := os.Getenv("USE_REAL_AI") == "true"
 
 	if !useRealAI {
@@ -88,6 +100,65 @@ func SetupTestEnv(t *testing.T) *TestEnv {
 	notesRepo := repository.NewNotesRepository(database)
 	chunksRepo := repository.NewChunksRepository(database)
 	channelSettingsRepo := repository.NewChannelSettingsRepository(database)
+	rulesRepo := repository.NewCategoryRulesRepository(database)
+	examplesRepo := repository.NewClassificationExamplesRepository(database)
+	auditRepo := repository.NewAuditRepository(database)
+	conversationsRepo := repository.NewConversationsRepository(database)
+	searchQueriesRepo := repository.NewSearchQueriesRepository(database)
+	reconciliationRepo := repository.NewReconciliationRepository(database)
+	noteEventsRepo := repository.NewNoteEventsRepository(database)
+	webhooksRepo := repository.NewWebhooksRepository(database)
+	webhookSecretsRepo := repository.NewWebhookSecretsRepository(database)
+	goalsRepo := repository.NewGoalsRepository(database)
+	podcastFeedsRepo := repository.NewPodcastFeedsRepository(database)
+	migrationRunsRepo := repository.NewMigrationRunsRepository(database)
+	jobsRepo := repository.NewJobsRepository(database)
+	deadLetterRepo := repository.NewDeadLetterRepository(database)
+	settingsRepo := repository.NewSettingsRepository(database)
+	channelPresetsRepo := repository.NewChannelPresetsRepository(database)
+	aiOperationsRepo := repository.NewAIOperationsRepository(database)
+	aiSamplesRepo := repository.NewAISamplesRepository(database)
+	graphRepo := repository.NewGraphRepository(database)
+	automationRepo := repository.NewAutomationRulesRepository(database)
+	categoriesRepo := repository.NewCategoriesRepository(database)
+	filesRepo, err := repository.NewFilesRepository(database)
+	if err != nil {
+		t.Fatalf("Failed to open uploads bucket: %v", err)
+	}
+
+	if err := notesRepo.EnsureTextIndex(context.Background()); err != nil {
+		t.Logf("Warning: Could not create notes text index: %v", err)
+	}
+	if err := notesRepo.EnsureSuggestIndexes(context.Background()); err != nil {
+		t.Logf("Warning: Could not create notes suggestion indexes: %v", err)
+	}
+	if err := notesRepo.EnsureVaultPathIndex(context.Background()); err != nil {
+		t.Logf("Warning: Could not create notes vault path index: %v", err)
+	}
+	if err := notesRepo.EnsureEntityIndex(context.Background()); err != nil {
+		t.Logf("Warning: Could not create notes entities index: %v", err)
+	}
+	if err := channelSettingsRepo.EnsureIndexes(context.Background()); err != nil {
+		t.Logf("Warning: Could not create channel settings index: %v", err)
+	}
+	if err := searchQueriesRepo.EnsureIndexes(context.Background()); err != nil {
+		t.Logf("Warning: Could not create search queries index: %v", err)
+	}
+	if err := podcastFeedsRepo.EnsureIndexes(context.Background()); err != nil {
+		t.Logf("Warning: Could not create podcast feeds index: %v", err)
+	}
+	if err := jobsRepo.EnsureIndexes(context.Background()); err != nil {
+		t.Logf("Warning: Could not create processing jobs index: %v", err)
+	}
+	if err := aiOperationsRepo.EnsureIndexes(context.Background()); err != nil {
+		t.Logf("Warning: Could not create AI operations index: %v", err)
+	}
+	if err := aiSamplesRepo.EnsureCollection(context.Background(), config.AI_SAMPLES_MAX_BYTES); err != nil {
+		t.Logf("Warning: Could not create AI samples collection: %v", err)
+	}
+	if err := graphRepo.EnsureIndexes(context.Background()); err != nil {
+		t.Logf("Warning: Could not create graph edges index: %v", err)
+	}
 
 	// Initialize Qdrant client
 	var qdrantClient *vectordb.QdrantClient
@@ -95,60 +166,114 @@ func SetupTestEnv(t *testing.T) *TestEnv {
 	if err != nil {
 		t.Logf("Warning: Could not connect to Qdrant at %s: %v. Skipping vector tests.", qdrantURL, err)
 	} else {
-		if err := qdrantClient.Initialize(); err != nil {
+		if err := qdrantClient.Initialize(config.EMBEDDING_DIM); err != nil {
 			t.Logf("Warning: Could not initialize Qdrant: %v", err)
 		}
 	}
 
 	// Initialize AI client (mock unless USE_REAL_AI=true)
 	var aiClient ai.Client
+	var mockAI *ai.MockAIClient
 	if useRealAI {
-		aiClient, err = ai.NewAIClient(context.Background(), geminiAPIKey)
+		aiClient, err = ai.NewAIClient(context.Background(), cfg)
 		if err != nil {
 			t.Logf("Warning: Could not create AI client: %v. Using mock AI client.", err)
-			aiClient = ai.NewMockAIClient()
+			mockAI = ai.NewMockAIClient()
+			aiClient = mockAI
 		} else {
 			t.Log("Using REAL Gemini AI client - API calls will consume quota")
 		}
 	} else {
-		aiClient = ai.NewMockAIClient()
+		mockAI = ai.NewMockAIClient()
+		aiClient = mockAI
+	}
+
+	webhookService := services.NewWebhookService(noteEventsRepo, webhooksRepo, webhookSecretsRepo, allowAnyAddress)
+	webhookService.Start()
+
+	eventStream := services.NewEventStream()
+	webhookService.Subscribe(eventStream.Publish)
+
+	rulesService := services.NewRulesService(rulesRepo)
+	categoryService := services.NewCategoryService(categoriesRepo, notesRepo, rulesService, automationRepo, examplesRepo, settingsRepo, webhookService, qdrantClient)
+	if err := categoryService.Load(context.Background()); err != nil {
+		t.Fatalf("Failed to load categories: %v", err)
 	}
 
 	// Initialize worker pool (always initialize with AI client, even if mock)
 	var workerPool *services.WorkerPool
 	if qdrantClient != nil {
-		workerPool = services.NewWorkerPool(1, 10, chunksRepo, aiClient, qdrantClient)
+		workerPool = services.NewWorkerPool(1, jobsRepo, deadLetterRepo, notesRepo, webhookService, chunksRepo, channelSettingsRepo, aiClient, qdrantClient, cfg)
 		workerPool.Start()
 	}
 
+	goalsService := services.NewGoalsService(goalsRepo, notesRepo, aiClient, cfg)
+	webhookService.Subscribe(goalsService.HandleEvent)
+	goalsService.Start()
+
 	// Create services
+	reviewService := services.NewReviewService(notesRepo, examplesRepo, webhookService, qdrantClient, cfg)
+	pipelineService := services.NewPipelineService(settingsRepo)
+	aiHistoryService := services.NewAIHistoryService(aiOperationsRepo, notesRepo, cfg)
+	presetService := services.NewPresetService(channelPresetsRepo, channelSettingsRepo)
+	automationService := services.NewAutomationService(automationRepo, presetService)
+
 	notesService := services.NewNotesService(
 		notesRepo,
 		chunksRepo,
 		channelSettingsRepo,
+		auditRepo,
 		aiClient,
 		qdrantClient,
 		workerPool,
+		rulesService,
+		reviewService,
+		webhookService,
+		pipelineService,
+		automationService,
+		aiHistoryService,
+		cfg,
 	)
 
 	var searchService *services.SearchService
 	if qdrantClient != nil {
-		searchService = services.NewSearchService(notesRepo, aiClient, qdrantClient)
+		searchService = services.NewSearchService(notesRepo, chunksRepo, channelSettingsRepo, settingsRepo, aiClient, qdrantClient, nil, cfg)
 	}
 
-	summaryService := services.NewSummaryService(notesRepo, channelSettingsRepo, aiClient)
+	graphService := services.NewGraphService(graphRepo, notesRepo, searchService)
+	webhookService.Subscribe(graphService.HandleEvent)
+	graphService.Start()
+
+	summaryService := services.NewSummaryService(notesRepo, channelSettingsRepo, aiClient, workerPool, webhookService, aiHistoryService, cfg)
 
 	// Create handlers
 	notesHandler := handlers.NewNotesHandler(notesService)
-	categoriesHandler := handlers.NewCategoriesHandler(notesRepo, aiClient)
+	categoriesHandler := handlers.NewCategoriesHandler(notesRepo, categoryService)
 	channelsHandler := handlers.NewChannelsHandler(notesRepo, chunksRepo, channelSettingsRepo, qdrantClient)
+	rulesHandler := handlers.NewRulesHandler(rulesService)
+	reviewHandler := handlers.NewReviewHandler(reviewService)
+	platformsHandler := handlers.NewPlatformsHandler(notesRepo, channelSettingsRepo)
+	exportHandler := handlers.NewExportHandler(services.NewExportService(notesRepo, chunksRepo, channelSettingsRepo, workerPool, webhookService))
+	notionHandler := handlers.NewNotionHandler(services.NewNotionImportService(notesService))
+	vaultSyncHandler := handlers.NewVaultSyncHandler(services.NewVaultSyncService(notesRepo, notesService))
+	webhooksHandler := handlers.NewWebhooksHandler(webhookService)
+	eventsHandler := handlers.NewEventsHandler(eventStream)
+	goalsHandler := handlers.NewGoalsHandler(goalsService)
+	statsHandler := handlers.NewStatsHandler(services.NewStatsService(notesRepo))
+	entitiesHandler := handlers.NewEntitiesHandler(services.NewEntityService(notesRepo))
+	graphHandler := handlers.NewGraphHandler(graphService, testAdminToken)
+	stalenessHandler := handlers.NewStalenessHandler(services.NewStalenessService(notesRepo, aiClient))
+	clipHandler := handlers.NewClipHandler(services.NewClipService(notesRepo, notesService, clipper.NewClient(allowAnyAddress)))
+	uploadsHandler := handlers.NewUploadsHandler(services.NewUploadService(notesRepo, filesRepo, notesService, aiClient))
+	podcastsHandler := handlers.NewPodcastsHandler(services.NewPodcastService(podcastFeedsRepo, notesRepo, notesService, podcast.NewClient(allowAnyAddress), transcribe.NewClient(cfg), cfg))
+	graphqlHandler := handlers.NewGraphQLHandler(graphql.NewSchema(notesService, searchService, channelSettingsRepo))
 
 	// Configure Gin router
 	router := gin.New()
 	router.Use(gin.Recovery())
 	router.Use(cors.New(cors.Config{
 		AllowAllOrigins:  true,
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Content-Type"},
 		AllowCredentials: false,
 	}))
@@ -156,29 +281,96 @@ func SetupTestEnv(t *testing.T) *TestEnv {
 	// Register routes
 	notesHandler.RegisterRoutes(router)
 	categoriesHandler.RegisterRoutes(router)
+	migrationService := services.NewMigrationService(notesRepo, migrationRunsRepo, aiClient, qdrantClient, rulesService, reviewService, summaryService, aiHistoryService, cfg)
+	handlers.NewMigrationsHandler(migrationService).RegisterRoutes(router)
+	handlers.NewResummarizeHandler(migrationService, testAdminToken).RegisterRoutes(router)
+	handlers.NewEstimatesHandler(migrationService, testAdminToken).RegisterRoutes(router)
+	handlers.NewAISamplesHandler(services.NewAISampleService(aiSamplesRepo, cfg.AISampleRate), testAdminToken).RegisterRoutes(router)
+	handlers.NewAIHistoryHandler(aiHistoryService).RegisterRoutes(router)
+	handlers.NewTranslationHandler(services.NewTranslationService(notesRepo, aiClient, webhookService, aiHistoryService, cfg)).RegisterRoutes(router)
+	handlers.NewJobsHandler(services.NewJobsService(jobsRepo, deadLetterRepo, notesRepo, workerPool), testAdminToken).RegisterRoutes(router)
+	handlers.NewPersonaHandler(services.NewPersonaService(settingsRepo)).RegisterRoutes(router)
+	handlers.NewPipelineHandler(pipelineService).RegisterRoutes(router)
+	handlers.NewPresetsHandler(presetService).RegisterRoutes(router)
+	handlers.NewAutomationsHandler(automationService).RegisterRoutes(router)
 	channelsHandler.RegisterRoutes(router)
+	rulesHandler.RegisterRoutes(router)
+	reviewHandler.RegisterRoutes(router)
+	platformsHandler.RegisterRoutes(router)
+	exportHandler.RegisterRoutes(router)
+	notionHandler.RegisterRoutes(router)
+	vaultSyncHandler.RegisterRoutes(router)
+	webhooksHandler.RegisterRoutes(router)
+	eventsHandler.RegisterRoutes(router)
+	goalsHandler.RegisterRoutes(router)
+	statsHandler.RegisterRoutes(router)
+	entitiesHandler.RegisterRoutes(router)
+	graphHandler.RegisterRoutes(router)
+	stalenessHandler.RegisterRoutes(router)
+	podcastsHandler.RegisterRoutes(router)
+	clipHandler.RegisterRoutes(router)
+	uploadsHandler.RegisterRoutes(router)
+	graphqlHandler.RegisterRoutes(router)
 
 	// Register search and summary handlers
+	var readinessService *services.ReadinessService
 	if searchService != nil {
-		searchHandler := handlers.NewSearchHandler(searchService, aiClient)
+		readinessService = services.NewReadinessService(qdrantClient)
+		readinessService.Start()
+		handlers.NewHealthHandler(readinessService).RegisterRoutes(router)
+
+		suggestService := services.NewSuggestService(notesRepo, searchQueriesRepo)
+		spellService := services.NewSpellService(notesRepo)
+		searchHandler := handlers.NewSearchHandler(searchService, suggestService, spellService, services.NewAnswerNotesService(notesService), aiClient)
 		searchHandler.RegisterRoutes(router)
+
+		conversationService := services.NewConversationService(conversationsRepo, searchService)
+		conversationsHandler := handlers.NewConversationsHandler(conversationService)
+		conversationsHandler.RegisterRoutes(router)
+
+		embeddingsHandler := handlers.NewEmbeddingsHandler(services.NewEmbeddingsService(qdrantClient), testAdminToken)
+		embeddingsHandler.RegisterRoutes(router)
+
+		handlers.NewTopicsHandler(services.NewTopicService(notesRepo, aiClient, qdrantClient)).RegisterRoutes(router)
+
+		reconciliationService := services.NewReconciliationService(chunksRepo, reconciliationRepo, qdrantClient, cfg)
+		reconciliationHandler := handlers.NewReconciliationHandler(reconciliationService, testAdminToken)
+		reconciliationHandler.RegisterRoutes(router)
+
+		reindexService := services.NewReindexService(notesRepo, chunksRepo, migrationRunsRepo, workerPool, qdrantClient, cfg)
+		handlers.NewReindexHandler(reindexService, testAdminToken).RegisterRoutes(router)
 	}
 	summaryHandler := handlers.NewSummaryHandler(summaryService)
 	summaryHandler.RegisterRoutes(router)
 
+	// YouTube sync is only routed when a test points YOUTUBE_API_KEY/YOUTUBE_API_URL at a fake server
+	if youtubeClient := youtube.NewClient(cfg); youtubeClient != nil {
+		youtubeSyncService := services.NewYouTubeSyncService(channelSettingsRepo, notesRepo, notesService, youtubeClient, cfg)
+		handlers.NewYouTubeSyncHandler(youtubeSyncService).RegisterRoutes(router)
+	}
+	handlers.NewProfilingHandler(testAdminToken).RegisterRoutes(router)
+
 	testEnv = &TestEnv{
 		Router:      router,
 		MongoClient: mongoClient,
 		Database:    database,
 		QdrantURL:   qdrantURL,
+		MockAI:      mockAI,
 		CleanupFns:  []func(){},
 	}
 
 	// Add cleanup functions
 	testEnv.CleanupFns = append(testEnv.CleanupFns, func() {
+		goalsService.Stop()
+		graphService.Stop()
+		migrationService.Stop()
+		if readinessService != nil {
+			readinessService.Stop()
+		}
 		if workerPool != nil {
 			workerPool.Stop()
 		}
+		webhookService.Stop()
 		if qdrantClient != nil {
 			qdrantClient.Close()
 		}
@@ -219,7 +411,7 @@ func TeardownTestEnv(t *testing.T, env *TestEnv) {
 // CleanupCollections clears all test collections
 func CleanupCollections(t *testing.T, env *TestEnv) {
 	ctx := context.Background()
-	collections := []string{"notes", "chunks", "channel_settings"}
+	collections := []string{"notes", "chunks", "channel_settings", "category_rules", "classification_examples", "audit_log", "conversations", "search_queries", "reconciliation_reports", "note_events", "webhooks", "webhook_secrets", "goals", "podcast_feeds", "uploads.files", "uploads.chunks", "migration_runs", "processing_jobs", "dead_letter", "settings", "channel_presets", "ai_operations", "ai_samples", "graph_edges", "automation_rules"}
 
 	for _, name := range collections {
 		_, err := env.Database.Collection(name).DeleteMany(ctx, bson.M{})
@@ -229,6 +421,23 @@ func CleanupCollections(t *testing.T, env *TestEnv) {
 	}
 }
 
+// allowAnyAddress is the address check of the test clients, which fetch from httptest servers on
+// the loopback interface
+func allowAnyAddress(netip.Addr) error {
+	return nil
+}
+
+// adminRequest sends a bodiless request with the given bearer token; an empty token sends none
+func adminRequest(env *TestEnv, method, path, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	env.Router.ServeHTTP(w, req)
+	return w
+}
+
 // HTTPRequest performs an HTTP request and returns the response
 func HTTPRequest(t *testing.T, env *TestEnv, method, path string, body interface{}) *httptest.ResponseRecorder {
 	var reqBody io.Reader
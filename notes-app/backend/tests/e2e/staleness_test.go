@@ -0,0 +1,97 @@
+package e2e
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestStalenessAPI(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	CleanupCollections(t, env)
+
+	insert := func(title, category, content string, created time.Time) primitive.ObjectID {
+		result, err := env.Database.Collection("notes").InsertOne(context.Background(), models.Note{
+			Title:    title,
+			Content:  content,
+			Category: category,
+			Created:  created,
+		})
+		if err != nil {
+			t.Fatalf("Failed to insert note: %v", err)
+		}
+		return result.InsertedID.(primitive.ObjectID)
+	}
+
+	twoYearsAgo := time.Now().AddDate(-2, 0, 0)
+	lastMonth := time.Now().AddDate(0, -1, 0)
+	oldID := insert("Payments API webhooks", "apis", "Register webhooks against the v1 endpoint.", twoYearsAgo)
+	newID := insert("Payments API webhooks v2", "apis", "The v1 webhook endpoint is deprecated, register against v2.", lastMonth)
+	insert("Connection pooling setup", "coding-notes", "Put pgbouncer in front of Postgres.", twoYearsAgo)
+	insert("Connection pooling tuning", "coding-notes", "Pgbouncer in transaction mode works well.", lastMonth)
+
+	t.Run("GET /analysis/outdated flags superseded notes with their successors", func(t *testing.T) {
+		w := HTTPRequest(t, env, "GET", "/analysis/outdated", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var report models.OutdatedReport
+		ParseResponse(t, w, &report)
+		if report.Checked != 2 {
+			t.Errorf("Expected both old notes checked, got %d", report.Checked)
+		}
+		if len(report.Candidates) != 1 || report.Candidates[0].ID != oldID {
+			t.Fatalf("Expected only the webhooks note flagged, got %+v", report.Candidates)
+		}
+		candidate := report.Candidates[0]
+		if candidate.AgeDays < 700 {
+			t.Errorf("Expected an age of about two years, got %d days", candidate.AgeDays)
+		}
+		if len(candidate.Successors) != 1 || candidate.Successors[0].ID != newID || candidate.Successors[0].Relation != models.NoteRelationSupersedes {
+			t.Errorf("Expected the v2 note as successor, got %+v", candidate.Successors)
+		}
+	})
+
+	t.Run("GET /analysis/outdated ignores notes newer than the threshold", func(t *testing.T) {
+		w := HTTPRequest(t, env, "GET", "/analysis/outdated?days=1000", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var report models.OutdatedReport
+		ParseResponse(t, w, &report)
+		if report.Checked != 0 || len(report.Candidates) != 0 {
+			t.Errorf("Expected nothing old enough, got %d checked", report.Checked)
+		}
+	})
+
+	t.Run("GET /analysis/outdated checks a single category", func(t *testing.T) {
+		w := HTTPRequest(t, env, "GET", "/analysis/outdated?category=coding-notes", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var report models.OutdatedReport
+		ParseResponse(t, w, &report)
+		if report.Checked != 1 || len(report.Candidates) != 0 {
+			t.Errorf("Expected 1 consistent note checked, got %d checked and %d flagged", report.Checked, len(report.Candidates))
+		}
+	})
+
+	t.Run("GET /analysis/outdated rejects bad parameters", func(t *testing.T) {
+		for _, path := range []string{"/analysis/outdated?category=not-a-category", "/analysis/outdated?days=0"} {
+			w := HTTPRequest(t, env, "GET", path, nil)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status 400, got %d", path, w.Code)
+			}
+		}
+	})
+}
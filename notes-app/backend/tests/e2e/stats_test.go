@@ -0,0 +1,151 @@
+package e2e
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestStatsAPI(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	CleanupCollections(t, env)
+
+	ctx := context.Background()
+	notes := env.Database.Collection("notes")
+
+	CreateTestNote(t, env, "Today", nil)
+	CreateTestNote(t, env, "Also today", nil)
+	recentID := CreateTestNote(t, env, "Last week", map[string]interface{}{"author": "Heatmap Channel"})
+	oldID := CreateTestNote(t, env, "Two years ago", nil)
+	for id, created := range map[interface{}]time.Time{recentID: time.Now().AddDate(0, 0, -7), oldID: time.Now().AddDate(-2, 0, 0)} {
+		if _, err := notes.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"created": created}}); err != nil {
+			t.Fatalf("Failed to backdate note: %v", err)
+		}
+	}
+
+	t.Run("GET /stats/heatmap counts the past year by day", func(t *testing.T) {
+		w := HTTPRequest(t, env, "GET", "/stats/heatmap", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var heatmap models.Heatmap
+		ParseResponse(t, w, &heatmap)
+
+		if heatmap.Total != 3 {
+			t.Errorf("Expected 3 notes in the past year, got %d", heatmap.Total)
+		}
+		if heatmap.Max != 2 {
+			t.Errorf("Expected max of 2 notes on one day, got %d", heatmap.Max)
+		}
+		if heatmap.To != time.Now().UTC().Format("2006-01-02") || heatmap.Timezone != "UTC" {
+			t.Errorf("Expected range ending today in UTC, got %s (%s)", heatmap.To, heatmap.Timezone)
+		}
+		if len(heatmap.Weeks) < 53 || len(heatmap.Weeks) > 54 {
+			t.Errorf("Expected 53-54 week columns, got %d", len(heatmap.Weeks))
+		}
+		for _, week := range heatmap.Weeks {
+			if len(week) != 7 {
+				t.Fatalf("Expected 7 days per week, got %d", len(week))
+			}
+		}
+
+		// Today is the last in-range cell of the last column
+		lastWeek := heatmap.Weeks[len(heatmap.Weeks)-1]
+		today := int(time.Now().UTC().Weekday())
+		if lastWeek[today] != 2 {
+			t.Errorf("Expected 2 notes today, got %d", lastWeek[today])
+		}
+		for day := today + 1; day < 7; day++ {
+			if lastWeek[day] != -1 {
+				t.Errorf("Expected future day %d to be -1, got %d", day, lastWeek[day])
+			}
+		}
+	})
+
+	t.Run("GET /stats/heatmap filters by channel", func(t *testing.T) {
+		w := HTTPRequest(t, env, "GET", "/stats/heatmap?channel=Heatmap%20Channel", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+
+		var heatmap models.Heatmap
+		ParseResponse(t, w, &heatmap)
+
+		if heatmap.Total != 1 {
+			t.Errorf("Expected 1 channel note, got %d", heatmap.Total)
+		}
+	})
+
+	t.Run("GET /stats/heatmap rejects an unknown timezone", func(t *testing.T) {
+		w := HTTPRequest(t, env, "GET", "/stats/heatmap?tz=Mars/Olympus", nil)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("POST /notes/:id/viewed counts views", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			w := HTTPRequest(t, env, "POST", "/notes/"+recentID.Hex()+"/viewed", nil)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+		}
+		w := HTTPRequest(t, env, "POST", "/notes/"+oldID.Hex()+"/viewed", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var note models.Note
+		ParseResponse(t, w, &note)
+		if note.ViewCount != 1 || note.LastViewedAt == nil {
+			t.Errorf("Expected 1 view with a timestamp, got %d (%v)", note.ViewCount, note.LastViewedAt)
+		}
+	})
+
+	t.Run("POST /notes/:id/viewed returns 404 for an unknown note", func(t *testing.T) {
+		w := HTTPRequest(t, env, "POST", "/notes/507f1f77bcf86cd799439011/viewed", nil)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+	})
+
+	t.Run("GET /stats/access lists the most and least viewed notes", func(t *testing.T) {
+		w := HTTPRequest(t, env, "GET", "/stats/access?limit=2", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var most models.AccessReport
+		ParseResponse(t, w, &most)
+		if most.Order != models.AccessOrderMost || len(most.Notes) != 2 {
+			t.Fatalf("Expected the 2 most viewed notes, got %s with %d", most.Order, len(most.Notes))
+		}
+		if most.Notes[0].ID != recentID || most.Notes[0].ViewCount != 2 || most.Notes[1].ID != oldID {
+			t.Errorf("Expected the note viewed twice first, got %+v", most.Notes)
+		}
+
+		w = HTTPRequest(t, env, "GET", "/stats/access?order=least", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var least models.AccessReport
+		ParseResponse(t, w, &least)
+		if len(least.Notes) != 4 || least.Notes[0].ViewCount != 0 || least.Notes[3].ID != recentID {
+			t.Errorf("Expected unviewed notes first and the most viewed last, got %+v", least.Notes)
+		}
+	})
+
+	t.Run("GET /stats/access rejects an unknown order", func(t *testing.T) {
+		w := HTTPRequest(t, env, "GET", "/stats/access?order=random", nil)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+}
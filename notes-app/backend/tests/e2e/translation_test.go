@@ -0,0 +1,94 @@
+package e2e
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"backend/internal/models"
+)
+
+func TestTranslationAPI(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	CleanupCollections(t, env)
+
+	w := HTTPRequest(t, env, "POST", "/notes", map[string]interface{}{
+		"content": "Ein Artikel über die Geschichte der Eisenbahn in Europa",
+	})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+	}
+	var note models.Note
+	ParseResponse(t, w, &note)
+	path := "/notes/" + note.ID.Hex() + "/translate"
+
+	t.Run("POST /notes/:id/translate returns the translation without saving it", func(t *testing.T) {
+		w := HTTPRequest(t, env, "POST", path+"?lang=en", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var translation models.NoteTranslation
+		ParseResponse(t, w, &translation)
+		if translation.Language != "en" || translation.Content != "[en] "+note.Content || translation.Saved {
+			t.Errorf("Expected an unsaved English translation, got %+v", translation)
+		}
+
+		var stored models.Note
+		ParseResponse(t, HTTPRequest(t, env, "GET", "/notes/"+note.ID.Hex(), nil), &stored)
+		if _, ok := stored.StructuredData["translations"]; ok {
+			t.Errorf("Expected no translations saved, got %v", stored.StructuredData)
+		}
+	})
+
+	t.Run("POST /notes/:id/translate?save=true keeps the translation in structuredData", func(t *testing.T) {
+		w := HTTPRequest(t, env, "POST", path+"?lang=PT-BR&save=true", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var translation models.NoteTranslation
+		ParseResponse(t, w, &translation)
+		if translation.Language != "pt-br" || !translation.Saved {
+			t.Errorf("Expected a saved pt-br translation, got %+v", translation)
+		}
+
+		var stored models.Note
+		ParseResponse(t, HTTPRequest(t, env, "GET", "/notes/"+note.ID.Hex(), nil), &stored)
+		translations, _ := stored.StructuredData["translations"].(map[string]interface{})
+		saved, _ := translations["pt-br"].(map[string]interface{})
+		if saved["content"] != translation.Content || saved["summary"] != translation.Summary {
+			t.Errorf("Expected the translation under structuredData.translations.pt-br, got %v", stored.StructuredData)
+		}
+	})
+
+	t.Run("translations are recorded in the AI history", func(t *testing.T) {
+		var history []models.AIOperation
+		ParseResponse(t, HTTPRequest(t, env, "GET", "/notes/"+note.ID.Hex()+"/ai-history", nil), &history)
+		count := 0
+		for _, op := range history {
+			if op.Operation == models.AIOperationTranslation {
+				count++
+			}
+		}
+		if count != 2 {
+			t.Errorf("Expected 2 translations in the AI history, got %+v", history)
+		}
+	})
+
+	t.Run("POST /notes/:id/translate validates the language and note", func(t *testing.T) {
+		for _, query := range []string{"", "?lang=", "?lang=french", "?lang=e1"} {
+			w := HTTPRequest(t, env, "POST", path+query, nil)
+			if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "invalid language") {
+				t.Errorf("%q: expected status 400 for the language, got %d", query, w.Code)
+			}
+		}
+		if w := HTTPRequest(t, env, "POST", "/notes/nope/translate?lang=fr", nil); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for an invalid ID, got %d", w.Code)
+		}
+		if w := HTTPRequest(t, env, "POST", "/notes/000000000000000000000000/translate?lang=fr", nil); w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 for an unknown note, got %d", w.Code)
+		}
+	})
+}
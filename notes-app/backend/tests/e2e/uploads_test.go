@@ -0,0 +1,186 @@
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// buildPDF writes a minimal PDF with one page per text, each drawn line by line in Helvetica
+func buildPDF(pages ...string) []byte {
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	kids := ""
+	for i := range pages {
+		kids += fmt.Sprintf("%d 0 R ", 4+2*i)
+	}
+	fmt.Fprintf(&b, "1 0 obj << /Type /Catalog /Pages 2 0 R >> endobj\n")
+	fmt.Fprintf(&b, "2 0 obj << /Type /Pages /Kids [%s] /Count %d /Resources << /Font << /F1 3 0 R >> >> >> endobj\n", kids, len(pages))
+	fmt.Fprintf(&b, "3 0 obj << /Type /Font /Subtype /Type1 /BaseFont /Helvetica >> endobj\n")
+	for i, text := range pages {
+		content := "BT /F1 12 Tf 72 720 Td"
+		for _, line := range strings.Split(text, "\n") {
+			content += fmt.Sprintf(" (%s) Tj 0 -14 Td", line)
+		}
+		content += " ET"
+		fmt.Fprintf(&b, "%d 0 obj << /Type /Page /Parent 2 0 R /Contents %d 0 R >> endobj\n", 4+2*i, 5+2*i)
+		fmt.Fprintf(&b, "%d 0 obj << /Length %d >>\nstream\n%s\nendstream\nendobj\n", 5+2*i, len(content), content)
+	}
+	b.WriteString("trailer << /Root 1 0 R >>\n%%EOF\n")
+	return b.Bytes()
+}
+
+// buildPNG encodes a small blank PNG
+func buildPNG() []byte {
+	var b bytes.Buffer
+	png.Encode(&b, image.NewGray(image.Rect(0, 0, 4, 4)))
+	return b.Bytes()
+}
+
+// uploadFile POSTs a file to /notes/upload as a multipart upload
+func uploadFile(t *testing.T, env *TestEnv, fileName string, data []byte) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("file", fileName)
+	if err != nil {
+		t.Fatalf("Failed to create form file: %v", err)
+	}
+	part.Write(data)
+	form.WriteField("tags", "papers")
+	form.Close()
+
+	req := httptest.NewRequest("POST", "/notes/upload", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	w := httptest.NewRecorder()
+	env.Router.ServeHTTP(w, req)
+	return w
+}
+
+func TestUploadsAPI(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	CleanupCollections(t, env)
+
+	paper := buildPDF(
+		"Attention Is All You Need\nThe Transformer relies entirely on attention.",
+		"Results\nThe model reaches a new state of the art in translation quality.",
+	)
+	var upload models.UploadResponse
+
+	t.Run("POST /notes/upload creates a note with the text of each page", func(t *testing.T) {
+		w := uploadFile(t, env, "transformer.pdf", paper)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+		}
+
+		ParseResponse(t, w, &upload)
+		if upload.Pages != 2 || len(upload.Notes) != 1 {
+			t.Fatalf("Expected 2 pages in 1 note, got %d pages in %d notes", upload.Pages, len(upload.Notes))
+		}
+		note := upload.Notes[0]
+		want := "[Page 1]\nAttention Is All You Need\nThe Transformer relies entirely on attention.\n\n[Page 2]\nResults\n"
+		if !strings.HasPrefix(note.Content, want) {
+			t.Errorf("Expected page-marked content, got %q", note.Content)
+		}
+		if note.Title != "transformer" || note.Metadata["file_id"] != upload.FileID.Hex() || note.Metadata["source"] != "pdf-upload" {
+			t.Errorf("Unexpected title %q or metadata %v", note.Title, note.Metadata)
+		}
+	})
+
+	t.Run("chunks of uploaded notes record their page", func(t *testing.T) {
+		if len(upload.Notes) == 0 {
+			t.Skip("upload failed")
+		}
+		var chunks []models.NoteChunk
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) && len(chunks) == 0 {
+			cursor, err := env.Database.Collection("chunks").Find(context.Background(), bson.M{"note_id": upload.Notes[0].ID})
+			if err == nil {
+				cursor.All(context.Background(), &chunks)
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		if len(chunks) == 0 {
+			t.Skip("no worker is running without Qdrant")
+		}
+		for _, chunk := range chunks {
+			if chunk.Page < 1 || chunk.Page > 2 {
+				t.Errorf("Expected chunk %d to cite page 1 or 2, got %d", chunk.ChunkIdx, chunk.Page)
+			}
+		}
+	})
+
+	t.Run("GET /notes/:id/file downloads the original PDF", func(t *testing.T) {
+		if len(upload.Notes) == 0 {
+			t.Skip("upload failed")
+		}
+		w := HTTPRequest(t, env, "GET", "/notes/"+upload.Notes[0].ID.Hex()+"/file", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if !bytes.Equal(w.Body.Bytes(), paper) || w.Header().Get("Content-Type") != "application/pdf" {
+			t.Errorf("Expected the uploaded PDF back, got %d bytes of %s", w.Body.Len(), w.Header().Get("Content-Type"))
+		}
+	})
+
+	t.Run("POST /notes/upload reports a file uploaded before as a duplicate", func(t *testing.T) {
+		w := uploadFile(t, env, "copy.pdf", paper)
+		if w.Code != http.StatusConflict {
+			t.Errorf("Expected status 409, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("POST /notes/upload rejects files that are not PDFs", func(t *testing.T) {
+		w := uploadFile(t, env, "notes.txt", []byte("just some text"))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("POST /notes/upload creates a note from the text and description extracted from an image", func(t *testing.T) {
+		photo := buildPNG()
+		w := uploadFile(t, env, "IMG_0042.png", photo)
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var result models.UploadResponse
+		ParseResponse(t, w, &result)
+		if len(result.Notes) != 1 {
+			t.Fatalf("Expected 1 note, got %d", len(result.Notes))
+		}
+		note := result.Notes[0]
+		if note.Content != "Mock description of a png image\n\nText in the image:\nMock text read from the image" {
+			t.Errorf("Expected the extracted description and text, got %q", note.Content)
+		}
+		if note.Metadata["source"] != "image-upload" || note.Metadata["content_type"] != "image/png" {
+			t.Errorf("Unexpected metadata %v", note.Metadata)
+		}
+
+		w = HTTPRequest(t, env, "GET", "/notes/"+note.ID.Hex()+"/file", nil)
+		if w.Code != http.StatusOK || !bytes.Equal(w.Body.Bytes(), photo) || w.Header().Get("Content-Type") != "image/png" {
+			t.Errorf("Expected the uploaded image back, got %d with %d bytes of %s", w.Code, w.Body.Len(), w.Header().Get("Content-Type"))
+		}
+	})
+
+	t.Run("GET /notes/:id/file returns 404 for notes without a file", func(t *testing.T) {
+		noteID := CreateTestNote(t, env, "Typed in by hand", nil)
+		w := HTTPRequest(t, env, "GET", "/notes/"+noteID.Hex()+"/file", nil)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,135 @@
+package e2e
+
+import (
+	"net/http"
+	"testing"
+
+	"backend/internal/models"
+	"backend/internal/utils"
+)
+
+func TestVaultSyncAPI(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	CleanupCollections(t, env)
+
+	serverID := CreateTestNote(t, env, "Written in the app", nil)
+
+	push := func(t *testing.T, files ...models.VaultPushFile) []models.VaultPushResult {
+		w := HTTPRequest(t, env, "POST", "/sync/obsidian/push", models.VaultPushRequest{Files: files})
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var response models.VaultPushResponse
+		ParseResponse(t, w, &response)
+		if len(response.Results) != len(files) {
+			t.Fatalf("Expected %d results, got %d", len(files), len(response.Results))
+		}
+		return response.Results
+	}
+
+	manifest := func(t *testing.T) []models.VaultFileInfo {
+		var manifest models.VaultManifest
+		ParseResponse(t, HTTPRequest(t, env, "GET", "/sync/obsidian/manifest", nil), &manifest)
+		return manifest.Files
+	}
+
+	var serverPath string
+	t.Run("POST /migrate/vault-paths assigns stable paths to app notes", func(t *testing.T) {
+		if files := manifest(t); len(files) != 0 {
+			t.Fatalf("Expected no files before paths are assigned, got %+v", files)
+		}
+
+		var result struct {
+			Updated int `json:"updated"`
+		}
+		ParseResponse(t, HTTPRequest(t, env, "POST", "/migrate/vault-paths", nil), &result)
+		if result.Updated != 1 {
+			t.Errorf("Expected 1 note given a path, got %d", result.Updated)
+		}
+
+		files := manifest(t)
+		if len(files) != 1 {
+			t.Fatalf("Expected 1 file, got %d", len(files))
+		}
+		file := files[0]
+		if file.NoteID != serverID.Hex() || file.Hash != utils.ContentHash("Written in the app") {
+			t.Errorf("Unexpected manifest entry %+v", file)
+		}
+		serverPath = file.Path
+
+		ParseResponse(t, HTTPRequest(t, env, "POST", "/migrate/vault-paths", nil), &result)
+		if again := manifest(t); result.Updated != 0 || len(again) != 1 || again[0].Path != serverPath {
+			t.Errorf("Expected path %q to be kept, got %+v", serverPath, again)
+		}
+	})
+
+	t.Run("POST /sync/obsidian/pull returns file content", func(t *testing.T) {
+		w := HTTPRequest(t, env, "POST", "/sync/obsidian/pull", models.VaultPullRequest{Paths: []string{serverPath, "nope.md"}})
+		var response models.VaultPullResponse
+		ParseResponse(t, w, &response)
+		if len(response.Files) != 1 || response.Files[0].Content != "Written in the app" {
+			t.Errorf("Expected the note content, got %+v", response.Files)
+		}
+		if len(response.Missing) != 1 || response.Missing[0] != "nope.md" {
+			t.Errorf("Expected nope.md missing, got %v", response.Missing)
+		}
+	})
+
+	t.Run("POST /sync/obsidian/push creates, updates and detects conflicts", func(t *testing.T) {
+		results := push(t, models.VaultPushFile{Path: "Daily/2024-01-01.md", Content: "From the vault"})
+		if results[0].Status != models.VaultStatusCreated || results[0].NoteID == "" {
+			t.Fatalf("Expected created, got %+v", results[0])
+		}
+		var created models.Note
+		ParseResponse(t, HTTPRequest(t, env, "GET", "/notes/"+results[0].NoteID, nil), &created)
+		if created.Title != "2024-01-01" || created.Metadata["vault_path"] != "Daily/2024-01-01.md" {
+			t.Errorf("Expected note titled after the file, got %q %v", created.Title, created.Metadata)
+		}
+
+		baseHash := utils.ContentHash("Written in the app")
+		results = push(t, models.VaultPushFile{Path: serverPath, Content: "Edited in the vault", BaseHash: baseHash})
+		if results[0].Status != models.VaultStatusUpdated || results[0].Hash != utils.ContentHash("Edited in the vault") {
+			t.Errorf("Expected updated, got %+v", results[0])
+		}
+
+		// The vault still thinks the old content is current, so a second edit conflicts
+		results = push(t, models.VaultPushFile{Path: serverPath, Content: "Stale edit", BaseHash: baseHash})
+		if results[0].Status != models.VaultStatusConflict {
+			t.Errorf("Expected conflict, got %+v", results[0])
+		}
+
+		results = push(t, models.VaultPushFile{Path: serverPath, Deleted: true, BaseHash: utils.ContentHash("Edited in the vault")})
+		if results[0].Status != models.VaultStatusDeleted {
+			t.Errorf("Expected deleted, got %+v", results[0])
+		}
+		if w := HTTPRequest(t, env, "GET", "/notes/"+serverID.Hex(), nil); w.Code != http.StatusNotFound {
+			t.Errorf("Expected deleted note to be gone, got %d", w.Code)
+		}
+	})
+
+	t.Run("POST /sync/obsidian/push gives new app notes a path", func(t *testing.T) {
+		appID := CreateTestNote(t, env, "Also written in the app", nil)
+		push(t, models.VaultPushFile{Path: "Inbox/idea.md", Content: "Jotted in the vault"})
+
+		listed := false
+		for _, file := range manifest(t) {
+			listed = listed || file.NoteID == appID.Hex()
+		}
+		if !listed {
+			t.Error("Expected the new app note in the manifest after a push")
+		}
+	})
+
+	t.Run("POST /sync/obsidian/push rejects paths outside the vault", func(t *testing.T) {
+		for _, path := range []string{"../escape.md", "/abs.md", "image.png"} {
+			w := HTTPRequest(t, env, "POST", "/sync/obsidian/push", models.VaultPushRequest{
+				Files: []models.VaultPushFile{{Path: path, Content: "x"}},
+			})
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400 for %q, got %d", path, w.Code)
+			}
+		}
+	})
+}
@@ -0,0 +1,398 @@
+package e2e
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/services"
+	"backend/internal/utils"
+)
+
+func TestWebhooksAPI(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	CleanupCollections(t, env)
+
+	var mu sync.Mutex
+	var received []models.NoteEvent
+	var signatures []string
+	var bodies [][]byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var event models.NoteEvent
+		json.Unmarshal(body, &event)
+
+		mu.Lock()
+		received = append(received, event)
+		signatures = append(signatures, r.Header.Get("X-Webhook-Signature"))
+		bodies = append(bodies, body)
+		mu.Unlock()
+	}))
+	defer receiver.Close()
+
+	waitForEvents := func(t *testing.T, n int) {
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			mu.Lock()
+			count := len(received)
+			mu.Unlock()
+			if count >= n {
+				return
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		t.Fatalf("Timed out waiting for %d webhook deliveries", n)
+	}
+
+	var webhook models.Webhook
+
+	t.Run("POST /webhooks registers a webhook and returns its secret", func(t *testing.T) {
+		w := HTTPRequest(t, env, "POST", "/webhooks", map[string]interface{}{
+			"url":    receiver.URL,
+			"secret": "test-secret",
+		})
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+		}
+
+		ParseResponse(t, w, &webhook)
+		if webhook.Secret != "test-secret" {
+			t.Errorf("Expected secret in create response, got '%s'", webhook.Secret)
+		}
+	})
+
+	t.Run("POST /webhooks with invalid URL returns 400", func(t *testing.T) {
+		w := HTTPRequest(t, env, "POST", "/webhooks", map[string]interface{}{"url": "not a url"})
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("CreateWebhook refuses endpoints on non-public addresses", func(t *testing.T) {
+		webhookService := services.NewWebhookService(
+			repository.NewNoteEventsRepository(env.Database),
+			repository.NewWebhooksRepository(env.Database),
+			repository.NewWebhookSecretsRepository(env.Database),
+			utils.PublicAddress,
+		)
+		for _, endpoint := range []string{receiver.URL, "http://169.254.169.254/latest/meta-data/", "http://[fd00::1]/hook", "http://localhost:8080/hook"} {
+			_, err := webhookService.CreateWebhook(context.Background(), &models.CreateWebhookRequest{URL: endpoint})
+			if err == nil || !strings.Contains(err.Error(), "non-public address") {
+				t.Errorf("Expected an error naming the non-public address for %s, got %v", endpoint, err)
+			}
+		}
+	})
+
+	t.Run("POST /webhooks with unknown event type returns 400", func(t *testing.T) {
+		w := HTTPRequest(t, env, "POST", "/webhooks", map[string]interface{}{
+			"url":        receiver.URL,
+			"eventTypes": []string{"note.exploded"},
+		})
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("GET /webhooks hides secrets", func(t *testing.T) {
+		var webhooks []models.Webhook
+		ParseResponse(t, HTTPRequest(t, env, "GET", "/webhooks", nil), &webhooks)
+
+		if len(webhooks) != 1 || webhooks[0].Secret != "" {
+			t.Errorf("Expected 1 webhook without secret, got %+v", webhooks)
+		}
+	})
+
+	noteID := CreateTestNote(t, env, "Indexed note", nil)
+
+	t.Run("Note changes are delivered as signed events", func(t *testing.T) {
+		w := HTTPRequest(t, env, "POST", "/notes/"+noteID.Hex()+"/pin", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200 pinning note, got %d", w.Code)
+		}
+		waitForEvents(t, 1)
+
+		mu.Lock()
+		defer mu.Unlock()
+		event := received[0]
+		if event.Type != models.NoteEventUpdated || event.NoteID != noteID {
+			t.Errorf("Expected note.updated for %s, got %s for %s", noteID.Hex(), event.Type, event.NoteID.Hex())
+		}
+		if event.Delta["pinned"] != true {
+			t.Errorf("Expected pinned delta, got %v", event.Delta)
+		}
+		expected := "sha256=" + services.SignWebhookPayload("test-secret", bodies[0])
+		if signatures[0] != expected {
+			t.Errorf("Expected signature %s, got %s", expected, signatures[0])
+		}
+	})
+
+	t.Run("GET /webhooks/events pages the event log", func(t *testing.T) {
+		var events []models.NoteEvent
+		ParseResponse(t, HTTPRequest(t, env, "GET", "/webhooks/events", nil), &events)
+		if len(events) != 1 {
+			t.Fatalf("Expected 1 logged event, got %d", len(events))
+		}
+
+		ParseResponse(t, HTTPRequest(t, env, "GET", "/webhooks/events?after="+events[0].ID.Hex(), nil), &events)
+		if len(events) != 0 {
+			t.Errorf("Expected no events after the last cursor, got %d", len(events))
+		}
+	})
+
+	t.Run("POST /webhooks/:id/replay redelivers from the cursor", func(t *testing.T) {
+		w := HTTPRequest(t, env, "POST", "/webhooks/"+webhook.ID.Hex()+"/replay", map[string]interface{}{"cursor": ""})
+		if w.Code != http.StatusAccepted {
+			t.Fatalf("Expected status 202, got %d: %s", w.Code, w.Body.String())
+		}
+		waitForEvents(t, 2)
+
+		mu.Lock()
+		defer mu.Unlock()
+		if received[1].ID != received[0].ID {
+			t.Errorf("Expected replay of event %s, got %s", received[0].ID.Hex(), received[1].ID.Hex())
+		}
+	})
+
+	t.Run("Generated summaries are delivered as summary.generated events", func(t *testing.T) {
+		w := HTTPRequest(t, env, "POST", "/summarize/"+noteID.Hex(), nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200 summarizing note, got %d: %s", w.Code, w.Body.String())
+		}
+		waitForEvents(t, 3)
+
+		mu.Lock()
+		defer mu.Unlock()
+		event := received[2]
+		if event.Type != models.NoteEventSummaryGenerated || event.NoteID != noteID {
+			t.Errorf("Expected summary.generated for %s, got %s for %s", noteID.Hex(), event.Type, event.NoteID.Hex())
+		}
+		if summary, _ := event.Delta["summary"].(string); summary == "" {
+			t.Errorf("Expected the summary in the delta, got %v", event.Delta)
+		}
+	})
+
+	t.Run("POST /webhooks/:id/replay with invalid cursor returns 400", func(t *testing.T) {
+		w := HTTPRequest(t, env, "POST", "/webhooks/"+webhook.ID.Hex()+"/replay", map[string]interface{}{"cursor": "nope"})
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("DELETE /webhooks/:id removes the webhook", func(t *testing.T) {
+		w := HTTPRequest(t, env, "DELETE", "/webhooks/"+webhook.ID.Hex(), nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+
+		w = HTTPRequest(t, env, "DELETE", "/webhooks/"+webhook.ID.Hex(), nil)
+		if w.Code != http.StatusNotFound || !strings.Contains(w.Body.String(), "not found") {
+			t.Errorf("Expected 404 for deleted webhook, got %d", w.Code)
+		}
+	})
+}
+
+func TestWebhookOutboundAuth(t *testing.T) {
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	CleanupCollections(t, env)
+
+	var mu sync.Mutex
+	var authorizations, signatures []string
+	var bodies [][]byte
+	var clientCerts []int
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		certs := 0
+		if r.TLS != nil {
+			certs = len(r.TLS.PeerCertificates)
+		}
+
+		mu.Lock()
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		signatures = append(signatures, r.Header.Get("X-Webhook-Signature"))
+		bodies = append(bodies, body)
+		clientCerts = append(clientCerts, certs)
+		mu.Unlock()
+	})
+	receiver := httptest.NewServer(handler)
+	defer receiver.Close()
+
+	// Deliveries without a client certificate fail the handshake
+	tlsReceiver := httptest.NewUnstartedServer(handler)
+	tlsReceiver.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	tlsReceiver.StartTLS()
+	defer tlsReceiver.Close()
+	caCert := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: tlsReceiver.Certificate().Raw}))
+	clientCert, clientKey := generateClientCert(t)
+
+	waitForDeliveries := func(t *testing.T, n int) {
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			mu.Lock()
+			count := len(authorizations)
+			mu.Unlock()
+			if count >= n {
+				return
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+		t.Fatalf("Timed out waiting for %d webhook deliveries", n)
+	}
+
+	t.Run("POST /webhooks rejects invalid outbound auth", func(t *testing.T) {
+		for name, reqBody := range map[string]map[string]interface{}{
+			"unknown type":      {"url": receiver.URL, "auth": map[string]string{"type": "basic"}},
+			"bearer no token":   {"url": receiver.URL, "auth": map[string]string{"type": "bearer"}},
+			"hmac with token":   {"url": receiver.URL, "auth": map[string]string{"type": "hmac", "token": "x"}},
+			"mtls no key":       {"url": tlsReceiver.URL, "auth": map[string]string{"type": "mtls", "clientCert": clientCert}},
+			"mtls bad cert":     {"url": tlsReceiver.URL, "auth": map[string]string{"type": "mtls", "clientCert": "nope", "clientKey": clientKey}},
+			"mtls over http":    {"url": receiver.URL, "auth": map[string]string{"type": "mtls", "clientCert": clientCert, "clientKey": clientKey}},
+			"ca cert not pem":   {"url": tlsReceiver.URL, "auth": map[string]string{"type": "hmac", "caCert": "nope"}},
+			"ca cert over http": {"url": receiver.URL, "auth": map[string]string{"type": "hmac", "caCert": caCert}},
+		} {
+			w := HTTPRequest(t, env, "POST", "/webhooks", reqBody)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("%s: expected status 400, got %d", name, w.Code)
+			}
+		}
+	})
+
+	var webhook models.Webhook
+
+	t.Run("Bearer webhooks send the token with the signature", func(t *testing.T) {
+		w := HTTPRequest(t, env, "POST", "/webhooks", map[string]interface{}{
+			"url":    receiver.URL,
+			"secret": "test-secret",
+			"auth":   map[string]string{"type": "Bearer", "token": "first-token"},
+		})
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+		}
+		ParseResponse(t, w, &webhook)
+		if webhook.AuthType != models.WebhookAuthBearer || webhook.Secret != "test-secret" {
+			t.Errorf("Expected a bearer webhook with its secret, got %+v", webhook)
+		}
+
+		noteID := CreateTestNote(t, env, "Bearer note", nil)
+		HTTPRequest(t, env, "POST", "/notes/"+noteID.Hex()+"/pin", nil)
+		waitForDeliveries(t, 1)
+
+		mu.Lock()
+		defer mu.Unlock()
+		if authorizations[0] != "Bearer first-token" {
+			t.Errorf("Expected the bearer token, got '%s'", authorizations[0])
+		}
+		if expected := "sha256=" + services.SignWebhookPayload("test-secret", bodies[0]); signatures[0] != expected {
+			t.Errorf("Expected signature %s, got %s", expected, signatures[0])
+		}
+	})
+
+	t.Run("GET /webhooks hides credentials", func(t *testing.T) {
+		w := HTTPRequest(t, env, "GET", "/webhooks", nil)
+		if strings.Contains(w.Body.String(), "first-token") || strings.Contains(w.Body.String(), "test-secret") {
+			t.Errorf("Expected no credentials listed, got %s", w.Body.String())
+		}
+	})
+
+	t.Run("PUT /webhooks/:id/auth rotates the token and keeps the secret", func(t *testing.T) {
+		w := HTTPRequest(t, env, "PUT", "/webhooks/"+webhook.ID.Hex()+"/auth", map[string]string{"type": "bearer", "token": "second-token"})
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		if strings.Contains(w.Body.String(), "second-token") {
+			t.Errorf("Expected the token not returned, got %s", w.Body.String())
+		}
+
+		noteID := CreateTestNote(t, env, "Rotated note", nil)
+		HTTPRequest(t, env, "POST", "/notes/"+noteID.Hex()+"/pin", nil)
+		waitForDeliveries(t, 2)
+
+		mu.Lock()
+		defer mu.Unlock()
+		if authorizations[1] != "Bearer second-token" {
+			t.Errorf("Expected the rotated token, got '%s'", authorizations[1])
+		}
+		if expected := "sha256=" + services.SignWebhookPayload("test-secret", bodies[1]); signatures[1] != expected {
+			t.Errorf("Expected the delivery still signed with the webhook secret, got %s", signatures[1])
+		}
+	})
+
+	t.Run("PUT /webhooks/:id/auth validates the auth and the webhook", func(t *testing.T) {
+		w := HTTPRequest(t, env, "PUT", "/webhooks/"+webhook.ID.Hex()+"/auth", map[string]string{"type": "mtls", "clientCert": clientCert, "clientKey": clientKey})
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for mtls over http, got %d", w.Code)
+		}
+		w = HTTPRequest(t, env, "PUT", "/webhooks/000000000000000000000000/auth", map[string]string{"type": "hmac"})
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404 for an unknown webhook, got %d", w.Code)
+		}
+	})
+
+	t.Run("mTLS webhooks present their client certificate", func(t *testing.T) {
+		if w := HTTPRequest(t, env, "DELETE", "/webhooks/"+webhook.ID.Hex(), nil); w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		w := HTTPRequest(t, env, "POST", "/webhooks", map[string]interface{}{
+			"url":    tlsReceiver.URL,
+			"secret": "test-secret",
+			"auth":   map[string]string{"type": "mtls", "clientCert": clientCert, "clientKey": clientKey, "caCert": caCert},
+		})
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+		}
+
+		noteID := CreateTestNote(t, env, "mTLS note", nil)
+		HTTPRequest(t, env, "POST", "/notes/"+noteID.Hex()+"/pin", nil)
+		waitForDeliveries(t, 3)
+
+		mu.Lock()
+		defer mu.Unlock()
+		if clientCerts[2] != 1 || authorizations[2] != "" {
+			t.Errorf("Expected one client certificate and no bearer token, got %d and '%s'", clientCerts[2], authorizations[2])
+		}
+	})
+}
+
+// generateClientCert returns a self-signed PEM client certificate and its key
+func generateClientCert(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "notes-webhooks"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+}
@@ -0,0 +1,180 @@
+package e2e
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"backend/internal/models"
+	"backend/internal/youtube"
+)
+
+// newFakeYouTube serves a channel with three uploads: one with captions, one without captions
+// yet and one the test saves beforehand
+func newFakeYouTube(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	var server *httptest.Server
+
+	mux.HandleFunc("/youtube/v3/channels", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("forHandle") != "@synctest" || r.URL.Query().Get("key") != "test-key" {
+			json.NewEncoder(w).Encode(map[string]interface{}{"items": []interface{}{}})
+			return
+		}
+		fmt.Fprint(w, `{"items": [{"contentDetails": {"relatedPlaylists": {"uploads": "UUsynctest"}}}]}`)
+	})
+	mux.HandleFunc("/youtube/v3/playlistItems", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("playlistId") != "UUsynctest" {
+			http.Error(w, `{"error": {"message": "playlist not found"}}`, http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `{"items": [
+			{"snippet": {"title": "Captioned upload"}, "contentDetails": {"videoId": "vidcaptioned", "videoPublishedAt": "2024-03-01T12:00:00Z"}},
+			{"snippet": {"title": "Fresh upload"}, "contentDetails": {"videoId": "vidfresh", "videoPublishedAt": "2024-03-02T12:00:00Z"}},
+			{"snippet": {"title": "Saved upload"}, "contentDetails": {"videoId": "vidsaved", "videoPublishedAt": "2024-02-01T12:00:00Z"}},
+			{"snippet": {"title": "Private video"}, "contentDetails": {"videoId": "vidprivate"}}
+		]}`)
+	})
+	mux.HandleFunc("/watch", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("v") {
+		case "vidcaptioned":
+			fmt.Fprintf(w, `<html><script>var ytInitialPlayerResponse = {"captions": {"playerCaptionsTracklistRenderer": {"captionTracks": [`+
+				`{"baseUrl": "%[1]s/timedtext?lang=de", "languageCode": "de"}, `+
+				`{"baseUrl": "%[1]s/timedtext?lang=en&kind=asr", "languageCode": "en", "kind": "asr"}]}}};</script></html>`, server.URL)
+		case "vidsaved":
+			t.Errorf("Transcript fetched for an upload that is already saved")
+		default:
+			fmt.Fprint(w, `<html><script>var ytInitialPlayerResponse = {};</script></html>`)
+		}
+	})
+	mux.HandleFunc("/timedtext", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("lang") != "en" {
+			t.Errorf("Expected the English track, got %s", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8" ?><transcript>`+
+			`<text start="0" dur="2">Welcome back to the channel</text>`+
+			`<text start="2" dur="3">today we&amp;#39;re  syncing transcripts</text></transcript>`)
+	})
+
+	server = httptest.NewServer(mux)
+	return server
+}
+
+func TestYouTubeSyncAPI(t *testing.T) {
+	fake := newFakeYouTube(t)
+	defer fake.Close()
+
+	t.Setenv("YOUTUBE_API_KEY", "test-key")
+	t.Setenv("YOUTUBE_API_URL", fake.URL+"/youtube/v3")
+	t.Setenv("YOUTUBE_WATCH_URL", fake.URL+"/watch")
+
+	env := SetupTestEnv(t)
+	defer TeardownTestEnv(t, env)
+
+	CleanupCollections(t, env)
+
+	w := HTTPRequest(t, env, "PUT", "/channel-settings/Sync%20Test", map[string]interface{}{
+		"platform":   "youtube",
+		"channelUrl": "https://www.youtube.com/@synctest/videos",
+		"promptText": "Summarize the episode",
+	})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Failed to save channel settings: %d %s", w.Code, w.Body.String())
+	}
+	CreateTestNote(t, env, "Saved earlier from the extension", map[string]interface{}{
+		"author":   "Sync Test",
+		"platform": "youtube",
+		"url":      youtube.WatchURL("vidsaved"),
+	})
+
+	t.Run("POST /sync/youtube saves new uploads with transcripts", func(t *testing.T) {
+		w := HTTPRequest(t, env, "POST", "/sync/youtube", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var report models.YouTubeSyncReport
+		ParseResponse(t, w, &report)
+		if len(report.Channels) != 1 {
+			t.Fatalf("Expected 1 synced channel, got %d", len(report.Channels))
+		}
+		result := report.Channels[0]
+		if result.Error != "" || result.Checked != 3 || result.Created != 1 || result.Existing != 1 || result.NoTranscript != 1 {
+			t.Fatalf("Unexpected sync result: %+v", result)
+		}
+
+		w = HTTPRequest(t, env, "GET", "/notes?channel=Sync%20Test", nil)
+		var notes []models.Note
+		ParseResponse(t, w, &notes)
+		var synced *models.Note
+		for i := range notes {
+			if notes[i].Metadata["video_id"] == "vidcaptioned" {
+				synced = &notes[i]
+			}
+		}
+		if synced == nil {
+			t.Fatalf("Expected a note for the captioned upload among %d notes", len(notes))
+		}
+		if synced.Content != "Welcome back to the channel today we're syncing transcripts" {
+			t.Errorf("Unexpected transcript: %q", synced.Content)
+		}
+		if synced.Title != "Captioned upload" || synced.Metadata["url"] != youtube.WatchURL("vidcaptioned") {
+			t.Errorf("Expected video title and URL, got %q %v", synced.Title, synced.Metadata["url"])
+		}
+		if synced.SourcePublishedAt == nil || synced.SourcePublishedAt.Format("2006-01-02") != "2024-03-01" {
+			t.Errorf("Expected the publish date as source date, got %v", synced.SourcePublishedAt)
+		}
+	})
+
+	t.Run("POST /sync/youtube skips uploads saved by an earlier sync", func(t *testing.T) {
+		w := HTTPRequest(t, env, "POST", "/sync/youtube?channel=sync%20test", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var report models.YouTubeSyncReport
+		ParseResponse(t, w, &report)
+		if len(report.Channels) != 1 || report.Channels[0].Created != 0 || report.Channels[0].Existing != 2 {
+			t.Errorf("Expected nothing new, got %+v", report.Channels)
+		}
+
+		w = HTTPRequest(t, env, "GET", "/channel-settings/Sync%20Test?platform=youtube", nil)
+		var settings models.ChannelSettings
+		ParseResponse(t, w, &settings)
+		if settings.LastSyncedAt == nil || settings.LastSyncError != "" {
+			t.Errorf("Expected a successful sync recorded, got %v %q", settings.LastSyncedAt, settings.LastSyncError)
+		}
+	})
+
+	t.Run("POST /sync/youtube records channels that can't be resolved", func(t *testing.T) {
+		w := HTTPRequest(t, env, "PUT", "/channel-settings/Unknown%20Channel", map[string]interface{}{
+			"platform":   "youtube",
+			"channelUrl": "https://www.youtube.com/@nobody",
+		})
+		if w.Code != http.StatusOK {
+			t.Fatalf("Failed to save channel settings: %d", w.Code)
+		}
+
+		w = HTTPRequest(t, env, "POST", "/sync/youtube?channel=Unknown%20Channel", nil)
+		var report models.YouTubeSyncReport
+		ParseResponse(t, w, &report)
+		if len(report.Channels) != 1 || report.Channels[0].Error == "" {
+			t.Fatalf("Expected a channel error, got %+v", report.Channels)
+		}
+
+		w = HTTPRequest(t, env, "GET", "/channel-settings/Unknown%20Channel?platform=youtube", nil)
+		var settings models.ChannelSettings
+		ParseResponse(t, w, &settings)
+		if settings.LastSyncError == "" {
+			t.Error("Expected the sync error saved on the channel settings")
+		}
+	})
+
+	t.Run("POST /sync/youtube returns 404 for a channel without a channel URL", func(t *testing.T) {
+		w := HTTPRequest(t, env, "POST", "/sync/youtube?channel=Nobody", nil)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+	})
+}